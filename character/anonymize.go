@@ -0,0 +1,104 @@
+package character
+
+import (
+	"regexp"
+
+	"github.com/r3dpixel/card-parser/property"
+)
+
+// DefaultURLPlaceholder is the placeholder replacing URLs in free-text fields (when no placeholder is given)
+const DefaultURLPlaceholder = "[link]"
+
+var (
+	// AnonymizedExtensionKeys are the identity keys removed from the content, lorebook and lorebook entry extensions
+	AnonymizedExtensionKeys = []string{"author", "creator", "source", "source_url", "url", "link"}
+
+	// Regex matching URLs (http, https or www prefixed), excluding trailing punctuation
+	urlRegex = regexp.MustCompile(`(?i)\b(?:https?://|www\.)[^\s<>"'()\[\]]*[^\s<>"'()\[\].,;:!?]`)
+)
+
+// AnonymizeOptions options of Content.Anonymize
+type AnonymizeOptions struct {
+	BlankCreator   bool   // Clear the creator instead of replacing it with AnonymousCreator
+	KeepNotes      bool   // Keep the creator notes (and the multilingual creator notes)
+	ReplaceURLs    bool   // Replace URLs in free-text fields with the URL placeholder
+	URLPlaceholder string // Placeholder replacing URLs (DefaultURLPlaceholder if empty)
+}
+
+// Anonymize scrubs the creator-identifying fields: creator, creator notes, source, source/character/platform IDs,
+// direct link, and the AnonymizedExtensionKeys of the content, lorebook and lorebook entry extensions
+// Other extension keys are left untouched
+func (c *Content) Anonymize(opts AnonymizeOptions) {
+	// Replace (or clear) the creator
+	c.Creator = AnonymousCreator
+	if opts.BlankCreator {
+		c.Creator = ""
+	}
+
+	// Remove the creator notes
+	if !opts.KeepNotes {
+		c.CreatorNotes = ""
+		c.CreatorNotesMultilingual = nil
+	}
+
+	// Clear the origin identifiers
+	c.Source = nil
+	c.SourceID = ""
+	c.CharacterID = ""
+	c.PlatformID = ""
+	c.DirectLink = ""
+
+	// Remove the identity keys from the extensions
+	deleteKeys(c.Extensions, AnonymizedExtensionKeys)
+	if c.CharacterBook != nil {
+		deleteKeys(c.CharacterBook.Extensions, AnonymizedExtensionKeys)
+		for _, entry := range c.CharacterBook.Entries {
+			if entry != nil {
+				deleteKeys(entry.RawExtensions, AnonymizedExtensionKeys)
+			}
+		}
+	}
+
+	// Replace the URLs in the free-text fields
+	if opts.ReplaceURLs {
+		c.replaceURLs(opts.URLPlaceholder)
+	}
+}
+
+// replaceURLs replaces the URLs in all free-text fields with the placeholder (DefaultURLPlaceholder if empty)
+func (c *Content) replaceURLs(placeholder string) {
+	if placeholder == "" {
+		placeholder = DefaultURLPlaceholder
+	}
+
+	// Replace the URLs in a property field
+	replace := func(input property.String) property.String {
+		return property.String(urlRegex.ReplaceAllLiteralString(string(input), placeholder))
+	}
+
+	c.Description = replace(c.Description)
+	c.Personality = replace(c.Personality)
+	c.Scenario = replace(c.Scenario)
+	c.FirstMessage = replace(c.FirstMessage)
+	c.MessageExamples = replace(c.MessageExamples)
+	c.CreatorNotes = replace(c.CreatorNotes)
+	c.SystemPrompt = replace(c.SystemPrompt)
+	c.PostHistoryInstructions = replace(c.PostHistoryInstructions)
+	for index := range c.AlternateGreetings {
+		c.AlternateGreetings[index] = urlRegex.ReplaceAllLiteralString(c.AlternateGreetings[index], placeholder)
+	}
+	for index := range c.GroupGreetings {
+		c.GroupGreetings[index] = urlRegex.ReplaceAllLiteralString(c.GroupGreetings[index], placeholder)
+	}
+	for language, notes := range c.CreatorNotesMultilingual {
+		c.CreatorNotesMultilingual[language] = replace(notes)
+	}
+	c.DepthPrompt.Prompt = urlRegex.ReplaceAllLiteralString(c.DepthPrompt.Prompt, placeholder)
+}
+
+// deleteKeys removes the keys from the map (nil maps are ignored)
+func deleteKeys(m map[string]any, keys []string) {
+	for _, key := range keys {
+		delete(m, key)
+	}
+}
@@ -0,0 +1,153 @@
+package character
+
+import (
+	"testing"
+
+	"github.com/r3dpixel/card-parser/property"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// identifiedContent returns a content populated with creator-identifying fields
+func identifiedContent() *Content {
+	return &Content{
+		Name:                     "Alice",
+		Description:              "Alice is a knight. Art by https://example.com/artist?id=42.",
+		Personality:              "Brave (see www.example.org/alice), loyal",
+		Scenario:                 "A castle",
+		FirstMessage:             "Hello! Visit HTTP://EXAMPLE.NET",
+		CreatorNotes:             "Made by Bob - https://bob.example.com",
+		AlternateGreetings:       property.StringArray{"Hi from https://example.com", "Plain greeting"},
+		Creator:                  "Bob",
+		CreatorNotesMultilingual: map[string]property.String{"fr": "Fait par Bob"},
+		Source:                   property.StringArray{"https://example.com/cards/alice"},
+		SourceID:                 "source-42",
+		CharacterID:              "char-42",
+		PlatformID:               "platform-42",
+		DirectLink:               "https://example.com/alice",
+		DepthPrompt:              DepthPrompt{Prompt: "Remember https://example.com", Depth: 4},
+		Extensions: map[string]any{
+			"author":        "Bob",
+			"talkativeness": 0.5,
+			"fav":           true,
+		},
+		CharacterBook: &Book{
+			Name:       "Alice lore",
+			Extensions: map[string]any{"source": "https://example.com/book", "theme": "castle"},
+			Entries: []*BookEntry{
+				{
+					BookEntryCore: BookEntryCore{Content: "The castle https://example.com/castle"},
+					RawExtensions: map[string]any{"author": "Bob", "source": "chub", "weight": 10},
+				},
+				nil,
+			},
+		},
+	}
+}
+
+func TestContent_Anonymize(t *testing.T) {
+	tests := []struct {
+		name          string
+		opts          AnonymizeOptions
+		creator       property.String
+		notes         property.String
+		multilingual  map[string]property.String
+		description   property.String
+		personality   property.String
+		firstMessage  property.String
+		greeting      string
+		depthPrompt   string
+		bookEntryText property.String
+	}{
+		{
+			name:          "Default options",
+			opts:          AnonymizeOptions{},
+			creator:       AnonymousCreator,
+			description:   "Alice is a knight. Art by https://example.com/artist?id=42.",
+			personality:   "Brave (see www.example.org/alice), loyal",
+			firstMessage:  "Hello! Visit HTTP://EXAMPLE.NET",
+			greeting:      "Hi from https://example.com",
+			depthPrompt:   "Remember https://example.com",
+			bookEntryText: "The castle https://example.com/castle",
+		},
+		{
+			name:          "Blank creator and kept notes",
+			opts:          AnonymizeOptions{BlankCreator: true, KeepNotes: true},
+			creator:       "",
+			notes:         "Made by Bob - https://bob.example.com",
+			multilingual:  map[string]property.String{"fr": "Fait par Bob"},
+			description:   "Alice is a knight. Art by https://example.com/artist?id=42.",
+			personality:   "Brave (see www.example.org/alice), loyal",
+			firstMessage:  "Hello! Visit HTTP://EXAMPLE.NET",
+			greeting:      "Hi from https://example.com",
+			depthPrompt:   "Remember https://example.com",
+			bookEntryText: "The castle https://example.com/castle",
+		},
+		{
+			name:          "Replaced URLs",
+			opts:          AnonymizeOptions{KeepNotes: true, ReplaceURLs: true},
+			creator:       AnonymousCreator,
+			notes:         "Made by Bob - [link]",
+			multilingual:  map[string]property.String{"fr": "Fait par Bob"},
+			description:   "Alice is a knight. Art by [link].",
+			personality:   "Brave (see [link]), loyal",
+			firstMessage:  "Hello! Visit [link]",
+			greeting:      "Hi from [link]",
+			depthPrompt:   "Remember [link]",
+			bookEntryText: "The castle https://example.com/castle",
+		},
+		{
+			name:          "Custom URL placeholder",
+			opts:          AnonymizeOptions{ReplaceURLs: true, URLPlaceholder: "<url>"},
+			creator:       AnonymousCreator,
+			description:   "Alice is a knight. Art by <url>.",
+			personality:   "Brave (see <url>), loyal",
+			firstMessage:  "Hello! Visit <url>",
+			greeting:      "Hi from <url>",
+			depthPrompt:   "Remember <url>",
+			bookEntryText: "The castle https://example.com/castle",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			content := identifiedContent()
+			content.Anonymize(tt.opts)
+
+			// Identity fields
+			assert.Equal(t, tt.creator, content.Creator)
+			assert.Equal(t, tt.notes, content.CreatorNotes)
+			assert.Equal(t, tt.multilingual, content.CreatorNotesMultilingual)
+			assert.Empty(t, content.Source)
+			assert.Empty(t, content.SourceID)
+			assert.Empty(t, content.CharacterID)
+			assert.Empty(t, content.PlatformID)
+			assert.Empty(t, content.DirectLink)
+
+			// Free-text fields
+			assert.Equal(t, tt.description, content.Description)
+			assert.Equal(t, tt.personality, content.Personality)
+			assert.Equal(t, tt.firstMessage, content.FirstMessage)
+			assert.Equal(t, property.StringArray{tt.greeting, "Plain greeting"}, content.AlternateGreetings)
+			assert.Equal(t, tt.depthPrompt, content.DepthPrompt.Prompt)
+			assert.Equal(t, property.String("Alice"), content.Name)
+			assert.Equal(t, property.String("A castle"), content.Scenario)
+
+			// Extensions (identity keys removed, everything else untouched)
+			assert.Equal(t, map[string]any{"talkativeness": 0.5, "fav": true}, content.Extensions)
+			require.NotNil(t, content.CharacterBook)
+			assert.Equal(t, map[string]any{"theme": "castle"}, content.CharacterBook.Extensions)
+			assert.Equal(t, map[string]any{"weight": 10}, content.CharacterBook.Entries[0].RawExtensions)
+			assert.Equal(t, tt.bookEntryText, content.CharacterBook.Entries[0].Content)
+		})
+	}
+
+	t.Run("Without extensions and lorebook", func(t *testing.T) {
+		content := &Content{Creator: "Bob", Description: "www.example.com"}
+		content.Anonymize(AnonymizeOptions{ReplaceURLs: true})
+		assert.Equal(t, property.String(AnonymousCreator), content.Creator)
+		assert.Equal(t, property.String(DefaultURLPlaceholder), content.Description)
+		assert.Nil(t, content.Extensions)
+		assert.Nil(t, content.CharacterBook)
+	})
+}
@@ -1,11 +1,116 @@
 package character
 
-import "github.com/r3dpixel/card-parser/property"
+import (
+	"path"
+	"strings"
+
+	"github.com/r3dpixel/card-parser/internal/codec"
+	"github.com/r3dpixel/card-parser/property"
+	"github.com/r3dpixel/toolkit/stringsx"
+)
+
+// Asset URI schemes and defaults (V3 spec)
+const (
+	EmbeddedAssetScheme  = "embeded://"  // Asset embedded in the card archive (the spec typo is intended)
+	EmbeddedAssetAlias   = "embedded://" // Common spelling of the embedded scheme
+	DefaultAssetURI      = "ccdefault:"  // Default asset of the frontend (e.g. the PNG image itself)
+	DefaultAssetName     = "main"        // Name of the main asset
+	DefaultAssetExt      = "png"         // Extension of the default asset
+	UnknownAssetExt      = "unknown"     // Extension used when it cannot be derived from the URI
+	remoteAssetScheme    = "http://"
+	secureRemoteScheme   = "https://"
+	dataAssetScheme      = "data:"
+	assetExtensionPrefix = "."
+)
+
+// assetAlias alias for Asset to avoid circular references
+type assetAlias Asset
 
 // Asset asset structure of a V3 chara card
 type Asset struct {
-	Type      property.String `json:"type"`
-	URI       property.String `json:"uri"`
-	Name      property.String `json:"name"`
-	Extension property.String `json:"ext"`
+	Type      property.AssetType `json:"type"`
+	URI       property.String    `json:"uri"`
+	Name      property.String    `json:"name"`
+	Extension property.String    `json:"ext"`
+}
+
+// DefaultAsset returns the default main icon asset of a V3 chara card
+func DefaultAsset() Asset {
+	return Asset{
+		Type:      property.IconAsset,
+		URI:       DefaultAssetURI,
+		Name:      DefaultAssetName,
+		Extension: DefaultAssetExt,
+	}
+}
+
+// UnmarshalJSON unmarshals JSON into the Asset, filling missing name/ext from the URI
+func (a *Asset) UnmarshalJSON(data []byte) error {
+	// Unmarshal from JSON using the JSON codec
+	if err := codec.Unmarshal(data, (*assetAlias)(a)); err != nil {
+		return err
+	}
+
+	// A missing type is the default type
+	if a.Type == "" {
+		a.Type = property.DefaultAssetType
+	}
+
+	// Fill the missing name and extension
+	a.FillDefaults()
+
+	// Decoding is complete
+	return nil
+}
+
+// FillDefaults fills a blank name (URI base name, or "main" for default assets) and a blank extension (URI extension)
+func (a *Asset) FillDefaults() {
+	// Split the URI base name into name and extension
+	base := ""
+	if !a.IsDefault() && !strings.HasPrefix(string(a.URI), dataAssetScheme) {
+		base = path.Base(strings.TrimSpace(string(a.URI)))
+		if base == "." || base == "/" || strings.HasSuffix(base, ":") {
+			base = ""
+		}
+	}
+	ext := path.Ext(base)
+
+	// Fill the name
+	if stringsx.IsBlank(string(a.Name)) {
+		switch {
+		case a.IsDefault() || base == "":
+			a.Name = DefaultAssetName
+		default:
+			a.Name = property.String(strings.TrimSuffix(base, ext))
+		}
+	}
+
+	// Fill the extension
+	if stringsx.IsBlank(string(a.Extension)) {
+		switch {
+		case a.IsDefault():
+			a.Extension = DefaultAssetExt
+		case ext != "":
+			a.Extension = property.String(strings.ToLower(strings.TrimPrefix(ext, assetExtensionPrefix)))
+		default:
+			a.Extension = UnknownAssetExt
+		}
+	}
+}
+
+// IsEmbedded returns true if the asset is embedded in the card archive (embeded:// URI)
+func (a *Asset) IsEmbedded() bool {
+	uri := strings.TrimSpace(string(a.URI))
+	return strings.HasPrefix(uri, EmbeddedAssetScheme) || strings.HasPrefix(uri, EmbeddedAssetAlias)
+}
+
+// IsRemote returns true if the asset is fetched from a remote location (http:// or https:// URI)
+func (a *Asset) IsRemote() bool {
+	uri := strings.ToLower(strings.TrimSpace(string(a.URI)))
+	return strings.HasPrefix(uri, remoteAssetScheme) || strings.HasPrefix(uri, secureRemoteScheme)
+}
+
+// IsDefault returns true if the asset is the default asset of the frontend (ccdefault: URI)
+func (a *Asset) IsDefault() bool {
+	return strings.TrimSpace(string(a.URI)) == DefaultAssetURI
 }
@@ -0,0 +1,122 @@
+package character
+
+import (
+	"testing"
+
+	"github.com/r3dpixel/card-parser/property"
+	"github.com/r3dpixel/toolkit/sonicx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDefaultAsset(t *testing.T) {
+	asset := DefaultAsset()
+	assert.Equal(t, property.IconAsset, asset.Type)
+	assert.Equal(t, property.String("ccdefault:"), asset.URI)
+	assert.Equal(t, property.String("main"), asset.Name)
+	assert.Equal(t, property.String("png"), asset.Extension)
+	assert.True(t, asset.IsDefault())
+	assert.False(t, asset.IsEmbedded())
+	assert.False(t, asset.IsRemote())
+}
+
+func TestAsset_URISchemes(t *testing.T) {
+	tests := []struct {
+		uri       string
+		embedded  bool
+		remote    bool
+		isDefault bool
+	}{
+		{"ccdefault:", false, false, true},
+		{"embeded://assets/icon/images/main.png", true, false, false},
+		{"embedded://assets/emotion/happy.webp", true, false, false},
+		{"https://example.com/bg.jpg", false, true, false},
+		{"HTTP://example.com/bg.jpg", false, true, false},
+		{"data:image/png;base64,AAAA", false, false, false},
+		{"", false, false, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.uri, func(t *testing.T) {
+			asset := Asset{URI: property.String(tt.uri)}
+			assert.Equal(t, tt.embedded, asset.IsEmbedded())
+			assert.Equal(t, tt.remote, asset.IsRemote())
+			assert.Equal(t, tt.isDefault, asset.IsDefault())
+		})
+	}
+}
+
+func TestAsset_UnmarshalJSON(t *testing.T) {
+	tests := []struct {
+		name     string
+		jsonData string
+		expected Asset
+	}{
+		{
+			name:     "complete asset",
+			jsonData: `{"type":"emotion","uri":"embeded://assets/emotion/happy.png","name":"joy","ext":"webp"}`,
+			expected: Asset{Type: property.EmotionAsset, URI: "embeded://assets/emotion/happy.png", Name: "joy", Extension: "webp"},
+		},
+		{
+			name:     "missing name and ext (embedded)",
+			jsonData: `{"type":"Emotions","uri":"embeded://assets/emotion/Happy.PNG"}`,
+			expected: Asset{Type: property.EmotionAsset, URI: "embeded://assets/emotion/Happy.PNG", Name: "Happy", Extension: "png"},
+		},
+		{
+			name:     "missing name and ext (default)",
+			jsonData: `{"type":"icon","uri":"ccdefault:"}`,
+			expected: DefaultAsset(),
+		},
+		{
+			name:     "missing name and ext (no extension)",
+			jsonData: `{"type":"background","uri":"https://example.com/backgrounds/forest"}`,
+			expected: Asset{Type: property.BackgroundAsset, URI: "https://example.com/backgrounds/forest", Name: "forest", Extension: "unknown"},
+		},
+		{
+			name:     "missing type",
+			jsonData: `{"uri":"https://example.com/x.jpg","name":"x","ext":"jpg"}`,
+			expected: Asset{Type: property.OtherAsset, URI: "https://example.com/x.jpg", Name: "x", Extension: "jpg"},
+		},
+		{
+			name:     "empty asset",
+			jsonData: `{}`,
+			expected: Asset{Type: property.OtherAsset, Name: "main", Extension: "unknown"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var asset Asset
+			require.NoError(t, sonicx.Config.UnmarshalFromString(tt.jsonData, &asset))
+			assert.Equal(t, tt.expected, asset)
+		})
+	}
+
+	t.Run("invalid asset", func(t *testing.T) {
+		var asset Asset
+		assert.Error(t, sonicx.Config.UnmarshalFromString(`"asset"`, &asset))
+	})
+}
+
+func TestAsset_RoundTrip(t *testing.T) {
+	input := `{"spec":"chara_card_v3","spec_version":"3.0","data":{"name":"Assets","assets":[
+		{"type":"icon","uri":"ccdefault:","name":"main","ext":"png"},
+		{"type":"background","uri":"embeded://assets/background/forest.jpg"},
+		{"type":"user_icon","uri":"https://example.com/me.webp","name":"me","ext":"webp"},
+		{"type":"expression","uri":"embeded://assets/emotion/angry.png","name":"angry"}
+	]}}`
+
+	sheet, err := FromBytes([]byte(input))
+	require.NoError(t, err)
+	require.Len(t, sheet.Assets, 4)
+	assert.Equal(t, property.String("forest"), sheet.Assets[1].Name)
+	assert.Equal(t, property.String("jpg"), sheet.Assets[1].Extension)
+	assert.Equal(t, property.EmotionAsset, sheet.Assets[3].Type)
+
+	data, err := sheet.ToBytes()
+	require.NoError(t, err)
+	roundTrip, err := FromBytes(data)
+	require.NoError(t, err)
+	assert.Equal(t, sheet.Assets, roundTrip.Assets)
+	assert.Contains(t, string(data), `"type":"emotion"`)
+}
@@ -0,0 +1,163 @@
+package character
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/r3dpixel/card-parser/property"
+	"github.com/r3dpixel/toolkit/bytex"
+	"github.com/r3dpixel/toolkit/reqx"
+)
+
+// Defaults of the asset resolution (see AssetResolveOptions)
+const (
+	DefaultMaxAssetBytes    int64 = 32 * bytex.MiB // Maximum size of a downloaded asset
+	DefaultAssetConcurrency int   = 4              // Number of assets downloaded concurrently
+)
+
+// ErrAssetDownload is returned (in ResolvedAsset.Err) when an asset cannot be downloaded (request error or
+// non-2xx status)
+var ErrAssetDownload = errors.New("asset download failed")
+
+// ErrAssetTooLarge is returned (in ResolvedAsset.Err) when an asset exceeds the maximum asset size
+var ErrAssetTooLarge = errors.New("asset too large")
+
+// AssetResolveOptions options of Content.ResolveAssets
+type AssetResolveOptions struct {
+	MaxAssetBytes int64 // Maximum size of a downloaded asset (default: DefaultMaxAssetBytes, negative for no limit)
+	Concurrency   int   // Number of assets downloaded concurrently (default: DefaultAssetConcurrency)
+	// Rename returns the new URI of a downloaded asset (blank to keep the URI), and whether its bytes are kept in the
+	// ResolvedAsset; called from multiple goroutines (without it, the URIs are kept and the bytes are always kept)
+	Rename func(asset Asset, data []byte) (newURI string, keepBytes bool)
+}
+
+// ResolvedAsset outcome of the download of a remote asset
+type ResolvedAsset struct {
+	Index       int    // Index of the asset in Content.Assets
+	OriginalURI string // URI the asset was downloaded from
+	Asset       Asset  // Asset after the resolution (with the new URI if renamed)
+	Size        int64  // Size of the downloaded bytes
+	Hash        string // Hash of the downloaded bytes (e.g. sha256:<hex>)
+	Data        []byte // Downloaded bytes (nil if not kept by Rename)
+	Err         error  // Failure reason (nil if the asset was downloaded)
+}
+
+// withDefaults returns the options with the defaults applied
+func (o AssetResolveOptions) withDefaults() AssetResolveOptions {
+	if o.MaxAssetBytes == 0 {
+		o.MaxAssetBytes = DefaultMaxAssetBytes
+	}
+	if o.Concurrency <= 0 {
+		o.Concurrency = DefaultAssetConcurrency
+	}
+	return o
+}
+
+// ResolveAssets downloads the remote (http:// and https://) assets concurrently, and returns their outcome in asset
+// order; other assets (e.g. ccdefault:, embeded:// or data: URIs) are skipped
+// Per-asset failures are collected in ResolvedAsset.Err instead of failing the whole card; the error is only set if
+// the context is done (the outcome of every asset is still returned)
+// The URIs returned by Rename are written to the assets of the content once every download is done
+func (c *Content) ResolveAssets(ctx context.Context, client *reqx.Client, opts AssetResolveOptions) ([]ResolvedAsset, error) {
+	opts = opts.withDefaults()
+
+	// Collect the remote assets
+	var resolved []ResolvedAsset
+	for index, asset := range c.Assets {
+		if asset.IsRemote() {
+			resolved = append(resolved, ResolvedAsset{Index: index, OriginalURI: strings.TrimSpace(string(asset.URI)), Asset: asset})
+		}
+	}
+
+	// Download the assets (each worker fills the outcome of the assets it picks)
+	indexes := make(chan int)
+	var workers sync.WaitGroup
+	for range min(opts.Concurrency, len(resolved)) {
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			for index := range indexes {
+				resolved[index].download(ctx, client, opts)
+			}
+		}()
+	}
+	for index := range resolved {
+		indexes <- index
+	}
+	close(indexes)
+	workers.Wait()
+
+	// Rewrite the renamed URIs
+	for _, asset := range resolved {
+		if asset.Err == nil {
+			c.Assets[asset.Index].URI = asset.Asset.URI
+		}
+	}
+	return resolved, ctx.Err()
+}
+
+// download downloads the asset, and fills its size, hash, data and new URI (or the failure reason)
+func (r *ResolvedAsset) download(ctx context.Context, client *reqx.Client, opts AssetResolveOptions) {
+	// Download the bytes
+	data, err := fetchAsset(ctx, client, r.OriginalURI, opts.MaxAssetBytes)
+	if err != nil {
+		r.Err = err
+		return
+	}
+
+	// Hash the bytes
+	sum := sha256.Sum256(data)
+	r.Size = int64(len(data))
+	r.Hash = fingerprintAlgorithm + ":" + hex.EncodeToString(sum[:])
+	r.Data = data
+
+	// Rename the asset
+	if opts.Rename != nil {
+		newURI, keepBytes := opts.Rename(r.Asset, data)
+		if strings.TrimSpace(newURI) != "" {
+			r.Asset.URI = property.String(newURI)
+		}
+		if !keepBytes {
+			r.Data = nil
+		}
+	}
+}
+
+// fetchAsset downloads the bytes of the URL, bounded by the maximum size (negative for no limit)
+func fetchAsset(ctx context.Context, client *reqx.Client, url string, maxBytes int64) ([]byte, error) {
+	// Send the request
+	response, err := client.R().SetContext(ctx).Get(url)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s: %w", ErrAssetDownload, url, err)
+	}
+	defer response.Body.Close()
+
+	// Check the status and the declared size
+	if response.StatusCode < http.StatusOK || response.StatusCode >= http.StatusMultipleChoices {
+		return nil, fmt.Errorf("%w: %s: status %d", ErrAssetDownload, url, response.StatusCode)
+	}
+	if maxBytes >= 0 && response.ContentLength > maxBytes {
+		return nil, fmt.Errorf("%w: %s declares %d bytes (maximum %d)", ErrAssetTooLarge, url, response.ContentLength, maxBytes)
+	}
+
+	// Read the body (one byte over the limit to detect larger bodies)
+	body := io.Reader(response.Body)
+	if maxBytes >= 0 {
+		body = io.LimitReader(body, maxBytes+1)
+	}
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s: %w", ErrAssetDownload, url, err)
+	}
+	if maxBytes >= 0 && int64(len(data)) > maxBytes {
+		return nil, fmt.Errorf("%w: %s exceeds %d bytes", ErrAssetTooLarge, url, maxBytes)
+	}
+	return data, nil
+}
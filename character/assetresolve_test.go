@@ -0,0 +1,126 @@
+package character
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/r3dpixel/card-parser/property"
+	"github.com/r3dpixel/toolkit/reqx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// assetServer serves two images (/avatar.png and /happy.webp), every other path is not found
+func assetServer(t *testing.T) (*httptest.Server, map[string][]byte) {
+	t.Helper()
+	images := map[string][]byte{
+		"/avatar.png": []byte("\x89PNG\r\n\x1a\navatar"),
+		"/happy.webp": []byte("RIFF\x00\x00\x00\x00WEBPhappy"),
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, ok := images[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = w.Write(data)
+	}))
+	t.Cleanup(server.Close)
+	return server, images
+}
+
+func TestContent_ResolveAssets(t *testing.T) {
+	server, images := assetServer(t)
+	client := reqx.NewClient(reqx.Options{})
+	hash := func(data []byte) string {
+		sum := sha256.Sum256(data)
+		return "sha256:" + hex.EncodeToString(sum[:])
+	}
+	content := func() *Content {
+		return &Content{Assets: []Asset{
+			DefaultAsset(),
+			{Type: property.IconAsset, URI: property.String(server.URL + "/avatar.png"), Name: "avatar", Extension: "png"},
+			{Type: "emotion", URI: "embeded://assets/sad.png", Name: "sad", Extension: "png"},
+			{Type: "emotion", URI: property.String(server.URL + "/happy.webp"), Name: "happy", Extension: "webp"},
+			{Type: "emotion", URI: property.String(server.URL + "/missing.png"), Name: "missing", Extension: "png"},
+		}}
+	}
+
+	t.Run("Download and hash", func(t *testing.T) {
+		c := content()
+		resolved, err := c.ResolveAssets(context.Background(), client, AssetResolveOptions{Concurrency: 2})
+		require.NoError(t, err)
+		require.Len(t, resolved, 3)
+
+		// Downloaded images
+		for position, path := range map[int]string{0: "/avatar.png", 1: "/happy.webp"} {
+			assert.NoError(t, resolved[position].Err)
+			assert.Equal(t, server.URL+path, resolved[position].OriginalURI)
+			assert.Equal(t, images[path], resolved[position].Data)
+			assert.Equal(t, int64(len(images[path])), resolved[position].Size)
+			assert.Equal(t, hash(images[path]), resolved[position].Hash)
+		}
+		assert.Equal(t, []int{1, 3, 4}, []int{resolved[0].Index, resolved[1].Index, resolved[2].Index})
+
+		// Missing image
+		assert.ErrorIs(t, resolved[2].Err, ErrAssetDownload)
+		assert.Empty(t, resolved[2].Hash)
+
+		// The assets are unchanged without a naming scheme
+		assert.Equal(t, content().Assets, c.Assets)
+	})
+
+	t.Run("Rename", func(t *testing.T) {
+		c := content()
+		var calls atomic.Int32
+		resolved, err := c.ResolveAssets(context.Background(), client, AssetResolveOptions{
+			Rename: func(asset Asset, data []byte) (string, bool) {
+				calls.Add(1)
+				return "embeded://assets/" + string(asset.Name) + "." + string(asset.Extension), asset.Name == "avatar"
+			},
+		})
+		require.NoError(t, err)
+		assert.EqualValues(t, 2, calls.Load())
+
+		// The downloaded assets are renamed, the bytes are kept for the avatar only
+		assert.Equal(t, property.String("embeded://assets/avatar.png"), c.Assets[1].URI)
+		assert.Equal(t, property.String("embeded://assets/happy.webp"), c.Assets[3].URI)
+		assert.Equal(t, property.String(server.URL+"/missing.png"), c.Assets[4].URI)
+		assert.Equal(t, c.Assets[1], resolved[0].Asset)
+		assert.Equal(t, images["/avatar.png"], resolved[0].Data)
+		assert.Nil(t, resolved[1].Data)
+		assert.NotEmpty(t, resolved[1].Hash)
+	})
+
+	t.Run("Size cap", func(t *testing.T) {
+		resolved, err := content().ResolveAssets(context.Background(), client, AssetResolveOptions{MaxAssetBytes: 16})
+		require.NoError(t, err)
+		assert.NoError(t, resolved[0].Err)
+		assert.ErrorIs(t, resolved[1].Err, ErrAssetTooLarge)
+		assert.ErrorIs(t, resolved[2].Err, ErrAssetDownload)
+	})
+
+	t.Run("Cancelled context", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		resolved, err := content().ResolveAssets(ctx, client, AssetResolveOptions{})
+		assert.ErrorIs(t, err, context.Canceled)
+		require.Len(t, resolved, 3)
+		for _, asset := range resolved {
+			assert.ErrorIs(t, asset.Err, ErrAssetDownload)
+		}
+	})
+
+	t.Run("No remote assets", func(t *testing.T) {
+		c := &Content{Assets: []Asset{DefaultAsset(), {URI: "data:image/png;base64,AAAA"}}}
+		resolved, err := c.ResolveAssets(context.Background(), client, AssetResolveOptions{})
+		require.NoError(t, err)
+		assert.Empty(t, resolved)
+		assert.Equal(t, property.String("data:image/png;base64,AAAA"), c.Assets[1].URI)
+	})
+}
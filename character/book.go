@@ -1,7 +1,15 @@
 package character
 
 import (
+	"bytes"
+	"cmp"
+	"encoding/json"
+	"slices"
+	"strconv"
+
+	"github.com/r3dpixel/card-parser/internal/codec"
 	"github.com/r3dpixel/card-parser/property"
+	"github.com/r3dpixel/toolkit/stringsx"
 )
 
 const (
@@ -10,6 +18,9 @@ const (
 	BookNamePlaceholder             = `<<||-@PLACEHOLDER@-||>>`
 )
 
+// bookAlias alias for Book to avoid circular references
+type bookAlias Book
+
 // Book lorebook structure of a V3 chara card
 type Book struct {
 	Name              property.String  `json:"name"`
@@ -18,12 +29,160 @@ type Book struct {
 	TokenBudget       property.Integer `json:"token_budget"`
 	RecursiveScanning property.Bool    `json:"recursive_scanning"`
 	Extensions        map[string]any   `json:"extensions,omitempty"`
+	Settings          BookExtensions   `json:"-"` // Typed extensions (written into the extensions)
 	Entries           []*BookEntry     `json:"entries"`
 }
 
-// DefaultBook creates an empty book with an initialized entry list
+// bookStragglerWrapper top level values of the book extensions (possible stragglers, see Book.UnmarshalJSON)
+type bookStragglerWrapper struct {
+	TokenBudgetCap    json.RawMessage `json:"token_budget_cap"`
+	InsertionStrategy json.RawMessage `json:"insertion_strategy"`
+	MinActivations    json.RawMessage `json:"min_activations"`
+	MaxDepth          json.RawMessage `json:"max_depth"`
+	CaseSensitive     json.RawMessage `json:"case_sensitive"`
+}
+
+// DefaultBook creates an empty book with the default typed extensions
 func DefaultBook() *Book {
-	return &Book{}
+	return &Book{Settings: DefaultBookExtensions()}
+}
+
+// MarshalJSON marshals the Book to JSON, with the typed extensions merged into (a copy of) the extensions
+// A nil entry list is written as an empty list
+func (b *Book) MarshalJSON() ([]byte, error) {
+	extensions, err := b.insertExtensions()
+	if err != nil {
+		return nil, err
+	}
+	entries := b.Entries
+	if entries == nil {
+		entries = []*BookEntry{}
+	}
+	return codec.Marshal(&struct {
+		*bookAlias
+		Extensions map[string]any `json:"extensions"`
+		Entries    []*BookEntry   `json:"entries"`
+	}{bookAlias: (*bookAlias)(b), Extensions: extensions, Entries: entries})
+}
+
+// UnmarshalJSON unmarshals JSON into the Book using the JSON codec
+// Entries are accepted as an array, or as an object keyed by index (SillyTavern world-info layout),
+// in which case the entries are ordered by numeric key (non-numeric keys last) and the key is used as fallback ID
+// The typed extensions are extracted from the extensions (defaulting to DefaultBookExtensions), and the straggler
+// extensions found at the book top level are used when missing from the extensions
+func (b *Book) UnmarshalJSON(data []byte) error {
+	// Unmarshal the book fields, keeping the entries and the stragglers raw
+	wrapper := struct {
+		*bookAlias
+		bookStragglerWrapper
+		Entries json.RawMessage `json:"entries"`
+	}{bookAlias: (*bookAlias)(b)}
+	if err := codec.Unmarshal(data, &wrapper); err != nil {
+		return err
+	}
+
+	// Extract the typed extensions, and override them with the stragglers missing from the extensions
+	b.Settings = DefaultBookExtensions()
+	stragglers := map[BookExtension]json.RawMessage{
+		BookTokenBudgetCap:    wrapper.TokenBudgetCap,
+		BookInsertionStrategy: wrapper.InsertionStrategy,
+		BookMinActivations:    wrapper.MinActivations,
+		BookMaxDepth:          wrapper.MaxDepth,
+		BookCaseSensitive:     wrapper.CaseSensitive,
+	}
+	for _, key := range bookStragglers {
+		if _, isExtension := b.Extensions[key]; isExtension || len(stragglers[key]) == 0 {
+			continue
+		}
+		if err := codec.HandlePrimitive(stragglers[key], b.Settings.field(key)); err != nil {
+			return err
+		}
+	}
+	b.extractExtensions()
+
+	// Unmarshal the entries (array form)
+	entries := bytes.TrimSpace(wrapper.Entries)
+	if len(entries) == 0 || entries[0] != '{' {
+		b.Entries = nil
+		if len(entries) == 0 {
+			return nil
+		}
+		return codec.Unmarshal(entries, &b.Entries)
+	}
+
+	// Unmarshal the entries (map form)
+	var entryMap map[string]*BookEntry
+	if err := codec.Unmarshal(entries, &entryMap); err != nil {
+		return err
+	}
+	b.Entries = make([]*BookEntry, 0, len(entryMap))
+	for _, key := range sortedEntryKeys(entryMap) {
+		entry := entryMap[key]
+		if entry == nil {
+			continue
+		}
+		// Use the key as ID when the entry has none
+		if entry.ID.IntValue == nil && entry.ID.StringValue == nil {
+			entry.ID.OnString(key)
+		}
+		b.Entries = append(b.Entries, entry)
+	}
+
+	// Decoding is complete
+	return nil
+}
+
+// sortedEntryKeys returns the keys of a map-form entry list, numeric keys first (in numeric order),
+// followed by the non-numeric keys (in lexical order)
+func sortedEntryKeys[T any](entries map[string]T) []string {
+	keys := make([]string, 0, len(entries))
+	for key := range entries {
+		keys = append(keys, key)
+	}
+	slices.SortFunc(keys, func(a, b string) int {
+		aIndex, aErr := strconv.Atoi(a)
+		bIndex, bErr := strconv.Atoi(b)
+		switch {
+		case aErr == nil && bErr == nil:
+			return cmp.Or(cmp.Compare(aIndex, bIndex), cmp.Compare(a, b))
+		case aErr == nil:
+			return -1
+		case bErr == nil:
+			return 1
+		default:
+			return cmp.Compare(a, b)
+		}
+	})
+	return keys
+}
+
+// IsEmpty returns true if the book carries no data (no entries, no name, no description and no extensions)
+// NOTE: Scan depth, token budget and recursive scanning are settings, and they are not considered data
+func (b *Book) IsEmpty() bool {
+	return b == nil ||
+		len(b.Entries) == 0 &&
+			stringsx.IsBlank(string(b.Name)) &&
+			stringsx.IsBlank(string(b.Description)) &&
+			len(b.Extensions) == 0
+}
+
+// Clone returns a deep copy of the book (entries and extensions are not shared)
+func (b *Book) Clone() *Book {
+	// Copy the value fields
+	clone := *b
+	// Copy the extensions
+	clone.Extensions = cloneMap(b.Extensions)
+	// Copy the entries
+	if b.Entries != nil {
+		clone.Entries = make([]*BookEntry, len(b.Entries))
+		for index, entry := range b.Entries {
+			if entry != nil {
+				clone.Entries[index] = entry.Clone()
+			}
+		}
+	}
+	// Return the copy
+	return &clone
 }
 
 // NormalizeSymbols normalizes the book name and description, and all book entries
@@ -21,6 +21,29 @@ func TestEmptyBook(t *testing.T) {
 	assert.False(t, bool(book.RecursiveScanning))
 }
 
+func TestBook_IsEmpty(t *testing.T) {
+	tests := []struct {
+		name     string
+		book     *Book
+		expected bool
+	}{
+		{name: "nil book", book: nil, expected: true},
+		{name: "default book", book: DefaultBook(), expected: true},
+		{name: "settings only", book: &Book{ScanDepth: 5, TokenBudget: 100, RecursiveScanning: true}, expected: true},
+		{name: "blank name", book: &Book{Name: "   "}, expected: true},
+		{name: "named book", book: &Book{Name: "Book"}, expected: false},
+		{name: "description only", book: &Book{Description: "Description"}, expected: false},
+		{name: "extensions only", book: &Book{Extensions: map[string]any{"key": "value"}}, expected: false},
+		{name: "with entries", book: &Book{Entries: []*BookEntry{DefaultBookEntry()}}, expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.book.IsEmpty())
+		})
+	}
+}
+
 func TestBook_NormalizeSymbols(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -100,6 +123,13 @@ func TestBook_JSONMarshal(t *testing.T) {
 				"scan_depth": 0,
 				"token_budget": 0,
 				"recursive_scanning": false,
+				"extensions": {
+					"token_budget_cap": 0,
+					"insertion_strategy": 1,
+					"min_activations": 0,
+					"max_depth": 0,
+					"case_sensitive": false
+				},
 				"entries": []
 			}`,
 		},
@@ -119,6 +149,13 @@ func TestBook_JSONMarshal(t *testing.T) {
 				"scan_depth": 5,
 				"token_budget": 1000,
 				"recursive_scanning": true,
+				"extensions": {
+					"token_budget_cap": 0,
+					"insertion_strategy": 0,
+					"min_activations": 0,
+					"max_depth": 0,
+					"case_sensitive": false
+				},
 				"entries": []
 			}`,
 		},
@@ -141,7 +178,12 @@ func TestBook_JSONMarshal(t *testing.T) {
 				"recursive_scanning": false,
 				"extensions": {
 					"custom_field": "custom_value",
-					"number_field": 42
+					"number_field": 42,
+					"token_budget_cap": 0,
+					"insertion_strategy": 0,
+					"min_activations": 0,
+					"max_depth": 0,
+					"case_sensitive": false
 				},
 				"entries": []
 			}`,
@@ -178,6 +220,13 @@ func TestBook_JSONUnmarshal(t *testing.T) {
 				"scan_depth": 5,
 				"token_budget": 1000,
 				"recursive_scanning": true,
+				"extensions": {
+					"token_budget_cap": 0,
+					"insertion_strategy": 0,
+					"min_activations": 0,
+					"max_depth": 0,
+					"case_sensitive": false
+				},
 				"entries": []
 			}`,
 			expected: &Book{
@@ -199,7 +248,12 @@ func TestBook_JSONUnmarshal(t *testing.T) {
 				"recursive_scanning": false,
 				"extensions": {
 					"custom_field": "custom_value",
-					"number_field": 42
+					"number_field": 42,
+					"token_budget_cap": 0,
+					"insertion_strategy": 0,
+					"min_activations": 0,
+					"max_depth": 0,
+					"case_sensitive": false
 				},
 				"entries": []
 			}`,
@@ -287,6 +341,95 @@ func TestBook_JSONRoundTrip(t *testing.T) {
 	assert.Equal(t, float64(123), roundTrip.Extensions["numeric"]) // JSON converts numbers to float64
 }
 
+func TestBook_JSONUnmarshal_MapEntries(t *testing.T) {
+	tests := []struct {
+		name        string
+		jsonData    string
+		expectedIDs []any
+		contents    []string
+	}{
+		{
+			name:        "out of order numeric keys",
+			jsonData:    `{"name":"World","entries":{"10":{"content":"ten"},"2":{"content":"two"},"0":{"content":"zero"}}}`,
+			expectedIDs: []any{0, 2, 10},
+			contents:    []string{"zero", "two", "ten"},
+		},
+		{
+			name:        "non-numeric keys are last",
+			jsonData:    `{"entries":{"beta":{"content":"b"},"1":{"content":"one"},"alpha":{"content":"a"}}}`,
+			expectedIDs: []any{1, "alpha", "beta"},
+			contents:    []string{"one", "a", "b"},
+		},
+		{
+			name:        "entry IDs win over keys",
+			jsonData:    `{"entries":{"0":{"id":7,"content":"seven"},"1":{"content":"one"}}}`,
+			expectedIDs: []any{7, 1},
+			contents:    []string{"seven", "one"},
+		},
+		{
+			name:        "null entries are dropped",
+			jsonData:    `{"entries":{"0":null,"1":{"content":"one"}}}`,
+			expectedIDs: []any{1},
+			contents:    []string{"one"},
+		},
+		{
+			name:        "array form",
+			jsonData:    `{"entries":[{"id":3,"content":"three"},{"id":1,"content":"one"}]}`,
+			expectedIDs: []any{3, 1},
+			contents:    []string{"three", "one"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var book Book
+			require.NoError(t, sonicx.Config.UnmarshalFromString(tt.jsonData, &book))
+			require.Len(t, book.Entries, len(tt.expectedIDs))
+			for index, entry := range book.Entries {
+				switch id := tt.expectedIDs[index].(type) {
+				case int:
+					require.NotNil(t, entry.ID.IntValue)
+					assert.Equal(t, id, *entry.ID.IntValue)
+				case string:
+					require.NotNil(t, entry.ID.StringValue)
+					assert.Equal(t, id, *entry.ID.StringValue)
+				}
+				assert.Equal(t, tt.contents[index], string(entry.Content))
+			}
+
+			// Marshalling emits an array, which decodes to the same entries
+			data, err := sonicx.Config.Marshal(&book)
+			require.NoError(t, err)
+			var raw struct {
+				Entries []map[string]any `json:"entries"`
+			}
+			require.NoError(t, sonicx.Config.Unmarshal(data, &raw))
+			assert.Len(t, raw.Entries, len(tt.contents))
+
+			var roundTrip Book
+			require.NoError(t, sonicx.Config.Unmarshal(data, &roundTrip))
+			require.Len(t, roundTrip.Entries, len(book.Entries))
+			for index, entry := range roundTrip.Entries {
+				assert.Equal(t, book.Entries[index].BookEntryCore, entry.BookEntryCore)
+				assert.Equal(t, book.Entries[index].Extensions, entry.Extensions)
+			}
+		})
+	}
+
+	t.Run("invalid entries", func(t *testing.T) {
+		var book Book
+		assert.Error(t, sonicx.Config.UnmarshalFromString(`{"entries":{"0":"not an entry"}}`, &book))
+	})
+
+	t.Run("missing and null entries", func(t *testing.T) {
+		var book Book
+		require.NoError(t, sonicx.Config.UnmarshalFromString(`{"name":"World"}`, &book))
+		assert.Nil(t, book.Entries)
+		require.NoError(t, sonicx.Config.UnmarshalFromString(`{"name":"World","entries":null}`, &book))
+		assert.Nil(t, book.Entries)
+	})
+}
+
 func TestBook_JSONInvalidData(t *testing.T) {
 	tests := []struct {
 		name     string
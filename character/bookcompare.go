@@ -0,0 +1,70 @@
+package character
+
+import (
+	"cmp"
+	"slices"
+	"strings"
+
+	gcmp "github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+)
+
+// BookCompareOptions options of the book comparison (DeepEquals and Diff)
+type BookCompareOptions struct {
+	IgnoreEntryOrder bool // Compare the entries regardless of their order (sorted by ID, comment, content, keys)
+}
+
+// DeepEquals returns true if the two books are deeply equal, with the same semantics as Sheet.DeepEquals
+// (the entries are compared in order, unless IgnoreEntryOrder is set)
+func (b *Book) DeepEquals(other *Book, opts ...BookCompareOptions) bool {
+	return gcmp.Equal(b, other, bookCmpOptions(opts)...)
+}
+
+// Diff returns the fields that differ between the two books (JSON paths relative to the book), using the same
+// comparison as DeepEquals; with IgnoreEntryOrder, the entries are indexed in their sorted order
+func (b *Book) Diff(other *Book, opts ...BookCompareOptions) []FieldDiff {
+	reporter := &diffReporter{diffs: []FieldDiff{}}
+	gcmp.Equal(b, other, append(bookCmpOptions(opts), gcmp.Reporter(reporter))...)
+	return reporter.diffs
+}
+
+// DeepEquals returns true if the two entries are deeply equal, with the same semantics as Sheet.DeepEquals
+func (e *BookEntry) DeepEquals(other *BookEntry) bool {
+	return gcmp.Equal(e, other, cmpOptions...)
+}
+
+// Diff returns the fields that differ between the two entries (JSON paths relative to the entry), using the same
+// comparison as DeepEquals
+func (e *BookEntry) Diff(other *BookEntry) []FieldDiff {
+	reporter := &diffReporter{diffs: []FieldDiff{}}
+	gcmp.Equal(e, other, append(cmpOptions, gcmp.Reporter(reporter))...)
+	return reporter.diffs
+}
+
+// bookCmpOptions returns the comparison options of the first of the given book options
+func bookCmpOptions(opts []BookCompareOptions) []gcmp.Option {
+	options := slices.Clone(cmpOptions)
+	if len(opts) > 0 && opts[0].IgnoreEntryOrder {
+		options = append(options, cmpopts.SortSlices(entryLess))
+	}
+	return options
+}
+
+// entryLess orders the entries by a stable key (ID, comment, content, then the sorted keys), nil entries first
+func entryLess(a, b *BookEntry) bool {
+	if a == nil || b == nil {
+		return a == nil && b != nil
+	}
+	return cmp.Or(
+		cmp.Compare(a.ID.String(), b.ID.String()),
+		cmp.Compare(a.Comment, b.Comment),
+		cmp.Compare(a.Content, b.Content),
+		cmp.Compare(sortedKeys(a.Keys), sortedKeys(b.Keys)),
+		cmp.Compare(sortedKeys(a.SecondaryKeys), sortedKeys(b.SecondaryKeys)),
+	) < 0
+}
+
+// sortedKeys returns the keys sorted and joined (so the order of the keys does not change the entry order)
+func sortedKeys(keys []string) string {
+	return strings.Join(slices.Sorted(slices.Values(keys)), "\x00")
+}
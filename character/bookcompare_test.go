@@ -0,0 +1,103 @@
+package character
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/r3dpixel/card-parser/property"
+	"github.com/stretchr/testify/assert"
+)
+
+// compareEntry returns an entry with the given ID, keys and extension values
+func compareEntry(id property.Union, content string, keys []string, extensions map[string]any) *BookEntry {
+	entry := DefaultBookEntry()
+	entry.ID = id
+	entry.Content = property.String(content)
+	entry.Keys = keys
+	entry.SecondaryKeys = []string{"secondary", "other"}
+	entry.RawExtensions = extensions
+	return entry
+}
+
+func TestBookEntry_DeepEquals(t *testing.T) {
+	base := func() *BookEntry {
+		return compareEntry(property.UnionFromInt(5), "content", []string{"a", "b", "c"}, map[string]any{"weight": 3.0})
+	}
+
+	tests := []struct {
+		name     string
+		modify   func(entry *BookEntry)
+		expected bool
+	}{
+		{"Same entry", func(entry *BookEntry) {}, true},
+		{"Shuffled keys", func(entry *BookEntry) {
+			entry.Keys = []string{"c", "a", "b"}
+			entry.SecondaryKeys = []string{"other", "secondary"}
+		}, true},
+		{"String ID", func(entry *BookEntry) { entry.ID = property.UnionFromString("5") }, true},
+		{"Integer extension value", func(entry *BookEntry) { entry.RawExtensions = map[string]any{"weight": 3} }, true},
+		{"Different ID", func(entry *BookEntry) { entry.ID = property.UnionFromString("6") }, false},
+		{"Different extension value", func(entry *BookEntry) { entry.RawExtensions = map[string]any{"weight": 3.5} }, false},
+		{"Different key", func(entry *BookEntry) { entry.Keys = []string{"a", "b", "d"} }, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			other := base()
+			tt.modify(other)
+			assert.Equal(t, tt.expected, base().DeepEquals(other))
+			assert.Equal(t, tt.expected, other.DeepEquals(base()))
+			assert.Equal(t, tt.expected, len(base().Diff(other)) == 0)
+		})
+	}
+
+	t.Run("Nil and empty extensions", func(t *testing.T) {
+		entry, other := DefaultBookEntry(), DefaultBookEntry()
+		entry.RawExtensions = nil
+		other.RawExtensions = map[string]any{}
+		assert.True(t, entry.DeepEquals(other))
+	})
+}
+
+func TestBook_DeepEquals(t *testing.T) {
+	book := func(ids ...int) *Book {
+		b := &Book{Name: "Lore", Extensions: map[string]any{"depth": 4.0}}
+		for _, id := range ids {
+			b.Entries = append(b.Entries, compareEntry(property.UnionFromInt(id), "content", []string{"key", "k" + strconv.Itoa(id)}, nil))
+		}
+		return b
+	}
+
+	t.Run("Equal books", func(t *testing.T) {
+		other := book(1, 2, 3)
+		other.Extensions = map[string]any{"depth": 4}
+		other.Entries[0].ID = property.UnionFromString("1")
+		other.Entries[1].Keys = []string{other.Entries[1].Keys[1], other.Entries[1].Keys[0]}
+		assert.True(t, book(1, 2, 3).DeepEquals(other))
+		assert.Empty(t, book(1, 2, 3).Diff(other))
+	})
+
+	t.Run("Entry order", func(t *testing.T) {
+		shuffled := book(3, 1, 2)
+		shuffled.Entries[0].ID = property.UnionFromString("3")
+		assert.False(t, book(1, 2, 3).DeepEquals(shuffled))
+		assert.True(t, book(1, 2, 3).DeepEquals(shuffled, BookCompareOptions{IgnoreEntryOrder: true}))
+		assert.Empty(t, book(1, 2, 3).Diff(shuffled, BookCompareOptions{IgnoreEntryOrder: true}))
+	})
+
+	t.Run("Diff paths", func(t *testing.T) {
+		other := book(1, 2)
+		other.Entries[1].Content = "changed"
+		other.Extensions = map[string]any{"depth": 5}
+		assert.Equal(t, []FieldDiff{
+			{Path: "extensions.depth", Old: "4", New: "5"},
+			{Path: "entries[1].content", Old: "content", New: "changed"},
+		}, book(1, 2).Diff(other))
+	})
+
+	t.Run("Nil books", func(t *testing.T) {
+		var nilBook *Book
+		assert.True(t, nilBook.DeepEquals(nil))
+		assert.False(t, nilBook.DeepEquals(book(1)))
+	})
+}
@@ -1,14 +1,20 @@
 package character
 
 import (
+	"errors"
 	"maps"
+	"slices"
+	"strings"
 
+	"github.com/r3dpixel/card-parser/internal/codec"
 	"github.com/r3dpixel/card-parser/property"
 	"github.com/r3dpixel/toolkit/jsonx"
-	"github.com/r3dpixel/toolkit/sonicx"
 	"github.com/r3dpixel/toolkit/stringsx"
 )
 
+// errNotObject is returned when a JSON object is expected
+var errNotObject = errors.New("not a JSON object")
+
 // bookEntryAlias is used to avoid circular references
 type bookEntryAlias BookEntry
 
@@ -71,6 +77,21 @@ func FilledBookEntry(entryName, entryDescription string) *BookEntry {
 	return entry
 }
 
+// Clone returns a deep copy of the entry (the ID, keys, raw extensions and typed extensions are not shared)
+func (e *BookEntry) Clone() *BookEntry {
+	// Copy the value fields (the typed extensions hold values only)
+	clone := *e
+	// Copy the ID
+	clone.ID = e.ID.Clone()
+	// Copy the keys
+	clone.Keys = slices.Clone(e.Keys)
+	clone.SecondaryKeys = slices.Clone(e.SecondaryKeys)
+	// Copy the raw extensions
+	clone.RawExtensions = cloneMap(e.RawExtensions)
+	// Return the copy
+	return &clone
+}
+
 // MirrorNameAndComment assures that the comment/name of the entry are consistent
 func (e *BookEntry) MirrorNameAndComment() {
 	// Check the blank status of each field
@@ -95,7 +116,7 @@ func (e *BookEntry) MarshalJSON() ([]byte, error) {
 	temp.BookEntryCore = e.BookEntryCore
 
 	// Extract the typed extensions
-	knownExtensions, err := jsonx.StructToMap(e.Extensions)
+	knownExtensions, err := jsonx.StructToMap(&e.Extensions)
 	if err != nil {
 		return nil, err
 	}
@@ -114,10 +135,12 @@ func (e *BookEntry) MarshalJSON() ([]byte, error) {
 	}
 
 	// Marshal the BookEntryWrapper struct to JSON
-	return sonicx.Config.Marshal(&temp)
+	return codec.Marshal(&temp)
 }
 
 // UnmarshalJSON unmarshals JSON data into the BookEntry struct
+// The entry is parsed in a single pass: core fields and typed extensions are decoded from their raw values,
+// unknown extensions are collected into the raw extensions, and straggler extensions override the typed ones
 func (e *BookEntry) UnmarshalJSON(data []byte) error {
 	// Initialize the BookEntry struct with default values
 	*e = *DefaultBookEntry()
@@ -125,21 +148,46 @@ func (e *BookEntry) UnmarshalJSON(data []byte) error {
 	// Convert to string without copying the underlying array
 	ref := stringsx.FromBytes(data)
 
-	// Unmarshal the alias book entry struct from JSON
-	if err := sonicx.Config.UnmarshalFromString(ref, (*bookEntryAlias)(e)); err != nil {
+	// Parse the entry (values that are not objects are decoded by the alias, e.g. null is a no-op)
+	root, err := codec.GetFromString(ref)
+	if err != nil {
 		return err
 	}
+	if !root.IsObject() {
+		return codec.UnmarshalFromString(ref, (*bookEntryAlias)(e))
+	}
 
-	// Unmarshal to a raw map as well (double unmarshalling necessary, unfortunately)
-	var rawMap map[string]any
-	if err := sonicx.Config.UnmarshalFromString(ref, &rawMap); err != nil {
+	// Walk the entry members once
+	var extensionKeys map[string]struct{}
+	topLevel := make(map[BookEntryExtension]string)
+	err = forEachMember(root, func(key string, raw string, node codec.Node) error {
+		// Decode the core fields
+		if field := e.coreField(key); field != nil {
+			return codec.UnmarshalFromString(raw, field)
+		}
+		// Remember the top level extensions (possible stragglers)
+		if slices.Contains(bookEntryStragglers, key) {
+			topLevel[key] = raw
+			return nil
+		}
+		if !strings.EqualFold(key, ExtensionsField) {
+			return nil
+		}
+		// Decode the extension map (values that are not objects are decoded by the typed extensions)
+		if !node.IsObject() {
+			return codec.UnmarshalFromString(raw, &e.Extensions)
+		}
+		if key == ExtensionsField {
+			extensionKeys = make(map[string]struct{})
+			e.RawExtensions = make(map[string]any)
+		}
+		return e.unmarshalExtensions(node, key == ExtensionsField, extensionKeys)
+	})
+	if err != nil {
 		return err
 	}
 
-	// Extract the extension map
-	extensionsMap, ok := rawMap["extensions"].(map[string]any)
-
-	// Extract straggler keyed extensions
+	// Override the typed extensions with the stragglers (in the bookEntryStragglers order)
 	// A straggler key extension is an extension that is mapped outside the extension map itself
 	// Examples (in this case case_sensitive is a straggler since it's outside the extension map):
 	// {
@@ -148,51 +196,110 @@ func (e *BookEntry) UnmarshalJSON(data []byte) error {
 	//   }
 	//   "case_sensitive": true
 	// }
-	// Extract case_sensitive from the top level map, if it exists
-	if caseSensitive, straggler := stragglerKey(EntryCaseSensitive, rawMap, extensionsMap); straggler {
-		jsonx.HandlePrimitiveValue(caseSensitive, &e.Extensions.CaseSensitive)
-	}
-	// Extract lore position from the top level map, if it exists
-	if lorePosition, straggler := stragglerKey(EntryPosition, rawMap, extensionsMap); straggler {
-		jsonx.HandleEntityValue(lorePosition, &e.Extensions.LorePosition)
-	}
-	// Extract probability from the top level map, if it exists
-	if probability, straggler := stragglerKey(EntryProbability, rawMap, extensionsMap); straggler {
-		jsonx.HandlePrimitiveValue(probability, &e.Extensions.Probability)
-	}
-	// Extract selective logic from the top level map, if it exists
-	if selectiveLogic, straggler := stragglerKey(EntrySelectiveLogic, rawMap, extensionsMap); straggler {
-		jsonx.HandleEntityValue(selectiveLogic, &e.Extensions.SelectiveLogic)
-	}
-	// Extract role from the top level map, if it exists
-	if role, straggler := stragglerKey(EntryRole, rawMap, extensionsMap); straggler {
-		jsonx.HandleEntityValue(role, &e.Extensions.Role)
+	for _, key := range bookEntryStragglers {
+		raw, isTopLevel := topLevel[key]
+		if _, isExtension := extensionKeys[key]; !isTopLevel || isExtension {
+			continue
+		}
+		if err := e.handleStraggler(key, raw); err != nil {
+			return err
+		}
 	}
 
-	// If an extension map was found, remove the typed extensions from the raw extensions map
-	if ok {
-		// Remove the typed extensions from the raw extensions map
-		for _, fieldName := range bookEntryExtensionFields {
-			delete(extensionsMap, fieldName)
+	// Return nil (success)
+	return nil
+}
+
+// unmarshalExtensions decodes the typed extensions of the extension map node, and collects the other ones
+// into the raw extensions (if collect is set, recording every key found)
+func (e *BookEntry) unmarshalExtensions(node codec.Node, collect bool, keys map[string]struct{}) error {
+	return forEachMember(node, func(key string, raw string, _ codec.Node) error {
+		// Decode the typed extensions
+		if field := e.Extensions.field(key); field != nil {
+			if err := codec.UnmarshalFromString(raw, field); err != nil {
+				return err
+			}
+		}
+		if !collect {
+			return nil
+		}
+		// Collect the other extensions
+		keys[key] = struct{}{}
+		if slices.Contains(bookEntryExtensionFields, key) {
+			return nil
 		}
-		// Set the raw extensions map on the BookEntry struct
-		e.RawExtensions = extensionsMap
+		var value any
+		if err := codec.UnmarshalFromString(raw, &value); err != nil {
+			return err
+		}
+		e.RawExtensions[key] = value
+		return nil
+	})
+}
+
+// handleStraggler decodes a straggler extension into the typed extensions
+func (e *BookEntry) handleStraggler(key BookEntryExtension, raw string) error {
+	data := []byte(raw)
+	switch key {
+	case EntryCaseSensitive:
+		return codec.HandlePrimitive(data, &e.Extensions.CaseSensitive)
+	case EntryPosition:
+		return codec.HandleEntity(data, &e.Extensions.LorePosition)
+	case EntryProbability:
+		return codec.HandlePrimitive(data, &e.Extensions.Probability)
+	case EntrySelectiveLogic:
+		return codec.HandleEntity(data, &e.Extensions.SelectiveLogic)
+	case EntryRole:
+		return codec.HandleEntity(data, &e.Extensions.Role)
+	case EntryAutomationID:
+		return codec.HandlePrimitive(data, &e.Extensions.AutomationID)
+	case EntryGroup:
+		return codec.HandlePrimitive(data, &e.Extensions.Group)
+	case EntryGroupOverride:
+		return codec.HandlePrimitive(data, &e.Extensions.GroupOverride)
+	case EntryGroupWeight:
+		return codec.HandlePrimitive(data, &e.Extensions.GroupWeight)
+	case EntryUseProbability:
+		return codec.HandlePrimitive(data, &e.Extensions.UseProbability)
+	case EntryPreventRecursion:
+		return codec.HandlePrimitive(data, &e.Extensions.PreventRecursion)
 	}
+	return nil
+}
 
-	// Return nil (success)
+// coreField returns a pointer to the core field with the given JSON name (matched case-insensitively), nil if unknown
+func (e *BookEntry) coreField(key string) any {
+	switch strings.ToLower(key) {
+	case "id":
+		return &e.ID
+	case "keys":
+		return &e.Keys
+	case "secondary_keys":
+		return &e.SecondaryKeys
+	case "name":
+		return &e.Name
+	case "comment":
+		return &e.Comment
+	case "content":
+		return &e.Content
+	case "constant":
+		return &e.Constant
+	case "selective":
+		return &e.Selective
+	case "insertion_order":
+		return &e.InsertionOrder
+	case "enabled":
+		return &e.Enabled
+	case "use_regex":
+		return &e.UseRegex
+	}
 	return nil
 }
 
-// stragglerKey returns the value of a key if it exists outside the extension map, and whether the key is a straggler
-func stragglerKey(key BookEntryExtension, entryMap map[BookEntryExtension]any, extensionsMap map[BookEntryExtension]any) (any, bool) {
-	// Check if the key exists in the top level map
-	topLevelValue, isTopLevel := entryMap[key]
-	// Check if the key exists in the extension map
-	_, isExtension := extensionsMap[key]
-	// If the key is not an extension and is at the top level then it is a straggler
-	if !isExtension && isTopLevel {
-		return topLevelValue, true
+// forEachMember calls fn with the key, raw value and node of every member of the object node (in order)
+func forEachMember(node codec.Node, fn func(key string, raw string, node codec.Node) error) error {
+	if !node.IsObject() {
+		return errNotObject
 	}
-	// Otherwise return nil and false
-	return nil, false
+	return node.ForEachMember(fn)
 }
@@ -2,9 +2,12 @@ package character
 
 import (
 	"encoding/json"
+	"fmt"
+	"strings"
 	"testing"
 
 	"github.com/r3dpixel/card-parser/property"
+	"github.com/r3dpixel/toolkit/jsonx"
 	"github.com/r3dpixel/toolkit/sonicx"
 	"github.com/r3dpixel/toolkit/stringsx"
 	"github.com/stretchr/testify/assert"
@@ -47,6 +50,27 @@ func TestDefaultBookEntry(t *testing.T) {
 	assert.Equal(t, property.DefaultSelectiveLogic, entry.Extensions.SelectiveLogic)
 }
 
+func TestBookEntry_Clone(t *testing.T) {
+	original := FilledBookEntry("Name", "Content")
+	original.ID = property.UnionFromInt(3)
+	original.RawExtensions = map[string]any{"custom": map[string]any{"nested": []any{"a"}}}
+	original.Extensions.Group = "group"
+
+	// Mutate every reference of the clone
+	clone := original.Clone()
+	*clone.ID.IntValue = 4
+	clone.Keys[0] = "Changed"
+	clone.RawExtensions["custom"].(map[string]any)["nested"].([]any)[0] = "b"
+	clone.RawExtensions["added"] = true
+	clone.Extensions.Group = "changed"
+
+	// The original is untouched
+	assert.Equal(t, 3, *original.ID.IntValue)
+	assert.Equal(t, property.StringArray{"Name"}, original.Keys)
+	assert.Equal(t, map[string]any{"custom": map[string]any{"nested": []any{"a"}}}, original.RawExtensions)
+	assert.Equal(t, property.String("group"), original.Extensions.Group)
+}
+
 func TestBookEntry_MirrorNameAndComment(t *testing.T) {
 	testCases := []struct {
 		name            string
@@ -165,6 +189,12 @@ func TestBookEntry_UnmarshalJSON(t *testing.T) {
 				"sticky": 2,
 				"cooldown": 10,
 				"delay": 5,
+				"automation_id": "auto-1",
+				"group": "people",
+				"group_override": true,
+				"group_weight": 40,
+				"use_probability": false,
+				"prevent_recursion": true,
 				"unknown_field": "should remain"
 			}
 		}`
@@ -185,6 +215,12 @@ func TestBookEntry_UnmarshalJSON(t *testing.T) {
 		assert.Equal(t, 2, int(extensions.Sticky))
 		assert.Equal(t, 10, int(extensions.Cooldown))
 		assert.Equal(t, 5, int(extensions.Delay))
+		assert.Equal(t, "auto-1", string(extensions.AutomationID))
+		assert.Equal(t, "people", string(extensions.Group))
+		assert.Equal(t, true, bool(extensions.GroupOverride))
+		assert.Equal(t, 40.0, float64(extensions.GroupWeight))
+		assert.Equal(t, false, bool(extensions.UseProbability))
+		assert.Equal(t, true, bool(extensions.PreventRecursion))
 
 		// Check that known extensions were removed from map
 		assert.NotContains(t, entry.RawExtensions, EntryPosition)
@@ -197,6 +233,12 @@ func TestBookEntry_UnmarshalJSON(t *testing.T) {
 		assert.NotContains(t, entry.RawExtensions, EntrySticky)
 		assert.NotContains(t, entry.RawExtensions, EntryCooldown)
 		assert.NotContains(t, entry.RawExtensions, EntryDelay)
+		assert.NotContains(t, entry.RawExtensions, EntryAutomationID)
+		assert.NotContains(t, entry.RawExtensions, EntryGroup)
+		assert.NotContains(t, entry.RawExtensions, EntryGroupOverride)
+		assert.NotContains(t, entry.RawExtensions, EntryGroupWeight)
+		assert.NotContains(t, entry.RawExtensions, EntryUseProbability)
+		assert.NotContains(t, entry.RawExtensions, EntryPreventRecursion)
 
 		// Check that unknown extensions remain in map
 		assert.Contains(t, entry.RawExtensions, "unknown_field")
@@ -236,6 +278,30 @@ func TestBookEntry_UnmarshalJSON(t *testing.T) {
 		assert.Equal(t, true, bool(entry.Extensions.CaseSensitive))
 	})
 
+	t.Run("Unmarshal with SillyTavern straggler extensions", func(t *testing.T) {
+		jsonData := `{
+			"automation_id": "auto-2",
+			"group": "places",
+			"group_override": "true",
+			"group_weight": "25",
+			"use_probability": false,
+			"prevent_recursion": 1,
+			"extensions": {"group": "people"}
+		}`
+
+		var entry BookEntry
+		err := sonicx.Config.UnmarshalFromString(jsonData, &entry)
+		require.NoError(t, err)
+
+		assert.Equal(t, "auto-2", string(entry.Extensions.AutomationID))
+		assert.Equal(t, "people", string(entry.Extensions.Group))
+		assert.Equal(t, true, bool(entry.Extensions.GroupOverride))
+		assert.Equal(t, 25.0, float64(entry.Extensions.GroupWeight))
+		assert.Equal(t, false, bool(entry.Extensions.UseProbability))
+		assert.Equal(t, true, bool(entry.Extensions.PreventRecursion))
+		assert.Empty(t, entry.RawExtensions)
+	})
+
 	t.Run("Unmarshal with straggler and normal extensions", func(t *testing.T) {
 		jsonData := `{
 			"selectiveLogic": "NOT_ANY",
@@ -482,6 +548,12 @@ func TestExtensionConstants(t *testing.T) {
 		assert.Equal(t, "sticky", EntrySticky)
 		assert.Equal(t, "cooldown", EntryCooldown)
 		assert.Equal(t, "delay", EntryDelay)
+		assert.Equal(t, "automation_id", EntryAutomationID)
+		assert.Equal(t, "group", EntryGroup)
+		assert.Equal(t, "group_override", EntryGroupOverride)
+		assert.Equal(t, "group_weight", EntryGroupWeight)
+		assert.Equal(t, "use_probability", EntryUseProbability)
+		assert.Equal(t, "prevent_recursion", EntryPreventRecursion)
 	})
 }
 
@@ -530,3 +602,118 @@ func TestBookEntry_EdgeCases(t *testing.T) {
 		assert.NotContains(t, entry.RawExtensions, EntryProbability)
 	})
 }
+
+// twoPassUnmarshalBookEntry is the previous (two pass) implementation of BookEntry.UnmarshalJSON,
+// used as the reference of the single pass implementation
+func twoPassUnmarshalBookEntry(e *BookEntry, data []byte) error {
+	*e = *DefaultBookEntry()
+	ref := stringsx.FromBytes(data)
+	if err := sonicx.Config.UnmarshalFromString(ref, (*bookEntryAlias)(e)); err != nil {
+		return err
+	}
+	var rawMap map[string]any
+	if err := sonicx.Config.UnmarshalFromString(ref, &rawMap); err != nil {
+		return err
+	}
+	extensionsMap, ok := rawMap["extensions"].(map[string]any)
+	stragglerKey := func(key BookEntryExtension) (any, bool) {
+		topLevelValue, isTopLevel := rawMap[key]
+		_, isExtension := extensionsMap[key]
+		return topLevelValue, !isExtension && isTopLevel
+	}
+	if value, straggler := stragglerKey(EntryCaseSensitive); straggler {
+		jsonx.HandlePrimitiveValue(value, &e.Extensions.CaseSensitive)
+	}
+	if value, straggler := stragglerKey(EntryPosition); straggler {
+		jsonx.HandleEntityValue(value, &e.Extensions.LorePosition)
+	}
+	if value, straggler := stragglerKey(EntryProbability); straggler {
+		jsonx.HandlePrimitiveValue(value, &e.Extensions.Probability)
+	}
+	if value, straggler := stragglerKey(EntrySelectiveLogic); straggler {
+		jsonx.HandleEntityValue(value, &e.Extensions.SelectiveLogic)
+	}
+	if value, straggler := stragglerKey(EntryRole); straggler {
+		jsonx.HandleEntityValue(value, &e.Extensions.Role)
+	}
+	if ok {
+		for _, fieldName := range bookEntryExtensionFields {
+			delete(extensionsMap, fieldName)
+		}
+		e.RawExtensions = extensionsMap
+	}
+	return nil
+}
+
+func TestBookEntry_UnmarshalJSON_MatchesTwoPass(t *testing.T) {
+	inputs := []string{
+		`{}`,
+		`null`,
+		`{"id": 1, "keys": ["a", "b"], "secondary_keys": "c", "name": "Name", "comment": 5, "content": "Content", "constant": "true", "selective": 1, "insertion_order": "7", "enabled": false, "use_regex": null}`,
+		`{"ID": "uuid", "Keys": ["a"], "Name": "Upper case keys"}`,
+		`{"extensions": {"position": 1, "probability": 75.5, "depth": 5, "selectiveLogic": 2, "match_whole_words": true, "case_sensitive": false, "role": 1, "sticky": 2, "cooldown": 10, "delay": 5, "unknown": {"nested": [1, 2]}}}`,
+		`{"extensions": {"probability": 50}, "probability": 25, "case_sensitive": true, "position": "before_char", "selectiveLogic": "and_all", "role": "user"}`,
+		`{"case_sensitive": true, "position": 2, "probability": "80", "selectiveLogic": 3, "role": 2}`,
+		`{"extensions": {"Probability": 10, "ROLE": 1}, "probability": 20}`,
+		`{"extensions": {}, "role": null}`,
+		`{"extensions": null, "case_sensitive": [true]}`,
+		`{"Extensions": {"depth": 9, "custom": true}}`,
+		`{"extensions": {"custom": 1}, "extensions": {"other": 2}}`,
+		`{"extensions": {"deep": ` + strings.Repeat(`[`, DefaultMaxNestingDepth+5) + strings.Repeat(`]`, DefaultMaxNestingDepth+5) + `}}`,
+		`{"extensions": "not an object"}`,
+		`[]`,
+		`{"name": }`,
+	}
+
+	for index, input := range inputs {
+		t.Run(fmt.Sprintf("Input %d", index), func(t *testing.T) {
+			var expected, actual BookEntry
+			expectedErr := twoPassUnmarshalBookEntry(&expected, []byte(input))
+			actualErr := actual.UnmarshalJSON([]byte(input))
+			if expectedErr != nil {
+				assert.Error(t, actualErr)
+				return
+			}
+			require.NoError(t, actualErr)
+			assert.Equal(t, expected, actual)
+		})
+	}
+}
+
+// benchmarkBook returns the JSON of a book with 5k entries (with extensions and stragglers)
+func benchmarkBook() []byte {
+	entries := make([]string, 5000)
+	for index := range entries {
+		entries[index] = fmt.Sprintf(`{"id": %d, "keys": ["key %d", "alias %d"], "secondary_keys": [], "name": "Entry %d", "comment": "Entry %d",
+			"content": "%s", "constant": false, "selective": true, "insertion_order": %d, "enabled": true, "use_regex": false,
+			"extensions": {"position": 1, "probability": 75, "depth": 4, "selectiveLogic": 0, "match_whole_words": true,
+			"role": 0, "sticky": 0, "cooldown": 0, "delay": 0, "display_index": %d, "vectorized": false, "group": "group"},
+			"case_sensitive": true}`, index, index, index, index, index, strings.Repeat("Lorem ipsum dolor sit amet. ", 20), index, index)
+	}
+	return []byte("[" + strings.Join(entries, ",") + "]")
+}
+
+func BenchmarkBookEntry_UnmarshalJSON(b *testing.B) {
+	var raw []json.RawMessage
+	require.NoError(b, json.Unmarshal(benchmarkBook(), &raw))
+
+	b.Run("SinglePass", func(b *testing.B) {
+		b.ReportAllocs()
+		for b.Loop() {
+			for _, data := range raw {
+				var entry BookEntry
+				_ = entry.UnmarshalJSON(data)
+			}
+		}
+	})
+
+	b.Run("TwoPass", func(b *testing.B) {
+		b.ReportAllocs()
+		for b.Loop() {
+			for _, data := range raw {
+				var entry BookEntry
+				_ = twoPassUnmarshalBookEntry(&entry, data)
+			}
+		}
+	})
+}
@@ -1,6 +1,9 @@
 package character
 
 import (
+	"math"
+	"strings"
+
 	"github.com/r3dpixel/card-parser/property"
 	"github.com/r3dpixel/toolkit/jsonx"
 )
@@ -9,21 +12,28 @@ import (
 type BookEntryExtension = string
 
 const (
-	EntryPosition        BookEntryExtension = "position"
-	EntryProbability     BookEntryExtension = "probability"
-	EntryDepth           BookEntryExtension = "depth"
-	EntrySelectiveLogic  BookEntryExtension = "selectiveLogic"
-	EntryMatchWholeWords BookEntryExtension = "match_whole_words"
-	EntryCaseSensitive   BookEntryExtension = "case_sensitive"
-	EntryRole            BookEntryExtension = "role"
-	EntrySticky          BookEntryExtension = "sticky"
-	EntryCooldown        BookEntryExtension = "cooldown"
-	EntryDelay           BookEntryExtension = "delay"
+	EntryPosition         BookEntryExtension = "position"
+	EntryProbability      BookEntryExtension = "probability"
+	EntryDepth            BookEntryExtension = "depth"
+	EntrySelectiveLogic   BookEntryExtension = "selectiveLogic"
+	EntryMatchWholeWords  BookEntryExtension = "match_whole_words"
+	EntryCaseSensitive    BookEntryExtension = "case_sensitive"
+	EntryRole             BookEntryExtension = "role"
+	EntrySticky           BookEntryExtension = "sticky"
+	EntryCooldown         BookEntryExtension = "cooldown"
+	EntryDelay            BookEntryExtension = "delay"
+	EntryAutomationID     BookEntryExtension = "automation_id"
+	EntryGroup            BookEntryExtension = "group"
+	EntryGroupOverride    BookEntryExtension = "group_override"
+	EntryGroupWeight      BookEntryExtension = "group_weight"
+	EntryUseProbability   BookEntryExtension = "use_probability"
+	EntryPreventRecursion BookEntryExtension = "prevent_recursion"
 )
 
 const (
 	DefaultEntryProbability float64 = 100.00 // Default probability for entries
 	DefaultEntryDepth       int     = 4      // Default depth for entries
+	DefaultEntryGroupWeight float64 = 100.00 // Default weight of entries in their inclusion group
 )
 
 // bookEntryExtensionFields is a helper variable that extracts the field names from BookEntryExtensions (typed extension struct)
@@ -31,30 +41,95 @@ var bookEntryExtensionFields = jsonx.ExtractJsonFieldNames(BookEntryExtensions{}
 
 // BookEntryExtensions is a typed struct for extensions that can be added to a BookEntry
 type BookEntryExtensions struct {
-	LorePosition    property.LorePosition   `json:"position"`
-	Probability     property.Float          `json:"probability"`
-	Depth           property.Integer        `json:"depth"`
-	SelectiveLogic  property.SelectiveLogic `json:"selectiveLogic"`
-	MatchWholeWords property.Bool           `json:"match_whole_words"`
-	CaseSensitive   property.Bool           `json:"case_sensitive"`
-	Role            property.Role           `json:"role"`
-	Sticky          property.Integer        `json:"sticky"`
-	Cooldown        property.Integer        `json:"cooldown"`
-	Delay           property.Integer        `json:"delay"`
+	LorePosition     property.LorePosition   `json:"position"`
+	Probability      property.Float          `json:"probability"`
+	Depth            property.Integer        `json:"depth"`
+	SelectiveLogic   property.SelectiveLogic `json:"selectiveLogic"`
+	MatchWholeWords  property.Bool           `json:"match_whole_words"`
+	CaseSensitive    property.Bool           `json:"case_sensitive"`
+	Role             property.Role           `json:"role"`
+	Sticky           property.Integer        `json:"sticky"`
+	Cooldown         property.Integer        `json:"cooldown"`
+	Delay            property.Integer        `json:"delay"`
+	AutomationID     property.String         `json:"automation_id"`
+	Group            property.String         `json:"group"`
+	GroupOverride    property.Bool           `json:"group_override"`
+	GroupWeight      property.Float          `json:"group_weight"`
+	UseProbability   property.Bool           `json:"use_probability"`
+	PreventRecursion property.Bool           `json:"prevent_recursion"`
 }
 
 // DefaultBookEntryExtensions returns an initialized BookEntryExtensions struct with default values
 func DefaultBookEntryExtensions() BookEntryExtensions {
 	return BookEntryExtensions{
-		LorePosition:    property.DefaultLorePosition,
-		Probability:     property.Float(DefaultEntryProbability),
-		Depth:           property.Integer(DefaultEntryDepth),
-		SelectiveLogic:  property.DefaultSelectiveLogic,
-		MatchWholeWords: false,
-		CaseSensitive:   false,
-		Role:            property.DefaultRole,
-		Sticky:          0,
-		Cooldown:        0,
-		Delay:           0,
+		LorePosition:     property.DefaultLorePosition,
+		Probability:      property.Float(DefaultEntryProbability),
+		Depth:            property.Integer(DefaultEntryDepth),
+		SelectiveLogic:   property.DefaultSelectiveLogic,
+		MatchWholeWords:  false,
+		CaseSensitive:    false,
+		Role:             property.DefaultRole,
+		Sticky:           0,
+		Cooldown:         0,
+		Delay:            0,
+		AutomationID:     "",
+		Group:            "",
+		GroupOverride:    false,
+		GroupWeight:      property.Float(DefaultEntryGroupWeight),
+		UseProbability:   true,
+		PreventRecursion: false,
+	}
+}
+
+// Sanitize clamps the probability to [0, 100], the depth, sticky, cooldown and delay to non-negative values,
+// and resets the out-of-range position, selective logic and role to their defaults
+// The parsers already do so for JSON input, this covers the values set directly on the fields
+func (e *BookEntryExtensions) Sanitize() {
+	e.Probability.Clamp(0, DefaultEntryProbability)
+	e.Depth.Clamp(0, math.MaxInt)
+	e.Sticky.Clamp(0, math.MaxInt)
+	e.Cooldown.Clamp(0, math.MaxInt)
+	e.Delay.Clamp(0, math.MaxInt)
+	e.LorePosition = property.LorePositionProp().FromInt(int(e.LorePosition))
+	e.SelectiveLogic = property.SelectiveLogicProp().FromInt(int(e.SelectiveLogic))
+	e.Role = property.RoleProp().FromInt(int(e.Role))
+}
+
+// field returns a pointer to the typed extension with the given JSON name (matched case-insensitively), nil if unknown
+func (e *BookEntryExtensions) field(key string) any {
+	switch strings.ToLower(key) {
+	case "position":
+		return &e.LorePosition
+	case "probability":
+		return &e.Probability
+	case "depth":
+		return &e.Depth
+	case "selectivelogic":
+		return &e.SelectiveLogic
+	case "match_whole_words":
+		return &e.MatchWholeWords
+	case "case_sensitive":
+		return &e.CaseSensitive
+	case "role":
+		return &e.Role
+	case "sticky":
+		return &e.Sticky
+	case "cooldown":
+		return &e.Cooldown
+	case "delay":
+		return &e.Delay
+	case "automation_id":
+		return &e.AutomationID
+	case "group":
+		return &e.Group
+	case "group_override":
+		return &e.GroupOverride
+	case "group_weight":
+		return &e.GroupWeight
+	case "use_probability":
+		return &e.UseProbability
+	case "prevent_recursion":
+		return &e.PreventRecursion
 	}
+	return nil
 }
@@ -1,12 +1,14 @@
 package character
 
 import (
+	"os"
 	"testing"
 
 	"github.com/r3dpixel/card-parser/property"
 	"github.com/r3dpixel/toolkit/ptr"
 	"github.com/r3dpixel/toolkit/sonicx"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestBookEntryExtensions_Constants(t *testing.T) {
@@ -20,6 +22,12 @@ func TestBookEntryExtensions_Constants(t *testing.T) {
 	assert.Equal(t, "sticky", EntrySticky)
 	assert.Equal(t, "cooldown", EntryCooldown)
 	assert.Equal(t, "delay", EntryDelay)
+	assert.Equal(t, "automation_id", EntryAutomationID)
+	assert.Equal(t, "group", EntryGroup)
+	assert.Equal(t, "group_override", EntryGroupOverride)
+	assert.Equal(t, "group_weight", EntryGroupWeight)
+	assert.Equal(t, "use_probability", EntryUseProbability)
+	assert.Equal(t, "prevent_recursion", EntryPreventRecursion)
 }
 
 func TestBookEntryExtensions_DefaultMissing(t *testing.T) {
@@ -63,6 +71,12 @@ func TestBookEntryExtensions_Default(t *testing.T) {
 	assert.Equal(t, 0, int(defaults.Sticky))
 	assert.Equal(t, 0, int(defaults.Cooldown))
 	assert.Equal(t, 0, int(defaults.Delay))
+	assert.Equal(t, "", string(defaults.AutomationID))
+	assert.Equal(t, "", string(defaults.Group))
+	assert.Equal(t, false, bool(defaults.GroupOverride))
+	assert.Equal(t, DefaultEntryGroupWeight, float64(defaults.GroupWeight))
+	assert.Equal(t, true, bool(defaults.UseProbability))
+	assert.Equal(t, false, bool(defaults.PreventRecursion))
 }
 
 // assertBookEntryExtensions is a helper function that asserts BookEntryExtensions values
@@ -78,6 +92,12 @@ func assertBookEntryExtensions(t *testing.T, expected BookEntryExtensions, actua
 	assert.Equal(t, int(expected.Sticky), int(actual.Sticky))
 	assert.Equal(t, int(expected.Cooldown), int(actual.Cooldown))
 	assert.Equal(t, int(expected.Delay), int(actual.Delay))
+	assert.Equal(t, string(expected.AutomationID), string(actual.AutomationID))
+	assert.Equal(t, string(expected.Group), string(actual.Group))
+	assert.Equal(t, bool(expected.GroupOverride), bool(actual.GroupOverride))
+	assert.Equal(t, float64(expected.GroupWeight), float64(actual.GroupWeight))
+	assert.Equal(t, bool(expected.UseProbability), bool(actual.UseProbability))
+	assert.Equal(t, bool(expected.PreventRecursion), bool(actual.PreventRecursion))
 }
 
 // assertBookEntryExtensionsFromMap is a helper function that asserts BookEntryExtensions values
@@ -98,4 +118,140 @@ func assertBookEntryExtensionsFromMap(
 	assertFunc(t, int(expected.Sticky), int(actualMap[EntrySticky].(property.Integer)))
 	assertFunc(t, int(expected.Cooldown), int(actualMap[EntryCooldown].(property.Integer)))
 	assertFunc(t, int(expected.Delay), int(actualMap[EntryDelay].(property.Integer)))
+	assertFunc(t, string(expected.AutomationID), string(actualMap[EntryAutomationID].(property.String)))
+	assertFunc(t, string(expected.Group), string(actualMap[EntryGroup].(property.String)))
+	assertFunc(t, bool(expected.GroupOverride), bool(actualMap[EntryGroupOverride].(property.Bool)))
+	assertFunc(t, float64(expected.GroupWeight), float64(actualMap[EntryGroupWeight].(property.Float)))
+	assertFunc(t, bool(expected.UseProbability), bool(actualMap[EntryUseProbability].(property.Bool)))
+	assertFunc(t, bool(expected.PreventRecursion), bool(actualMap[EntryPreventRecursion].(property.Bool)))
+}
+
+func TestBookEntryExtensions_EnumsAsStrings(t *testing.T) {
+	property.MarshalEnumsAsStrings(true)
+	defer property.MarshalEnumsAsStrings(false)
+
+	entry := DefaultBookEntry()
+	entry.Keys = property.StringArray{"key"}
+	entry.Extensions = BookEntryExtensions{
+		LorePosition:    property.BeforeExampleMessages,
+		Probability:     55,
+		Depth:           2,
+		SelectiveLogic:  property.SelectiveNotAny,
+		MatchWholeWords: true,
+		CaseSensitive:   true,
+		Role:            property.AssistantRole,
+		Sticky:          1,
+		Cooldown:        2,
+		Delay:           3,
+	}
+
+	// The enums are marshaled as their canonical names
+	data, err := sonicx.Config.Marshal(entry)
+	assert.NoError(t, err)
+	var raw struct {
+		Extensions map[string]any `json:"extensions"`
+	}
+	assert.NoError(t, sonicx.Config.Unmarshal(data, &raw))
+	assert.Equal(t, "before_em", raw.Extensions[EntryPosition])
+	assert.Equal(t, "not_any", raw.Extensions[EntrySelectiveLogic])
+	assert.Equal(t, "assistant", raw.Extensions[EntryRole])
+
+	// The numeric parser reads the names back
+	property.MarshalEnumsAsStrings(false)
+	var roundTrip BookEntry
+	assert.NoError(t, sonicx.Config.Unmarshal(data, &roundTrip))
+	assert.Equal(t, entry.Extensions, roundTrip.Extensions)
+
+	// Default mode marshals numbers
+	data, err = sonicx.Config.Marshal(&roundTrip)
+	assert.NoError(t, err)
+	assert.NoError(t, sonicx.Config.Unmarshal(data, &raw))
+	assert.Equal(t, float64(property.BeforeExampleMessages), raw.Extensions[EntryPosition])
+}
+
+func TestBookEntryExtensions_Sanitize(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    BookEntryExtensions
+		expected BookEntryExtensions
+	}{
+		{
+			name:     "Defaults are kept",
+			input:    DefaultBookEntryExtensions(),
+			expected: DefaultBookEntryExtensions(),
+		},
+		{
+			name: "Out of range values",
+			input: BookEntryExtensions{
+				LorePosition:   property.LorePosition(42),
+				Probability:    250,
+				Depth:          -3,
+				SelectiveLogic: property.SelectiveLogic(-1),
+				Role:           property.Role(7),
+				Sticky:         -1,
+				Cooldown:       -2,
+				Delay:          -5,
+				GroupWeight:    100,
+			},
+			expected: BookEntryExtensions{
+				LorePosition:   property.DefaultLorePosition,
+				Probability:    100,
+				SelectiveLogic: property.DefaultSelectiveLogic,
+				Role:           property.DefaultRole,
+				GroupWeight:    100,
+			},
+		},
+		{
+			name:     "Negative probability",
+			input:    BookEntryExtensions{Probability: -10.5, Depth: 12, LorePosition: property.AtDepth},
+			expected: BookEntryExtensions{Probability: 0, Depth: 12, LorePosition: property.AtDepth},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			extensions := tt.input
+			extensions.Sanitize()
+			assert.Equal(t, tt.expected, extensions)
+		})
+	}
+}
+
+func TestBookEntryExtensions_SanitizeRoundTrip(t *testing.T) {
+	data, err := os.ReadFile("testdata/out_of_range_entries.json")
+	require.NoError(t, err)
+
+	// The out-of-range numbers are accepted as is by the parsers
+	sheet, err := FromBytes(data)
+	require.NoError(t, err)
+	require.Len(t, sheet.CharacterBook.Entries, 2)
+	assert.Equal(t, property.Float(250), sheet.CharacterBook.Entries[0].Extensions.Probability)
+	assert.Equal(t, property.Integer(-3), sheet.CharacterBook.Entries[0].Extensions.Depth)
+
+	// Normalizing the book sanitizes the entries, and the sane numbers round trip
+	require.NoError(t, sheet.CharacterBook.Normalize())
+	encoded, err := sheet.ToBytes()
+	require.NoError(t, err)
+	decoded, err := FromBytes(encoded)
+	require.NoError(t, err)
+
+	first, second := decoded.CharacterBook.Entries[0].Extensions, decoded.CharacterBook.Entries[1].Extensions
+	assert.Equal(t, property.Float(100), first.Probability)
+	assert.Equal(t, property.Integer(0), first.Depth)
+	assert.Equal(t, property.Integer(0), first.Sticky)
+	assert.Equal(t, property.Integer(0), first.Cooldown)
+	assert.Equal(t, property.Integer(0), first.Delay)
+	assert.Equal(t, property.AfterCharPosition, first.LorePosition)
+	assert.Equal(t, property.AssistantRole, first.Role)
+	assert.Equal(t, property.Float(0), second.Probability)
+	assert.Equal(t, property.Integer(12), second.Depth)
+	assert.Equal(t, property.Integer(3), second.Sticky)
+	assert.Equal(t, property.Integer(1), second.Delay)
+
+	// The content level hook sanitizes the lorebook as well
+	sheet, err = FromBytes(data)
+	require.NoError(t, err)
+	sheet.Sanitize()
+	assert.Equal(t, property.Float(100), sheet.CharacterBook.Entries[0].Extensions.Probability)
+	assert.Equal(t, property.Integer(0), sheet.CharacterBook.Entries[0].Extensions.Depth)
 }
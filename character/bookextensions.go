@@ -0,0 +1,119 @@
+package character
+
+import (
+	"maps"
+	"math"
+
+	"github.com/r3dpixel/card-parser/property"
+	"github.com/r3dpixel/toolkit/jsonx"
+)
+
+// BookExtension is a string alias for the extension keys of a book
+type BookExtension = string
+
+const (
+	BookTokenBudgetCap    BookExtension = "token_budget_cap"
+	BookInsertionStrategy BookExtension = "insertion_strategy"
+	BookMinActivations    BookExtension = "min_activations"
+	BookMaxDepth          BookExtension = "max_depth"
+	BookCaseSensitive     BookExtension = "case_sensitive"
+)
+
+// Insertion strategies of the book entries (SillyTavern order of the character and global lorebook entries)
+const (
+	InsertionEvenly          int = 0 // Character and global entries sorted together
+	InsertionCharacterFirst  int = 1 // Character entries first
+	InsertionGlobalFirst     int = 2 // Global entries first
+	DefaultInsertionStrategy     = InsertionCharacterFirst
+)
+
+// bookExtensionFields is a helper variable that extracts the field names from BookExtensions (typed extension struct)
+var bookExtensionFields = jsonx.ExtractJsonFieldNames(BookExtensions{})
+
+// BookExtensions is a typed struct for the (SillyTavern world-level) extensions of a Book
+type BookExtensions struct {
+	TokenBudgetCap    property.Integer `json:"token_budget_cap"`   // Maximum token budget (0 means no cap)
+	InsertionStrategy property.Integer `json:"insertion_strategy"` // Order of the character and global entries
+	MinActivations    property.Integer `json:"min_activations"`    // Minimum number of activated entries (0 to disable)
+	MaxDepth          property.Integer `json:"max_depth"`          // Maximum scan depth of the minimum activations (0 means unlimited)
+	CaseSensitive     property.Bool    `json:"case_sensitive"`     // Default case sensitivity of the entry keys
+}
+
+// DefaultBookExtensions returns an initialized BookExtensions struct with default values
+func DefaultBookExtensions() BookExtensions {
+	return BookExtensions{
+		TokenBudgetCap:    0,
+		InsertionStrategy: property.Integer(DefaultInsertionStrategy),
+		MinActivations:    0,
+		MaxDepth:          0,
+		CaseSensitive:     false,
+	}
+}
+
+// Sanitize clamps the token budget cap, minimum activations and maximum depth to non-negative values, and resets
+// an unknown insertion strategy to the default
+func (e *BookExtensions) Sanitize() {
+	e.TokenBudgetCap.Clamp(0, math.MaxInt)
+	e.MinActivations.Clamp(0, math.MaxInt)
+	e.MaxDepth.Clamp(0, math.MaxInt)
+	if e.InsertionStrategy < property.Integer(InsertionEvenly) || e.InsertionStrategy > property.Integer(InsertionGlobalFirst) {
+		e.InsertionStrategy = property.Integer(DefaultInsertionStrategy)
+	}
+}
+
+// field returns a pointer to the typed extension with the given JSON name, nil if unknown
+func (e *BookExtensions) field(key BookExtension) jsonx.PrimitiveHandler {
+	switch key {
+	case BookTokenBudgetCap:
+		return &e.TokenBudgetCap
+	case BookInsertionStrategy:
+		return &e.InsertionStrategy
+	case BookMinActivations:
+		return &e.MinActivations
+	case BookMaxDepth:
+		return &e.MaxDepth
+	case BookCaseSensitive:
+		return &e.CaseSensitive
+	}
+	return nil
+}
+
+// merge reconciles the extensions of another book into the extensions: the numeric caps keep the maximum value, and
+// case sensitivity is turned on if either book is case-sensitive (the insertion strategy is kept)
+func (e *BookExtensions) merge(other BookExtensions) {
+	e.TokenBudgetCap = max(e.TokenBudgetCap, other.TokenBudgetCap)
+	e.MinActivations = max(e.MinActivations, other.MinActivations)
+	e.MaxDepth = max(e.MaxDepth, other.MaxDepth)
+	e.CaseSensitive = e.CaseSensitive || other.CaseSensitive
+}
+
+// extractExtensions moves the typed extensions of the Extensions map into the Settings typed struct
+// Reverse of the insertExtensions method (the other extensions are left in the Extensions map)
+func (b *Book) extractExtensions() {
+	for _, key := range bookExtensionFields {
+		value, ok := b.Extensions[key]
+		if !ok {
+			continue
+		}
+		jsonx.HandlePrimitiveValue(value, b.Settings.field(key))
+		delete(b.Extensions, key)
+	}
+	if len(b.Extensions) == 0 {
+		b.Extensions = nil
+	}
+}
+
+// insertExtensions returns a copy of the Extensions map with the typed extensions inserted (the Extensions map is not
+// modified)
+func (b *Book) insertExtensions() (map[string]any, error) {
+	knownExtensions, err := jsonx.StructToMap(&b.Settings)
+	if err != nil {
+		return nil, err
+	}
+	if b.Extensions == nil {
+		return knownExtensions, nil
+	}
+	extensions := maps.Clone(b.Extensions)
+	maps.Copy(extensions, knownExtensions)
+	return extensions, nil
+}
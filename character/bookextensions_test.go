@@ -0,0 +1,156 @@
+package character
+
+import (
+	"testing"
+
+	"github.com/r3dpixel/card-parser/property"
+	"github.com/r3dpixel/toolkit/sonicx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBookExtensions_Constants(t *testing.T) {
+	assert.Equal(t, "token_budget_cap", BookTokenBudgetCap)
+	assert.Equal(t, "insertion_strategy", BookInsertionStrategy)
+	assert.Equal(t, "min_activations", BookMinActivations)
+	assert.Equal(t, "max_depth", BookMaxDepth)
+	assert.Equal(t, "case_sensitive", BookCaseSensitive)
+	assert.ElementsMatch(t, bookStragglers, bookExtensionFields)
+}
+
+func TestBookExtensions_Default(t *testing.T) {
+	defaults := DefaultBookExtensions()
+
+	assert.Equal(t, 0, int(defaults.TokenBudgetCap))
+	assert.Equal(t, InsertionCharacterFirst, int(defaults.InsertionStrategy))
+	assert.Equal(t, 0, int(defaults.MinActivations))
+	assert.Equal(t, 0, int(defaults.MaxDepth))
+	assert.Equal(t, false, bool(defaults.CaseSensitive))
+	assert.Equal(t, defaults, DefaultBook().Settings)
+}
+
+func TestBookExtensions_Unmarshal(t *testing.T) {
+	tests := []struct {
+		name       string
+		input      string
+		expected   BookExtensions
+		extensions map[string]any
+	}{
+		{
+			name:       "Missing extensions",
+			input:      `{"name": "Lore"}`,
+			expected:   DefaultBookExtensions(),
+			extensions: nil,
+		},
+		{
+			name:  "Typed extensions are removed from the map",
+			input: `{"extensions": {"token_budget_cap": 2048, "insertion_strategy": 2, "min_activations": "3", "max_depth": 10.0, "case_sensitive": true, "theme": "castle"}}`,
+			expected: BookExtensions{
+				TokenBudgetCap: 2048, InsertionStrategy: property.Integer(InsertionGlobalFirst), MinActivations: 3, MaxDepth: 10, CaseSensitive: true,
+			},
+			extensions: map[string]any{"theme": "castle"},
+		},
+		{
+			name:  "Stragglers",
+			input: `{"token_budget_cap": 512, "case_sensitive": "true", "extensions": {"max_depth": 5}}`,
+			expected: BookExtensions{
+				TokenBudgetCap: 512, InsertionStrategy: property.Integer(DefaultInsertionStrategy), MaxDepth: 5, CaseSensitive: true,
+			},
+			extensions: nil,
+		},
+		{
+			name:  "Extensions win over the stragglers",
+			input: `{"min_activations": 7, "extensions": {"min_activations": 2, "custom": [1]}}`,
+			expected: BookExtensions{
+				InsertionStrategy: property.Integer(DefaultInsertionStrategy), MinActivations: 2,
+			},
+			extensions: map[string]any{"custom": []any{float64(1)}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var book Book
+			require.NoError(t, sonicx.Config.UnmarshalFromString(tt.input, &book))
+			assert.Equal(t, tt.expected, book.Settings)
+			assert.Equal(t, tt.extensions, book.Extensions)
+		})
+	}
+}
+
+func TestBookExtensions_RoundTrip(t *testing.T) {
+	book := DefaultBook()
+	book.Name = "Lore"
+	book.Extensions = map[string]any{"theme": "castle", BookMaxDepth: 99.0}
+	book.Settings.TokenBudgetCap = 1024
+	book.Settings.InsertionStrategy = property.Integer(InsertionEvenly)
+	book.Settings.CaseSensitive = true
+
+	// The typed extensions are merged into the extensions (over the raw values), without modifying the raw map
+	data, err := sonicx.Config.Marshal(book)
+	require.NoError(t, err)
+	var raw struct {
+		Extensions map[string]any `json:"extensions"`
+	}
+	require.NoError(t, sonicx.Config.Unmarshal(data, &raw))
+	assert.Equal(t, map[string]any{
+		"theme":               "castle",
+		BookTokenBudgetCap:    float64(1024),
+		BookInsertionStrategy: float64(InsertionEvenly),
+		BookMinActivations:    float64(0),
+		BookMaxDepth:          float64(0),
+		BookCaseSensitive:     true,
+	}, raw.Extensions)
+	assert.Equal(t, map[string]any{"theme": "castle", BookMaxDepth: 99.0}, book.Extensions)
+
+	// The typed extensions are read back
+	var decoded Book
+	require.NoError(t, sonicx.Config.Unmarshal(data, &decoded))
+	assert.Equal(t, book.Settings, decoded.Settings)
+	assert.Equal(t, map[string]any{"theme": "castle"}, decoded.Extensions)
+
+	// A sheet keeps them as well
+	sheet := DefaultSheet(RevisionV3)
+	sheet.CharacterBook = book
+	sheet.CharacterBook.Entries = []*BookEntry{FilledBookEntry("key", "content")}
+	sheetData, err := sheet.ToBytes()
+	require.NoError(t, err)
+	reparsed, err := FromBytes(sheetData)
+	require.NoError(t, err)
+	assert.Equal(t, book.Settings, reparsed.CharacterBook.Settings)
+}
+
+func TestBookExtensions_Sanitize(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    BookExtensions
+		expected BookExtensions
+	}{
+		{
+			name:     "Valid values are kept",
+			input:    BookExtensions{TokenBudgetCap: 100, InsertionStrategy: property.Integer(InsertionGlobalFirst), MinActivations: 2, MaxDepth: 3},
+			expected: BookExtensions{TokenBudgetCap: 100, InsertionStrategy: property.Integer(InsertionGlobalFirst), MinActivations: 2, MaxDepth: 3},
+		},
+		{
+			name:     "Out-of-range values",
+			input:    BookExtensions{TokenBudgetCap: -1, InsertionStrategy: 7, MinActivations: -2, MaxDepth: -3, CaseSensitive: true},
+			expected: BookExtensions{InsertionStrategy: property.Integer(DefaultInsertionStrategy), CaseSensitive: true},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.input.Sanitize()
+			assert.Equal(t, tt.expected, tt.input)
+		})
+	}
+}
+
+func TestBookExtensions_Strict(t *testing.T) {
+	input := `{"spec": "chara_card_v3", "spec_version": "3.0", "data": {"name": "Lore", "character_book": {"max_depth": 2, "entries": []}}}`
+
+	_, err := FromBytesWithOptions([]byte(input), DecodeOptions{StrictFields: true})
+	assert.NoError(t, err)
+	_, err = FromBytesWithOptions([]byte(input), DecodeOptions{StrictFields: true, RejectAliases: true})
+	assert.ErrorContains(t, err, "data.character_book.max_depth")
+}
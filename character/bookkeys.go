@@ -0,0 +1,119 @@
+package character
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/r3dpixel/card-parser/property"
+)
+
+// ErrInvalidRegexKey is returned (joined for every failing key) when a key of a regex entry does not compile
+var ErrInvalidRegexKey = errors.New("invalid regex key")
+
+// RegexKeyOptions options of the regex key validation (see BookEntry.ValidateRegex)
+type RegexKeyOptions struct {
+	// RawPatterns compiles the keys as is, without translating the JS regex literals (/pattern/flags) first
+	RawPatterns bool
+}
+
+// jsRegexLiteral regex matching a JS regex literal: /pattern/flags
+var jsRegexLiteral = regexp.MustCompile(`^/(.+)/([a-z]*)$`)
+
+// NormalizeKeys trims the keys and secondary keys, and drops the blank and duplicate (case-insensitive) ones
+// The first occurrence of a duplicate is kept
+func (e *BookEntry) NormalizeKeys() {
+	e.Keys = normalizeKeys(e.Keys)
+	e.SecondaryKeys = normalizeKeys(e.SecondaryKeys)
+}
+
+// ValidateRegex returns an error for every key (and secondary key) that does not compile, if the entry uses regex keys
+// JS regex literals are translated first (unless RegexKeyOptions.RawPatterns is set), so /pattern/i is checked as
+// (?i)pattern
+func (e *BookEntry) ValidateRegex(opts ...RegexKeyOptions) []error {
+	// Skip plain keys
+	if !e.UseRegex {
+		return nil
+	}
+
+	// Compile every key
+	translate := len(opts) == 0 || !opts[0].RawPatterns
+	var errs []error
+	for _, group := range []struct {
+		field string
+		keys  property.StringArray
+	}{{"keys", e.Keys}, {"secondary_keys", e.SecondaryKeys}} {
+		for index, key := range group.keys {
+			pattern := key
+			if translate {
+				pattern = TranslateJSRegex(key)
+			}
+			if _, err := regexp.Compile(pattern); err != nil {
+				errs = append(errs, fmt.Errorf("%w: %s[%d] %q: %v", ErrInvalidRegexKey, group.field, index, key, err))
+			}
+		}
+	}
+	return errs
+}
+
+// TranslateJSRegex translates a JS regex literal (/pattern/flags) into the Go syntax (best-effort)
+// The i, m and s flags become an inline (?ims) prefix, the other flags (g, u, y, d) are dropped;
+// keys that are not JS regex literals are returned as is
+func TranslateJSRegex(key string) string {
+	// Skip the keys that are not JS regex literals
+	match := jsRegexLiteral.FindStringSubmatch(strings.TrimSpace(key))
+	if match == nil {
+		return key
+	}
+
+	// Keep the flags supported by Go
+	var flags strings.Builder
+	for _, flag := range match[2] {
+		if strings.ContainsRune("ims", flag) && !strings.ContainsRune(flags.String(), flag) {
+			flags.WriteRune(flag)
+		}
+	}
+
+	// Prefix the pattern with the flags
+	if flags.Len() == 0 {
+		return match[1]
+	}
+	return "(?" + flags.String() + ")" + match[1]
+}
+
+// Normalize mirrors the name and comment, normalizes the keys, and sanitizes the extensions of every entry (and the
+// typed extensions of the book); returns the joined regex errors of the entries (nil if every regex key compiles)
+func (b *Book) Normalize() error {
+	b.Settings.Sanitize()
+	var errs []error
+	for index, entry := range b.Entries {
+		if entry == nil {
+			continue
+		}
+		entry.MirrorNameAndComment()
+		entry.NormalizeKeys()
+		entry.Extensions.Sanitize()
+		for _, err := range entry.ValidateRegex() {
+			errs = append(errs, fmt.Errorf("entry %d: %w", index, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// normalizeKeys returns the trimmed keys without the blank and duplicate (case-insensitive) ones (nil if nil)
+func normalizeKeys(keys property.StringArray) property.StringArray {
+	if keys == nil {
+		return nil
+	}
+	normalized := make(property.StringArray, 0, len(keys))
+	seen := make(map[string]bool, len(keys))
+	for _, key := range keys {
+		key = strings.TrimSpace(key)
+		if folded := strings.ToLower(key); key != "" && !seen[folded] {
+			seen[folded] = true
+			normalized = append(normalized, key)
+		}
+	}
+	return normalized
+}
@@ -0,0 +1,123 @@
+package character
+
+import (
+	"testing"
+
+	"github.com/r3dpixel/card-parser/property"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBookEntry_NormalizeKeys(t *testing.T) {
+	tests := []struct {
+		name     string
+		keys     property.StringArray
+		expected property.StringArray
+	}{
+		{"Nil keys", nil, nil},
+		{"Empty keys", property.StringArray{}, property.StringArray{}},
+		{"Trimmed keys", property.StringArray{"  alice ", "\tbob\n"}, property.StringArray{"alice", "bob"}},
+		{"Blank keys", property.StringArray{"", "  ", "alice"}, property.StringArray{"alice"}},
+		{"Duplicate keys", property.StringArray{"Alice", "alice ", "ALICE", "bob"}, property.StringArray{"Alice", "bob"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entry := DefaultBookEntry()
+			entry.Keys = tt.keys
+			entry.SecondaryKeys = tt.keys
+			entry.NormalizeKeys()
+			assert.Equal(t, tt.expected, entry.Keys)
+			assert.Equal(t, tt.expected, entry.SecondaryKeys)
+		})
+	}
+}
+
+func TestBookEntry_ValidateRegex(t *testing.T) {
+	tests := []struct {
+		name     string
+		useRegex bool
+		keys     property.StringArray
+		failing  int
+	}{
+		{"Valid patterns", true, property.StringArray{`alice|bob`, `\bcat\b`}, 0},
+		{"Plain keys", false, property.StringArray{`(?<=mr\. )smith`, `[unclosed`}, 0},
+		{"Lookbehind pattern", true, property.StringArray{`(?<=mr\. )smith`}, 1},
+		{"JS literal with flags", true, property.StringArray{`/slash/i`, `/a.b/gims`}, 0},
+		{"Invalid JS literal", true, property.StringArray{`/(?<!no)way/i`, `valid`}, 1},
+		{"Unclosed group", true, property.StringArray{`(alice`, `[bob`}, 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entry := DefaultBookEntry()
+			entry.UseRegex = property.Bool(tt.useRegex)
+			entry.Keys = tt.keys
+			errs := entry.ValidateRegex()
+			assert.Len(t, errs, tt.failing)
+			for _, err := range errs {
+				assert.ErrorIs(t, err, ErrInvalidRegexKey)
+			}
+		})
+	}
+
+	t.Run("Error names the key", func(t *testing.T) {
+		entry := DefaultBookEntry()
+		entry.SecondaryKeys = property.StringArray{"ok", `(?<=x)y`}
+		errs := entry.ValidateRegex()
+		require.Len(t, errs, 1)
+		assert.Contains(t, errs[0].Error(), `secondary_keys[1] "(?<=x)y"`)
+	})
+
+	t.Run("Untranslated JS literal", func(t *testing.T) {
+		entry := DefaultBookEntry()
+		entry.UseRegex = true
+		entry.Keys = property.StringArray{`/*/`}
+		assert.Len(t, entry.ValidateRegex(), 1)
+		assert.Empty(t, entry.ValidateRegex(RegexKeyOptions{RawPatterns: true}))
+	})
+}
+
+func TestTranslateJSRegex(t *testing.T) {
+	tests := []struct {
+		key      string
+		expected string
+	}{
+		{`/slash/i`, `(?i)slash`},
+		{`/slash/`, `slash`},
+		{`/a.b/gimsuy`, `(?ims)a.b`},
+		{`/x/ii`, `(?i)x`},
+		{` /padded/i `, `(?i)padded`},
+		{`plain`, `plain`},
+		{`/`, `/`},
+		{`//i`, `//i`},
+		{`a/b/c`, `a/b/c`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			assert.Equal(t, tt.expected, TranslateJSRegex(tt.key))
+		})
+	}
+}
+
+func TestBook_Normalize(t *testing.T) {
+	book := &Book{Entries: []*BookEntry{
+		{BookEntryCore: BookEntryCore{Name: "Alice", Keys: property.StringArray{" alice", "Alice", "/ali(ce)?/i"}, UseRegex: true}},
+		nil,
+		{BookEntryCore: BookEntryCore{Comment: "Bob", Keys: property.StringArray{"(?<=mr )bob", ""}, UseRegex: true}},
+	}}
+
+	err := book.Normalize()
+	require.ErrorIs(t, err, ErrInvalidRegexKey)
+	assert.Contains(t, err.Error(), "entry 2:")
+	assert.NotContains(t, err.Error(), "entry 0:")
+
+	assert.Equal(t, property.StringArray{"alice", "/ali(ce)?/i"}, book.Entries[0].Keys)
+	assert.Equal(t, property.String("Alice"), book.Entries[0].Comment)
+	assert.Equal(t, property.StringArray{"(?<=mr )bob"}, book.Entries[2].Keys)
+	assert.Equal(t, property.String("Bob"), book.Entries[2].Name)
+
+	book.Entries[2].Keys = property.StringArray{"bob"}
+	assert.NoError(t, book.Normalize())
+}
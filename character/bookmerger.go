@@ -1,19 +1,61 @@
 package character
 
 import (
+	"maps"
+	"slices"
 	"strings"
 
 	"github.com/r3dpixel/card-parser/property"
-	"github.com/r3dpixel/toolkit/ptr"
 	"github.com/r3dpixel/toolkit/stringsx"
 )
 
+// DedupMode defines how the BookMerger deduplicates the appended entries
+type DedupMode int
+
+// DedupMode values
+const (
+	DedupNone   DedupMode = iota // Every entry is appended (default)
+	DedupExact                   // Entries with the same content and the same keys are appended once
+	DedupByKeys                  // Entries with the same content are appended once, with the union of their keys
+)
+
+// MergeSourceKey is the reserved entry extension key holding the source label of the entries appended by
+// BookMerger.AppendBookWithSource
+const MergeSourceKey = "merge_source"
+
+// MergeSource returns the source label recorded on the entry by BookMerger.AppendBookWithSource (empty if unlabeled)
+func (e *BookEntry) MergeSource() string {
+	source, _ := e.RawExtensions[MergeSourceKey].(string)
+	return source
+}
+
 // BookMerger merges multiple lorebooks through a safe API
 type BookMerger struct {
 	book               *Book
 	nameBuilder        *tokenAppender
 	descriptionBuilder *tokenAppender
 	entryIndex         int
+	dedup              DedupMode
+	contentIndex       map[string][]*BookEntry
+	sourcePrefix       bool
+	source             string            // Source label of the book being appended (empty if unlabeled)
+	extensionSources   map[string]string // Source label of the book each merged extension was taken from
+	settingsAppended   bool              // Set once the typed extensions of a book were appended
+	orderStep          int               // Gap between the insertion orders rewritten by BuildSorted (0 if default)
+	report             MergeReport
+}
+
+// MergeReport summary of the books appended to a BookMerger
+type MergeReport struct {
+	SourceCounts       map[string]int      // Number of entries appended from each source label (duplicates included)
+	ExtensionConflicts []ExtensionConflict // Book extensions dropped because another source set them first
+}
+
+// ExtensionConflict book extension key set with different values by several sources (the first value is kept)
+type ExtensionConflict struct {
+	Key           string // Extension key (dot separated path for nested keys, e.g. world.name)
+	KeptSource    string // Source label of the kept value (empty if unlabeled)
+	DroppedSource string // Source label of the dropped value (empty if unlabeled)
 }
 
 // NewBookMerger creates a new lorebook merger
@@ -27,6 +69,35 @@ func NewBookMerger() *BookMerger {
 	return merger
 }
 
+// WithDeduplication sets the deduplication mode of the appended entries (contents are compared case and whitespace
+// insensitively); the surviving entry keeps the lowest insertion order, and the extensions of the skipped entries
+// are merged into it without overwriting
+func (bm *BookMerger) WithDeduplication(mode DedupMode) *BookMerger {
+	bm.dedup = mode
+	return bm
+}
+
+// WithInsertionOrderStep sets the gap between the insertion orders rewritten by BuildSorted (a non-positive step
+// defaults to DefaultInsertionOrderStep)
+func (bm *BookMerger) WithInsertionOrderStep(step int) *BookMerger {
+	bm.orderStep = step
+	return bm
+}
+
+// WithSourcePrefix prefixes the comment of the entries appended by AppendBookWithSource with "[source] "
+func (bm *BookMerger) WithSourcePrefix(enabled bool) *BookMerger {
+	bm.sourcePrefix = enabled
+	return bm
+}
+
+// AppendBookWithSource appends the given lorebook, recording the source label on each of its entries (under the
+// MergeSourceKey extension), and counting its entries in the Report
+func (bm *BookMerger) AppendBookWithSource(book *Book, source string) {
+	bm.source = source
+	defer func() { bm.source = "" }()
+	bm.AppendBook(book)
+}
+
 // AppendBook appends the given lorebook
 func (bm *BookMerger) AppendBook(book *Book) {
 	// If the book is nil, return (NO-OP)
@@ -43,7 +114,7 @@ func (bm *BookMerger) AppendBook(book *Book) {
 	}
 
 	// Append book properties
-	bm.AppendProperties(int(book.ScanDepth), int(book.TokenBudget), bool(book.RecursiveScanning))
+	bm.AppendProperties(int(book.ScanDepth), int(book.TokenBudget), bool(book.RecursiveScanning), book.Settings)
 
 	// Append book name and description
 	bm.AppendNameAndDescription(string(book.Name), string(book.Description))
@@ -56,13 +127,23 @@ func (bm *BookMerger) AppendBook(book *Book) {
 }
 
 // AppendProperties compute new properties of the merged book
-func (bm *BookMerger) AppendProperties(scanDepth int, tokenBudget int, recursiveScanning bool) {
+// The typed extensions (if given) are reconciled: the numeric caps keep the maximum value, case sensitivity is turned
+// on by any book, and the insertion strategy of the first book is kept
+func (bm *BookMerger) AppendProperties(scanDepth int, tokenBudget int, recursiveScanning bool, settings ...BookExtensions) {
 	// Scan depth will always be the maximum scan depth found
 	bm.book.ScanDepth = max(bm.book.ScanDepth, property.Integer(scanDepth))
 	// Token Budget will always have the maximum value found
 	bm.book.TokenBudget = max(bm.book.TokenBudget, property.Integer(tokenBudget))
 	// Recursive scanning will be turned on, if at least one book has recursive scanning
 	bm.book.RecursiveScanning = bm.book.RecursiveScanning || property.Bool(recursiveScanning)
+	// Typed extensions are reconciled (the first insertion strategy is kept)
+	for _, bookSettings := range settings {
+		if !bm.settingsAppended {
+			bm.book.Settings.InsertionStrategy = bookSettings.InsertionStrategy
+			bm.settingsAppended = true
+		}
+		bm.book.Settings.merge(bookSettings)
+	}
 }
 
 // AppendNameAndDescription appends the name and description to the merged book
@@ -78,38 +159,171 @@ func (bm *BookMerger) AppendEntries(entries []*BookEntry) {
 	}
 }
 
-// AppendEntry appends the given entry
+// AppendEntry appends the given entry (duplicates are merged into the existing entry if deduplication is enabled)
 func (bm *BookMerger) AppendEntry(entry *BookEntry) {
 	// Mirror the name and comment for SillyTavern
 	entry.MirrorNameAndComment()
+	// Record the source of the entry (if labeled)
+	bm.recordSource(entry)
+	// Merge the entry into its duplicate (if any), without consuming an ID
+	if bm.mergeDuplicate(entry) {
+		return
+	}
 	// Assign the entryIndex as the ID of the entry
-	entry.ID = property.Union{IntValue: ptr.Of(bm.entryIndex)}
+	entry.ID = property.UnionFromInt(bm.entryIndex)
 	// Append the entry to the merged book
 	bm.book.Entries = append(bm.book.Entries, entry)
 	// Increment the entry index for the next entry
 	bm.entryIndex++
 }
 
+// mergeDuplicate merges the entry into an already appended duplicate, and returns true if a duplicate was found
+// Entries that are not duplicates are indexed by content
+func (bm *BookMerger) mergeDuplicate(entry *BookEntry) bool {
+	// Skip if deduplication is disabled
+	if bm.dedup == DedupNone {
+		return false
+	}
+
+	// Find a duplicate among the entries with the same content
+	content := normalizeContent(string(entry.Content))
+	for _, existing := range bm.contentIndex[content] {
+		if bm.dedup == DedupExact && !sameKeys(existing.Keys, entry.Keys) {
+			continue
+		}
+		if bm.dedup == DedupExact && !sameKeys(existing.SecondaryKeys, entry.SecondaryKeys) {
+			continue
+		}
+
+		// Keep the lowest insertion order and the union of the keys
+		existing.InsertionOrder = min(existing.InsertionOrder, entry.InsertionOrder)
+		existing.Keys = unionStrings(existing.Keys, entry.Keys, normalizeKey)
+		existing.SecondaryKeys = unionStrings(existing.SecondaryKeys, entry.SecondaryKeys, normalizeKey)
+
+		// Merge the extensions without overwriting
+		for k, v := range entry.RawExtensions {
+			if existing.RawExtensions == nil {
+				existing.RawExtensions = make(map[string]any, len(entry.RawExtensions))
+			}
+			if _, duplicate := existing.RawExtensions[k]; !duplicate {
+				existing.RawExtensions[k] = v
+			}
+		}
+		return true
+	}
+
+	// Index the entry by content
+	if bm.contentIndex == nil {
+		bm.contentIndex = make(map[string][]*BookEntry)
+	}
+	bm.contentIndex[content] = append(bm.contentIndex[content], entry)
+	return false
+}
+
+// recordSource records the source label of the book being appended on the entry, and counts the entry
+func (bm *BookMerger) recordSource(entry *BookEntry) {
+	// Skip unlabeled entries
+	if bm.source == "" {
+		return
+	}
+
+	// Record the source in the extensions
+	if entry.RawExtensions == nil {
+		entry.RawExtensions = make(map[string]any, 1)
+	}
+	entry.RawExtensions[MergeSourceKey] = bm.source
+
+	// Prefix the comment with the source
+	if bm.sourcePrefix {
+		entry.Comment = property.String("[" + bm.source + "] " + string(entry.Comment))
+	}
+
+	// Count the entry
+	if bm.report.SourceCounts == nil {
+		bm.report.SourceCounts = make(map[string]int)
+	}
+	bm.report.SourceCounts[bm.source]++
+}
+
+// normalizeContent returns the content lowercased, with the whitespace collapsed
+func normalizeContent(content string) string {
+	return strings.Join(strings.Fields(strings.ToLower(content)), " ")
+}
+
+// normalizeKey returns the key lowercased and trimmed
+func normalizeKey(key string) string {
+	return strings.ToLower(strings.TrimSpace(key))
+}
+
+// sameKeys checks if both key lists contain the same keys (case-insensitive, regardless of order and duplicates)
+func sameKeys(keys property.StringArray, other property.StringArray) bool {
+	set := make(map[string]bool, len(keys))
+	for _, key := range keys {
+		if normalized := normalizeKey(key); normalized != "" {
+			set[normalized] = true
+		}
+	}
+	otherSet := make(map[string]bool, len(other))
+	for _, key := range other {
+		if normalized := normalizeKey(key); normalized != "" {
+			if !set[normalized] {
+				return false
+			}
+			otherSet[normalized] = true
+		}
+	}
+	return len(set) == len(otherSet)
+}
+
 // AppendMapExtensions Append extension map
+// The extensions are deep merged (see MergeExtensions): the first value of a conflicting key is kept, and the
+// conflict is reported
 func (bm *BookMerger) AppendMapExtensions(extensions map[string]any) {
 	// If the extensions map is empty, return (NO-OP)
 	if len(extensions) == 0 {
 		return
 	}
 
-	// Create a merged book extensions map (if it doesn't exist)
-	if bm.book.Extensions == nil {
-		bm.book.Extensions = make(map[string]any)
+	// Record the source of the new keys
+	for key := range extensions {
+		if _, exists := bm.book.Extensions[key]; !exists {
+			bm.recordExtensionSource(key)
+		}
 	}
 
-	// Copy extensions into accumulator
-	for k, v := range extensions {
-		if _, duplicate := bm.book.Extensions[k]; !duplicate {
-			bm.book.Extensions[k] = v
-		}
+	// Merge the extensions into the accumulator (the first value is kept, different values are reported as conflicts)
+	merged, conflicts := MergeExtensions(bm.book.Extensions, extensions, MergeKeepDst)
+	bm.book.Extensions = merged
+	for _, conflict := range conflicts {
+		bm.report.ExtensionConflicts = append(bm.report.ExtensionConflicts, ExtensionConflict{
+			Key:           conflict.Path,
+			KeptSource:    bm.extensionSources[extensionRoot(conflict.Path)],
+			DroppedSource: bm.source,
+		})
 	}
 }
 
+// recordExtensionSource records the source label of the book the extension was taken from
+func (bm *BookMerger) recordExtensionSource(key string) {
+	if bm.extensionSources == nil {
+		bm.extensionSources = make(map[string]string)
+	}
+	bm.extensionSources[key] = bm.source
+}
+
+// Report returns the summary of the appended books (complete once every book is appended, e.g. after Build)
+// The conflicts are sorted by key (in order of appearance for the same key)
+func (bm *BookMerger) Report() MergeReport {
+	report := MergeReport{
+		SourceCounts:       maps.Clone(bm.report.SourceCounts),
+		ExtensionConflicts: slices.Clone(bm.report.ExtensionConflicts),
+	}
+	slices.SortStableFunc(report.ExtensionConflicts, func(a, b ExtensionConflict) int {
+		return strings.Compare(a.Key, b.Key)
+	})
+	return report
+}
+
 // Build builds the merged book
 func (bm *BookMerger) Build() *Book {
 	// If there are no entries, return nil (no book needed)
@@ -127,6 +341,23 @@ func (bm *BookMerger) Build() *Book {
 	return bm.book
 }
 
+// BuildSorted builds the merged book, sorts the entries by insertion order (stable), and rewrites the insertion orders
+// with DefaultInsertionOrderStep gaps (see WithInsertionOrderStep), so entries coming from different books are injected in a predictable order
+func (bm *BookMerger) BuildSorted() *Book {
+	// Build the merged book
+	book := bm.Build()
+	if book == nil {
+		return nil
+	}
+
+	// Sort the entries and rewrite the insertion orders
+	book.SortEntries(EntryByInsertionOrder)
+	book.ReindexInsertionOrder(bm.orderStep)
+
+	// Return the sorted book
+	return book
+}
+
 // tokenAppender token appender that handles adding separators between tokens automatically
 type tokenAppender struct {
 	stringBuilder      strings.Builder
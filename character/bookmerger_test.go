@@ -5,6 +5,7 @@ import (
 
 	"github.com/r3dpixel/card-parser/property"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestNewBookMerger(t *testing.T) {
@@ -77,6 +78,32 @@ func TestBookMerger_AppendEntry(t *testing.T) {
 	assert.Equal(t, merger.book.Entries[0].Name, merger.book.Entries[0].Comment)
 }
 
+func TestBookMerger_AppendProperties_Settings(t *testing.T) {
+	merger := NewBookMerger()
+	merger.AppendBook(&Book{
+		Settings: BookExtensions{TokenBudgetCap: 1000, InsertionStrategy: property.Integer(InsertionGlobalFirst), MaxDepth: 8},
+		Entries:  []*BookEntry{FilledBookEntry("first", "content")},
+	})
+	merger.AppendBook(&Book{
+		Settings: BookExtensions{TokenBudgetCap: 500, InsertionStrategy: property.Integer(InsertionEvenly), MinActivations: 3, CaseSensitive: true},
+		Entries:  []*BookEntry{FilledBookEntry("second", "content")},
+	})
+
+	// Max wins for the numeric caps, case sensitivity is turned on, and the first insertion strategy is kept
+	assert.Equal(t, BookExtensions{
+		TokenBudgetCap:    1000,
+		InsertionStrategy: property.Integer(InsertionGlobalFirst),
+		MinActivations:    3,
+		MaxDepth:          8,
+		CaseSensitive:     true,
+	}, merger.Build().Settings)
+
+	// Properties appended without typed extensions leave them untouched
+	merger = NewBookMerger()
+	merger.AppendProperties(1, 2, false)
+	assert.Equal(t, DefaultBookExtensions(), merger.book.Settings)
+}
+
 func TestBookMerger_AppendMapExtensions(t *testing.T) {
 	merger := NewBookMerger()
 	merger.AppendMapExtensions(map[string]any{"key1": "val1"})
@@ -138,6 +165,37 @@ func TestBookMerger_Build(t *testing.T) {
 	})
 }
 
+func TestBookMerger_BuildSorted(t *testing.T) {
+	t.Run("BuildSorted returns nil if no entries", func(t *testing.T) {
+		assert.Nil(t, NewBookMerger().BuildSorted())
+	})
+
+	t.Run("BuildSorted sorts and reindexes the entries", func(t *testing.T) {
+		merger := NewBookMerger()
+		merger.AppendBook(&Book{Entries: []*BookEntry{sortEntry("first-a", 10, nil), sortEntry("first-b", 30, nil)}})
+		merger.AppendBook(&Book{Entries: []*BookEntry{sortEntry("second-a", 10, nil), sortEntry("second-b", 5, nil)}})
+
+		book := merger.BuildSorted()
+
+		// Equal insertion orders keep their append order
+		assert.Equal(t, []string{"second-b", "first-a", "second-a", "first-b"}, entryNames(book.Entries))
+		for index, entry := range book.Entries {
+			assert.Equal(t, property.Integer(index*DefaultInsertionOrderStep), entry.InsertionOrder)
+		}
+		// The IDs assigned by the merger are left untouched
+		assert.Equal(t, 3, *book.Entries[0].ID.IntValue)
+		assert.Equal(t, 0, *book.Entries[1].ID.IntValue)
+	})
+	t.Run("BuildSorted uses the configured step", func(t *testing.T) {
+		merger := NewBookMerger().WithInsertionOrderStep(100)
+		merger.AppendBook(&Book{Entries: []*BookEntry{sortEntry("b", 20, nil), sortEntry("a", 10, nil)}})
+
+		book := merger.BuildSorted()
+		assert.Equal(t, []string{"a", "b"}, entryNames(book.Entries))
+		assert.Equal(t, property.Integer(100), book.Entries[1].InsertionOrder)
+	})
+}
+
 func TestBookMerger_AppendEntries(t *testing.T) {
 	merger := NewBookMerger()
 	entries := []*BookEntry{
@@ -253,3 +311,191 @@ func TestTokenAppender(t *testing.T) {
 		assert.Equal(t, 0, appender.nonEmptyTokenIndex)
 	})
 }
+
+func TestBookMerger_WithDeduplication(t *testing.T) {
+	// entries returns fresh entries: duplicates differing by case/whitespace, keys, insertion order and extensions
+	entries := func() []*BookEntry {
+		first := FilledBookEntry("Castle", "A large  stone castle.")
+		first.InsertionOrder = 20
+		first.RawExtensions = map[string]any{"weight": 10}
+
+		sameKeys := FilledBookEntry("castle ", "a large stone\ncastle.")
+		sameKeys.InsertionOrder = 5
+		sameKeys.SecondaryKeys = property.StringArray{}
+		sameKeys.RawExtensions = map[string]any{"weight": 99, "group": "places"}
+
+		otherKeys := FilledBookEntry("Fortress", "A LARGE STONE CASTLE.")
+		otherKeys.SecondaryKeys = property.StringArray{"walls"}
+
+		other := FilledBookEntry("King", "An old king.")
+		return []*BookEntry{first, sameKeys, otherKeys, other}
+	}
+
+	tests := []struct {
+		name          string
+		mode          DedupMode
+		expectedNames []property.String
+		expectedKeys  property.StringArray
+		secondaryKeys property.StringArray
+		order         property.Integer
+		extensions    map[string]any
+	}{
+		{
+			name:          "Disabled",
+			mode:          DedupNone,
+			expectedNames: []property.String{"Castle", "castle ", "Fortress", "King"},
+			expectedKeys:  property.StringArray{"Castle"},
+			secondaryKeys: property.StringArray{},
+			order:         20,
+			extensions:    map[string]any{"weight": 10},
+		},
+		{
+			name:          "Exact",
+			mode:          DedupExact,
+			expectedNames: []property.String{"Castle", "Fortress", "King"},
+			expectedKeys:  property.StringArray{"Castle"},
+			secondaryKeys: property.StringArray{},
+			order:         5,
+			extensions:    map[string]any{"weight": 10, "group": "places"},
+		},
+		{
+			name:          "By keys",
+			mode:          DedupByKeys,
+			expectedNames: []property.String{"Castle", "King"},
+			expectedKeys:  property.StringArray{"Castle", "Fortress"},
+			secondaryKeys: property.StringArray{"walls"},
+			order:         5,
+			extensions:    map[string]any{"weight": 10, "group": "places"},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			merger := NewBookMerger().WithDeduplication(tc.mode)
+			merger.AppendEntries(entries())
+			book := merger.Build()
+			require.NotNil(t, book)
+
+			// Surviving entries, with contiguous IDs
+			names := make([]property.String, 0, len(book.Entries))
+			for index, entry := range book.Entries {
+				names = append(names, entry.Name)
+				require.NotNil(t, entry.ID.IntValue)
+				assert.Equal(t, index, *entry.ID.IntValue)
+			}
+			assert.Equal(t, tc.expectedNames, names)
+			assert.Equal(t, len(book.Entries), merger.entryIndex)
+
+			// Merged entry
+			merged := book.Entries[0]
+			assert.Equal(t, tc.expectedKeys, merged.Keys)
+			assert.Equal(t, tc.secondaryKeys, merged.SecondaryKeys)
+			assert.Equal(t, tc.order, merged.InsertionOrder)
+			assert.Equal(t, tc.extensions, merged.RawExtensions)
+		})
+	}
+
+	t.Run("Across books", func(t *testing.T) {
+		merger := NewBookMerger().WithDeduplication(DedupByKeys)
+		merger.AppendBook(&Book{Name: "Embedded", Entries: []*BookEntry{FilledBookEntry("King", "An old king.")}})
+		merger.AppendBook(&Book{Name: "World", Entries: []*BookEntry{
+			FilledBookEntry("Queen", "A young queen."),
+			FilledBookEntry("Monarch", "  an OLD king. "),
+		}})
+		book := merger.Build()
+		require.Len(t, book.Entries, 2)
+		assert.Equal(t, property.StringArray{"King", "Monarch"}, book.Entries[0].Keys)
+		assert.Equal(t, 1, *book.Entries[1].ID.IntValue)
+	})
+}
+
+func TestBookMerger_AppendBookWithSource(t *testing.T) {
+	// books returns three fresh books, with conflicting and shared extensions
+	books := func() []*Book {
+		return []*Book{
+			{Name: "Kingdom", Extensions: map[string]any{"theme": "medieval", "lang": "en"}, Entries: []*BookEntry{
+				FilledBookEntry("King", "An old king."),
+				FilledBookEntry("Castle", "A stone castle."),
+			}},
+			{Name: "Forest", Extensions: map[string]any{"theme": "nature", "lang": "en"}, Entries: []*BookEntry{
+				FilledBookEntry("Elf", "A tall elf."),
+			}},
+			{Name: "Sea", Extensions: map[string]any{"theme": "ocean", "tides": true}, Entries: []*BookEntry{
+				FilledBookEntry("Ship", "A wooden ship."),
+				FilledBookEntry("Kraken", "A giant squid."),
+				FilledBookEntry("Port", "A busy port."),
+			}},
+		}
+	}
+	sources := []string{"kingdom.json", "forest.json", "sea.json"}
+
+	tests := []struct {
+		name     string
+		prefix   bool
+		comments []property.String
+	}{
+		{"Without prefix", false, []property.String{"King", "Castle", "Elf", "Ship", "Kraken", "Port"}},
+		{"With prefix", true, []property.String{
+			"[kingdom.json] King", "[kingdom.json] Castle", "[forest.json] Elf",
+			"[sea.json] Ship", "[sea.json] Kraken", "[sea.json] Port",
+		}},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			merger := NewBookMerger().WithSourcePrefix(tc.prefix)
+			for index, book := range books() {
+				merger.AppendBookWithSource(book, sources[index])
+			}
+			book := merger.Build()
+			require.Len(t, book.Entries, 6)
+
+			// Provenance and comments
+			expectedSources := []string{"kingdom.json", "kingdom.json", "forest.json", "sea.json", "sea.json", "sea.json"}
+			for index, entry := range book.Entries {
+				assert.Equal(t, expectedSources[index], entry.RawExtensions[MergeSourceKey])
+				assert.Equal(t, tc.comments[index], entry.Comment)
+			}
+			assert.Equal(t, property.String("King"), book.Entries[0].Name)
+
+			// Report
+			report := merger.Report()
+			assert.Equal(t, map[string]int{"kingdom.json": 2, "forest.json": 1, "sea.json": 3}, report.SourceCounts)
+			assert.Equal(t, []ExtensionConflict{
+				{Key: "theme", KeptSource: "kingdom.json", DroppedSource: "forest.json"},
+				{Key: "theme", KeptSource: "kingdom.json", DroppedSource: "sea.json"},
+			}, report.ExtensionConflicts)
+			assert.Equal(t, "medieval", book.Extensions["theme"])
+			assert.Equal(t, true, book.Extensions["tides"])
+		})
+	}
+
+	t.Run("Unlabeled books", func(t *testing.T) {
+		merger := NewBookMerger().WithSourcePrefix(true)
+		merger.AppendBook(books()[0])
+		merger.AppendBookWithSource(books()[1], "forest.json")
+		book := merger.Build()
+		require.Len(t, book.Entries, 3)
+		assert.NotContains(t, book.Entries[0].RawExtensions, MergeSourceKey)
+		assert.Equal(t, property.String("King"), book.Entries[0].Comment)
+
+		report := merger.Report()
+		assert.Equal(t, map[string]int{"forest.json": 1}, report.SourceCounts)
+		assert.Equal(t, []ExtensionConflict{{Key: "theme", KeptSource: "", DroppedSource: "forest.json"}}, report.ExtensionConflicts)
+	})
+
+	t.Run("Empty report", func(t *testing.T) {
+		report := NewBookMerger().Report()
+		assert.Empty(t, report.SourceCounts)
+		assert.Empty(t, report.ExtensionConflicts)
+	})
+}
+
+func TestBookMerger_AppendMapExtensions_Nested(t *testing.T) {
+	merger := NewBookMerger()
+	merger.AppendBookWithSource(&Book{Extensions: map[string]any{"world": map[string]any{"name": "Kingdom", "tags": []any{"castle"}}}}, "kingdom.json")
+	merger.AppendBookWithSource(&Book{Extensions: map[string]any{"world": map[string]any{"name": "Forest", "tags": []any{"elf"}}}}, "forest.json")
+
+	assert.Equal(t, map[string]any{"name": "Kingdom", "tags": []any{"castle", "elf"}}, merger.book.Extensions["world"])
+	assert.Equal(t, []ExtensionConflict{{Key: "world.name", KeptSource: "kingdom.json", DroppedSource: "forest.json"}}, merger.Report().ExtensionConflicts)
+}
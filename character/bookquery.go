@@ -0,0 +1,150 @@
+package character
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/r3dpixel/card-parser/property"
+)
+
+// EntryPredicate reports whether a lorebook entry matches (never called with nil entries)
+type EntryPredicate func(entry *BookEntry) bool
+
+// Enabled matches the enabled entries
+func Enabled() EntryPredicate {
+	return func(entry *BookEntry) bool { return bool(entry.Enabled) }
+}
+
+// Constant matches the constant entries (always inserted, regardless of the keys)
+func Constant() EntryPredicate {
+	return func(entry *BookEntry) bool { return bool(entry.Constant) }
+}
+
+// HasKey matches the entries having the key among their keys or secondary keys
+// (case-insensitive unless caseSensitive is set)
+func HasKey(key string, caseSensitive bool) EntryPredicate {
+	equal := strings.EqualFold
+	if caseSensitive {
+		equal = func(a, b string) bool { return a == b }
+	}
+	return func(entry *BookEntry) bool {
+		matches := func(candidate string) bool { return equal(candidate, key) }
+		return slices.ContainsFunc(entry.Keys, matches) || slices.ContainsFunc(entry.SecondaryKeys, matches)
+	}
+}
+
+// AtPosition matches the entries inserted at the lore position
+func AtPosition(position property.LorePosition) EntryPredicate {
+	return func(entry *BookEntry) bool { return entry.Extensions.LorePosition == position }
+}
+
+// WithRole matches the entries inserted with the role
+func WithRole(role property.Role) EntryPredicate {
+	return func(entry *BookEntry) bool { return entry.Extensions.Role == role }
+}
+
+// And matches the entries matching all the predicates (every entry if there are none)
+func And(predicates ...EntryPredicate) EntryPredicate {
+	return func(entry *BookEntry) bool {
+		for _, predicate := range predicates {
+			if !predicate(entry) {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+// Or matches the entries matching any of the predicates (no entry if there are none)
+func Or(predicates ...EntryPredicate) EntryPredicate {
+	return func(entry *BookEntry) bool {
+		for _, predicate := range predicates {
+			if predicate(entry) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// Not matches the entries not matching the predicate
+func Not(predicate EntryPredicate) EntryPredicate {
+	return func(entry *BookEntry) bool { return !predicate(entry) }
+}
+
+// Filter returns the entries matching the predicate, in order (the entries are shared with the book)
+func (b *Book) Filter(predicate EntryPredicate) []*BookEntry {
+	var matched []*BookEntry
+	for _, entry := range b.Entries {
+		if entry != nil && predicate(entry) {
+			matched = append(matched, entry)
+		}
+	}
+	return matched
+}
+
+// Remove removes the entries matching the predicate, and returns them in order
+// The remaining entries keep their order, IDs and insertion orders (nil entries are kept)
+func (b *Book) Remove(predicate EntryPredicate) []*BookEntry {
+	var removed []*BookEntry
+	b.Entries = slices.DeleteFunc(b.Entries, func(entry *BookEntry) bool {
+		if entry == nil || !predicate(entry) {
+			return false
+		}
+		removed = append(removed, entry)
+		return true
+	})
+	return removed
+}
+
+// Partition moves the entries matching the predicate into a new book (with a copy of the book settings and extensions),
+// and returns it; the entries of both books keep their order, IDs and insertion orders
+func (b *Book) Partition(predicate EntryPredicate) *Book {
+	partition := *b
+	partition.Extensions = cloneMap(b.Extensions)
+	partition.Entries = b.Remove(predicate)
+	return &partition
+}
+
+// Split groups the entries by the label returned by groupFn (the merge source of the entries if nil, see
+// BookEntry.MergeSource), and returns a new book per label, named after it (entries without a label go into the ""
+// book); it is the inverse of merging labeled books with BookMerger.AppendBookWithSource
+// Each book gets a copy of the book settings and extensions, and copies of its entries in order, without the
+// MergeSourceKey extension, and with their IDs renumbered from 0 (the book is not modified)
+func (b *Book) Split(groupFn func(*BookEntry) string) map[string]*Book {
+	if groupFn == nil {
+		groupFn = (*BookEntry).MergeSource
+	}
+
+	books := make(map[string]*Book)
+	for _, entry := range b.Entries {
+		if entry == nil {
+			continue
+		}
+
+		// Create the book of the label, with the shared properties
+		label := groupFn(entry)
+		book, ok := books[label]
+		if !ok {
+			book = &Book{
+				Name:              property.String(label),
+				ScanDepth:         b.ScanDepth,
+				TokenBudget:       b.TokenBudget,
+				RecursiveScanning: b.RecursiveScanning,
+				Extensions:        cloneMap(b.Extensions),
+				Settings:          b.Settings,
+			}
+			books[label] = book
+		}
+
+		// Append a copy of the entry, without its merge source
+		clone := entry.Clone()
+		delete(clone.RawExtensions, MergeSourceKey)
+		if len(clone.RawExtensions) == 0 {
+			clone.RawExtensions = nil
+		}
+		clone.ID = property.UnionFromInt(len(book.Entries))
+		book.Entries = append(book.Entries, clone)
+	}
+	return books
+}
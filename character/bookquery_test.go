@@ -0,0 +1,142 @@
+package character
+
+import (
+	"testing"
+
+	"github.com/r3dpixel/card-parser/property"
+	"github.com/r3dpixel/toolkit/ptr"
+	"github.com/stretchr/testify/assert"
+)
+
+// queryBook returns a book whose entries exercise every predicate
+func queryBook() *Book {
+	entry := func(name string, id int, enabled bool, constant bool, position property.LorePosition, role property.Role, keys []string, secondary []string) *BookEntry {
+		e := DefaultBookEntry()
+		e.ID = property.Union{IntValue: ptr.Of(id)}
+		e.Name = property.String(name)
+		e.InsertionOrder = property.Integer(id * 10)
+		e.Enabled = property.Bool(enabled)
+		e.Constant = property.Bool(constant)
+		e.Keys = keys
+		e.SecondaryKeys = secondary
+		e.Extensions.LorePosition = position
+		e.Extensions.Role = role
+		return e
+	}
+	return &Book{
+		Name: "Lore",
+		Entries: []*BookEntry{
+			entry("castle", 1, true, false, property.BeforeCharPosition, property.SystemRole, []string{"Castle"}, nil),
+			entry("dragon", 2, true, true, property.AtDepth, property.AssistantRole, []string{"wyrm"}, []string{"DRAGON"}),
+			nil,
+			entry("forest", 3, false, false, property.AtDepth, property.AssistantRole, []string{"forest"}, nil),
+			entry("king", 4, true, true, property.AtDepth, property.UserRole, nil, []string{"castle"}),
+		},
+	}
+}
+
+func TestBook_Filter(t *testing.T) {
+	tests := []struct {
+		name      string
+		predicate EntryPredicate
+		expected  []string
+	}{
+		{"Enabled", Enabled(), []string{"castle", "dragon", "king"}},
+		{"Constant", Constant(), []string{"dragon", "king"}},
+		{"Key case-insensitive", HasKey("castle", false), []string{"castle", "king"}},
+		{"Key case-sensitive", HasKey("castle", true), []string{"king"}},
+		{"Secondary key case-insensitive", HasKey("dragon", false), []string{"dragon"}},
+		{"Secondary key case-sensitive", HasKey("dragon", true), nil},
+		{"Position", AtPosition(property.AtDepth), []string{"dragon", "forest", "king"}},
+		{"Role", WithRole(property.AssistantRole), []string{"dragon", "forest"}},
+		{"And", And(Enabled(), AtPosition(property.AtDepth), WithRole(property.AssistantRole)), []string{"dragon"}},
+		{"Or", Or(HasKey("forest", false), WithRole(property.UserRole)), []string{"forest", "king"}},
+		{"Not", And(Enabled(), Not(Constant())), []string{"castle"}},
+		{"Empty And", And(), []string{"castle", "dragon", "forest", "king"}},
+		{"Empty Or", Or(), nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			matched := queryBook().Filter(tt.predicate)
+			if tt.expected == nil {
+				assert.Empty(t, matched)
+				return
+			}
+			assert.Equal(t, tt.expected, entryNames(matched))
+		})
+	}
+}
+
+func TestBook_Remove(t *testing.T) {
+	book := queryBook()
+	removed := book.Remove(Constant())
+	assert.Equal(t, []string{"dragon", "king"}, entryNames(removed))
+	assert.Equal(t, []string{"castle", "<nil>", "forest"}, entryNames(book.Entries))
+	assert.Equal(t, 3, *book.Entries[2].ID.IntValue)
+	assert.Equal(t, property.Integer(30), book.Entries[2].InsertionOrder)
+}
+
+func TestBook_Partition(t *testing.T) {
+	book := queryBook()
+	partition := book.Partition(Not(Enabled()))
+	assert.Equal(t, property.String("Lore"), partition.Name)
+	assert.Equal(t, []string{"forest"}, entryNames(partition.Entries))
+	assert.Equal(t, property.Integer(30), partition.Entries[0].InsertionOrder)
+	assert.Equal(t, []string{"castle", "dragon", "<nil>", "king"}, entryNames(book.Entries))
+	assert.Equal(t, 4, *book.Entries[3].ID.IntValue)
+}
+
+func TestBook_Split(t *testing.T) {
+	// sourceBook returns a book with an entry per content
+	sourceBook := func(name string, scanDepth int, contents ...string) *Book {
+		book := &Book{Name: property.String(name), ScanDepth: property.Integer(scanDepth), Extensions: map[string]any{name: true}}
+		for _, content := range contents {
+			book.Entries = append(book.Entries, FilledBookEntry(content, content))
+		}
+		return book
+	}
+	merge := func(books map[string]*Book, labels ...string) *Book {
+		merger := NewBookMerger()
+		for _, label := range labels {
+			merger.AppendBookWithSource(books[label], label)
+		}
+		return merger.Build()
+	}
+
+	t.Run("Merge, split and merge", func(t *testing.T) {
+		merged := merge(map[string]*Book{
+			"castle": sourceBook("castle", 4, "gate", "tower"),
+			"forest": sourceBook("forest", 8, "oak"),
+		}, "castle", "forest")
+		original := merged.Clone()
+
+		split := merged.Split(nil)
+		assert.True(t, original.DeepEquals(merged), "the merged book is not modified")
+		assert.Len(t, split, 2)
+		castle := split["castle"]
+		assert.Equal(t, []string{"gate", "tower"}, entryNames(castle.Entries))
+		assert.Equal(t, property.UnionFromInt(1), castle.Entries[1].ID)
+		assert.NotContains(t, castle.Entries[0].RawExtensions, MergeSourceKey)
+		assert.Equal(t, property.Integer(8), castle.ScanDepth)
+		assert.Equal(t, map[string]any{"castle": true, "forest": true}, castle.Extensions)
+		assert.Equal(t, property.UnionFromInt(0), split["forest"].Entries[0].ID)
+
+		remerged := merge(split, "castle", "forest")
+		assert.True(t, original.DeepEquals(remerged), original.Diff(remerged))
+	})
+
+	t.Run("Custom labels", func(t *testing.T) {
+		book := queryBook()
+		split := book.Split(func(entry *BookEntry) string {
+			if entry.Constant {
+				return "constant"
+			}
+			return ""
+		})
+		assert.Equal(t, []string{"dragon", "king"}, entryNames(split["constant"].Entries))
+		assert.Equal(t, []string{"castle", "forest"}, entryNames(split[""].Entries))
+		assert.Equal(t, property.UnionFromInt(1), split[""].Entries[1].ID)
+		assert.Equal(t, 3, *book.Entries[3].ID.IntValue)
+	})
+}
@@ -0,0 +1,96 @@
+package character
+
+import (
+	"cmp"
+	"slices"
+
+	"github.com/r3dpixel/card-parser/property"
+)
+
+// DefaultInsertionOrderStep is the default gap between the insertion orders rewritten by ReindexInsertionOrder and
+// BookMerger.BuildSorted (see BookMerger.WithInsertionOrderStep)
+const DefaultInsertionOrderStep = 10
+
+// EntrySortKey compares two lorebook entries (negative if a < b, zero if equal, positive if a > b)
+type EntrySortKey func(a, b *BookEntry) int
+
+// EntrySortKey values
+var (
+	// EntryByInsertionOrder sorts by insertion order (lowest first)
+	EntryByInsertionOrder EntrySortKey = func(a, b *BookEntry) int { return cmp.Compare(a.InsertionOrder, b.InsertionOrder) }
+	// EntryByID sorts by ID (integer IDs first, then string IDs, then missing IDs)
+	EntryByID EntrySortKey = func(a, b *BookEntry) int { return compareIDs(a.ID, b.ID) }
+	// EntryByName sorts by name, collated with CollateFold (see EntryByNameCollated)
+	EntryByName = EntryByNameCollated(CollateFold)
+)
+
+// EntryByNameCollated returns the sort key sorting entries by name, collated with the given collation
+func EntryByNameCollated(collation Collation) EntrySortKey {
+	return func(a, b *BookEntry) int { return collation(string(a.Name), string(b.Name)) }
+}
+
+// SortEntries sorts the entries by the given keys (in priority order, defaulting to the insertion order)
+// The sort is stable: entries with equal keys keep their relative order; nil entries are moved last
+func (b *Book) SortEntries(by ...EntrySortKey) {
+	// Default to the insertion order
+	if len(by) == 0 {
+		by = []EntrySortKey{EntryByInsertionOrder}
+	}
+
+	slices.SortStableFunc(b.Entries, func(x, y *BookEntry) int {
+		// Move nil entries last
+		if x == nil || y == nil {
+			return boolCompare(x == nil, y == nil)
+		}
+		// Compare the keys in priority order
+		for _, key := range by {
+			if result := key(x, y); result != 0 {
+				return result
+			}
+		}
+		return 0
+	})
+}
+
+// ReindexInsertionOrder rewrites the insertion orders to 0, step, 2*step... following the current order of the entries,
+// leaving gaps for manual inserts (the IDs are not modified); a non-positive step defaults to DefaultInsertionOrderStep
+func (b *Book) ReindexInsertionOrder(step int) {
+	if step <= 0 {
+		step = DefaultInsertionOrderStep
+	}
+
+	order := 0
+	for _, entry := range b.Entries {
+		if entry == nil {
+			continue
+		}
+		entry.InsertionOrder = property.Integer(order)
+		order += step
+	}
+}
+
+// compareIDs compares two entry IDs (integer IDs first, then string IDs, then missing IDs)
+func compareIDs(x, y property.Union) int {
+	switch {
+	case x.IntValue != nil && y.IntValue != nil:
+		return cmp.Compare(*x.IntValue, *y.IntValue)
+	case x.IntValue != nil || y.IntValue != nil:
+		return boolCompare(x.IntValue == nil, y.IntValue == nil)
+	case x.StringValue != nil && y.StringValue != nil:
+		return cmp.Compare(*x.StringValue, *y.StringValue)
+	default:
+		return boolCompare(x.StringValue == nil, y.StringValue == nil)
+	}
+}
+
+// boolCompare orders false before true
+func boolCompare(x, y bool) int {
+	switch {
+	case x == y:
+		return 0
+	case x:
+		return 1
+	default:
+		return -1
+	}
+}
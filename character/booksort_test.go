@@ -0,0 +1,107 @@
+package character
+
+import (
+	"testing"
+
+	"github.com/r3dpixel/card-parser/property"
+	"github.com/r3dpixel/toolkit/ptr"
+	"github.com/stretchr/testify/assert"
+)
+
+// sortEntry creates an entry with the given name, insertion order and ID (nil for no ID)
+func sortEntry(name string, order int, id any) *BookEntry {
+	entry := DefaultBookEntry()
+	entry.Name = property.String(name)
+	entry.InsertionOrder = property.Integer(order)
+	switch typedID := id.(type) {
+	case int:
+		entry.ID = property.Union{IntValue: ptr.Of(typedID)}
+	case string:
+		entry.ID = property.Union{StringValue: ptr.Of(typedID)}
+	}
+	return entry
+}
+
+// entryNames returns the names of the entries (nil entries are named <nil>)
+func entryNames(entries []*BookEntry) []string {
+	names := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		if entry == nil {
+			names = append(names, "<nil>")
+			continue
+		}
+		names = append(names, string(entry.Name))
+	}
+	return names
+}
+
+func TestBook_SortEntries(t *testing.T) {
+	tests := []struct {
+		name     string
+		by       []EntrySortKey
+		expected []string
+	}{
+		{"Default", nil, []string{"c", "a", "d", "b", "e", "<nil>"}},
+		{"Insertion order", []EntrySortKey{EntryByInsertionOrder}, []string{"c", "a", "d", "b", "e", "<nil>"}},
+		{"ID", []EntrySortKey{EntryByID}, []string{"e", "a", "d", "b", "c", "<nil>"}},
+		{"Name", []EntrySortKey{EntryByName}, []string{"a", "b", "c", "d", "e", "<nil>"}},
+		{"Insertion order then name", []EntrySortKey{EntryByInsertionOrder, EntryByName}, []string{"c", "a", "b", "d", "e", "<nil>"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			book := &Book{Entries: []*BookEntry{
+				sortEntry("a", 10, 1),
+				nil,
+				sortEntry("d", 10, 2),
+				sortEntry("b", 10, "x"),
+				sortEntry("c", 5, nil),
+				sortEntry("e", 20, 0),
+			}}
+
+			book.SortEntries(tt.by...)
+			assert.Equal(t, tt.expected, entryNames(book.Entries))
+		})
+	}
+
+	t.Run("Collated name", func(t *testing.T) {
+		book := &Book{Entries: []*BookEntry{sortEntry("b", 0, nil), sortEntry("B", 0, nil), sortEntry("a", 0, nil)}}
+		book.SortEntries(EntryByName)
+		assert.Equal(t, []string{"a", "B", "b"}, entryNames(book.Entries))
+		book.SortEntries(EntryByNameCollated(CollateBinary))
+		assert.Equal(t, []string{"B", "a", "b"}, entryNames(book.Entries))
+	})
+}
+
+func TestBook_ReindexInsertionOrder(t *testing.T) {
+	book := &Book{Entries: []*BookEntry{
+		sortEntry("a", 10, 7),
+		sortEntry("b", 10, 3),
+		nil,
+		sortEntry("c", 10, "x"),
+	}}
+
+	tests := []struct {
+		name     string
+		step     int
+		expected []property.Integer
+	}{
+		{"Step", 100, []property.Integer{0, 100, 200}},
+		{"Default step", 0, []property.Integer{0, 10, 20}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			book.ReindexInsertionOrder(tt.step)
+
+			// The relative order is kept, and the IDs are left untouched
+			assert.Equal(t, []string{"a", "b", "<nil>", "c"}, entryNames(book.Entries))
+			assert.Equal(t, tt.expected, []property.Integer{
+				book.Entries[0].InsertionOrder, book.Entries[1].InsertionOrder, book.Entries[3].InsertionOrder,
+			})
+			assert.Equal(t, 7, *book.Entries[0].ID.IntValue)
+			assert.Equal(t, 3, *book.Entries[1].ID.IntValue)
+			assert.Equal(t, "x", *book.Entries[3].ID.StringValue)
+		})
+	}
+}
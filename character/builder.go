@@ -0,0 +1,162 @@
+package character
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/r3dpixel/card-parser/property"
+	"github.com/r3dpixel/toolkit/stringsx"
+	"github.com/r3dpixel/toolkit/timestamp"
+)
+
+// ErrIntegrity is returned by SheetBuilder.Build in strict mode when the sheet does not pass the integrity check
+var ErrIntegrity = errors.New("sheet fails the integrity check")
+
+// SheetBuilder builds chara sheets from scratch (see NewSheetBuilder)
+type SheetBuilder struct {
+	sheet            *Sheet
+	fixTemplates     bool
+	normalizeSymbols bool
+	strict           bool
+}
+
+// NewSheetBuilder returns a sheet builder for the given revision
+func NewSheetBuilder(revision Revision) *SheetBuilder {
+	return &SheetBuilder{sheet: DefaultSheet(revision)}
+}
+
+// Title sets the title (defaults to the name)
+func (sb *SheetBuilder) Title(title string) *SheetBuilder {
+	sb.sheet.Title = property.String(title)
+	return sb
+}
+
+// Name sets the name
+func (sb *SheetBuilder) Name(name string) *SheetBuilder {
+	sb.sheet.Name = property.String(name)
+	return sb
+}
+
+// Nickname sets the nickname (defaults to the name)
+func (sb *SheetBuilder) Nickname(nickname string) *SheetBuilder {
+	sb.sheet.Nickname = property.String(nickname)
+	return sb
+}
+
+// Description sets the description
+func (sb *SheetBuilder) Description(description string) *SheetBuilder {
+	sb.sheet.Description = property.String(description)
+	return sb
+}
+
+// FirstMessage sets the first message
+func (sb *SheetBuilder) FirstMessage(message string) *SheetBuilder {
+	sb.sheet.FirstMessage = property.String(message)
+	return sb
+}
+
+// Creator sets the creator (defaults to AnonymousCreator)
+func (sb *SheetBuilder) Creator(creator string) *SheetBuilder {
+	sb.sheet.Creator = property.String(creator)
+	return sb
+}
+
+// SourceID sets the source ID (required by the integrity check)
+func (sb *SheetBuilder) SourceID(sourceID string) *SheetBuilder {
+	sb.sheet.SourceID = property.String(sourceID)
+	return sb
+}
+
+// Tag appends the tags
+func (sb *SheetBuilder) Tag(tags ...string) *SheetBuilder {
+	sb.sheet.Tags = append(sb.sheet.Tags, tags...)
+	return sb
+}
+
+// Greeting appends the alternate greetings
+func (sb *SheetBuilder) Greeting(greetings ...string) *SheetBuilder {
+	sb.sheet.AlternateGreetings = append(sb.sheet.AlternateGreetings, greetings...)
+	return sb
+}
+
+// BookEntry appends a lorebook entry with the given name (also its key) and content, and the default values for the
+// other fields (see FilledBookEntry); the lorebook is created on the first entry
+func (sb *SheetBuilder) BookEntry(name string, content string) *SheetBuilder {
+	if sb.sheet.CharacterBook == nil {
+		sb.sheet.CharacterBook = DefaultBook()
+	}
+	sb.sheet.CharacterBook.Entries = append(sb.sheet.CharacterBook.Entries, FilledBookEntry(name, content))
+	return sb
+}
+
+// DepthPrompt sets the depth prompt and its depth
+func (sb *SheetBuilder) DepthPrompt(prompt string, depth int) *SheetBuilder {
+	sb.sheet.DepthPrompt = DepthPrompt{Prompt: prompt, Depth: depth}
+	return sb
+}
+
+// FixTemplates runs Content.FixUserCharTemplates on the built sheet
+func (sb *SheetBuilder) FixTemplates() *SheetBuilder {
+	sb.fixTemplates = true
+	return sb
+}
+
+// NormalizeSymbols runs Content.NormalizeSymbols on the built sheet
+func (sb *SheetBuilder) NormalizeSymbols() *SheetBuilder {
+	sb.normalizeSymbols = true
+	return sb
+}
+
+// Strict fails the build with ErrIntegrity if the sheet does not pass the integrity check (see Content.Validate)
+func (sb *SheetBuilder) Strict() *SheetBuilder {
+	sb.strict = true
+	return sb
+}
+
+// Build returns a new sheet with the defaults filled in: the creation and modification dates (now, when unset), the
+// creator (AnonymousCreator, when blank), and the nickname and title (the name, when blank)
+// The builder can be reused, the built sheets share nothing with it; in strict mode, ErrIntegrity is returned
+// listing the errors reported by the validation
+func (sb *SheetBuilder) Build() (*Sheet, error) {
+	// Copy the sheet
+	sheet := &Sheet{Spec: sb.sheet.Spec, Version: sb.sheet.Version, Revision: sb.sheet.Revision, Content: *sb.sheet.Content.Clone()}
+
+	// Fill the defaults
+	now := timestamp.Seconds(time.Now().Unix())
+	if sheet.CreationDate == 0 {
+		sheet.CreationDate = now
+	}
+	if sheet.ModificationDate == 0 {
+		sheet.ModificationDate = now
+	}
+	if stringsx.IsBlank(string(sheet.Creator)) {
+		sheet.Creator = AnonymousCreator
+	}
+	if stringsx.IsBlank(string(sheet.Nickname)) {
+		sheet.Nickname = sheet.Name
+	}
+	if stringsx.IsBlank(string(sheet.Title)) {
+		sheet.Title = sheet.Name
+	}
+
+	// Clean up the text fields
+	if sb.fixTemplates {
+		sheet.FixUserCharTemplates()
+	}
+	if sb.normalizeSymbols {
+		sheet.NormalizeSymbols()
+	}
+
+	// Check the integrity
+	if sb.strict && !sheet.Integrity() {
+		var messages []string
+		for _, issue := range slices.DeleteFunc(sheet.Validate(), func(issue ValidationIssue) bool { return !issue.IsError() }) {
+			messages = append(messages, issue.String())
+		}
+		return nil, fmt.Errorf("%w: %s", ErrIntegrity, strings.Join(messages, "; "))
+	}
+	return sheet, nil
+}
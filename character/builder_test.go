@@ -0,0 +1,73 @@
+package character
+
+import (
+	"testing"
+	"time"
+
+	"github.com/r3dpixel/card-parser/property"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSheetBuilder_Build(t *testing.T) {
+	t.Run("Minimal sheet passes the integrity check", func(t *testing.T) {
+		before := time.Now().Unix()
+		sheet, err := NewSheetBuilder(RevisionV3).Name("Alice").Description("A curious girl").SourceID("alice").Strict().Build()
+		require.NoError(t, err)
+		assert.True(t, sheet.Integrity())
+		assert.Empty(t, sheet.Validate())
+
+		// The defaults are filled in
+		assert.Equal(t, RevisionV3, sheet.Revision)
+		assert.Equal(t, SpecV3, sheet.Spec)
+		assert.Equal(t, property.String("Alice"), sheet.Title)
+		assert.Equal(t, property.String("Alice"), sheet.Nickname)
+		assert.Equal(t, property.String(AnonymousCreator), sheet.Creator)
+		assert.GreaterOrEqual(t, int64(sheet.CreationDate), before)
+		assert.Equal(t, sheet.CreationDate, sheet.ModificationDate)
+	})
+
+	t.Run("Every field", func(t *testing.T) {
+		builder := NewSheetBuilder(RevisionV2).
+			Title("Wonderland").Name("Alice").Nickname("Ally").Description("Hi {char}}").FirstMessage("“Hello” {{{user}").
+			Creator("r3dpixel").SourceID("alice").Tag("fantasy", "adventure").Tag("classic").Greeting("Welcome", "Again").
+			BookEntry("rabbit", "A white rabbit").BookEntry("queen", "The queen of hearts").DepthPrompt("Stay in character", 4).
+			FixTemplates().NormalizeSymbols()
+		sheet, err := builder.Build()
+		require.NoError(t, err)
+
+		assert.Equal(t, RevisionV2, sheet.Revision)
+		assert.Equal(t, property.String("Wonderland"), sheet.Title)
+		assert.Equal(t, property.String("Ally"), sheet.Nickname)
+		assert.Equal(t, property.String("r3dpixel"), sheet.Creator)
+		assert.Equal(t, property.String("Hi {{char}}"), sheet.Description)
+		assert.Equal(t, property.String(`"Hello" {{user}}`), sheet.FirstMessage)
+		assert.Equal(t, property.StringArray{"fantasy", "adventure", "classic"}, sheet.Tags)
+		assert.Equal(t, property.StringArray{"Welcome", "Again"}, sheet.AlternateGreetings)
+		assert.Equal(t, DepthPrompt{Prompt: "Stay in character", Depth: 4}, sheet.DepthPrompt)
+		require.NotNil(t, sheet.CharacterBook)
+		require.Len(t, sheet.CharacterBook.Entries, 2)
+		assert.Equal(t, FilledBookEntry("rabbit", "A white rabbit"), sheet.CharacterBook.Entries[0])
+		assert.True(t, sheet.Integrity())
+
+		// The built sheets share nothing with the builder
+		sheet.Tags[0] = "changed"
+		sheet.CharacterBook.Entries[0].Name = "changed"
+		rebuilt, err := builder.Build()
+		require.NoError(t, err)
+		assert.Equal(t, "fantasy", rebuilt.Tags[0])
+		assert.Equal(t, property.String("rabbit"), rebuilt.CharacterBook.Entries[0].Name)
+	})
+
+	t.Run("Strict build of an invalid sheet", func(t *testing.T) {
+		_, err := NewSheetBuilder(RevisionV3).Name("Alice").Strict().Build()
+		require.ErrorIs(t, err, ErrIntegrity)
+		assert.Contains(t, err.Error(), DescriptionField)
+		assert.Contains(t, err.Error(), SourceIDField)
+
+		// The same sheet is built without the strict mode
+		sheet, err := NewSheetBuilder(RevisionV3).Name("Alice").Build()
+		require.NoError(t, err)
+		assert.False(t, sheet.Integrity())
+	})
+}
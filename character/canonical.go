@@ -0,0 +1,104 @@
+package character
+
+import (
+	"bytes"
+
+	"github.com/r3dpixel/card-parser/internal/codec"
+	"github.com/r3dpixel/card-parser/property"
+)
+
+// CanonicalIndent is the indentation of the indented canonical output
+const CanonicalIndent = "  "
+
+// CanonicalOptions options of Sheet.ToCanonicalBytes
+type CanonicalOptions struct {
+	// Indent indents the output with CanonicalIndent
+	Indent bool
+}
+
+// ToCanonicalBytes returns the deterministic JSON of the sheet, meant for diff-friendly exports (e.g. cards kept in git)
+// Every object key is sorted recursively (extensions, lorebook and entry extensions, multilingual creator notes, and
+// the struct fields), numbers keep their encoded form, empty optional collections are omitted, and integer-like
+// string IDs are written as integers; sheets that DeepEquals produce identical bytes as long as their string and
+// number arrays share the same order (arrays are never reordered, so greetings keep their order)
+// Unlike CanonicalBytes, the sheet is not normalized, and the unknown top-level members are kept
+func (s *Sheet) ToCanonicalBytes(opts CanonicalOptions) ([]byte, error) {
+	// Encode the canonical copy of the sheet
+	data, err := s.canonicalCopy().ToBytes()
+	if err != nil {
+		return nil, err
+	}
+	// Sort the keys
+	return canonicalJSON(data, opts.Indent)
+}
+
+// canonicalCopy returns a copy of the sheet where the values treated as equal by DeepEquals are encoded the same way
+func (s *Sheet) canonicalCopy() *Sheet {
+	// Copy the sheet
+	sheet := *s
+	sheet.Content = *s.Content.Clone()
+	content := &sheet.Content
+
+	// Required arrays are empty instead of missing
+	content.AlternateGreetings = nonNilStrings(content.AlternateGreetings)
+	content.Tags = nonNilStrings(content.Tags)
+
+	// Empty optional collections are omitted
+	if len(content.Extensions) == 0 {
+		content.Extensions = nil
+	}
+	if len(content.Assets) == 0 {
+		content.Assets = nil
+	}
+	if len(content.CreatorNotesMultilingual) == 0 {
+		content.CreatorNotesMultilingual = nil
+	}
+	if len(content.Source) == 0 {
+		content.Source = nil
+	}
+	if len(content.GroupGreetings) == 0 {
+		content.GroupGreetings = nil
+	}
+
+	// Entries get empty key arrays and integer IDs
+	if content.CharacterBook != nil {
+		for _, entry := range content.CharacterBook.Entries {
+			if entry == nil {
+				continue
+			}
+			entry.Keys = nonNilStrings(entry.Keys)
+			entry.SecondaryKeys = nonNilStrings(entry.SecondaryKeys)
+			if id, ok := entry.ID.Int(); ok && property.UnionFromInt(id).Equals(entry.ID) {
+				entry.ID = property.UnionFromInt(id)
+			}
+		}
+	}
+
+	// Return the copy
+	return &sheet
+}
+
+// nonNilStrings returns an empty string array instead of a nil one
+func nonNilStrings(values property.StringArray) property.StringArray {
+	if values == nil {
+		return property.StringArray{}
+	}
+	return values
+}
+
+// canonicalJSON re-encodes the JSON with recursively sorted keys (numbers are kept as encoded)
+func canonicalJSON(data []byte, indent bool) ([]byte, error) {
+	// Decode the JSON as a generic value (numbers are kept as text)
+	decoder := codec.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var generic any
+	if err := decoder.Decode(&generic); err != nil {
+		return nil, err
+	}
+
+	// Encode the value with sorted keys
+	if indent {
+		return codec.MarshalSorted(generic, CanonicalIndent)
+	}
+	return codec.MarshalSorted(generic, "")
+}
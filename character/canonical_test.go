@@ -0,0 +1,100 @@
+package character
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/r3dpixel/card-parser/property"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSheet_ToCanonicalBytes(t *testing.T) {
+	t.Run("Repeated marshaling is identical", func(t *testing.T) {
+		sheet, err := FromBytes([]byte(comprehensiveSheetJSON))
+		require.NoError(t, err)
+		sheet.Extensions["nested"] = map[string]any{"z": 1, "a": map[string]any{"y": true, "b": []any{map[string]any{"d": 1, "c": 2}}}}
+		sheet.CreatorNotesMultilingual = map[string]property.String{"fr": "Bonjour", "de": "Hallo", "en": "Hello"}
+		sheet.CharacterBook.Entries[0].RawExtensions["z_nested"] = map[string]any{"k": "v", "a": []any{3, 1, 2}}
+
+		expected, err := sheet.ToCanonicalBytes(CanonicalOptions{})
+		require.NoError(t, err)
+		for range 50 {
+			actual, err := sheet.ToCanonicalBytes(CanonicalOptions{})
+			require.NoError(t, err)
+			require.Equal(t, expected, actual)
+		}
+	})
+
+	t.Run("Keys are sorted recursively", func(t *testing.T) {
+		sheet := DefaultSheet(RevisionV3)
+		sheet.Extensions = map[string]any{"zeta": map[string]any{"b": 1, "a": map[string]any{"d": 2, "c": 1}}, "alpha": 1.5}
+		data, err := sheet.ToCanonicalBytes(CanonicalOptions{})
+		require.NoError(t, err)
+		assert.Contains(t, string(data), `"extensions":{"alpha":1.5,"zeta":{"a":{"c":1,"d":2},"b":1}}`)
+		assert.True(t, strings.HasPrefix(string(data), `{"data":{`))
+		assert.True(t, strings.HasSuffix(string(data), `"spec":"chara_card_v3","spec_version":"3.0"}`))
+	})
+
+	t.Run("Arrays keep their order", func(t *testing.T) {
+		sheet := DefaultSheet(RevisionV3)
+		sheet.AlternateGreetings = property.StringArray{"second", "first"}
+		data, err := sheet.ToCanonicalBytes(CanonicalOptions{})
+		require.NoError(t, err)
+		assert.Contains(t, string(data), `"alternate_greetings":["second","first"]`)
+	})
+
+	t.Run("Large numbers keep their precision", func(t *testing.T) {
+		sheet := DefaultSheet(RevisionV3)
+		sheet.Extensions = map[string]any{"big": json.Number("9007199254740993")}
+		data, err := sheet.ToCanonicalBytes(CanonicalOptions{})
+		require.NoError(t, err)
+		assert.Contains(t, string(data), `"big":9007199254740993`)
+	})
+
+	t.Run("Indented", func(t *testing.T) {
+		sheet := DefaultSheet(RevisionV3)
+		data, err := sheet.ToCanonicalBytes(CanonicalOptions{Indent: true})
+		require.NoError(t, err)
+		assert.True(t, strings.HasPrefix(string(data), "{\n  \"data\": {\n    \""))
+		compact, err := sheet.ToCanonicalBytes(CanonicalOptions{})
+		require.NoError(t, err)
+		assert.JSONEq(t, string(compact), string(data))
+	})
+
+	t.Run("DeepEquals sheets produce identical bytes", func(t *testing.T) {
+		tests := []struct {
+			name   string
+			modify func(s *Sheet)
+		}{
+			{"Empty extensions", func(s *Sheet) { s.Extensions = map[string]any{} }},
+			{"Empty multilingual notes", func(s *Sheet) { s.CreatorNotesMultilingual = map[string]property.String{} }},
+			{"Empty sources", func(s *Sheet) { s.Source = property.StringArray{} }},
+			{"Nil tags", func(s *Sheet) { s.Tags = nil }},
+			{"Nil entry keys", func(s *Sheet) { s.CharacterBook.Entries[0].SecondaryKeys = nil }},
+			{"Stringified entry ID", func(s *Sheet) { s.CharacterBook.Entries[0].ID = property.UnionFromString("1") }},
+			{"Raw spec values", func(s *Sheet) { s.RawSpec, s.RawVersion = "CHARA_CARD_V3", "3" }},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				original := DefaultSheet(RevisionV3)
+				original.Name = "Alice"
+				original.CharacterBook = &Book{Entries: []*BookEntry{FilledBookEntry("Entry", "Content")}}
+				original.CharacterBook.Entries[0].ID = property.UnionFromInt(1)
+				original.Tags = property.StringArray{}
+				original.CharacterBook.Entries[0].SecondaryKeys = property.StringArray{}
+				modified := original.Freeze().Thaw()
+				tt.modify(modified)
+				require.True(t, original.DeepEquals(modified))
+
+				expected, err := original.ToCanonicalBytes(CanonicalOptions{})
+				require.NoError(t, err)
+				actual, err := modified.ToCanonicalBytes(CanonicalOptions{})
+				require.NoError(t, err)
+				assert.Equal(t, string(expected), string(actual))
+			})
+		}
+	})
+}
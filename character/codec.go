@@ -0,0 +1,20 @@
+package character
+
+import (
+	"github.com/r3dpixel/card-parser/internal/codec"
+)
+
+// Codec JSON codec used by the custom (un)marshalers of the sheets and properties (see SetCodec)
+type Codec = codec.Codec
+
+// StdCodec encoding/json codec, for the platforms where Sonic is unsupported (default with the stdjson build tag)
+var StdCodec = codec.Std
+
+// SetCodec sets the JSON codec of the sheets and properties (Sheet, Content, Book, BookEntry and every property type),
+// used by Sheet.ToJSON, ToFile and ToBytes too, and returns the previous one; it must not be called while JSON is
+// encoded or decoded
+func SetCodec(c Codec) Codec {
+	previous := codec.Get()
+	codec.Set(c)
+	return previous
+}
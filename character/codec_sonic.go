@@ -0,0 +1,10 @@
+//go:build !stdjson
+
+package character
+
+import (
+	"github.com/r3dpixel/card-parser/internal/codec"
+)
+
+// SonicCodec Sonic codec (default, not compiled with the stdjson build tag)
+var SonicCodec = codec.Sonic
@@ -0,0 +1,73 @@
+package character
+
+import (
+	"io"
+	"os"
+	"testing"
+
+	"github.com/r3dpixel/card-parser/internal/codec"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestMain runs every test of the package with each available codec (Sonic first, then encoding/json)
+func TestMain(m *testing.M) {
+	for _, c := range codec.Available {
+		SetCodec(c)
+		if code := m.Run(); code != 0 {
+			os.Exit(code)
+		}
+	}
+}
+
+func TestSetCodec(t *testing.T) {
+	active := SetCodec(StdCodec)
+	defer SetCodec(active)
+
+	custom := countingCodec{Codec: StdCodec, marshaled: new(int)}
+	assert.Equal(t, StdCodec, SetCodec(custom))
+	assert.Equal(t, custom, SetCodec(active))
+}
+
+// countingCodec codec counting the sheets it marshals or streams (top-level encodings)
+type countingCodec struct {
+	Codec
+	marshaled *int
+}
+
+// Marshal counts the sheets, and encodes the value with the wrapped codec
+func (c countingCodec) Marshal(v any) ([]byte, error) {
+	if _, ok := v.(*Sheet); ok {
+		*c.marshaled++
+	}
+	return c.Codec.Marshal(v)
+}
+
+// Encode counts the sheets, and streams the value with the wrapped codec
+func (c countingCodec) Encode(w io.Writer, v any, opts codec.EncodeOptions) error {
+	if _, ok := v.(*Sheet); ok {
+		*c.marshaled++
+	}
+	return c.Codec.Encode(w, v, opts)
+}
+
+func TestSetCodec_SheetEncoding(t *testing.T) {
+	var marshaled int
+	active := SetCodec(countingCodec{Codec: StdCodec, marshaled: &marshaled})
+	defer SetCodec(active)
+
+	// The top-level encoding goes through the codec
+	sheet := DefaultSheet(RevisionV2)
+	sheet.Name = "Codec"
+	data, err := sheet.ToBytes()
+	assert.NoError(t, err)
+	assert.Contains(t, string(data), `"name":"Codec"`)
+	assert.Equal(t, 1, marshaled)
+
+	marshaled = 0
+	path := t.TempDir() + "/sheet.json"
+	assert.NoError(t, sheet.ToFile(path, Pretty("  ")))
+	assert.Equal(t, 1, marshaled)
+	written, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	assert.Contains(t, string(written), "\n  \"data\": {")
+}
@@ -0,0 +1,256 @@
+package character
+
+import (
+	"fmt"
+	"maps"
+	"math"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/r3dpixel/card-parser/internal/codec"
+	"github.com/r3dpixel/card-parser/property"
+	"github.com/r3dpixel/toolkit/jsonx"
+	"github.com/spf13/cast"
+)
+
+// CoercionEvent a value the permissive decoding converted from another JSON type, or replaced by a default
+// (e.g. an unknown position name, an out-of-range role, an object where a string is expected, or an invalid depth)
+type CoercionEvent struct {
+	Path     string // JSON path of the value (e.g. data.character_book.entries[0].extensions.position)
+	Raw      any    // Value found in the JSON (as decoded into an any: float64, string, bool, []any or map[string]any)
+	Resolved any    // Value of the decoded field (e.g. property.BeforeCharPosition)
+}
+
+// String returns the path with the raw and resolved values (e.g. data.tags: "fantasy" -> [fantasy])
+func (e CoercionEvent) String() string {
+	return fmt.Sprintf("%s: %s -> %v", e.Path, codec.String(e.Raw), e.Resolved)
+}
+
+// enumParser parser of an enum property (e.g. property.LorePositionProp())
+type enumParser[T ~int] interface {
+	Parse(value string) (T, bool)
+	FromInt(value int) T
+}
+
+// coercionRecorder collects the coercion events of a decoded sheet
+type coercionRecorder struct {
+	events []CoercionEvent
+}
+
+// FromBytesStrict decodes the JSON like FromBytesWithOptions (the decoded sheet is the same), and reports the values
+// the permissive decoding converted or replaced by a default, in field order (null values are never reported)
+// The content, lorebook and entry fields (straggler entry extensions included) and the depth prompt are checked
+func FromBytesStrict(b []byte, opts ...DecodeOptions) (*Sheet, []CoercionEvent, error) {
+	// Decode the sheet
+	options := decodeOptions(opts)
+	sheet, b, err := decodeSheet(b, options)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Decode the raw values (of the JSON truncated by the sheet decoding)
+	var root map[string]any
+	if err := codec.Unmarshal(b, &root); err != nil {
+		return nil, nil, err
+	}
+
+	// Walk the content
+	recorder := &coercionRecorder{}
+	if content, ok := root["data"].(map[string]any); ok {
+		recorder.object("data", content, reflect.ValueOf(Content{}))
+		recorder.depthPrompt("data."+ExtensionsField+"."+DepthPromptKey, content, sheet.DepthPrompt)
+	}
+	return sheet, recorder.events, nil
+}
+
+// object records the coercions of the fields of a raw object decoded into a struct (with the default field values)
+func (r *coercionRecorder) object(path string, raw map[string]any, defaults reflect.Value) {
+	structType := defaults.Type()
+	for index := range structType.NumField() {
+		field := structType.Field(index)
+		if !field.IsExported() {
+			continue
+		}
+
+		// Flatten the embedded structs
+		fieldDefaults := defaults.Field(index)
+		if field.Anonymous && field.Type.Kind() == reflect.Struct {
+			r.object(path, raw, fieldDefaults)
+			continue
+		}
+
+		// Check the fields found in the object
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if value, ok := raw[name]; ok && name != "" && name != "-" {
+			r.value(path+"."+name, value, fieldDefaults)
+		}
+	}
+
+	// Check the straggler entry extensions
+	if structType == reflect.TypeFor[BookEntry]() {
+		extensions := defaults.FieldByName("Extensions")
+		for _, key := range bookEntryStragglers {
+			if value, ok := raw[key]; ok {
+				if fieldIndex := jsonFieldIndex(extensions.Type(), key); fieldIndex >= 0 {
+					r.value(path+"."+key, value, extensions.Field(fieldIndex))
+				}
+			}
+		}
+	}
+}
+
+// value records the coercion of a raw value decoded into a field (with the default field value)
+func (r *coercionRecorder) value(path string, raw any, defaults reflect.Value) {
+	// Null values are never reported
+	if raw == nil {
+		return
+	}
+
+	// Decode the properties with their handlers, starting from the default value
+	resolved := reflect.New(defaults.Type())
+	resolved.Elem().Set(defaults)
+	switch handler := resolved.Interface().(type) {
+	case jsonx.EntityHandler:
+		jsonx.HandleEntityValue(raw, handler)
+	case jsonx.PrimitiveHandler:
+		jsonx.HandlePrimitiveValue(raw, handler)
+	default:
+		r.structure(path, raw, defaults)
+		return
+	}
+
+	// Record the values not accepted as is
+	if !acceptedRaw(resolved.Interface(), raw) {
+		r.events = append(r.events, CoercionEvent{Path: path, Raw: raw, Resolved: resolved.Elem().Interface()})
+	}
+}
+
+// structure records the coercions below a raw value decoded into a structure (struct, pointer, slice or map)
+func (r *coercionRecorder) structure(path string, raw any, defaults reflect.Value) {
+	switch defaults.Kind() {
+	case reflect.Pointer:
+		r.structure(path, raw, elementDefaults(defaults.Type().Elem()))
+	case reflect.Struct:
+		if object, ok := raw.(map[string]any); ok {
+			r.object(path, object, defaults)
+		}
+	case reflect.Slice:
+		// Arrays, and keyed objects of entries
+		elemType := defaults.Type().Elem()
+		switch typed := raw.(type) {
+		case []any:
+			for index, item := range typed {
+				r.structure(fmt.Sprintf("%s[%d]", path, index), item, elementDefaults(elemType))
+			}
+		case map[string]any:
+			for _, key := range sortedEntryKeys(typed) {
+				r.structure(fmt.Sprintf("%s[%q]", path, key), typed[key], elementDefaults(elemType))
+			}
+		}
+	case reflect.Map:
+		// Objects of properties (e.g. creator_notes_multilingual)
+		if object, ok := raw.(map[string]any); ok && defaults.Type().Key().Kind() == reflect.String {
+			for _, key := range slices.Sorted(maps.Keys(object)) {
+				r.value(path+"."+key, object[key], elementDefaults(defaults.Type().Elem()))
+			}
+		}
+	}
+}
+
+// depthPrompt records the coercions of the depth prompt extension of the raw content
+func (r *coercionRecorder) depthPrompt(path string, content map[string]any, resolved DepthPrompt) {
+	extensions, _ := content[ExtensionsField].(map[string]any)
+	switch raw := extensions[DepthPromptKey].(type) {
+	case nil:
+	case map[string]any:
+		// The prompt must be a string, and the depth an integer
+		if prompt, ok := raw[DepthPromptPromptKey]; ok && prompt != nil {
+			if _, isString := prompt.(string); !isString {
+				r.events = append(r.events, CoercionEvent{Path: path + "." + DepthPromptPromptKey, Raw: prompt, Resolved: resolved.Prompt})
+			}
+		}
+		if depth, ok := raw[DepthPromptDepthKey]; ok && depth != nil {
+			if number, isNumber := depth.(float64); !isNumber || number != math.Trunc(number) {
+				converted, err := cast.ToIntE(depth)
+				if err != nil {
+					converted = DefaultDepth
+				}
+				r.events = append(r.events, CoercionEvent{Path: path + "." + DepthPromptDepthKey, Raw: depth, Resolved: converted})
+			}
+		}
+	default:
+		// Other values are converted to the prompt
+		r.events = append(r.events, CoercionEvent{Path: path, Raw: raw, Resolved: resolved})
+	}
+}
+
+// elementDefaults returns the default value of the elements of a structure (lorebook entries start from their
+// defaults, other elements from the zero value)
+func elementDefaults(elemType reflect.Type) reflect.Value {
+	switch elemType {
+	case reflect.TypeFor[*BookEntry]():
+		return reflect.ValueOf(DefaultBookEntry())
+	case reflect.TypeFor[BookEntry]():
+		return reflect.ValueOf(*DefaultBookEntry())
+	default:
+		return reflect.Zero(elemType)
+	}
+}
+
+// jsonFieldIndex returns the index of the field of the struct with the given JSON name (-1 if none)
+func jsonFieldIndex(structType reflect.Type, name string) int {
+	for index := range structType.NumField() {
+		if fieldName, _, _ := strings.Cut(structType.Field(index).Tag.Get("json"), ","); fieldName == name {
+			return index
+		}
+	}
+	return -1
+}
+
+// acceptedRaw checks if the raw value is accepted as is by the property (the JSON type of the property, and known
+// enum names and numbers in range)
+func acceptedRaw(target any, raw any) bool {
+	switch target.(type) {
+	case *property.String, *property.AssetType:
+		_, ok := raw.(string)
+		return ok
+	case *property.Bool:
+		_, ok := raw.(bool)
+		return ok
+	case *property.Integer:
+		_, ok := integerValue(raw)
+		return ok
+	case *property.Float:
+		_, ok := raw.(float64)
+		return ok
+	case *property.StringArray:
+		items, ok := raw.([]any)
+		return ok && !slices.ContainsFunc(items, func(item any) bool {
+			_, isString := item.(string)
+			return !isString
+		})
+	case *property.Union:
+		_, isInt := integerValue(raw)
+		_, isString := raw.(string)
+		return isInt || isString
+	case *property.LorePosition:
+		return knownEnum[property.LorePosition](raw, property.LorePositionProp())
+	case *property.Role:
+		return knownEnum[property.Role](raw, property.RoleProp())
+	case *property.SelectiveLogic:
+		return knownEnum[property.SelectiveLogic](raw, property.SelectiveLogicProp())
+	default:
+		return true
+	}
+}
+
+// knownEnum checks if the raw value is a name known to the parser, or a number in range
+func knownEnum[T ~int](raw any, parser enumParser[T]) bool {
+	if name, ok := raw.(string); ok {
+		_, known := parser.Parse(name)
+		return known
+	}
+	number, ok := integerValue(raw)
+	return ok && int(parser.FromInt(number)) == number
+}
@@ -0,0 +1,97 @@
+package character
+
+import (
+	"testing"
+
+	"github.com/r3dpixel/card-parser/property"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// messyCard sheet JSON with values the permissive decoding converts
+const messyCard = `{
+	"spec": "chara_card_v3",
+	"spec_version": "3.0",
+	"data": {
+		"name": "Messy",
+		"description": 42,
+		"tags": "fantasy",
+		"alternate_greetings": ["Hi", 5],
+		"creator_notes_multilingual": {"en": "Notes", "fr": {"text": "Notes"}},
+		"extensions": {"depth_prompt": {"prompt": "Stay", "depth": "deep"}},
+		"character_book": {
+			"name": "Lore",
+			"scan_depth": "4",
+			"recursive_scanning": true,
+			"entries": [
+				{
+					"id": 1,
+					"keys": ["castle"],
+					"content": "A castle",
+					"enabled": "yes",
+					"insertion_order": 2.5,
+					"extensions": {"position": "bfore_char", "role": 7, "selectiveLogic": ["and_any"], "depth": 4}
+				},
+				{
+					"id": "two",
+					"keys": ["forest"],
+					"constant": null,
+					"extensions": {"position": "after_char", "role": "user"},
+					"selectiveLogic": "not_all",
+					"probability": "50"
+				}
+			]
+		}
+	}
+}`
+
+func TestFromBytesStrict(t *testing.T) {
+	sheet, events, err := FromBytesStrict([]byte(messyCard))
+	require.NoError(t, err)
+
+	expected := []CoercionEvent{
+		{Path: "data.description", Raw: 42.0, Resolved: property.String("42")},
+		{Path: "data.alternate_greetings", Raw: []any{"Hi", 5.0}, Resolved: property.StringArray{"Hi", "5"}},
+		{Path: "data.character_book.scan_depth", Raw: "4", Resolved: property.Integer(4)},
+		{Path: "data.character_book.entries[0].insertion_order", Raw: 2.5, Resolved: property.Integer(2)},
+		{Path: "data.character_book.entries[0].enabled", Raw: "yes", Resolved: property.Bool(true)},
+		{Path: "data.character_book.entries[0].extensions.position", Raw: "bfore_char", Resolved: property.BeforeCharPosition},
+		{Path: "data.character_book.entries[0].extensions.selectiveLogic", Raw: []any{"and_any"}, Resolved: property.DefaultSelectiveLogic},
+		{Path: "data.character_book.entries[0].extensions.role", Raw: 7.0, Resolved: property.DefaultRole},
+		{Path: "data.character_book.entries[1].probability", Raw: "50", Resolved: property.Float(50)},
+		{Path: "data.tags", Raw: "fantasy", Resolved: property.StringArray{"fantasy"}},
+		{Path: "data.creator_notes_multilingual.fr", Raw: map[string]any{"text": "Notes"}, Resolved: property.String(`{"text":"Notes"}`)},
+		{Path: "data.extensions.depth_prompt.depth", Raw: "deep", Resolved: DefaultDepth},
+	}
+	assert.Equal(t, expected, events)
+
+	// The decoded sheet is the same as with the permissive decoding
+	permissive, err := FromBytes([]byte(messyCard))
+	require.NoError(t, err)
+	assert.True(t, permissive.DeepEquals(sheet))
+	assert.Equal(t, property.BeforeCharPosition, sheet.CharacterBook.Entries[0].Extensions.LorePosition)
+}
+
+func TestFromBytesStrict_CleanCard(t *testing.T) {
+	sheet := DefaultSheet(RevisionV3)
+	sheet.Name = "Clean"
+	sheet.Tags = property.StringArray{"fantasy"}
+	sheet.DepthPrompt = DepthPrompt{Prompt: "Stay", Depth: 2}
+	sheet.CharacterBook = &Book{Name: "Lore", Entries: []*BookEntry{FilledBookEntry("castle", "A castle")}}
+	sheet.CharacterBook.Entries[0].Extensions.LorePosition = property.AtDepth
+	data, err := sheet.ToBytes()
+	require.NoError(t, err)
+
+	for _, enumsAsStrings := range []bool{false, true} {
+		property.MarshalEnumsAsStrings(enumsAsStrings)
+		data, err = sheet.ToBytes()
+		require.NoError(t, err)
+		_, events, err := FromBytesStrict(data)
+		require.NoError(t, err)
+		assert.Empty(t, events)
+	}
+	property.MarshalEnumsAsStrings(false)
+
+	_, _, err = FromBytesStrict([]byte(`{"data": `))
+	assert.Error(t, err)
+}
@@ -0,0 +1,147 @@
+package character
+
+import (
+	"cmp"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/r3dpixel/toolkit/stringsx"
+	"golang.org/x/text/collate"
+	"golang.org/x/text/language"
+)
+
+// Collection a set of chara sheets (e.g. a gallery)
+type Collection []*Sheet
+
+// GroupKey computes the group of a sheet
+type GroupKey func(sheet *Sheet) string
+
+// GroupKey values
+var (
+	// GroupByCreator groups by the normalized creator handle
+	GroupByCreator GroupKey = func(sheet *Sheet) string { return NormalizeCreator(string(sheet.Creator)) }
+	// GroupByLanguage groups by the detected language
+	GroupByLanguage GroupKey = func(sheet *Sheet) string { return sheet.Language() }
+	// GroupByPrimaryTag groups by the first (normalized) tag
+	GroupByPrimaryTag GroupKey = func(sheet *Sheet) string {
+		for _, tag := range sheet.Tags {
+			if normalized := NormalizeTag(tag); normalized != "" {
+				return normalized
+			}
+		}
+		return ""
+	}
+	// GroupByRevision groups by the sheet revision (v2, v3, v3.1)
+	GroupByRevision GroupKey = func(sheet *Sheet) string { return sheet.Revision.String() }
+)
+
+// SortKey compares two sheets (negative if a < b, zero if equal, positive if a > b)
+type SortKey func(a, b *Sheet) int
+
+// SortKey values
+var (
+	// SortByName sorts by name, collated with CollateFold (see SortByNameCollated)
+	SortByName = SortByNameCollated(CollateFold)
+	// SortByModificationDate sorts by modification date (oldest first)
+	SortByModificationDate SortKey = func(a, b *Sheet) int { return cmp.Compare(a.ModificationDate, b.ModificationDate) }
+)
+
+// Descending reverses the order of the sort key
+func (k SortKey) Descending() SortKey {
+	return func(a, b *Sheet) int { return k(b, a) }
+}
+
+// Collation compares two strings for sorting
+type Collation func(a, b string) int
+
+// Collation values
+var (
+	// CollateBinary compares strings byte by byte
+	CollateBinary Collation = strings.Compare
+	// CollateFold compares strings case-insensitively (ties broken byte by byte); it is not localization-aware, accented
+	// letters sort after every ASCII letter (see CollateUnicode and CollateLanguage)
+	CollateFold Collation = func(a, b string) int {
+		if result := strings.Compare(strings.ToLower(a), strings.ToLower(b)); result != 0 {
+			return result
+		}
+		return strings.Compare(a, b)
+	}
+	// CollateUnicode compares strings with the root collation of the Unicode Collation Algorithm (see CollateLanguage)
+	CollateUnicode = CollateLanguage(language.Und)
+)
+
+// CollateLanguage returns the collation comparing strings in the order of the given language (e.g. language.Swedish),
+// as defined by the Unicode Collation Algorithm: accents and case only break ties, so "Émile" sorts before "Zoe"
+// Strings equal for the language are compared byte by byte, so distinct strings never collate as equal
+func CollateLanguage(tag language.Tag) Collation {
+	// Collators are not safe for concurrent use, so they are pooled
+	collators := &sync.Pool{New: func() any { return collate.New(tag) }}
+	return func(a, b string) int {
+		collator := collators.Get().(*collate.Collator)
+		defer collators.Put(collator)
+		if result := collator.CompareString(a, b); result != 0 {
+			return result
+		}
+		return strings.Compare(a, b)
+	}
+}
+
+// SortByNameCollated returns the sort key sorting by name, collated with the given collation
+func SortByNameCollated(collation Collation) SortKey {
+	return func(a, b *Sheet) int { return collation(string(a.Name), string(b.Name)) }
+}
+
+// NormalizeCreator returns the normalized creator handle (trimmed, lowercase, no leading @)
+// Blank creators are normalized to the anonymous creator
+func NormalizeCreator(creator string) string {
+	handle := strings.TrimPrefix(strings.TrimSpace(creator), "@")
+	if stringsx.IsBlank(handle) {
+		handle = AnonymousCreator
+	}
+	return strings.ToLower(strings.TrimSpace(handle))
+}
+
+// NormalizeTag returns the normalized tag (trimmed, lowercase, single spaced)
+func NormalizeTag(tag string) string {
+	return strings.ToLower(strings.Join(strings.Fields(tag), " "))
+}
+
+// GroupBy groups the sheets by the given key (sheets keep the collection order within each group)
+func (c Collection) GroupBy(key GroupKey) map[string][]*Sheet {
+	groups := make(map[string][]*Sheet)
+	for _, sheet := range c {
+		group := key(sheet)
+		groups[group] = append(groups[group], sheet)
+	}
+	return groups
+}
+
+// SortBy returns a copy of the collection stably sorted by the given keys (in order of precedence)
+// The fingerprint is always used as the final tiebreak, so the order does not depend on the input order
+func (c Collection) SortBy(keys ...SortKey) Collection {
+	// Fingerprints are computed lazily (only for ties)
+	fingerprints := make(map[*Sheet]string)
+	fingerprint := func(sheet *Sheet) string {
+		value, ok := fingerprints[sheet]
+		if !ok {
+			value = sheet.Fingerprint()
+			fingerprints[sheet] = value
+		}
+		return value
+	}
+
+	// Sort a copy of the collection
+	sorted := slices.Clone(c)
+	slices.SortStableFunc(sorted, func(a, b *Sheet) int {
+		for _, key := range keys {
+			if result := key(a, b); result != 0 {
+				return result
+			}
+		}
+		return strings.Compare(fingerprint(a), fingerprint(b))
+	})
+
+	// Return the sorted copy
+	return sorted
+}
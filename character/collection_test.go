@@ -0,0 +1,209 @@
+package character
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/r3dpixel/card-parser/property"
+	"github.com/r3dpixel/toolkit/timestamp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/text/language"
+)
+
+func createCollectionSheet(revision Revision, name, creator, description string, tags []string, modified int64) *Sheet {
+	sheet := DefaultSheet(revision)
+	sheet.Name = property.String(name)
+	sheet.Creator = property.String(creator)
+	sheet.Description = property.String(description)
+	sheet.Tags = tags
+	sheet.ModificationDate = timestamp.Seconds(modified)
+	return sheet
+}
+
+func createCollection() (Collection, map[string]*Sheet) {
+	sheets := map[string]*Sheet{
+		"alice":  createCollectionSheet(RevisionV3, "alice", "@Author", "A brave knight", []string{" Fantasy ", "Adventure"}, 300),
+		"Bob":    createCollectionSheet(RevisionV2, "Bob", "author", "Un chevalier", []string{"Sci-Fi"}, 150),
+		"Yuki":   createCollectionSheet(RevisionV3, "Yuki", "Other", "彼女は学生です", []string{"  ", "slice of  life"}, 200),
+		"Carl1":  createCollectionSheet(RevisionV2, "Carl", "", "First Carl", nil, 100),
+		"Carl2":  createCollectionSheet(RevisionV2, "Carl", "  ", "Second Carl", nil, 100),
+		"Oleg":   createCollectionSheet(RevisionV3, "Oleg", "Other", "Он студент", []string{"fantasy"}, 400),
+		"ALICE2": createCollectionSheet(RevisionV3, "Alice", "Author", "Another Alice", []string{"fantasy"}, 50),
+	}
+	collection := Collection{sheets["alice"], sheets["Bob"], sheets["Yuki"], sheets["Carl1"], sheets["Carl2"], sheets["Oleg"], sheets["ALICE2"]}
+	return collection, sheets
+}
+
+func TestCollection_GroupBy(t *testing.T) {
+	collection, sheets := createCollection()
+
+	tests := []struct {
+		name     string
+		key      GroupKey
+		expected map[string][]*Sheet
+	}{
+		{
+			name: "Creator",
+			key:  GroupByCreator,
+			expected: map[string][]*Sheet{
+				"author":    {sheets["alice"], sheets["Bob"], sheets["ALICE2"]},
+				"other":     {sheets["Yuki"], sheets["Oleg"]},
+				"anonymous": {sheets["Carl1"], sheets["Carl2"]},
+			},
+		},
+		{
+			name: "Language",
+			key:  GroupByLanguage,
+			expected: map[string][]*Sheet{
+				"und-Latn": {sheets["alice"], sheets["Bob"], sheets["Carl1"], sheets["Carl2"], sheets["ALICE2"]},
+				"ja":       {sheets["Yuki"]},
+				"und-Cyrl": {sheets["Oleg"]},
+			},
+		},
+		{
+			name: "Primary tag",
+			key:  GroupByPrimaryTag,
+			expected: map[string][]*Sheet{
+				"fantasy":       {sheets["alice"], sheets["Oleg"], sheets["ALICE2"]},
+				"sci-fi":        {sheets["Bob"]},
+				"slice of life": {sheets["Yuki"]},
+				"":              {sheets["Carl1"], sheets["Carl2"]},
+			},
+		},
+		{
+			name: "Revision",
+			key:  GroupByRevision,
+			expected: map[string][]*Sheet{
+				"v2": {sheets["Bob"], sheets["Carl1"], sheets["Carl2"]},
+				"v3": {sheets["alice"], sheets["Yuki"], sheets["Oleg"], sheets["ALICE2"]},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, collection.GroupBy(tt.key))
+		})
+	}
+}
+
+func TestCollection_SortBy(t *testing.T) {
+	collection, sheets := createCollection()
+
+	// The two Carl sheets are identical for every sort key: their order is decided by the fingerprint
+	firstCarl, secondCarl := sheets["Carl1"], sheets["Carl2"]
+	if strings.Compare(firstCarl.Fingerprint(), secondCarl.Fingerprint()) > 0 {
+		firstCarl, secondCarl = secondCarl, firstCarl
+	}
+
+	tests := []struct {
+		name     string
+		keys     []SortKey
+		expected Collection
+	}{
+		{
+			name:     "Name",
+			keys:     []SortKey{SortByName},
+			expected: Collection{sheets["ALICE2"], sheets["alice"], sheets["Bob"], firstCarl, secondCarl, sheets["Oleg"], sheets["Yuki"]},
+		},
+		{
+			name:     "Binary name",
+			keys:     []SortKey{SortByNameCollated(CollateBinary)},
+			expected: Collection{sheets["ALICE2"], sheets["Bob"], firstCarl, secondCarl, sheets["Oleg"], sheets["Yuki"], sheets["alice"]},
+		},
+		{
+			name:     "Modification date then name",
+			keys:     []SortKey{SortByModificationDate, SortByName},
+			expected: Collection{sheets["ALICE2"], firstCarl, secondCarl, sheets["Bob"], sheets["Yuki"], sheets["alice"], sheets["Oleg"]},
+		},
+		{
+			name:     "Modification date descending",
+			keys:     []SortKey{SortByModificationDate.Descending()},
+			expected: Collection{sheets["Oleg"], sheets["alice"], sheets["Yuki"], sheets["Bob"], firstCarl, secondCarl, sheets["ALICE2"]},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sorted := collection.SortBy(tt.keys...)
+			assert.Equal(t, tt.expected, sorted)
+
+			// The order does not depend on the input order
+			reversed := make(Collection, len(collection))
+			for index, sheet := range collection {
+				reversed[len(collection)-1-index] = sheet
+			}
+			assert.Equal(t, sorted, reversed.SortBy(tt.keys...))
+		})
+	}
+
+	t.Run("Fingerprint only", func(t *testing.T) {
+		sorted := collection.SortBy()
+		require.Len(t, sorted, len(collection))
+		for index := 1; index < len(sorted); index++ {
+			assert.Less(t, sorted[index-1].Fingerprint(), sorted[index].Fingerprint())
+		}
+	})
+
+	t.Run("Original untouched", func(t *testing.T) {
+		original, _ := createCollection()
+		require.Len(t, collection, len(original))
+		for index := range collection {
+			assert.Equal(t, original[index].Name, collection[index].Name)
+		}
+	})
+}
+
+func TestCollation(t *testing.T) {
+	assert.Negative(t, CollateFold("alice", "Bob"))
+	assert.Positive(t, CollateBinary("alice", "Bob"))
+	assert.Negative(t, CollateFold("Alice", "alice"))
+	assert.Zero(t, CollateFold("alice", "alice"))
+
+	t.Run("Unicode", func(t *testing.T) {
+		tests := []struct {
+			name      string
+			collation Collation
+			a         string
+			b         string
+		}{
+			{"Accented before later letters", CollateUnicode, "Émile", "Zoe"},
+			{"Accents break ties", CollateUnicode, "Emile", "Émile"},
+			{"Accents before later letters", CollateUnicode, "émile", "Emma"},
+			{"Case breaks ties", CollateUnicode, "alice", "Alice"},
+			{"Case insensitive", CollateUnicode, "alice", "Bob"},
+			{"Non-Latin scripts", CollateUnicode, "Zoe", "Ωmega"},
+			{"Swedish Ö after Z", CollateLanguage(language.Swedish), "Zoe", "Örjan"},
+			{"German Ö before Z", CollateLanguage(language.German), "Örjan", "Zoe"},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				assert.Negative(t, tt.collation(tt.a, tt.b))
+				assert.Positive(t, tt.collation(tt.b, tt.a))
+			})
+		}
+		assert.Zero(t, CollateUnicode("Émile", "Émile"))
+		assert.Positive(t, CollateFold("Émile", "Zoe"))
+	})
+
+	t.Run("Sort by collated name", func(t *testing.T) {
+		collection := Collection{
+			createCollectionSheet(RevisionV3, "Zoe", "", "", nil, 0),
+			createCollectionSheet(RevisionV3, "Émile", "", "", nil, 0),
+			createCollectionSheet(RevisionV3, "alice", "", "", nil, 0),
+		}
+		var names []string
+		for _, sheet := range collection.SortBy(SortByNameCollated(CollateUnicode)) {
+			names = append(names, string(sheet.Name))
+		}
+		assert.Equal(t, []string{"alice", "Émile", "Zoe"}, names)
+	})
+}
+
+func TestNormalizeCreator(t *testing.T) {
+	assert.Equal(t, "author", NormalizeCreator("  @Author "))
+	assert.Equal(t, "anonymous", NormalizeCreator("  "))
+	assert.Equal(t, "anonymous", NormalizeCreator("@"))
+}
@@ -0,0 +1,221 @@
+package character
+
+import (
+	"fmt"
+	"slices"
+	"unicode/utf8"
+
+	"github.com/r3dpixel/card-parser/internal/codec"
+	"github.com/r3dpixel/toolkit/stringsx"
+)
+
+// Frontend identifies a chat frontend that imports chara cards
+type Frontend string
+
+// Built-in Frontend values
+const (
+	FrontendSillyTavern       Frontend = "sillytavern"
+	FrontendSillyTavernLegacy Frontend = "sillytavern_legacy"
+	FrontendJanitorAI         Frontend = "janitorai"
+	FrontendAgnai             Frontend = "agnai"
+)
+
+// Severity classifies how a frontend handles a feature it does not support
+type Severity int
+
+// Allowed Severity values
+const (
+	SeverityIgnored  Severity = iota // The feature is silently ignored on import
+	SeverityBreaking                 // The feature breaks the import
+)
+
+// String returns the name of the severity
+func (s Severity) String() string {
+	switch s {
+	case SeverityIgnored:
+		return "silently-ignored"
+	case SeverityBreaking:
+		return "breaks-import"
+	default:
+		return fmt.Sprintf("Severity(%d)", int(s))
+	}
+}
+
+// Capability describes the known limitations of a frontend
+type Capability struct {
+	Name          string         // Display name of the frontend
+	Fields        []string       // Card data fields read by the frontend
+	MaxLengths    map[string]int // Maximum length (in characters) of text fields, exceeding it breaks the import
+	ExtensionKeys []string       // Well-known extension keys honored by the frontend
+	Revisions     []Revision     // Card revisions the frontend reads from PNG chunks (V2 = chara, V3 = ccv3)
+}
+
+// CompatNote a used feature that is not supported by the target frontend
+type CompatNote struct {
+	Feature  string   // Field, extension key (extensions.<key>) or PNG chunk spec (png.<spec>)
+	Severity Severity // How the frontend handles the feature
+	Message  string   // Human-readable description
+}
+
+// SpecFields are the card data fields (V2 and V3 spec) checked by CompatibilityReport
+var SpecFields = []string{
+	NameField, DescriptionField, PersonalityField, ScenarioField, FirstMessageField, MessageExamplesField,
+	CreatorNotesField, SystemPromptField, PostHistoryInstructionsField, AlternateGreetingsField, CharacterBookField,
+	TagsField, CreatorField, CharacterVersionField, AssetsField, NicknameField, CreatorNotesMultilingualField,
+	SourceField, GroupGreetingsField, CreationDateField, ModificationDateField,
+}
+
+// v2Fields are the card data fields of the V2 spec
+var v2Fields = []string{
+	NameField, DescriptionField, PersonalityField, ScenarioField, FirstMessageField, MessageExamplesField,
+	CreatorNotesField, SystemPromptField, PostHistoryInstructionsField, AlternateGreetingsField, CharacterBookField,
+	TagsField, CreatorField, CharacterVersionField,
+}
+
+// sillyTavernExtensionKeys are the extension keys honored by SillyTavern (the superset of the known frontends)
+var sillyTavernExtensionKeys = []string{DepthPromptKey, "talkativeness", "fav", "world", RegexScriptsKey}
+
+// KnownExtensionKeys are the well-known extension keys checked by CompatibilityReport (other keys are opaque)
+var KnownExtensionKeys = slices.Clone(sillyTavernExtensionKeys)
+
+// Frontends capability tables of the known frontends (downstreams can register their own)
+var Frontends = map[Frontend]Capability{
+	FrontendSillyTavern: {
+		Name:          "SillyTavern",
+		Fields:        append(slices.Clone(v2Fields), GroupGreetingsField),
+		ExtensionKeys: slices.Clone(sillyTavernExtensionKeys),
+		Revisions:     []Revision{RevisionV2, RevisionV3},
+	},
+	FrontendSillyTavernLegacy: {
+		Name:          "SillyTavern (before 1.10)",
+		Fields:        slices.Clone(v2Fields),
+		ExtensionKeys: []string{"talkativeness", "fav", "world"},
+		Revisions:     []Revision{RevisionV2},
+	},
+	FrontendJanitorAI: {
+		Name: "JanitorAI",
+		Fields: []string{
+			NameField, DescriptionField, PersonalityField, ScenarioField, FirstMessageField, MessageExamplesField,
+			CreatorNotesField, TagsField, CreatorField,
+		},
+		MaxLengths: map[string]int{NameField: 100},
+		Revisions:  []Revision{RevisionV2, RevisionV3},
+	},
+	FrontendAgnai: {
+		Name: "Agnai",
+		Fields: []string{
+			NameField, DescriptionField, PersonalityField, ScenarioField, FirstMessageField, MessageExamplesField,
+			CreatorNotesField, SystemPromptField, PostHistoryInstructionsField, AlternateGreetingsField,
+			CharacterBookField, TagsField, CreatorField, CharacterVersionField,
+		},
+		ExtensionKeys: []string{DepthPromptKey},
+		Revisions:     []Revision{RevisionV2, RevisionV3},
+	},
+}
+
+// CompatibilityReport returns the features used by the sheet that the target frontend does not support
+// Returns nil if the target frontend is not registered in Frontends
+func CompatibilityReport(sheet *Sheet, target Frontend) []CompatNote {
+	// Get the capability table of the target
+	capability, ok := Frontends[target]
+	if !ok || sheet == nil {
+		return nil
+	}
+
+	// Collect the data fields as they are exported
+	data, err := contentMap(&sheet.Content)
+	if err != nil {
+		return nil
+	}
+
+	var notes []CompatNote
+
+	// Check the PNG chunk revision
+	// Every 3.x revision is read from the same chunk
+	if !slices.ContainsFunc(capability.Revisions, func(revision Revision) bool { return revision.Major() == sheet.Revision.Major() }) {
+		spec := Stamps[sheet.Revision].Spec
+		notes = append(notes, CompatNote{
+			Feature:  "png." + string(spec),
+			Severity: SeverityBreaking,
+			Message:  fmt.Sprintf("%s does not read %s PNG chunks", capability.Name, spec),
+		})
+	}
+
+	// Check the used fields
+	for _, field := range SpecFields {
+		value := data[field]
+		if !isUsed(value) {
+			continue
+		}
+		// The field is not read by the frontend
+		if !slices.Contains(capability.Fields, field) {
+			notes = append(notes, CompatNote{
+				Feature:  field,
+				Severity: SeverityIgnored,
+				Message:  fmt.Sprintf("%s ignores %s", capability.Name, field),
+			})
+			continue
+		}
+		// The field is longer than the frontend accepts
+		text, isText := value.(string)
+		if maxLength, limited := capability.MaxLengths[field]; limited && isText && utf8.RuneCountInString(text) > maxLength {
+			notes = append(notes, CompatNote{
+				Feature:  field,
+				Severity: SeverityBreaking,
+				Message:  fmt.Sprintf("%s rejects %s longer than %d characters", capability.Name, field, maxLength),
+			})
+		}
+	}
+
+	// Check the well-known extension keys
+	extensions, _ := data[ExtensionsField].(map[string]any)
+	for _, key := range KnownExtensionKeys {
+		if !isUsed(extensions[key]) || slices.Contains(capability.ExtensionKeys, key) {
+			continue
+		}
+		notes = append(notes, CompatNote{
+			Feature:  ExtensionsField + "." + key,
+			Severity: SeverityIgnored,
+			Message:  fmt.Sprintf("%s ignores the %s extension", capability.Name, key),
+		})
+	}
+
+	// Return the notes
+	return notes
+}
+
+// contentMap returns the JSON object representation of the content
+func contentMap(c *Content) (map[string]any, error) {
+	// Encode the content
+	data, err := codec.Marshal(c)
+	if err != nil {
+		return nil, err
+	}
+	// Decode it as a generic map
+	var result map[string]any
+	if err := codec.Unmarshal(data, &result); err != nil {
+		return nil, err
+	}
+	// Return the map
+	return result, nil
+}
+
+// isUsed returns true if a JSON value carries data (non-blank string, non-empty collection, non-zero number, true)
+func isUsed(value any) bool {
+	switch typedValue := value.(type) {
+	case nil:
+		return false
+	case string:
+		return stringsx.IsNotBlank(typedValue)
+	case bool:
+		return typedValue
+	case float64:
+		return typedValue != 0
+	case []any:
+		return len(typedValue) > 0
+	case map[string]any:
+		return len(typedValue) > 0
+	default:
+		return true
+	}
+}
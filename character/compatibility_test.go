@@ -0,0 +1,142 @@
+package character
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/r3dpixel/card-parser/property"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompatibilityReport_Comprehensive(t *testing.T) {
+	sheet, err := FromBytes([]byte(comprehensiveSheetJSON))
+	require.NoError(t, err)
+
+	v3Dates := []CompatNote{
+		{Feature: NicknameField, Severity: SeverityIgnored},
+		{Feature: CreationDateField, Severity: SeverityIgnored},
+		{Feature: ModificationDateField, Severity: SeverityIgnored},
+	}
+
+	tests := []struct {
+		name     string
+		target   Frontend
+		expected []CompatNote
+	}{
+		{
+			name:     "SillyTavern",
+			target:   FrontendSillyTavern,
+			expected: v3Dates,
+		},
+		{
+			name:   "SillyTavern legacy",
+			target: FrontendSillyTavernLegacy,
+			expected: append(append([]CompatNote{
+				{Feature: "png.chara_card_v3", Severity: SeverityBreaking},
+			}, v3Dates...),
+				CompatNote{Feature: "extensions.depth_prompt", Severity: SeverityIgnored},
+			),
+		},
+		{
+			name:   "JanitorAI",
+			target: FrontendJanitorAI,
+			expected: append(append([]CompatNote{
+				{Feature: SystemPromptField, Severity: SeverityIgnored},
+				{Feature: PostHistoryInstructionsField, Severity: SeverityIgnored},
+				{Feature: AlternateGreetingsField, Severity: SeverityIgnored},
+				{Feature: CharacterBookField, Severity: SeverityIgnored},
+				{Feature: CharacterVersionField, Severity: SeverityIgnored},
+			}, v3Dates...),
+				CompatNote{Feature: "extensions.depth_prompt", Severity: SeverityIgnored},
+			),
+		},
+		{
+			name:     "Agnai",
+			target:   FrontendAgnai,
+			expected: v3Dates,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			notes := CompatibilityReport(sheet, tt.target)
+			require.Len(t, notes, len(tt.expected))
+			for i, note := range notes {
+				assert.Equal(t, tt.expected[i].Feature, note.Feature)
+				assert.Equal(t, tt.expected[i].Severity, note.Severity)
+				assert.Contains(t, note.Message, Frontends[tt.target].Name)
+			}
+		})
+	}
+}
+
+func TestCompatibilityReport(t *testing.T) {
+	t.Run("Group greetings dropped by Agnai", func(t *testing.T) {
+		sheet := DefaultSheet(RevisionV3)
+		sheet.Name = "Char"
+		sheet.GroupGreetings = property.StringArray{"Hello everyone"}
+
+		notes := CompatibilityReport(sheet, FrontendAgnai)
+		require.Len(t, notes, 1)
+		assert.Equal(t, GroupGreetingsField, notes[0].Feature)
+		assert.Equal(t, "Agnai ignores group_only_greetings", notes[0].Message)
+		assert.Empty(t, CompatibilityReport(sheet, FrontendSillyTavern))
+	})
+
+	t.Run("Name too long for JanitorAI", func(t *testing.T) {
+		sheet := DefaultSheet(RevisionV2)
+		sheet.Name = property.String(strings.Repeat("a", 101))
+
+		notes := CompatibilityReport(sheet, FrontendJanitorAI)
+		require.Len(t, notes, 1)
+		assert.Equal(t, NameField, notes[0].Feature)
+		assert.Equal(t, SeverityBreaking, notes[0].Severity)
+	})
+
+	t.Run("V2 card on legacy SillyTavern", func(t *testing.T) {
+		sheet := DefaultSheet(RevisionV2)
+		sheet.Name = "Char"
+		sheet.Extensions = map[string]any{"custom": "opaque"}
+
+		assert.Empty(t, CompatibilityReport(sheet, FrontendSillyTavernLegacy))
+	})
+
+	t.Run("Unknown frontend", func(t *testing.T) {
+		assert.Nil(t, CompatibilityReport(DefaultSheet(RevisionV3), "unknown"))
+		assert.Nil(t, CompatibilityReport(nil, FrontendSillyTavern))
+	})
+
+	t.Run("Registered frontend", func(t *testing.T) {
+		Frontends["custom"] = Capability{Name: "Custom", Fields: []string{NameField}, Revisions: []Revision{RevisionV3}}
+		defer delete(Frontends, "custom")
+
+		sheet := DefaultSheet(RevisionV2)
+		sheet.Name = "Char"
+		sheet.Tags = property.StringArray{"tag"}
+
+		notes := CompatibilityReport(sheet, "custom")
+		require.Len(t, notes, 2)
+		assert.Equal(t, CompatNote{Feature: "png.chara_card_v2", Severity: SeverityBreaking, Message: "Custom does not read chara_card_v2 PNG chunks"}, notes[0])
+		assert.Equal(t, CompatNote{Feature: TagsField, Severity: SeverityIgnored, Message: "Custom ignores tags"}, notes[1])
+	})
+}
+
+func TestSeverity_String(t *testing.T) {
+	assert.Equal(t, "silently-ignored", SeverityIgnored.String())
+	assert.Equal(t, "breaks-import", SeverityBreaking.String())
+	assert.Equal(t, "Severity(7)", Severity(7).String())
+}
+
+func TestCompatibility_FieldAndKeyTables(t *testing.T) {
+	// The V2 fields lead the spec fields
+	assert.Equal(t, v2Fields, SpecFields[:len(v2Fields)])
+	assert.Equal(t, CharacterVersionField, v2Fields[len(v2Fields)-1])
+
+	// The known extension keys are the SillyTavern ones, without sharing the backing array
+	assert.Equal(t, KnownExtensionKeys, Frontends[FrontendSillyTavern].ExtensionKeys)
+	for _, capability := range Frontends {
+		assert.Subset(t, KnownExtensionKeys, capability.ExtensionKeys, capability.Name)
+	}
+	assert.NotSame(t, &KnownExtensionKeys[0], &Frontends[FrontendSillyTavern].ExtensionKeys[0])
+}
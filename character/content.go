@@ -1,12 +1,13 @@
 package character
 
 import (
+	"maps"
 	"regexp"
+	"slices"
 	"strings"
 
+	"github.com/r3dpixel/card-parser/internal/codec"
 	"github.com/r3dpixel/card-parser/property"
-	"github.com/r3dpixel/toolkit/jsonx"
-	"github.com/r3dpixel/toolkit/sonicx"
 	"github.com/r3dpixel/toolkit/stringsx"
 	"github.com/r3dpixel/toolkit/timestamp"
 	"github.com/spf13/cast"
@@ -14,27 +15,42 @@ import (
 
 // Field names
 const (
-	NameField                    string = "name"
-	DescriptionField             string = "description"
-	PersonalityField             string = "personality"
-	ScenarioField                string = "scenario"
-	FirstMessageField            string = "first_mes"
-	MessageExamplesField         string = "mes_example"
-	CreatorNotesField            string = "creator_notes"
-	PostHistoryInstructionsField string = "post_history_instructions"
-	AlternateGreetingsField      string = "alternate_greetings"
-	TagsField                    string = "tags"
-	CreatorField                 string = "creator"
-	DepthPromptKey               string = "depth_prompt"
-	DepthPromptPromptKey         string = "prompt"
-	DepthPromptDepthKey          string = "depth"
-	DefaultDepth                 int    = 4
+	TitleField                    string = "title"
+	NameField                     string = "name"
+	DescriptionField              string = "description"
+	PersonalityField              string = "personality"
+	ScenarioField                 string = "scenario"
+	FirstMessageField             string = "first_mes"
+	MessageExamplesField          string = "mes_example"
+	CreatorNotesField             string = "creator_notes"
+	SystemPromptField             string = "system_prompt"
+	PostHistoryInstructionsField  string = "post_history_instructions"
+	AlternateGreetingsField       string = "alternate_greetings"
+	CharacterBookField            string = "character_book"
+	TagsField                     string = "tags"
+	CreatorField                  string = "creator"
+	CharacterVersionField         string = "character_version"
+	ExtensionsField               string = "extensions"
+	AssetsField                   string = "assets"
+	NicknameField                 string = "nickname"
+	CreatorNotesMultilingualField string = "creator_notes_multilingual"
+	SourceField                   string = "source"
+	GroupGreetingsField           string = "group_only_greetings"
+	CreationDateField             string = "creation_date"
+	ModificationDateField         string = "modification_date"
+	SourceIDField                 string = "source_id"
+	DepthPromptKey                string = "depth_prompt"
+	DepthPromptPromptKey          string = "prompt"
+	DepthPromptDepthKey           string = "depth"
+	DefaultDepth                  int    = 4
 )
 
 var (
 	// Regexes to fix errors of the type {{{user}, {{char}, {char}}, {char} -> {{user}, {{char}}
 	charRegex = regexp.MustCompile(`\{+char}+`)
 	userRegex = regexp.MustCompile(`\{+user}+`)
+	// Regex matching the name of a macro at the start of its body: {{random::a,b}} -> random, {{// note}} -> //
+	macroNameRegex = regexp.MustCompile(`^\s*(//|[A-Za-z_][A-Za-z0-9_]*)`)
 )
 
 // contentAlias alias for Content to avoid circular references
@@ -58,6 +74,8 @@ type Content struct {
 	Creator                 property.String      `json:"creator"`
 	CharacterVersion        property.String      `json:"character_version"`
 	DepthPrompt             DepthPrompt          `json:"-"`
+	Risu                    *RisuExtensions      `json:"-"`
+	RegexScripts            []RegexScript        `json:"-"`
 	Extensions              map[string]any       `json:"extensions,omitzero"`
 
 	Assets                   []Asset                    `json:"assets,omitzero"`
@@ -72,6 +90,12 @@ type Content struct {
 	CharacterID property.String `json:"character_id"`
 	PlatformID  property.String `json:"platform_id"`
 	DirectLink  property.String `json:"direct_link"`
+
+	lazyBook      *lazyBook // Lorebook not decoded yet (see FromBytesLazy)
+	bookResolved  bool      // The lazy lorebook was resolved into CharacterBook (see Content.Book)
+	keepEmptyBook bool      // Empty lorebooks are kept (see DecodeOptions.PreserveEmptyBook)
+	// An empty lorebook was decoded as absent (see NoteEmptyBookDropped)
+	emptyBookDropped bool
 }
 
 // DepthPrompt depth prompt structure of a V3 chara card
@@ -80,28 +104,95 @@ type DepthPrompt struct {
 	Depth  int
 }
 
-// MarshalJSON marshals Content into JSON format to respect Silly Tavern format using Sonic
+// MarshalJSON marshals Content into JSON format to respect Silly Tavern format using the JSON codec
+// The content is never modified, so concurrent marshaling of a shared content is safe
+// A lorebook not decoded yet (see FromBytesLazy) is written back as is, after the other fields
 func (c *Content) MarshalJSON() ([]byte, error) {
-	// Insert depth prompt extension
-	depthMap := c.insertDepthPrompt()
-	// Purge depth prompt extension after marshaling (idempotent)
-	defer c.purgeDepthPromptExtension(depthMap)
-	// Delegate to Sonic encoder
-	return sonicx.Config.Marshal((*contentAlias)(c))
+	// Delegate to the JSON codec
+	data, err := codec.Marshal((*contentAlias)(c.marshaledCopy()))
+	if err != nil {
+		return nil, err
+	}
+	return c.appendPendingBook(data), nil
+}
+
+// marshaledCopy returns the shallow copy of the content that is marshaled
+func (c *Content) marshaledCopy() *Content {
+	// Copy the content
+	content := *c
+	// Insert depth prompt extension (into a copy of the Extensions map)
+	content.Extensions = c.insertDepthPrompt()
+	// Insert RisuAI extensions (into a copy of the Extensions map)
+	content.Extensions = c.insertRisuExtensions(content.Extensions)
+	// Insert regex scripts (into a copy of the Extensions map)
+	content.Extensions = c.insertRegexScripts(content.Extensions)
+	// Omit empty lorebooks
+	if content.CharacterBook.IsEmpty() && !c.keepEmptyBook {
+		content.CharacterBook = nil
+	}
+	// Return the copy
+	return &content
 }
 
-// UnmarshalJSON unmarshals JSON into the Content, with fallbacks and best effort strategies using Sonic
+// UnmarshalJSON unmarshals JSON into the Content, with fallbacks and best effort strategies using the JSON codec
 func (c *Content) UnmarshalJSON(data []byte) error {
-	// Unmarshal from JSON using Sonic
-	if err := sonicx.Config.UnmarshalFromString(stringsx.FromBytes(data), (*contentAlias)(c)); err != nil {
+	// Unmarshal from JSON using the JSON codec (the dates are parsed as flexible timestamps)
+	wrapper := struct {
+		*contentAlias
+		CreationDate     property.Timestamp `json:"creation_date"`
+		ModificationDate property.Timestamp `json:"modification_date"`
+	}{(*contentAlias)(c), property.Timestamp(c.CreationDate), property.Timestamp(c.ModificationDate)}
+	if err := codec.UnmarshalFromString(stringsx.FromBytes(data), &wrapper); err != nil {
 		return err
 	}
+	c.CreationDate = timestamp.Seconds(wrapper.CreationDate)
+	c.ModificationDate = timestamp.Seconds(wrapper.ModificationDate)
 	c.extractDepthPrompt()
+	c.extractRisuExtensions()
+	c.extractRegexScripts()
+
+	// An empty lorebook (e.g. "character_book": {}) is treated as absent (see DecodeOptions.PreserveEmptyBook),
+	// and noted (unlike a null lorebook)
+	c.emptyBookDropped = c.CharacterBook != nil && c.CharacterBook.IsEmpty() && !c.keepEmptyBook
+	if c.emptyBookDropped {
+		c.CharacterBook = nil
+	}
 
 	// Decoding is complete
 	return nil
 }
 
+// Clone returns a deep copy of the content (slices, maps, lorebook and extensions are not shared)
+func (c *Content) Clone() *Content {
+	// Copy the value fields
+	clone := *c
+
+	// Copy the slices
+	clone.AlternateGreetings = slices.Clone(c.AlternateGreetings)
+	clone.Tags = slices.Clone(c.Tags)
+	clone.Source = slices.Clone(c.Source)
+	clone.GroupGreetings = slices.Clone(c.GroupGreetings)
+	clone.Assets = slices.Clone(c.Assets)
+
+	// Copy the maps
+	clone.Extensions = cloneMap(c.Extensions)
+	clone.CreatorNotesMultilingual = maps.Clone(c.CreatorNotesMultilingual)
+	clone.Risu = c.Risu.Clone()
+	clone.RegexScripts = cloneRegexScripts(c.RegexScripts)
+
+	// Copy the lorebook (a lorebook not decoded yet stays pending on the copy, sharing the raw JSON)
+	if c.CharacterBook != nil {
+		clone.CharacterBook = c.CharacterBook.Clone()
+	}
+	clone.lazyBook, clone.bookResolved = nil, false
+	if raw := c.pendingBook(); raw != nil {
+		clone.lazyBook = &lazyBook{raw: raw}
+	}
+
+	// Return the copy
+	return &clone
+}
+
 // NormalizeSymbols replace all abnormal quotes, apostrophes or commas characters from ALL fields with the normal ASCII version (`"`, `,` `'`)
 func (c *Content) NormalizeSymbols() {
 	// Fix Quotes applied on every field
@@ -114,10 +205,22 @@ func (c *Content) NormalizeSymbols() {
 	c.SystemPrompt.NormalizeSymbols()
 	c.PostHistoryInstructions.NormalizeSymbols()
 
-	// Fix Quotes applied on each and every greeting
-	greetings := c.AlternateGreetings
-	for index := range greetings {
-		greetings[index] = stringsx.NormalizeSymbols(greetings[index])
+	// Fix Quotes applied on each and every greeting (alternate and group only)
+	for _, greetings := range []property.StringArray{c.AlternateGreetings, c.GroupGreetings} {
+		for index := range greetings {
+			greetings[index] = stringsx.NormalizeSymbols(greetings[index])
+		}
+	}
+
+	// Fix Quotes applied on every multilingual creator note (the language codes ARE NOT affected)
+	for language, notes := range c.CreatorNotesMultilingual {
+		notes.NormalizeSymbols()
+		c.CreatorNotesMultilingual[language] = notes
+	}
+
+	// Trim every source
+	for index := range c.Source {
+		c.Source[index] = strings.TrimSpace(c.Source[index])
 	}
 
 	// Fix Quotes applied on every entry (name, comment, content)
@@ -130,6 +233,18 @@ func (c *Content) NormalizeSymbols() {
 	c.DepthPrompt.Prompt = stringsx.NormalizeSymbols(c.DepthPrompt.Prompt)
 }
 
+// Sanitize resets the out-of-range values of the lorebook entries extensions (see BookEntryExtensions.Sanitize)
+func (c *Content) Sanitize() {
+	if c.CharacterBook == nil {
+		return
+	}
+	for _, entry := range c.CharacterBook.Entries {
+		if entry != nil {
+			entry.Extensions.Sanitize()
+		}
+	}
+}
+
 // FixUserCharTemplates fixes the user character templates for all fields: {{{user}, {{char}, {char}}, {char} -> {{user}, {{char}}
 func (c *Content) FixUserCharTemplates() {
 	c.Description = c.fixUserCharTemplateProp(c.Description)
@@ -142,6 +257,9 @@ func (c *Content) FixUserCharTemplates() {
 	for index := range c.AlternateGreetings {
 		c.AlternateGreetings[index] = c.fixUserCharTemplate(c.AlternateGreetings[index])
 	}
+	for index := range c.GroupGreetings {
+		c.GroupGreetings[index] = c.fixUserCharTemplate(c.GroupGreetings[index])
+	}
 
 	c.DepthPrompt.Prompt = c.fixUserCharTemplate(c.DepthPrompt.Prompt)
 
@@ -161,31 +279,33 @@ func (c *Content) fixUserCharTemplate(input string) string {
 	return userRegex.ReplaceAllString(result, "{{user}}")
 }
 
-// insertDepthPrompt inserts the depth prompt extension into the Extensions map
+// insertDepthPrompt returns a copy of the Extensions map with the depth prompt extension inserted
+// The Extensions map itself is not modified (returned as is if there is no prompt)
 func (c *Content) insertDepthPrompt() map[string]any {
 	// Skip if no prompt
 	if stringsx.IsBlank(c.DepthPrompt.Prompt) {
-		return nil
+		return c.Extensions
 	}
 
-	// Create the Extensions map if needed
-	if c.Extensions == nil {
-		c.Extensions = make(map[string]any)
+	// Copy the Extensions map
+	extensions := maps.Clone(c.Extensions)
+	if extensions == nil {
+		extensions = make(map[string]any)
 	}
 
-	// Set the depth map in the Extensions map
-	depthMap, ok := c.Extensions[DepthPromptKey].(map[string]any)
-	if !ok {
+	// Copy the depth map (if any)
+	depthMap, ok := extensions[DepthPromptKey].(map[string]any)
+	if depthMap = maps.Clone(depthMap); !ok || depthMap == nil {
 		depthMap = make(map[string]any)
-		c.Extensions[DepthPromptKey] = depthMap
 	}
+	extensions[DepthPromptKey] = depthMap
 
 	// Populate the depth map with the prompt and depth values
 	depthMap[DepthPromptPromptKey] = c.DepthPrompt.Prompt
 	depthMap[DepthPromptDepthKey] = c.DepthPrompt.Depth
 
-	// Return the depth map
-	return depthMap
+	// Return the extensions
+	return extensions
 }
 
 // extractDepthPrompt extracts the depth prompt extension from the Extensions map and populates the DepthPrompt field
@@ -231,7 +351,7 @@ func (c *Content) extractDepthPrompt() {
 	// If the extension is an array
 	case []any:
 		// Convert the array to JSON string
-		c.DepthPrompt.Prompt = jsonx.String(promptValue)
+		c.DepthPrompt.Prompt = codec.String(promptValue)
 		// Set the depth to default
 		c.DepthPrompt.Depth = DefaultDepth
 		// Remove the extension
@@ -263,18 +383,64 @@ func (c *Content) purgeDepthPromptExtension(depthMap map[string]any) {
 	}
 }
 
-// Integrity checks if the sheet is malformed (missing necessary fields)
+// Integrity checks if the sheet is malformed (missing necessary fields), i.e. Validate reports no errors
 func (c *Content) Integrity() bool {
-	// Check if title, name, description, creator, nickname and source_id are not blank
-	// CreationDate and ModificationDate must be strictly positive
-	// ModificationDate must be greater or equal than CreationDate (ModificationDate >= CreationDate)
-	return stringsx.IsNotBlank(string(c.Title)) &&
-		stringsx.IsNotBlank(string(c.Name)) &&
-		stringsx.IsNotBlank(string(c.Description)) &&
-		stringsx.IsNotBlank(string(c.Creator)) &&
-		stringsx.IsNotBlank(string(c.Nickname)) &&
-		c.CreationDate > 0 &&
-		c.ModificationDate > 0 &&
-		c.ModificationDate >= c.CreationDate &&
-		stringsx.IsNotBlank(string(c.SourceID))
+	return !slices.ContainsFunc(c.Validate(), ValidationIssue.IsError)
+}
+
+// cloneMap returns a deep copy of a JSON object (nested objects and arrays are copied as well)
+func cloneMap(m map[string]any) map[string]any {
+	if m == nil {
+		return nil
+	}
+	return cloneValue(m).(map[string]any)
+}
+
+// cloneValue returns a deep copy of a JSON value
+// The copy is iterative (explicit stack), so any nesting depth is supported
+func cloneValue(value any) any {
+	// Containers copied, but whose children are not copied yet
+	type pending struct {
+		source any
+		target any
+	}
+	var stack []pending
+
+	// shallowCopy returns the shallow copy of a container (and schedules its children), or the value itself
+	shallowCopy := func(value any) any {
+		switch typedValue := value.(type) {
+		case map[string]any:
+			target := make(map[string]any, len(typedValue))
+			stack = append(stack, pending{source: typedValue, target: target})
+			return target
+		case []any:
+			target := make([]any, len(typedValue))
+			stack = append(stack, pending{source: typedValue, target: target})
+			return target
+		default:
+			return value
+		}
+	}
+
+	// Copy the root, then the children of each container
+	root := shallowCopy(value)
+	for len(stack) > 0 {
+		current := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		switch source := current.source.(type) {
+		case map[string]any:
+			target := current.target.(map[string]any)
+			for key, child := range source {
+				target[key] = shallowCopy(child)
+			}
+		case []any:
+			target := current.target.([]any)
+			for index, child := range source {
+				target[index] = shallowCopy(child)
+			}
+		}
+	}
+
+	// Return the copy
+	return root
 }
@@ -194,6 +194,22 @@ func TestContent_UnmarshalJSONFrom(t *testing.T) {
 				assert.Empty(t, content.Extensions)
 			},
 		},
+		{
+			name:     "unmarshal with RFC 3339 and millisecond dates",
+			jsonData: `{"name":"TestChar","creation_date":"2023-11-14T22:13:20Z","modification_date":1700000000123}`,
+			expected: func(t *testing.T, content *Content) {
+				assert.Equal(t, timestamp.Seconds(1700000000), content.CreationDate)
+				assert.Equal(t, timestamp.Seconds(1700000000), content.ModificationDate)
+			},
+		},
+		{
+			name:     "unmarshal with invalid dates",
+			jsonData: `{"name":"TestChar","creation_date":"yesterday","modification_date":true}`,
+			expected: func(t *testing.T, content *Content) {
+				assert.Zero(t, content.CreationDate)
+				assert.Zero(t, content.ModificationDate)
+			},
+		},
 	}
 
 	for _, tt := range tests {
@@ -273,6 +289,126 @@ func TestContent_MarshalUnmarshal_Roundtrip(t *testing.T) {
 	assert.Equal(t, original.DepthPrompt.Depth, unmarshaled.DepthPrompt.Depth)
 }
 
+func TestContent_EmptyCharacterBook(t *testing.T) {
+	originalConfig := sonicx.Config
+	defer func() { sonicx.Config = originalConfig }()
+	sonicx.Config = sonicx.StableSort
+
+	// Reference output of a card without any lorebook
+	absent := `{"name":"Test"}`
+	var reference Content
+	require.NoError(t, sonicx.Config.UnmarshalFromString(absent, &reference))
+	expected, err := sonicx.Config.Marshal(&reference)
+	require.NoError(t, err)
+	assert.NotContains(t, string(expected), `"character_book"`)
+
+	tests := []struct {
+		name     string
+		jsonData string
+	}{
+		{name: "empty object", jsonData: `{"name":"Test","character_book":{}}`},
+		{name: "null", jsonData: `{"name":"Test","character_book":null}`},
+		{name: "settings only", jsonData: `{"name":"Test","character_book":{"scan_depth":5,"token_budget":100,"entries":[]}}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var content Content
+			require.NoError(t, sonicx.Config.UnmarshalFromString(tt.jsonData, &content))
+			assert.Nil(t, content.CharacterBook)
+
+			data, err := sonicx.Config.Marshal(&content)
+			require.NoError(t, err)
+			assert.Equal(t, string(expected), string(data))
+		})
+	}
+
+	t.Run("real book", func(t *testing.T) {
+		var content Content
+		jsonData := `{"name":"Test","character_book":{"name":"Book","entries":[{"id":1,"keys":["k"],"content":"c"}]}}`
+		require.NoError(t, sonicx.Config.UnmarshalFromString(jsonData, &content))
+		require.NotNil(t, content.CharacterBook)
+		require.Len(t, content.CharacterBook.Entries, 1)
+
+		first, err := sonicx.Config.Marshal(&content)
+		require.NoError(t, err)
+		assert.Contains(t, string(first), `"character_book":{`)
+
+		var roundTrip Content
+		require.NoError(t, sonicx.Config.UnmarshalFromString(stringsx.FromBytes(first), &roundTrip))
+		second, err := sonicx.Config.Marshal(&roundTrip)
+		require.NoError(t, err)
+		assert.Equal(t, string(first), string(second))
+	})
+
+	t.Run("marshal never emits an empty book", func(t *testing.T) {
+		content := Content{Name: "Test", CharacterBook: DefaultBook()}
+		data, err := sonicx.Config.Marshal(&content)
+		require.NoError(t, err)
+		assert.Equal(t, string(expected), string(data))
+		// The book is restored after marshaling
+		assert.NotNil(t, content.CharacterBook)
+	})
+
+	t.Run("preserve empty book", func(t *testing.T) {
+		opts := DecodeOptions{PreserveEmptyBook: true}
+		sheet, err := FromBytesWithOptions([]byte(`{"data":{"name":"Test","character_book":{}}}`), opts)
+		require.NoError(t, err)
+		require.NotNil(t, sheet.CharacterBook)
+
+		data, err := sheet.ToBytes()
+		require.NoError(t, err)
+		assert.Contains(t, string(data), `"character_book":{`)
+
+		// Clones keep the empty book too
+		data, err = sheet.Content.Clone().MarshalJSON()
+		require.NoError(t, err)
+		assert.Contains(t, string(data), `"character_book":{`)
+
+		nullSheet, err := FromBytesWithOptions([]byte(`{"data":{"name":"Test","character_book":null}}`), opts)
+		require.NoError(t, err)
+		assert.Nil(t, nullSheet.CharacterBook)
+
+		// The default decoding drops the empty book
+		defaultSheet, err := FromBytes([]byte(`{"data":{"name":"Test","character_book":{}}}`))
+		require.NoError(t, err)
+		assert.Nil(t, defaultSheet.CharacterBook)
+	})
+
+	t.Run("parse report", func(t *testing.T) {
+		emptyNote := DecodeNote{Kind: NoteEmptyBookDropped, Message: "the empty lorebook (character_book) was decoded as absent"}
+		tests := []struct {
+			name     string
+			jsonData string
+			notes    []DecodeNote
+		}{
+			{name: "empty object", jsonData: `{"data":{"name":"Test","character_book":{ }}}`, notes: []DecodeNote{emptyNote}},
+			{name: "null", jsonData: `{"data":{"name":"Test","character_book":null}}`},
+			{name: "absent", jsonData: `{"data":{"name":"Test"}}`},
+			{name: "real book", jsonData: `{"data":{"name":"Test","character_book":{"entries":[{"keys":["k"]}]}}}`},
+			{name: "legacy layout", jsonData: `{"name":"Test","character_book":{}}`, notes: []DecodeNote{emptyNote}},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				sheet, err := FromBytes([]byte(tt.jsonData))
+				require.NoError(t, err)
+				assert.Equal(t, tt.notes, sheet.DecodeNotes)
+
+				// The lazy decoding notes the empty object too
+				lazy, err := FromBytesLazy([]byte(tt.jsonData))
+				require.NoError(t, err)
+				assert.Equal(t, tt.notes, lazy.DecodeNotes)
+			})
+		}
+
+		// No note when the empty book is preserved
+		sheet, err := FromBytesWithOptions([]byte(`{"data":{"character_book":{}}}`), DecodeOptions{PreserveEmptyBook: true})
+		require.NoError(t, err)
+		assert.Empty(t, sheet.DecodeNotes)
+	})
+}
+
 func TestContent_NormalizeSymbols_NameAndComment(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -560,6 +696,26 @@ func TestContent_fixUserCharTemplate(t *testing.T) {
 	}
 }
 
+func TestContent_NormalizeSymbols_V3Fields(t *testing.T) {
+	content := &Content{
+		GroupGreetings: property.StringArray{`Group's „hello"`, `Group's «bye»`},
+		CreatorNotesMultilingual: map[string]property.String{
+			"en": `Creator's 「notes」`,
+			"fr": `Notes «du créateur»`,
+		},
+		Source: property.StringArray{"  https://example.com/card  ", "\tid-1\n"},
+	}
+
+	content.NormalizeSymbols()
+
+	assert.Equal(t, property.StringArray{`Group's "hello"`, `Group's "bye"`}, content.GroupGreetings)
+	assert.Equal(t, map[string]property.String{
+		"en": `Creator's "notes"`,
+		"fr": `Notes "du créateur"`,
+	}, content.CreatorNotesMultilingual)
+	assert.Equal(t, property.StringArray{"https://example.com/card", "id-1"}, content.Source)
+}
+
 func TestContent_FixUserCharTemplates(t *testing.T) {
 	tests := []struct {
 		name   string
@@ -577,6 +733,7 @@ func TestContent_FixUserCharTemplates(t *testing.T) {
 				SystemPrompt:            property.String("You are {char}, talking to {user}"),
 				PostHistoryInstructions: property.String("Remember {char} and {user} context"),
 				AlternateGreetings:      property.StringArray{"Hey {user}!", "{char} waves at {{{user}}}"},
+				GroupGreetings:          property.StringArray{"{char} greets the group"},
 				DepthPrompt:             DepthPrompt{Prompt: "{char} depth with {user}", Depth: 4},
 			},
 			expect: &Content{
@@ -588,6 +745,7 @@ func TestContent_FixUserCharTemplates(t *testing.T) {
 				SystemPrompt:            property.String("You are {{char}}, talking to {{user}}"),
 				PostHistoryInstructions: property.String("Remember {{char}} and {{user}} context"),
 				AlternateGreetings:      property.StringArray{"Hey {{user}}!", "{{char}} waves at {{user}}"},
+				GroupGreetings:          property.StringArray{"{{char}} greets the group"},
 				DepthPrompt:             DepthPrompt{Prompt: "{{char}} depth with {{user}}", Depth: 4},
 			},
 		},
@@ -656,8 +814,42 @@ func TestContent_FixUserCharTemplates(t *testing.T) {
 			assert.Equal(t, tt.expect.SystemPrompt, tt.input.SystemPrompt)
 			assert.Equal(t, tt.expect.PostHistoryInstructions, tt.input.PostHistoryInstructions)
 			assert.Equal(t, tt.expect.AlternateGreetings, tt.input.AlternateGreetings)
+			assert.Equal(t, tt.expect.GroupGreetings, tt.input.GroupGreetings)
 			assert.Equal(t, tt.expect.DepthPrompt.Prompt, tt.input.DepthPrompt.Prompt)
 			assert.Equal(t, tt.expect.DepthPrompt.Depth, tt.input.DepthPrompt.Depth)
 		})
 	}
 }
+
+func TestContent_Clone(t *testing.T) {
+	original := &Content{
+		Name:               "Char",
+		AlternateGreetings: property.StringArray{"Hi"},
+		Tags:               property.StringArray{"tag"},
+		Extensions:         map[string]any{"nested": map[string]any{"list": []any{"a"}}},
+		CharacterBook: &Book{
+			Extensions: map[string]any{"book": "value"},
+			Entries: []*BookEntry{
+				{BookEntryCore: BookEntryCore{Keys: property.StringArray{"key"}}, RawExtensions: map[string]any{"raw": "value"}},
+			},
+		},
+	}
+
+	clone := original.Clone()
+	assert.Equal(t, original, clone)
+
+	// Mutating the clone must not affect the original
+	clone.AlternateGreetings[0] = "Changed"
+	clone.Tags = append(clone.Tags, "other")
+	clone.Extensions["nested"].(map[string]any)["list"].([]any)[0] = "b"
+	clone.CharacterBook.Extensions["book"] = "changed"
+	clone.CharacterBook.Entries[0].Keys[0] = "changed"
+	clone.CharacterBook.Entries[0].RawExtensions["raw"] = "changed"
+
+	assert.Equal(t, property.StringArray{"Hi"}, original.AlternateGreetings)
+	assert.Equal(t, property.StringArray{"tag"}, original.Tags)
+	assert.Equal(t, "a", original.Extensions["nested"].(map[string]any)["list"].([]any)[0])
+	assert.Equal(t, "value", original.CharacterBook.Extensions["book"])
+	assert.Equal(t, property.StringArray{"key"}, original.CharacterBook.Entries[0].Keys)
+	assert.Equal(t, "value", original.CharacterBook.Entries[0].RawExtensions["raw"])
+}
@@ -0,0 +1,31 @@
+package character
+
+// DecodeNoteKind kind of a decode note
+type DecodeNoteKind int
+
+const (
+	// NoteNestingTruncated structures nested deeper than the maximum nesting depth were truncated to null
+	// (see DecodeOptions.MaxNestingDepth)
+	NoteNestingTruncated DecodeNoteKind = iota
+	// NoteEmptyBookDropped an empty lorebook (e.g. "character_book": {}) was decoded as absent
+	// (see DecodeOptions.PreserveEmptyBook)
+	NoteEmptyBookDropped
+)
+
+// DecodeNote a change the decoding made to the card, reported in Sheet.DecodeNotes (silent normalizations, e.g. a
+// null lorebook decoded as absent, are not reported)
+type DecodeNote struct {
+	Kind    DecodeNoteKind // What was changed
+	Message string         // Human-readable description
+}
+
+// contentNotes returns the decode notes of the decoded content
+func (c *Content) contentNotes() []DecodeNote {
+	if !c.emptyBookDropped {
+		return nil
+	}
+	return []DecodeNote{{
+		Kind:    NoteEmptyBookDropped,
+		Message: "the empty lorebook (" + CharacterBookField + ") was decoded as absent",
+	}}
+}
@@ -0,0 +1,126 @@
+package character
+
+import (
+	"errors"
+	"fmt"
+)
+
+// DefaultMaxNestingDepth is the default maximum nesting depth of JSON objects/arrays (counted from the decoded document
+// root); deeper structures (which can only occur inside extension maps) are truncated to null once, by the decoding
+// entry points (e.g. FromBytes), before any decoding (see DecodeOptions.MaxNestingDepth and NoteNestingTruncated)
+const DefaultMaxNestingDepth = 64
+
+// ErrNestingTooDeep is returned in strict mode when the JSON is nested deeper than the maximum nesting depth
+var ErrNestingTooDeep = errors.New("JSON nesting too deep")
+
+// truncateDepth returns the JSON data with every object/array nested deeper than maxDepth replaced by null,
+// and whether any truncation happened (the data itself is returned, without copying, if nothing was truncated)
+// The scan is iterative, so any depth is supported
+func truncateDepth(data []byte, maxDepth int) ([]byte, bool) {
+	var result []byte
+	// Start of the data not yet copied to the result
+	copied := 0
+	depth := 0
+	inString := false
+
+	for index := 0; index < len(data); index++ {
+		char := data[index]
+		// Skip the string contents (including escaped quotes)
+		if inString {
+			switch char {
+			case '\\':
+				index++
+			case '"':
+				inString = false
+			}
+			continue
+		}
+
+		switch char {
+		case '"':
+			inString = true
+		case '}', ']':
+			depth--
+		case '{', '[':
+			depth++
+			// Keep the containers within the limit
+			if depth <= maxDepth {
+				continue
+			}
+			// Replace the container with null
+			end := skipContainer(data, index)
+			result = append(result, data[copied:index]...)
+			result = append(result, "null"...)
+			copied = end
+			index = end - 1
+			depth--
+		}
+	}
+
+	// Nothing was truncated
+	if result == nil {
+		return data, false
+	}
+
+	// Copy the remaining data
+	return append(result, data[copied:]...), true
+}
+
+// skipContainer returns the index right after the end of the object/array starting at start (or the data length if unbalanced)
+func skipContainer(data []byte, start int) int {
+	depth := 0
+	inString := false
+	for index := start; index < len(data); index++ {
+		char := data[index]
+		// Skip the string contents (including escaped quotes)
+		if inString {
+			switch char {
+			case '\\':
+				index++
+			case '"':
+				inString = false
+			}
+			continue
+		}
+
+		switch char {
+		case '"':
+			inString = true
+		case '{', '[':
+			depth++
+		case '}', ']':
+			depth--
+			if depth == 0 {
+				return index + 1
+			}
+		}
+	}
+	return len(data)
+}
+
+// maxNestingDepth returns the maximum nesting depth of the decoded sheets (DefaultMaxNestingDepth if not set)
+func (o DecodeOptions) maxNestingDepth() int {
+	if o.MaxNestingDepth <= 0 {
+		return DefaultMaxNestingDepth
+	}
+	return o.MaxNestingDepth
+}
+
+// truncate returns the JSON data truncated to the maximum nesting depth (see truncateDepth), and whether any
+// truncation happened; in strict mode, a truncation is rejected with ErrNestingTooDeep
+func (o DecodeOptions) truncate(data []byte) ([]byte, bool, error) {
+	maxDepth := o.maxNestingDepth()
+	data, truncated := truncateDepth(data, maxDepth)
+	if truncated && o.StrictFields {
+		return nil, false, fmt.Errorf("%w: maximum depth is %d", ErrNestingTooDeep, maxDepth)
+	}
+	return data, truncated, nil
+}
+
+// truncationNote returns the decode note of a JSON truncated to the maximum nesting depth
+func (o DecodeOptions) truncationNote() DecodeNote {
+	return DecodeNote{
+		Kind:    NoteNestingTruncated,
+		Message: fmt.Sprintf("structures nested deeper than %d levels were truncated to null", o.maxNestingDepth()),
+	}
+}
@@ -0,0 +1,188 @@
+package character
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// nestedJSON returns count nested arrays (or objects) around the value
+func nestedJSON(count int, objects bool, value string) string {
+	open, close := "[", "]"
+	if objects {
+		open, close = `{"k":`, "}"
+	}
+	return strings.Repeat(open, count) + value + strings.Repeat(close, count)
+}
+
+// maxDepth returns the maximum nesting depth of a decoded JSON value
+func maxDepth(value any) int {
+	type item struct {
+		value any
+		depth int
+	}
+	deepest := 0
+	stack := []item{{value: value, depth: 0}}
+	for len(stack) > 0 {
+		current := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		deepest = max(deepest, current.depth)
+		switch typedValue := current.value.(type) {
+		case map[string]any:
+			for _, child := range typedValue {
+				stack = append(stack, item{value: child, depth: current.depth + 1})
+			}
+		case []any:
+			for _, child := range typedValue {
+				stack = append(stack, item{value: child, depth: current.depth + 1})
+			}
+		}
+	}
+	return deepest
+}
+
+func TestTruncateDepth(t *testing.T) {
+	tests := []struct {
+		name      string
+		input     string
+		max       int
+		expected  string
+		truncated bool
+	}{
+		{name: "Within limit", input: `{"a":[1,{"b":2}]}`, max: 3, expected: `{"a":[1,{"b":2}]}`, truncated: false},
+		{name: "Object truncated", input: `{"a":[1,{"b":2}]}`, max: 2, expected: `{"a":[1,null]}`, truncated: true},
+		{name: "Array truncated", input: `{"a":[1,[2]],"c":3}`, max: 1, expected: `{"a":null,"c":3}`, truncated: true},
+		{name: "Multiple truncations", input: `[[1],[2],3]`, max: 1, expected: `[null,null,3]`, truncated: true},
+		{name: "Brackets in strings", input: `{"a":"[{\"}]","b":["{"]}`, max: 2, expected: `{"a":"[{\"}]","b":["{"]}`, truncated: false},
+		{name: "Unbalanced", input: `[[[1`, max: 1, expected: `[null`, truncated: true},
+		{name: "Scalar", input: `"text"`, max: 0, expected: `"text"`, truncated: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, truncated := truncateDepth([]byte(tt.input), tt.max)
+			assert.Equal(t, tt.expected, string(result))
+			assert.Equal(t, tt.truncated, truncated)
+		})
+	}
+}
+
+func TestFromBytes_DeepNesting(t *testing.T) {
+	const depth = 100_000
+
+	tests := []struct {
+		name    string
+		objects bool
+	}{
+		{name: "Arrays", objects: false},
+		{name: "Objects", objects: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			deep := nestedJSON(depth, tt.objects, `"bottom"`)
+			input := `{"spec":"chara_card_v3","data":{"name":"Deep","extensions":{"deep":` + deep + `},` +
+				`"character_book":{"extensions":{"deep":` + deep + `},"entries":[{"keys":["k"],"extensions":{"deep":` + deep + `}}]}}}`
+
+			sheet, err := FromBytes([]byte(input))
+			require.NoError(t, err)
+			assert.Equal(t, "Deep", string(sheet.Name))
+			assert.Equal(t, []DecodeNote{DecodeOptions{}.truncationNote()}, sheet.DecodeNotes)
+
+			// Every extension map is truncated within the limit
+			assert.LessOrEqual(t, maxDepth(sheet.Extensions), DefaultMaxNestingDepth)
+			assert.LessOrEqual(t, maxDepth(sheet.CharacterBook.Extensions), DefaultMaxNestingDepth)
+			require.Len(t, sheet.CharacterBook.Entries, 1)
+			assert.LessOrEqual(t, maxDepth(sheet.CharacterBook.Entries[0].RawExtensions), DefaultMaxNestingDepth)
+
+			// Traversals of limit-adjacent structures work
+			clone := sheet.Content.Clone()
+			assert.Equal(t, sheet.Extensions, clone.Extensions)
+			_, err = sheet.ToBytes()
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestDeepNesting_EntryPoints(t *testing.T) {
+	deep := nestedJSON(DefaultMaxNestingDepth*2, false, "1")
+
+	sheetJSON := []byte(`{"spec":"chara_card_v2","data":{"name":"Deep","extensions":{"deep":` + deep + `}}}`)
+	truncated := []DecodeNote{DecodeOptions{}.truncationNote()}
+
+	t.Run("Sheet", func(t *testing.T) {
+		lazy, err := FromBytesLazy(sheetJSON)
+		require.NoError(t, err)
+		lenient, _, err := FromBytesLenient(sheetJSON)
+		require.NoError(t, err)
+		var unmarshaled Sheet
+		require.NoError(t, unmarshaled.UnmarshalJSON(sheetJSON))
+
+		for _, sheet := range []*Sheet{lazy, lenient, &unmarshaled} {
+			assert.LessOrEqual(t, maxDepth(sheet.Extensions), DefaultMaxNestingDepth)
+			assert.Equal(t, truncated, sheet.DecodeNotes)
+		}
+	})
+
+	t.Run("V1 sheet", func(t *testing.T) {
+		sheet, err := FromV1Bytes([]byte(`{"name":"Deep","extensions":{"deep":` + deep + `}}`))
+		require.NoError(t, err)
+		assert.LessOrEqual(t, maxDepth(sheet.Extensions), DefaultMaxNestingDepth)
+		assert.Equal(t, truncated, sheet.DecodeNotes)
+	})
+
+	t.Run("World info", func(t *testing.T) {
+		book, err := BookFromWorldInfo(strings.NewReader(`{"entries":{"0":{"key":["k"],"extensions":{"deep":` + deep + `}}}}`))
+		require.NoError(t, err)
+		require.Len(t, book.Entries, 1)
+		assert.LessOrEqual(t, maxDepth(book.Entries[0].RawExtensions), DefaultMaxNestingDepth)
+	})
+
+	t.Run("Within the limit", func(t *testing.T) {
+		sheet, err := FromBytes([]byte(`{"data":{"name":"Shallow","extensions":{"a":[1]}}}`))
+		require.NoError(t, err)
+		assert.Empty(t, sheet.DecodeNotes)
+	})
+
+	t.Run("Configurable", func(t *testing.T) {
+		input := []byte(`{"data":{"extensions":{"a":{"b":{"c":1}}}}}`)
+		sheet, err := FromBytesWithOptions(input, DecodeOptions{MaxNestingDepth: 4})
+		require.NoError(t, err)
+		assert.Equal(t, map[string]any{"a": map[string]any{"b": nil}}, sheet.Extensions)
+		require.Len(t, sheet.DecodeNotes, 1)
+		assert.Equal(t, NoteNestingTruncated, sheet.DecodeNotes[0].Kind)
+		assert.Contains(t, sheet.DecodeNotes[0].Message, "deeper than 4 levels")
+
+		// The limit can be raised above the default
+		sheet, err = FromBytesWithOptions(sheetJSON, DecodeOptions{MaxNestingDepth: DefaultMaxNestingDepth * 4})
+		require.NoError(t, err)
+		assert.Equal(t, DefaultMaxNestingDepth*2+1, maxDepth(sheet.Extensions))
+		assert.Empty(t, sheet.DecodeNotes)
+
+		_, err = FromBytesWithOptions(input, DecodeOptions{MaxNestingDepth: 4, StrictFields: true})
+		assert.ErrorIs(t, err, ErrNestingTooDeep)
+	})
+
+	t.Run("Strict mode", func(t *testing.T) {
+		input := []byte(`{"data":{"name":"Deep","extensions":{"deep":` + deep + `}}}`)
+		_, err := FromBytesWithOptions(input, DecodeOptions{StrictFields: true})
+		assert.ErrorIs(t, err, ErrNestingTooDeep)
+
+		sheet, err := FromBytesWithOptions(input, DecodeOptions{})
+		require.NoError(t, err)
+		assert.Equal(t, "Deep", string(sheet.Name))
+	})
+}
+
+func TestCloneValue_Deep(t *testing.T) {
+	// Build a deep structure without recursion
+	var root any = "bottom"
+	for range 100_000 {
+		root = map[string]any{"k": root}
+	}
+
+	clone := cloneValue(root)
+	assert.Equal(t, 100_000, maxDepth(clone))
+}
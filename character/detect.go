@@ -0,0 +1,177 @@
+package character
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/r3dpixel/card-parser/internal/codec"
+	"github.com/r3dpixel/toolkit/stringsx"
+)
+
+// ErrNotACard is returned when the JSON has neither the V2/V3 layout (data object) nor the flat V1 layout (first_mes)
+var ErrNotACard = errors.New("not a chara card")
+
+// errStopScan stops a member scan early
+var errStopScan = errors.New("stop scan")
+
+// v3DataHints keys of the data object that only V3 cards carry
+var v3DataHints = []string{AssetsField, GroupGreetingsField}
+
+// sheetHeader the metadata of a JSON sheet (the data object is kept raw)
+type sheetHeader struct {
+	spec    string                     // Spec as text (strings unquoted, numbers formatted)
+	version string                     // Spec version as text (strings unquoted, numbers formatted)
+	data    string                     // Raw data object
+	flat    bool                       // Has a top-level first_mes (flat V1 layout)
+	named   bool                       // Has a top-level name (flat V1 layout, if there is no data object)
+	unknown map[string]json.RawMessage // Unknown top-level members (if collected)
+}
+
+// DetectRevision detects the revision of the JSON sheet without decoding its content (RevisionV3_1 for 3.1 and newer)
+// The spec and spec_version are trusted first (case-insensitive keys, variants like CHARA_CARD_V3 or 3 included);
+// otherwise a data object carrying assets or group_only_greetings is V3, any other data object is V2, and a flat
+// first_mes is V1; ErrNotACard is returned when neither layout matches
+func DetectRevision(data []byte) (Revision, error) {
+	// Lazily parse the JSON (the member values are skipped, not decoded)
+	root, err := codec.GetFromString(stringsx.FromBytes(data))
+	if err != nil {
+		return 0, err
+	}
+
+	// Read the header, and detect the revision
+	header, err := readSheetHeader(root, false)
+	if errors.Is(err, errNotObject) {
+		return 0, ErrNotACard
+	}
+	if err != nil {
+		return 0, err
+	}
+	return header.revision()
+}
+
+// readSheetHeader reads the metadata of the JSON sheet (exact keys take precedence over case-insensitive matches)
+// If requested, the unknown top-level members are collected (compacted), except the flat content fields (V1 layout)
+func readSheetHeader(root codec.Node, collectUnknown bool) (sheetHeader, error) {
+	var header sheetHeader
+	var spec, version string
+	targets, exact := []*string{&spec, &version, &header.data}, make([]bool, len(sheetFields))
+	err := forEachMember(root, func(key string, raw string, _ codec.Node) error {
+		// Read the metadata
+		known := false
+		for index, name := range sheetFields {
+			if strings.EqualFold(key, name) {
+				known = true
+				if !exact[index] {
+					*targets[index], exact[index] = raw, key == name
+				}
+			}
+		}
+		header.flat = header.flat || strings.EqualFold(key, FirstMessageField)
+		header.named = header.named || strings.EqualFold(key, NameField)
+
+		// Collect the unknown members
+		if !collectUnknown || known || slices.Contains(contentFields, key) {
+			return nil
+		}
+		var compacted bytes.Buffer
+		if err := json.Compact(&compacted, []byte(raw)); err != nil {
+			return err
+		}
+		if header.unknown == nil {
+			header.unknown = make(map[string]json.RawMessage)
+		}
+		header.unknown[key] = compacted.Bytes()
+		return nil
+	})
+	header.spec, header.version = rawStampValue(spec), rawStampValue(version)
+	return header, err
+}
+
+// revision returns the revision declared by the spec and version, or inferred from the layout
+func (h *sheetHeader) revision() (Revision, error) {
+	// Trust the declared spec and version
+	switch {
+	case isV3Spec(h.spec) || isV3Version(h.version):
+		revision, _ := v3Stamp(h.version)
+		return revision, nil
+	case isV2Spec(h.spec) || isV2Version(h.version):
+		return RevisionV2, nil
+	}
+
+	// Infer the revision from the data object
+	if data, err := codec.GetFromString(h.data); err == nil && data.IsObject() {
+		revision := RevisionV2
+		_ = forEachMember(data, func(key string, _ string, _ codec.Node) error {
+			if slices.ContainsFunc(v3DataHints, func(hint string) bool { return strings.EqualFold(key, hint) }) {
+				revision = RevisionV3
+				return errStopScan
+			}
+			return nil
+		})
+		return revision, nil
+	}
+
+	// Infer the flat V1 layout
+	if h.flat {
+		return RevisionV1, nil
+	}
+	return 0, ErrNotACard
+}
+
+// legacy checks if the sheet has the flat V1 layout: no data object, but a top-level name or first_mes
+func (h *sheetHeader) legacy() bool {
+	if !h.flat && !h.named {
+		return false
+	}
+	data, err := codec.GetFromString(h.data)
+	return err != nil || !data.IsObject()
+}
+
+// rawStampValue returns a spec/spec_version raw JSON value as text (strings unquoted, numbers formatted)
+func rawStampValue(raw string) string {
+	var value any
+	if err := codec.UnmarshalFromString(raw, &value); err != nil {
+		return ""
+	}
+	switch typedValue := value.(type) {
+	case string:
+		return typedValue
+	case float64:
+		return strconv.FormatFloat(typedValue, 'f', -1, 64)
+	default:
+		return ""
+	}
+}
+
+// isV3Spec checks if the spec designates a V3 card (contains v3, case-insensitive)
+func isV3Spec(spec string) bool {
+	return strings.Contains(strings.ToLower(spec), "v3")
+}
+
+// isV2Spec checks if the spec designates a V2 card (contains v2, case-insensitive)
+func isV2Spec(spec string) bool {
+	return strings.Contains(strings.ToLower(spec), "v2")
+}
+
+// isV3Version checks if the spec version designates a V3 card (a version of at least 3, with an optional v prefix)
+func isV3Version(version string) bool {
+	number, ok := parseStampVersion(version)
+	return ok && number >= 3
+}
+
+// isV2Version checks if the spec version designates a V2 card (a version of at least 2, with an optional v prefix)
+func isV2Version(version string) bool {
+	number, ok := parseStampVersion(version)
+	return ok && number >= 2
+}
+
+// parseStampVersion parses the spec version number (with an optional v prefix)
+func parseStampVersion(version string) (float64, bool) {
+	version = strings.TrimPrefix(strings.ToLower(strings.TrimSpace(version)), "v")
+	number, err := strconv.ParseFloat(version, 64)
+	return number, err == nil
+}
@@ -0,0 +1,91 @@
+package character
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDetectRevision(t *testing.T) {
+	tests := []struct {
+		name     string
+		jsonData string
+		expected Revision
+		err      error
+	}{
+		{name: "V3 spec", jsonData: `{"spec":"chara_card_v3","spec_version":"3.0","data":{}}`, expected: RevisionV3},
+		{name: "V2 spec", jsonData: `{"spec":"chara_card_v2","spec_version":"2.0","data":{}}`, expected: RevisionV2},
+		{name: "Uppercase spec key", jsonData: `{"SPEC":"CHARA_CARD_V3","data":{}}`, expected: RevisionV3},
+		{name: "Numeric version", jsonData: `{"spec_version":3,"data":{}}`, expected: RevisionV3},
+		{name: "V2 version", jsonData: `{"spec_version":"2","data":{"assets":[]}}`, expected: RevisionV2},
+		{name: "Spec wins over structure", jsonData: `{"spec":"chara_card_v2","data":{"assets":[]}}`, expected: RevisionV2},
+		{name: "Data with assets", jsonData: `{"data":{"name":"Alice","assets":[]}}`, expected: RevisionV3},
+		{name: "Data with group greetings", jsonData: `{"spec":"tavern_card","data":{"group_only_greetings":[]}}`, expected: RevisionV3},
+		{name: "Data without V3 fields", jsonData: `{"data":{"name":"Alice","first_mes":"Hi"}}`, expected: RevisionV2},
+		{name: "Flat layout", jsonData: `{"name":"Alice","first_mes":"Hi"}`, expected: RevisionV1},
+		{name: "Flat layout with data string", jsonData: `{"first_mes":"Hi","data":"none"}`, expected: RevisionV1},
+		{name: "Unrelated object", jsonData: `{"name":"Alice"}`, err: ErrNotACard},
+		{name: "Array", jsonData: `[{"spec":"chara_card_v3"}]`, err: ErrNotACard},
+		{name: "String", jsonData: `"chara_card_v3"`, err: ErrNotACard},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			revision, err := DetectRevision([]byte(tt.jsonData))
+			if tt.err != nil {
+				assert.ErrorIs(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, revision)
+		})
+	}
+
+	t.Run("Invalid JSON", func(t *testing.T) {
+		_, err := DetectRevision([]byte(`{"spec":`))
+		assert.Error(t, err)
+		assert.NotErrorIs(t, err, ErrNotACard)
+	})
+}
+
+func TestDetectRevision_MatchesSheet(t *testing.T) {
+	inputs := []string{
+		`{"spec":"chara_card_v3","spec_version":"3.0","data":{"name":"Alice"}}`,
+		`{"spec":"chara_card_v2","spec_version":"2.0","data":{"name":"Alice"}}`,
+		`{"Spec":"CHARA_CARD_V3","data":{"name":"Alice"}}`,
+		`{"data":{"name":"Alice","assets":[]}}`,
+		`{"data":{"name":"Alice"}}`,
+	}
+
+	for _, input := range inputs {
+		t.Run(input, func(t *testing.T) {
+			revision, err := DetectRevision([]byte(input))
+			require.NoError(t, err)
+			sheet, err := FromBytes([]byte(input))
+			require.NoError(t, err)
+			assert.Equal(t, revision, sheet.Revision)
+		})
+	}
+}
+
+func BenchmarkDetectRevision(b *testing.B) {
+	data := []byte(`{"data":{"name":"Alice","first_mes":"Hi","character_book":{"entries":` + string(benchmarkBook()) +
+		`},"assets":[]},"spec":"chara_card_v3","spec_version":"3.0"}`)
+
+	b.Run("DetectRevision", func(b *testing.B) {
+		b.SetBytes(int64(len(data)))
+		b.ReportAllocs()
+		for b.Loop() {
+			_, _ = DetectRevision(data)
+		}
+	})
+
+	b.Run("FromBytes", func(b *testing.B) {
+		b.SetBytes(int64(len(data)))
+		b.ReportAllocs()
+		for b.Loop() {
+			_, _ = FromBytes(data)
+		}
+	})
+}
@@ -0,0 +1,156 @@
+package character
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+
+	gcmp "github.com/google/go-cmp/cmp"
+	"github.com/r3dpixel/card-parser/internal/codec"
+	"github.com/r3dpixel/card-parser/property"
+)
+
+// diffFieldNames JSON path names of the struct fields without a JSON name (keyed by type and field name)
+// An empty name skips the field in the path (the field value is reported as the value of its parent)
+var diffFieldNames = map[string]string{
+	"Sheet.Spec":              "spec",
+	"Sheet.Version":           "spec_version",
+	"Sheet.Revision":          "revision",
+	"Sheet.Content":           "data",
+	"Content.DepthPrompt":     ExtensionsField + "." + DepthPromptKey,
+	"DepthPrompt.Prompt":      DepthPromptPromptKey,
+	"DepthPrompt.Depth":       DepthPromptDepthKey,
+	"Content.Risu":            ExtensionsField + "." + RisuKey,
+	"Content.RegexScripts":    ExtensionsField + "." + RegexScriptsKey,
+	"BookEntry.RawExtensions": ExtensionsField,
+	"Book.Settings":           ExtensionsField,
+}
+
+// FieldDiff a field that differs between two sheets
+type FieldDiff struct {
+	Path string `json:"path"` // JSON path of the field (e.g. data.character_book.entries[3].content)
+	Old  string `json:"old"`  // Value in the original sheet (empty if missing)
+	New  string `json:"new"`  // Value in the other sheet (empty if missing)
+}
+
+// diffReporter collects the differences reported by cmp
+type diffReporter struct {
+	path  gcmp.Path
+	diffs []FieldDiff
+}
+
+// Diff returns the fields that differ between the two sheets, using the same comparison as DeepEquals
+// (empty and nil collections are equal, and the order of string and number arrays is ignored)
+// Returns an empty slice exactly when DeepEquals is true
+func (s *Sheet) Diff(other *Sheet) []FieldDiff {
+	materializeBooks(s, other)
+	reporter := &diffReporter{diffs: []FieldDiff{}}
+	gcmp.Equal(s, other, append(cmpOptions, gcmp.Reporter(reporter))...)
+	return reporter.diffs
+}
+
+// PushStep enters a step of the compared path
+func (r *diffReporter) PushStep(step gcmp.PathStep) {
+	r.path = append(r.path, step)
+}
+
+// Report records the compared values if they are not equal
+func (r *diffReporter) Report(result gcmp.Result) {
+	if result.Equal() {
+		return
+	}
+	vx, vy := r.path.Last().Values()
+	r.diffs = append(r.diffs, FieldDiff{
+		Path: diffPath(r.path),
+		Old:  diffValue(vx),
+		New:  diffValue(vy),
+	})
+}
+
+// PopStep leaves the last step of the compared path
+func (r *diffReporter) PopStep() {
+	r.path = r.path[:len(r.path)-1]
+}
+
+// diffPath converts a cmp path to a JSON path
+func diffPath(path gcmp.Path) string {
+	var builder strings.Builder
+	for index, step := range path {
+		switch typedStep := step.(type) {
+		case gcmp.StructField:
+			name := diffFieldName(path[index-1].Type(), typedStep)
+			if name == "" {
+				continue
+			}
+			if builder.Len() > 0 {
+				builder.WriteByte('.')
+			}
+			builder.WriteString(name)
+		case gcmp.SliceIndex:
+			// Elements missing on one side are indexed on the other side
+			key, otherKey := typedStep.SplitKeys()
+			if key < 0 {
+				key = otherKey
+			}
+			builder.WriteString("[" + strconv.Itoa(key) + "]")
+		case gcmp.MapIndex:
+			builder.WriteString("." + diffValue(typedStep.Key()))
+		}
+	}
+	return builder.String()
+}
+
+// diffFieldName returns the JSON path name of a struct field (empty for embedded structs and skipped fields)
+func diffFieldName(parent reflect.Type, step gcmp.StructField) string {
+	return fieldPathName(parent, parent.Field(step.Index()))
+}
+
+// fieldPathName returns the JSON path name of a field of the parent struct (empty for embedded structs and skipped fields)
+func fieldPathName(parent reflect.Type, field reflect.StructField) string {
+	// Check the fields without a JSON name
+	if name, ok := diffFieldNames[parent.Name()+"."+field.Name]; ok {
+		return name
+	}
+
+	// Embedded structs are flattened
+	if field.Anonymous {
+		return ""
+	}
+
+	// Use the JSON name of the field
+	if name, _, _ := strings.Cut(field.Tag.Get("json"), ","); name != "" && name != "-" {
+		return name
+	}
+	return strings.ToLower(field.Name)
+}
+
+// diffValue formats a compared value (strings as is, other values as JSON, missing values as an empty string)
+func diffValue(value reflect.Value) string {
+	// Missing values (e.g. added or removed elements)
+	if !value.IsValid() || !value.CanInterface() {
+		return ""
+	}
+
+	// Unwrap interfaces (e.g. extension values), and dereference pointers to scalars (e.g. union values)
+	if value.Kind() == reflect.Interface && !value.IsNil() {
+		value = value.Elem()
+	}
+	if value.Kind() == reflect.Pointer && !value.IsNil() && value.Elem().Kind() != reflect.Struct {
+		value = value.Elem()
+	}
+
+	// Strings (and unions) are returned as is
+	if union, ok := value.Interface().(property.Union); ok {
+		return union.String()
+	}
+	if value.Kind() == reflect.String {
+		return value.String()
+	}
+
+	// Other values are encoded as JSON
+	data, err := codec.Marshal(value.Interface())
+	if err != nil {
+		return ""
+	}
+	return string(data)
+}
@@ -0,0 +1,127 @@
+package character
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/r3dpixel/card-parser/property"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSheet_Diff(t *testing.T) {
+	tests := []struct {
+		name     string
+		modify   func(s *Sheet)
+		expected []FieldDiff
+	}{
+		{
+			name:     "Identical",
+			modify:   func(s *Sheet) {},
+			expected: []FieldDiff{},
+		},
+		{
+			name: "Reordered tags and greetings",
+			modify: func(s *Sheet) {
+				slices.Reverse(s.Tags)
+				slices.Reverse(s.AlternateGreetings)
+			},
+			expected: []FieldDiff{},
+		},
+		{
+			name:   "Changed description",
+			modify: func(s *Sheet) { s.Description = "Changed" },
+			expected: []FieldDiff{
+				{Path: "data.description", Old: "A character with every possible field populated for testing.", New: "Changed"},
+			},
+		},
+		{
+			name: "Changed depth prompt",
+			modify: func(s *Sheet) {
+				s.DepthPrompt.Prompt = "Think less."
+				s.DepthPrompt.Depth = 2
+			},
+			expected: []FieldDiff{
+				{Path: "data.extensions.depth_prompt.prompt", Old: "Think deeply about this comprehensive character.", New: "Think less."},
+				{Path: "data.extensions.depth_prompt.depth", Old: "10", New: "2"},
+			},
+		},
+		{
+			name: "Changed entry content",
+			modify: func(s *Sheet) {
+				s.CharacterBook.Entries[1].Content = "Changed"
+			},
+			expected: []FieldDiff{
+				{Path: "data.character_book.entries[1].content", Old: "This is comprehensive test content for the character2.", New: "Changed"},
+			},
+		},
+		{
+			name: "Changed extension keys",
+			modify: func(s *Sheet) {
+				s.Extensions["custom_extension_1"] = "changed"
+				s.CharacterBook.Entries[0].RawExtensions["entry_added"] = "added"
+			},
+			expected: []FieldDiff{
+				{Path: "data.extensions.custom_extension_1", Old: "value1", New: "changed"},
+				{Path: "data.character_book.entries[0].extensions.entry_added", Old: "", New: "added"},
+			},
+		},
+		{
+			name: "Changed entry ID",
+			modify: func(s *Sheet) {
+				id := 7
+				s.CharacterBook.Entries[0].ID = property.Union{IntValue: &id}
+			},
+			expected: []FieldDiff{
+				{Path: "data.character_book.entries[0].id", Old: "1", New: "7"},
+			},
+		},
+		{
+			name: "Stringified entry ID",
+			modify: func(s *Sheet) {
+				s.CharacterBook.Entries[0].ID = property.UnionFromString("1")
+			},
+			expected: []FieldDiff{},
+		},
+		{
+			name: "Changed entry ID to a string",
+			modify: func(s *Sheet) {
+				s.CharacterBook.Entries[1].ID = property.UnionFromString("entry-2")
+			},
+			expected: []FieldDiff{
+				{Path: "data.character_book.entries[1].id", Old: "2", New: "entry-2"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			original, err := FromBytes([]byte(comprehensiveSheetJSON))
+			require.NoError(t, err)
+			modified, err := FromBytes([]byte(comprehensiveSheetJSON))
+			require.NoError(t, err)
+			tt.modify(modified)
+
+			diffs := original.Diff(modified)
+			assert.ElementsMatch(t, tt.expected, diffs)
+			assert.Equal(t, original.DeepEquals(modified), len(diffs) == 0)
+		})
+	}
+
+	t.Run("Added book entry", func(t *testing.T) {
+		original, err := FromBytes([]byte(comprehensiveSheetJSON))
+		require.NoError(t, err)
+		modified, err := FromBytes([]byte(comprehensiveSheetJSON))
+		require.NoError(t, err)
+		entry := DefaultBookEntry()
+		entry.Content = "New entry"
+		modified.CharacterBook.Entries = append(modified.CharacterBook.Entries, entry)
+
+		diffs := original.Diff(modified)
+		require.Len(t, diffs, 1)
+		assert.Equal(t, "data.character_book.entries[2]", diffs[0].Path)
+		assert.Empty(t, diffs[0].Old)
+		assert.Contains(t, diffs[0].New, `"content":"New entry"`)
+		assert.False(t, original.DeepEquals(modified))
+	})
+}
@@ -0,0 +1,58 @@
+package character
+
+import (
+	"encoding/json"
+	"io"
+
+	"github.com/r3dpixel/card-parser/internal/codec"
+)
+
+// EncodeOption option of the sheet encoding (see Sheet.ToBytes, ToJSON and ToFile)
+// Breaking change: the encoding methods took jsonx.Options before; use Pretty instead of jsonx.Options{Indent: ...}
+type EncodeOption func(config *encodeConfig)
+
+// encodeConfig options applied to the encoded sheet
+type encodeConfig struct {
+	codec.EncodeOptions
+	sortKeys bool
+}
+
+// Pretty indents the output with the given indent (e.g. two spaces), nested structures included
+func Pretty(indent string) EncodeOption {
+	return func(config *encodeConfig) { config.Indent = indent }
+}
+
+// EscapeHTML escapes the HTML characters (<, > and &) of the strings as \u003c, \u003e and \u0026, so the output can be
+// embedded in web pages (they are not escaped by default)
+func EscapeHTML(enabled bool) EncodeOption {
+	return func(config *encodeConfig) { config.EscapeHTML = enabled }
+}
+
+// SortKeys sorts the keys of every object (by default, the known fields follow the spec order)
+func SortKeys(enabled bool) EncodeOption {
+	return func(config *encodeConfig) { config.sortKeys = enabled }
+}
+
+// encode writes the JSON representation of the sheet, followed by a newline, with the JSON codec and the options
+func (s *Sheet) encode(w io.Writer, opts []EncodeOption) error {
+	// Collect the options
+	var config encodeConfig
+	for _, opt := range opts {
+		opt(&config)
+	}
+
+	// Sort the keys (numbers are kept as encoded), and write the sorted JSON with the other options
+	if config.sortKeys {
+		data, err := codec.Marshal(s)
+		if err != nil {
+			return err
+		}
+		if data, err = canonicalJSON(data, false); err != nil {
+			return err
+		}
+		return codec.Encode(w, json.RawMessage(data), config.EncodeOptions)
+	}
+
+	// Encode the sheet (the codec encoder applies the options to the nested structures too)
+	return codec.Encode(w, s, config.EncodeOptions)
+}
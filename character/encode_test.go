@@ -0,0 +1,89 @@
+package character
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/r3dpixel/card-parser/property"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// encodeSheet returns a V3 sheet with HTML in the description, and a lorebook
+func encodeSheet() *Sheet {
+	sheet := DefaultSheet(RevisionV3)
+	sheet.Name = "Alice"
+	sheet.Description = "<b>Alice</b> & friends"
+	sheet.Extensions = map[string]any{"zeta": 1, "alpha": json.Number("12345678901234567890")}
+	sheet.CharacterBook = &Book{Name: "Lore", Entries: []*BookEntry{FilledBookEntry("Castle", "A <castle>")}}
+	return sheet
+}
+
+func TestSheet_ToBytes_Options(t *testing.T) {
+	t.Run("Default", func(t *testing.T) {
+		data, err := encodeSheet().ToBytes()
+		require.NoError(t, err)
+		assert.Contains(t, string(data), `"<b>Alice</b> & friends"`)
+		assert.NotContains(t, string(data), "\n")
+	})
+
+	t.Run("Pretty", func(t *testing.T) {
+		data, err := encodeSheet().ToBytes(Pretty("  "))
+		require.NoError(t, err)
+		// The nested lorebook entries are indented too
+		assert.Contains(t, string(data), "\n  \"data\": {\n")
+		assert.Contains(t, string(data), "\n    \"character_book\": {\n")
+		assert.Contains(t, string(data), "\n        {\n          \"id\"")
+		assert.Contains(t, string(data), `"A <castle>"`)
+	})
+
+	t.Run("Escape HTML", func(t *testing.T) {
+		data, err := encodeSheet().ToBytes(EscapeHTML(true))
+		require.NoError(t, err)
+		assert.Contains(t, string(data), `"\u003cb\u003eAlice\u003c/b\u003e \u0026 friends"`)
+		assert.Contains(t, string(data), `"A \u003ccastle\u003e"`)
+		assert.NotContains(t, string(data), "<")
+	})
+
+	t.Run("Sort keys", func(t *testing.T) {
+		data, err := encodeSheet().ToBytes(SortKeys(true), Pretty("\t"))
+		require.NoError(t, err)
+		text := string(data)
+		assert.Less(t, strings.Index(text, `"data"`), strings.Index(text, `"spec"`))
+		assert.Less(t, strings.Index(text, `"alternate_greetings"`), strings.Index(text, `"name"`))
+		assert.Less(t, strings.Index(text, `"alpha"`), strings.Index(text, `"zeta"`))
+		// Large numbers are kept as written
+		assert.Contains(t, text, "12345678901234567890")
+	})
+
+	t.Run("Round trip", func(t *testing.T) {
+		for _, opts := range [][]EncodeOption{{Pretty("  ")}, {EscapeHTML(true)}, {SortKeys(true), Pretty("  "), EscapeHTML(true)}} {
+			data, err := encodeSheet().ToBytes(opts...)
+			require.NoError(t, err)
+			require.True(t, json.Valid(data))
+			decoded, err := FromBytes(data)
+			require.NoError(t, err)
+			assert.Equal(t, property.String("<b>Alice</b> & friends"), decoded.Description)
+			assert.Equal(t, property.String("A <castle>"), decoded.CharacterBook.Entries[0].Content)
+		}
+	})
+}
+
+func TestSheet_ToFile_Options(t *testing.T) {
+	// The file is streamed by the codec encoder (followed by a newline)
+	path := filepath.Join(t.TempDir(), "sheet.json")
+	require.NoError(t, encodeSheet().ToFile(path, Pretty("  "), EscapeHTML(true)))
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	expected, err := encodeSheet().ToBytes(Pretty("  "), EscapeHTML(true))
+	require.NoError(t, err)
+	assert.Equal(t, string(expected)+"\n", string(data))
+
+	// The JSON writer streams the same output
+	var buffer strings.Builder
+	require.NoError(t, encodeSheet().ToJSON(&buffer, Pretty("  "), EscapeHTML(true)))
+	assert.Equal(t, string(data), buffer.String())
+}
@@ -0,0 +1,181 @@
+package character
+
+import (
+	"strings"
+
+	"github.com/r3dpixel/card-parser/property"
+)
+
+// ExampleSeparator separates the example dialogues of mes_example
+const ExampleSeparator = "<START>"
+
+// ExampleRole role of the speaker of an example turn
+type ExampleRole string
+
+// ExampleRole values
+const (
+	ExampleRoleUser   ExampleRole = "user"   // {{user}}: (or <USER>:) turns
+	ExampleRoleChar   ExampleRole = "char"   // {{char}}: (or <BOT>:) turns
+	ExampleRoleSystem ExampleRole = "system" // Narration before the first prefixed turn of a dialogue
+)
+
+// examplePrefixes are the line prefixes starting a new turn in an example dialogue (matched case-insensitively)
+var examplePrefixes = []string{"{{user}}:", "{{char}}:", "<USER>:", "<BOT>:"}
+
+// exampleRoles roles of the example prefixes
+var exampleRoles = map[string]ExampleRole{
+	"{{user}}:": ExampleRoleUser,
+	"{{char}}:": ExampleRoleChar,
+	"<USER>:":   ExampleRoleUser,
+	"<BOT>:":    ExampleRoleChar,
+}
+
+// canonicalPrefixes prefixes of the roles in the canonical format (narration has no prefix)
+var canonicalPrefixes = map[ExampleRole]string{
+	ExampleRoleUser: "{{user}}: ",
+	ExampleRoleChar: "{{char}}: ",
+}
+
+// ExampleTurn a single turn (message) of an example dialogue
+type ExampleTurn struct {
+	Speaker string      // Speaker prefix (e.g. {{user}}:), empty for narration before the first turn
+	Role    ExampleRole // Role of the speaker (system for narration before the first turn)
+	Text    string      // Full turn text, including the speaker prefix and continuation lines
+}
+
+// ExampleDialogue a single example dialogue (block starting with <START>)
+type ExampleDialogue struct {
+	Turns []ExampleTurn
+}
+
+// ParsedExamples structured representation of mes_example
+type ParsedExamples struct {
+	Dialogues []ExampleDialogue
+}
+
+// ParseExamples splits mes_example into dialogues (on <START>) and turns (on speaker prefixes)
+// Windows line endings are normalized, blank blocks and lines between turns are dropped, and lines without a
+// speaker prefix continue the previous turn
+func ParseExamples(examples string) ParsedExamples {
+	parsed := ParsedExamples{}
+	examples = strings.ReplaceAll(examples, "\r\n", "\n")
+	// Split the examples into dialogues
+	for _, block := range strings.Split(examples, ExampleSeparator) {
+		// Skip blank blocks (e.g. before the first <START>, or between consecutive ones)
+		if strings.TrimSpace(block) == "" {
+			continue
+		}
+		// Split the dialogue into turns
+		dialogue := ExampleDialogue{}
+		for _, line := range strings.Split(strings.Trim(block, "\n"), "\n") {
+			// A speaker prefix starts a new turn (as does the first non-blank line)
+			speaker := exampleSpeaker(line)
+			if speaker != "" || len(dialogue.Turns) == 0 {
+				if speaker == "" && strings.TrimSpace(line) == "" {
+					continue
+				}
+				dialogue.Turns = append(dialogue.Turns, ExampleTurn{Speaker: speaker, Role: exampleRole(speaker), Text: line})
+				continue
+			}
+			// Other lines continue the current turn
+			turn := &dialogue.Turns[len(dialogue.Turns)-1]
+			turn.Text += "\n" + line
+		}
+		// Drop the blank lines trailing the turns
+		for index := range dialogue.Turns {
+			dialogue.Turns[index].Text = strings.TrimRight(dialogue.Turns[index].Text, " \t\n")
+		}
+		parsed.Dialogues = append(parsed.Dialogues, dialogue)
+	}
+	// Return the parsed examples
+	return parsed
+}
+
+// String returns the mes_example representation of the parsed examples
+func (p ParsedExamples) String() string {
+	builder := strings.Builder{}
+	for index, dialogue := range p.Dialogues {
+		// Separate the dialogues with a new line
+		if index > 0 {
+			builder.WriteString("\n")
+		}
+		// Write the dialogue separator, followed by the turns
+		builder.WriteString(ExampleSeparator)
+		for _, turn := range dialogue.Turns {
+			builder.WriteString("\n")
+			builder.WriteString(turn.Text)
+		}
+	}
+	return builder.String()
+}
+
+// TurnCount returns the total number of turns across all dialogues
+func (p ParsedExamples) TurnCount() int {
+	count := 0
+	for _, dialogue := range p.Dialogues {
+		count += len(dialogue.Turns)
+	}
+	return count
+}
+
+// Message returns the turn text without the speaker prefix
+func (t ExampleTurn) Message() string {
+	trimmed := strings.TrimLeft(t.Text, " \t")
+	if speaker := exampleSpeaker(trimmed); speaker != "" {
+		return strings.TrimLeft(trimmed[len(speaker):], " \t")
+	}
+	return trimmed
+}
+
+// canonical returns the turn in the canonical format ({{user}}: or {{char}}: prefix, none for narration)
+// Turns without a role take the role of the prefix of their text
+func (t ExampleTurn) canonical() string {
+	role := t.Role
+	if role == "" {
+		role = exampleRole(exampleSpeaker(t.Text))
+	}
+	return canonicalPrefixes[role] + t.Message()
+}
+
+// ParsedExamples returns the example dialogues of mes_example (see ParseExamples), MessageExamples is left untouched
+func (c *Content) ParsedExamples() []ExampleDialogue {
+	return ParseExamples(string(c.MessageExamples)).Dialogues
+}
+
+// SetExamples sets mes_example to the dialogues rendered in the canonical format (every dialogue starts with <START>,
+// followed by one {{user}}: or {{char}}: line per turn, narration turns have no prefix)
+func (c *Content) SetExamples(dialogues []ExampleDialogue) {
+	builder := strings.Builder{}
+	for index, dialogue := range dialogues {
+		// Separate the dialogues with a new line
+		if index > 0 {
+			builder.WriteString("\n")
+		}
+		// Write the dialogue separator, followed by the canonical turns
+		builder.WriteString(ExampleSeparator)
+		for _, turn := range dialogue.Turns {
+			builder.WriteString("\n")
+			builder.WriteString(turn.canonical())
+		}
+	}
+	c.MessageExamples = property.String(builder.String())
+}
+
+// exampleSpeaker returns the speaker prefix of the line (in its canonical case), or empty if the line does not start a turn
+func exampleSpeaker(line string) string {
+	trimmed := strings.TrimSpace(line)
+	for _, prefix := range examplePrefixes {
+		if len(trimmed) >= len(prefix) && strings.EqualFold(trimmed[:len(prefix)], prefix) {
+			return prefix
+		}
+	}
+	return ""
+}
+
+// exampleRole returns the role of the speaker prefix (system for narration)
+func exampleRole(speaker string) ExampleRole {
+	if role, ok := exampleRoles[speaker]; ok {
+		return role
+	}
+	return ExampleRoleSystem
+}
@@ -0,0 +1,174 @@
+package character
+
+import (
+	"testing"
+
+	"github.com/r3dpixel/card-parser/property"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseExamples(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected ParsedExamples
+		output   string
+	}{
+		{
+			name:     "Empty",
+			input:    "",
+			expected: ParsedExamples{},
+			output:   "",
+		},
+		{
+			name:  "Multiple dialogues",
+			input: "<START>\n{{user}}: Hello\n{{char}}: Hi there!\n<START>\n{{user}}: How are you?\n{{char}}: Great!",
+			expected: ParsedExamples{Dialogues: []ExampleDialogue{
+				{Turns: []ExampleTurn{{Speaker: "{{user}}:", Role: ExampleRoleUser, Text: "{{user}}: Hello"}, {Speaker: "{{char}}:", Role: ExampleRoleChar, Text: "{{char}}: Hi there!"}}},
+				{Turns: []ExampleTurn{{Speaker: "{{user}}:", Role: ExampleRoleUser, Text: "{{user}}: How are you?"}, {Speaker: "{{char}}:", Role: ExampleRoleChar, Text: "{{char}}: Great!"}}},
+			}},
+			output: "<START>\n{{user}}: Hello\n{{char}}: Hi there!\n<START>\n{{user}}: How are you?\n{{char}}: Great!",
+		},
+		{
+			name:  "Multiline turns and narration",
+			input: "Intro text\n<START>\nThe room is dark.\n<USER>: Hello\n<BOT>: Hi\n*waves*",
+			expected: ParsedExamples{Dialogues: []ExampleDialogue{
+				{Turns: []ExampleTurn{{Speaker: "", Role: ExampleRoleSystem, Text: "Intro text"}}},
+				{Turns: []ExampleTurn{
+					{Speaker: "", Role: ExampleRoleSystem, Text: "The room is dark."},
+					{Speaker: "<USER>:", Role: ExampleRoleUser, Text: "<USER>: Hello"},
+					{Speaker: "<BOT>:", Role: ExampleRoleChar, Text: "<BOT>: Hi\n*waves*"},
+				}},
+			}},
+			output: "<START>\nIntro text\n<START>\nThe room is dark.\n<USER>: Hello\n<BOT>: Hi\n*waves*",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parsed := ParseExamples(tt.input)
+			assert.Equal(t, tt.expected, parsed)
+			assert.Equal(t, tt.output, parsed.String())
+		})
+	}
+}
+
+func TestParsedExamples_TurnCount(t *testing.T) {
+	parsed := ParseExamples("<START>\n{{user}}: A\n{{char}}: B\n<START>\n{{user}}: C")
+	assert.Equal(t, 3, parsed.TurnCount())
+}
+
+func TestParseExamples_Tolerance(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected []ExampleDialogue
+	}{
+		{
+			name:  "Missing separator",
+			input: "{{user}}: Hello\n{{char}}: Hi",
+			expected: []ExampleDialogue{{Turns: []ExampleTurn{
+				{Speaker: "{{user}}:", Role: ExampleRoleUser, Text: "{{user}}: Hello"},
+				{Speaker: "{{char}}:", Role: ExampleRoleChar, Text: "{{char}}: Hi"},
+			}}},
+		},
+		{
+			name:  "Consecutive separators and blank lines",
+			input: "<START>\n\n<START>\n<START>\n\n{{user}}: Hello\n\n\n{{char}}: Hi\n\n<START>\n  \n{{user}}: Bye\n",
+			expected: []ExampleDialogue{
+				{Turns: []ExampleTurn{
+					{Speaker: "{{user}}:", Role: ExampleRoleUser, Text: "{{user}}: Hello"},
+					{Speaker: "{{char}}:", Role: ExampleRoleChar, Text: "{{char}}: Hi"},
+				}},
+				{Turns: []ExampleTurn{{Speaker: "{{user}}:", Role: ExampleRoleUser, Text: "{{user}}: Bye"}}},
+			},
+		},
+		{
+			name:  "Prefix case",
+			input: "<START>\n{{USER}}: Hello\n{{Char}}: Hi\n<bot>: Hey",
+			expected: []ExampleDialogue{{Turns: []ExampleTurn{
+				{Speaker: "{{user}}:", Role: ExampleRoleUser, Text: "{{USER}}: Hello"},
+				{Speaker: "{{char}}:", Role: ExampleRoleChar, Text: "{{Char}}: Hi"},
+				{Speaker: "<BOT>:", Role: ExampleRoleChar, Text: "<bot>: Hey"},
+			}}},
+		},
+		{
+			name:  "Narration",
+			input: "<START>\n*The tavern is quiet.*\n{{user}}: Hello\n{{char}}: Hi\n*waves*\n\nStill waving.",
+			expected: []ExampleDialogue{{Turns: []ExampleTurn{
+				{Speaker: "", Role: ExampleRoleSystem, Text: "*The tavern is quiet.*"},
+				{Speaker: "{{user}}:", Role: ExampleRoleUser, Text: "{{user}}: Hello"},
+				{Speaker: "{{char}}:", Role: ExampleRoleChar, Text: "{{char}}: Hi\n*waves*\n\nStill waving."},
+			}}},
+		},
+		{
+			name:  "Windows line endings",
+			input: "<START>\r\n{{user}}: Hello\r\n{{char}}: Hi\r\n*waves*\r\n<START>\r\n{{user}}: Bye\r\n",
+			expected: []ExampleDialogue{
+				{Turns: []ExampleTurn{
+					{Speaker: "{{user}}:", Role: ExampleRoleUser, Text: "{{user}}: Hello"},
+					{Speaker: "{{char}}:", Role: ExampleRoleChar, Text: "{{char}}: Hi\n*waves*"},
+				}},
+				{Turns: []ExampleTurn{{Speaker: "{{user}}:", Role: ExampleRoleUser, Text: "{{user}}: Bye"}}},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			content := &Content{MessageExamples: property.String(tt.input)}
+			assert.Equal(t, tt.expected, content.ParsedExamples())
+			// Parsing leaves the field untouched
+			assert.Equal(t, tt.input, string(content.MessageExamples))
+		})
+	}
+}
+
+func TestContent_SetExamples(t *testing.T) {
+	tests := []struct {
+		name      string
+		dialogues []ExampleDialogue
+		expected  string
+	}{
+		{
+			name:      "Empty",
+			dialogues: nil,
+			expected:  "",
+		},
+		{
+			name: "Roles and messages",
+			dialogues: []ExampleDialogue{
+				{Turns: []ExampleTurn{
+					{Role: ExampleRoleSystem, Text: "*The tavern is quiet.*"},
+					{Role: ExampleRoleUser, Text: "Hello"},
+					{Role: ExampleRoleChar, Text: "Hi\n*waves*"},
+				}},
+				{Turns: []ExampleTurn{{Role: ExampleRoleUser, Text: "Bye"}}},
+			},
+			expected: "<START>\n*The tavern is quiet.*\n{{user}}: Hello\n{{char}}: Hi\n*waves*\n<START>\n{{user}}: Bye",
+		},
+		{
+			name: "Non-canonical prefixes",
+			dialogues: []ExampleDialogue{{Turns: []ExampleTurn{
+				{Role: ExampleRoleUser, Text: "<USER>:Hello"},
+				{Text: "{{CHAR}}:  Hi"},
+			}}},
+			expected: "<START>\n{{user}}: Hello\n{{char}}: Hi",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			content := &Content{}
+			content.SetExamples(tt.dialogues)
+			assert.Equal(t, tt.expected, string(content.MessageExamples))
+		})
+	}
+
+	t.Run("Round trip", func(t *testing.T) {
+		content := &Content{MessageExamples: "{{USER}}: Hello\r\n\r\n<bot>: Hi\r\n<START>\r\n<START>\r\n{{user}}: Bye"}
+		content.SetExamples(content.ParsedExamples())
+		assert.Equal(t, "<START>\n{{user}}: Hello\n{{char}}: Hi\n<START>\n{{user}}: Bye", string(content.MessageExamples))
+		assert.Equal(t, content.ParsedExamples(), ParseExamples(string(content.MessageExamples)).Dialogues)
+	})
+}
@@ -0,0 +1,139 @@
+package character
+
+import (
+	"fmt"
+	"maps"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+// MergePolicy defines how MergeExtensions resolves two different values set under the same key
+type MergePolicy int
+
+// MergePolicy values
+const (
+	MergeKeepDst  MergePolicy = iota // The destination value is kept (default)
+	MergeKeepSrc                     // The source value replaces the destination value
+	MergeKeepBoth                    // The destination value is kept, and the source value is added under a suffixed key
+)
+
+// MergeConflictSuffix is appended to the key of the source values kept by MergeKeepBoth (followed by a number if the
+// suffixed key is taken, e.g. world_merged_2)
+const MergeConflictSuffix = "_merged"
+
+// Conflict value set differently in both extension maps merged by MergeExtensions
+type Conflict struct {
+	Path     string // Dot separated path of the conflicting key (e.g. depth_prompt.role)
+	Dst      any    // Destination value
+	Src      any    // Source value
+	AddedKey string // Key the source value was added under (MergeKeepBoth only)
+}
+
+// MergeExtensions deep merges the source extensions into a copy of the destination extensions, and returns it with
+// the conflicts (sorted by path); neither map is modified
+//   - keys missing from the destination are added
+//   - nested maps are merged recursively
+//   - arrays of primitives (strings, numbers, booleans, null) are concatenated, without the duplicate source values
+//   - any other different values (scalars, arrays of objects, type mismatches) are resolved by the policy
+func MergeExtensions(dst map[string]any, src map[string]any, policy MergePolicy) (map[string]any, []Conflict) {
+	var conflicts []Conflict
+	merged := mergeExtensionMaps(cloneMap(dst), src, policy, "", &conflicts)
+	return merged, conflicts
+}
+
+// MergeExtensionsFrom deep merges the extensions of the other content into the content (see MergeExtensions), and
+// returns the conflicts; the other content is not modified
+func (c *Content) MergeExtensionsFrom(other *Content, policy MergePolicy) []Conflict {
+	// If the other content has no extensions, return (NO-OP)
+	if other == nil || len(other.Extensions) == 0 {
+		return nil
+	}
+
+	// Merge the extensions
+	merged, conflicts := MergeExtensions(c.Extensions, other.Extensions, policy)
+	c.Extensions = merged
+	return conflicts
+}
+
+// mergeExtensionMaps merges the source map into the destination map (modified in place, created if nil) and returns it,
+// appending the conflicts (the keys are walked in sorted order, so the conflicts are sorted by path)
+func mergeExtensionMaps(dst map[string]any, src map[string]any, policy MergePolicy, prefix string, conflicts *[]Conflict) map[string]any {
+	if dst == nil && len(src) > 0 {
+		dst = make(map[string]any, len(src))
+	}
+
+	for _, key := range slices.Sorted(maps.Keys(src)) {
+		srcValue := src[key]
+		path := prefix + key
+
+		// Add the missing keys
+		dstValue, exists := dst[key]
+		if !exists {
+			dst[key] = cloneValue(srcValue)
+			continue
+		}
+
+		// Merge the nested maps, and concatenate the arrays of primitives
+		dstMap, dstIsMap := dstValue.(map[string]any)
+		srcMap, srcIsMap := srcValue.(map[string]any)
+		if dstIsMap && srcIsMap {
+			dst[key] = mergeExtensionMaps(dstMap, srcMap, policy, path+".", conflicts)
+			continue
+		}
+		dstArray, dstIsArray := dstValue.([]any)
+		srcArray, srcIsArray := srcValue.([]any)
+		if dstIsArray && srcIsArray && primitiveArray(dstArray) && primitiveArray(srcArray) {
+			for _, value := range srcArray {
+				if !slices.ContainsFunc(dstArray, func(existing any) bool { return reflect.DeepEqual(existing, value) }) {
+					dstArray = append(dstArray, value)
+				}
+			}
+			dst[key] = dstArray
+			continue
+		}
+
+		// Resolve the different values by the policy
+		if reflect.DeepEqual(dstValue, srcValue) {
+			continue
+		}
+		conflict := Conflict{Path: path, Dst: dstValue, Src: srcValue}
+		switch policy {
+		case MergeKeepSrc:
+			dst[key] = cloneValue(srcValue)
+		case MergeKeepBoth:
+			conflict.AddedKey = freeKey(dst, key+MergeConflictSuffix)
+			dst[conflict.AddedKey] = cloneValue(srcValue)
+		}
+		*conflicts = append(*conflicts, conflict)
+	}
+	return dst
+}
+
+// primitiveArray checks if the array holds no objects or arrays
+func primitiveArray(values []any) bool {
+	return !slices.ContainsFunc(values, func(value any) bool {
+		switch value.(type) {
+		case map[string]any, []any:
+			return true
+		}
+		return false
+	})
+}
+
+// freeKey returns the key if it is not set in the map, otherwise the key followed by the first free number (from 2)
+func freeKey(m map[string]any, key string) string {
+	candidate := key
+	for number := 2; ; number++ {
+		if _, taken := m[candidate]; !taken {
+			return candidate
+		}
+		candidate = key + "_" + fmt.Sprint(number)
+	}
+}
+
+// extensionRoot returns the top-level key of a conflict path
+func extensionRoot(path string) string {
+	root, _, _ := strings.Cut(path, ".")
+	return root
+}
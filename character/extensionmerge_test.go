@@ -0,0 +1,116 @@
+package character
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMergeExtensions(t *testing.T) {
+	dst := func() map[string]any {
+		return map[string]any{
+			"talkativeness": "0.5",
+			"fav":           true,
+			"world":         map[string]any{"name": "Kingdom", "tags": []any{"castle", "king"}},
+			"regex_scripts": []any{map[string]any{"id": "a"}},
+			"depth_prompt":  map[string]any{"depth": 4.0, "role": "system"},
+			"mixed":         map[string]any{"x": 1.0},
+		}
+	}
+	src := func() map[string]any {
+		return map[string]any{
+			"talkativeness": "0.9",
+			"fav":           true,
+			"world":         map[string]any{"name": "Forest", "size": 3.0, "tags": []any{"king", "elf", nil}},
+			"regex_scripts": []any{map[string]any{"id": "b"}},
+			"depth_prompt":  map[string]any{"depth": 4.0, "role": "user", "prompt": "Stay in character"},
+			"mixed":         "flat",
+			"new":           []any{1.0},
+		}
+	}
+	// The merged values that do not depend on the policy
+	common := func(merged map[string]any) {
+		assert.Equal(t, true, merged["fav"])
+		assert.Equal(t, 3.0, merged["world"].(map[string]any)["size"])
+		assert.Equal(t, []any{"castle", "king", "elf", nil}, merged["world"].(map[string]any)["tags"])
+		assert.Equal(t, 4.0, merged["depth_prompt"].(map[string]any)["depth"])
+		assert.Equal(t, "Stay in character", merged["depth_prompt"].(map[string]any)["prompt"])
+		assert.Equal(t, []any{1.0}, merged["new"])
+	}
+	conflictPaths := func(conflicts []Conflict) []string {
+		paths := make([]string, 0, len(conflicts))
+		for _, conflict := range conflicts {
+			paths = append(paths, conflict.Path)
+		}
+		return paths
+	}
+	expectedPaths := []string{"depth_prompt.role", "mixed", "regex_scripts", "talkativeness", "world.name"}
+
+	t.Run("Keep dst", func(t *testing.T) {
+		merged, conflicts := MergeExtensions(dst(), src(), MergeKeepDst)
+		common(merged)
+		assert.Equal(t, "0.5", merged["talkativeness"])
+		assert.Equal(t, "Kingdom", merged["world"].(map[string]any)["name"])
+		assert.Equal(t, "system", merged["depth_prompt"].(map[string]any)["role"])
+		assert.Equal(t, map[string]any{"x": 1.0}, merged["mixed"])
+		assert.Equal(t, []any{map[string]any{"id": "a"}}, merged["regex_scripts"])
+		assert.Equal(t, expectedPaths, conflictPaths(conflicts))
+		assert.Equal(t, Conflict{Path: "mixed", Dst: map[string]any{"x": 1.0}, Src: "flat"}, conflicts[1])
+	})
+
+	t.Run("Keep src", func(t *testing.T) {
+		merged, conflicts := MergeExtensions(dst(), src(), MergeKeepSrc)
+		common(merged)
+		assert.Equal(t, "0.9", merged["talkativeness"])
+		assert.Equal(t, "Forest", merged["world"].(map[string]any)["name"])
+		assert.Equal(t, "user", merged["depth_prompt"].(map[string]any)["role"])
+		assert.Equal(t, "flat", merged["mixed"])
+		assert.Equal(t, []any{map[string]any{"id": "b"}}, merged["regex_scripts"])
+		assert.Equal(t, expectedPaths, conflictPaths(conflicts))
+	})
+
+	t.Run("Keep both", func(t *testing.T) {
+		dstMap := dst()
+		dstMap["talkativeness_merged"] = "taken"
+		merged, conflicts := MergeExtensions(dstMap, src(), MergeKeepBoth)
+		common(merged)
+		assert.Equal(t, "0.5", merged["talkativeness"])
+		assert.Equal(t, "taken", merged["talkativeness_merged"])
+		assert.Equal(t, "0.9", merged["talkativeness_merged_2"])
+		assert.Equal(t, "Forest", merged["world"].(map[string]any)["name_merged"])
+		assert.Equal(t, "flat", merged["mixed_merged"])
+		assert.Equal(t, "talkativeness_merged_2", conflicts[3].AddedKey)
+		assert.Equal(t, "name_merged", conflicts[4].AddedKey)
+	})
+
+	t.Run("Inputs untouched", func(t *testing.T) {
+		dstMap, srcMap := dst(), src()
+		merged, _ := MergeExtensions(dstMap, srcMap, MergeKeepSrc)
+		merged["world"].(map[string]any)["name"] = "Changed"
+		merged["new"].([]any)[0] = 2.0
+		assert.Equal(t, dst(), dstMap)
+		assert.Equal(t, src(), srcMap)
+	})
+
+	t.Run("Nil maps", func(t *testing.T) {
+		merged, conflicts := MergeExtensions(nil, map[string]any{"fav": true}, MergeKeepDst)
+		assert.Equal(t, map[string]any{"fav": true}, merged)
+		assert.Empty(t, conflicts)
+
+		merged, conflicts = MergeExtensions(nil, nil, MergeKeepDst)
+		assert.Nil(t, merged)
+		assert.Empty(t, conflicts)
+	})
+}
+
+func TestContent_MergeExtensionsFrom(t *testing.T) {
+	content := &Content{Extensions: map[string]any{"world": map[string]any{"name": "Kingdom"}}}
+	other := &Content{Extensions: map[string]any{"world": map[string]any{"name": "Forest", "size": 3.0}}}
+
+	conflicts := content.MergeExtensionsFrom(other, MergeKeepSrc)
+	assert.Equal(t, map[string]any{"world": map[string]any{"name": "Forest", "size": 3.0}}, content.Extensions)
+	assert.Equal(t, []Conflict{{Path: "world.name", Dst: "Kingdom", Src: "Forest"}}, conflicts)
+	assert.Equal(t, map[string]any{"world": map[string]any{"name": "Forest", "size": 3.0}}, other.Extensions)
+
+	assert.Empty(t, content.MergeExtensionsFrom(nil, MergeKeepDst))
+}
@@ -0,0 +1,186 @@
+package character
+
+import (
+	"cmp"
+	"crypto/sha256"
+	"encoding/hex"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/r3dpixel/card-parser/internal/codec"
+)
+
+// BehaviorVersion is the version of the parse/normalize/canonicalize semantics
+// It MUST be bumped whenever a change alters normalized or canonical outputs (e.g. a new quote character in NormalizeSymbols),
+// so stored fingerprints computed with older semantics can be detected and recomputed
+const BehaviorVersion = 5
+
+// fingerprintAlgorithm is the hash algorithm name embedded in fingerprints
+const fingerprintAlgorithm = "sha256"
+
+// FingerprintVersion returns the behavior version embedded in the fingerprints computed by this library
+func FingerprintVersion() int {
+	return BehaviorVersion
+}
+
+// fingerprintPrefix returns the prefix of fingerprints computed with the current behavior version (e.g. v5:sha256:)
+func fingerprintPrefix() string {
+	return "v" + strconv.Itoa(BehaviorVersion) + ":" + fingerprintAlgorithm + ":"
+}
+
+// CanonicalBytes returns the canonical JSON of the normalized sheet (sorted keys), without modifying the sheet
+func (s *Sheet) CanonicalBytes() ([]byte, error) {
+	// Copy the sheet (round trip), so normalization does not modify the original
+	data, err := s.ToBytes()
+	if err != nil {
+		return nil, err
+	}
+	normalized, err := FromBytes(data)
+	if err != nil {
+		return nil, err
+	}
+
+	// Normalize the copy (the unknown top-level members are not part of the card)
+	normalized.RawTopLevel = nil
+	normalized.NormalizeSymbols()
+	normalized.FixUserCharTemplates()
+	if data, err = normalized.ToBytes(); err != nil {
+		return nil, err
+	}
+
+	// Re-encode with sorted keys
+	return canonicalJSON(data, false)
+}
+
+// Fingerprint returns the versioned hash of the canonical sheet (e.g. v5:sha256:<hex>)
+// Returns an empty string if the sheet cannot be encoded
+func (s *Sheet) Fingerprint() string {
+	// Compute the canonical JSON
+	data, err := s.CanonicalBytes()
+	if err != nil {
+		return ""
+	}
+	// Hash the canonical JSON
+	sum := sha256.Sum256(data)
+	// Return the versioned fingerprint
+	return fingerprintPrefix() + hex.EncodeToString(sum[:])
+}
+
+// Refingerprint recomputes the fingerprint of the sheet and reports whether the old (stored) fingerprint
+// was computed with a different (older) behavior version, in which case it must be replaced
+func (s *Sheet) Refingerprint(old string) (string, bool) {
+	return s.Fingerprint(), !strings.HasPrefix(old, fingerprintPrefix())
+}
+
+// HashOptions options of Sheet.ContentHash (the excluded fields do not affect the hash)
+type HashOptions struct {
+	ExcludeDates        bool // Exclude the creation and modification dates
+	ExcludeExtensions   bool // Exclude the extensions of the content, lorebook and entries (the depth prompt is kept)
+	ExcludeSourceID     bool // Exclude the source ID
+	ExcludeCreatorNotes bool // Exclude the creator notes (including the multilingual ones)
+}
+
+// ContentHash returns the versioned hash of the canonical content (e.g. v5:sha256:<hex>), used to detect duplicate cards
+// Symbols are normalized, string and number arrays are sorted, and empty arrays and objects are dropped,
+// so sheets for which DeepEquals is true have the same hash
+// Returns an empty string if the sheet cannot be encoded
+func (s *Sheet) ContentHash(opts HashOptions) string {
+	// Copy the sheet, so normalization does not modify the original
+	sheet := *s
+	sheet.Content = *s.Content.Clone()
+	sheet.NormalizeSymbols()
+
+	// Drop the excluded fields
+	opts.exclude(&sheet.Content)
+
+	// Encode the sheet as a generic value
+	data, err := codec.Marshal(&sheet)
+	if err != nil {
+		return ""
+	}
+	var generic any
+	if err := codec.Unmarshal(data, &generic); err != nil {
+		return ""
+	}
+
+	// Canonicalize the value (sorted keys)
+	generic, _ = canonicalValue(generic)
+	if data, err = codec.MarshalSorted(generic, ""); err != nil {
+		return ""
+	}
+
+	// Hash the canonical JSON
+	sum := sha256.Sum256(data)
+	// Return the versioned hash
+	return fingerprintPrefix() + hex.EncodeToString(sum[:])
+}
+
+// exclude clears the excluded fields of the content
+func (o HashOptions) exclude(c *Content) {
+	if o.ExcludeDates {
+		c.CreationDate = 0
+		c.ModificationDate = 0
+	}
+	if o.ExcludeSourceID {
+		c.SourceID = ""
+	}
+	if o.ExcludeCreatorNotes {
+		c.CreatorNotes = ""
+		c.CreatorNotesMultilingual = nil
+	}
+	if o.ExcludeExtensions {
+		c.Extensions = nil
+		c.Risu = nil
+		c.RegexScripts = nil
+		if c.CharacterBook != nil {
+			c.CharacterBook.Extensions = nil
+			c.CharacterBook.Settings = DefaultBookExtensions()
+			for _, entry := range c.CharacterBook.Entries {
+				if entry != nil {
+					entry.RawExtensions = nil
+				}
+			}
+		}
+	}
+}
+
+// canonicalValue sorts the string and number arrays, and drops the empty arrays and objects from the objects
+// Returns false if the value is an empty array or object
+func canonicalValue(value any) (any, bool) {
+	switch typedValue := value.(type) {
+	case map[string]any:
+		for key, child := range typedValue {
+			if canonical, ok := canonicalValue(child); ok {
+				typedValue[key] = canonical
+			} else {
+				delete(typedValue, key)
+			}
+		}
+		return typedValue, len(typedValue) > 0
+	case []any:
+		for index, child := range typedValue {
+			typedValue[index], _ = canonicalValue(child)
+		}
+		sortScalars(typedValue)
+		return typedValue, len(typedValue) > 0
+	default:
+		return value, true
+	}
+}
+
+// sortScalars sorts an array made only of strings, or only of numbers (mixed arrays are left untouched)
+func sortScalars(values []any) {
+	strs, nums := true, true
+	for _, value := range values {
+		_, isString := value.(string)
+		_, isNumber := value.(float64)
+		strs, nums = strs && isString, nums && isNumber
+	}
+	switch {
+	case strs:
+		slices.SortFunc(values, func(a, b any) int { return cmp.Compare(a.(string), b.(string)) })
+	case nums:
+		slices.SortFunc(values, func(a, b any) int { return cmp.Compare(a.(float64), b.(float64)) })
+	}
+}
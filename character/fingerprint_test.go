@@ -0,0 +1,209 @@
+package character
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/r3dpixel/card-parser/property"
+	"github.com/r3dpixel/toolkit/stringsx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// behaviorGolden is the hash of the normalization tables for the current BehaviorVersion
+// If this test fails, normalization semantics changed: bump BehaviorVersion and update both values
+const (
+	behaviorGoldenVersion = 5
+	behaviorGolden        = "1f100b6eec4fe4e529d47b600a57aca1d4294c2a53e18fdb7adfd3bcc6b04513"
+)
+
+func TestBehaviorVersion_Golden(t *testing.T) {
+	// Symbols normalized by NormalizeSymbols (and symbols that must be left untouched)
+	symbols := []string{"‘", "‚", "‛", "„", "«", "»", "《", "》", "「", "」", "〈", "〉", "‹", "›", "〝", "〞", "—", "é"}
+	// Template variants fixed by FixUserCharTemplates
+	templates := []string{"{char}", "{{char}", "{char}}", "{{{char}}}", "{user}", "{{user}", "{user}}", "{{{user}}}", "{{char}} {{user}}"}
+
+	// Build the normalization table
+	table := strings.Builder{}
+	for _, symbol := range symbols {
+		table.WriteString(symbol + "\t" + stringsx.NormalizeSymbols(symbol) + "\n")
+	}
+	content := &Content{}
+	for _, template := range templates {
+		table.WriteString(template + "\t" + content.fixUserCharTemplate(template) + "\n")
+	}
+	table.WriteString(charRegex.String() + "\n" + userRegex.String() + "\n")
+
+	// Hash the normalization table
+	sum := sha256.Sum256([]byte(table.String()))
+	assert.Equal(t, behaviorGoldenVersion, BehaviorVersion, "BehaviorVersion changed: update the golden hash")
+	assert.Equal(t, behaviorGolden, hex.EncodeToString(sum[:]), "Normalization semantics changed: bump BehaviorVersion")
+}
+
+func TestSheet_Fingerprint(t *testing.T) {
+	sheet, err := FromBytes([]byte(comprehensiveSheetJSON))
+	require.NoError(t, err)
+
+	t.Run("Format", func(t *testing.T) {
+		fingerprint := sheet.Fingerprint()
+		assert.True(t, strings.HasPrefix(fingerprint, "v5:sha256:"))
+		assert.Len(t, fingerprint, len("v5:sha256:")+64)
+		assert.Equal(t, BehaviorVersion, FingerprintVersion())
+	})
+
+	t.Run("Stable", func(t *testing.T) {
+		copySheet, err := FromBytes([]byte(comprehensiveSheetJSON))
+		require.NoError(t, err)
+		assert.Equal(t, sheet.Fingerprint(), copySheet.Fingerprint())
+		assert.Equal(t, sheet.Fingerprint(), sheet.Fingerprint())
+	})
+
+	t.Run("Normalized", func(t *testing.T) {
+		plain := DefaultSheet(RevisionV3)
+		plain.Description = `{{char}} says "hello"`
+		fancy := DefaultSheet(RevisionV3)
+		fancy.Description = `{char}} says „hello"`
+
+		assert.Equal(t, plain.Fingerprint(), fancy.Fingerprint())
+		assert.Equal(t, property.String(`{char}} says „hello"`), fancy.Description, "Fingerprint must not modify the sheet")
+	})
+
+	t.Run("Content sensitive", func(t *testing.T) {
+		other := DefaultSheet(RevisionV3)
+		other.Name = "Other"
+		assert.NotEqual(t, DefaultSheet(RevisionV3).Fingerprint(), other.Fingerprint())
+	})
+}
+
+func TestSheet_Refingerprint(t *testing.T) {
+	sheet := DefaultSheet(RevisionV2)
+	sheet.Name = "Char"
+	current := sheet.Fingerprint()
+
+	tests := []struct {
+		name    string
+		old     string
+		changed bool
+	}{
+		{name: "Current version", old: current, changed: false},
+		{name: "Older version", old: "v1:sha256:" + strings.Repeat("0", 64), changed: true},
+		{name: "Unversioned", old: strings.Repeat("0", 64), changed: true},
+		{name: "Empty", old: "", changed: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fingerprint, changed := sheet.Refingerprint(tt.old)
+			assert.Equal(t, current, fingerprint)
+			assert.Equal(t, tt.changed, changed)
+		})
+	}
+}
+
+func TestSheet_ContentHash(t *testing.T) {
+	sheet, err := FromBytes([]byte(comprehensiveSheetJSON))
+	require.NoError(t, err)
+	hash := sheet.ContentHash(HashOptions{})
+
+	t.Run("Format", func(t *testing.T) {
+		assert.True(t, strings.HasPrefix(hash, "v5:sha256:"))
+		assert.Len(t, hash, len("v5:sha256:")+64)
+	})
+
+	t.Run("Does not modify the sheet", func(t *testing.T) {
+		fancy := DefaultSheet(RevisionV3)
+		fancy.Description = `says „hello"`
+		plain := DefaultSheet(RevisionV3)
+		plain.Description = `says "hello"`
+
+		assert.Equal(t, plain.ContentHash(HashOptions{}), fancy.ContentHash(HashOptions{}))
+		assert.Equal(t, property.String(`says „hello"`), fancy.Description)
+	})
+
+	tests := []struct {
+		name   string
+		opts   HashOptions
+		modify func(s *Sheet)
+		equal  bool
+	}{
+		{
+			name: "Reordered greetings and tags",
+			modify: func(s *Sheet) {
+				slices.Reverse(s.AlternateGreetings)
+				slices.Reverse(s.Tags)
+				slices.Reverse(s.CharacterBook.Entries[0].Keys)
+			},
+			equal: true,
+		},
+		{
+			name:   "Empty and nil collections",
+			modify: func(s *Sheet) { s.GroupGreetings = property.StringArray{}; s.Assets = []Asset{} },
+			equal:  true,
+		},
+		{
+			name:   "Changed description",
+			modify: func(s *Sheet) { s.Description += "!" },
+			equal:  false,
+		},
+		{
+			name:   "Changed dates",
+			modify: func(s *Sheet) { s.CreationDate++; s.ModificationDate++ },
+			equal:  false,
+		},
+		{
+			name:   "Changed dates excluded",
+			opts:   HashOptions{ExcludeDates: true},
+			modify: func(s *Sheet) { s.CreationDate++; s.ModificationDate++ },
+			equal:  true,
+		},
+		{
+			name: "Changed extensions excluded",
+			opts: HashOptions{ExcludeExtensions: true},
+			modify: func(s *Sheet) {
+				s.Extensions["custom_extension_1"] = "other"
+				s.CharacterBook.Extensions["custom_book_field"] = "other"
+				s.CharacterBook.Entries[0].RawExtensions["entry_custom"] = "other"
+			},
+			equal: true,
+		},
+		{
+			name:   "Depth prompt with extensions excluded",
+			opts:   HashOptions{ExcludeExtensions: true},
+			modify: func(s *Sheet) { s.DepthPrompt.Prompt = "other" },
+			equal:  false,
+		},
+		{
+			name:   "Changed source ID excluded",
+			opts:   HashOptions{ExcludeSourceID: true},
+			modify: func(s *Sheet) { s.SourceID = "other" },
+			equal:  true,
+		},
+		{
+			name: "Changed creator notes excluded",
+			opts: HashOptions{ExcludeCreatorNotes: true},
+			modify: func(s *Sheet) {
+				s.CreatorNotes = "other"
+				s.CreatorNotesMultilingual = map[string]property.String{"fr": "autre"}
+			},
+			equal: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			original, err := FromBytes([]byte(comprehensiveSheetJSON))
+			require.NoError(t, err)
+			modified, err := FromBytes([]byte(comprehensiveSheetJSON))
+			require.NoError(t, err)
+			tt.modify(modified)
+
+			assert.Equal(t, tt.equal, original.ContentHash(tt.opts) == modified.ContentHash(tt.opts))
+			if tt.opts == (HashOptions{}) {
+				assert.Equal(t, original.DeepEquals(modified), tt.equal)
+			}
+		})
+	}
+}
@@ -0,0 +1,88 @@
+package character
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/r3dpixel/card-parser/property"
+	"github.com/r3dpixel/toolkit/stringsx"
+)
+
+// ErrGreetingIndex is returned when an alternate greeting index is out of range
+var ErrGreetingIndex = errors.New("greeting index out of range")
+
+// GreetingOrigin field a greeting comes from
+type GreetingOrigin int
+
+// GreetingOrigin values
+const (
+	FromFirstMessage GreetingOrigin = iota // first_mes
+	FromAlternate                          // alternate_greetings
+	FromGroup                              // group_only_greetings
+)
+
+// Greeting greeting of the content, with its origin (the index is the position in the origin field, 0 for first_mes)
+type Greeting struct {
+	Text   string
+	Origin GreetingOrigin
+	Index  int
+}
+
+// GreetingOptions options of Content.AllGreetings
+type GreetingOptions struct {
+	IncludeGroup bool // Include the group only greetings (after the alternate greetings)
+	SkipBlank    bool // Skip the blank greetings
+	Deduplicate  bool // Drop the greetings equal to a previous one (after normalizing the symbols and the whitespace)
+}
+
+// AllGreetings returns the first message, followed by the alternate greetings (and the group only greetings if enabled)
+// Duplicates are removed keeping the first occurrence, so the first message always wins over its alternate copies
+func (c *Content) AllGreetings(opts GreetingOptions) []Greeting {
+	// Collect the greetings in order
+	greetings := make([]Greeting, 0, 1+len(c.AlternateGreetings)+len(c.GroupGreetings))
+	greetings = append(greetings, Greeting{Text: string(c.FirstMessage), Origin: FromFirstMessage})
+	for index, text := range c.AlternateGreetings {
+		greetings = append(greetings, Greeting{Text: text, Origin: FromAlternate, Index: index})
+	}
+	if opts.IncludeGroup {
+		for index, text := range c.GroupGreetings {
+			greetings = append(greetings, Greeting{Text: text, Origin: FromGroup, Index: index})
+		}
+	}
+
+	// Filter the blank greetings and the duplicates
+	seen := make(map[string]bool, len(greetings))
+	filtered := greetings[:0]
+	for _, greeting := range greetings {
+		if opts.SkipBlank && stringsx.IsBlank(greeting.Text) {
+			continue
+		}
+		if opts.Deduplicate {
+			key := greetingKey(greeting.Text)
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+		}
+		filtered = append(filtered, greeting)
+	}
+
+	// Return the greetings
+	return filtered
+}
+
+// SetPrimaryGreeting swaps the alternate greeting at the index with the first message
+// The old first message takes the place of the alternate greeting, so the other greetings keep their indexes
+func (c *Content) SetPrimaryGreeting(i int) error {
+	if i < 0 || i >= len(c.AlternateGreetings) {
+		return fmt.Errorf("%w: %d (%d alternate greetings)", ErrGreetingIndex, i, len(c.AlternateGreetings))
+	}
+	c.FirstMessage, c.AlternateGreetings[i] = property.String(c.AlternateGreetings[i]), string(c.FirstMessage)
+	return nil
+}
+
+// greetingKey returns the comparison key of a greeting (normalized symbols, collapsed whitespace)
+func greetingKey(text string) string {
+	return strings.Join(strings.Fields(stringsx.NormalizeSymbols(text)), " ")
+}
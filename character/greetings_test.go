@@ -0,0 +1,102 @@
+package character
+
+import (
+	"testing"
+
+	"github.com/r3dpixel/card-parser/property"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestContent_AllGreetings(t *testing.T) {
+	content := &Content{
+		FirstMessage:       "“Hello,” she said.",
+		AlternateGreetings: property.StringArray{"\"Hello,\"  she said.\n", "   ", "Goodbye", "Goodbye"},
+		GroupGreetings:     property.StringArray{"Hello everyone", "Goodbye"},
+	}
+
+	tests := []struct {
+		name     string
+		opts     GreetingOptions
+		expected []Greeting
+	}{
+		{
+			name: "All",
+			opts: GreetingOptions{},
+			expected: []Greeting{
+				{Text: "“Hello,” she said.", Origin: FromFirstMessage},
+				{Text: "\"Hello,\"  she said.\n", Origin: FromAlternate, Index: 0},
+				{Text: "   ", Origin: FromAlternate, Index: 1},
+				{Text: "Goodbye", Origin: FromAlternate, Index: 2},
+				{Text: "Goodbye", Origin: FromAlternate, Index: 3},
+			},
+		},
+		{
+			name: "Skip blank",
+			opts: GreetingOptions{SkipBlank: true},
+			expected: []Greeting{
+				{Text: "“Hello,” she said.", Origin: FromFirstMessage},
+				{Text: "\"Hello,\"  she said.\n", Origin: FromAlternate, Index: 0},
+				{Text: "Goodbye", Origin: FromAlternate, Index: 2},
+				{Text: "Goodbye", Origin: FromAlternate, Index: 3},
+			},
+		},
+		{
+			name: "Deduplicate across first message and alternates",
+			opts: GreetingOptions{SkipBlank: true, Deduplicate: true},
+			expected: []Greeting{
+				{Text: "“Hello,” she said.", Origin: FromFirstMessage},
+				{Text: "Goodbye", Origin: FromAlternate, Index: 2},
+			},
+		},
+		{
+			name: "Include group",
+			opts: GreetingOptions{IncludeGroup: true, SkipBlank: true, Deduplicate: true},
+			expected: []Greeting{
+				{Text: "“Hello,” she said.", Origin: FromFirstMessage},
+				{Text: "Goodbye", Origin: FromAlternate, Index: 2},
+				{Text: "Hello everyone", Origin: FromGroup, Index: 0},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, content.AllGreetings(tt.opts))
+		})
+	}
+
+	t.Run("Blank first message", func(t *testing.T) {
+		content := &Content{AlternateGreetings: property.StringArray{"Hi"}}
+		assert.Equal(t, []Greeting{{Text: "Hi", Origin: FromAlternate}}, content.AllGreetings(GreetingOptions{SkipBlank: true}))
+		assert.Len(t, content.AllGreetings(GreetingOptions{}), 2)
+	})
+}
+
+func TestContent_SetPrimaryGreeting(t *testing.T) {
+	content := &Content{
+		FirstMessage:       "First",
+		AlternateGreetings: property.StringArray{"Second", "Third", "Fourth"},
+	}
+
+	// The chosen greeting becomes the first message, the old one takes its index
+	require.NoError(t, content.SetPrimaryGreeting(1))
+	assert.Equal(t, property.String("Third"), content.FirstMessage)
+	assert.Equal(t, property.StringArray{"Second", "First", "Fourth"}, content.AlternateGreetings)
+
+	// The indexes of the greetings are stable
+	greetings := content.AllGreetings(GreetingOptions{})
+	assert.Equal(t, Greeting{Text: "First", Origin: FromAlternate, Index: 1}, greetings[2])
+	assert.Equal(t, Greeting{Text: "Fourth", Origin: FromAlternate, Index: 2}, greetings[3])
+
+	// Swapping back restores the content
+	require.NoError(t, content.SetPrimaryGreeting(1))
+	assert.Equal(t, property.String("First"), content.FirstMessage)
+	assert.Equal(t, property.StringArray{"Second", "Third", "Fourth"}, content.AlternateGreetings)
+
+	// Out of range
+	for _, index := range []int{-1, 3} {
+		assert.ErrorIs(t, content.SetPrimaryGreeting(index), ErrGreetingIndex)
+	}
+	assert.Equal(t, property.String("First"), content.FirstMessage)
+}
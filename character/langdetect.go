@@ -0,0 +1,191 @@
+package character
+
+import (
+	"cmp"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// DefaultLanguageTagPrefix is the prefix of the language tags added by EnsureLanguageTag (e.g. lang:en)
+const DefaultLanguageTagPrefix = "lang:"
+
+// DefaultMinLanguageConfidence is the default confidence below which Content.DetectLanguage reports the undetermined
+// language (see LanguageOptions)
+const DefaultMinLanguageConfidence = 0.6
+
+// LanguageOptions options of Content.DetectLanguage
+type LanguageOptions struct {
+	MinConfidence float64 // Confidence below which the language is undetermined (DefaultMinLanguageConfidence if zero)
+}
+
+// Thresholds of the language detection
+const (
+	minLanguageLetters = 12   // Texts with fewer letters are undetermined
+	minKanaShare       = 0.05 // Share of kana (among the CJK letters) above which a CJK text is Japanese
+	minStopwordHits    = 2    // Latin texts with fewer stopwords are undetermined
+)
+
+// Markup removed before the language detection (macros, links, HTML tags and markdown symbols)
+var (
+	languageMacroRegex    = regexp.MustCompile(`\{\{[^{}]*}}|(?i)<(?:user|bot|char)>`)
+	languageLinkRegex     = regexp.MustCompile(`!?\[([^\]]*)]\([^)]*\)`)
+	languageURLRegex      = regexp.MustCompile(`(?i)\b(?:https?://|www\.)\S+`)
+	languageHTMLRegex     = regexp.MustCompile(`</?[A-Za-z][^>]*>`)
+	languageMarkdownRegex = regexp.MustCompile("[*_#~`>|]+")
+)
+
+// ukrainianLetters Cyrillic letters used by Ukrainian, but not by Russian
+const ukrainianLetters = "іїєґ"
+
+// latinStopwords most frequent words of the languages written in the Latin script
+var latinStopwords = map[string]map[string]bool{
+	"en": stopwordSet("the and of to a in is you that it he she was for on are with as his her they i my me your be at this have not but what"),
+	"es": stopwordSet("el la los las de que y en un una es por con para no se su al lo del como pero más sus le ya está muy ella él yo"),
+	"de": stopwordSet("der die das und ist nicht ein eine zu den mit sich des auf für dem im sie er ich du auch es wie aber bin hat"),
+	"fr": stopwordSet("le la les de et un une est que en des du pas il elle je tu vous avec pour dans qui sur ne se au ce mais son sa"),
+	"it": stopwordSet("il lo la gli le di che e un una è per non con del della sono ma si mi ti ho nel alla anche"),
+	"pt": stopwordSet("o a os as de que e um uma é não com para do da em no na se mas ele ela eu você seu sua"),
+}
+
+// DetectLanguage detects the language of the content (description, first message and personality), ignoring macros
+// and markup, and returns its ISO 639-1 code with a confidence between 0 and 1
+// The script decides the language of CJK, Korean, Cyrillic (ru, or uk) and other single-language scripts, the most
+// frequent words decide among the Latin languages (en, es, de, fr, it, pt); mixed, tiny or ambiguous texts are
+// undetermined (UndeterminedLanguage, with the confidence below the minimum confidence)
+func (c *Content) DetectLanguage(opts ...LanguageOptions) (string, float64) {
+	minConfidence := DefaultMinLanguageConfidence
+	if len(opts) > 0 && opts[0].MinConfidence > 0 {
+		minConfidence = opts[0].MinConfidence
+	}
+	text := string(c.Description) + "\n" + string(c.FirstMessage) + "\n" + string(c.Personality)
+	return detectLanguage(text, minConfidence)
+}
+
+// LanguageTagOptions options of EnsureLanguageTag
+type LanguageTagOptions struct {
+	Prefix        string  // Prefix of the language tags (DefaultLanguageTagPrefix if empty)
+	MinConfidence float64 // Confidence below which no tag is added (DefaultMinLanguageConfidence if zero)
+}
+
+// EnsureLanguageTag appends the tag of the detected language (e.g. lang:en) to the tags, unless there already is a tag
+// with the prefix (case-insensitive) or the language is undetermined; returns true if the tag was added
+func (c *Content) EnsureLanguageTag(opts ...LanguageTagOptions) bool {
+	prefix := DefaultLanguageTagPrefix
+	var detectOpts LanguageOptions
+	if len(opts) > 0 {
+		prefix = cmp.Or(opts[0].Prefix, prefix)
+		detectOpts.MinConfidence = opts[0].MinConfidence
+	}
+
+	// Keep the existing language tag
+	for _, tag := range c.Tags {
+		if len(tag) >= len(prefix) && strings.EqualFold(tag[:len(prefix)], prefix) {
+			return false
+		}
+	}
+
+	// Add the detected language
+	lang, _ := c.DetectLanguage(detectOpts)
+	if lang == UndeterminedLanguage {
+		return false
+	}
+	c.Tags = append(c.Tags, prefix+lang)
+	return true
+}
+
+// detectLanguage returns the ISO 639-1 code of the language of the text, and the confidence of the detection
+// (the language is undetermined below the minimum confidence)
+func detectLanguage(text string, minConfidence float64) (string, float64) {
+	// Remove the macros and the markup
+	text = languageMacroRegex.ReplaceAllString(text, " ")
+	text = languageLinkRegex.ReplaceAllString(text, "$1")
+	text = languageURLRegex.ReplaceAllString(text, " ")
+	text = languageHTMLRegex.ReplaceAllString(text, " ")
+	text = languageMarkdownRegex.ReplaceAllString(text, " ")
+
+	// Tiny texts are undetermined
+	counts, total := scriptLetters(text)
+	if total < minLanguageLetters {
+		return UndeterminedLanguage, 0
+	}
+
+	// Japanese texts mix kana and Han
+	cjk := counts[hiraganaScript] + counts[katakanaScript] + counts[hanScript]
+	kana := counts[hiraganaScript] + counts[katakanaScript]
+	if cjk > 0 {
+		counts[hiraganaScript], counts[katakanaScript], counts[hanScript] = 0, 0, 0
+		if float64(kana) >= minKanaShare*float64(cjk) {
+			counts[hiraganaScript] = cjk
+		} else {
+			counts[hanScript] = cjk
+		}
+	}
+
+	// Pick the dominant script, its share of the letters bounds the confidence (mixed texts are undetermined)
+	best := 0
+	for index, count := range counts {
+		if count > counts[best] {
+			best = index
+		}
+	}
+	confidence := float64(counts[best]) / float64(total)
+
+	// Tell apart the languages sharing the script
+	lang := languageScripts[best].code
+	switch best {
+	case cyrillicScript:
+		lang = "ru"
+		if strings.ContainsAny(strings.ToLower(text), ukrainianLetters) {
+			lang = "uk"
+		}
+	case latinScript:
+		var margin float64
+		lang, margin = latinLanguage(text)
+		confidence *= margin
+	}
+
+	// Report the undetermined language below the minimum confidence
+	if lang == "" || confidence < minConfidence {
+		return UndeterminedLanguage, confidence
+	}
+	return lang, confidence
+}
+
+// latinLanguage returns the Latin language whose stopwords are the most frequent in the text, and the share of its
+// stopwords among the stopwords of the two most frequent languages (empty with no margin if there are too few)
+func latinLanguage(text string) (string, float64) {
+	// Count the stopwords of each language
+	hits := make(map[string]int, len(latinStopwords))
+	for _, word := range strings.FieldsFunc(strings.ToLower(text), func(r rune) bool { return !unicode.IsLetter(r) }) {
+		for lang, stopwords := range latinStopwords {
+			if stopwords[word] {
+				hits[lang]++
+			}
+		}
+	}
+
+	// Pick the two most frequent languages (ties broken by code, so the detection is stable)
+	best, second := "", ""
+	for lang, count := range hits {
+		switch {
+		case best == "" || count > hits[best] || count == hits[best] && lang < best:
+			best, second = lang, best
+		case second == "" || count > hits[second] || count == hits[second] && lang < second:
+			second = lang
+		}
+	}
+	if hits[best] < minStopwordHits {
+		return "", 0
+	}
+	return best, float64(hits[best]) / float64(hits[best]+hits[second])
+}
+
+// stopwordSet returns the set of the space separated words
+func stopwordSet(words string) map[string]bool {
+	set := make(map[string]bool)
+	for _, word := range strings.Fields(words) {
+		set[word] = true
+	}
+	return set
+}
@@ -0,0 +1,179 @@
+package character
+
+import (
+	"testing"
+
+	"github.com/r3dpixel/card-parser/property"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestContent_DetectLanguage(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  Content
+		expected string
+	}{
+		{
+			name: "English",
+			content: Content{
+				Description:  "{{char}} is a retired knight who runs a small tavern at the edge of the kingdom.",
+				FirstMessage: "*{{char}} wipes the counter and looks up.* Welcome, traveler. What can I get you?",
+			},
+			expected: "en",
+		},
+		{
+			name: "Spanish",
+			content: Content{
+				Description:  "{{char}} es una bruja joven que vive en el bosque con su gato.",
+				FirstMessage: "*Te mira con curiosidad.* ¿Qué haces en mi casa? No recibo muchas visitas por aquí.",
+			},
+			expected: "es",
+		},
+		{
+			name: "German",
+			content: Content{
+				Description:  "{{char}} ist eine Bibliothekarin, die in der alten Stadtbibliothek arbeitet.",
+				FirstMessage: "*Sie schaut von ihrem Buch auf.* Ich habe dich hier noch nie gesehen. Suchst du etwas Bestimmtes?",
+			},
+			expected: "de",
+		},
+		{
+			name: "French",
+			content: Content{
+				Description:  "{{char}} est un détective privé qui travaille dans les rues sombres de Paris.",
+				FirstMessage: "*Il allume une cigarette.* Vous avez un problème, je le vois dans vos yeux. Asseyez-vous et racontez-moi tout.",
+			},
+			expected: "fr",
+		},
+		{
+			name: "Chinese",
+			content: Content{
+				Description:  "{{char}}是一位住在山上的老剑客，性格沉稳，说话不多。",
+				FirstMessage: "你终于来了。我等你很久了，坐下喝杯茶吧。",
+			},
+			expected: "zh",
+		},
+		{
+			name: "Japanese",
+			content: Content{
+				Description:  "{{char}}は図書館で働く静かな女の子です。本を読むのが大好きです。",
+				FirstMessage: "あ、こんにちは。何か探している本はありますか？",
+			},
+			expected: "ja",
+		},
+		{
+			name: "Korean",
+			content: Content{
+				Description:  "{{char}}는 서울에 사는 대학생입니다. 밝고 친절한 성격을 가지고 있습니다.",
+				FirstMessage: "안녕하세요! 오늘 처음 만났네요. 반가워요!",
+			},
+			expected: "ko",
+		},
+		{
+			name: "Russian",
+			content: Content{
+				Description:  "{{char}} — молодой механик, который работает на старой станции.",
+				FirstMessage: "*Он вытирает руки.* Привет. Ты новенький? Давай я покажу тебе станцию.",
+			},
+			expected: "ru",
+		},
+		{
+			name: "Markup and macros do not skew the detection",
+			content: Content{
+				Description:  "**{{char}}** es una **cazadora** de [dragones](https://example.com/the-dragon-hunters-of-the-north) <b>del norte</b>.",
+				FirstMessage: "{{user}}: ¿Quién eres?\n{{char}}: Soy la que protege el valle y a su gente.",
+				Personality:  "<USER> <BOT> {{random::the,a,of}}",
+			},
+			expected: "es",
+		},
+		{
+			name:     "Tiny input",
+			content:  Content{Description: "Hi {{user}}!"},
+			expected: UndeterminedLanguage,
+		},
+		{
+			name: "Mixed scripts",
+			content: Content{
+				Description:  "A quiet girl from the city.",
+				FirstMessage: "她是一个安静的女孩，来自城市。",
+			},
+			expected: UndeterminedLanguage,
+		},
+		{
+			name:     "Empty",
+			content:  Content{},
+			expected: UndeterminedLanguage,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lang, confidence := tt.content.DetectLanguage()
+			assert.Equal(t, tt.expected, lang)
+			if tt.expected == UndeterminedLanguage {
+				assert.Less(t, confidence, DefaultMinLanguageConfidence)
+			} else {
+				assert.GreaterOrEqual(t, confidence, DefaultMinLanguageConfidence)
+				assert.LessOrEqual(t, confidence, 1.0)
+			}
+		})
+	}
+	t.Run("Minimum confidence", func(t *testing.T) {
+		content := Content{Description: "{{char}} is a retired knight who runs a small tavern at the edge of the kingdom."}
+		lang, confidence := content.DetectLanguage(LanguageOptions{MinConfidence: 1.01})
+		assert.Equal(t, UndeterminedLanguage, lang)
+		assert.Positive(t, confidence)
+	})
+}
+
+func TestContent_EnsureLanguageTag(t *testing.T) {
+	english := Content{Description: "{{char}} is a retired knight who runs a small tavern at the edge of the kingdom."}
+
+	tests := []struct {
+		name     string
+		content  Content
+		opts     []LanguageTagOptions
+		added    bool
+		expected property.StringArray
+	}{
+		{
+			name:     "Default prefix",
+			content:  Content{Description: english.Description, Tags: property.StringArray{"fantasy"}},
+			added:    true,
+			expected: property.StringArray{"fantasy", "lang:en"},
+		},
+		{
+			name:     "Custom prefix",
+			content:  english,
+			opts:     []LanguageTagOptions{{Prefix: "language-"}},
+			added:    true,
+			expected: property.StringArray{"language-en"},
+		},
+		{
+			name:     "Existing language tag",
+			content:  Content{Description: english.Description, Tags: property.StringArray{"LANG:fr"}},
+			added:    false,
+			expected: property.StringArray{"LANG:fr"},
+		},
+		{
+			name:     "Undetermined language",
+			content:  Content{Description: "Hi!", Tags: property.StringArray{"short"}},
+			added:    false,
+			expected: property.StringArray{"short"},
+		},
+		{
+			name:     "Below the minimum confidence",
+			content:  english,
+			opts:     []LanguageTagOptions{{MinConfidence: 1.01}},
+			added:    false,
+			expected: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.added, tt.content.EnsureLanguageTag(tt.opts...))
+			assert.Equal(t, tt.expected, tt.content.Tags)
+		})
+	}
+}
@@ -0,0 +1,182 @@
+package character
+
+import (
+	"maps"
+	"slices"
+	"strings"
+	"unicode"
+
+	"github.com/r3dpixel/card-parser/property"
+	"github.com/r3dpixel/toolkit/stringsx"
+)
+
+// UndeterminedLanguage is the language tag of texts whose script cannot be determined
+const UndeterminedLanguage = "und"
+
+// PrimaryLanguage is the language of the plain creator notes field (kept synchronized by SetCreatorNotes)
+const PrimaryLanguage = "en"
+
+// languageScripts maps unicode scripts to language tags (BCP 47), and to the ISO 639-1 code used by
+// Content.DetectLanguage (empty for the scripts whose language is told apart by its words)
+// Scripts shared by many languages map to the undetermined language with a script subtag (e.g. und-Latn)
+var languageScripts = []struct {
+	script *unicode.RangeTable
+	tag    string
+	code   string
+}{
+	{unicode.Hiragana, "ja", "ja"},
+	{unicode.Katakana, "ja", "ja"},
+	{unicode.Hangul, "ko", "ko"},
+	{unicode.Han, "zh", "zh"},
+	{unicode.Thai, "th", "th"},
+	{unicode.Greek, "el", "el"},
+	{unicode.Hebrew, "he", "he"},
+	{unicode.Cyrillic, "und-Cyrl", ""},
+	{unicode.Arabic, "und-Arab", "ar"},
+	{unicode.Devanagari, "und-Deva", "hi"},
+	{unicode.Latin, "und-Latn", ""},
+}
+
+// Indexes of the scripts in languageScripts
+const (
+	hiraganaScript = 0
+	katakanaScript = 1
+	hangulScript   = 2
+	hanScript      = 3
+	cyrillicScript = 7
+	latinScript    = 10
+)
+
+// DetectLanguage returns the language tag (BCP 47) of the text based on its dominant script
+// Kana take precedence over Han (Japanese texts mix both), otherwise the script with the most letters wins
+// See Content.DetectLanguage for the detection of the language (not only the script) of the content
+func DetectLanguage(text string) string {
+	// Count the letters of each script
+	counts, _ := scriptLetters(text)
+
+	// Any kana means Japanese
+	if counts[hiraganaScript]+counts[katakanaScript] > 0 {
+		return "ja"
+	}
+
+	// Pick the dominant script (first declared wins ties)
+	best := -1
+	for index, count := range counts {
+		if count > 0 && (best < 0 || count > counts[best]) {
+			best = index
+		}
+	}
+	if best < 0 {
+		return UndeterminedLanguage
+	}
+	return languageScripts[best].tag
+}
+
+// scriptLetters returns the number of letters of each script of languageScripts, and the total number of letters
+func scriptLetters(text string) ([]int, int) {
+	counts := make([]int, len(languageScripts))
+	total := 0
+	for _, r := range text {
+		if !unicode.IsLetter(r) {
+			continue
+		}
+		total++
+		for index, entry := range languageScripts {
+			if unicode.Is(entry.script, r) {
+				counts[index]++
+				break
+			}
+		}
+	}
+	return counts, total
+}
+
+// Language returns the detected language of the content (based on the description, first message and personality)
+func (c *Content) Language() string {
+	return DetectLanguage(string(c.Description) + "\n" + string(c.FirstMessage) + "\n" + string(c.Personality))
+}
+
+// CreatorNotesFor returns the creator notes in the given language (e.g. pt-BR), falling back to the base language (pt)
+// or one of its regional variants (pt-PT), then to the primary language, then to the plain creator notes field
+// Language tags are matched case-insensitively (with _ and - as equivalent separators), blank notes are skipped
+func (c *Content) CreatorNotesFor(lang string) string {
+	for _, candidate := range []string{lang, PrimaryLanguage} {
+		if notes, ok := c.creatorNotesIn(candidate); ok {
+			return notes
+		}
+	}
+	return string(c.CreatorNotes)
+}
+
+// creatorNotesIn returns the non-blank multilingual creator notes in the language, its base language,
+// or the first regional variant of the base language (in key order)
+func (c *Content) creatorNotesIn(lang string) (string, bool) {
+	// Exact and base language
+	base := baseLanguage(lang)
+	for _, candidate := range []string{lang, base} {
+		if key, ok := c.creatorNotesKey(candidate); ok && !stringsx.IsBlank(string(c.CreatorNotesMultilingual[key])) {
+			return string(c.CreatorNotesMultilingual[key]), true
+		}
+	}
+
+	// Regional variants of the base language
+	for _, key := range slices.Sorted(maps.Keys(c.CreatorNotesMultilingual)) {
+		if base != "" && sameLanguage(baseLanguage(key), base) && !stringsx.IsBlank(string(c.CreatorNotesMultilingual[key])) {
+			return string(c.CreatorNotesMultilingual[key]), true
+		}
+	}
+	return "", false
+}
+
+// SetCreatorNotes sets the creator notes in the given language (an empty text removes them)
+// The existing key matching the language is reused (keeping its case), and the plain creator notes field is
+// synchronized if the language is the primary language
+func (c *Content) SetCreatorNotes(lang string, text string) {
+	// Find the existing key of the language
+	key, ok := c.creatorNotesKey(lang)
+	if !ok {
+		key = lang
+	}
+
+	// Set (or remove) the notes
+	if text == "" {
+		delete(c.CreatorNotesMultilingual, key)
+	} else {
+		if c.CreatorNotesMultilingual == nil {
+			c.CreatorNotesMultilingual = make(map[string]property.String)
+		}
+		c.CreatorNotesMultilingual[key] = property.String(text)
+	}
+
+	// Synchronize the plain creator notes
+	if sameLanguage(lang, PrimaryLanguage) {
+		c.CreatorNotes = property.String(text)
+	}
+}
+
+// creatorNotesKey returns the key of the multilingual creator notes matching the language
+func (c *Content) creatorNotesKey(lang string) (string, bool) {
+	if lang == "" {
+		return "", false
+	}
+	if _, ok := c.CreatorNotesMultilingual[lang]; ok {
+		return lang, true
+	}
+	for key := range c.CreatorNotesMultilingual {
+		if sameLanguage(key, lang) {
+			return key, true
+		}
+	}
+	return "", false
+}
+
+// baseLanguage returns the primary subtag of the language tag (e.g. pt for pt-BR)
+func baseLanguage(lang string) string {
+	base, _, _ := strings.Cut(strings.ReplaceAll(lang, "_", "-"), "-")
+	return base
+}
+
+// sameLanguage checks if the language tags are equal (case-insensitive, with _ and - as equivalent separators)
+func sameLanguage(a string, b string) bool {
+	return strings.EqualFold(strings.ReplaceAll(a, "_", "-"), strings.ReplaceAll(b, "_", "-"))
+}
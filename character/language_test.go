@@ -0,0 +1,116 @@
+package character
+
+import (
+	"testing"
+
+	"github.com/r3dpixel/card-parser/property"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDetectLanguage(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{name: "Empty", input: "", expected: UndeterminedLanguage},
+		{name: "Symbols only", input: "123 !? {{char}}", expected: "und-Latn"},
+		{name: "Digits only", input: "123 !?", expected: UndeterminedLanguage},
+		{name: "Latin", input: "A brave knight", expected: "und-Latn"},
+		{name: "Japanese", input: "彼女は学生です", expected: "ja"},
+		{name: "Chinese", input: "她是一个学生", expected: "zh"},
+		{name: "Korean", input: "그녀는 학생입니다", expected: "ko"},
+		{name: "Cyrillic", input: "Она студентка", expected: "und-Cyrl"},
+		{name: "Greek", input: "Είναι φοιτήτρια", expected: "el"},
+		{name: "Dominant script", input: "{{char}} говорит: привет, мир", expected: "und-Cyrl"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, DetectLanguage(tt.input))
+		})
+	}
+}
+
+func TestContent_Language(t *testing.T) {
+	content := &Content{Name: "Alice", Description: "学生です", FirstMessage: "こんにちは"}
+	assert.Equal(t, "ja", content.Language())
+}
+
+func TestContent_CreatorNotesFor(t *testing.T) {
+	content := &Content{
+		CreatorNotes: "Plain notes",
+		CreatorNotesMultilingual: map[string]property.String{
+			"EN":    "English notes",
+			"pt":    "Notas",
+			"zh-TW": "   ",
+			"fr":    "",
+		},
+	}
+
+	tests := []struct {
+		name     string
+		content  *Content
+		lang     string
+		expected string
+	}{
+		{name: "Exact", content: content, lang: "pt", expected: "Notas"},
+		{name: "Case-insensitive", content: content, lang: "en", expected: "English notes"},
+		{name: "Region subtag", content: content, lang: "pt-BR", expected: "Notas"},
+		{name: "Underscore separator", content: content, lang: "pt_BR", expected: "Notas"},
+		{name: "Regional variant", content: &Content{CreatorNotesMultilingual: map[string]property.String{"pt-PT": "Notas", "pt-BR": "Notas BR"}}, lang: "pt", expected: "Notas BR"},
+		{name: "Missing language", content: content, lang: "de", expected: "English notes"},
+		{name: "Empty notes", content: content, lang: "fr", expected: "English notes"},
+		{name: "Blank notes", content: content, lang: "zh-TW", expected: "English notes"},
+		{name: "Empty language", content: content, lang: "", expected: "English notes"},
+		{name: "Missing map", content: &Content{CreatorNotes: "Plain notes"}, lang: "pt", expected: "Plain notes"},
+		{
+			name:     "Missing primary language",
+			content:  &Content{CreatorNotes: "Plain notes", CreatorNotesMultilingual: map[string]property.String{"ja": "ノート"}},
+			lang:     "de",
+			expected: "Plain notes",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.content.CreatorNotesFor(tt.lang))
+		})
+	}
+}
+
+func TestContent_SetCreatorNotes(t *testing.T) {
+	t.Run("Missing map", func(t *testing.T) {
+		content := &Content{CreatorNotes: "Plain notes"}
+		content.SetCreatorNotes("pt-BR", "Notas")
+		assert.Equal(t, map[string]property.String{"pt-BR": "Notas"}, content.CreatorNotesMultilingual)
+		assert.Equal(t, property.String("Plain notes"), content.CreatorNotes)
+	})
+
+	t.Run("Primary language", func(t *testing.T) {
+		content := &Content{CreatorNotesMultilingual: map[string]property.String{"EN": "Old notes"}}
+		content.SetCreatorNotes("en", "New notes")
+		assert.Equal(t, map[string]property.String{"EN": "New notes"}, content.CreatorNotesMultilingual)
+		assert.Equal(t, property.String("New notes"), content.CreatorNotes)
+	})
+
+	t.Run("Empty text", func(t *testing.T) {
+		content := &Content{CreatorNotes: "Notes", CreatorNotesMultilingual: map[string]property.String{"en": "Notes", "pt": "Notas"}}
+		content.SetCreatorNotes("EN", "")
+		assert.Equal(t, map[string]property.String{"pt": "Notas"}, content.CreatorNotesMultilingual)
+		assert.Empty(t, content.CreatorNotes)
+	})
+
+	t.Run("Round trip", func(t *testing.T) {
+		sheet := DefaultSheet(RevisionV3)
+		sheet.SetCreatorNotes("en", "Notes")
+		sheet.SetCreatorNotes("pt-BR", "Notas")
+		data, err := sheet.ToBytes()
+		require.NoError(t, err)
+		decoded, err := FromBytes(data)
+		require.NoError(t, err)
+		assert.Equal(t, sheet.CreatorNotesMultilingual, decoded.CreatorNotesMultilingual)
+		assert.Equal(t, "Notas", decoded.CreatorNotesFor("pt"))
+	})
+}
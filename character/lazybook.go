@@ -0,0 +1,143 @@
+package character
+
+import (
+	"bytes"
+	"encoding/json"
+	"slices"
+	"sync"
+
+	"github.com/r3dpixel/card-parser/internal/codec"
+	"github.com/r3dpixel/card-parser/property"
+	"github.com/r3dpixel/toolkit/timestamp"
+)
+
+// lazyBook lorebook of a lazily decoded content, kept as raw JSON until decoded by Content.Book
+// The lorebook is decoded once, and shared by all the copies of the content
+type lazyBook struct {
+	once sync.Once
+	mu   sync.Mutex // Guards the resolution of the lorebook into the copies of the content
+	raw  []byte     // Raw JSON of the lorebook (never modified)
+	book *Book      // Decoded lorebook (nil if empty)
+	err  error      // Decoding error of the lorebook
+}
+
+// lazyContent decodes a content without its lorebook (kept as raw JSON, see FromBytesLazy)
+type lazyContent Content
+
+// FromBytesLazy decodes the JSON like FromBytes, except for the lorebook: it is kept as raw JSON, and only decoded
+// on the first call to Content.Book, so listing the top-level fields (e.g. name, tags, creator) does not pay for huge
+// lorebooks; until then CharacterBook is nil, and MarshalJSON writes the raw lorebook back as is (key order, unknown
+// keys and number formats are kept, only the whitespace is compacted)
+// Copies of the content share the pending lorebook; Clone, DeepEquals and Diff are safe to use (see Content.Book)
+func FromBytesLazy(b []byte) (*Sheet, error) {
+	b, truncated := truncateDepth(b, DefaultMaxNestingDepth)
+	sheet := new(Sheet)
+	if err := sheet.unmarshal(b, &sheetDecoder{sheet: sheet, lazy: true, truncated: truncated}); err != nil {
+		return nil, err
+	}
+	return sheet, nil
+}
+
+// UnmarshalJSON unmarshals JSON into the content like Content.UnmarshalJSON, keeping the lorebook as raw JSON
+func (c *lazyContent) UnmarshalJSON(data []byte) error {
+	// Unmarshal the content fields, keeping the lorebook raw
+	wrapper := struct {
+		*contentAlias
+		CharacterBook    json.RawMessage    `json:"character_book"`
+		CreationDate     property.Timestamp `json:"creation_date"`
+		ModificationDate property.Timestamp `json:"modification_date"`
+	}{contentAlias: (*contentAlias)(c), CreationDate: property.Timestamp(c.CreationDate), ModificationDate: property.Timestamp(c.ModificationDate)}
+	if err := codec.Unmarshal(data, &wrapper); err != nil {
+		return err
+	}
+	content := (*Content)(c)
+	content.CreationDate = timestamp.Seconds(wrapper.CreationDate)
+	content.ModificationDate = timestamp.Seconds(wrapper.ModificationDate)
+	content.extractDepthPrompt()
+	content.extractRisuExtensions()
+	content.extractRegexScripts()
+
+	// Keep the raw lorebook (null is treated as absent, and an empty object as absent with a note)
+	content.CharacterBook, content.lazyBook, content.bookResolved = nil, nil, false
+	raw := bytes.TrimSpace(wrapper.CharacterBook)
+	content.emptyBookDropped = isEmptyObject(raw) && !content.keepEmptyBook
+	if len(raw) > 0 && !bytes.Equal(raw, []byte("null")) && !content.emptyBookDropped {
+		content.lazyBook = &lazyBook{raw: slices.Clone(raw)}
+	}
+
+	// Decoding is complete
+	return nil
+}
+
+// isEmptyObject checks if the raw JSON is an empty object (whitespace ignored)
+func isEmptyObject(raw []byte) bool {
+	return len(raw) >= 2 && raw[0] == '{' && raw[len(raw)-1] == '}' && len(bytes.TrimSpace(raw[1:len(raw)-1])) == 0
+}
+
+// Book returns the lorebook, decoding it on the first call if the content was decoded lazily (see FromBytesLazy)
+// The decoding happens once even with concurrent calls (and across the copies of the content, which all resolve to
+// the same lorebook), and sets CharacterBook (an empty lorebook is treated as absent, as with FromBytes); a
+// CharacterBook assigned before the first call replaces the pending lorebook
+// Reading CharacterBook directly while other goroutines call Book is not safe
+func (c *Content) Book() (*Book, error) {
+	lazy := c.lazyBook
+	if lazy == nil {
+		return c.CharacterBook, nil
+	}
+
+	// Decode the lorebook (once for all the copies)
+	lazy.once.Do(func() {
+		book := new(Book)
+		if lazy.err = codec.Unmarshal(lazy.raw, book); lazy.err == nil && (!book.IsEmpty() || c.keepEmptyBook) {
+			lazy.book = book
+		}
+	})
+
+	// Resolve the lorebook into this copy (a lorebook that failed to decode stays pending)
+	lazy.mu.Lock()
+	defer lazy.mu.Unlock()
+	if lazy.err == nil && !c.bookResolved {
+		if c.CharacterBook == nil {
+			c.CharacterBook = lazy.book
+		}
+		c.bookResolved = true
+	}
+	return c.CharacterBook, lazy.err
+}
+
+// pendingBook returns the raw JSON of the lorebook not resolved yet into the content (nil if the lorebook was resolved
+// or replaced, or if the content was not decoded lazily)
+func (c *Content) pendingBook() []byte {
+	if c.lazyBook == nil || c.bookResolved || c.CharacterBook != nil {
+		return nil
+	}
+	return c.lazyBook.raw
+}
+
+// appendPendingBook appends the raw JSON of the pending lorebook (if any) to the marshaled content object
+func (c *Content) appendPendingBook(data []byte) []byte {
+	raw := c.pendingBook()
+	if raw == nil || len(data) == 0 || data[len(data)-1] != '}' {
+		return data
+	}
+	data = data[:len(data)-1]
+	if len(data) > 1 {
+		data = append(data, ',')
+	}
+	data = append(data, `"`+CharacterBookField+`":`...)
+	data = append(data, raw...)
+	return append(data, '}')
+}
+
+// materializeBooks decodes the pending lorebooks of the sheets, and returns false if one fails to decode
+func materializeBooks(sheets ...*Sheet) bool {
+	for _, sheet := range sheets {
+		if sheet == nil {
+			continue
+		}
+		if _, err := sheet.Book(); err != nil {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,253 @@
+package character
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/r3dpixel/card-parser/property"
+	"github.com/r3dpixel/toolkit/timestamp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// lazyBookJSON lorebook with unusual formatting and unknown keys (kept verbatim by the lazy decoding)
+const lazyBookJSON = `{ "name" : "Lore",  "scan_depth": "4", "unknown_key": [1, 2.50],
+		"entries": { "1": {"keys": ["forest"], "content": "A forest", "extensions": {"position": 1}},
+		             "0": {"keys": ["castle"], "content": "A castle"} } }`
+
+// lazySheetJSON returns a V3 sheet JSON with the given lorebook JSON
+func lazySheetJSON(book string) []byte {
+	return []byte(`{"spec": "chara_card_v3", "spec_version": "3.0", "data": {"name": "Lazy", "tags": ["fantasy"],
+		"character_book": ` + book + `, "creator": "someone",
+		"creation_date": "2023-11-14T22:13:20Z", "modification_date": 1700000000123, "extensions": {"depth_prompt": {"prompt": "Stay", "depth": 2}}}}`)
+}
+
+func TestFromBytesLazy(t *testing.T) {
+	data := lazySheetJSON(lazyBookJSON)
+	var compacted bytes.Buffer
+	require.NoError(t, json.Compact(&compacted, []byte(lazyBookJSON)))
+	rawBook := compacted.String()
+	full, err := FromBytes(data)
+	require.NoError(t, err)
+
+	t.Run("Top-level fields without the lorebook", func(t *testing.T) {
+		sheet, err := FromBytesLazy(data)
+		require.NoError(t, err)
+		assert.Equal(t, property.String("Lazy"), sheet.Name)
+		assert.Equal(t, property.StringArray{"fantasy"}, sheet.Tags)
+		assert.Equal(t, property.String("someone"), sheet.Creator)
+		assert.Equal(t, DepthPrompt{Prompt: "Stay", Depth: 2}, sheet.DepthPrompt)
+		assert.Equal(t, full.CreationDate, sheet.CreationDate)
+		assert.Equal(t, timestamp.Seconds(1700000000), sheet.ModificationDate)
+		assert.Equal(t, RevisionV3, sheet.Revision)
+		assert.Nil(t, sheet.CharacterBook)
+	})
+
+	t.Run("Passthrough marshal", func(t *testing.T) {
+		for _, revision := range []Revision{RevisionV3, RevisionV2} {
+			sheet, err := FromBytesLazy(data)
+			require.NoError(t, err)
+			sheet.SetRevision(revision)
+			sheet.Name = "Renamed"
+
+			// The raw lorebook is written back as is (the book is still not decoded)
+			output, err := sheet.ToBytes()
+			require.NoError(t, err)
+			assert.Contains(t, string(output), `"character_book":`+rawBook)
+			assert.Nil(t, sheet.CharacterBook)
+
+			// The written sheet decodes to the same lorebook
+			reparsed, err := FromBytes(output)
+			require.NoError(t, err)
+			assert.Equal(t, property.String("Renamed"), reparsed.Name)
+			assert.Equal(t, full.CharacterBook, reparsed.CharacterBook)
+		}
+	})
+
+	t.Run("Book decodes once", func(t *testing.T) {
+		sheet, err := FromBytesLazy(data)
+		require.NoError(t, err)
+
+		// Concurrent first accesses
+		books := make([]*Book, 8)
+		var wg sync.WaitGroup
+		for index := range books {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				book, err := sheet.Book()
+				assert.NoError(t, err)
+				books[index] = book
+			}()
+		}
+		wg.Wait()
+		for _, book := range books {
+			assert.Same(t, books[0], book)
+		}
+		assert.Same(t, books[0], sheet.CharacterBook)
+		assert.Equal(t, full.CharacterBook, sheet.CharacterBook)
+
+		// The decoded lorebook is marshaled (with its changes)
+		sheet.CharacterBook.Name = "Edited"
+		output, err := sheet.ToBytes()
+		require.NoError(t, err)
+		assert.NotContains(t, string(output), "unknown_key")
+		reparsed, err := FromBytes(output)
+		require.NoError(t, err)
+		assert.Equal(t, property.String("Edited"), reparsed.CharacterBook.Name)
+	})
+
+	t.Run("Copies resolve to the same lorebook", func(t *testing.T) {
+		sheet, err := FromBytesLazy(data)
+		require.NoError(t, err)
+		copied := *sheet
+
+		// Decoding the lorebook of one copy keeps it pending on the other copy
+		book, err := sheet.Book()
+		require.NoError(t, err)
+		require.NotNil(t, book)
+		output, err := copied.ToBytes()
+		require.NoError(t, err)
+		assert.Contains(t, string(output), `"character_book":`+rawBook)
+
+		// The other copy resolves to the decoded lorebook
+		copiedBook, err := copied.Book()
+		require.NoError(t, err)
+		assert.Same(t, book, copiedBook)
+		assert.Same(t, book, copied.CharacterBook)
+
+		// A removed lorebook stays removed
+		sheet.CharacterBook = nil
+		output, err = sheet.ToBytes()
+		require.NoError(t, err)
+		assert.NotContains(t, string(output), CharacterBookField)
+	})
+
+	t.Run("Assigned lorebook replaces the pending lorebook", func(t *testing.T) {
+		sheet, err := FromBytesLazy(data)
+		require.NoError(t, err)
+		sheet.CharacterBook = &Book{Name: "New", Entries: []*BookEntry{FilledBookEntry("key", "content")}}
+		book, err := sheet.Book()
+		require.NoError(t, err)
+		assert.Equal(t, property.String("New"), book.Name)
+		output, err := sheet.ToBytes()
+		require.NoError(t, err)
+		assert.NotContains(t, string(output), "unknown_key")
+	})
+
+	t.Run("DeepEquals, Diff and Clone", func(t *testing.T) {
+		sheet, err := FromBytesLazy(data)
+		require.NoError(t, err)
+		clone := &Sheet{Spec: sheet.Spec, Version: sheet.Version, Revision: sheet.Revision, Content: *sheet.Content.Clone()}
+
+		// The clone keeps the pending lorebook
+		output, err := clone.ToBytes()
+		require.NoError(t, err)
+		assert.Contains(t, string(output), rawBook)
+
+		// Comparisons decode the lorebooks
+		assert.True(t, full.DeepEquals(sheet))
+		assert.NotNil(t, sheet.CharacterBook)
+		assert.Empty(t, clone.Diff(full))
+		assert.NotNil(t, clone.CharacterBook)
+	})
+
+	t.Run("Absent, empty and invalid lorebooks", func(t *testing.T) {
+		for _, book := range []string{"null", "{}", `{"entries": []}`} {
+			sheet, err := FromBytesLazy(lazySheetJSON(book))
+			require.NoError(t, err)
+			decoded, err := sheet.Book()
+			require.NoError(t, err)
+			assert.Nil(t, decoded, book)
+		}
+
+		sheet, err := FromBytesLazy(lazySheetJSON(`[1, 2]`))
+		require.NoError(t, err)
+		_, err = sheet.Book()
+		assert.Error(t, err)
+		assert.False(t, sheet.DeepEquals(sheet))
+
+		// Contents decoded eagerly have no pending lorebook
+		book, err := full.Book()
+		require.NoError(t, err)
+		assert.Same(t, full.CharacterBook, book)
+	})
+
+	t.Run("Patched lorebook", func(t *testing.T) {
+		sheet, err := FromBytesLazy(data)
+		require.NoError(t, err)
+		assert.Empty(t, sheet.ApplyPatch(map[string]any{"data.character_book.name": "Patched"}))
+		assert.Equal(t, property.String("Patched"), sheet.CharacterBook.Name)
+		assert.Len(t, sheet.CharacterBook.Entries, 2)
+	})
+}
+
+// benchmarkBookJSON returns a V3 sheet JSON with a lorebook of the given number of entries
+func benchmarkBookJSON(b *testing.B, entries int) []byte {
+	b.Helper()
+	sheet := DefaultSheet(RevisionV3)
+	sheet.Name = "Listing"
+	sheet.Tags = property.StringArray{"fantasy", "lore"}
+	sheet.CharacterBook = DefaultBook()
+	for index := range entries {
+		entry := FilledBookEntry(fmt.Sprintf("key%d", index), strings.Repeat(fmt.Sprintf("Entry %d content. ", index), 8))
+		entry.ID = property.Union{IntValue: new(int)}
+		*entry.ID.IntValue = index
+		sheet.CharacterBook.Entries = append(sheet.CharacterBook.Entries, entry)
+	}
+	data, err := sheet.ToBytes()
+	require.NoError(b, err)
+	return data
+}
+
+func BenchmarkFromBytesLazy(b *testing.B) {
+	data := benchmarkBookJSON(b, 5000)
+
+	// Listing path: the top-level fields only
+	b.Run("Full listing", func(b *testing.B) {
+		for b.Loop() {
+			sheet, err := FromBytes(data)
+			if err != nil || sheet.Name == "" {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("Lazy listing", func(b *testing.B) {
+		for b.Loop() {
+			sheet, err := FromBytesLazy(data)
+			if err != nil || sheet.Name == "" {
+				b.Fatal(err)
+			}
+		}
+	})
+
+	// Read-modify-write of a top-level field
+	b.Run("Full rename", func(b *testing.B) {
+		for b.Loop() {
+			sheet, err := FromBytes(data)
+			if err != nil {
+				b.Fatal(err)
+			}
+			sheet.Name = "Renamed"
+			if _, err := sheet.ToBytes(); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("Lazy rename", func(b *testing.B) {
+		for b.Loop() {
+			sheet, err := FromBytesLazy(data)
+			if err != nil {
+				b.Fatal(err)
+			}
+			sheet.Name = "Renamed"
+			if _, err := sheet.ToBytes(); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
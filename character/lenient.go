@@ -0,0 +1,176 @@
+package character
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"unicode/utf8"
+
+	"github.com/r3dpixel/toolkit/stringsx"
+)
+
+// RecoveryNote a duplicate key resolved by FromBytesLenient
+type RecoveryNote struct {
+	Path        string `json:"path"`        // JSON path of the duplicated key (e.g. data.first_mes)
+	Occurrences int    `json:"occurrences"` // Number of occurrences of the key
+	Kept        int    `json:"kept"`        // Index of the kept occurrence (0 for the first one)
+	Reason      string `json:"reason"`      // Why the occurrence was kept
+}
+
+// jsonMember a member of a JSON object (key and raw value)
+type jsonMember struct {
+	key   string
+	value json.RawMessage
+}
+
+// FromBytesLenient decodes the JSON from the given input byte slice, resolving the keys duplicated in the data object
+// For string fields, the longest non-blank value is kept (instead of the last one), other fields keep the last value
+// Returns a RecoveryNote for every duplicated key (nil if there is none)
+func FromBytesLenient(b []byte) (*Sheet, []RecoveryNote, error) {
+	// Limit the nesting depth (once, the rebuilt sheet is not truncated again)
+	b, truncated := truncateDepth(b, DefaultMaxNestingDepth)
+
+	// Read the sheet members (fallback to the default decoding if the sheet is not an object)
+	root, err := jsonObjectMembers(b)
+	if err != nil {
+		sheet, err := decodeTruncatedSheet(b, DecodeOptions{}, truncated)
+		return sheet, nil, err
+	}
+
+	// Resolve the duplicated keys of the data object (the last data object is the one decoded)
+	var notes []RecoveryNote
+	for index := len(root) - 1; index >= 0; index-- {
+		if root[index].key != "data" {
+			continue
+		}
+		content, err := jsonObjectMembers(root[index].value)
+		if err != nil {
+			break
+		}
+		content, notes = resolveDuplicateKeys(content, "data")
+		root[index].value = encodeJSONMembers(content)
+		break
+	}
+
+	// Decode the rebuilt sheet
+	sheet, err := decodeTruncatedSheet(encodeJSONMembers(root), DecodeOptions{}, truncated)
+	if err != nil {
+		return nil, nil, err
+	}
+	return sheet, notes, nil
+}
+
+// resolveDuplicateKeys keeps a single occurrence of every key (at the position of the first occurrence)
+func resolveDuplicateKeys(members []jsonMember, path string) ([]jsonMember, []RecoveryNote) {
+	// Group the occurrences by key
+	occurrences := make(map[string][]json.RawMessage, len(members))
+	for _, member := range members {
+		occurrences[member.key] = append(occurrences[member.key], member.value)
+	}
+
+	// Keep a single occurrence of every key
+	var notes []RecoveryNote
+	resolved := make([]jsonMember, 0, len(occurrences))
+	for _, member := range members {
+		values, pending := occurrences[member.key]
+		if !pending {
+			continue
+		}
+		delete(occurrences, member.key)
+
+		// Pick the occurrence to keep
+		kept, reason := len(values)-1, ""
+		if len(values) > 1 {
+			kept, reason = pickDuplicate(values)
+			notes = append(notes, RecoveryNote{
+				Path:        path + "." + member.key,
+				Occurrences: len(values),
+				Kept:        kept,
+				Reason:      reason,
+			})
+		}
+		resolved = append(resolved, jsonMember{key: member.key, value: values[kept]})
+	}
+
+	// Return the resolved members
+	return resolved, notes
+}
+
+// pickDuplicate returns the index of the duplicated value to keep, and the reason
+func pickDuplicate(values []json.RawMessage) (int, string) {
+	// Keep the last value if any of the values is not a string
+	texts := make([]string, len(values))
+	for index, value := range values {
+		if err := json.Unmarshal(value, &texts[index]); err != nil {
+			return len(values) - 1, "not a string field, kept the last value"
+		}
+	}
+
+	// Keep the longest non-blank value (the last one on ties)
+	kept, longest := len(values)-1, -1
+	for index, text := range texts {
+		length := utf8.RuneCountInString(text)
+		if stringsx.IsBlank(text) {
+			length = 0
+		}
+		if length >= longest {
+			kept, longest = index, length
+		}
+	}
+	if longest == 0 {
+		return kept, "every value is blank, kept the last value"
+	}
+	return kept, fmt.Sprintf("kept the longest non-blank value (%d characters)", longest)
+}
+
+// jsonObjectMembers reads the members of a JSON object in order (duplicated keys included)
+func jsonObjectMembers(data []byte) ([]jsonMember, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+
+	// Read the opening brace
+	token, err := decoder.Token()
+	if err != nil {
+		return nil, err
+	}
+	if token != json.Delim('{') {
+		return nil, errNotObject
+	}
+
+	// Read the members
+	var members []jsonMember
+	for decoder.More() {
+		token, err := decoder.Token()
+		if err != nil {
+			return nil, err
+		}
+		key, _ := token.(string)
+		var value json.RawMessage
+		if err := decoder.Decode(&value); err != nil {
+			return nil, err
+		}
+		members = append(members, jsonMember{key: key, value: value})
+	}
+
+	// Read the closing brace
+	if _, err := decoder.Token(); err != nil {
+		return nil, err
+	}
+	return members, nil
+}
+
+// encodeJSONMembers encodes the members as a JSON object (in order)
+func encodeJSONMembers(members []jsonMember) []byte {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for index, member := range members {
+		if index > 0 {
+			buf.WriteByte(',')
+		}
+		key, _ := json.Marshal(member.key)
+		buf.Write(key)
+		buf.WriteByte(':')
+		buf.Write(member.value)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes()
+}
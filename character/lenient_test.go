@@ -0,0 +1,70 @@
+package character
+
+import (
+	"os"
+	"testing"
+
+	"github.com/r3dpixel/card-parser/property"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFromBytesLenient(t *testing.T) {
+	data, err := os.ReadFile("testdata/duplicate_keys.json")
+	require.NoError(t, err)
+
+	t.Run("Default decoding keeps the last value", func(t *testing.T) {
+		sheet, err := FromBytes(data)
+		require.NoError(t, err)
+		assert.Empty(t, sheet.FirstMessage)
+	})
+
+	t.Run("Duplicate keys", func(t *testing.T) {
+		sheet, notes, err := FromBytesLenient(data)
+		require.NoError(t, err)
+
+		assert.Equal(t, RevisionV2, sheet.Revision)
+		assert.Equal(t, property.String("Alice"), sheet.Name)
+		assert.Equal(t, property.String("Hello! I'm Alice, nice to meet you."), sheet.FirstMessage)
+		assert.Equal(t, property.String("A brave knight from the northern kingdoms."), sheet.Description)
+		assert.Equal(t, property.String("Loyal and brave"), sheet.Personality)
+		assert.Equal(t, property.StringArray{"knight", "fantasy"}, sheet.Tags)
+
+		// Notes follow the order of the first occurrences
+		require.Len(t, notes, 4)
+		paths := make([]string, 0, len(notes))
+		for _, note := range notes {
+			paths = append(paths, note.Path)
+			assert.Equal(t, 2, note.Occurrences)
+		}
+		assert.Equal(t, []string{"data.first_mes", "data.description", "data.tags", "data.personality"}, paths)
+		assert.Equal(t, 0, notes[0].Kept)
+		assert.Equal(t, 1, notes[1].Kept)
+		assert.Equal(t, 1, notes[2].Kept)
+		assert.Contains(t, notes[2].Reason, "last value")
+	})
+
+	t.Run("Without duplicates", func(t *testing.T) {
+		sheet, notes, err := FromBytesLenient([]byte(comprehensiveSheetJSON))
+		require.NoError(t, err)
+		assert.Nil(t, notes)
+
+		expected, err := FromBytes([]byte(comprehensiveSheetJSON))
+		require.NoError(t, err)
+		assert.True(t, expected.DeepEquals(sheet))
+	})
+
+	t.Run("Blank values", func(t *testing.T) {
+		sheet, notes, err := FromBytesLenient([]byte(`{"spec": "chara_card_v3", "data": {"scenario": " ", "scenario": ""}}`))
+		require.NoError(t, err)
+		assert.Equal(t, RevisionV3, sheet.Revision)
+		assert.Empty(t, sheet.Scenario)
+		require.Len(t, notes, 1)
+		assert.Equal(t, 1, notes[0].Kept)
+	})
+
+	t.Run("Malformed JSON", func(t *testing.T) {
+		_, _, err := FromBytesLenient([]byte(`{"data": {"name": "Alice",}}`))
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,72 @@
+package character
+
+import (
+	"fmt"
+
+	"github.com/r3dpixel/card-parser/property"
+)
+
+// FieldLimits maximum lengths of the content fields in runes (0 means no limit), see Content.EnforceLimits
+type FieldLimits struct {
+	Description     int
+	Personality     int
+	Scenario        int
+	FirstMessage    int
+	MessageExamples int
+	Greeting        int    // Each alternate and group only greeting
+	EntryContent    int    // Each lorebook entry content
+	Ellipsis        string // Appended to the truncated fields (counted in the limit)
+}
+
+// TruncationNote a field truncated by Content.EnforceLimits
+type TruncationNote struct {
+	Field         string `json:"field"`          // JSON path of the field (e.g. alternate_greetings[1], character_book.entries[3].content)
+	OriginalRunes int    `json:"original_runes"` // Length of the field before the truncation (in runes)
+	Runes         int    `json:"runes"`          // Length of the field after the truncation (in runes)
+}
+
+// EnforceLimits truncates the fields longer than their limit (see property.String.Truncate), in field order
+// Returns a note for every truncated field
+func (c *Content) EnforceLimits(limits FieldLimits) []TruncationNote {
+	var notes []TruncationNote
+	truncate := func(field string, value *property.String, limit int) {
+		if limit <= 0 || value.LenRunes() <= limit {
+			return
+		}
+		note := TruncationNote{Field: field, OriginalRunes: value.LenRunes()}
+		*value = value.Truncate(limit, limits.Ellipsis)
+		note.Runes = value.LenRunes()
+		notes = append(notes, note)
+	}
+
+	// Truncate the text fields
+	truncate(DescriptionField, &c.Description, limits.Description)
+	truncate(PersonalityField, &c.Personality, limits.Personality)
+	truncate(ScenarioField, &c.Scenario, limits.Scenario)
+	truncate(FirstMessageField, &c.FirstMessage, limits.FirstMessage)
+	truncate(MessageExamplesField, &c.MessageExamples, limits.MessageExamples)
+
+	// Truncate the greetings
+	for _, greetings := range []struct {
+		field  string
+		values property.StringArray
+	}{{AlternateGreetingsField, c.AlternateGreetings}, {GroupGreetingsField, c.GroupGreetings}} {
+		for index := range greetings.values {
+			greeting := property.String(greetings.values[index])
+			truncate(fmt.Sprintf("%s[%d]", greetings.field, index), &greeting, limits.Greeting)
+			greetings.values[index] = string(greeting)
+		}
+	}
+
+	// Truncate the lorebook entries contents
+	if c.CharacterBook != nil {
+		for index, entry := range c.CharacterBook.Entries {
+			if entry != nil {
+				truncate(fmt.Sprintf("%s.entries[%d].content", CharacterBookField, index), &entry.Content, limits.EntryContent)
+			}
+		}
+	}
+
+	// Return the notes
+	return notes
+}
@@ -0,0 +1,62 @@
+package character
+
+import (
+	"testing"
+
+	"github.com/r3dpixel/card-parser/property"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestContent_EnforceLimits(t *testing.T) {
+	content := &Content{
+		Description:        "{{char}} is a knight sworn to protect {{user}} at all costs.",
+		Personality:        "brave",
+		Scenario:           "城の門の前で{{user}}を待っている。",
+		FirstMessage:       "Greetings, traveler 👋🏽 welcome!",
+		AlternateGreetings: property.StringArray{"Short", "A much longer alternate greeting"},
+		GroupGreetings:     property.StringArray{"Hello, everyone in the group"},
+		CharacterBook: &Book{Entries: []*BookEntry{
+			{BookEntryCore: BookEntryCore{Content: "The castle stands on the hill."}},
+			nil,
+			{BookEntryCore: BookEntryCore{Content: "Tiny"}},
+		}},
+	}
+
+	notes := content.EnforceLimits(FieldLimits{
+		Description:  20,
+		Personality:  20,
+		Scenario:     8,
+		FirstMessage: 22,
+		Greeting:     10,
+		EntryContent: 12,
+		Ellipsis:     "…",
+	})
+
+	// Every truncated field is reported, in field order
+	assert.Equal(t, []TruncationNote{
+		{Field: DescriptionField, OriginalRunes: 60, Runes: 20},
+		{Field: ScenarioField, OriginalRunes: 21, Runes: 7},
+		{Field: FirstMessageField, OriginalRunes: 31, Runes: 20},
+		{Field: "alternate_greetings[1]", OriginalRunes: 32, Runes: 10},
+		{Field: "group_only_greetings[0]", OriginalRunes: 28, Runes: 10},
+		{Field: "character_book.entries[0].content", OriginalRunes: 30, Runes: 11},
+	}, notes)
+
+	// The fields are cut on character boundaries, before the straddling macros
+	assert.Equal(t, property.String("{{char}} is a knigh…"), content.Description)
+	assert.Equal(t, property.String("brave"), content.Personality)
+	assert.Equal(t, property.String("城の門の前で…"), content.Scenario)
+	assert.Equal(t, property.String("Greetings, traveler…"), content.FirstMessage)
+	assert.Equal(t, property.StringArray{"Short", "A much lo…"}, content.AlternateGreetings)
+	assert.Equal(t, property.StringArray{"Hello, ev…"}, content.GroupGreetings)
+	require.Len(t, content.CharacterBook.Entries, 3)
+	assert.Equal(t, property.String("The castle…"), content.CharacterBook.Entries[0].Content)
+	assert.Equal(t, property.String("Tiny"), content.CharacterBook.Entries[2].Content)
+
+	t.Run("No limits", func(t *testing.T) {
+		content := &Content{Description: "A very long description"}
+		assert.Empty(t, content.EnforceLimits(FieldLimits{}))
+		assert.Equal(t, property.String("A very long description"), content.Description)
+	})
+}
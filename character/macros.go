@@ -0,0 +1,188 @@
+package character
+
+import (
+	"cmp"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// KnownMacros names of the known (SillyTavern) macros, lowercase; macros with other names are reported by InvalidMacros
+var KnownMacros = map[string]bool{
+	"user": true, "char": true, "group": true, "groupnotmuted": true, "notchar": true, "charifnotgroup": true,
+	"description": true, "personality": true, "scenario": true, "persona": true, "charprompt": true,
+	"charinstruction": true, "charjailbreak": true, "charversion": true, "char_version": true, "mesexamples": true,
+	"mesexamplesraw": true, "original": true, "input": true, "model": true, "maxprompt": true,
+	"lastmessage": true, "lastmessageid": true, "lastusermessage": true, "lastcharmessage": true,
+	"firstincludedmessageid": true, "currentswipeid": true, "lastswipeid": true,
+	"time": true, "date": true, "weekday": true, "isotime": true, "isodate": true, "time_utc": true,
+	"datetimeformat": true, "idle_duration": true, "timediff": true,
+	"random": true, "pick": true, "roll": true, "reverse": true, "banned": true, "bias": true,
+	"setvar": true, "getvar": true, "addvar": true, "incvar": true, "decvar": true,
+	"setglobalvar": true, "getglobalvar": true, "addglobalvar": true, "incglobalvar": true, "decglobalvar": true,
+	"newline": true, "trim": true, "noop": true, "outlet": true, "//": true,
+}
+
+// MacroUsage an occurrence of a macro in a field of the content
+type MacroUsage struct {
+	Field  string `json:"field"`  // JSON path of the field (e.g. alternate_greetings[1], character_book.entries[3].content)
+	Offset int    `json:"offset"` // Byte offset of the macro in the field
+	Text   string `json:"text"`   // Text of the macro (e.g. {{random::a,b}}), up to the end of the line if not closed
+}
+
+// Macros returns the occurrences of every known macro (e.g. user, char, random, setvar), keyed by lowercase name
+// Macros nested in other macros are reported as well, and malformed char/user templates ({char}, {{{user}}}) count as
+// char/user (as fixed by FixUserCharTemplates)
+func (c *Content) Macros() map[string][]MacroUsage {
+	macros := make(map[string][]MacroUsage)
+	c.scanMacros(func(name string, usage MacroUsage) {
+		if KnownMacros[name] {
+			macros[name] = append(macros[name], usage)
+		}
+	})
+	return macros
+}
+
+// InvalidMacros returns the occurrences of the unknown macros ({{foo}}) and malformed macros ({{foo, {{}}), in field order
+func (c *Content) InvalidMacros() []MacroUsage {
+	var invalid []MacroUsage
+	c.scanMacros(func(name string, usage MacroUsage) {
+		if !KnownMacros[name] {
+			invalid = append(invalid, usage)
+		}
+	})
+	return invalid
+}
+
+// scanMacros calls fn with the name (empty if malformed) and usage of every macro of the macro fields
+func (c *Content) scanMacros(fn func(name string, usage MacroUsage)) {
+	fields := []struct {
+		name string
+		text string
+	}{
+		{DescriptionField, string(c.Description)},
+		{PersonalityField, string(c.Personality)},
+		{ScenarioField, string(c.Scenario)},
+		{FirstMessageField, string(c.FirstMessage)},
+		{MessageExamplesField, string(c.MessageExamples)},
+		{SystemPromptField, string(c.SystemPrompt)},
+		{PostHistoryInstructionsField, string(c.PostHistoryInstructions)},
+	}
+	for _, field := range fields {
+		scanFieldMacros(field.name, field.text, fn)
+	}
+	for index, greeting := range c.AlternateGreetings {
+		scanFieldMacros(fmt.Sprintf("%s[%d]", AlternateGreetingsField, index), greeting, fn)
+	}
+	for index, greeting := range c.GroupGreetings {
+		scanFieldMacros(fmt.Sprintf("%s[%d]", GroupGreetingsField, index), greeting, fn)
+	}
+	scanFieldMacros(ExtensionsField+"."+DepthPromptKey+"."+DepthPromptPromptKey, c.DepthPrompt.Prompt, fn)
+	if c.CharacterBook != nil {
+		for index, entry := range c.CharacterBook.Entries {
+			if entry != nil {
+				scanFieldMacros(fmt.Sprintf("%s.entries[%d].content", CharacterBookField, index), string(entry.Content), fn)
+			}
+		}
+	}
+}
+
+// scanFieldMacros calls fn with the name and usage of every macro of the field text (in offset order)
+func scanFieldMacros(field string, text string, fn func(name string, usage MacroUsage)) {
+	type macro struct {
+		name     string
+		usage    MacroUsage
+		template bool
+	}
+
+	// Collect the macros
+	var macros []macro
+	closed := make(map[int]bool)
+	scanMacroText(text, 0, func(offset int, end int, isClosed bool) {
+		name := ""
+		if match := macroNameRegex.FindStringSubmatch(text[offset+2 : end]); isClosed && match != nil {
+			name = strings.ToLower(match[1])
+		}
+		macros = append(macros, macro{name: name, usage: MacroUsage{Field: field, Offset: offset, Text: text[offset:end]}})
+		closed[offset] = isClosed
+	})
+
+	// Collect the malformed char/user templates, except the well-formed macros followed or preceded by the braces
+	// of an enclosing macro (e.g. {{random::{{char}}}})
+	var templates [][]int
+	for _, template := range []struct {
+		name  string
+		regex *regexp.Regexp
+	}{{"char", charRegex}, {"user", userRegex}} {
+		for _, match := range template.regex.FindAllStringIndex(text, -1) {
+			macroOffset := strings.Index(text[match[0]:match[1]], "{{"+template.name+"}}")
+			if macroOffset >= 0 && closed[match[0]+macroOffset] {
+				continue
+			}
+			templates = append(templates, match)
+			usage := MacroUsage{Field: field, Offset: match[0], Text: text[match[0]:match[1]]}
+			macros = append(macros, macro{name: template.name, usage: usage, template: true})
+		}
+	}
+
+	// Drop the macros scanned inside the malformed templates (e.g. {{char} is not an unclosed macro)
+	macros = slices.DeleteFunc(macros, func(m macro) bool {
+		return !m.template && slices.ContainsFunc(templates, func(match []int) bool {
+			return match[0] <= m.usage.Offset && m.usage.Offset < match[1]
+		})
+	})
+
+	// Report the macros in offset order
+	slices.SortStableFunc(macros, func(a, b macro) int {
+		return cmp.Compare(a.usage.Offset, b.usage.Offset)
+	})
+	for _, macro := range macros {
+		fn(macro.name, macro.usage)
+	}
+}
+
+// scanMacroText calls fn with the start and end offsets of every macro of the text (nested macros included)
+// Macros that are not closed end at the end of the line, and are reported as not closed
+func scanMacroText(text string, base int, fn func(offset int, end int, closed bool)) {
+	for position := 0; position < len(text); {
+		// Find the next macro
+		start := strings.Index(text[position:], "{{")
+		if start < 0 {
+			return
+		}
+		start += position
+
+		// Find the matching closing braces (counting the nested macros)
+		depth, end := 0, start
+		for end < len(text)-1 {
+			switch text[end : end+2] {
+			case "{{":
+				depth, end = depth+1, end+2
+			case "}}":
+				depth, end = depth-1, end+2
+			default:
+				end++
+			}
+			if depth == 0 {
+				break
+			}
+		}
+
+		// Report the unclosed macro (the text after its braces is still scanned)
+		if depth > 0 {
+			lineEnd := strings.IndexByte(text[start:], '\n')
+			if lineEnd < 0 {
+				lineEnd = len(text) - start
+			}
+			fn(base+start, base+start+lineEnd, false)
+			position = start + 2
+			continue
+		}
+
+		// Report the macro, then the nested macros
+		fn(base+start, base+end, true)
+		scanMacroText(text[start+2:end-2], base+start+2, fn)
+		position = end
+	}
+}
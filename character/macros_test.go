@@ -0,0 +1,110 @@
+package character
+
+import (
+	"testing"
+
+	"github.com/r3dpixel/card-parser/property"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestContent_Macros(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  *Content
+		expected map[string][]MacroUsage
+		invalid  []MacroUsage
+	}{
+		{
+			name:     "No macros",
+			content:  &Content{Description: "A brave knight"},
+			expected: map[string][]MacroUsage{},
+		},
+		{
+			name:    "Simple macros",
+			content: &Content{Description: "{{char}} meets {{user}}", FirstMessage: "Hi {{User}}"},
+			expected: map[string][]MacroUsage{
+				"char": {{Field: DescriptionField, Offset: 0, Text: "{{char}}"}},
+				"user": {
+					{Field: DescriptionField, Offset: 15, Text: "{{user}}"},
+					{Field: FirstMessageField, Offset: 3, Text: "{{User}}"},
+				},
+			},
+		},
+		{
+			name:    "Macros with arguments",
+			content: &Content{Scenario: "{{setvar::mood::happy}} {{getvar::mood}} {{random:a,b}} {{roll 1d6}} {{idle_duration}} {{// note}} {{time_UTC+2}}"},
+			expected: map[string][]MacroUsage{
+				"setvar":        {{Field: ScenarioField, Offset: 0, Text: "{{setvar::mood::happy}}"}},
+				"getvar":        {{Field: ScenarioField, Offset: 24, Text: "{{getvar::mood}}"}},
+				"random":        {{Field: ScenarioField, Offset: 41, Text: "{{random:a,b}}"}},
+				"roll":          {{Field: ScenarioField, Offset: 56, Text: "{{roll 1d6}}"}},
+				"idle_duration": {{Field: ScenarioField, Offset: 69, Text: "{{idle_duration}}"}},
+				"//":            {{Field: ScenarioField, Offset: 87, Text: "{{// note}}"}},
+				"time_utc":      {{Field: ScenarioField, Offset: 99, Text: "{{time_UTC+2}}"}},
+			},
+		},
+		{
+			name:    "Nested braces",
+			content: &Content{Personality: "{{random::{{user}},{{char}}}}"},
+			expected: map[string][]MacroUsage{
+				"random": {{Field: PersonalityField, Offset: 0, Text: "{{random::{{user}},{{char}}}}"}},
+				"user":   {{Field: PersonalityField, Offset: 10, Text: "{{user}}"}},
+				"char":   {{Field: PersonalityField, Offset: 19, Text: "{{char}}"}},
+			},
+		},
+		{
+			name:    "Templates fixed by FixUserCharTemplates",
+			content: &Content{MessageExamples: "{char}: {{{user}}} and {{char}"},
+			expected: map[string][]MacroUsage{
+				"char": {
+					{Field: MessageExamplesField, Offset: 0, Text: "{char}"},
+					{Field: MessageExamplesField, Offset: 23, Text: "{{char}"},
+				},
+				"user": {{Field: MessageExamplesField, Offset: 8, Text: "{{{user}}}"}},
+			},
+		},
+		{
+			name: "Unknown and malformed macros",
+			content: &Content{
+				SystemPrompt:            "{{foo}} and {{bar\nnext {{user}}",
+				PostHistoryInstructions: "{{}} {{123}}",
+			},
+			expected: map[string][]MacroUsage{
+				"user": {{Field: SystemPromptField, Offset: 23, Text: "{{user}}"}},
+			},
+			invalid: []MacroUsage{
+				{Field: SystemPromptField, Offset: 0, Text: "{{foo}}"},
+				{Field: SystemPromptField, Offset: 12, Text: "{{bar"},
+				{Field: PostHistoryInstructionsField, Offset: 0, Text: "{{}}"},
+				{Field: PostHistoryInstructionsField, Offset: 5, Text: "{{123}}"},
+			},
+		},
+		{
+			name: "Greetings, depth prompt and lorebook",
+			content: &Content{
+				AlternateGreetings: property.StringArray{"Hello", "Hi {{user}}"},
+				GroupGreetings:     property.StringArray{"{{char}} waves"},
+				DepthPrompt:        DepthPrompt{Prompt: "{{char}} is brave", Depth: 4},
+				CharacterBook: &Book{Entries: []*BookEntry{
+					nil,
+					{BookEntryCore: BookEntryCore{Content: "{{getvar::quest}}"}},
+				}},
+			},
+			expected: map[string][]MacroUsage{
+				"user": {{Field: "alternate_greetings[1]", Offset: 3, Text: "{{user}}"}},
+				"char": {
+					{Field: "group_only_greetings[0]", Offset: 0, Text: "{{char}}"},
+					{Field: "extensions.depth_prompt.prompt", Offset: 0, Text: "{{char}}"},
+				},
+				"getvar": {{Field: "character_book.entries[1].content", Offset: 0, Text: "{{getvar::quest}}"}},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.content.Macros())
+			assert.Equal(t, tt.invalid, tt.content.InvalidMacros())
+		})
+	}
+}
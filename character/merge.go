@@ -0,0 +1,145 @@
+package character
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/r3dpixel/card-parser/property"
+	"github.com/r3dpixel/toolkit/stringsx"
+)
+
+// Merge merges the other sheet into the sheet (see Content.Merge), keeping the higher revision of the two
+func (s *Sheet) Merge(other *Sheet) {
+	// If the other sheet is nil, return (NO-OP)
+	if other == nil {
+		return
+	}
+
+	// Merge the content
+	s.Content.Merge(&other.Content)
+
+	// Keep the higher revision
+	if other.Revision > s.Revision {
+		s.SetRevision(other.Revision)
+	}
+}
+
+// Merge merges the other content into the content field-by-field:
+//   - non-blank strings of the other content win (same semantics as the property setters)
+//   - the later modification date and the earlier (non-zero) creation date win
+//   - greetings, tags, sources and assets are unioned without duplicates (tags are compared case-insensitively)
+//   - extension maps are deep merged without overwriting existing values (same rule as BookMerger.AppendMapExtensions)
+//   - lorebooks are merged with the BookMerger
+//
+// The other content is not modified
+func (c *Content) Merge(other *Content) {
+	// If the other content is nil, return (NO-OP)
+	if other == nil {
+		return
+	}
+
+	// Non-blank strings win
+	c.Title.SetIfProperty(other.Title)
+	c.Name.SetIfProperty(other.Name)
+	c.Description.SetIfProperty(other.Description)
+	c.Personality.SetIfProperty(other.Personality)
+	c.Scenario.SetIfProperty(other.Scenario)
+	c.FirstMessage.SetIfProperty(other.FirstMessage)
+	c.MessageExamples.SetIfProperty(other.MessageExamples)
+	c.CreatorNotes.SetIfProperty(other.CreatorNotes)
+	c.SystemPrompt.SetIfProperty(other.SystemPrompt)
+	c.PostHistoryInstructions.SetIfProperty(other.PostHistoryInstructions)
+	c.Creator.SetIfProperty(other.Creator)
+	c.CharacterVersion.SetIfProperty(other.CharacterVersion)
+	c.Nickname.SetIfProperty(other.Nickname)
+	c.SourceID.SetIfProperty(other.SourceID)
+	c.CharacterID.SetIfProperty(other.CharacterID)
+	c.PlatformID.SetIfProperty(other.PlatformID)
+	c.DirectLink.SetIfProperty(other.DirectLink)
+
+	// A non-blank depth prompt wins (together with its depth)
+	if stringsx.IsNotBlank(other.DepthPrompt.Prompt) {
+		c.DepthPrompt = other.DepthPrompt
+	}
+
+	// The later modification date wins, the earlier creation date wins
+	c.ModificationDate = max(c.ModificationDate, other.ModificationDate)
+	if other.CreationDate > 0 && (c.CreationDate <= 0 || other.CreationDate < c.CreationDate) {
+		c.CreationDate = other.CreationDate
+	}
+
+	// Union the arrays
+	c.AlternateGreetings = unionStrings(c.AlternateGreetings, other.AlternateGreetings, strings.TrimSpace)
+	c.GroupGreetings = unionStrings(c.GroupGreetings, other.GroupGreetings, strings.TrimSpace)
+	c.Tags = unionStrings(c.Tags, other.Tags, NormalizeTag)
+	c.Source = unionStrings(c.Source, other.Source, strings.TrimSpace)
+	for _, asset := range other.Assets {
+		if !slices.Contains(c.Assets, asset) {
+			c.Assets = append(c.Assets, asset)
+		}
+	}
+
+	// Merge the multilingual creator notes (non-blank notes win)
+	for language, note := range other.CreatorNotesMultilingual {
+		if stringsx.IsBlank(string(note)) {
+			continue
+		}
+		if c.CreatorNotesMultilingual == nil {
+			c.CreatorNotesMultilingual = make(map[string]property.String)
+		}
+		c.CreatorNotesMultilingual[language] = note
+	}
+
+	// Merge the extensions without overwriting existing values
+	c.MergeExtensionsFrom(other, MergeKeepDst)
+
+	// Keep the existing RisuAI extensions (same rule as the extension keys)
+	if c.Risu == nil {
+		c.Risu = other.Risu.Clone()
+	}
+
+	// Keep the existing regex scripts (same rule as the extension keys)
+	if c.RegexScripts == nil {
+		c.RegexScripts = cloneRegexScripts(other.RegexScripts)
+	}
+
+	// Merge the lorebooks
+	c.CharacterBook = mergeBooks(c.CharacterBook, other.CharacterBook)
+}
+
+// mergeBooks merges the other lorebook into the book using the BookMerger (the other book is not modified)
+func mergeBooks(book *Book, other *Book) *Book {
+	// Nothing to merge
+	if other.IsEmpty() {
+		return book
+	}
+	if book.IsEmpty() {
+		return other.Clone()
+	}
+
+	// Merge both books
+	merger := NewBookMerger()
+	merger.AppendBook(book)
+	merger.AppendBook(other.Clone())
+	return merger.Build()
+}
+
+// unionStrings appends the values missing from the base (compared by key) and returns the union
+func unionStrings(base property.StringArray, values property.StringArray, key func(string) string) property.StringArray {
+	// Collect the keys of the base
+	seen := make(map[string]bool, len(base)+len(values))
+	for _, value := range base {
+		seen[key(value)] = true
+	}
+
+	// Append the missing values
+	for _, value := range values {
+		if valueKey := key(value); !seen[valueKey] && stringsx.IsNotBlank(valueKey) {
+			seen[valueKey] = true
+			base = append(base, value)
+		}
+	}
+
+	// Return the union
+	return base
+}
@@ -0,0 +1,142 @@
+package character
+
+import (
+	"testing"
+
+	"github.com/r3dpixel/card-parser/property"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSheet_Merge(t *testing.T) {
+	t.Run("Nil other", func(t *testing.T) {
+		sheet := DefaultSheet(RevisionV2)
+		sheet.Name = "Alice"
+		sheet.Merge(nil)
+		assert.Equal(t, property.String("Alice"), sheet.Name)
+		assert.Equal(t, RevisionV2, sheet.Revision)
+	})
+
+	t.Run("Strings and dates", func(t *testing.T) {
+		sheet := DefaultSheet(RevisionV2)
+		sheet.Name = "Alice"
+		sheet.Description = "Old description"
+		sheet.CreationDate = 200
+		sheet.ModificationDate = 300
+
+		other := DefaultSheet(RevisionV3)
+		other.Name = "   "
+		other.Description = "New description"
+		other.CreationDate = 100
+		other.ModificationDate = 250
+
+		sheet.Merge(other)
+		assert.Equal(t, property.String("Alice"), sheet.Name)
+		assert.Equal(t, property.String("New description"), sheet.Description)
+		assert.EqualValues(t, 100, sheet.CreationDate)
+		assert.EqualValues(t, 300, sheet.ModificationDate)
+		assert.Equal(t, RevisionV3, sheet.Revision)
+		assert.Equal(t, SpecV3, sheet.Spec)
+	})
+
+	t.Run("Lower revision is kept higher", func(t *testing.T) {
+		sheet := DefaultSheet(RevisionV3)
+		sheet.Merge(DefaultSheet(RevisionV2))
+		assert.Equal(t, RevisionV3, sheet.Revision)
+	})
+}
+
+func TestContent_Merge_DepthPrompt(t *testing.T) {
+	testCases := []struct {
+		name     string
+		base     DepthPrompt
+		other    DepthPrompt
+		expected DepthPrompt
+	}{
+		{"Conflicting prompts (other wins)", DepthPrompt{"base", 2}, DepthPrompt{"other", 6}, DepthPrompt{"other", 6}},
+		{"Blank other prompt", DepthPrompt{"base", 2}, DepthPrompt{"  ", 6}, DepthPrompt{"base", 2}},
+		{"Blank base prompt", DepthPrompt{"", DefaultDepth}, DepthPrompt{"other", 1}, DepthPrompt{"other", 1}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			content := &Content{DepthPrompt: tc.base}
+			content.Merge(&Content{DepthPrompt: tc.other})
+			assert.Equal(t, tc.expected, content.DepthPrompt)
+		})
+	}
+}
+
+func TestContent_Merge_Arrays(t *testing.T) {
+	content := &Content{
+		Tags:               property.StringArray{"Fantasy", "Elf"},
+		AlternateGreetings: property.StringArray{"Hello"},
+		Assets:             []Asset{{Type: "icon", URI: "ccdefault:"}},
+	}
+	other := &Content{
+		Tags:               property.StringArray{"fantasy", " ELF ", "Magic", "magic", ""},
+		AlternateGreetings: property.StringArray{"Hello", "Hi"},
+		Assets:             []Asset{{Type: "icon", URI: "ccdefault:"}, {Type: "background", URI: "embeded://bg.png"}},
+	}
+
+	content.Merge(other)
+	assert.Equal(t, property.StringArray{"Fantasy", "Elf", "Magic"}, content.Tags)
+	assert.Equal(t, property.StringArray{"Hello", "Hi"}, content.AlternateGreetings)
+	assert.Len(t, content.Assets, 2)
+}
+
+func TestContent_Merge_Maps(t *testing.T) {
+	content := &Content{
+		Extensions:               map[string]any{"talkativeness": "0.5"},
+		CreatorNotesMultilingual: map[string]property.String{"en": "English"},
+	}
+	other := &Content{
+		Extensions:               map[string]any{"talkativeness": "0.9", "fav": true},
+		CreatorNotesMultilingual: map[string]property.String{"en": "Other English", "fr": "Français", "de": " "},
+	}
+
+	content.Merge(other)
+	assert.Equal(t, map[string]any{"talkativeness": "0.5", "fav": true}, content.Extensions)
+	assert.Equal(t, map[string]property.String{"en": "Other English", "fr": "Français"}, content.CreatorNotesMultilingual)
+}
+
+func TestContent_Merge_Books(t *testing.T) {
+	newBook := func(name string, contents ...string) *Book {
+		book := &Book{Name: property.String(name)}
+		for _, entryContent := range contents {
+			book.Entries = append(book.Entries, &BookEntry{BookEntryCore: BookEntryCore{Content: property.String(entryContent)}})
+		}
+		return book
+	}
+
+	t.Run("Both books", func(t *testing.T) {
+		content := &Content{CharacterBook: newBook("Base", "a")}
+		other := &Content{CharacterBook: newBook("Other", "b", "c")}
+
+		content.Merge(other)
+		require.NotNil(t, content.CharacterBook)
+		require.Len(t, content.CharacterBook.Entries, 3)
+		assert.Equal(t, property.String("c"), content.CharacterBook.Entries[2].Content)
+		// The other book is not modified
+		assert.Equal(t, property.String("Other"), other.CharacterBook.Name)
+		assert.Nil(t, other.CharacterBook.Entries[0].ID.IntValue)
+	})
+
+	t.Run("Only other book", func(t *testing.T) {
+		content := &Content{}
+		other := &Content{CharacterBook: newBook("Other", "b")}
+
+		content.Merge(other)
+		require.NotNil(t, content.CharacterBook)
+		assert.NotSame(t, other.CharacterBook, content.CharacterBook)
+		assert.Len(t, content.CharacterBook.Entries, 1)
+	})
+
+	t.Run("Only base book", func(t *testing.T) {
+		book := newBook("Base", "a")
+		content := &Content{CharacterBook: book}
+
+		content.Merge(&Content{})
+		assert.Same(t, book, content.CharacterBook)
+	})
+}
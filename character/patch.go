@@ -0,0 +1,453 @@
+package character
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"math"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/r3dpixel/card-parser/internal/codec"
+	"github.com/r3dpixel/card-parser/property"
+	"github.com/r3dpixel/toolkit/stringsx"
+)
+
+// Errors of the patch entries (reported wrapped in a PatchError)
+var (
+	ErrPatchPath  = errors.New("unknown patch path")
+	ErrPatchType  = errors.New("patch value type mismatch")
+	ErrPatchValue = errors.New("invalid patch value")
+	ErrPatchIndex = errors.New("patch index out of range")
+)
+
+// PatchError a patch entry that could not be applied
+type PatchError struct {
+	Path string // Path of the entry (e.g. data.character_book.entries[2].enabled)
+	Err  error  // Cause of the failure (wrapping ErrPatchPath, ErrPatchType, ErrPatchValue or ErrPatchIndex)
+}
+
+// Error returns the path of the entry with the cause of the failure
+func (e PatchError) Error() string {
+	return e.Path + ": " + e.Err.Error()
+}
+
+// Unwrap returns the cause of the failure
+func (e PatchError) Unwrap() error {
+	return e.Err
+}
+
+// patchStep step of a patch path (a field or key name, or an index for named steps set to -1)
+type patchStep struct {
+	name  string
+	index int
+}
+
+// patchField field of a struct reachable by a patch path
+type patchField struct {
+	names []string // Path names of the field (e.g. extensions, depth_prompt)
+	index []int    // Index sequence of the field (embedded structs are flattened)
+	raw   bool     // Set for map fields (tried after the typed fields sharing their path)
+}
+
+// ApplyPatch applies the patch entries (JSON paths, e.g. data.name or data.character_book.entries[2].enabled, mapped
+// to their values) to the sheet, in path order, and returns the entries that could not be applied
+// Values go through the property semantics: blank strings do not overwrite strings (SetIf), enums (e.g. position,
+// role) accept their names or numbers in range, and numbers must match the field type; missing structures are created
+// (e.g. the character book), while indexes must refer to existing elements; paths into the extensions write the raw
+// values, except the fields with a dedicated structure (e.g. extensions.depth_prompt.depth)
+// Failed entries leave the sheet untouched, and do not prevent the other entries from being applied
+func (s *Sheet) ApplyPatch(patch map[string]any) []PatchError {
+	var failures []PatchError
+	for _, path := range slices.Sorted(maps.Keys(patch)) {
+		if err := s.applyPatchEntry(path, patch[path]); err != nil {
+			failures = append(failures, PatchError{Path: path, Err: err})
+		}
+	}
+	return failures
+}
+
+// applyPatchEntry applies a single patch entry to the content of the sheet
+func (s *Sheet) applyPatchEntry(path string, value any) error {
+	// Parse the path
+	steps, err := parsePatchPath(path)
+	if err != nil {
+		return err
+	}
+
+	// Only the content can be patched
+	contentName := diffFieldNames["Sheet.Content"]
+	if steps[0].name != contentName {
+		return fmt.Errorf("%w: only the %s fields can be patched", ErrPatchPath, contentName)
+	}
+
+	// Decode the pending lorebook first (see FromBytesLazy)
+	if len(steps) > 1 && steps[1].name == CharacterBookField {
+		if _, err := s.Book(); err != nil {
+			return err
+		}
+	}
+	return patchValue(reflect.ValueOf(&s.Content).Elem(), steps[1:], value)
+}
+
+// parsePatchPath splits a patch path into its steps (dot separated names, each followed by any bracketed indexes)
+func parsePatchPath(path string) ([]patchStep, error) {
+	var steps []patchStep
+	for part := range strings.SplitSeq(path, ".") {
+		// Add the name
+		name, indexes, hasIndex := strings.Cut(part, "[")
+		if name == "" {
+			return nil, fmt.Errorf("%w: malformed path", ErrPatchPath)
+		}
+		steps = append(steps, patchStep{name: name, index: -1})
+
+		// Add the indexes
+		for hasIndex {
+			digits, rest, ok := strings.Cut(indexes, "]")
+			index, err := strconv.Atoi(digits)
+			if !ok || err != nil || index < 0 {
+				return nil, fmt.Errorf("%w: malformed index in %q", ErrPatchPath, part)
+			}
+			steps = append(steps, patchStep{index: index})
+			if indexes, hasIndex = strings.CutPrefix(rest, "["); !hasIndex && rest != "" {
+				return nil, fmt.Errorf("%w: malformed index in %q", ErrPatchPath, part)
+			}
+		}
+	}
+	return steps, nil
+}
+
+// patchValue sets the value at the path (steps) below the target
+// Missing structures (pointers, maps and raw objects) are only attached to the target once the value is set
+func patchValue(target reflect.Value, steps []patchStep, value any) error {
+	// Set the value at the end of the path
+	if len(steps) == 0 {
+		return setPatchValue(target, value)
+	}
+
+	step := steps[0]
+	switch target.Kind() {
+	case reflect.Pointer:
+		// Create the missing structure
+		elem := target
+		if target.IsNil() {
+			elem = reflect.New(target.Type().Elem())
+		}
+		if err := patchValue(elem.Elem(), steps, value); err != nil {
+			return err
+		}
+		if target.IsNil() {
+			target.Set(elem)
+		}
+		return nil
+	case reflect.Struct:
+		return patchStruct(target, steps, value)
+	case reflect.Slice:
+		// Only existing elements can be patched
+		if step.index < 0 {
+			return fmt.Errorf("%w: %q is an array", ErrPatchPath, step.name)
+		}
+		if step.index >= target.Len() {
+			return fmt.Errorf("%w: index %d (length %d)", ErrPatchIndex, step.index, target.Len())
+		}
+		return patchValue(target.Index(step.index), steps[1:], value)
+	case reflect.Map:
+		if step.index >= 0 || target.Type().Key().Kind() != reflect.String {
+			return fmt.Errorf("%w: index %d of an object", ErrPatchPath, step.index)
+		}
+		// Patch a copy of the element (map elements are not addressable), and store it back
+		key := reflect.ValueOf(step.name).Convert(target.Type().Key())
+		elem := reflect.New(target.Type().Elem()).Elem()
+		if existing := target.MapIndex(key); existing.IsValid() {
+			elem.Set(existing)
+		}
+		if err := patchValue(elem, steps[1:], value); err != nil {
+			return err
+		}
+		if target.IsNil() {
+			target.Set(reflect.MakeMap(target.Type()))
+		}
+		target.SetMapIndex(key, elem)
+		return nil
+	case reflect.Interface:
+		// Create missing raw objects (missing raw arrays cannot be indexed)
+		if target.IsNil() {
+			if step.index >= 0 {
+				return fmt.Errorf("%w: index %d (length 0)", ErrPatchIndex, step.index)
+			}
+			created := reflect.ValueOf(map[string]any{})
+			if err := patchValue(created, steps, value); err != nil {
+				return err
+			}
+			target.Set(created)
+			return nil
+		}
+		// Descend into raw objects and arrays
+		if inner := target.Elem(); inner.Kind() == reflect.Map || inner.Kind() == reflect.Slice {
+			return patchValue(inner, steps, value)
+		}
+		return fmt.Errorf("%w: expected an object or an array, got %T", ErrPatchType, target.Interface())
+	default:
+		return fmt.Errorf("%w: %s is not an object or an array", ErrPatchPath, target.Type())
+	}
+}
+
+// patchStruct sets the value at the path (steps) below the struct, trying the fields matching the path in order
+func patchStruct(target reflect.Value, steps []patchStep, value any) error {
+	var err error
+	for _, field := range patchFields(target.Type()) {
+		// Skip the fields not matching the path
+		if !field.matches(steps) {
+			continue
+		}
+
+		// Try the next matching field if the path is unknown below the field (e.g. extensions.depth_prompt.role)
+		fieldErr := patchValue(target.FieldByIndex(field.index), steps[len(field.names):], value)
+		if !errors.Is(fieldErr, ErrPatchPath) {
+			return fieldErr
+		}
+		if err == nil {
+			err = fieldErr
+		}
+	}
+
+	// Report the first failure, or the unknown field
+	if err == nil {
+		err = fmt.Errorf("%w: no field %q", ErrPatchPath, steps[0].name)
+	}
+	return err
+}
+
+// matches checks if the path (steps) starts with the names of the field
+func (f patchField) matches(steps []patchStep) bool {
+	if len(f.names) > len(steps) {
+		return false
+	}
+	for index, name := range f.names {
+		if steps[index].index >= 0 || steps[index].name != name {
+			return false
+		}
+	}
+	return true
+}
+
+// patchFields returns the fields of the struct reachable by a patch path, fields with longer paths first
+// (e.g. extensions.depth_prompt before extensions), and typed fields before the raw maps sharing their path
+func patchFields(parent reflect.Type) []patchField {
+	var fields []patchField
+	for index := range parent.NumField() {
+		field := parent.Field(index)
+		if !field.IsExported() {
+			continue
+		}
+
+		// Skip the fields without a path
+		if _, mapped := diffFieldNames[parent.Name()+"."+field.Name]; !mapped && field.Tag.Get("json") == "-" {
+			continue
+		}
+
+		// Flatten the embedded structs
+		name := fieldPathName(parent, field)
+		if name == "" {
+			if field.Type.Kind() == reflect.Struct {
+				for _, nested := range patchFields(field.Type) {
+					nested.index = append([]int{index}, nested.index...)
+					fields = append(fields, nested)
+				}
+			}
+			continue
+		}
+		fields = append(fields, patchField{
+			names: strings.Split(name, "."),
+			index: []int{index},
+			raw:   field.Type.Kind() == reflect.Map,
+		})
+	}
+
+	// Sort by path length, then typed fields first
+	slices.SortStableFunc(fields, func(a, b patchField) int {
+		if len(a.names) != len(b.names) {
+			return len(b.names) - len(a.names)
+		}
+		switch {
+		case a.raw == b.raw:
+			return 0
+		case a.raw:
+			return 1
+		default:
+			return -1
+		}
+	})
+	return fields
+}
+
+// setPatchValue sets the value into the target, converting it through the property semantics
+func setPatchValue(target reflect.Value, value any) error {
+	// Raw values are set as is
+	if target.Kind() == reflect.Interface {
+		if value == nil {
+			target.SetZero()
+		} else {
+			target.Set(reflect.ValueOf(value))
+		}
+		return nil
+	}
+
+	switch typed := target.Addr().Interface().(type) {
+	case *property.String:
+		text, ok := value.(string)
+		if !ok {
+			return patchTypeError("a string", value)
+		}
+		typed.SetIf(text)
+	case *string:
+		text, ok := value.(string)
+		if !ok {
+			return patchTypeError("a string", value)
+		}
+		if stringsx.IsNotBlank(text) {
+			*typed = text
+		}
+	case *property.StringArray:
+		texts, ok := patchStrings(value)
+		if !ok {
+			return patchTypeError("an array of strings", value)
+		}
+		*typed = texts
+	case *property.Bool:
+		flag, ok := value.(bool)
+		if !ok {
+			return patchTypeError("a boolean", value)
+		}
+		*typed = property.Bool(flag)
+	case *property.Integer:
+		number, ok := integerValue(value)
+		if !ok {
+			return patchTypeError("an integer", value)
+		}
+		*typed = property.Integer(number)
+	case *property.Float:
+		number, ok := numberValue(value)
+		if !ok {
+			return patchTypeError("a number", value)
+		}
+		*typed = property.Float(number)
+	case *property.Union:
+		if number, ok := integerValue(value); ok {
+			*typed = property.UnionFromInt(number)
+		} else if text, ok := value.(string); ok {
+			*typed = property.UnionFromString(text)
+		} else {
+			return patchTypeError("a string or an integer", value)
+		}
+	case *property.LorePosition:
+		return setPatchEnum[property.LorePosition](typed, value, property.LorePositionProp())
+	case *property.Role:
+		return setPatchEnum[property.Role](typed, value, property.RoleProp())
+	case *property.SelectiveLogic:
+		return setPatchEnum[property.SelectiveLogic](typed, value, property.SelectiveLogicProp())
+	default:
+		return setPatchKind(target, value)
+	}
+	return nil
+}
+
+// setPatchKind sets the value into a target without property semantics (plain numbers and booleans are checked,
+// other values are converted through JSON)
+func setPatchKind(target reflect.Value, value any) error {
+	switch target.Kind() {
+	case reflect.Bool:
+		flag, ok := value.(bool)
+		if !ok {
+			return patchTypeError("a boolean", value)
+		}
+		target.SetBool(flag)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		number, ok := integerValue(value)
+		if !ok || target.OverflowInt(int64(number)) {
+			return patchTypeError("an integer", value)
+		}
+		target.SetInt(int64(number))
+	case reflect.Float32, reflect.Float64:
+		number, ok := numberValue(value)
+		if !ok {
+			return patchTypeError("a number", value)
+		}
+		target.SetFloat(number)
+	default:
+		// Convert the value through JSON (e.g. a whole book, or an array of assets)
+		data, err := codec.Marshal(value)
+		if err != nil {
+			return fmt.Errorf("%w: %w", ErrPatchType, err)
+		}
+		converted := reflect.New(target.Type())
+		if err := codec.Unmarshal(data, converted.Interface()); err != nil {
+			return fmt.Errorf("%w: %w", ErrPatchType, err)
+		}
+		target.Set(converted.Elem())
+	}
+	return nil
+}
+
+// setPatchEnum sets the enum to the value (a name known to the parser, or a number in range, possibly as a string)
+func setPatchEnum[T ~int](target *T, value any, parser enumParser[T]) error {
+	// Names (or numbers as strings)
+	number, ok := integerValue(value)
+	if text, isText := value.(string); isText {
+		if parsed, known := parser.Parse(text); known {
+			*target = parsed
+			return nil
+		}
+		parsedNumber, err := strconv.Atoi(strings.TrimSpace(text))
+		if err != nil {
+			return fmt.Errorf("%w: unknown name %q", ErrPatchValue, text)
+		}
+		number, ok = parsedNumber, true
+	}
+
+	// Numbers in range
+	if !ok {
+		return patchTypeError("a name or an integer", value)
+	}
+	if parsed := parser.FromInt(number); int(parsed) == number {
+		*target = parsed
+		return nil
+	}
+	return fmt.Errorf("%w: %d is out of range", ErrPatchValue, number)
+}
+
+// integerValue returns the value as an integer, if it is a number without a fractional part (of any numeric type)
+func integerValue(value any) (int, bool) {
+	number, ok := numberValue(value)
+	if !ok || number != math.Trunc(number) || math.Abs(number) > 1<<53 {
+		return 0, false
+	}
+	return int(number), true
+}
+
+// patchStrings returns the value as a string array, if it is an array of strings
+func patchStrings(value any) (property.StringArray, bool) {
+	switch typed := value.(type) {
+	case []string:
+		return slices.Clone(typed), true
+	case []any:
+		texts := make(property.StringArray, 0, len(typed))
+		for _, item := range typed {
+			text, ok := item.(string)
+			if !ok {
+				return nil, false
+			}
+			texts = append(texts, text)
+		}
+		return texts, true
+	default:
+		return nil, false
+	}
+}
+
+// patchTypeError returns the type mismatch error of a value
+func patchTypeError(expected string, value any) error {
+	return fmt.Errorf("%w: expected %s, got %T", ErrPatchType, expected, value)
+}
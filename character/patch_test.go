@@ -0,0 +1,149 @@
+package character
+
+import (
+	"testing"
+
+	"github.com/r3dpixel/card-parser/property"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// patchSheet returns a sheet with a three entry book
+func patchSheet() *Sheet {
+	sheet := DefaultSheet(RevisionV3)
+	sheet.Name = "Old Name"
+	sheet.Extensions = map[string]any{"fav": false}
+	sheet.CharacterBook = &Book{Name: "Lore"}
+	for range 3 {
+		sheet.CharacterBook.Entries = append(sheet.CharacterBook.Entries, DefaultBookEntry())
+	}
+	return sheet
+}
+
+func TestSheet_ApplyPatch(t *testing.T) {
+	sheet := patchSheet()
+	failures := sheet.ApplyPatch(map[string]any{
+		"data.name":                                          "New Name",
+		"data.description":                                   "   ",
+		"data.tags":                                          []any{"fantasy", "knight"},
+		"data.extensions.depth_prompt.prompt":                "Stay in character",
+		"data.extensions.depth_prompt.depth":                 6,
+		"data.extensions.depth_prompt.role":                  "system",
+		"data.extensions.world.name":                         "Aldor",
+		"data.character_book.entries[2].enabled":             false,
+		"data.character_book.entries[2].keys":                []string{"sword"},
+		"data.character_book.entries[1].id":                  7.0,
+		"data.character_book.entries[1].extensions.position": "at_depth",
+		"data.character_book.entries[1].extensions.role":     1,
+		"data.character_book.entries[1].extensions.custom":   map[string]any{"level": 2},
+		"data.creator_notes_multilingual.fr":                 "Notes",
+	})
+	require.Empty(t, failures)
+
+	// Strings (blank strings are ignored) and arrays
+	assert.Equal(t, property.String("New Name"), sheet.Name)
+	assert.Empty(t, sheet.Description)
+	assert.Equal(t, property.StringArray{"fantasy", "knight"}, sheet.Tags)
+	assert.Equal(t, property.String("Notes"), sheet.CreatorNotesMultilingual["fr"])
+
+	// Extensions (the depth prompt is typed, the other values are raw)
+	assert.Equal(t, DepthPrompt{Prompt: "Stay in character", Depth: 6}, sheet.DepthPrompt)
+	assert.Equal(t, map[string]any{"role": "system"}, sheet.Extensions[DepthPromptKey])
+	assert.Equal(t, map[string]any{"name": "Aldor"}, sheet.Extensions["world"])
+	assert.Equal(t, false, sheet.Extensions["fav"])
+
+	// Nested book entries
+	entries := sheet.CharacterBook.Entries
+	assert.False(t, bool(entries[2].Enabled))
+	assert.Equal(t, property.StringArray{"sword"}, entries[2].Keys)
+	assert.Equal(t, property.UnionFromInt(7), entries[1].ID)
+	assert.Equal(t, property.AtDepth, entries[1].Extensions.LorePosition)
+	assert.Equal(t, property.UserRole, entries[1].Extensions.Role)
+	assert.Equal(t, map[string]any{"level": 2}, entries[1].RawExtensions["custom"])
+	assert.True(t, bool(entries[0].Enabled))
+}
+
+func TestSheet_ApplyPatch_Failures(t *testing.T) {
+	tests := []struct {
+		name  string
+		path  string
+		value any
+		err   error
+	}{
+		{"Invalid enum value", "data.character_book.entries[0].extensions.position", "sideways", ErrPatchValue},
+		{"Enum out of range", "data.character_book.entries[0].extensions.role", 9, ErrPatchValue},
+		{"Entry index out of range", "data.character_book.entries[3].enabled", false, ErrPatchIndex},
+		{"Unknown field", "data.character_book.entries[0].nmae", "Name", ErrPatchPath},
+		{"Outside the content", "spec", "chara_card_v2", ErrPatchPath},
+		{"Malformed index", "data.character_book.entries[x].enabled", false, ErrPatchPath},
+		{"Index of an object", "data.character_book[0]", false, ErrPatchPath},
+		{"Field of a string", "data.name.first", "Name", ErrPatchPath},
+		{"String mismatch", "data.name", 5, ErrPatchType},
+		{"Boolean mismatch", "data.character_book.entries[0].enabled", "no", ErrPatchType},
+		{"Integer mismatch", "data.character_book.entries[0].insertion_order", 2.5, ErrPatchType},
+		{"Array mismatch", "data.tags", []any{"a", 1}, ErrPatchType},
+		{"Field of a raw value", "data.extensions.fav.value", true, ErrPatchType},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sheet := patchSheet()
+			failures := sheet.ApplyPatch(map[string]any{tt.path: tt.value, "data.scenario": "Applied"})
+			require.Len(t, failures, 1)
+			assert.Equal(t, tt.path, failures[0].Path)
+			assert.ErrorIs(t, failures[0], tt.err)
+
+			// The other entries are applied, and the sheet is untouched by the failed entry
+			assert.Equal(t, property.String("Applied"), sheet.Scenario)
+			expected := patchSheet()
+			expected.Scenario = "Applied"
+			assert.True(t, expected.DeepEquals(sheet))
+		})
+	}
+}
+
+func TestSheet_ApplyPatch_CreatesStructures(t *testing.T) {
+	sheet := DefaultSheet(RevisionV3)
+	failures := sheet.ApplyPatch(map[string]any{
+		"data.character_book.name":               "Lore",
+		"data.extensions.risuai.viewScreen":      "emotion",
+		"data.extensions.world.region.name":      "North",
+		"data.character_book.entries[0].enabled": true,
+	})
+
+	// The book and the raw objects are created, entries are not
+	require.Len(t, failures, 1)
+	assert.ErrorIs(t, failures[0], ErrPatchIndex)
+	require.NotNil(t, sheet.CharacterBook)
+	assert.Equal(t, property.String("Lore"), sheet.CharacterBook.Name)
+	require.NotNil(t, sheet.Risu)
+	assert.Equal(t, "emotion", sheet.Risu.ViewScreen)
+	assert.Equal(t, map[string]any{"region": map[string]any{"name": "North"}}, sheet.Extensions["world"])
+
+	// Whole structures are converted through JSON
+	failures = sheet.ApplyPatch(map[string]any{
+		"data.character_book": map[string]any{"name": "Replaced", "entries": []any{map[string]any{"content": "Entry"}}},
+	})
+	require.Empty(t, failures)
+	assert.Equal(t, property.String("Replaced"), sheet.CharacterBook.Name)
+	require.Len(t, sheet.CharacterBook.Entries, 1)
+	assert.Equal(t, property.String("Entry"), sheet.CharacterBook.Entries[0].Content)
+}
+
+func TestParsePatchPath(t *testing.T) {
+	steps, err := parsePatchPath("data.character_book.entries[2].keys[0]")
+	require.NoError(t, err)
+	assert.Equal(t, []patchStep{
+		{name: "data", index: -1},
+		{name: "character_book", index: -1},
+		{name: "entries", index: -1},
+		{index: 2},
+		{name: "keys", index: -1},
+		{index: 0},
+	}, steps)
+
+	for _, path := range []string{"", "data..name", "data.entries[", "data.entries[-1]", "data.entries[1]x", "[0]"} {
+		_, err := parsePatchPath(path)
+		assert.ErrorIs(t, err, ErrPatchPath, path)
+	}
+}
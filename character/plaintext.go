@@ -0,0 +1,42 @@
+package character
+
+import "github.com/r3dpixel/card-parser/property"
+
+// plainTextFields default fields of PlainText (the prose fields)
+var plainTextFields = []string{
+	DescriptionField, PersonalityField, ScenarioField, FirstMessageField, MessageExamplesField, CreatorNotesField,
+	SystemPromptField, PostHistoryInstructionsField,
+}
+
+// PlainText returns the given text fields (JSON names, e.g. DescriptionField) without HTML and markdown (see
+// property.String.StripHTML and property.String.StripMarkdown), keyed by field name; the content is not modified
+// Defaults to the prose fields (description, personality, scenario, first_mes, mes_example, creator_notes,
+// system_prompt, post_history_instructions); unknown and non-text fields are skipped
+func (c *Content) PlainText(fields ...string) map[string]string {
+	if len(fields) == 0 {
+		fields = plainTextFields
+	}
+	texts := map[string]property.String{
+		TitleField:                   c.Title,
+		NameField:                    c.Name,
+		NicknameField:                c.Nickname,
+		DescriptionField:             c.Description,
+		PersonalityField:             c.Personality,
+		ScenarioField:                c.Scenario,
+		FirstMessageField:            c.FirstMessage,
+		MessageExamplesField:         c.MessageExamples,
+		CreatorNotesField:            c.CreatorNotes,
+		SystemPromptField:            c.SystemPrompt,
+		PostHistoryInstructionsField: c.PostHistoryInstructions,
+		CreatorField:                 c.Creator,
+	}
+
+	// Strip the requested fields
+	plain := make(map[string]string, len(fields))
+	for _, field := range fields {
+		if text, ok := texts[field]; ok {
+			plain[field] = string(text.StripHTML().StripMarkdown())
+		}
+	}
+	return plain
+}
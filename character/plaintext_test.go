@@ -0,0 +1,53 @@
+package character
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestContent_PlainText(t *testing.T) {
+	content := &Content{
+		Name:         "**Alice**",
+		Description:  `<p>{{char}} is a <b>knight</b>.</p><p>She says &quot;hi&quot;</p>`,
+		FirstMessage: "*{{char}} bows* \"Welcome, [traveler](https://example.com)!\"",
+		Scenario:     "```\n{ \"a\": 1 }\n```",
+	}
+	original := *content
+
+	tests := []struct {
+		name     string
+		fields   []string
+		expected map[string]string
+	}{
+		{
+			name:   "Requested fields",
+			fields: []string{DescriptionField, FirstMessageField, NameField, "unknown", TagsField},
+			expected: map[string]string{
+				DescriptionField:  "{{char}} is a knight.\n\nShe says \"hi\"",
+				FirstMessageField: `{{char}} bows "Welcome, traveler!"`,
+				NameField:         "Alice",
+			},
+		},
+		{
+			name: "Prose fields by default",
+			expected: map[string]string{
+				DescriptionField:             "{{char}} is a knight.\n\nShe says \"hi\"",
+				PersonalityField:             "",
+				ScenarioField:                `{ "a": 1 }`,
+				FirstMessageField:            `{{char}} bows "Welcome, traveler!"`,
+				MessageExamplesField:         "",
+				CreatorNotesField:            "",
+				SystemPromptField:            "",
+				PostHistoryInstructionsField: "",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, content.PlainText(tt.fields...))
+			assert.Equal(t, original, *content)
+		})
+	}
+}
@@ -0,0 +1,150 @@
+package character
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/r3dpixel/card-parser/property"
+)
+
+var (
+	// Regexes to compress repeated whitespace (runs of spaces/tabs, and more than one blank line)
+	inlineWhitespaceRegex = regexp.MustCompile(`[ \t]{2,}`)
+	blankLinesRegex       = regexp.MustCompile(`\n{3,}`)
+)
+
+// Tokenizer counts the tokens of a text
+type Tokenizer interface {
+	Count(text string) int
+}
+
+// TokenizerFunc adapts a function to the Tokenizer interface
+type TokenizerFunc func(text string) int
+
+// Count returns the number of tokens of the text
+func (f TokenizerFunc) Count(text string) int {
+	return f(text)
+}
+
+// PromptFitPolicy controls how FitPromptWithin trims the content
+type PromptFitPolicy struct {
+	KeepGreetings      int  // Number of alternate greetings always kept (the first N)
+	CompressWhitespace bool // Compress repeated whitespace in the description
+}
+
+// FitRemoval a single removal performed by FitPromptWithin
+type FitRemoval struct {
+	Field  string // Field affected (e.g. alternate_greetings)
+	Index  int    // Index of the greeting, or of the example dialogue (-1 if not applicable)
+	Text   string // Removed text (the original text for whitespace compression, the whole block for dialogues)
+	Tokens int    // Tokens saved by the removal
+}
+
+// FitReport result of FitPromptWithin
+type FitReport struct {
+	Budget       int          // Requested budget
+	TokensBefore int          // Prompt tokens of the original content
+	TokensAfter  int          // Prompt tokens of the trimmed content
+	Removals     []FitRemoval // Removals performed, in order
+}
+
+// Fits returns true if the trimmed content fits within the budget
+func (r FitReport) Fits() bool {
+	return r.TokensAfter <= r.Budget
+}
+
+// FitPromptWithin returns a trimmed copy of the content that fits (as much as possible) within the token budget
+// Trimming steps (each step runs only while the content exceeds the budget):
+//   - compress repeated whitespace in the description (if enabled by the policy)
+//   - drop alternate greetings beyond the first policy.KeepGreetings (last first)
+//   - drop example dialogues from the end of mes_example (whole <START> blocks, the text before the first <START>
+//     counts as a dialogue); the remaining dialogues keep their original text
+//
+// The name, first_mes and system_prompt are never modified, and the original content is untouched
+func (c *Content) FitPromptWithin(budget int, tok Tokenizer, policy PromptFitPolicy) (*Content, FitReport) {
+	// Deep copy the content
+	trimmed := c.Clone()
+	report := FitReport{Budget: budget, TokensBefore: trimmed.promptTokens(tok)}
+	total := report.TokensBefore
+
+	// Compress the repeated whitespace in the description
+	if policy.CompressWhitespace && total > budget {
+		original := string(trimmed.Description)
+		compressed := inlineWhitespaceRegex.ReplaceAllString(original, " ")
+		compressed = blankLinesRegex.ReplaceAllString(compressed, "\n\n")
+		if compressed != original {
+			saved := tok.Count(original) - tok.Count(compressed)
+			trimmed.Description = property.String(compressed)
+			total -= saved
+			report.Removals = append(report.Removals, FitRemoval{Field: DescriptionField, Index: -1, Text: original, Tokens: saved})
+		}
+	}
+
+	// Drop the alternate greetings beyond the first N (last first)
+	for len(trimmed.AlternateGreetings) > max(policy.KeepGreetings, 0) && total > budget {
+		index := len(trimmed.AlternateGreetings) - 1
+		greeting := trimmed.AlternateGreetings[index]
+		trimmed.AlternateGreetings = trimmed.AlternateGreetings[:index]
+		saved := tok.Count(greeting)
+		total -= saved
+		report.Removals = append(report.Removals, FitRemoval{Field: AlternateGreetingsField, Index: index, Text: greeting, Tokens: saved})
+	}
+
+	// Drop the example dialogues from the end (the text is cut right before the dialogue)
+	if total > budget && strings.TrimSpace(string(trimmed.MessageExamples)) != "" {
+		examples := string(trimmed.MessageExamples)
+		starts := exampleDialogueStarts(examples)
+		for len(starts) > 0 && total > budget {
+			index := len(starts) - 1
+			start := starts[index]
+			starts = starts[:index]
+			remaining := strings.TrimRight(examples[:start], " \t\r\n")
+			// Recount the whole field, since separators are removed as well
+			saved := tok.Count(examples) - tok.Count(remaining)
+			total -= saved
+			report.Removals = append(report.Removals, FitRemoval{Field: MessageExamplesField, Index: index, Text: strings.TrimSpace(examples[start:]), Tokens: saved})
+			examples = remaining
+		}
+		trimmed.MessageExamples = property.String(examples)
+	}
+
+	// Return the trimmed copy and the report
+	report.TokensAfter = trimmed.promptTokens(tok)
+	return trimmed, report
+}
+
+// exampleDialogueStarts returns the offsets of the example dialogues of mes_example: every <START>, preceded by the
+// start of the text if there is text before the first <START>
+func exampleDialogueStarts(examples string) []int {
+	// The text before the first <START> (or the whole text without any) is a dialogue too
+	var starts []int
+	first := strings.Index(examples, ExampleSeparator)
+	if first < 0 || strings.TrimSpace(examples[:first]) != "" {
+		starts = append(starts, 0)
+	}
+
+	// Every <START> starts a dialogue
+	for offset := 0; ; offset += len(ExampleSeparator) {
+		index := strings.Index(examples[offset:], ExampleSeparator)
+		if index < 0 {
+			return starts
+		}
+		offset += index
+		starts = append(starts, offset)
+	}
+}
+
+// promptTokens returns the number of tokens of the prompt fields (including every alternate greeting)
+func (c *Content) promptTokens(tok Tokenizer) int {
+	total := 0
+	for _, text := range []string{
+		string(c.Name), string(c.Description), string(c.Personality), string(c.Scenario), string(c.FirstMessage),
+		string(c.MessageExamples), string(c.SystemPrompt), string(c.PostHistoryInstructions), c.DepthPrompt.Prompt,
+	} {
+		total += tok.Count(text)
+	}
+	for _, greeting := range c.AlternateGreetings {
+		total += tok.Count(greeting)
+	}
+	return total
+}
@@ -0,0 +1,186 @@
+package character
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/r3dpixel/card-parser/property"
+	"github.com/stretchr/testify/assert"
+)
+
+// wordTokenizer counts one token per whitespace separated word
+var wordTokenizer = TokenizerFunc(func(text string) int { return len(strings.Fields(text)) })
+
+// byteTokenizer counts one token per byte
+var byteTokenizer = TokenizerFunc(func(text string) int { return len(text) })
+
+func createPromptContent() *Content {
+	return &Content{
+		Name:               "Ann",
+		Description:        "Kind girl",
+		FirstMessage:       "Hello there",
+		SystemPrompt:       "Be nice",
+		AlternateGreetings: property.StringArray{"Hi you", "Hey there friend", "Yo"},
+		MessageExamples:    "<START>\n{{user}}: How are you\n{{char}}: Fine thanks\n<START>\n{{user}}: Bye\n{{char}}: See you soon",
+	}
+}
+
+func TestContent_FitPromptWithin(t *testing.T) {
+	tests := []struct {
+		name             string
+		budget           int
+		policy           PromptFitPolicy
+		expectedAfter    int
+		expectedFits     bool
+		expectedGreeting property.StringArray
+		expectedExamples property.String
+		expectedRemovals []FitRemoval
+	}{
+		{
+			name:             "Already fits",
+			budget:           100,
+			policy:           PromptFitPolicy{KeepGreetings: 1},
+			expectedAfter:    28,
+			expectedFits:     true,
+			expectedGreeting: property.StringArray{"Hi you", "Hey there friend", "Yo"},
+			expectedExamples: createPromptContent().MessageExamples,
+		},
+		{
+			name:             "Greetings only",
+			budget:           24,
+			policy:           PromptFitPolicy{KeepGreetings: 1},
+			expectedAfter:    24,
+			expectedFits:     true,
+			expectedGreeting: property.StringArray{"Hi you"},
+			expectedExamples: createPromptContent().MessageExamples,
+			expectedRemovals: []FitRemoval{
+				{Field: AlternateGreetingsField, Index: 2, Text: "Yo", Tokens: 1},
+				{Field: AlternateGreetingsField, Index: 1, Text: "Hey there friend", Tokens: 3},
+			},
+		},
+		{
+			name:             "Greetings and examples",
+			budget:           18,
+			policy:           PromptFitPolicy{KeepGreetings: 1},
+			expectedAfter:    17,
+			expectedFits:     true,
+			expectedGreeting: property.StringArray{"Hi you"},
+			expectedExamples: "<START>\n{{user}}: How are you\n{{char}}: Fine thanks",
+			expectedRemovals: []FitRemoval{
+				{Field: AlternateGreetingsField, Index: 2, Text: "Yo", Tokens: 1},
+				{Field: AlternateGreetingsField, Index: 1, Text: "Hey there friend", Tokens: 3},
+				{Field: MessageExamplesField, Index: 1, Text: "<START>\n{{user}}: Bye\n{{char}}: See you soon", Tokens: 7},
+			},
+		},
+		{
+			name:             "Unreachable budget",
+			budget:           3,
+			policy:           PromptFitPolicy{KeepGreetings: 1},
+			expectedAfter:    9,
+			expectedFits:     false,
+			expectedGreeting: property.StringArray{"Hi you"},
+			expectedExamples: "",
+			expectedRemovals: []FitRemoval{
+				{Field: AlternateGreetingsField, Index: 2, Text: "Yo", Tokens: 1},
+				{Field: AlternateGreetingsField, Index: 1, Text: "Hey there friend", Tokens: 3},
+				{Field: MessageExamplesField, Index: 1, Text: "<START>\n{{user}}: Bye\n{{char}}: See you soon", Tokens: 7},
+				{Field: MessageExamplesField, Index: 0, Text: "<START>\n{{user}}: How are you\n{{char}}: Fine thanks", Tokens: 8},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			original := createPromptContent()
+
+			trimmed, report := original.FitPromptWithin(tt.budget, wordTokenizer, tt.policy)
+
+			// Report
+			assert.Equal(t, tt.budget, report.Budget)
+			assert.Equal(t, 28, report.TokensBefore)
+			assert.Equal(t, tt.expectedAfter, report.TokensAfter)
+			assert.Equal(t, tt.expectedFits, report.Fits())
+			assert.Equal(t, tt.expectedRemovals, report.Removals)
+
+			// Trimmed content
+			assert.Equal(t, tt.expectedGreeting, trimmed.AlternateGreetings)
+			assert.Equal(t, tt.expectedExamples, trimmed.MessageExamples)
+			assert.Equal(t, property.String("Ann"), trimmed.Name)
+			assert.Equal(t, property.String("Hello there"), trimmed.FirstMessage)
+			assert.Equal(t, property.String("Be nice"), trimmed.SystemPrompt)
+
+			// The original is untouched
+			assert.Equal(t, createPromptContent(), original)
+		})
+	}
+}
+
+func TestContent_FitPromptWithin_ExampleBlocks(t *testing.T) {
+	tests := []struct {
+		name     string
+		examples property.String
+		budget   int
+		expected property.String
+		indexes  []int
+	}{
+		{
+			name:     "Untouched blocks keep their text",
+			examples: "<START>\r\n{{user}}:   Hi\r\n\r\n<START>\n{{user}}: Bye",
+			budget:   3,
+			expected: "<START>\r\n{{user}}:   Hi",
+			indexes:  []int{1},
+		},
+		{
+			name:     "Leading text is not prefixed with <START>",
+			examples: "Ann greets everyone.\n<START>\n{{user}}: Bye",
+			budget:   3,
+			expected: "Ann greets everyone.",
+			indexes:  []int{1},
+		},
+		{
+			name:     "Leading text is dropped last",
+			examples: "Ann greets everyone.\n<START>\n{{user}}: Bye",
+			budget:   0,
+			expected: "",
+			indexes:  []int{1, 0},
+		},
+		{
+			name:     "Text without <START>",
+			examples: "{{user}}: Hi\n{{char}}: Hello",
+			budget:   0,
+			expected: "",
+			indexes:  []int{0},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			trimmed, report := (&Content{MessageExamples: tt.examples}).FitPromptWithin(tt.budget, wordTokenizer, PromptFitPolicy{})
+			assert.Equal(t, tt.expected, trimmed.MessageExamples)
+			indexes := make([]int, 0, len(report.Removals))
+			for _, removal := range report.Removals {
+				indexes = append(indexes, removal.Index)
+			}
+			assert.Equal(t, tt.indexes, indexes)
+		})
+	}
+}
+
+func TestContent_FitPromptWithin_CompressWhitespace(t *testing.T) {
+	original := &Content{Description: "A   kind\n\n\n\ngirl"}
+
+	t.Run("Enabled", func(t *testing.T) {
+		trimmed, report := original.FitPromptWithin(12, byteTokenizer, PromptFitPolicy{CompressWhitespace: true})
+		assert.Equal(t, property.String("A kind\n\ngirl"), trimmed.Description)
+		assert.Equal(t, []FitRemoval{{Field: DescriptionField, Index: -1, Text: "A   kind\n\n\n\ngirl", Tokens: 4}}, report.Removals)
+		assert.True(t, report.Fits())
+		assert.Equal(t, property.String("A   kind\n\n\n\ngirl"), original.Description)
+	})
+
+	t.Run("Disabled", func(t *testing.T) {
+		trimmed, report := original.FitPromptWithin(12, byteTokenizer, PromptFitPolicy{})
+		assert.Equal(t, original.Description, trimmed.Description)
+		assert.Empty(t, report.Removals)
+		assert.False(t, report.Fits())
+	})
+}
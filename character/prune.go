@@ -0,0 +1,116 @@
+package character
+
+import (
+	"github.com/r3dpixel/card-parser/internal/codec"
+	"github.com/r3dpixel/card-parser/property"
+	"github.com/r3dpixel/toolkit/timestamp"
+)
+
+// PrunedFieldsKey is the extension key under which PruneForRevision stashes the pruned V3 fields
+const PrunedFieldsKey = "card_parser_v3_fields"
+
+// PruneOptions options of Sheet.PruneForRevision
+type PruneOptions struct {
+	// Stash moves the pruned values into the extensions (under PrunedFieldsKey) instead of deleting them
+	Stash bool
+}
+
+// v2Content marshals a content in the V2 layout: the V3 only fields are omitted when empty
+type v2Content Content
+
+// MarshalJSON marshals the content, omitting the empty V3 only fields
+func (c *v2Content) MarshalJSON() ([]byte, error) {
+	content := (*Content)(c).marshaledCopy()
+	// The V3 only fields that are not omitted when empty are shadowed
+	data, err := codec.Marshal(&struct {
+		*contentAlias
+		Nickname         property.String   `json:"nickname,omitzero"`
+		CreationDate     timestamp.Seconds `json:"creation_date,omitzero"`
+		ModificationDate timestamp.Seconds `json:"modification_date,omitzero"`
+	}{(*contentAlias)(content), content.Nickname, content.CreationDate, content.ModificationDate})
+	if err != nil {
+		return nil, err
+	}
+	return (*Content)(c).appendPendingBook(data), nil
+}
+
+// v3Field a field of the V3 spec that is not part of the V2 spec
+type v3Field struct {
+	name string
+	move func(dst *Content, src *Content)
+}
+
+// v3Fields are the V3 only fields pruned from V2 sheets
+var v3Fields = []v3Field{
+	{AssetsField, func(dst *Content, src *Content) { dst.Assets = src.Assets }},
+	{NicknameField, func(dst *Content, src *Content) { dst.Nickname = src.Nickname }},
+	{CreatorNotesMultilingualField, func(dst *Content, src *Content) { dst.CreatorNotesMultilingual = src.CreatorNotesMultilingual }},
+	{SourceField, func(dst *Content, src *Content) { dst.Source = src.Source }},
+	{GroupGreetingsField, func(dst *Content, src *Content) { dst.GroupGreetings = src.GroupGreetings }},
+	{CreationDateField, func(dst *Content, src *Content) { dst.CreationDate = src.CreationDate }},
+	{ModificationDateField, func(dst *Content, src *Content) { dst.ModificationDate = src.ModificationDate }},
+}
+
+// PruneForRevision makes the sheet clean for its revision
+// V2 sheets lose the V3 only fields (assets, nickname, multilingual creator notes, source, group only greetings,
+// creation and modification dates), which are stashed in the extensions if requested;
+// V3 sheets get back the fields stashed by an earlier pruning
+func (s *Sheet) PruneForRevision(opts PruneOptions) error {
+	if s.Revision >= RevisionV3 {
+		return s.restorePrunedFields()
+	}
+
+	// Stash the used V3 fields
+	if opts.Stash {
+		data, err := contentMap(&s.Content)
+		if err != nil {
+			return err
+		}
+		stash := make(map[string]any)
+		for _, field := range v3Fields {
+			if isUsed(data[field.name]) {
+				stash[field.name] = data[field.name]
+			}
+		}
+		if len(stash) > 0 {
+			if s.Extensions == nil {
+				s.Extensions = make(map[string]any)
+			}
+			s.Extensions[PrunedFieldsKey] = stash
+		}
+	}
+
+	// Clear the V3 fields
+	for _, field := range v3Fields {
+		field.move(&s.Content, &Content{})
+	}
+	return nil
+}
+
+// restorePrunedFields moves the fields stashed by PruneForRevision back into the content
+func (s *Sheet) restorePrunedFields() error {
+	// Skip if there is no stash
+	stash, ok := s.Extensions[PrunedFieldsKey].(map[string]any)
+	if !ok {
+		return nil
+	}
+
+	// Decode the stashed fields
+	data, err := codec.Marshal(stash)
+	if err != nil {
+		return err
+	}
+	var stashed Content
+	if err := codec.Unmarshal(data, &stashed); err != nil {
+		return err
+	}
+
+	// Restore the stashed fields, and remove the stash
+	for _, field := range v3Fields {
+		if _, ok := stash[field.name]; ok {
+			field.move(&s.Content, &stashed)
+		}
+	}
+	delete(s.Extensions, PrunedFieldsKey)
+	return nil
+}
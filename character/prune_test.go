@@ -0,0 +1,71 @@
+package character
+
+import (
+	"testing"
+
+	"github.com/r3dpixel/card-parser/property"
+	"github.com/r3dpixel/toolkit/sonicx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// prunedSheet returns a V3 sheet using every V3 only field
+func prunedSheet(t *testing.T) *Sheet {
+	sheet, err := FromBytes([]byte(comprehensiveSheetJSON))
+	require.NoError(t, err)
+	sheet.Assets = []Asset{DefaultAsset()}
+	sheet.CreatorNotesMultilingual = map[string]property.String{"fr": "Notes"}
+	sheet.Source = property.StringArray{"https://example.com/alice"}
+	sheet.GroupGreetings = property.StringArray{"Hello everyone!"}
+	return sheet
+}
+
+func TestSheet_PruneForRevision(t *testing.T) {
+	t.Run("Stash round trip", func(t *testing.T) {
+		original := prunedSheet(t)
+		sheet := prunedSheet(t)
+
+		// Downgrade to V2
+		sheet.SetRevision(RevisionV2)
+		require.NoError(t, sheet.PruneForRevision(PruneOptions{Stash: true}))
+		data, err := sheet.ToBytes()
+		require.NoError(t, err)
+		var encoded struct {
+			Data map[string]any `json:"data"`
+		}
+		require.NoError(t, sonicx.Config.Unmarshal(data, &encoded))
+		stash := encoded.Data[ExtensionsField].(map[string]any)[PrunedFieldsKey].(map[string]any)
+		for _, field := range v3Fields {
+			assert.NotContains(t, encoded.Data, field.name)
+			assert.Contains(t, stash, field.name)
+		}
+
+		// Upgrade back to V3
+		decoded, err := FromBytes(data)
+		require.NoError(t, err)
+		decoded.SetRevision(RevisionV3)
+		require.NoError(t, decoded.PruneForRevision(PruneOptions{}))
+		assert.NotContains(t, decoded.Extensions, PrunedFieldsKey)
+		assert.Empty(t, original.Diff(decoded))
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		sheet := prunedSheet(t)
+		sheet.SetRevision(RevisionV2)
+		require.NoError(t, sheet.PruneForRevision(PruneOptions{}))
+		assert.Empty(t, sheet.Assets)
+		assert.Empty(t, sheet.Nickname)
+		assert.Empty(t, sheet.CreatorNotesMultilingual)
+		assert.Empty(t, sheet.Source)
+		assert.Empty(t, sheet.GroupGreetings)
+		assert.Zero(t, sheet.CreationDate)
+		assert.Zero(t, sheet.ModificationDate)
+		assert.NotContains(t, sheet.Extensions, PrunedFieldsKey)
+	})
+
+	t.Run("V3 without stash", func(t *testing.T) {
+		sheet := prunedSheet(t)
+		require.NoError(t, sheet.PruneForRevision(PruneOptions{Stash: true}))
+		assert.True(t, prunedSheet(t).DeepEquals(sheet))
+	})
+}
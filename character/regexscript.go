@@ -0,0 +1,288 @@
+package character
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"math"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// SillyTavern regex scripts extension keys
+const (
+	RegexScriptsKey            string = "regex_scripts"
+	regexScriptIDKey           string = "id"
+	regexScriptNameKey         string = "scriptName"
+	regexScriptFindKey         string = "findRegex"
+	regexScriptReplaceKey      string = "replaceString"
+	regexScriptTrimStringsKey  string = "trimStrings"
+	regexScriptPlacementKey    string = "placement"
+	regexScriptDisabledKey     string = "disabled"
+	regexScriptMarkdownOnlyKey string = "markdownOnly"
+	regexScriptPromptOnlyKey   string = "promptOnly"
+	regexScriptRunOnEditKey    string = "runOnEdit"
+)
+
+// JS regex flags of the find regex
+const (
+	supportedJSFlags   string = "dgimsu" // Flags with a Go equivalent (or without effect on the matching)
+	unsupportedJSFlags string = "vy"     // Flags without a Go equivalent (unicode sets, sticky)
+)
+
+// RegexPlacement where a SillyTavern regex script applies
+type RegexPlacement int
+
+// Allowed RegexPlacement values
+const (
+	RegexPlacementUserInput    RegexPlacement = 1 // User messages
+	RegexPlacementAIOutput     RegexPlacement = 2 // Character messages
+	RegexPlacementSlashCommand RegexPlacement = 3 // Slash commands
+	RegexPlacementWorldInfo    RegexPlacement = 5 // World info entries
+	RegexPlacementReasoning    RegexPlacement = 6 // Reasoning blocks
+)
+
+// ErrIncompatibleRegex is returned (joined for every problem) when the find regex of a script cannot be used in Go
+var ErrIncompatibleRegex = errors.New("incompatible regex script")
+
+// RegexScript SillyTavern regex script (an element of extensions.regex_scripts)
+type RegexScript struct {
+	ID            string           `json:"id"`
+	ScriptName    string           `json:"scriptName"`
+	FindRegex     string           `json:"findRegex"`     // JS regex literal (/pattern/flags) or bare pattern
+	ReplaceString string           `json:"replaceString"` // Replacement ({{match}} and $1 style references)
+	TrimStrings   []string         `json:"trimStrings"`   // Strings removed from the matches before the replacement
+	Placement     []RegexPlacement `json:"placement"`     // Where the script applies
+	Disabled      bool             `json:"disabled"`
+	MarkdownOnly  bool             `json:"markdownOnly"` // Only alters the displayed messages
+	PromptOnly    bool             `json:"promptOnly"`   // Only alters the prompt
+	RunOnEdit     bool             `json:"runOnEdit"`
+	Extra         map[string]any   `json:"extra"` // Other members of the script (e.g. substituteRegex, minDepth, maxDepth)
+}
+
+// Validate compiles the find regex with the Go regexp package (JS regex literals are translated, see TranslateJSRegex)
+// Returns the joined incompatibilities (nil if the script can be used in Go): a blank or non-compiling regex (e.g.
+// lookarounds and backreferences are not supported), and the flags without a Go equivalent (v and y)
+func (s RegexScript) Validate() error {
+	// A blank regex matches nothing in SillyTavern
+	if strings.TrimSpace(s.FindRegex) == "" {
+		return fmt.Errorf("%w: %q: blank find regex", ErrIncompatibleRegex, s.ScriptName)
+	}
+
+	// Check the flags of the JS regex literal
+	var errs []error
+	if match := jsRegexLiteral.FindStringSubmatch(strings.TrimSpace(s.FindRegex)); match != nil {
+		for _, flag := range match[2] {
+			switch {
+			case strings.ContainsRune(unsupportedJSFlags, flag):
+				errs = append(errs, fmt.Errorf("%w: %q: flag %q is not supported", ErrIncompatibleRegex, s.ScriptName, flag))
+			case !strings.ContainsRune(supportedJSFlags, flag):
+				errs = append(errs, fmt.Errorf("%w: %q: unknown flag %q", ErrIncompatibleRegex, s.ScriptName, flag))
+			}
+		}
+	}
+
+	// Compile the regex
+	if _, err := regexp.Compile(TranslateJSRegex(s.FindRegex)); err != nil {
+		errs = append(errs, fmt.Errorf("%w: %q: %v", ErrIncompatibleRegex, s.ScriptName, err))
+	}
+	return errors.Join(errs...)
+}
+
+// Clone returns a deep copy of the script (the trim strings, placement and extra members are not shared)
+func (s RegexScript) Clone() RegexScript {
+	s.TrimStrings = slices.Clone(s.TrimStrings)
+	s.Placement = slices.Clone(s.Placement)
+	s.Extra = cloneMap(s.Extra)
+	return s
+}
+
+// DisableRegexScripts disables every regex script, and returns the number of scripts that were enabled
+func (c *Content) DisableRegexScripts() int {
+	disabled := 0
+	for index := range c.RegexScripts {
+		if !c.RegexScripts[index].Disabled {
+			c.RegexScripts[index].Disabled = true
+			disabled++
+		}
+	}
+	return disabled
+}
+
+// ValidateRegexScripts returns the joined incompatibilities of the regex scripts (nil if every script can be used in Go)
+func (c *Content) ValidateRegexScripts() error {
+	var errs []error
+	for index, script := range c.RegexScripts {
+		if err := script.Validate(); err != nil {
+			errs = append(errs, fmt.Errorf("script %d: %w", index, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// cloneRegexScripts returns a deep copy of the regex scripts (nil if nil)
+func cloneRegexScripts(scripts []RegexScript) []RegexScript {
+	if scripts == nil {
+		return nil
+	}
+	clone := make([]RegexScript, len(scripts))
+	for index, script := range scripts {
+		clone[index] = script.Clone()
+	}
+	return clone
+}
+
+// insertRegexScripts returns a copy of the extensions with the regex scripts inserted
+// The given map is not modified (returned as is if there are no regex scripts)
+func (c *Content) insertRegexScripts(extensions map[string]any) map[string]any {
+	// Skip if no regex scripts
+	if c.RegexScripts == nil {
+		return extensions
+	}
+
+	// Copy the extensions map
+	extensions = maps.Clone(extensions)
+	if extensions == nil {
+		extensions = make(map[string]any)
+	}
+
+	// Populate the script objects with the typed values (over the other members)
+	scripts := make([]any, len(c.RegexScripts))
+	for index, script := range c.RegexScripts {
+		scriptMap := maps.Clone(script.Extra)
+		if scriptMap == nil {
+			scriptMap = make(map[string]any)
+		}
+		scriptMap[regexScriptIDKey] = script.ID
+		scriptMap[regexScriptNameKey] = script.ScriptName
+		scriptMap[regexScriptFindKey] = script.FindRegex
+		scriptMap[regexScriptReplaceKey] = script.ReplaceString
+		scriptMap[regexScriptTrimStringsKey] = append([]string{}, script.TrimStrings...)
+		scriptMap[regexScriptPlacementKey] = append([]RegexPlacement{}, script.Placement...)
+		scriptMap[regexScriptDisabledKey] = script.Disabled
+		scriptMap[regexScriptMarkdownOnlyKey] = script.MarkdownOnly
+		scriptMap[regexScriptPromptOnlyKey] = script.PromptOnly
+		scriptMap[regexScriptRunOnEditKey] = script.RunOnEdit
+		scripts[index] = scriptMap
+	}
+	extensions[RegexScriptsKey] = scripts
+
+	// Return the extensions
+	return extensions
+}
+
+// extractRegexScripts extracts the regex scripts extension and populates the RegexScripts field
+// Reverse of the insertRegexScripts method (a malformed extension is left in the Extensions map as is)
+func (c *Content) extractRegexScripts() {
+	// Type the script objects (skipped if any script is malformed)
+	scripts, ok := parseRegexScripts(c.Extensions[RegexScriptsKey])
+	if !ok {
+		return
+	}
+	c.RegexScripts = scripts
+
+	// Remove the regex scripts from the Extensions map
+	delete(c.Extensions, RegexScriptsKey)
+	if len(c.Extensions) == 0 {
+		c.Extensions = nil
+	}
+}
+
+// parseRegexScripts parses the script objects (false if any script is not an object, or has a malformed known member)
+func parseRegexScripts(value any) ([]RegexScript, bool) {
+	objects, ok := value.([]any)
+	if !ok {
+		return nil, false
+	}
+	scripts := make([]RegexScript, 0, len(objects))
+	for _, object := range objects {
+		scriptMap, ok := object.(map[string]any)
+		if !ok {
+			return nil, false
+		}
+		var script RegexScript
+		strs := map[string]*string{
+			regexScriptIDKey:      &script.ID,
+			regexScriptNameKey:    &script.ScriptName,
+			regexScriptFindKey:    &script.FindRegex,
+			regexScriptReplaceKey: &script.ReplaceString,
+		}
+		flags := map[string]*bool{
+			regexScriptDisabledKey:     &script.Disabled,
+			regexScriptMarkdownOnlyKey: &script.MarkdownOnly,
+			regexScriptPromptOnlyKey:   &script.PromptOnly,
+			regexScriptRunOnEditKey:    &script.RunOnEdit,
+		}
+		for key, member := range scriptMap {
+			// Type the known members
+			if target, known := strs[key]; known {
+				if *target, ok = member.(string); !ok {
+					return nil, false
+				}
+				continue
+			}
+			if target, known := flags[key]; known {
+				if *target, ok = member.(bool); !ok {
+					return nil, false
+				}
+				continue
+			}
+			switch key {
+			case regexScriptTrimStringsKey:
+				if script.TrimStrings, ok = parseStrings(member); !ok {
+					return nil, false
+				}
+			case regexScriptPlacementKey:
+				if script.Placement, ok = parsePlacements(member); !ok {
+					return nil, false
+				}
+			default:
+				// Keep the other members
+				if script.Extra == nil {
+					script.Extra = make(map[string]any)
+				}
+				script.Extra[key] = member
+			}
+		}
+		scripts = append(scripts, script)
+	}
+	return scripts, true
+}
+
+// parseStrings parses an array of strings (false if any element is not a string)
+func parseStrings(value any) ([]string, bool) {
+	elements, ok := value.([]any)
+	if !ok {
+		return nil, false
+	}
+	strs := make([]string, len(elements))
+	for index, element := range elements {
+		if strs[index], ok = element.(string); !ok {
+			return nil, false
+		}
+	}
+	return strs, true
+}
+
+// parsePlacements parses an array of placements (false if any element is not an integer)
+func parsePlacements(value any) ([]RegexPlacement, bool) {
+	elements, ok := value.([]any)
+	if !ok {
+		return nil, false
+	}
+	placements := make([]RegexPlacement, len(elements))
+	for index, element := range elements {
+		number, isNumber := element.(float64)
+		if jsonNumber, ok := element.(json.Number); ok {
+			parsed, err := jsonNumber.Float64()
+			number, isNumber = parsed, err == nil
+		}
+		if !isNumber || number != math.Trunc(number) {
+			return nil, false
+		}
+		placements[index] = RegexPlacement(number)
+	}
+	return placements, true
+}
@@ -0,0 +1,180 @@
+package character
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const regexSheetJSON = `{
+	"spec": "chara_card_v3",
+	"spec_version": "3.0",
+	"data": {
+		"name": "Regex",
+		"extensions": {
+			"fav": true,
+			"regex_scripts": [
+				{
+					"id": "1f0c", "scriptName": "Hide thoughts", "findRegex": "/<thinking>[\\s\\S]*?<\\/thinking>/gi",
+					"replaceString": "", "trimStrings": [], "placement": [2], "disabled": false,
+					"markdownOnly": true, "promptOnly": false, "runOnEdit": true, "substituteRegex": 0, "minDepth": null
+				},
+				{"scriptName": "Rename", "findRegex": "Bob", "replaceString": "Robert", "placement": [1, 2]}
+			]
+		}
+	}
+}`
+
+func TestContent_RegexScripts(t *testing.T) {
+	sheet, err := FromBytes([]byte(regexSheetJSON))
+	require.NoError(t, err)
+
+	// The scripts are typed (the other members are kept with the script)
+	assert.Equal(t, []RegexScript{
+		{
+			ID: "1f0c", ScriptName: "Hide thoughts", FindRegex: `/<thinking>[\s\S]*?<\/thinking>/gi`, TrimStrings: []string{},
+			Placement: []RegexPlacement{RegexPlacementAIOutput}, MarkdownOnly: true, RunOnEdit: true,
+			Extra: map[string]any{"substituteRegex": 0.0, "minDepth": nil},
+		},
+		{ScriptName: "Rename", FindRegex: "Bob", ReplaceString: "Robert", Placement: []RegexPlacement{RegexPlacementUserInput, RegexPlacementAIOutput}},
+	}, sheet.RegexScripts)
+
+	// The unknown keys stay in the map
+	assert.Equal(t, map[string]any{"fav": true}, sheet.Extensions)
+}
+
+func TestSheet_MarshalRegexScriptsNonDestructively(t *testing.T) {
+	t.Run("regex scripts with existing keys", func(t *testing.T) {
+		sheet, err := FromBytes([]byte(regexSheetJSON))
+		require.NoError(t, err)
+
+		jsonBytes, err := sheet.ToBytes()
+		require.NoError(t, err)
+		unmarshaledSheet, err := FromBytes(jsonBytes)
+		require.NoError(t, err)
+
+		assert.True(t, sheet.DeepEquals(unmarshaledSheet))
+		assert.Equal(t, true, unmarshaledSheet.Extensions["fav"])
+
+		// The absent known members are written with their default values
+		assert.Equal(t, []string{}, unmarshaledSheet.RegexScripts[1].TrimStrings)
+		sheet.RegexScripts[1].TrimStrings = []string{}
+		assert.Equal(t, sheet.RegexScripts, unmarshaledSheet.RegexScripts)
+		assert.Contains(t, string(jsonBytes), `"substituteRegex":0`)
+		assert.Contains(t, string(jsonBytes), `"minDepth":null`)
+
+		// The marshaling does not modify the sheet
+		assert.Equal(t, map[string]any{"fav": true}, sheet.Extensions)
+	})
+
+	t.Run("regex scripts without other keys", func(t *testing.T) {
+		sheet := DefaultSheet(RevisionV2)
+		sheet.RegexScripts = []RegexScript{{ScriptName: "Rename", FindRegex: "Bob"}}
+
+		jsonBytes, err := sheet.ToBytes()
+		require.NoError(t, err)
+		assert.Contains(t, string(jsonBytes), `"placement":[]`)
+		assert.Contains(t, string(jsonBytes), `"trimStrings":[]`)
+		unmarshaledSheet, err := FromBytes(jsonBytes)
+		require.NoError(t, err)
+
+		assert.Equal(t, "Rename", unmarshaledSheet.RegexScripts[0].ScriptName)
+		assert.Nil(t, unmarshaledSheet.Extensions)
+	})
+
+	t.Run("empty regex scripts", func(t *testing.T) {
+		sheet, err := FromBytes([]byte(`{"spec":"chara_card_v3","spec_version":"3.0","data":{"name":"Regex","extensions":{"regex_scripts":[]}}}`))
+		require.NoError(t, err)
+		assert.Equal(t, []RegexScript{}, sheet.RegexScripts)
+
+		jsonBytes, err := sheet.ToBytes()
+		require.NoError(t, err)
+		assert.Contains(t, string(jsonBytes), `"extensions":{"regex_scripts":[]}`)
+	})
+
+	t.Run("malformed scripts stay untyped", func(t *testing.T) {
+		sheet, err := FromBytes([]byte(`{"spec":"chara_card_v3","spec_version":"3.0","data":{"name":"Regex","extensions":{"regex_scripts":[{"scriptName":"A"},{"findRegex":1}]}}}`))
+		require.NoError(t, err)
+		assert.Nil(t, sheet.RegexScripts)
+
+		jsonBytes, err := sheet.ToBytes()
+		require.NoError(t, err)
+		assert.Contains(t, string(jsonBytes), `"regex_scripts":[{"scriptName":"A"},{"findRegex":1}]`)
+	})
+}
+
+func TestRegexScript_Validate(t *testing.T) {
+	tests := []struct {
+		name      string
+		findRegex string
+		problems  []string
+	}{
+		{"Bare pattern", `\bBob\b`, nil},
+		{"JS literal with supported flags", `/<thinking>[\s\S]*?<\/thinking>/gims`, nil},
+		{"Blank", "  ", []string{"blank find regex"}},
+		{"Lookahead", `/foo(?=bar)/g`, []string{"invalid or unsupported Perl syntax"}},
+		{"Backreference", `(a)\1`, []string{"invalid escape sequence"}},
+		{"Sticky and unicode sets flags", `/foo/yv`, []string{`flag 'y' is not supported`, `flag 'v' is not supported`}},
+		{"Unknown flag", `/foo/x`, []string{`unknown flag 'x'`}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := RegexScript{ScriptName: "Script", FindRegex: tt.findRegex}.Validate()
+			if tt.problems == nil {
+				assert.NoError(t, err)
+				return
+			}
+			require.ErrorIs(t, err, ErrIncompatibleRegex)
+			for _, problem := range tt.problems {
+				assert.Contains(t, err.Error(), problem)
+			}
+		})
+	}
+
+	t.Run("Content", func(t *testing.T) {
+		content := &Content{RegexScripts: []RegexScript{{FindRegex: "ok"}, {FindRegex: "(?<=a)b"}}}
+		err := content.ValidateRegexScripts()
+		require.ErrorIs(t, err, ErrIncompatibleRegex)
+		assert.Contains(t, err.Error(), "script 1:")
+		assert.NotContains(t, err.Error(), "script 0:")
+		assert.NoError(t, (&Content{}).ValidateRegexScripts())
+	})
+}
+
+func TestContent_DisableRegexScripts(t *testing.T) {
+	sheet, err := FromBytes([]byte(regexSheetJSON))
+	require.NoError(t, err)
+	sheet.RegexScripts[1].Disabled = true
+
+	assert.Equal(t, 1, sheet.DisableRegexScripts())
+	for _, script := range sheet.RegexScripts {
+		assert.True(t, script.Disabled)
+	}
+	assert.Equal(t, 0, sheet.DisableRegexScripts())
+
+	// The disabled scripts are written back
+	jsonBytes, err := sheet.ToBytes()
+	require.NoError(t, err)
+	reparsed, err := FromBytes(jsonBytes)
+	require.NoError(t, err)
+	assert.True(t, reparsed.RegexScripts[0].Disabled)
+}
+
+func TestRegexScript_Clone(t *testing.T) {
+	original := &Content{RegexScripts: []RegexScript{{
+		TrimStrings: []string{"a"},
+		Placement:   []RegexPlacement{RegexPlacementAIOutput},
+		Extra:       map[string]any{"flags": []any{"x"}},
+	}}}
+	clone := original.Clone()
+	require.Equal(t, original.RegexScripts, clone.RegexScripts)
+
+	clone.RegexScripts[0].TrimStrings[0] = "b"
+	clone.RegexScripts[0].Placement[0] = RegexPlacementUserInput
+	clone.RegexScripts[0].Extra["flags"].([]any)[0] = "y"
+	assert.Equal(t, "a", original.RegexScripts[0].TrimStrings[0])
+	assert.Equal(t, RegexPlacementAIOutput, original.RegexScripts[0].Placement[0])
+	assert.Equal(t, "x", original.RegexScripts[0].Extra["flags"].([]any)[0])
+}
@@ -0,0 +1,304 @@
+package character
+
+import (
+	"encoding/json"
+	"maps"
+	"slices"
+
+	"github.com/r3dpixel/card-parser/property"
+	"github.com/r3dpixel/toolkit/stringsx"
+)
+
+// RisuAI extension keys
+const (
+	RisuKey                 string = "risuai"
+	RisuBiasKey             string = "bias"
+	RisuViewScreenKey       string = "viewScreen"
+	RisuCustomScriptsKey    string = "customScripts"
+	RisuAdditionalAssetsKey string = "additionalAssets"
+	risuScriptCommentKey    string = "comment"
+	risuScriptInKey         string = "in"
+	risuScriptOutKey        string = "out"
+	risuScriptTypeKey       string = "type"
+)
+
+// RisuExtensions typed RisuAI extension block (extensions.risuai) of a chara card
+// Only the well-formed known keys are typed, the other keys of the block stay in the Extensions map
+type RisuExtensions struct {
+	Bias             []RisuBias   `json:"bias"`             // Token biases
+	ViewScreen       string       `json:"viewScreen"`       // View screen mode (e.g. none, emotion, imggen)
+	CustomScripts    []RisuScript `json:"customScripts"`    // Regex scripts
+	AdditionalAssets []RisuAsset  `json:"additionalAssets"` // Additional assets (V2 cards exported by RisuAI)
+}
+
+// RisuBias token bias of a RisuAI card (encoded as a [text, weight] pair)
+type RisuBias struct {
+	Text   string  `json:"text"`
+	Weight float64 `json:"weight"`
+}
+
+// RisuScript regex script of a RisuAI card
+type RisuScript struct {
+	Comment string         `json:"comment"`
+	In      string         `json:"in"`
+	Out     string         `json:"out"`
+	Type    string         `json:"type"`
+	Extra   map[string]any `json:"extra"` // Other members of the script (e.g. flag, ableFlag)
+}
+
+// RisuAsset additional asset of a RisuAI card (encoded as a [name, uri, ext] triple)
+type RisuAsset struct {
+	Name      string `json:"name"`
+	URI       string `json:"uri"`
+	Extension string `json:"ext"`
+}
+
+// IsEmpty checks if the RisuAI extensions carry no value
+func (r *RisuExtensions) IsEmpty() bool {
+	return r == nil || (r.Bias == nil && stringsx.IsBlank(r.ViewScreen) && r.CustomScripts == nil && r.AdditionalAssets == nil)
+}
+
+// Clone returns a deep copy of the RisuAI extensions (nil if nil)
+func (r *RisuExtensions) Clone() *RisuExtensions {
+	if r == nil {
+		return nil
+	}
+	clone := *r
+	clone.Bias = slices.Clone(r.Bias)
+	clone.AdditionalAssets = slices.Clone(r.AdditionalAssets)
+	if r.CustomScripts != nil {
+		clone.CustomScripts = make([]RisuScript, len(r.CustomScripts))
+		for index, script := range r.CustomScripts {
+			script.Extra = cloneMap(script.Extra)
+			clone.CustomScripts[index] = script
+		}
+	}
+	return &clone
+}
+
+// Asset returns the V3 asset of the additional asset (blank name and extension are derived from the URI)
+func (a RisuAsset) Asset() Asset {
+	asset := Asset{
+		Type:      property.OtherAsset,
+		URI:       property.String(a.URI),
+		Name:      property.String(a.Name),
+		Extension: property.String(a.Extension),
+	}
+	asset.FillDefaults()
+	return asset
+}
+
+// ImportRisuAssets moves the RisuAI additional assets into the V3 assets, and returns the number of imported assets
+// Assets whose URI is already present are not duplicated; the RisuAI extensions are dropped once empty
+func (c *Content) ImportRisuAssets() int {
+	// Skip if there are no additional assets
+	if c.Risu == nil {
+		return 0
+	}
+
+	// Append the assets not already present
+	imported := 0
+	for _, risuAsset := range c.Risu.AdditionalAssets {
+		asset := risuAsset.Asset()
+		if slices.ContainsFunc(c.Assets, func(existing Asset) bool { return existing.URI == asset.URI }) {
+			continue
+		}
+		c.Assets = append(c.Assets, asset)
+		imported++
+	}
+
+	// Drop the moved assets
+	c.Risu.AdditionalAssets = nil
+	if c.Risu.IsEmpty() {
+		c.Risu = nil
+	}
+	return imported
+}
+
+// insertRisuExtensions returns a copy of the extensions with the RisuAI extension block inserted
+// The given map is not modified (returned as is if there are no RisuAI extensions)
+func (c *Content) insertRisuExtensions(extensions map[string]any) map[string]any {
+	// Skip if no RisuAI extensions
+	if c.Risu.IsEmpty() {
+		return extensions
+	}
+
+	// Copy the extensions map
+	extensions = maps.Clone(extensions)
+	if extensions == nil {
+		extensions = make(map[string]any)
+	}
+
+	// Copy the RisuAI map (if any)
+	risuMap, ok := extensions[RisuKey].(map[string]any)
+	if risuMap = maps.Clone(risuMap); !ok || risuMap == nil {
+		risuMap = make(map[string]any)
+	}
+	extensions[RisuKey] = risuMap
+
+	// Populate the RisuAI map with the typed values
+	if c.Risu.Bias != nil {
+		bias := make([]any, len(c.Risu.Bias))
+		for index, entry := range c.Risu.Bias {
+			bias[index] = []any{entry.Text, entry.Weight}
+		}
+		risuMap[RisuBiasKey] = bias
+	}
+	if stringsx.IsNotBlank(c.Risu.ViewScreen) {
+		risuMap[RisuViewScreenKey] = c.Risu.ViewScreen
+	}
+	if c.Risu.CustomScripts != nil {
+		scripts := make([]any, len(c.Risu.CustomScripts))
+		for index, script := range c.Risu.CustomScripts {
+			scriptMap := maps.Clone(script.Extra)
+			if scriptMap == nil {
+				scriptMap = make(map[string]any)
+			}
+			scriptMap[risuScriptCommentKey] = script.Comment
+			scriptMap[risuScriptInKey] = script.In
+			scriptMap[risuScriptOutKey] = script.Out
+			scriptMap[risuScriptTypeKey] = script.Type
+			scripts[index] = scriptMap
+		}
+		risuMap[RisuCustomScriptsKey] = scripts
+	}
+	if c.Risu.AdditionalAssets != nil {
+		assets := make([]any, len(c.Risu.AdditionalAssets))
+		for index, asset := range c.Risu.AdditionalAssets {
+			assets[index] = []any{asset.Name, asset.URI, asset.Extension}
+		}
+		risuMap[RisuAdditionalAssetsKey] = assets
+	}
+
+	// Return the extensions
+	return extensions
+}
+
+// extractRisuExtensions extracts the well-formed known keys of the RisuAI extension block and populates the Risu field
+// Reverse of the insertRisuExtensions method (malformed and unknown keys are left in the Extensions map)
+func (c *Content) extractRisuExtensions() {
+	// Skip if there is no RisuAI extension object
+	risuMap, ok := c.Extensions[RisuKey].(map[string]any)
+	if !ok {
+		return
+	}
+
+	// Type the well-formed known keys
+	risu := &RisuExtensions{}
+	if bias, ok := parseRisuBias(risuMap[RisuBiasKey]); ok {
+		risu.Bias = bias
+		delete(risuMap, RisuBiasKey)
+	}
+	if viewScreen, ok := risuMap[RisuViewScreenKey].(string); ok && stringsx.IsNotBlank(viewScreen) {
+		risu.ViewScreen = viewScreen
+		delete(risuMap, RisuViewScreenKey)
+	}
+	if scripts, ok := parseRisuScripts(risuMap[RisuCustomScriptsKey]); ok {
+		risu.CustomScripts = scripts
+		delete(risuMap, RisuCustomScriptsKey)
+	}
+	if assets, ok := parseRisuAssets(risuMap[RisuAdditionalAssetsKey]); ok {
+		risu.AdditionalAssets = assets
+		delete(risuMap, RisuAdditionalAssetsKey)
+	}
+	if risu.IsEmpty() {
+		return
+	}
+	c.Risu = risu
+
+	// Remove the RisuAI map if it is empty
+	if len(risuMap) == 0 {
+		delete(c.Extensions, RisuKey)
+	}
+	// Remove the Extensions map if it is empty
+	if len(c.Extensions) == 0 {
+		c.Extensions = nil
+	}
+}
+
+// parseRisuBias parses the [text, weight] pairs (false if any pair is malformed)
+func parseRisuBias(value any) ([]RisuBias, bool) {
+	pairs, ok := value.([]any)
+	if !ok {
+		return nil, false
+	}
+	bias := make([]RisuBias, 0, len(pairs))
+	for _, pair := range pairs {
+		values, ok := pair.([]any)
+		if !ok || len(values) != 2 {
+			return nil, false
+		}
+		text, isText := values[0].(string)
+		weight, isNumber := values[1].(float64)
+		if number, ok := values[1].(json.Number); ok {
+			parsed, err := number.Float64()
+			weight, isNumber = parsed, err == nil
+		}
+		if !isText || !isNumber {
+			return nil, false
+		}
+		bias = append(bias, RisuBias{Text: text, Weight: weight})
+	}
+	return bias, true
+}
+
+// parseRisuScripts parses the script objects (false if any script is not an object, or has a non-string known member)
+func parseRisuScripts(value any) ([]RisuScript, bool) {
+	objects, ok := value.([]any)
+	if !ok {
+		return nil, false
+	}
+	scripts := make([]RisuScript, 0, len(objects))
+	for _, object := range objects {
+		scriptMap, ok := object.(map[string]any)
+		if !ok {
+			return nil, false
+		}
+		var script RisuScript
+		targets := map[string]*string{
+			risuScriptCommentKey: &script.Comment,
+			risuScriptInKey:      &script.In,
+			risuScriptOutKey:     &script.Out,
+			risuScriptTypeKey:    &script.Type,
+		}
+		for key, member := range scriptMap {
+			// Type the known members
+			if target, known := targets[key]; known {
+				if *target, ok = member.(string); !ok {
+					return nil, false
+				}
+				continue
+			}
+			// Keep the other members
+			if script.Extra == nil {
+				script.Extra = make(map[string]any)
+			}
+			script.Extra[key] = member
+		}
+		scripts = append(scripts, script)
+	}
+	return scripts, true
+}
+
+// parseRisuAssets parses the [name, uri, ext] triples (false if any triple is malformed)
+func parseRisuAssets(value any) ([]RisuAsset, bool) {
+	triples, ok := value.([]any)
+	if !ok {
+		return nil, false
+	}
+	assets := make([]RisuAsset, 0, len(triples))
+	for _, triple := range triples {
+		values, ok := triple.([]any)
+		if !ok || len(values) < 2 || len(values) > 3 {
+			return nil, false
+		}
+		var fields [3]string
+		for index, member := range values {
+			if fields[index], ok = member.(string); !ok {
+				return nil, false
+			}
+		}
+		assets = append(assets, RisuAsset{Name: fields[0], URI: fields[1], Extension: fields[2]})
+	}
+	return assets, true
+}
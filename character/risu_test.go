@@ -0,0 +1,142 @@
+package character
+
+import (
+	"testing"
+
+	"github.com/r3dpixel/card-parser/property"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const risuSheetJSON = `{
+	"spec": "chara_card_v2",
+	"spec_version": "2.0",
+	"data": {
+		"name": "Risu",
+		"first_mes": "Hello",
+		"extensions": {
+			"fav": true,
+			"risuai": {
+				"bias": [["hello", 5], ["bye", -2.5]],
+				"viewScreen": "emotion",
+				"customScripts": [{"comment": "Swap", "in": "a", "out": "b", "type": "editoutput", "ableFlag": true}],
+				"additionalAssets": [["smile", "embeded://assets/smile.png", "png"], ["frown", "https://example.com/frown.webp", ""]],
+				"utilityBot": false
+			}
+		}
+	}
+}`
+
+func TestContent_RisuExtensions(t *testing.T) {
+	sheet, err := FromBytes([]byte(risuSheetJSON))
+	require.NoError(t, err)
+
+	// The known keys are typed
+	require.NotNil(t, sheet.Risu)
+	assert.Equal(t, []RisuBias{{Text: "hello", Weight: 5}, {Text: "bye", Weight: -2.5}}, sheet.Risu.Bias)
+	assert.Equal(t, "emotion", sheet.Risu.ViewScreen)
+	assert.Equal(t, []RisuScript{{Comment: "Swap", In: "a", Out: "b", Type: "editoutput", Extra: map[string]any{"ableFlag": true}}}, sheet.Risu.CustomScripts)
+	assert.Equal(t, []RisuAsset{
+		{Name: "smile", URI: "embeded://assets/smile.png", Extension: "png"},
+		{Name: "frown", URI: "https://example.com/frown.webp"},
+	}, sheet.Risu.AdditionalAssets)
+
+	// The unknown keys stay in the map
+	assert.Equal(t, map[string]any{"fav": true, RisuKey: map[string]any{"utilityBot": false}}, sheet.Extensions)
+}
+
+func TestSheet_MarshalRisuExtensionsNonDestructively(t *testing.T) {
+	t.Run("risu extensions with existing keys", func(t *testing.T) {
+		sheet, err := FromBytes([]byte(risuSheetJSON))
+		require.NoError(t, err)
+
+		jsonBytes, err := sheet.ToBytes()
+		require.NoError(t, err)
+		unmarshaledSheet, err := FromBytes(jsonBytes)
+		require.NoError(t, err)
+
+		assert.Equal(t, sheet.Risu, unmarshaledSheet.Risu)
+		assert.Equal(t, true, unmarshaledSheet.Extensions["fav"])
+		risuMap, ok := unmarshaledSheet.Extensions[RisuKey].(map[string]any)
+		require.True(t, ok)
+		assert.Equal(t, false, risuMap["utilityBot"])
+
+		// The marshaling does not modify the sheet
+		assert.Equal(t, map[string]any{"utilityBot": false}, sheet.Extensions[RisuKey])
+	})
+
+	t.Run("risu extensions without other keys", func(t *testing.T) {
+		sheet := DefaultSheet(RevisionV3)
+		sheet.Risu = &RisuExtensions{ViewScreen: "imggen"}
+		sheet.Extensions = map[string]any{"role": "user"}
+
+		jsonBytes, err := sheet.ToBytes()
+		require.NoError(t, err)
+		assert.Contains(t, string(jsonBytes), `"risuai":{"viewScreen":"imggen"}`)
+		unmarshaledSheet, err := FromBytes(jsonBytes)
+		require.NoError(t, err)
+
+		assert.Equal(t, "imggen", unmarshaledSheet.Risu.ViewScreen)
+		// risuai should be completely removed since it only had typed keys
+		_, ok := unmarshaledSheet.Extensions[RisuKey]
+		assert.False(t, ok)
+		assert.Len(t, unmarshaledSheet.Extensions, 1)
+	})
+
+	t.Run("malformed keys stay untyped", func(t *testing.T) {
+		sheet, err := FromBytes([]byte(`{"spec":"chara_card_v3","spec_version":"3.0","data":{"name":"Risu","extensions":{"risuai":{"bias":[["hello","5"]],"viewScreen":"none","customScripts":[{"in":1}]}}}}`))
+		require.NoError(t, err)
+
+		require.NotNil(t, sheet.Risu)
+		assert.Nil(t, sheet.Risu.Bias)
+		assert.Nil(t, sheet.Risu.CustomScripts)
+		assert.Equal(t, "none", sheet.Risu.ViewScreen)
+
+		jsonBytes, err := sheet.ToBytes()
+		require.NoError(t, err)
+		assert.Contains(t, string(jsonBytes), `"risuai":{"bias":[["hello","5"]],"customScripts":[{"in":1}],"viewScreen":"none"}`)
+	})
+
+	t.Run("no typed keys", func(t *testing.T) {
+		sheet, err := FromBytes([]byte(`{"spec":"chara_card_v3","spec_version":"3.0","data":{"name":"Risu","extensions":{"risuai":{}}}}`))
+		require.NoError(t, err)
+
+		assert.Nil(t, sheet.Risu)
+		assert.Equal(t, map[string]any{RisuKey: map[string]any{}}, sheet.Extensions)
+	})
+}
+
+func TestContent_ImportRisuAssets(t *testing.T) {
+	sheet, err := FromBytes([]byte(risuSheetJSON))
+	require.NoError(t, err)
+	sheet.Assets = []Asset{DefaultAsset(), {Type: property.EmotionAsset, URI: "embeded://assets/smile.png", Name: "smile", Extension: "png"}}
+
+	assert.Equal(t, 1, sheet.ImportRisuAssets())
+	assert.Equal(t, []Asset{
+		DefaultAsset(),
+		{Type: property.EmotionAsset, URI: "embeded://assets/smile.png", Name: "smile", Extension: "png"},
+		{Type: property.OtherAsset, URI: "https://example.com/frown.webp", Name: "frown", Extension: "webp"},
+	}, sheet.Assets)
+	assert.Nil(t, sheet.Risu.AdditionalAssets)
+
+	// The RisuAI extensions are dropped once empty
+	content := Content{Risu: &RisuExtensions{AdditionalAssets: []RisuAsset{{URI: "embeded://assets/a.png"}}}}
+	assert.Equal(t, 1, content.ImportRisuAssets())
+	assert.Nil(t, content.Risu)
+	assert.Equal(t, 0, content.ImportRisuAssets())
+}
+
+func TestRisuExtensions_Clone(t *testing.T) {
+	original := &RisuExtensions{
+		Bias:          []RisuBias{{Text: "a", Weight: 1}},
+		CustomScripts: []RisuScript{{In: "a", Extra: map[string]any{"flag": []any{"x"}}}},
+	}
+	clone := original.Clone()
+	require.Equal(t, original, clone)
+
+	clone.Bias[0].Weight = 2
+	clone.CustomScripts[0].Extra["flag"].([]any)[0] = "y"
+	assert.Equal(t, 1.0, original.Bias[0].Weight)
+	assert.Equal(t, "x", original.CustomScripts[0].Extra["flag"].([]any)[0])
+	assert.Nil(t, (*RisuExtensions)(nil).Clone())
+}
@@ -1,14 +1,22 @@
 package character
 
 import (
+	"bytes"
 	"cmp"
+	"encoding/json"
+	"errors"
 	"io"
+	"maps"
+	"os"
+	"reflect"
+	"slices"
+	"strings"
 
 	gcmp "github.com/google/go-cmp/cmp"
 	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/r3dpixel/card-parser/internal/codec"
 	"github.com/r3dpixel/card-parser/property"
-	"github.com/r3dpixel/toolkit/jsonx"
-	"github.com/r3dpixel/toolkit/sonicx"
+	"github.com/r3dpixel/toolkit/filex"
 	"github.com/r3dpixel/toolkit/stringsx"
 )
 
@@ -21,6 +29,10 @@ var cmpOptions = []gcmp.Option{
 	cmpopts.SortSlices(comparator[property.String]),
 	cmpopts.SortSlices(comparator[property.Integer]),
 	cmpopts.SortSlices(comparator[property.Float]),
+	cmpopts.IgnoreFields(Sheet{}, "RawSpec", "RawVersion", "RawTopLevel", "LegacyImport", "Upgrade", "DecodeNotes"),
+	cmpopts.IgnoreUnexported(Content{}),
+	gcmp.Comparer(property.Union.Equals),
+	gcmp.FilterValues(mixedNumbers, gcmp.Comparer(numbersEqual)),
 }
 
 const (
@@ -30,11 +42,50 @@ const (
 	AnonymousCreator = "Anonymous"
 )
 
+// DecodeOptions options for decoding chara sheets
+type DecodeOptions struct {
+	// StrictFields rejects any key (in data, character_book or entries) that does not map to a known field
+	StrictFields bool
+	// RejectAliases rejects known alias keys (e.g. creatorcomment, straggler entry extensions) in strict mode
+	RejectAliases bool
+	// StopOnError stops multi-sheet readers (FromJSONArray, FromJSONL) at the first malformed entry
+	StopOnError bool
+	// RejectLegacyCards fails the decoding of flat V1 sheets (no data object, but a top-level name or first_mes) with
+	// ErrLegacyCard, instead of importing their content as a V2 sheet (see Sheet.LegacyImport)
+	RejectLegacyCards bool
+	// DropUnknownTopLevel discards the unknown top-level members (e.g. metadata) instead of keeping them in
+	// Sheet.RawTopLevel, for strict output (spec, spec_version and data only)
+	DropUnknownTopLevel bool
+	// MaxNestingDepth sets the maximum nesting depth of JSON objects/arrays (0 uses DefaultMaxNestingDepth); deeper
+	// structures are truncated to null (reported with NoteNestingTruncated), or rejected in strict mode
+	MaxNestingDepth int
+	// PreserveEmptyBook keeps an empty lorebook (e.g. "character_book": {}) as a non-nil book, written back when
+	// encoding; by default, an empty lorebook is treated as absent (nil CharacterBook, never emitted)
+	PreserveEmptyBook bool
+}
+
+// sheetDecoder decodes a chara sheet with the given options (see FromBytesWithOptions)
+// The JSON is already truncated to the maximum nesting depth by the decoding entry point
+type sheetDecoder struct {
+	sheet     *Sheet
+	opts      DecodeOptions
+	lazy      bool // Keep the lorebook as raw JSON (see FromBytesLazy)
+	truncated bool // The JSON was truncated to the maximum nesting depth
+}
+
+// UnmarshalJSON decodes the chara sheet like Sheet.UnmarshalJSON, with the decoder options
+func (d *sheetDecoder) UnmarshalJSON(data []byte) error {
+	return d.sheet.unmarshal(data, d)
+}
+
+// ErrLegacyCard is returned (with DecodeOptions.RejectLegacyCards) when decoding a sheet with the flat V1 layout
+var ErrLegacyCard = errors.New("legacy chara card without a data object")
+
 // sheetWrapper is used to wrap the Sheet content in a JSON object for marshaling and unmarshalling
 type sheetWrapper struct {
-	Spec    Spec     `json:"spec"`
-	Version Version  `json:"spec_version"`
-	Content *Content `json:"data"`
+	Spec    Spec           `json:"spec"`
+	Version Version        `json:"spec_version"`
+	Content json.Marshaler `json:"data"`
 }
 
 // Sheet structure of a V3 chara card
@@ -43,6 +94,18 @@ type Sheet struct {
 	Version  Version
 	Revision Revision
 	Content
+	RawSpec    string // Spec value found when decoding (numbers formatted as text), before the normalization
+	RawVersion string // Spec version value found when decoding (numbers formatted as text), before the normalization
+	// RawTopLevel unknown top-level members found when decoding (e.g. metadata), written back after the known keys
+	// (see DecodeOptions.DropUnknownTopLevel); the flat content fields (V1 layout copies of the data fields, e.g.
+	// first_mes) are never kept, as they would go stale
+	RawTopLevel map[string]json.RawMessage
+	// LegacyImport is set when the sheet was decoded from the flat V1 layout (written back with the data wrapper)
+	LegacyImport bool
+	// Upgrade is set by UpgradeToV3 with the V3 only fields before and after the upgrade (reverted by DowngradeToV2)
+	Upgrade *UpgradeRecord
+	// DecodeNotes changes the decoding made to the card (e.g. truncated structures), in the order they were made
+	DecodeNotes []DecodeNote
 }
 
 // DefaultSheet returns an empty chara sheet with the given Revision
@@ -55,7 +118,8 @@ func DefaultSheet(revision Revision) *Sheet {
 	return sheet
 }
 
-// MarshalJSON marshals Sheet into JSON format with Content wrapped under "data" using Sonic
+// MarshalJSON marshals Sheet into JSON format with Content wrapped under "data" using the JSON codec
+// V2 sheets omit the empty V3 only fields (see PruneForRevision)
 func (s *Sheet) MarshalJSON() ([]byte, error) {
 	// Wrap the content in a JSON object
 	wrapper := sheetWrapper{
@@ -63,41 +127,115 @@ func (s *Sheet) MarshalJSON() ([]byte, error) {
 		Version: s.Version,
 		Content: &s.Content,
 	}
-	// Encode the JSON object using Sonic
-	return sonicx.Config.Marshal(&wrapper)
+	if s.Revision == RevisionV2 {
+		wrapper.Content = (*v2Content)(&s.Content)
+	}
+	// Encode the JSON object using the JSON codec
+	data, err := codec.Marshal(&wrapper)
+	if err != nil || len(s.RawTopLevel) == 0 {
+		return data, err
+	}
+
+	// Append the unknown top-level members (sorted by key, the known keys are skipped)
+	keys := slices.Sorted(maps.Keys(s.RawTopLevel))
+	for _, key := range keys {
+		if slices.ContainsFunc(sheetFields, func(name string) bool { return strings.EqualFold(key, name) }) {
+			continue
+		}
+		encodedKey, err := codec.Marshal(key)
+		if err != nil {
+			return nil, err
+		}
+		data = append(data[:len(data)-1], ',')
+		data = append(append(append(data, encodedKey...), ':'), s.RawTopLevel[key]...)
+		data = append(data, '}')
+	}
+	return data, nil
 }
 
-// UnmarshalJSON decode a chara sheet from JSON using Sonic
+// UnmarshalJSON decode a chara sheet from JSON using the JSON codec
+// The revision detection is tolerant: spec values containing v3 (case-insensitive, e.g. chara_card_v3.0) and spec
+// versions of at least 3 (numbers or strings, e.g. 3, "3", "v3.0") select a V3 revision, anything else RevisionV2
+// Version 3.1 selects RevisionV3_1; unknown newer 3.x versions select LatestRevisionV3 and keep their version
+// The spec, spec_version and data keys are matched case-insensitively (exact keys take precedence)
+// Flat V1 sheets (no data object, but a top-level name or first_mes) are imported as V2 sheets (see LegacyImport)
+// Structures nested deeper than DefaultMaxNestingDepth are truncated before decoding (see DecodeNotes)
 func (s *Sheet) UnmarshalJSON(data []byte) error {
-	// Decode the JSON object using Sonic
-	wrap, err := sonicx.GetFromString(stringsx.FromBytes(data))
+	data, truncated := truncateDepth(data, DefaultMaxNestingDepth)
+	return s.unmarshal(data, &sheetDecoder{sheet: s, truncated: truncated})
+}
+
+// unmarshal decodes a chara sheet from JSON (already truncated to the maximum nesting depth) with the decoder
+// options (see UnmarshalJSON)
+func (s *Sheet) unmarshal(data []byte, decoder *sheetDecoder) error {
+	opts := decoder.opts
+
+	// Report the truncated structures
+	s.DecodeNotes = nil
+	if decoder.truncated {
+		s.DecodeNotes = append(s.DecodeNotes, opts.truncationNote())
+	}
+
+	// Decode the JSON object using the JSON codec
+	root, err := codec.GetFromString(stringsx.FromBytes(data))
 	if err != nil {
 		return err
 	}
 
-	// Extract metadata without copying
-	spec := wrap.GetByPath("spec").String()
-	version := wrap.GetByPath("spec_version").String()
-	rawData := wrap.GetByPath("data").Raw()
-	if err := sonicx.Config.UnmarshalFromString(rawData, &s.Content); err != nil {
+	// Read the metadata without copying, and decode the data object
+	header, err := readSheetHeader(root, !opts.DropUnknownTopLevel)
+	if err != nil {
+		return err
+	}
+	s.keepEmptyBook = opts.PreserveEmptyBook
+	content := any(&s.Content)
+	if decoder.lazy {
+		content = (*lazyContent)(&s.Content)
+	}
+
+	// Import the content of the flat V1 layout from the top level
+	if header.legacy() {
+		if opts.RejectLegacyCards {
+			return ErrLegacyCard
+		}
+		if err := codec.Unmarshal(data, content); err != nil {
+			return err
+		}
+		s.DecodeNotes = append(s.DecodeNotes, s.contentNotes()...)
+		s.RawSpec, s.RawVersion, s.RawTopLevel, s.LegacyImport = header.spec, header.version, header.unknown, true
+		s.SetRevision(RevisionV2)
+		return nil
+	}
+
+	if err := codec.UnmarshalFromString(header.data, content); err != nil {
 		return err
 	}
+	s.DecodeNotes = append(s.DecodeNotes, s.contentNotes()...)
 
-	// Set the correct revision, spec and version
+	// Set the correct revision, spec and version (anything but a V3 card is decoded as V2)
+	s.RawSpec, s.RawVersion, s.RawTopLevel = header.spec, header.version, header.unknown
 	revision := RevisionV2
-	if spec == string(SpecV3) || version == string(V3) {
-		revision = RevisionV3
+	if detected, _ := header.revision(); detected >= RevisionV3 {
+		revision = detected
 	}
 	s.SetRevision(revision)
+	if revision >= RevisionV3 {
+		_, s.Version = v3Stamp(header.version)
+	}
 
 	// Decoding complete
 	return nil
 }
 
 // SetRevision sets the sheet revision, spec and version
+// Revisions without a stamp (see Stamps) keep the current spec and version, never blank ones
 func (s *Sheet) SetRevision(revision Revision) {
 	// Get the correct stamp
-	stamp := Stamps[revision]
+	stamp, ok := Stamps[revision]
+	if !ok {
+		s.Revision = revision
+		return
+	}
 
 	// Set the revision, spec and version
 	s.Revision = revision
@@ -105,39 +243,150 @@ func (s *Sheet) SetRevision(revision Revision) {
 	s.Version = stamp.Version
 }
 
-// ToJSON converts the sheet to its JSON representation and writes it to the given output io.Writer using Sonic streaming
-func (s *Sheet) ToJSON(w io.Writer, opts ...jsonx.Options) error {
-	return jsonx.ToJSON(s, w, opts...)
+// ToJSON converts the sheet to its JSON representation and streams it, followed by a newline, to the given output
+// io.Writer with the encoder of the JSON codec (see ToBytes for the options)
+func (s *Sheet) ToJSON(w io.Writer, opts ...EncodeOption) error {
+	return s.encode(w, opts)
 }
 
-// ToFile converts the sheet to its JSON representation and writes it to the given output file destination
-func (s *Sheet) ToFile(path string, opts ...jsonx.Options) error {
-	return jsonx.ToFile(s, path, opts...)
+// ToFile converts the sheet to its JSON representation and streams it, followed by a newline, to the given output file
+// destination (see ToJSON)
+func (s *Sheet) ToFile(path string, opts ...EncodeOption) error {
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, filex.FilePermission)
+	if err != nil {
+		return err
+	}
+	if err := s.encode(file, opts); err != nil {
+		_ = file.Close()
+		return err
+	}
+	return file.Close()
 }
 
-// ToBytes converts the sheet to its JSON representation and returns the JSON byte slice
-func (s *Sheet) ToBytes(opts ...jsonx.Options) ([]byte, error) {
-	return jsonx.ToBytes(s, opts...)
+// ToBytes converts the sheet to its JSON representation with the JSON codec (see SetCodec), and returns the JSON byte
+// slice; the options (Pretty, EscapeHTML, SortKeys) are applied by the codec encoder to the whole output, nested
+// structures included
+func (s *Sheet) ToBytes(opts ...EncodeOption) ([]byte, error) {
+	if len(opts) == 0 {
+		return codec.Marshal(s)
+	}
+	var buffer bytes.Buffer
+	if err := s.encode(&buffer, opts); err != nil {
+		return nil, err
+	}
+	// Drop the newline written by the encoder
+	return bytes.TrimSuffix(buffer.Bytes(), []byte("\n")), nil
 }
 
-// DeepEquals returns true if the two sheets are deeply equal
+// DeepEquals returns true if the two sheets are deeply equal (empty and nil collections are equal, the order of string
+// and number arrays is ignored, and IDs 5 and "5" as well as numbers of different types, e.g. 5 and 5.0, are equal)
+// Lorebooks not decoded yet are decoded first (see FromBytesLazy); sheets whose lorebook fails to decode are not equal
 func (s *Sheet) DeepEquals(other *Sheet) bool {
-	return gcmp.Equal(s, other, cmpOptions...)
+	return materializeBooks(s, other) && gcmp.Equal(s, other, cmpOptions...)
 }
 
-// FromJSON decodes the JSON from the given input io.Reader and returns the decoded sheet using Sonic streaming
+// FromJSON decodes the JSON from the given input io.Reader and returns the decoded sheet
 func FromJSON(r io.Reader) (*Sheet, error) {
-	return jsonx.FromJSON[*Sheet](r)
+	// Read the whole input (the nesting depth is limited before decoding)
+	// The buffer is sized up front if the reader reports its size (e.g. bytes.Reader)
+	var buffer bytes.Buffer
+	if sized, ok := r.(interface{ Len() int }); ok {
+		buffer.Grow(sized.Len() + bytes.MinRead)
+	}
+	if _, err := buffer.ReadFrom(r); err != nil {
+		return nil, err
+	}
+	return FromBytes(buffer.Bytes())
 }
 
 // FromFile decodes the JSON from the given input file and returns the decoded sheet
 func FromFile(path string) (*Sheet, error) {
-	return jsonx.FromFile[*Sheet](path)
+	// Read the whole file (the nesting depth is limited before decoding)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return FromBytes(data)
 }
 
 // FromBytes decodes the JSON from the given input byte slice and returns the decoded sheet
+// Structures nested deeper than DefaultMaxNestingDepth are truncated before decoding (see Sheet.DecodeNotes)
 func FromBytes(b []byte) (*Sheet, error) {
-	return jsonx.FromBytes[*Sheet](b)
+	return FromBytesWithOptions(b, DecodeOptions{})
+}
+
+// FromBytesWithOptions decodes the JSON from the given input byte slice using the given options and returns the decoded sheet
+// In strict mode, an *UnknownFieldsError listing every unknown key (with its path) is returned,
+// and structures nested deeper than the maximum nesting depth are rejected with ErrNestingTooDeep (instead of truncated)
+func FromBytesWithOptions(b []byte, opts DecodeOptions) (*Sheet, error) {
+	sheet, _, err := decodeSheet(b, opts)
+	return sheet, err
+}
+
+// decodeSheet decodes the JSON with the given options, and returns the sheet with the decoded JSON (truncated to the
+// maximum nesting depth, once for the whole sheet)
+func decodeSheet(b []byte, opts DecodeOptions) (*Sheet, []byte, error) {
+	// Truncate structures nested too deep (rejected in strict mode)
+	b, truncated, err := opts.truncate(b)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Reject unknown fields in strict mode
+	if opts.StrictFields {
+		if err := checkUnknownFields(b, opts); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	// Decode the sheet
+	sheet, err := decodeTruncatedSheet(b, opts, truncated)
+	if err != nil {
+		return nil, nil, err
+	}
+	return sheet, b, nil
+}
+
+// decodeTruncatedSheet decodes the JSON already truncated to the maximum nesting depth with the given options
+func decodeTruncatedSheet(b []byte, opts DecodeOptions, truncated bool) (*Sheet, error) {
+	sheet := new(Sheet)
+	if err := codec.Unmarshal(b, &sheetDecoder{sheet: sheet, opts: opts, truncated: truncated}); err != nil {
+		return nil, err
+	}
+	return sheet, nil
+}
+
+// mixedNumbers checks if both values are numbers of different types (e.g. an int and a float64 extension value)
+func mixedNumbers(x, y any) bool {
+	_, xNumber := numberValue(x)
+	_, yNumber := numberValue(y)
+	return xNumber && yNumber && reflect.TypeOf(x) != reflect.TypeOf(y)
+}
+
+// numbersEqual checks if both numbers hold the same value, regardless of their types
+func numbersEqual(x, y any) bool {
+	xValue, _ := numberValue(x)
+	yValue, _ := numberValue(y)
+	return xValue == yValue
+}
+
+// numberValue returns the value of a number (of any numeric type, or a json.Number) as a float64
+func numberValue(v any) (float64, bool) {
+	if number, ok := v.(json.Number); ok {
+		value, err := number.Float64()
+		return value, err == nil
+	}
+	value := reflect.ValueOf(v)
+	switch value.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(value.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(value.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return value.Float(), true
+	default:
+		return 0, false
+	}
 }
 
 // comparator is used to compare slices of any type
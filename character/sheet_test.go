@@ -2,6 +2,7 @@ package character
 
 import (
 	"bytes"
+	"encoding/json"
 	"os"
 	"strings"
 	"testing"
@@ -222,6 +223,58 @@ func TestSheet_UnmarshalJSON(t *testing.T) {
 	}
 }
 
+func TestSheet_UnmarshalJSON_SpecVariants(t *testing.T) {
+	tests := []struct {
+		name       string
+		metadata   string
+		revision   Revision
+		rawSpec    string
+		rawVersion string
+	}{
+		{name: "Standard V3", metadata: `"spec":"chara_card_v3","spec_version":"3.0"`, revision: RevisionV3, rawSpec: "chara_card_v3", rawVersion: "3.0"},
+		{name: "Standard V2", metadata: `"spec":"chara_card_v2","spec_version":"2.0"`, revision: RevisionV2, rawSpec: "chara_card_v2", rawVersion: "2.0"},
+		{name: "Dotted spec", metadata: `"spec":"chara_card_v3.0"`, revision: RevisionV3, rawSpec: "chara_card_v3.0"},
+		{name: "Uppercase spec", metadata: `"spec":"CHARA_CARD_V3"`, revision: RevisionV3, rawSpec: "CHARA_CARD_V3"},
+		{name: "Capitalized spec key", metadata: `"Spec":"CHARA_CARD_V3"`, revision: RevisionV3, rawSpec: "CHARA_CARD_V3"},
+		{name: "Exact key takes precedence", metadata: `"SPEC":"chara_card_v3","spec":"chara_card_v2"`, revision: RevisionV2, rawSpec: "chara_card_v2"},
+		{name: "Numeric version", metadata: `"spec_version":3`, revision: RevisionV3, rawVersion: "3"},
+		{name: "Numeric float version", metadata: `"spec_version":3.1`, revision: RevisionV3_1, rawVersion: "3.1"},
+		{name: "String integer version", metadata: `"spec_version":"3"`, revision: RevisionV3, rawVersion: "3"},
+		{name: "Prefixed version", metadata: `"spec_version":"v3.0"`, revision: RevisionV3, rawVersion: "v3.0"},
+		{name: "Numeric V2 version", metadata: `"spec":"chara_card_v2","spec_version":2`, revision: RevisionV2, rawSpec: "chara_card_v2", rawVersion: "2"},
+		{name: "Unknown values", metadata: `"spec":"tavern_card","spec_version":"latest"`, revision: RevisionV2, rawSpec: "tavern_card", rawVersion: "latest"},
+		{name: "Non-text values", metadata: `"spec":null,"spec_version":{"major":3}`, revision: RevisionV2},
+		{name: "Missing values", metadata: `"name":"Alice"`, revision: RevisionV2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var sheet Sheet
+			require.NoError(t, sonicx.Config.UnmarshalFromString(`{`+tt.metadata+`,"data":{"name":"Alice"}}`, &sheet))
+			assert.Equal(t, tt.revision, sheet.Revision)
+			assert.Equal(t, Stamps[tt.revision].Spec, sheet.Spec)
+			assert.Equal(t, Stamps[tt.revision].Version, sheet.Version)
+			assert.Equal(t, tt.rawSpec, sheet.RawSpec)
+			assert.Equal(t, tt.rawVersion, sheet.RawVersion)
+			assert.Equal(t, "Alice", string(sheet.Name))
+		})
+	}
+
+	t.Run("Capitalized data key", func(t *testing.T) {
+		var sheet Sheet
+		require.NoError(t, sonicx.Config.UnmarshalFromString(`{"spec":"chara_card_v3","Data":{"name":"Alice"}}`, &sheet))
+		assert.Equal(t, "Alice", string(sheet.Name))
+	})
+
+	t.Run("Raw values are ignored by DeepEquals", func(t *testing.T) {
+		var sheet Sheet
+		require.NoError(t, sonicx.Config.UnmarshalFromString(`{"spec":"CHARA_CARD_V3","data":{"name":"Alice"}}`, &sheet))
+		expected := DefaultSheet(RevisionV3)
+		expected.Name = "Alice"
+		assert.True(t, expected.DeepEquals(&sheet))
+	})
+}
+
 func TestSheet_UnmarshalJSON_ErrorCases(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -576,136 +629,252 @@ func TestSheet_RoundTripIntegrity(t *testing.T) {
 	assert.Equal(t, "preserved", depthPromptMap["other_data"])
 }
 
-func TestSheet_ComprehensiveRoundTrip(t *testing.T) {
-	// Create a comprehensive sheet JSON with every possible field populated
-	comprehensiveJSON := `{
-		"spec": "chara_card_v3",
-		"spec_version": "3.0",
-		"data": {
-			"title": "Comprehensive Test Character",
-			"name": "ComprehensiveChar",
-			"description": "A character with every possible field populated for testing.",
-			"personality": "Friendly, outgoing, and comprehensive",
-			"scenario": "Testing scenario with detailed background",
-			"first_mes": "Hello! I'm a comprehensive test character.",
-			"mes_example": "<START>\n{{user}}: Hello\n{{char}}: Hi there!\n<START>\n{{user}}: How are you?\n{{char}}: I'm doing great!",
-			"creator_notes": "Created for comprehensive testing purposes\n\nIncludes all possible fields",
-			"system_prompt": "You are a helpful assistant for testing.",
-			"post_history_instructions": "Remember to stay in character.",
-			"alternate_greetings": [
-				"Hi there! Ready for some comprehensive testing?",
-				"Greetings! I have all the fields populated.",
-				"Hey! Testing every possible property."
-			],
-			"character_book": {
-				"name": "Comprehensive Lorebook",
-				"description": "A lorebook with all possible configurations",
-				"scan_depth": 100,
-				"token_budget": 2048,
-				"recursive_scanning": true,
-				"extensions": {
-					"custom_book_field": "custom_book_value",
-					"book_metadata": {
-						"version": "1.0",
-						"author": "Test Suite"
+// comprehensiveSheetJSON is a V3 sheet JSON with every possible field populated
+const comprehensiveSheetJSON = `{
+	"spec": "chara_card_v3",
+	"spec_version": "3.0",
+	"data": {
+		"title": "Comprehensive Test Character",
+		"name": "ComprehensiveChar",
+		"description": "A character with every possible field populated for testing.",
+		"personality": "Friendly, outgoing, and comprehensive",
+		"scenario": "Testing scenario with detailed background",
+		"first_mes": "Hello! I'm a comprehensive test character.",
+		"mes_example": "<START>\n{{user}}: Hello\n{{char}}: Hi there!\n<START>\n{{user}}: How are you?\n{{char}}: I'm doing great!",
+		"creator_notes": "Created for comprehensive testing purposes\n\nIncludes all possible fields",
+		"system_prompt": "You are a helpful assistant for testing.",
+		"post_history_instructions": "Remember to stay in character.",
+		"alternate_greetings": [
+			"Hi there! Ready for some comprehensive testing?",
+			"Greetings! I have all the fields populated.",
+			"Hey! Testing every possible property."
+		],
+		"character_book": {
+			"name": "Comprehensive Lorebook",
+			"description": "A lorebook with all possible configurations",
+			"scan_depth": 100,
+			"token_budget": 2048,
+			"recursive_scanning": true,
+			"extensions": {
+				"custom_book_field": "custom_book_value",
+				"book_metadata": {
+					"version": "1.0",
+					"author": "Test Suite"
+				}
+			},
+			"entries": [
+				{
+					"id": 1,
+					"keys": ["comprehensive", "test", "character"],
+					"secondary_keys": ["comp", "test"],
+					"name": "Comprehensive Entry",
+					"comment": "Main character entry",
+					"content": "This is comprehensive test content for the character.",
+					"constant": true,
+					"selective": true,
+					"insertion_order": 100,
+					"enabled": true,
+					"use_regex": true,
+					"extensions": {
+						"position": 2,
+						"probability": 85.00,
+						"depth": 3,
+						"selectiveLogic": 3,
+						"match_whole_words": true,
+						"case_sensitive": false,
+						"role": 1,
+						"sticky": 2,
+						"cooldown": 5,
+						"delay": 1,
+						"automation_id": "comprehensive_automation",
+						"group": "comprehensive_group",
+						"group_override": true,
+						"group_weight": 60,
+						"use_probability": false,
+						"prevent_recursion": true,
+						"entry_custom": "entry_value"
 					}
 				},
-				"entries": [
-					{
-						"id": 1,
-						"keys": ["comprehensive", "test", "character"],
-						"secondary_keys": ["comp", "test"],
-						"name": "Comprehensive Entry",
-						"comment": "Main character entry",
-						"content": "This is comprehensive test content for the character.",
-						"constant": true,
-						"selective": true,
-						"insertion_order": 100,
-						"enabled": true,
-						"use_regex": true,
-						"extensions": {
-							"position": 2,
-							"probability": 85.00,
-							"depth": 3,
-							"selectiveLogic": 3,
-							"match_whole_words": true,
-							"case_sensitive": false,
-							"role": 1,
-							"sticky": 2,
-							"cooldown": 5,
-							"delay": 1,
-							"entry_custom": "entry_value"
-						}
-					},
-					{
-						"id": 2,
-						"keys": ["c", "t", "cc"],
-						"secondary_keys": ["cc", "tt"],
-						"name": "Comprehensive Entry2",
-						"comment": "Main character entry2",
-						"content": "This is comprehensive test content for the character2.",
-						"constant": false,
-						"selective": false,
-						"insertion_order": 85,
-						"enabled": false,
-						"use_regex": false,
-						"extensions": {
-							"position": 3,
-							"probability": 95.00,
-							"depth": 2,
-							"selectiveLogic": 1,
-							"match_whole_words": false,
-							"case_sensitive": true,
-							"role": 2,
-							"sticky": 3,
-							"cooldown": 5,
-							"delay": 2,
-							"entry_custom2": "entry_value2"
-						}
+				{
+					"id": 2,
+					"keys": ["c", "t", "cc"],
+					"secondary_keys": ["cc", "tt"],
+					"name": "Comprehensive Entry2",
+					"comment": "Main character entry2",
+					"content": "This is comprehensive test content for the character2.",
+					"constant": false,
+					"selective": false,
+					"insertion_order": 85,
+					"enabled": false,
+					"use_regex": false,
+					"extensions": {
+						"position": 3,
+						"probability": 95.00,
+						"depth": 2,
+						"selectiveLogic": 1,
+						"match_whole_words": false,
+						"case_sensitive": true,
+						"role": 2,
+						"sticky": 3,
+						"cooldown": 5,
+						"delay": 2,
+						"automation_id": "",
+						"group": "comprehensive_group",
+						"group_override": false,
+						"group_weight": 100,
+						"use_probability": true,
+						"prevent_recursion": false,
+						"entry_custom2": "entry_value2"
 					}
-				]
-			},
-			"tags": ["comprehensive", "test", "full-featured", "roundtrip"],
-			"creator": "Test Suite Author",
-			"character_version": "2.1.0",
-			"creation_date": 1640995200,
-			"modification_date": 1672531200,
-			"nickname": "CompChar",
-			"extensions": {
-				"depth_prompt": {
-					"prompt": "Think deeply about this comprehensive character.",
-					"depth": 10,
-					"custom_depth_field": "custom_value"
-				},
-				"custom_extension_1": "value1",
-				"custom_extension_2": {
-					"nested": "data",
-					"number": 42,
-					"boolean": true,
-					"array": ["item1", "item2", "item3"]
-				},
-				"character_metadata": {
-					"test_version": "1.0",
-					"features": ["comprehensive", "roundtrip", "validation"]
 				}
+			]
+		},
+		"tags": ["comprehensive", "test", "full-featured", "roundtrip"],
+		"creator": "Test Suite Author",
+		"character_version": "2.1.0",
+		"creation_date": 1640995200,
+		"modification_date": 1672531200,
+		"nickname": "CompChar",
+		"extensions": {
+			"depth_prompt": {
+				"prompt": "Think deeply about this comprehensive character.",
+				"depth": 10,
+				"custom_depth_field": "custom_value"
 			},
-			"source_id": "comprehensive_test_001",
-			"character_id": "comprehensive_id_001",
-			"platform_id": "comprehensive_pt_id_001",
-			"direct_link": "https://example.com/comprehensive_test_001"
-		}
-	}`
+			"custom_extension_1": "value1",
+			"custom_extension_2": {
+				"nested": "data",
+				"number": 42,
+				"boolean": true,
+				"array": ["item1", "item2", "item3"]
+			},
+			"character_metadata": {
+				"test_version": "1.0",
+				"features": ["comprehensive", "roundtrip", "validation"]
+			}
+		},
+		"source_id": "comprehensive_test_001",
+		"character_id": "comprehensive_id_001",
+		"platform_id": "comprehensive_pt_id_001",
+		"direct_link": "https://example.com/comprehensive_test_001"
+	}
+}`
+
+func TestSheet_ComprehensiveRoundTrip(t *testing.T) {
+
+	originalSheet, err := FromBytes([]byte(comprehensiveSheetJSON))
+	require.NoError(t, err)
+
+	marshaledBytes, err := originalSheet.ToBytes()
+	require.NoError(t, err)
+
+	roundtripSheet, err := FromBytes(marshaledBytes)
+	require.NoError(t, err)
+	println(cmp.Diff(originalSheet, roundtripSheet, cmpopts.IgnoreUnexported(Content{})))
+	assert.True(t, cmp.Equal(originalSheet, roundtripSheet, cmpopts.EquateEmpty(), cmpopts.IgnoreUnexported(Content{})))
+}
 
-	originalSheet, err := FromBytes([]byte(comprehensiveJSON))
+// comprehensiveTopLevelSheetJSON is the comprehensive sheet with unknown top-level members (and a flat V1 copy)
+var comprehensiveTopLevelSheetJSON = strings.Replace(comprehensiveSheetJSON, `"spec": "chara_card_v3",`,
+	`"metadata": {"tool": "exporter", "tags": [1, 2]}, "char_persona": "leftover", "first_mes": "stale", "spec": "chara_card_v3",`, 1)
+
+func TestSheet_ComprehensiveRoundTrip_TopLevel(t *testing.T) {
+	originalSheet, err := FromBytes([]byte(comprehensiveTopLevelSheetJSON))
 	require.NoError(t, err)
+	assert.Equal(t, map[string]json.RawMessage{
+		"metadata":     json.RawMessage(`{"tool":"exporter","tags":[1,2]}`),
+		"char_persona": json.RawMessage(`"leftover"`),
+	}, originalSheet.RawTopLevel)
 
 	marshaledBytes, err := originalSheet.ToBytes()
 	require.NoError(t, err)
 
 	roundtripSheet, err := FromBytes(marshaledBytes)
 	require.NoError(t, err)
-	println(cmp.Diff(originalSheet, roundtripSheet))
-	assert.True(t, cmp.Equal(originalSheet, roundtripSheet, cmpopts.EquateEmpty()))
+	assert.True(t, cmp.Equal(originalSheet, roundtripSheet, cmpopts.EquateEmpty(), cmpopts.IgnoreUnexported(Content{})))
+}
+
+func TestSheet_RawTopLevel(t *testing.T) {
+	t.Run("Written after the known keys", func(t *testing.T) {
+		sheet := DefaultSheet(RevisionV3)
+		sheet.RawTopLevel = map[string]json.RawMessage{
+			"zeta":  json.RawMessage(`1`),
+			"alpha": json.RawMessage(`{"a":true}`),
+			"Data":  json.RawMessage(`"shadowed"`),
+		}
+		data, err := sheet.ToBytes()
+		require.NoError(t, err)
+		text := string(data)
+		assert.True(t, strings.HasSuffix(text, `,"alpha":{"a":true},"zeta":1}`))
+		assert.NotContains(t, text, "shadowed")
+	})
+
+	t.Run("Ignored by DeepEquals and the fingerprint", func(t *testing.T) {
+		plain, err := FromBytes([]byte(comprehensiveSheetJSON))
+		require.NoError(t, err)
+		topLevel, err := FromBytes([]byte(comprehensiveTopLevelSheetJSON))
+		require.NoError(t, err)
+		assert.True(t, plain.DeepEquals(topLevel))
+		assert.Equal(t, plain.Fingerprint(), topLevel.Fingerprint())
+	})
+
+	t.Run("Disabled", func(t *testing.T) {
+		opts := DecodeOptions{DropUnknownTopLevel: true}
+		sheet, err := FromBytesWithOptions([]byte(comprehensiveTopLevelSheetJSON), opts)
+		require.NoError(t, err)
+		assert.Nil(t, sheet.RawTopLevel)
+
+		data, err := sheet.ToBytes()
+		require.NoError(t, err)
+		assert.NotContains(t, string(data), `"metadata":`)
+	})
+}
+
+func TestSheet_UnmarshalJSON_Legacy(t *testing.T) {
+	tests := []struct {
+		name     string
+		jsonData string
+		expected property.String
+		legacy   bool
+		err      error
+	}{
+		{name: "Flat layout", jsonData: `{"name":"Alice","first_mes":"Hi"}`, expected: "Alice", legacy: true},
+		{name: "Name only", jsonData: `{"name":"Alice","description":"A knight"}`, expected: "Alice", legacy: true},
+		{name: "Flat layout with spec", jsonData: `{"spec":"chara_card_v2","name":"Alice"}`, expected: "Alice", legacy: true},
+		{name: "Flat layout with data string", jsonData: `{"name":"Alice","data":"none"}`, expected: "Alice", legacy: true},
+		{name: "Wrapped layout", jsonData: `{"name":"Outer","data":{"name":"Alice"}}`, expected: "Alice"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sheet, err := FromBytes([]byte(tt.jsonData))
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, sheet.Name)
+			assert.Equal(t, tt.legacy, sheet.LegacyImport)
+			assert.Equal(t, RevisionV2, sheet.Revision)
+			assert.Equal(t, SpecV2, sheet.Spec)
+		})
+	}
+
+	t.Run("Content of the flat layout", func(t *testing.T) {
+		sheet, err := FromBytes([]byte(`{"name":"Alice","description":"A knight","first_mes":"Hi","mes_example":"<START>","avatar":"none"}`))
+		require.NoError(t, err)
+		assert.Equal(t, property.String("A knight"), sheet.Description)
+		assert.Equal(t, property.String("Hi"), sheet.FirstMessage)
+		assert.Equal(t, property.String("<START>"), sheet.MessageExamples)
+		assert.Equal(t, json.RawMessage(`"none"`), sheet.RawTopLevel["avatar"])
+		assert.NotContains(t, sheet.RawTopLevel, NameField)
+	})
+
+	t.Run("Rejected", func(t *testing.T) {
+		opts := DecodeOptions{RejectLegacyCards: true}
+		_, err := FromBytesWithOptions([]byte(`{"name":"Alice","first_mes":"Hi"}`), opts)
+		assert.ErrorIs(t, err, ErrLegacyCard)
+
+		sheet, err := FromBytesWithOptions([]byte(`{"data":{"name":"Alice"}}`), opts)
+		require.NoError(t, err)
+		assert.False(t, sheet.LegacyImport)
+	})
 }
 
 func TestSheet_DeepEquals(t *testing.T) {
@@ -874,11 +1043,16 @@ func TestSheet_SetRevision(t *testing.T) {
 			revision: RevisionV3,
 			expected: Stamps[RevisionV3],
 		},
+		{
+			name:     "set revision without stamp",
+			revision: 40,
+			expected: Stamp{Spec: SpecV2, Version: V2, Revision: 40},
+		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			sheet := &Sheet{}
+			sheet := &Sheet{Spec: SpecV2, Version: V2}
 			sheet.SetRevision(tt.revision)
 
 			assert.Equal(t, tt.expected.Revision, sheet.Revision)
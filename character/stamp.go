@@ -1,5 +1,15 @@
 package character
 
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ErrInvalidVersion is returned when a spec version is not a major[.minor] number
+var ErrInvalidVersion = errors.New("invalid spec version")
+
 // Spec type of chara card
 type Spec string // chara card spec
 
@@ -14,8 +24,9 @@ type Version string // chara card version
 
 // Allowed Version values
 const (
-	V2 Version = "2.0"
-	V3 Version = "3.0"
+	V2   Version = "2.0"
+	V3   Version = "3.0"
+	V3_1 Version = "3.1"
 )
 
 // Revision type of chara card
@@ -23,10 +34,16 @@ type Revision int // chara card revision
 
 // Allowed Revision values
 const (
+	RevisionV1 Revision = 1 // Flat layout (no data object), reported by DetectRevision only
 	RevisionV2 Revision = 2
 	RevisionV3 Revision = 3
+	// RevisionV3_1 follows RevisionV3 (revisions are ordered, their values are not spec versions)
+	RevisionV3_1 Revision = 4
 )
 
+// LatestRevisionV3 is the latest known V3 revision, used for the unknown newer 3.x versions (forward compatibility)
+const LatestRevisionV3 = RevisionV3_1
+
 // Stamp structure of a mapping from revision to spec/version
 type Stamp struct {
 	Spec     Spec
@@ -36,6 +53,106 @@ type Stamp struct {
 
 // Stamps mappings from revision to spec/versions
 var Stamps = map[Revision]Stamp{
-	RevisionV2: {Spec: SpecV2, Version: V2, Revision: RevisionV2},
-	RevisionV3: {Spec: SpecV3, Version: V3, Revision: RevisionV3},
+	RevisionV2:   {Spec: SpecV2, Version: V2, Revision: RevisionV2},
+	RevisionV3:   {Spec: SpecV3, Version: V3, Revision: RevisionV3},
+	RevisionV3_1: {Spec: SpecV3, Version: V3_1, Revision: RevisionV3_1},
+}
+
+// ParseVersion parses a spec version (major[.minor], with an optional v prefix, e.g. "3.1", "v3", "3.0")
+// Returns the normalized version (e.g. "3" -> "3.0"), or ErrInvalidVersion
+func ParseVersion(s string) (Version, error) {
+	major, minor, ok := parseVersionParts(s)
+	if !ok {
+		return "", fmt.Errorf("%w: %q", ErrInvalidVersion, s)
+	}
+	return Version(strconv.Itoa(major) + "." + strconv.Itoa(minor)), nil
+}
+
+// Major returns the major number of the version (0 if the version cannot be parsed)
+func (v Version) Major() int {
+	major, _, _ := parseVersionParts(string(v))
+	return major
+}
+
+// Minor returns the minor number of the version (0 if the version cannot be parsed)
+func (v Version) Minor() int {
+	_, minor, _ := parseVersionParts(string(v))
+	return minor
+}
+
+// Compare compares the versions by major, then minor number (-1, 0 or +1), unparsable versions compare as 0.0
+func (v Version) Compare(other Version) int {
+	if major := v.Major() - other.Major(); major != 0 {
+		return max(-1, min(1, major))
+	}
+	return max(-1, min(1, v.Minor()-other.Minor()))
+}
+
+// Major returns the major spec version of the revision
+func (r Revision) Major() int {
+	if stamp, ok := Stamps[r]; ok {
+		return stamp.Version.Major()
+	}
+	return int(r)
+}
+
+// String returns the revision as v<major> (v<major>.<minor> for minor revisions, e.g. v3.1)
+func (r Revision) String() string {
+	stamp, ok := Stamps[r]
+	if !ok || stamp.Version.Minor() == 0 {
+		return "v" + strconv.Itoa(r.Major())
+	}
+	return "v" + strconv.Itoa(r.Major()) + "." + strconv.Itoa(stamp.Version.Minor())
+}
+
+// v3Stamp returns the revision and version of a V3 sheet declaring the given spec version
+// Known versions get their revision, unknown newer 3.x versions get the latest V3 revision and keep their version
+// (trimmed), anything else is a plain V3 sheet
+func v3Stamp(version string) (Revision, Version) {
+	// Versions that are not 3.x are plain V3
+	parsed, err := ParseVersion(version)
+	if err != nil || parsed.Major() != V3.Major() {
+		return RevisionV3, V3
+	}
+
+	// Known versions
+	for revision := RevisionV3; revision <= LatestRevisionV3; revision++ {
+		if stamp := Stamps[revision]; stamp.Version.Compare(parsed) == 0 {
+			return revision, stamp.Version
+		}
+	}
+
+	// Newer versions keep their version, older ones are plain V3
+	if parsed.Compare(Stamps[LatestRevisionV3].Version) > 0 {
+		return LatestRevisionV3, Version(strings.TrimSpace(version))
+	}
+	return RevisionV3, V3
+}
+
+// matches checks if the spec and version match the stamp (newer minor versions of the latest V3 revision included)
+func (s Stamp) matches(spec Spec, version Version) bool {
+	if s.Spec != spec {
+		return false
+	}
+	if s.Revision == LatestRevisionV3 && version.Major() == s.Version.Major() {
+		return version.Compare(s.Version) >= 0
+	}
+	return s.Version == version
+}
+
+// parseVersionParts parses the major and minor numbers of a version (major[.minor], with an optional v prefix)
+func parseVersionParts(s string) (int, int, bool) {
+	s = strings.TrimPrefix(strings.ToLower(strings.TrimSpace(s)), "v")
+	majorText, minorText, hasMinor := strings.Cut(s, ".")
+	major, err := strconv.Atoi(majorText)
+	if err != nil || major < 0 || strings.HasPrefix(majorText, "+") {
+		return 0, 0, false
+	}
+	minor := 0
+	if hasMinor {
+		if minor, err = strconv.Atoi(minorText); err != nil || minor < 0 || strings.HasPrefix(minorText, "+") {
+			return 0, 0, false
+		}
+	}
+	return major, minor, true
 }
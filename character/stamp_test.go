@@ -0,0 +1,100 @@
+package character
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseVersion(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected Version
+		major    int
+		minor    int
+		valid    bool
+	}{
+		{"3.1", "3.1", 3, 1, true},
+		{"3", "3.0", 3, 0, true},
+		{"v3.0", "3.0", 3, 0, true},
+		{" V2.0 ", "2.0", 2, 0, true},
+		{"3.10", "3.10", 3, 10, true},
+		{"", "", 0, 0, false},
+		{"latest", "", 0, 0, false},
+		{"3.1.2", "", 0, 0, false},
+		{"-3", "", 0, 0, false},
+		{"3.+1", "", 0, 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			version, err := ParseVersion(tt.input)
+			if !tt.valid {
+				assert.ErrorIs(t, err, ErrInvalidVersion)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, version)
+			assert.Equal(t, tt.major, Version(tt.input).Major())
+			assert.Equal(t, tt.minor, Version(tt.input).Minor())
+		})
+	}
+}
+
+func TestVersion_Compare(t *testing.T) {
+	assert.Equal(t, 0, V3.Compare("3"))
+	assert.Equal(t, -1, V3.Compare(V3_1))
+	assert.Equal(t, 1, Version("3.10").Compare("3.9"))
+	assert.Equal(t, -1, V2.Compare(V3))
+	assert.Equal(t, 1, Version("4.0").Compare("3.99"))
+}
+
+func TestRevision_String(t *testing.T) {
+	assert.Equal(t, "v1", RevisionV1.String())
+	assert.Equal(t, "v2", RevisionV2.String())
+	assert.Equal(t, "v3", RevisionV3.String())
+	assert.Equal(t, "v3.1", RevisionV3_1.String())
+	assert.Equal(t, 3, RevisionV3_1.Major())
+}
+
+func TestSheet_ForwardCompatibleVersion(t *testing.T) {
+	tests := []struct {
+		name     string
+		version  string
+		revision Revision
+		expected Version
+	}{
+		{"Known minor version", `"3.1"`, RevisionV3_1, V3_1},
+		{"Unknown newer minor version", `"3.2"`, LatestRevisionV3, "3.2"},
+		{"Unknown newer numeric version", `3.25`, LatestRevisionV3, "3.25"},
+		{"Prefixed newer version", `"v3.4"`, LatestRevisionV3, "v3.4"},
+		{"Plain version", `"3"`, RevisionV3, V3},
+		{"Newer major version", `"4.0"`, RevisionV3, V3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sheet, err := FromBytes([]byte(`{"spec":"chara_card_v3","spec_version":` + tt.version + `,"data":{"name":"Alice"}}`))
+			require.NoError(t, err)
+			assert.Equal(t, tt.revision, sheet.Revision)
+			assert.Equal(t, SpecV3, sheet.Spec)
+			assert.Equal(t, tt.expected, sheet.Version)
+
+			// The version is re-emitted on marshal
+			data, err := sheet.ToBytes()
+			require.NoError(t, err)
+			assert.Contains(t, string(data), `"spec_version":"`+string(tt.expected)+`"`)
+
+			// The version is not reported as a spec mismatch
+			for _, issue := range sheet.Validate() {
+				assert.NotEqual(t, IssueSpecMismatch, issue.Code)
+			}
+
+			// The revision is detected without decoding
+			revision, err := DetectRevision(data)
+			require.NoError(t, err)
+			assert.Equal(t, tt.revision, revision)
+		})
+	}
+}
@@ -0,0 +1,160 @@
+package character
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// DefaultTokenizer estimates the tokens of a text (see EstimateTokens)
+var DefaultTokenizer Tokenizer = TokenizerFunc(EstimateTokens)
+
+// EstimateTokens estimates the number of tokens of a text: one token per 4 characters, and at least one per word
+func EstimateTokens(text string) int {
+	return max(len(strings.Fields(text)), (utf8.RuneCountInString(text)+3)/4)
+}
+
+// StatsOptions options of Content.Stats and Book.Stats
+type StatsOptions struct {
+	Tokenizer       Tokenizer // Tokenizer used for the token counts (DefaultTokenizer if nil)
+	ExcludeDisabled bool      // Exclude the disabled lorebook entries from the book totals
+}
+
+// TextStats size of a text
+type TextStats struct {
+	Characters int `json:"characters"`
+	Tokens     int `json:"tokens"`
+}
+
+// ContentStats sizes of the prompt payload of a content
+type ContentStats struct {
+	Permanent          TextStats            `json:"permanent"`           // Description, personality and system prompt
+	Fields             map[string]TextStats `json:"fields"`              // Every prompt field, by field name
+	AlternateGreetings []TextStats          `json:"alternate_greetings"` // Every alternate greeting, in order
+	Greetings          TextStats            `json:"greetings"`           // First message and alternate greetings
+	Total              TextStats            `json:"total"`               // Every prompt field and alternate greeting
+	Book               *BookStats           `json:"book,omitempty"`      // Lorebook stats (nil without lorebook)
+}
+
+// BookStats sizes of the entries of a lorebook
+type BookStats struct {
+	Entries     []EntryStats `json:"entries"`      // Every entry (excluded disabled entries are listed, but not counted)
+	Total       TextStats    `json:"total"`        // Content of the counted entries
+	TokenBudget int          `json:"token_budget"` // Token budget of the book (0 if not set)
+	OverBudget  []int        `json:"over_budget"`  // Indices of the entries exceeding the token budget on their own
+}
+
+// EntryStats size of a lorebook entry
+type EntryStats struct {
+	Index      int       `json:"index"`
+	Name       string    `json:"name"`
+	Enabled    bool      `json:"enabled"`
+	Content    TextStats `json:"content"`
+	OverBudget bool      `json:"over_budget"`
+}
+
+// Stats returns the character and token counts of the prompt fields, alternate greetings and lorebook
+func (c *Content) Stats(opts StatsOptions) ContentStats {
+	tok := opts.tokenizer()
+	stats := ContentStats{Fields: make(map[string]TextStats)}
+
+	// Count the prompt fields
+	for field, text := range map[string]string{
+		NameField:                    string(c.Name),
+		DescriptionField:             string(c.Description),
+		PersonalityField:             string(c.Personality),
+		ScenarioField:                string(c.Scenario),
+		FirstMessageField:            string(c.FirstMessage),
+		MessageExamplesField:         string(c.MessageExamples),
+		SystemPromptField:            string(c.SystemPrompt),
+		PostHistoryInstructionsField: string(c.PostHistoryInstructions),
+		DepthPromptKey:               c.DepthPrompt.Prompt,
+	} {
+		fieldStats := textStats(text, tok)
+		stats.Fields[field] = fieldStats
+		stats.Total.add(fieldStats)
+	}
+
+	// Aggregate the permanent fields
+	for _, field := range []string{DescriptionField, PersonalityField, SystemPromptField} {
+		stats.Permanent.add(stats.Fields[field])
+	}
+
+	// Count the greetings
+	stats.Greetings = stats.Fields[FirstMessageField]
+	stats.AlternateGreetings = make([]TextStats, 0, len(c.AlternateGreetings))
+	for _, greeting := range c.AlternateGreetings {
+		greetingStats := textStats(greeting, tok)
+		stats.AlternateGreetings = append(stats.AlternateGreetings, greetingStats)
+		stats.Greetings.add(greetingStats)
+		stats.Total.add(greetingStats)
+	}
+
+	// Count the lorebook
+	if c.CharacterBook != nil {
+		bookStats := c.CharacterBook.Stats(opts)
+		stats.Book = &bookStats
+	}
+
+	// Return the stats
+	return stats
+}
+
+// Stats returns the character and token counts of the lorebook entries (content only)
+// Entries whose content alone exceeds the book TokenBudget (if set) are flagged as over budget
+func (b *Book) Stats(opts StatsOptions) BookStats {
+	tok := opts.tokenizer()
+	stats := BookStats{
+		Entries:     make([]EntryStats, 0, len(b.Entries)),
+		TokenBudget: int(b.TokenBudget),
+		OverBudget:  []int{},
+	}
+
+	for index, entry := range b.Entries {
+		// Skip nil entries
+		if entry == nil {
+			continue
+		}
+
+		// Count the entry content
+		entryStats := EntryStats{
+			Index:   index,
+			Name:    string(entry.Name),
+			Enabled: bool(entry.Enabled),
+			Content: textStats(string(entry.Content), tok),
+		}
+
+		// Flag the entry if it exceeds the budget
+		if stats.TokenBudget > 0 && entryStats.Content.Tokens > stats.TokenBudget {
+			entryStats.OverBudget = true
+			stats.OverBudget = append(stats.OverBudget, index)
+		}
+		stats.Entries = append(stats.Entries, entryStats)
+
+		// Aggregate the entry (unless disabled entries are excluded)
+		if entryStats.Enabled || !opts.ExcludeDisabled {
+			stats.Total.add(entryStats.Content)
+		}
+	}
+
+	// Return the stats
+	return stats
+}
+
+// tokenizer returns the tokenizer of the options (DefaultTokenizer if nil)
+func (o StatsOptions) tokenizer() Tokenizer {
+	if o.Tokenizer == nil {
+		return DefaultTokenizer
+	}
+	return o.Tokenizer
+}
+
+// textStats returns the character and token counts of the text
+func textStats(text string, tok Tokenizer) TextStats {
+	return TextStats{Characters: utf8.RuneCountInString(text), Tokens: tok.Count(text)}
+}
+
+// add adds the counts of other to the stats
+func (s *TextStats) add(other TextStats) {
+	s.Characters += other.Characters
+	s.Tokens += other.Tokens
+}
@@ -0,0 +1,119 @@
+package character
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/r3dpixel/card-parser/property"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEstimateTokens(t *testing.T) {
+	tests := []struct {
+		text     string
+		expected int
+	}{
+		{"", 0},
+		{"abcd", 1},
+		{"abcde", 2},
+		{"a b c d e", 5},
+		{"héllo wörld", 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			assert.Equal(t, tt.expected, EstimateTokens(tt.text))
+			assert.Equal(t, tt.expected, DefaultTokenizer.Count(tt.text))
+		})
+	}
+}
+
+func TestContent_Stats(t *testing.T) {
+	content := &Content{
+		Name:               "Alice",
+		Description:        "a brave knight",
+		Personality:        "loyal",
+		SystemPrompt:       "stay in character",
+		FirstMessage:       "hello there",
+		AlternateGreetings: property.StringArray{"hi", "good morning sir"},
+		DepthPrompt:        DepthPrompt{Prompt: "remember", Depth: 4},
+	}
+
+	stats := content.Stats(StatsOptions{Tokenizer: wordTokenizer})
+
+	assert.Equal(t, TextStats{Characters: 14, Tokens: 3}, stats.Fields[DescriptionField])
+	assert.Equal(t, TextStats{Characters: 36, Tokens: 7}, stats.Permanent)
+	assert.Equal(t, []TextStats{{Characters: 2, Tokens: 1}, {Characters: 16, Tokens: 3}}, stats.AlternateGreetings)
+	assert.Equal(t, TextStats{Characters: 29, Tokens: 6}, stats.Greetings)
+	assert.Equal(t, TextStats{Characters: 78, Tokens: 15}, stats.Total)
+	assert.Equal(t, content.promptTokens(wordTokenizer), stats.Total.Tokens)
+	assert.Nil(t, stats.Book)
+
+	// Default tokenizer
+	assert.Equal(t, EstimateTokens("a brave knight"), content.Stats(StatsOptions{}).Fields[DescriptionField].Tokens)
+
+	// The stats marshal to JSON
+	data, err := json.Marshal(stats)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"permanent":{"characters":36,"tokens":7}`)
+	assert.NotContains(t, string(data), `"book"`)
+}
+
+func TestBook_Stats(t *testing.T) {
+	entry := func(name string, content string, enabled bool) *BookEntry {
+		e := DefaultBookEntry()
+		e.Name = property.String(name)
+		e.Content = property.String(content)
+		e.Enabled = property.Bool(enabled)
+		return e
+	}
+	book := &Book{
+		TokenBudget: 3,
+		Entries: []*BookEntry{
+			entry("Castle", "a large stone castle", true),
+			nil,
+			entry("Sword", "sharp", false),
+			entry("King", "old king", true),
+		},
+	}
+
+	tests := []struct {
+		name            string
+		excludeDisabled bool
+		total           TextStats
+	}{
+		{"All entries", false, TextStats{Characters: 33, Tokens: 7}},
+		{"Disabled entries excluded", true, TextStats{Characters: 28, Tokens: 6}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stats := book.Stats(StatsOptions{Tokenizer: wordTokenizer, ExcludeDisabled: tt.excludeDisabled})
+
+			require.Len(t, stats.Entries, 3)
+			assert.Equal(t, EntryStats{Index: 0, Name: "Castle", Enabled: true, Content: TextStats{Characters: 20, Tokens: 4}, OverBudget: true}, stats.Entries[0])
+			assert.Equal(t, EntryStats{Index: 2, Name: "Sword", Enabled: false, Content: TextStats{Characters: 5, Tokens: 1}}, stats.Entries[1])
+			assert.Equal(t, 3, stats.Entries[2].Index)
+			assert.Equal(t, tt.total, stats.Total)
+			assert.Equal(t, 3, stats.TokenBudget)
+			assert.Equal(t, []int{0}, stats.OverBudget)
+		})
+	}
+
+	t.Run("Without budget", func(t *testing.T) {
+		unbounded := &Book{Entries: book.Entries}
+		stats := unbounded.Stats(StatsOptions{Tokenizer: wordTokenizer})
+		assert.Empty(t, stats.OverBudget)
+		for _, entryStats := range stats.Entries {
+			assert.False(t, entryStats.OverBudget)
+		}
+	})
+
+	t.Run("Content stats include the book", func(t *testing.T) {
+		content := &Content{CharacterBook: book}
+		stats := content.Stats(StatsOptions{Tokenizer: wordTokenizer, ExcludeDisabled: true})
+		require.NotNil(t, stats.Book)
+		assert.Equal(t, TextStats{Characters: 28, Tokens: 6}, stats.Book.Total)
+	})
+}
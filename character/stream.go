@@ -0,0 +1,120 @@
+package character
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+
+	"github.com/r3dpixel/card-parser/internal/codec"
+)
+
+// EntryError an error decoding a single entry of a multi-sheet input (JSON array or JSONL)
+type EntryError struct {
+	Line  int // Line of the entry (1-based, JSONL only)
+	Index int // Index of the entry (0-based, blank lines are not counted)
+	Err   error
+}
+
+// Error returns the error message prefixed by the position of the entry
+func (e *EntryError) Error() string {
+	if e.Line > 0 {
+		return fmt.Sprintf("line %d: %v", e.Line, e.Err)
+	}
+	return fmt.Sprintf("entry %d: %v", e.Index, e.Err)
+}
+
+// Unwrap returns the underlying decoding error
+func (e *EntryError) Unwrap() error {
+	return e.Err
+}
+
+// FromJSONArray decodes a JSON array of sheets from the given input io.Reader
+// Every entry is decoded like FromBytes (including the legacy V2 fallback); malformed entries are skipped and
+// reported as *EntryError joined in the returned error, unless StopOnError is set (then no sheets are returned)
+func FromJSONArray(r io.Reader, opts ...DecodeOptions) ([]*Sheet, error) {
+	options := decodeOptions(opts)
+
+	// Read the whole input
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	// Split the array into raw entries
+	var entries []json.RawMessage
+	if err := codec.Unmarshal(data, &entries); err != nil {
+		return nil, err
+	}
+
+	// Decode every entry
+	sheets := make([]*Sheet, 0, len(entries))
+	var errs []error
+	for index, entry := range entries {
+		sheet, err := FromBytesWithOptions(entry, options)
+		if err != nil {
+			entryErr := &EntryError{Index: index, Err: err}
+			if options.StopOnError {
+				return nil, entryErr
+			}
+			errs = append(errs, entryErr)
+			continue
+		}
+		sheets = append(sheets, sheet)
+	}
+
+	// Return the sheets and the errors of the malformed entries
+	return sheets, errors.Join(errs...)
+}
+
+// FromJSONL streams the sheets of a JSONL input (one sheet per line, blank lines are skipped)
+// Every line is decoded like FromBytes (including the legacy V2 fallback); malformed lines are yielded as an
+// *EntryError (with the line number) and the stream continues, unless StopOnError is set
+// Read errors of the input always stop the stream
+func FromJSONL(r io.Reader, opts ...DecodeOptions) iter.Seq2[*Sheet, error] {
+	options := decodeOptions(opts)
+
+	return func(yield func(*Sheet, error) bool) {
+		reader := bufio.NewReader(r)
+		lineNumber, index := 0, 0
+
+		for {
+			// Read the next line (lines can be of any length)
+			line, readErr := reader.ReadBytes('\n')
+			if readErr != nil && readErr != io.EOF {
+				yield(nil, readErr)
+				return
+			}
+			lineNumber++
+
+			// Decode the line, skipping blank lines
+			if line = bytes.TrimSpace(line); len(line) > 0 {
+				sheet, err := FromBytesWithOptions(line, options)
+				if err != nil {
+					if !yield(nil, &EntryError{Line: lineNumber, Index: index, Err: err}) || options.StopOnError {
+						return
+					}
+				} else if !yield(sheet, nil) {
+					return
+				}
+				index++
+			}
+
+			// Stop at the end of the input
+			if readErr == io.EOF {
+				return
+			}
+		}
+	}
+}
+
+// decodeOptions returns the first of the given options (the zero options if none are given)
+func decodeOptions(opts []DecodeOptions) DecodeOptions {
+	if len(opts) == 0 {
+		return DecodeOptions{}
+	}
+	return opts[0]
+}
@@ -0,0 +1,137 @@
+package character
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	streamV3Entry      = `{"spec":"chara_card_v3","spec_version":"3.0","data":{"name":"V3 Character","tags":["a"]}}`
+	streamV2Entry      = `{"spec":"chara_card_v2","spec_version":"2.0","data":{"name":"V2 Character"}}`
+	streamLegacyEntry  = `{"data":{"name":"Legacy Character","description":"Legacy description"}}`
+	streamCorruptEntry = `{"spec":"chara_card_v3","data":{"name":`
+)
+
+func TestFromJSONL(t *testing.T) {
+	input := strings.Join([]string{streamV3Entry, "", streamCorruptEntry, streamV2Entry, "  ", streamLegacyEntry}, "\n")
+
+	t.Run("Malformed lines are reported", func(t *testing.T) {
+		var names []string
+		var entryErrs []*EntryError
+		for sheet, err := range FromJSONL(strings.NewReader(input)) {
+			if err != nil {
+				var entryErr *EntryError
+				require.True(t, errors.As(err, &entryErr))
+				entryErrs = append(entryErrs, entryErr)
+				continue
+			}
+			names = append(names, string(sheet.Name))
+		}
+
+		assert.Equal(t, []string{"V3 Character", "V2 Character", "Legacy Character"}, names)
+		require.Len(t, entryErrs, 1)
+		assert.Equal(t, 3, entryErrs[0].Line)
+		assert.Equal(t, 1, entryErrs[0].Index)
+		assert.Contains(t, entryErrs[0].Error(), "line 3: ")
+	})
+
+	t.Run("Revisions", func(t *testing.T) {
+		var revisions []Revision
+		for sheet, err := range FromJSONL(strings.NewReader(input)) {
+			if err == nil {
+				revisions = append(revisions, sheet.Revision)
+			}
+		}
+		assert.Equal(t, []Revision{RevisionV3, RevisionV2, RevisionV2}, revisions)
+	})
+
+	t.Run("StopOnError", func(t *testing.T) {
+		count, errCount := 0, 0
+		for _, err := range FromJSONL(strings.NewReader(input), DecodeOptions{StopOnError: true}) {
+			if err != nil {
+				errCount++
+				continue
+			}
+			count++
+		}
+		assert.Equal(t, 1, count)
+		assert.Equal(t, 1, errCount)
+	})
+
+	t.Run("Early break", func(t *testing.T) {
+		count := 0
+		for range FromJSONL(strings.NewReader(input)) {
+			count++
+			break
+		}
+		assert.Equal(t, 1, count)
+	})
+
+	t.Run("Read error", func(t *testing.T) {
+		readErr := errors.New("read failed")
+		var errs []error
+		for _, err := range FromJSONL(&failingReader{err: readErr}) {
+			errs = append(errs, err)
+		}
+		require.Len(t, errs, 1)
+		assert.ErrorIs(t, errs[0], readErr)
+	})
+
+	t.Run("No trailing newline and CRLF", func(t *testing.T) {
+		count := 0
+		for _, err := range FromJSONL(strings.NewReader(streamV2Entry + "\r\n" + streamV3Entry)) {
+			require.NoError(t, err)
+			count++
+		}
+		assert.Equal(t, 2, count)
+	})
+}
+
+func TestFromJSONArray(t *testing.T) {
+	input := "[" + strings.Join([]string{streamV3Entry, streamV2Entry, `"not a sheet"`, streamLegacyEntry}, ",") + "]"
+
+	t.Run("Malformed entries are skipped", func(t *testing.T) {
+		sheets, err := FromJSONArray(strings.NewReader(input))
+		require.Error(t, err)
+		var entryErr *EntryError
+		require.True(t, errors.As(err, &entryErr))
+		assert.Equal(t, 2, entryErr.Index)
+		assert.Contains(t, err.Error(), "entry 2: ")
+
+		require.Len(t, sheets, 3)
+		assert.Equal(t, RevisionV3, sheets[0].Revision)
+		assert.Equal(t, RevisionV2, sheets[1].Revision)
+		assert.Equal(t, "Legacy Character", string(sheets[2].Name))
+	})
+
+	t.Run("StopOnError", func(t *testing.T) {
+		sheets, err := FromJSONArray(strings.NewReader(input), DecodeOptions{StopOnError: true})
+		assert.Error(t, err)
+		assert.Nil(t, sheets)
+	})
+
+	t.Run("Valid array", func(t *testing.T) {
+		sheets, err := FromJSONArray(strings.NewReader("[" + streamV2Entry + "]"))
+		require.NoError(t, err)
+		assert.Len(t, sheets, 1)
+	})
+
+	t.Run("Not an array", func(t *testing.T) {
+		_, err := FromJSONArray(strings.NewReader(streamV2Entry))
+		assert.Error(t, err)
+	})
+}
+
+// failingReader is an io.Reader always failing with the given error
+type failingReader struct {
+	err error
+}
+
+// Read always returns the reader error
+func (r *failingReader) Read([]byte) (int, error) {
+	return 0, r.err
+}
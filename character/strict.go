@@ -0,0 +1,120 @@
+package character
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/r3dpixel/card-parser/internal/codec"
+	"github.com/r3dpixel/toolkit/jsonx"
+)
+
+// Known field names of the decoded structures (used by the strict fields check)
+var (
+	sheetFields     = []string{"spec", "spec_version", "data"} // In the order decoded by Sheet.UnmarshalJSON
+	contentFields   = jsonx.ExtractJsonFieldNames(Content{})
+	bookFields      = jsonx.ExtractJsonFieldNames(Book{})
+	bookEntryFields = append(jsonx.ExtractJsonFieldNames(BookEntryCore{}), ExtensionsField)
+)
+
+// Known alias keys, accepted in strict mode unless DecodeOptions.RejectAliases is set
+var (
+	// contentAliases legacy (V1) aliases of the content fields
+	contentAliases = []string{"creatorcomment"}
+	// bookEntryStragglers entry extensions found outside the extension map (see BookEntry.UnmarshalJSON)
+	bookEntryStragglers = []BookEntryExtension{
+		EntryCaseSensitive, EntryPosition, EntryProbability, EntrySelectiveLogic, EntryRole,
+		EntryAutomationID, EntryGroup, EntryGroupOverride, EntryGroupWeight, EntryUseProbability, EntryPreventRecursion,
+	}
+	// bookStragglers book extensions found outside the extension map (see Book.UnmarshalJSON)
+	bookStragglers = []BookExtension{
+		BookTokenBudgetCap, BookInsertionStrategy, BookMinActivations, BookMaxDepth, BookCaseSensitive,
+	}
+)
+
+// UnknownFieldsError lists every key that does not map to a known field (strict mode)
+type UnknownFieldsError struct {
+	Paths []string
+}
+
+// Error returns the error message listing all the unknown field paths
+func (e *UnknownFieldsError) Error() string {
+	return "unknown fields: " + strings.Join(e.Paths, ", ")
+}
+
+// checkUnknownFields walks the sheet JSON and returns an UnknownFieldsError if any key is unknown
+func checkUnknownFields(data []byte, opts DecodeOptions) error {
+	// Decode the sheet as a generic map
+	var root map[string]any
+	if err := codec.Unmarshal(data, &root); err != nil {
+		return err
+	}
+
+	// Collect the unknown keys of every known structure
+	var paths []string
+	paths = appendUnknownKeys(paths, "", root, sheetFields, nil)
+
+	// Check the data fields
+	content, _ := root["data"].(map[string]any)
+	paths = appendUnknownKeys(paths, "data", content, contentFields, aliases(contentAliases, opts))
+
+	// Check the lorebook fields
+	book, _ := content[CharacterBookField].(map[string]any)
+	paths = appendUnknownKeys(paths, "data.character_book", book, bookFields, aliases(bookStragglers, opts))
+
+	// Check the lorebook entry fields
+	entries, _ := book["entries"].([]any)
+	for index, entry := range entries {
+		entryMap, _ := entry.(map[string]any)
+		path := fmt.Sprintf("data.character_book.entries[%d]", index)
+		paths = appendUnknownKeys(paths, path, entryMap, bookEntryFields, aliases(bookEntryStragglers, opts))
+	}
+
+	// Check the lorebook entry fields (map form)
+	keyedEntries, _ := book["entries"].(map[string]any)
+	for _, key := range sortedEntryKeys(keyedEntries) {
+		entryMap, _ := keyedEntries[key].(map[string]any)
+		path := fmt.Sprintf("data.character_book.entries[%q]", key)
+		paths = appendUnknownKeys(paths, path, entryMap, bookEntryFields, aliases(bookEntryStragglers, opts))
+	}
+
+	// Return nil if all keys are known
+	if len(paths) == 0 {
+		return nil
+	}
+
+	// Return the typed error
+	return &UnknownFieldsError{Paths: paths}
+}
+
+// appendUnknownKeys appends the (sorted) paths of the keys that are neither known nor aliases
+func appendUnknownKeys(paths []string, prefix string, object map[string]any, known []string, aliases []string) []string {
+	// Collect the unknown keys
+	var unknown []string
+	for key := range object {
+		if !slices.Contains(known, key) && !slices.Contains(aliases, key) {
+			unknown = append(unknown, key)
+		}
+	}
+	// Sort the keys, so the error is deterministic
+	slices.Sort(unknown)
+
+	// Append the full paths
+	for _, key := range unknown {
+		if prefix != "" {
+			key = prefix + "." + key
+		}
+		paths = append(paths, key)
+	}
+
+	// Return the paths
+	return paths
+}
+
+// aliases returns the accepted alias keys given the decode options
+func aliases(keys []string, opts DecodeOptions) []string {
+	if opts.RejectAliases {
+		return nil
+	}
+	return keys
+}
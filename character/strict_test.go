@@ -0,0 +1,89 @@
+package character
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFromBytesWithOptions_StrictFields(t *testing.T) {
+	strict := DecodeOptions{StrictFields: true}
+
+	t.Run("Comprehensive valid sheet", func(t *testing.T) {
+		sheet, err := FromBytesWithOptions([]byte(comprehensiveSheetJSON), strict)
+		require.NoError(t, err)
+		assert.Equal(t, "ComprehensiveChar", string(sheet.Name))
+	})
+
+	t.Run("Typo'd field", func(t *testing.T) {
+		input := `{"spec":"chara_card_v3","spec_version":"3.0","data":{"name":"Char","alternate_greeting":["Hi"]}}`
+
+		_, err := FromBytesWithOptions([]byte(input), strict)
+		var unknownErr *UnknownFieldsError
+		require.ErrorAs(t, err, &unknownErr)
+		assert.Equal(t, []string{"data.alternate_greeting"}, unknownErr.Paths)
+		assert.Equal(t, "unknown fields: data.alternate_greeting", err.Error())
+
+		// The tolerant parser silently drops the field
+		sheet, err := FromBytesWithOptions([]byte(input), DecodeOptions{})
+		require.NoError(t, err)
+		assert.Empty(t, sheet.AlternateGreetings)
+	})
+
+	t.Run("Every unknown field is listed", func(t *testing.T) {
+		input := `{
+			"spec": "chara_card_v2",
+			"spec_versoin": "2.0",
+			"data": {
+				"nmae": "Char",
+				"character_book": {
+					"scan_dept": 5,
+					"entries": [
+						{"keys": ["a"], "content": "A"},
+						{"keys": ["b"], "contnet": "B", "extensions": {"anything": true}}
+					]
+				}
+			}
+		}`
+
+		_, err := FromBytesWithOptions([]byte(input), strict)
+		var unknownErr *UnknownFieldsError
+		require.ErrorAs(t, err, &unknownErr)
+		assert.Equal(t, []string{
+			"spec_versoin",
+			"data.nmae",
+			"data.character_book.scan_dept",
+			"data.character_book.entries[1].contnet",
+		}, unknownErr.Paths)
+	})
+
+	t.Run("Map-form entries", func(t *testing.T) {
+		input := `{"data":{"character_book":{"entries":{"1":{"contnet":"B"},"0":{"content":"A","uid":0}}}}}`
+
+		_, err := FromBytesWithOptions([]byte(input), strict)
+		var unknownErr *UnknownFieldsError
+		require.ErrorAs(t, err, &unknownErr)
+		assert.Equal(t, []string{
+			`data.character_book.entries["0"].uid`,
+			`data.character_book.entries["1"].contnet`,
+		}, unknownErr.Paths)
+	})
+
+	t.Run("Aliases", func(t *testing.T) {
+		input := `{"spec":"chara_card_v2","data":{"name":"Char","creatorcomment":"Notes","character_book":{"entries":[{"keys":["a"],"case_sensitive":true}]}}}`
+
+		_, err := FromBytesWithOptions([]byte(input), strict)
+		require.NoError(t, err)
+
+		_, err = FromBytesWithOptions([]byte(input), DecodeOptions{StrictFields: true, RejectAliases: true})
+		var unknownErr *UnknownFieldsError
+		require.ErrorAs(t, err, &unknownErr)
+		assert.Equal(t, []string{"data.creatorcomment", "data.character_book.entries[0].case_sensitive"}, unknownErr.Paths)
+	})
+
+	t.Run("Invalid JSON", func(t *testing.T) {
+		_, err := FromBytesWithOptions([]byte(`{"data":`), strict)
+		assert.Error(t, err)
+	})
+}
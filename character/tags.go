@@ -0,0 +1,74 @@
+package character
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/r3dpixel/card-parser/property"
+)
+
+// tagSeparators separators of the comma-joined tags (ASCII and fullwidth commas)
+const tagSeparators = ",，"
+
+// TagOptions options of NormalizeTags
+type TagOptions struct {
+	KeepCase    bool              // Keep the case of the tags (lowercased by default); duplicates are still case-insensitive
+	SplitCommas bool              // Split the comma-joined tags (e.g. "fantasy, magic" as a single tag)
+	Synonyms    map[string]string // Canonical forms of the tags, keyed by tag (compared normalized, e.g. "scifi": "sci-fi")
+}
+
+// NormalizeTags trims the tags, collapses their internal whitespace, lowercases them (unless KeepCase is set), splits
+// the comma-joined tags (if SplitCommas is set), and replaces the synonyms by their canonical forms
+// Blank tags and duplicates (compared with NormalizeTag) are removed, the surviving tags keep their order (the first
+// occurrence of a duplicate is kept); returns true if the tags changed
+func (c *Content) NormalizeTags(opts TagOptions) bool {
+	// Index the synonyms by normalized tag
+	synonyms := make(map[string]string, len(opts.Synonyms))
+	for synonym, canonical := range opts.Synonyms {
+		synonyms[NormalizeTag(synonym)] = canonical
+	}
+
+	// Normalize the tags, keeping the first occurrence of each tag
+	var tags property.StringArray
+	if c.Tags != nil {
+		tags = make(property.StringArray, 0, len(c.Tags))
+	}
+	seen := make(map[string]bool, len(c.Tags))
+	for _, tag := range c.Tags {
+		for _, part := range splitTag(tag, opts.SplitCommas) {
+			// Replace the synonym, and normalize the tag
+			if canonical, ok := synonyms[NormalizeTag(part)]; ok {
+				part = canonical
+			}
+			part = strings.Join(strings.Fields(part), " ")
+			if !opts.KeepCase {
+				part = strings.ToLower(part)
+			}
+
+			// Skip the blank tags and the duplicates
+			if key := NormalizeTag(part); key != "" && !seen[key] {
+				seen[key] = true
+				tags = append(tags, part)
+			}
+		}
+	}
+
+	// Replace the tags
+	changed := !slices.Equal(c.Tags, tags)
+	c.Tags = tags
+	return changed
+}
+
+// HasTag returns true if the content has the tag (compared with NormalizeTag: case-insensitive, whitespace collapsed)
+func (c *Content) HasTag(tag string) bool {
+	key := NormalizeTag(tag)
+	return key != "" && slices.ContainsFunc(c.Tags, func(existing string) bool { return NormalizeTag(existing) == key })
+}
+
+// splitTag splits the comma-joined tag if enabled
+func splitTag(tag string, splitCommas bool) []string {
+	if !splitCommas {
+		return []string{tag}
+	}
+	return strings.FieldsFunc(tag, func(r rune) bool { return strings.ContainsRune(tagSeparators, r) })
+}
@@ -0,0 +1,102 @@
+package character
+
+import (
+	"testing"
+
+	"github.com/r3dpixel/card-parser/property"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestContent_NormalizeTags(t *testing.T) {
+	synonyms := map[string]string{"SciFi": "sci-fi", "science  fiction": "sci-fi", "rpg": "Role Play"}
+
+	tests := []struct {
+		name     string
+		tags     property.StringArray
+		opts     TagOptions
+		expected property.StringArray
+		changed  bool
+	}{
+		{
+			name:     "Trim, lowercase and collapse whitespace",
+			tags:     property.StringArray{"  Dark   Fantasy ", "ROMANCE", "\tslice of life"},
+			expected: property.StringArray{"dark fantasy", "romance", "slice of life"},
+			changed:  true,
+		},
+		{
+			name:     "Duplicate variants keep the first occurrence",
+			tags:     property.StringArray{"Magic", "fantasy", "magic", " MAGIC ", "Fantasy", ""},
+			expected: property.StringArray{"magic", "fantasy"},
+			changed:  true,
+		},
+		{
+			name:     "Keep case",
+			tags:     property.StringArray{"Magic", "  Dark  Fantasy", "magic"},
+			opts:     TagOptions{KeepCase: true},
+			expected: property.StringArray{"Magic", "Dark Fantasy"},
+			changed:  true,
+		},
+		{
+			name:     "Unicode tags",
+			tags:     property.StringArray{"ÉPOQUE", "Ёлка", "ファンタジー", "époque", "ΑΓΆΠΗ"},
+			expected: property.StringArray{"époque", "ёлка", "ファンタジー", "αγάπη"},
+			changed:  true,
+		},
+		{
+			name:     "Synonyms",
+			tags:     property.StringArray{"SciFi", "space", "Science Fiction", "sci-fi", "RPG"},
+			opts:     TagOptions{Synonyms: synonyms},
+			expected: property.StringArray{"sci-fi", "space", "role play"},
+			changed:  true,
+		},
+		{
+			name:     "Comma-joined tags are kept without splitting",
+			tags:     property.StringArray{"fantasy, magic"},
+			expected: property.StringArray{"fantasy, magic"},
+			changed:  false,
+		},
+		{
+			name:     "Comma-joined tags",
+			tags:     property.StringArray{"fantasy, Magic", "magic", ",, ,", "romance，drama", "scifi,"},
+			opts:     TagOptions{SplitCommas: true, Synonyms: synonyms},
+			expected: property.StringArray{"fantasy", "magic", "romance", "drama", "sci-fi"},
+			changed:  true,
+		},
+		{
+			name:     "Normalized tags are unchanged",
+			tags:     property.StringArray{"fantasy", "magic"},
+			expected: property.StringArray{"fantasy", "magic"},
+			changed:  false,
+		},
+		{
+			name:     "Nil tags",
+			tags:     nil,
+			expected: nil,
+			changed:  false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			content := &Content{Tags: tt.tags}
+			assert.Equal(t, tt.changed, content.NormalizeTags(tt.opts))
+			assert.Equal(t, tt.expected, content.Tags)
+
+			// Normalizing again changes nothing
+			assert.False(t, content.NormalizeTags(tt.opts))
+		})
+	}
+}
+
+func TestContent_HasTag(t *testing.T) {
+	content := &Content{Tags: property.StringArray{"Dark  Fantasy", "ÉPOQUE", "fantasy, magic"}}
+
+	assert.True(t, content.HasTag("dark fantasy"))
+	assert.True(t, content.HasTag("  DARK\tFANTASY "))
+	assert.True(t, content.HasTag("époque"))
+	assert.True(t, content.HasTag("Fantasy, Magic"))
+	assert.False(t, content.HasTag("magic"))
+	assert.False(t, content.HasTag("fantasy"))
+	assert.False(t, content.HasTag("  "))
+	assert.False(t, (&Content{}).HasTag("fantasy"))
+}
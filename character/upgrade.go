@@ -0,0 +1,148 @@
+package character
+
+import (
+	"cmp"
+	"maps"
+	"reflect"
+	"slices"
+	"time"
+
+	"github.com/r3dpixel/card-parser/property"
+	"github.com/r3dpixel/toolkit/stringsx"
+	"github.com/r3dpixel/toolkit/timestamp"
+)
+
+// UpgradeOptions options of Sheet.UpgradeToV3
+type UpgradeOptions struct {
+	CreationDate     timestamp.Seconds // Creation date of the sheets without one (defaults to now)
+	ModificationDate timestamp.Seconds // Modification date of the sheets without one (defaults to now)
+	NotesLanguage    string            // Language of the plain creator notes (defaults to PrimaryLanguage)
+}
+
+// UpgradeRecord V3 only fields of a sheet before and after UpgradeToV3 (used by DowngradeToV2 to undo the upgrade)
+type UpgradeRecord struct {
+	Before Content // V3 only fields before the upgrade
+	After  Content // V3 only fields after the upgrade
+}
+
+// UpgradeToV3 upgrades a V2 sheet to V3, synthesizing the V3 only fields it lacks: the creation and modification
+// dates (from the options, or now), the nickname (from the name), the multilingual creator notes of the notes
+// language (from the creator notes), and the source (from the direct link and the source ID)
+// Everything else is left intact; a sheet downgraded by DowngradeToV2 (or pruned with a stash) gets its V3 only
+// fields back instead, and V3 sheets are left as is
+func (s *Sheet) UpgradeToV3(opts UpgradeOptions) error {
+	// V3 sheets are left as is
+	if s.Revision >= RevisionV3 {
+		return nil
+	}
+	s.SetRevision(RevisionV3)
+
+	// Restore the fields of a downgraded V3 sheet
+	if _, ok := s.Extensions[PrunedFieldsKey]; ok {
+		s.Upgrade = nil
+		return s.restorePrunedFields()
+	}
+
+	// Synthesize the missing V3 only fields
+	before := v3FieldValues(&s.Content)
+	now := timestamp.Seconds(time.Now().Unix())
+	if s.CreationDate == 0 {
+		s.CreationDate = cmpOr(opts.CreationDate, now)
+	}
+	if s.ModificationDate == 0 {
+		s.ModificationDate = cmpOr(opts.ModificationDate, now)
+	}
+	if stringsx.IsBlank(string(s.Nickname)) {
+		s.Nickname = s.Name
+	}
+	notesLanguage := cmp.Or(opts.NotesLanguage, PrimaryLanguage)
+	if _, ok := s.creatorNotesKey(notesLanguage); !ok && stringsx.IsNotBlank(string(s.CreatorNotes)) {
+		s.CreatorNotesMultilingual = maps.Clone(s.CreatorNotesMultilingual)
+		if s.CreatorNotesMultilingual == nil {
+			s.CreatorNotesMultilingual = make(map[string]property.String)
+		}
+		s.CreatorNotesMultilingual[notesLanguage] = s.CreatorNotes
+	}
+	if len(s.Source) == 0 {
+		for _, source := range []property.String{s.DirectLink, s.SourceID} {
+			if stringsx.IsNotBlank(string(source)) && !slices.Contains(s.Source, string(source)) {
+				s.Source = append(s.Source, string(source))
+			}
+		}
+	}
+
+	// Remember the upgrade, so it can be undone
+	s.Upgrade = &UpgradeRecord{Before: before, After: v3FieldValues(&s.Content)}
+	return nil
+}
+
+// DowngradeToV2 downgrades a V3 sheet to V2, stashing the V3 only fields in the extensions (under PrunedFieldsKey,
+// restored by UpgradeToV3 and PruneForRevision)
+// The fields synthesized by an earlier UpgradeToV3 (and left unchanged since) are reverted instead, so both
+// directions are inverses when run back-to-back; V2 sheets are left as is
+func (s *Sheet) DowngradeToV2() error {
+	// V2 sheets are left as is
+	if s.Revision < RevisionV3 {
+		return nil
+	}
+	upgrade := s.Upgrade
+	s.Upgrade = nil
+
+	// Revert the unchanged upgraded fields, and move the others out of the content
+	var stashed Content
+	for _, field := range v3Fields {
+		if upgrade != nil && v3FieldEqual(field, &s.Content, &upgrade.After) {
+			field.move(&s.Content, &upgrade.Before)
+			continue
+		}
+		field.move(&stashed, &s.Content)
+		field.move(&s.Content, &Content{})
+	}
+
+	// Stash the used fields (a V3 sheet always gets a stash, even empty, so it is restored as is)
+	data, err := contentMap(&stashed)
+	if err != nil {
+		return err
+	}
+	stash := make(map[string]any)
+	for _, field := range v3Fields {
+		if isUsed(data[field.name]) {
+			stash[field.name] = data[field.name]
+		}
+	}
+	if len(stash) > 0 || upgrade == nil {
+		if s.Extensions == nil {
+			s.Extensions = make(map[string]any)
+		}
+		s.Extensions[PrunedFieldsKey] = stash
+	}
+
+	// Set the revision
+	s.SetRevision(RevisionV2)
+	return nil
+}
+
+// v3FieldValues returns a content holding only the V3 only fields of the content
+func v3FieldValues(c *Content) Content {
+	var values Content
+	for _, field := range v3Fields {
+		field.move(&values, c)
+	}
+	return values
+}
+
+// v3FieldEqual checks if the V3 only field has the same value in both contents
+func v3FieldEqual(field v3Field, a *Content, b *Content) bool {
+	var valueA, valueB Content
+	field.move(&valueA, a)
+	field.move(&valueB, b)
+	return reflect.DeepEqual(valueA, valueB)
+}
+
+// cmpOr returns the value if not zero, otherwise the fallback
+func cmpOr(value timestamp.Seconds, fallback timestamp.Seconds) timestamp.Seconds {
+	if value != 0 {
+		return value
+	}
+	return fallback
+}
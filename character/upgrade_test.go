@@ -0,0 +1,157 @@
+package character
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"testing"
+
+	"github.com/r3dpixel/card-parser/property"
+	"github.com/r3dpixel/toolkit/timestamp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// randomText returns a random text (blank one time out of three)
+func randomText(rng *rand.Rand, prefix string) property.String {
+	if rng.IntN(3) == 0 {
+		return ""
+	}
+	return property.String(fmt.Sprintf("%s %d", prefix, rng.IntN(100)))
+}
+
+// randomSheet returns a random sheet of the revision (V3 only fields are set on V3 sheets only)
+func randomSheet(rng *rand.Rand, revision Revision) *Sheet {
+	sheet := DefaultSheet(revision)
+	sheet.Name = randomText(rng, "Name")
+	sheet.Description = randomText(rng, "Description")
+	sheet.CreatorNotes = randomText(rng, "Notes")
+	sheet.DirectLink = randomText(rng, "https://example.com/link")
+	sheet.SourceID = randomText(rng, "source")
+	if rng.IntN(2) == 0 {
+		sheet.Extensions = map[string]any{"fav": true}
+	}
+	if revision < RevisionV3 {
+		return sheet
+	}
+	sheet.Nickname = randomText(rng, "Nickname")
+	sheet.CreationDate = timestamp.Seconds(rng.IntN(3) * 1700000000)
+	sheet.ModificationDate = timestamp.Seconds(rng.IntN(3) * 1710000000)
+	if rng.IntN(2) == 0 {
+		sheet.CreatorNotesMultilingual = map[string]property.String{"fr": randomText(rng, "Notes")}
+	}
+	if rng.IntN(2) == 0 {
+		sheet.Source = property.StringArray{string(randomText(rng, "https://example.com/source"))}
+	}
+	if rng.IntN(2) == 0 {
+		sheet.GroupGreetings = property.StringArray{"Hello everyone!"}
+	}
+	if rng.IntN(2) == 0 {
+		sheet.Assets = []Asset{DefaultAsset()}
+	}
+	return sheet
+}
+
+// cloneSheet returns a deep copy of the sheet
+func cloneSheet(s *Sheet) *Sheet {
+	clone := *s
+	clone.Content = *s.Content.Clone()
+	return &clone
+}
+
+func TestSheet_UpgradeToV3(t *testing.T) {
+	t.Run("Synthesized fields", func(t *testing.T) {
+		sheet := DefaultSheet(RevisionV2)
+		sheet.Name = "Alice"
+		sheet.CreatorNotes = "Notes"
+		sheet.DirectLink = "https://example.com/alice"
+		sheet.SourceID = "alice-42"
+
+		require.NoError(t, sheet.UpgradeToV3(UpgradeOptions{CreationDate: 1700000000, ModificationDate: 1710000000}))
+		assert.Equal(t, RevisionV3, sheet.Revision)
+		assert.Equal(t, Stamps[RevisionV3].Spec, sheet.Spec)
+		assert.Equal(t, timestamp.Seconds(1700000000), sheet.CreationDate)
+		assert.Equal(t, timestamp.Seconds(1710000000), sheet.ModificationDate)
+		assert.Equal(t, property.String("Alice"), sheet.Nickname)
+		assert.Equal(t, map[string]property.String{PrimaryLanguage: "Notes"}, sheet.CreatorNotesMultilingual)
+		assert.Equal(t, property.StringArray{"https://example.com/alice", "alice-42"}, sheet.Source)
+	})
+
+	t.Run("Notes language", func(t *testing.T) {
+		sheet := DefaultSheet(RevisionV2)
+		sheet.CreatorNotes = "Notes"
+		require.NoError(t, sheet.UpgradeToV3(UpgradeOptions{NotesLanguage: "fr"}))
+		assert.Equal(t, map[string]property.String{"fr": "Notes"}, sheet.CreatorNotesMultilingual)
+	})
+
+	t.Run("Default dates", func(t *testing.T) {
+		sheet := DefaultSheet(RevisionV2)
+		require.NoError(t, sheet.UpgradeToV3(UpgradeOptions{}))
+		assert.NotZero(t, sheet.CreationDate)
+		assert.NotZero(t, sheet.ModificationDate)
+		assert.Empty(t, sheet.Nickname)
+		assert.Empty(t, sheet.CreatorNotesMultilingual)
+		assert.Empty(t, sheet.Source)
+	})
+
+	t.Run("V3 untouched", func(t *testing.T) {
+		sheet := DefaultSheet(RevisionV3)
+		require.NoError(t, sheet.UpgradeToV3(UpgradeOptions{}))
+		assert.Zero(t, sheet.CreationDate)
+	})
+}
+
+func TestSheet_DowngradeToV2(t *testing.T) {
+	t.Run("Stash", func(t *testing.T) {
+		sheet := DefaultSheet(RevisionV3)
+		sheet.Nickname = "Ally"
+		sheet.GroupGreetings = property.StringArray{"Hello everyone!"}
+
+		require.NoError(t, sheet.DowngradeToV2())
+		assert.Equal(t, RevisionV2, sheet.Revision)
+		assert.Empty(t, sheet.Nickname)
+		assert.Empty(t, sheet.GroupGreetings)
+		assert.Equal(t, map[string]any{NicknameField: "Ally", GroupGreetingsField: []any{"Hello everyone!"}}, sheet.Extensions[PrunedFieldsKey])
+	})
+
+	t.Run("Edited upgrade", func(t *testing.T) {
+		sheet := DefaultSheet(RevisionV2)
+		sheet.Name = "Alice"
+		require.NoError(t, sheet.UpgradeToV3(UpgradeOptions{CreationDate: 1, ModificationDate: 1}))
+		sheet.Nickname = "Ally"
+
+		require.NoError(t, sheet.DowngradeToV2())
+		assert.Zero(t, sheet.CreationDate)
+		assert.Equal(t, map[string]any{NicknameField: "Ally"}, sheet.Extensions[PrunedFieldsKey])
+	})
+}
+
+func TestSheet_UpgradeDowngrade_RoundTrip(t *testing.T) {
+	rng := rand.New(rand.NewPCG(2059, 1))
+
+	for range 200 {
+		// V2 -> V3 -> V2
+		original := randomSheet(rng, RevisionV2)
+		sheet := cloneSheet(original)
+		require.NoError(t, sheet.UpgradeToV3(UpgradeOptions{}))
+		require.NoError(t, sheet.DowngradeToV2())
+		assert.NotContains(t, sheet.Extensions, PrunedFieldsKey)
+		assert.True(t, original.DeepEquals(sheet), original.Diff(sheet))
+
+		// V3 -> V2 -> V3
+		original = randomSheet(rng, RevisionV3)
+		sheet = cloneSheet(original)
+		require.NoError(t, sheet.DowngradeToV2())
+		require.NoError(t, sheet.UpgradeToV3(UpgradeOptions{}))
+		assert.True(t, original.DeepEquals(sheet), original.Diff(sheet))
+
+		// V3 -> V2 -> JSON -> V3
+		sheet = cloneSheet(original)
+		require.NoError(t, sheet.DowngradeToV2())
+		data, err := sheet.ToBytes()
+		require.NoError(t, err)
+		decoded, err := FromBytes(data)
+		require.NoError(t, err)
+		require.NoError(t, decoded.UpgradeToV3(UpgradeOptions{}))
+		assert.True(t, original.DeepEquals(decoded), original.Diff(decoded))
+	}
+}
@@ -0,0 +1,95 @@
+package character
+
+import (
+	"errors"
+	"slices"
+
+	"github.com/r3dpixel/card-parser/internal/codec"
+)
+
+// ErrNotV1 is returned when decoding a V1 sheet from JSON that has a "data" or "spec" key (V2/V3 layout)
+var ErrNotV1 = errors.New("not a V1 chara sheet")
+
+// V1Fields are the card data fields of the flat V1 layout (alternate greetings are kept, as most V1 writers include them)
+var V1Fields = []string{
+	NameField, DescriptionField, PersonalityField, ScenarioField, FirstMessageField, MessageExamplesField,
+	AlternateGreetingsField,
+}
+
+// v1Sheet flat layout of a V1 chara card (no data wrapper, no spec fields)
+type v1Sheet struct {
+	Name               string   `json:"name"`
+	Description        string   `json:"description"`
+	Personality        string   `json:"personality"`
+	Scenario           string   `json:"scenario"`
+	FirstMessage       string   `json:"first_mes"`
+	MessageExamples    string   `json:"mes_example"`
+	AlternateGreetings []string `json:"alternate_greetings,omitempty"`
+}
+
+// v1Probe detects the keys of the V2/V3 layout
+type v1Probe struct {
+	Spec any `json:"spec"`
+	Data any `json:"data"`
+}
+
+// ToV1Bytes converts the sheet to the flat V1 JSON layout
+// Returns the used fields (and extensions) that V1 cannot carry, and are dropped from the output
+func (s *Sheet) ToV1Bytes() ([]byte, []string, error) {
+	// Collect the data fields as they are exported
+	data, err := contentMap(&s.Content)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Collect the dropped fields
+	var dropped []string
+	for _, field := range append(slices.Clone(SpecFields), ExtensionsField) {
+		if isUsed(data[field]) && !slices.Contains(V1Fields, field) {
+			dropped = append(dropped, field)
+		}
+	}
+
+	// Encode the flat layout
+	v1 := v1Sheet{
+		Name:               string(s.Name),
+		Description:        string(s.Description),
+		Personality:        string(s.Personality),
+		Scenario:           string(s.Scenario),
+		FirstMessage:       string(s.FirstMessage),
+		MessageExamples:    string(s.MessageExamples),
+		AlternateGreetings: []string(s.AlternateGreetings),
+	}
+	b, err := codec.Marshal(&v1)
+	if err != nil {
+		return nil, nil, err
+	}
+	return b, dropped, nil
+}
+
+// FromV1Bytes decodes a flat V1 JSON sheet (no "data" and no "spec" keys) into a V2 sheet
+// Any V2 field present at the top level is kept, as some V1 writers include them
+func FromV1Bytes(b []byte) (*Sheet, error) {
+	// Limit the nesting depth
+	b, truncated := truncateDepth(b, DefaultMaxNestingDepth)
+
+	// Reject the V2/V3 layout
+	var probe v1Probe
+	if err := codec.Unmarshal(b, &probe); err != nil {
+		return nil, err
+	}
+	if probe.Spec != nil || probe.Data != nil {
+		return nil, ErrNotV1
+	}
+
+	// Decode the flat layout into the content
+	sheet := DefaultSheet(RevisionV2)
+	if err := codec.Unmarshal(b, &sheet.Content); err != nil {
+		return nil, err
+	}
+	if truncated {
+		sheet.DecodeNotes = append(sheet.DecodeNotes, DecodeOptions{}.truncationNote())
+	}
+	sheet.DecodeNotes = append(sheet.DecodeNotes, sheet.contentNotes()...)
+	return sheet, nil
+}
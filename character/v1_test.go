@@ -0,0 +1,129 @@
+package character
+
+import (
+	"testing"
+
+	"github.com/r3dpixel/card-parser/property"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const v1SheetJSON = `{
+	"name": "Alice",
+	"description": "A brave knight",
+	"personality": "Loyal",
+	"scenario": "A castle",
+	"first_mes": "Hello!",
+	"mes_example": "<START>\n{{char}}: Hi",
+	"alternate_greetings": ["Hi!", "Greetings!"]
+}`
+
+func TestFromV1Bytes(t *testing.T) {
+	tests := []struct {
+		name     string
+		data     string
+		expected *Content
+		err      error
+	}{
+		{
+			name: "Flat layout",
+			data: v1SheetJSON,
+			expected: &Content{
+				Name:               "Alice",
+				Description:        "A brave knight",
+				Personality:        "Loyal",
+				Scenario:           "A castle",
+				FirstMessage:       "Hello!",
+				MessageExamples:    "<START>\n{{char}}: Hi",
+				AlternateGreetings: property.StringArray{"Hi!", "Greetings!"},
+			},
+		},
+		{
+			name:     "Without alternate greetings",
+			data:     `{"name": "Bob", "first_mes": "Hey"}`,
+			expected: &Content{Name: "Bob", FirstMessage: "Hey"},
+		},
+		{
+			name: "Wrapped layout",
+			data: `{"data": {"name": "Alice"}}`,
+			err:  ErrNotV1,
+		},
+		{
+			name: "Spec fields",
+			data: `{"spec": "chara_card_v2", "name": "Alice"}`,
+			err:  ErrNotV1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sheet, err := FromV1Bytes([]byte(tt.data))
+			if tt.err != nil {
+				assert.ErrorIs(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, RevisionV2, sheet.Revision)
+			assert.Equal(t, SpecV2, sheet.Spec)
+			expected := DefaultSheet(RevisionV2)
+			expected.Content = *tt.expected
+			assert.True(t, expected.DeepEquals(sheet))
+		})
+	}
+
+	t.Run("Malformed JSON", func(t *testing.T) {
+		_, err := FromV1Bytes([]byte(`{"name": `))
+		assert.Error(t, err)
+	})
+}
+
+func TestSheet_ToV1Bytes(t *testing.T) {
+	t.Run("V1 round trip", func(t *testing.T) {
+		sheet, err := FromV1Bytes([]byte(v1SheetJSON))
+		require.NoError(t, err)
+
+		data, dropped, err := sheet.ToV1Bytes()
+		require.NoError(t, err)
+		assert.Empty(t, dropped)
+		assert.JSONEq(t, v1SheetJSON, string(data))
+
+		roundTrip, err := FromV1Bytes(data)
+		require.NoError(t, err)
+		assert.True(t, sheet.DeepEquals(roundTrip))
+	})
+
+	t.Run("V3 round trip", func(t *testing.T) {
+		sheet, err := FromBytes([]byte(comprehensiveSheetJSON))
+		require.NoError(t, err)
+
+		data, dropped, err := sheet.ToV1Bytes()
+		require.NoError(t, err)
+		assert.Equal(t, []string{
+			CreatorNotesField, SystemPromptField, PostHistoryInstructionsField, CharacterBookField, TagsField,
+			CreatorField, CharacterVersionField, NicknameField, CreationDateField, ModificationDateField,
+			ExtensionsField,
+		}, dropped)
+		assert.NotContains(t, string(data), `"data"`)
+		assert.NotContains(t, string(data), `"spec"`)
+
+		// The V1 fields survive the round trip
+		roundTrip, err := FromV1Bytes(data)
+		require.NoError(t, err)
+		assert.Equal(t, RevisionV2, roundTrip.Revision)
+		assert.Equal(t, sheet.Name, roundTrip.Name)
+		assert.Equal(t, sheet.Description, roundTrip.Description)
+		assert.Equal(t, sheet.Personality, roundTrip.Personality)
+		assert.Equal(t, sheet.Scenario, roundTrip.Scenario)
+		assert.Equal(t, sheet.FirstMessage, roundTrip.FirstMessage)
+		assert.Equal(t, sheet.MessageExamples, roundTrip.MessageExamples)
+		assert.Equal(t, sheet.AlternateGreetings, roundTrip.AlternateGreetings)
+		assert.Empty(t, roundTrip.CreatorNotes)
+		assert.Nil(t, roundTrip.CharacterBook)
+
+		// The V1 sheet is imported by the wrapped layout decoder as a legacy sheet
+		legacy, err := FromBytes(data)
+		require.NoError(t, err)
+		assert.True(t, legacy.LegacyImport)
+		assert.True(t, roundTrip.DeepEquals(legacy))
+	})
+}
@@ -0,0 +1,183 @@
+package character
+
+import (
+	"fmt"
+
+	"github.com/r3dpixel/card-parser/property"
+	"github.com/r3dpixel/toolkit/stringsx"
+)
+
+// IssueSeverity classifies a validation issue
+type IssueSeverity string
+
+// Allowed IssueSeverity values
+const (
+	IssueError   IssueSeverity = "error"   // The sheet is malformed (fails the Integrity check)
+	IssueWarning IssueSeverity = "warning" // The sheet is usable, but likely not what the creator intended
+)
+
+// IssueCode stable identifier of a validation issue (safe to use as a UI translation key)
+type IssueCode string
+
+// Allowed IssueCode values
+const (
+	IssueBlankTitle            IssueCode = "blank_title"
+	IssueBlankName             IssueCode = "blank_name"
+	IssueBlankDescription      IssueCode = "blank_description"
+	IssueBlankCreator          IssueCode = "blank_creator"
+	IssueBlankNickname         IssueCode = "blank_nickname"
+	IssueBlankSourceID         IssueCode = "blank_source_id"
+	IssueMissingCreationDate   IssueCode = "missing_creation_date"
+	IssueMissingModDate        IssueCode = "missing_modification_date"
+	IssueModifiedBeforeCreated IssueCode = "modification_before_creation"
+	IssueBlankGreeting         IssueCode = "blank_alternate_greeting"
+	IssueUnreachableBookEntry  IssueCode = "unreachable_book_entry"
+	IssueSpecMismatch          IssueCode = "spec_mismatch"
+	IssueBlankAssetURI         IssueCode = "blank_asset_uri"
+)
+
+// ValidationIssue a failing constraint of a sheet
+type ValidationIssue struct {
+	Code     IssueCode     `json:"code"`
+	Field    string        `json:"field"`
+	Severity IssueSeverity `json:"severity"`
+	Message  string        `json:"message"`
+}
+
+// IsError returns true if the issue is an error
+func (i ValidationIssue) IsError() bool {
+	return i.Severity == IssueError
+}
+
+// String returns the issue in the format "severity: field: message"
+func (i ValidationIssue) String() string {
+	return fmt.Sprintf("%s: %s: %s", i.Severity, i.Field, i.Message)
+}
+
+// Validate returns every failing constraint of the sheet (content and spec), nil if the sheet is valid
+func (s *Sheet) Validate() []ValidationIssue {
+	// Validate the content
+	issues := s.Content.Validate()
+
+	// The spec and version must match the revision
+	if stamp, ok := Stamps[s.Revision]; !ok || !stamp.matches(s.Spec, s.Version) {
+		issues = append(issues, ValidationIssue{
+			Code:     IssueSpecMismatch,
+			Field:    "spec",
+			Severity: IssueWarning,
+			Message:  fmt.Sprintf("spec %q (version %q) does not match revision %d", s.Spec, s.Version, s.Revision),
+		})
+	}
+
+	// Return the issues
+	return issues
+}
+
+// Validate returns every failing constraint of the content, nil if the content is valid
+func (c *Content) Validate() []ValidationIssue {
+	var issues []ValidationIssue
+
+	// Title, name, description, creator, nickname and source_id must not be blank
+	required := []struct {
+		code  IssueCode
+		field string
+		value property.String
+	}{
+		{IssueBlankTitle, TitleField, c.Title},
+		{IssueBlankName, NameField, c.Name},
+		{IssueBlankDescription, DescriptionField, c.Description},
+		{IssueBlankCreator, CreatorField, c.Creator},
+		{IssueBlankNickname, NicknameField, c.Nickname},
+		{IssueBlankSourceID, SourceIDField, c.SourceID},
+	}
+	for _, field := range required {
+		if stringsx.IsBlank(string(field.value)) {
+			issues = append(issues, ValidationIssue{
+				Code:     field.code,
+				Field:    field.field,
+				Severity: IssueError,
+				Message:  fmt.Sprintf("%s is blank", field.field),
+			})
+		}
+	}
+
+	// CreationDate and ModificationDate must be strictly positive
+	if c.CreationDate <= 0 {
+		issues = append(issues, ValidationIssue{
+			Code:     IssueMissingCreationDate,
+			Field:    CreationDateField,
+			Severity: IssueError,
+			Message:  "creation date is not set",
+		})
+	}
+	if c.ModificationDate <= 0 {
+		issues = append(issues, ValidationIssue{
+			Code:     IssueMissingModDate,
+			Field:    ModificationDateField,
+			Severity: IssueError,
+			Message:  "modification date is not set",
+		})
+	}
+
+	// ModificationDate must be greater or equal than CreationDate (ModificationDate >= CreationDate)
+	if c.ModificationDate < c.CreationDate {
+		issues = append(issues, ValidationIssue{
+			Code:     IssueModifiedBeforeCreated,
+			Field:    ModificationDateField,
+			Severity: IssueError,
+			Message:  fmt.Sprintf("modification date %d is before creation date %d", c.ModificationDate, c.CreationDate),
+		})
+	}
+
+	// Alternate greetings must not be blank
+	for index, greeting := range c.AlternateGreetings {
+		if stringsx.IsBlank(greeting) {
+			issues = append(issues, ValidationIssue{
+				Code:     IssueBlankGreeting,
+				Field:    fmt.Sprintf("%s[%d]", AlternateGreetingsField, index),
+				Severity: IssueWarning,
+				Message:  "alternate greeting is blank",
+			})
+		}
+	}
+
+	// Assets must have a URI
+	for index, asset := range c.Assets {
+		if stringsx.IsBlank(string(asset.URI)) {
+			issues = append(issues, ValidationIssue{
+				Code:     IssueBlankAssetURI,
+				Field:    fmt.Sprintf("%s[%d].uri", AssetsField, index),
+				Severity: IssueWarning,
+				Message:  "asset has no URI",
+			})
+		}
+	}
+
+	// Lorebook entries must be reachable (at least one key, or constant)
+	if c.CharacterBook != nil {
+		for index, entry := range c.CharacterBook.Entries {
+			if entry == nil || bool(entry.Constant) || hasKey(entry.Keys) {
+				continue
+			}
+			issues = append(issues, ValidationIssue{
+				Code:     IssueUnreachableBookEntry,
+				Field:    fmt.Sprintf("%s.entries[%d]", CharacterBookField, index),
+				Severity: IssueWarning,
+				Message:  "lorebook entry has no keys and is not constant, so it is never triggered",
+			})
+		}
+	}
+
+	// Return the issues
+	return issues
+}
+
+// hasKey returns true if any of the keys is not blank
+func hasKey(keys property.StringArray) bool {
+	for _, key := range keys {
+		if stringsx.IsNotBlank(key) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,126 @@
+package character
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/r3dpixel/card-parser/property"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// validContent returns a content passing every validation constraint
+func validContent() Content {
+	return Content{
+		Title:              "Title",
+		Name:               "Name",
+		Description:        "Description",
+		Creator:            "Creator",
+		Nickname:           "Nickname",
+		SourceID:           "source",
+		CreationDate:       100,
+		ModificationDate:   200,
+		AlternateGreetings: property.StringArray{"Hello"},
+		CharacterBook: &Book{Entries: []*BookEntry{
+			{BookEntryCore: BookEntryCore{Keys: property.StringArray{"key"}}},
+			{BookEntryCore: BookEntryCore{Constant: true}},
+		}},
+	}
+}
+
+func TestContent_Validate(t *testing.T) {
+	tests := []struct {
+		name     string
+		mutate   func(c *Content)
+		expected []IssueCode
+		fields   []string
+	}{
+		{"Valid", func(c *Content) {}, nil, nil},
+		{"Blank title", func(c *Content) { c.Title = "  " }, []IssueCode{IssueBlankTitle}, []string{"title"}},
+		{"Blank name", func(c *Content) { c.Name = "" }, []IssueCode{IssueBlankName}, []string{"name"}},
+		{"Blank source ID", func(c *Content) { c.SourceID = "\n" }, []IssueCode{IssueBlankSourceID}, []string{"source_id"}},
+		{
+			"Zero dates",
+			func(c *Content) { c.CreationDate, c.ModificationDate = 0, 0 },
+			[]IssueCode{IssueMissingCreationDate, IssueMissingModDate},
+			[]string{"creation_date", "modification_date"},
+		},
+		{
+			"Modified before created",
+			func(c *Content) { c.ModificationDate = 50 },
+			[]IssueCode{IssueModifiedBeforeCreated},
+			[]string{"modification_date"},
+		},
+		{
+			"Whitespace greeting",
+			func(c *Content) { c.AlternateGreetings = append(c.AlternateGreetings, " \t ") },
+			[]IssueCode{IssueBlankGreeting},
+			[]string{"alternate_greetings[1]"},
+		},
+		{
+			"Blank asset URI",
+			func(c *Content) { c.Assets = []Asset{DefaultAsset(), {Type: property.EmotionAsset, Name: "happy"}} },
+			[]IssueCode{IssueBlankAssetURI},
+			[]string{"assets[1].uri"},
+		},
+		{
+			"Unreachable book entry",
+			func(c *Content) {
+				c.CharacterBook.Entries = append(c.CharacterBook.Entries, &BookEntry{BookEntryCore: BookEntryCore{Keys: property.StringArray{" "}}})
+			},
+			[]IssueCode{IssueUnreachableBookEntry},
+			[]string{"character_book.entries[2]"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			content := validContent()
+			tt.mutate(&content)
+
+			issues := content.Validate()
+			require.Len(t, issues, len(tt.expected))
+			for index, issue := range issues {
+				assert.Equal(t, tt.expected[index], issue.Code)
+				assert.Equal(t, tt.fields[index], issue.Field)
+				assert.NotEmpty(t, issue.Message)
+			}
+		})
+	}
+}
+
+func TestContent_Validate_Integrity(t *testing.T) {
+	content := validContent()
+	assert.True(t, content.Integrity())
+
+	// Warnings do not fail the integrity check
+	content.AlternateGreetings = property.StringArray{""}
+	require.Len(t, content.Validate(), 1)
+	assert.Equal(t, IssueWarning, content.Validate()[0].Severity)
+	assert.True(t, content.Integrity())
+
+	// Errors fail the integrity check
+	content.Title = ""
+	assert.False(t, content.Integrity())
+}
+
+func TestSheet_Validate(t *testing.T) {
+	sheet := DefaultSheet(RevisionV3)
+	sheet.Content = validContent()
+	assert.Empty(t, sheet.Validate())
+
+	sheet.Spec = SpecV2
+	issues := sheet.Validate()
+	require.Len(t, issues, 1)
+	assert.Equal(t, IssueSpecMismatch, issues[0].Code)
+	assert.Equal(t, IssueWarning, issues[0].Severity)
+}
+
+func TestValidationIssue_JSON(t *testing.T) {
+	issue := ValidationIssue{Code: IssueBlankTitle, Field: TitleField, Severity: IssueError, Message: "title is blank"}
+
+	data, err := json.Marshal(issue)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"code":"blank_title","field":"title","severity":"error","message":"title is blank"}`, string(data))
+	assert.Equal(t, "error: title: title is blank", issue.String())
+}
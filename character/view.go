@@ -0,0 +1,203 @@
+package character
+
+import (
+	"encoding/json"
+	"io"
+	"slices"
+
+	"github.com/r3dpixel/toolkit/timestamp"
+)
+
+// SheetView read-only snapshot of a Sheet, safe for concurrent use by multiple goroutines
+// The view exposes no mutating operation: getters return copies of slices, maps and lorebooks
+type SheetView struct {
+	sheet *Sheet
+}
+
+// Freeze returns a read-only snapshot of the sheet (later changes to the sheet are not reflected in the view)
+func (s *Sheet) Freeze() SheetView {
+	// Deep copy the sheet, so the snapshot cannot be changed through the original
+	frozen := &Sheet{Spec: s.Spec, Version: s.Version, Revision: s.Revision}
+	frozen.Content = *s.Content.Clone()
+	frozen.RawTopLevel = cloneRawMembers(s.RawTopLevel)
+	// Return the view
+	return SheetView{sheet: frozen}
+}
+
+// Thaw returns a mutable deep copy of the frozen sheet
+func (v SheetView) Thaw() *Sheet {
+	sheet := &Sheet{Spec: v.sheet.Spec, Version: v.sheet.Version, Revision: v.sheet.Revision}
+	sheet.Content = *v.sheet.Content.Clone()
+	sheet.RawTopLevel = cloneRawMembers(v.sheet.RawTopLevel)
+	return sheet
+}
+
+// cloneRawMembers returns a deep copy of the raw members (nil if there are none)
+func cloneRawMembers(members map[string]json.RawMessage) map[string]json.RawMessage {
+	if members == nil {
+		return nil
+	}
+	clone := make(map[string]json.RawMessage, len(members))
+	for key, value := range members {
+		clone[key] = slices.Clone(value)
+	}
+	return clone
+}
+
+// Spec returns the spec of the sheet
+func (v SheetView) Spec() Spec { return v.sheet.Spec }
+
+// Version returns the spec version of the sheet
+func (v SheetView) Version() Version { return v.sheet.Version }
+
+// Revision returns the revision of the sheet
+func (v SheetView) Revision() Revision { return v.sheet.Revision }
+
+// Title returns the title
+func (v SheetView) Title() string { return string(v.sheet.Title) }
+
+// Name returns the name
+func (v SheetView) Name() string { return string(v.sheet.Name) }
+
+// Description returns the description
+func (v SheetView) Description() string { return string(v.sheet.Description) }
+
+// Personality returns the personality
+func (v SheetView) Personality() string { return string(v.sheet.Personality) }
+
+// Scenario returns the scenario
+func (v SheetView) Scenario() string { return string(v.sheet.Scenario) }
+
+// FirstMessage returns the first message
+func (v SheetView) FirstMessage() string { return string(v.sheet.FirstMessage) }
+
+// MessageExamples returns the message examples
+func (v SheetView) MessageExamples() string { return string(v.sheet.MessageExamples) }
+
+// CreatorNotes returns the creator notes
+func (v SheetView) CreatorNotes() string { return string(v.sheet.CreatorNotes) }
+
+// SystemPrompt returns the system prompt
+func (v SheetView) SystemPrompt() string { return string(v.sheet.SystemPrompt) }
+
+// PostHistoryInstructions returns the post history instructions
+func (v SheetView) PostHistoryInstructions() string { return string(v.sheet.PostHistoryInstructions) }
+
+// AlternateGreetings returns a copy of the alternate greetings
+func (v SheetView) AlternateGreetings() []string { return slices.Clone(v.sheet.AlternateGreetings) }
+
+// GroupGreetings returns a copy of the group only greetings
+func (v SheetView) GroupGreetings() []string { return slices.Clone(v.sheet.GroupGreetings) }
+
+// CharacterBook returns a copy of the lorebook (nil if there is no lorebook)
+func (v SheetView) CharacterBook() *Book {
+	if v.sheet.CharacterBook == nil {
+		return nil
+	}
+	return v.sheet.CharacterBook.Clone()
+}
+
+// Tags returns a copy of the tags
+func (v SheetView) Tags() []string { return slices.Clone(v.sheet.Tags) }
+
+// Creator returns the creator
+func (v SheetView) Creator() string { return string(v.sheet.Creator) }
+
+// CharacterVersion returns the character version
+func (v SheetView) CharacterVersion() string { return string(v.sheet.CharacterVersion) }
+
+// DepthPrompt returns the depth prompt
+func (v SheetView) DepthPrompt() DepthPrompt { return v.sheet.DepthPrompt }
+
+// Risu returns a deep copy of the RisuAI extensions (nil if absent)
+func (v SheetView) Risu() *RisuExtensions { return v.sheet.Risu.Clone() }
+
+// RegexScripts returns a deep copy of the regex scripts
+func (v SheetView) RegexScripts() []RegexScript { return cloneRegexScripts(v.sheet.RegexScripts) }
+
+// Extensions returns a deep copy of the extensions
+func (v SheetView) Extensions() map[string]any { return cloneMap(v.sheet.Extensions) }
+
+// Assets returns a copy of the assets
+func (v SheetView) Assets() []Asset { return slices.Clone(v.sheet.Assets) }
+
+// Nickname returns the nickname
+func (v SheetView) Nickname() string { return string(v.sheet.Nickname) }
+
+// CreatorNotesMultilingual returns a copy of the multilingual creator notes
+func (v SheetView) CreatorNotesMultilingual() map[string]string {
+	if v.sheet.CreatorNotesMultilingual == nil {
+		return nil
+	}
+	notes := make(map[string]string, len(v.sheet.CreatorNotesMultilingual))
+	for language, note := range v.sheet.CreatorNotesMultilingual {
+		notes[language] = string(note)
+	}
+	return notes
+}
+
+// Source returns a copy of the sources
+func (v SheetView) Source() []string { return slices.Clone(v.sheet.Source) }
+
+// CreationDate returns the creation date
+func (v SheetView) CreationDate() timestamp.Seconds { return v.sheet.CreationDate }
+
+// ModificationDate returns the modification date
+func (v SheetView) ModificationDate() timestamp.Seconds { return v.sheet.ModificationDate }
+
+// SourceID returns the source ID
+func (v SheetView) SourceID() string { return string(v.sheet.SourceID) }
+
+// CharacterID returns the character ID
+func (v SheetView) CharacterID() string { return string(v.sheet.CharacterID) }
+
+// PlatformID returns the platform ID
+func (v SheetView) PlatformID() string { return string(v.sheet.PlatformID) }
+
+// DirectLink returns the direct link
+func (v SheetView) DirectLink() string { return string(v.sheet.DirectLink) }
+
+// Integrity checks if the sheet is malformed (missing necessary fields)
+func (v SheetView) Integrity() bool { return v.sheet.Integrity() }
+
+// Validate returns every failing constraint of the frozen sheet
+func (v SheetView) Validate() []ValidationIssue { return v.sheet.Validate() }
+
+// MarshalJSON marshals the frozen sheet into JSON format
+func (v SheetView) MarshalJSON() ([]byte, error) { return v.sheet.MarshalJSON() }
+
+// ToJSON converts the frozen sheet to its JSON representation and streams it to the given output io.Writer
+func (v SheetView) ToJSON(w io.Writer, opts ...EncodeOption) error {
+	return v.sheet.ToJSON(w, opts...)
+}
+
+// ToBytes converts the frozen sheet to its JSON representation and returns the JSON byte slice
+func (v SheetView) ToBytes(opts ...EncodeOption) ([]byte, error) { return v.sheet.ToBytes(opts...) }
+
+// CanonicalBytes returns the canonical JSON of the normalized frozen sheet
+func (v SheetView) CanonicalBytes() ([]byte, error) { return v.sheet.CanonicalBytes() }
+
+// Fingerprint returns the versioned hash of the canonical frozen sheet
+func (v SheetView) Fingerprint() string { return v.sheet.Fingerprint() }
+
+// DeepEquals returns true if the frozen sheet is deeply equal to the other sheet
+func (v SheetView) DeepEquals(other *Sheet) bool { return v.sheet.DeepEquals(other) }
+
+// CompatibilityReport returns the features used by the frozen sheet that the target frontend does not support
+func (v SheetView) CompatibilityReport(target Frontend) []CompatNote {
+	return CompatibilityReport(v.sheet, target)
+}
+
+// Stats returns the character and token counts of the frozen content (see Content.Stats)
+func (v SheetView) Stats(opts StatsOptions) ContentStats { return v.sheet.Stats(opts) }
+
+// PlainText renders the text fields of the frozen content without HTML and markdown (see Content.PlainText)
+func (v SheetView) PlainText(fields ...string) map[string]string { return v.sheet.PlainText(fields...) }
+
+// ParsedExamples returns the example dialogues of the frozen content (see Content.ParsedExamples)
+func (v SheetView) ParsedExamples() []ExampleDialogue { return v.sheet.ParsedExamples() }
+
+// FitPromptWithin returns a trimmed (mutable) copy of the frozen content that fits within the token budget
+func (v SheetView) FitPromptWithin(budget int, tok Tokenizer, policy PromptFitPolicy) (*Content, FitReport) {
+	return v.sheet.FitPromptWithin(budget, tok, policy)
+}
@@ -0,0 +1,94 @@
+package character
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSheet_Freeze(t *testing.T) {
+	sheet, err := FromBytes([]byte(comprehensiveSheetJSON))
+	require.NoError(t, err)
+	view := sheet.Freeze()
+
+	t.Run("Getters", func(t *testing.T) {
+		assert.Equal(t, SpecV3, view.Spec())
+		assert.Equal(t, RevisionV3, view.Revision())
+		assert.Equal(t, "ComprehensiveChar", view.Name())
+		assert.Equal(t, "CompChar", view.Nickname())
+		assert.Len(t, view.AlternateGreetings(), 3)
+		assert.Equal(t, "Think deeply about this comprehensive character.", view.DepthPrompt().Prompt)
+		assert.Len(t, view.CharacterBook().Entries, 2)
+		assert.True(t, view.DeepEquals(sheet))
+	})
+
+	t.Run("Snapshot", func(t *testing.T) {
+		fingerprint := view.Fingerprint()
+
+		// Changing the original sheet, or a thawed copy, does not affect the view
+		sheet.Description = "Changed"
+		changed := view.Thaw()
+		changed.Name = "Changed"
+		changed.AlternateGreetings[0] = "Changed"
+		changed.CharacterBook.Entries[0].Content = "Changed"
+
+		assert.Equal(t, "ComprehensiveChar", view.Name())
+		assert.Equal(t, "A character with every possible field populated for testing.", view.Description())
+		assert.Equal(t, "Hi there! Ready for some comprehensive testing?", view.AlternateGreetings()[0])
+		assert.Equal(t, fingerprint, view.Fingerprint())
+	})
+
+	t.Run("Getters return copies", func(t *testing.T) {
+		view.AlternateGreetings()[0] = "Changed"
+		view.Extensions()["custom_extension_1"] = "changed"
+		view.CharacterBook().Entries[0].Name = "Changed"
+
+		assert.Equal(t, "Hi there! Ready for some comprehensive testing?", view.AlternateGreetings()[0])
+		assert.Equal(t, "value1", view.Extensions()["custom_extension_1"])
+		assert.Equal(t, "Comprehensive Entry", string(view.CharacterBook().Entries[0].Name))
+	})
+}
+
+func TestSheetView_Concurrent(t *testing.T) {
+	sheet, err := FromBytes([]byte(comprehensiveSheetJSON))
+	require.NoError(t, err)
+	view := sheet.Freeze()
+
+	expectedBytes, err := view.ToBytes()
+	require.NoError(t, err)
+	expectedFingerprint := view.Fingerprint()
+	expectedStats := view.Stats(StatsOptions{})
+	expectedPlainText := view.PlainText()
+	expectedExamples := view.ParsedExamples()
+
+	// Hammer the view from multiple goroutines (run with -race)
+	const workers = 32
+	wg := sync.WaitGroup{}
+	for worker := range workers {
+		wg.Go(func() {
+			for range 20 {
+				switch worker % 6 {
+				case 0:
+					data, err := view.ToBytes()
+					assert.NoError(t, err)
+					assert.Equal(t, expectedBytes, data)
+				case 1:
+					assert.Equal(t, expectedFingerprint, view.Fingerprint())
+				case 2:
+					assert.Len(t, view.CompatibilityReport(FrontendJanitorAI), 9)
+				case 3:
+					trimmed, _ := view.FitPromptWithin(10, wordTokenizer, PromptFitPolicy{KeepGreetings: 1})
+					assert.Len(t, trimmed.AlternateGreetings, 1)
+				case 4:
+					assert.Equal(t, expectedStats, view.Stats(StatsOptions{}))
+				case 5:
+					assert.Equal(t, expectedPlainText, view.PlainText())
+					assert.Equal(t, expectedExamples, view.ParsedExamples())
+				}
+			}
+		})
+	}
+	wg.Wait()
+}
@@ -0,0 +1,289 @@
+package character
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"maps"
+	"strconv"
+
+	"github.com/r3dpixel/card-parser/internal/codec"
+	"github.com/r3dpixel/card-parser/property"
+	"github.com/r3dpixel/toolkit/jsonx"
+	"github.com/r3dpixel/toolkit/stringsx"
+)
+
+// worldInfoFields are the known keys of a world-info envelope (other keys are kept in the book extensions)
+var worldInfoFields = []string{"name", "description", "entries"}
+
+// worldInfoEntryFields are the known keys of a world-info entry (other keys are kept in the entry raw extensions)
+var worldInfoEntryFields = jsonx.ExtractJsonFieldNames(worldInfoEntry{})
+
+// worldInfoEntry entry of a standalone SillyTavern world-info file
+type worldInfoEntry struct {
+	UID              property.Union          `json:"uid"`
+	Key              property.StringArray    `json:"key"`
+	KeySecondary     property.StringArray    `json:"keysecondary"`
+	Comment          property.String         `json:"comment"`
+	Content          property.String         `json:"content"`
+	Constant         property.Bool           `json:"constant"`
+	Selective        property.Bool           `json:"selective"`
+	SelectiveLogic   property.SelectiveLogic `json:"selectiveLogic"`
+	Order            property.Integer        `json:"order"`
+	Position         property.LorePosition   `json:"position"`
+	Disable          property.Bool           `json:"disable"`
+	Probability      property.Float          `json:"probability"`
+	Depth            property.Integer        `json:"depth"`
+	Role             property.Role           `json:"role"`
+	CaseSensitive    property.Bool           `json:"caseSensitive"`
+	MatchWholeWords  property.Bool           `json:"matchWholeWords"`
+	Sticky           property.Integer        `json:"sticky"`
+	Cooldown         property.Integer        `json:"cooldown"`
+	Delay            property.Integer        `json:"delay"`
+	AutomationID     property.String         `json:"automationId"`
+	Group            property.String         `json:"group"`
+	GroupOverride    property.Bool           `json:"groupOverride"`
+	GroupWeight      property.Float          `json:"groupWeight"`
+	UseProbability   property.Bool           `json:"useProbability"`
+	PreventRecursion property.Bool           `json:"preventRecursion"`
+}
+
+// BookFromWorldInfo decodes a standalone SillyTavern world-info file into a Book
+// Entries are accepted as an object keyed by uid (SillyTavern layout) or as an array;
+// unknown entry fields are kept in the entry raw extensions, unknown envelope fields in the book extensions
+func BookFromWorldInfo(r io.Reader) (*Book, error) {
+	// Read the whole input (the nesting depth is limited before decoding)
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	data, _ = truncateDepth(data, DefaultMaxNestingDepth)
+
+	// Decode the envelope, keeping the entries raw
+	var envelope struct {
+		Name        property.String `json:"name"`
+		Description property.String `json:"description"`
+		Entries     json.RawMessage `json:"entries"`
+	}
+	if err := codec.Unmarshal(data, &envelope); err != nil {
+		return nil, err
+	}
+	book := &Book{
+		Name:        envelope.Name,
+		Description: envelope.Description,
+		Settings:    DefaultBookExtensions(),
+		Entries:     []*BookEntry{},
+	}
+
+	// Keep the unknown envelope fields as book extensions
+	var rawMap map[string]any
+	if err := codec.Unmarshal(data, &rawMap); err != nil {
+		return nil, err
+	}
+	for _, field := range worldInfoFields {
+		delete(rawMap, field)
+	}
+	if len(rawMap) > 0 {
+		book.Extensions = rawMap
+	}
+
+	// Collect the raw entries with their keys (map form), or their indexes (array form)
+	var keys []string
+	var rawEntries map[string]json.RawMessage
+	if entries := bytes.TrimSpace(envelope.Entries); len(entries) > 0 && entries[0] == '[' {
+		var entryList []json.RawMessage
+		if err := codec.Unmarshal(entries, &entryList); err != nil {
+			return nil, err
+		}
+		rawEntries = make(map[string]json.RawMessage, len(entryList))
+		for index, entry := range entryList {
+			key := strconv.Itoa(index)
+			keys = append(keys, key)
+			rawEntries[key] = entry
+		}
+	} else if len(entries) > 0 {
+		if err := codec.Unmarshal(entries, &rawEntries); err != nil {
+			return nil, err
+		}
+		keys = sortedEntryKeys(rawEntries)
+	}
+
+	// Decode the entries
+	for _, key := range keys {
+		entry, err := bookEntryFromWorldInfo(rawEntries[key], key)
+		if err != nil {
+			return nil, err
+		}
+		if entry != nil {
+			book.Entries = append(book.Entries, entry)
+		}
+	}
+
+	// Return the book
+	return book, nil
+}
+
+// bookEntryFromWorldInfo decodes a world-info entry into a BookEntry (nil for null entries)
+func bookEntryFromWorldInfo(data []byte, key string) (*BookEntry, error) {
+	// Unmarshal the raw map first (null entries are skipped)
+	var rawMap map[string]any
+	if err := codec.Unmarshal(data, &rawMap); err != nil {
+		return nil, err
+	}
+	if rawMap == nil {
+		return nil, nil
+	}
+
+	// Unmarshal the typed entry (starting from the default values)
+	defaults := DefaultBookEntry()
+	wiEntry := worldInfoEntry{
+		Key:            defaults.Keys,
+		KeySecondary:   defaults.SecondaryKeys,
+		SelectiveLogic: defaults.Extensions.SelectiveLogic,
+		Order:          defaults.InsertionOrder,
+		Position:       defaults.Extensions.LorePosition,
+		Probability:    defaults.Extensions.Probability,
+		Depth:          defaults.Extensions.Depth,
+		Role:           defaults.Extensions.Role,
+		GroupWeight:    defaults.Extensions.GroupWeight,
+		UseProbability: defaults.Extensions.UseProbability,
+	}
+	if err := codec.Unmarshal(data, &wiEntry); err != nil {
+		return nil, err
+	}
+
+	// Map the world-info fields onto the entry
+	entry := defaults
+	entry.ID = wiEntry.UID
+	entry.Keys = wiEntry.Key
+	entry.SecondaryKeys = wiEntry.KeySecondary
+	entry.Comment = wiEntry.Comment
+	entry.Content = wiEntry.Content
+	entry.Constant = wiEntry.Constant
+	entry.Selective = wiEntry.Selective
+	entry.InsertionOrder = wiEntry.Order
+	entry.Enabled = !wiEntry.Disable
+	entry.Extensions.LorePosition = wiEntry.Position
+	entry.Extensions.SelectiveLogic = wiEntry.SelectiveLogic
+	entry.Extensions.Probability = wiEntry.Probability
+	entry.Extensions.Depth = wiEntry.Depth
+	entry.Extensions.Role = wiEntry.Role
+	entry.Extensions.CaseSensitive = wiEntry.CaseSensitive
+	entry.Extensions.MatchWholeWords = wiEntry.MatchWholeWords
+	entry.Extensions.Sticky = wiEntry.Sticky
+	entry.Extensions.Cooldown = wiEntry.Cooldown
+	entry.Extensions.Delay = wiEntry.Delay
+	entry.Extensions.AutomationID = wiEntry.AutomationID
+	entry.Extensions.Group = wiEntry.Group
+	entry.Extensions.GroupOverride = wiEntry.GroupOverride
+	entry.Extensions.GroupWeight = wiEntry.GroupWeight
+	entry.Extensions.UseProbability = wiEntry.UseProbability
+	entry.Extensions.PreventRecursion = wiEntry.PreventRecursion
+	entry.MirrorNameAndComment()
+
+	// Use the key as ID when the entry has none
+	if _, hasUID := rawMap["uid"]; !hasUID {
+		entry.ID.OnString(key)
+	}
+
+	// Keep the unknown fields as raw extensions
+	for _, field := range worldInfoEntryFields {
+		delete(rawMap, field)
+	}
+	entry.RawExtensions = rawMap
+
+	// Return the entry
+	return entry, nil
+}
+
+// ToWorldInfo encodes the book as a standalone SillyTavern world-info file (entries keyed by uid)
+// Entries without an integer ID (or with a duplicate one) get the next free uid; raw extensions are written back as
+// entry fields, and book extensions as envelope fields (known fields win)
+func (b *Book) ToWorldInfo(w io.Writer) error {
+	// Write back the book extensions as envelope fields
+	envelope := maps.Clone(b.Extensions)
+	if envelope == nil {
+		envelope = make(map[string]any, len(worldInfoFields))
+	}
+	if stringsx.IsNotBlank(string(b.Name)) {
+		envelope["name"] = string(b.Name)
+	}
+	if stringsx.IsNotBlank(string(b.Description)) {
+		envelope["description"] = string(b.Description)
+	}
+
+	// Find the first free uid
+	nextUID := 0
+	for _, entry := range b.Entries {
+		if entry != nil && entry.ID.IntValue != nil {
+			nextUID = max(nextUID, *entry.ID.IntValue+1)
+		}
+	}
+
+	// Encode the entries
+	entries := make(map[string]any, len(b.Entries))
+	for _, entry := range b.Entries {
+		if entry == nil {
+			continue
+		}
+
+		// Pick the uid (integer ID, or the next free uid)
+		var uid int
+		if entry.ID.IntValue != nil && entries[strconv.Itoa(*entry.ID.IntValue)] == nil {
+			uid = *entry.ID.IntValue
+		} else {
+			uid, nextUID = nextUID, nextUID+1
+		}
+
+		// Map the entry onto the world-info fields
+		fields, err := jsonx.StructToMap(&worldInfoEntry{
+			UID:              property.UnionFromInt(uid),
+			Key:              entry.Keys,
+			KeySecondary:     entry.SecondaryKeys,
+			Comment:          entry.Comment,
+			Content:          entry.Content,
+			Constant:         entry.Constant,
+			Selective:        entry.Selective,
+			SelectiveLogic:   entry.Extensions.SelectiveLogic,
+			Order:            entry.InsertionOrder,
+			Position:         entry.Extensions.LorePosition,
+			Disable:          !entry.Enabled,
+			Probability:      entry.Extensions.Probability,
+			Depth:            entry.Extensions.Depth,
+			Role:             entry.Extensions.Role,
+			CaseSensitive:    entry.Extensions.CaseSensitive,
+			MatchWholeWords:  entry.Extensions.MatchWholeWords,
+			Sticky:           entry.Extensions.Sticky,
+			Cooldown:         entry.Extensions.Cooldown,
+			Delay:            entry.Extensions.Delay,
+			AutomationID:     entry.Extensions.AutomationID,
+			Group:            entry.Extensions.Group,
+			GroupOverride:    entry.Extensions.GroupOverride,
+			GroupWeight:      entry.Extensions.GroupWeight,
+			UseProbability:   entry.Extensions.UseProbability,
+			PreventRecursion: entry.Extensions.PreventRecursion,
+		})
+		if err != nil {
+			return err
+		}
+
+		// Write back the raw extensions (known fields win)
+		entryFields := maps.Clone(entry.RawExtensions)
+		if entryFields == nil {
+			entryFields = make(map[string]any, len(fields))
+		}
+		maps.Copy(entryFields, fields)
+		entries[strconv.Itoa(uid)] = entryFields
+	}
+	envelope["entries"] = entries
+
+	// Encode the world-info
+	data, err := codec.Marshal(envelope)
+	if err != nil {
+		return err
+	}
+
+	// Write the encoded world-info
+	_, err = w.Write(data)
+	return err
+}
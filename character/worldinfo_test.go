@@ -0,0 +1,189 @@
+package character
+
+import (
+	"bytes"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/r3dpixel/card-parser/property"
+	"github.com/r3dpixel/toolkit/sonicx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// readWorldInfo decodes the world-info file from the testdata directory
+func readWorldInfo(t *testing.T, name string) *Book {
+	t.Helper()
+	file, err := os.Open("testdata/" + name)
+	require.NoError(t, err)
+	defer file.Close()
+	book, err := BookFromWorldInfo(file)
+	require.NoError(t, err)
+	return book
+}
+
+func TestBookFromWorldInfo_MapLayout(t *testing.T) {
+	book := readWorldInfo(t, "worldinfo_map.json")
+	require.Len(t, book.Entries, 3)
+
+	// Entries are ordered by uid
+	var uids []int
+	for _, entry := range book.Entries {
+		require.NotNil(t, entry.ID.IntValue)
+		uids = append(uids, *entry.ID.IntValue)
+	}
+	assert.Equal(t, []int{0, 3, 12}, uids)
+
+	// Default-looking entry
+	kingdom := book.Entries[0]
+	assert.Equal(t, property.StringArray{"Eldoria", "kingdom"}, kingdom.Keys)
+	assert.Equal(t, property.String("Kingdom of Eldoria"), kingdom.Comment)
+	assert.Equal(t, property.String("Kingdom of Eldoria"), kingdom.Name)
+	assert.True(t, bool(kingdom.Enabled))
+	assert.True(t, bool(kingdom.Selective))
+	assert.Equal(t, property.Integer(100), kingdom.InsertionOrder)
+	assert.Equal(t, property.BeforeCharPosition, kingdom.Extensions.LorePosition)
+	assert.Equal(t, property.DefaultRole, kingdom.Extensions.Role)
+	assert.False(t, bool(kingdom.Extensions.CaseSensitive))
+
+	// Constant entry
+	storm := book.Entries[1]
+	assert.True(t, bool(storm.Constant))
+	assert.Equal(t, property.AfterExampleMessages, storm.Extensions.LorePosition)
+	assert.True(t, bool(storm.Extensions.PreventRecursion))
+	assert.NotContains(t, storm.RawExtensions, "preventRecursion")
+
+	// Disabled entry at depth
+	mira := book.Entries[2]
+	assert.False(t, bool(mira.Enabled))
+	assert.Equal(t, property.StringArray{"council", "silver"}, mira.SecondaryKeys)
+	assert.Equal(t, property.AtDepth, mira.Extensions.LorePosition)
+	assert.Equal(t, property.SelectiveAndAll, mira.Extensions.SelectiveLogic)
+	assert.Equal(t, property.AssistantRole, mira.Extensions.Role)
+	assert.Equal(t, property.Float(75), mira.Extensions.Probability)
+	assert.Equal(t, property.Integer(2), mira.Extensions.Depth)
+	assert.Equal(t, property.Integer(3), mira.Extensions.Sticky)
+	assert.Equal(t, property.Integer(1), mira.Extensions.Cooldown)
+	assert.True(t, bool(mira.Extensions.CaseSensitive))
+	assert.True(t, bool(mira.Extensions.MatchWholeWords))
+
+	assert.Equal(t, property.String("people"), mira.Extensions.Group)
+	assert.Equal(t, property.Float(100), mira.Extensions.GroupWeight)
+	assert.True(t, bool(mira.Extensions.UseProbability))
+
+	// Unknown fields land in the raw extensions
+	assert.NotContains(t, mira.RawExtensions, "group")
+	assert.Equal(t, true, mira.RawExtensions["addMemo"])
+	assert.NotContains(t, mira.RawExtensions, "uid")
+	assert.NotContains(t, mira.RawExtensions, "disable")
+	assert.Nil(t, book.Extensions)
+}
+
+func TestBookFromWorldInfo_ArrayLayout(t *testing.T) {
+	book := readWorldInfo(t, "worldinfo_array.json")
+	assert.Equal(t, property.String("Harbor Lore"), book.Name)
+	assert.Contains(t, book.Extensions, "originalData")
+	require.Len(t, book.Entries, 2)
+
+	// Messy values are parsed by the property parsers
+	harbor := book.Entries[0]
+	require.NotNil(t, harbor.ID.IntValue)
+	assert.Equal(t, 0, *harbor.ID.IntValue)
+	assert.True(t, bool(harbor.Enabled))
+	assert.Equal(t, property.Integer(120), harbor.InsertionOrder)
+	assert.Equal(t, property.AfterCharPosition, harbor.Extensions.LorePosition)
+	assert.Equal(t, property.SelectiveAndAll, harbor.Extensions.SelectiveLogic)
+	assert.Equal(t, property.AssistantRole, harbor.Extensions.Role)
+	assert.Equal(t, property.Float(60), harbor.Extensions.Probability)
+
+	// Missing fields get the default values
+	lighthouse := book.Entries[1]
+	require.NotNil(t, lighthouse.ID.IntValue)
+	assert.Equal(t, 7, *lighthouse.ID.IntValue)
+	assert.Equal(t, property.StringArray{"lighthouse"}, lighthouse.Keys)
+	assert.Equal(t, property.StringArray{}, lighthouse.SecondaryKeys)
+	assert.False(t, bool(lighthouse.Enabled))
+	assert.Equal(t, property.Float(DefaultEntryProbability), lighthouse.Extensions.Probability)
+	assert.Equal(t, property.Integer(DefaultEntryDepth), lighthouse.Extensions.Depth)
+	assert.Equal(t, DefaultBookEntry().InsertionOrder, lighthouse.InsertionOrder)
+}
+
+func TestBook_ToWorldInfo(t *testing.T) {
+	for _, name := range []string{"worldinfo_map.json", "worldinfo_array.json"} {
+		t.Run(name, func(t *testing.T) {
+			book := readWorldInfo(t, name)
+
+			var buf bytes.Buffer
+			require.NoError(t, book.ToWorldInfo(&buf))
+
+			// The output uses the SillyTavern layout
+			var raw struct {
+				Entries map[string]map[string]any `json:"entries"`
+			}
+			require.NoError(t, sonicx.Config.Unmarshal(buf.Bytes(), &raw))
+			require.Len(t, raw.Entries, len(book.Entries))
+			for key, entry := range raw.Entries {
+				assert.Contains(t, entry, "disable")
+				assert.Contains(t, entry, "keysecondary")
+				assert.Equal(t, key, strconv.Itoa(int(entry["uid"].(float64))))
+			}
+
+			// Round trip
+			roundTrip, err := BookFromWorldInfo(&buf)
+			require.NoError(t, err)
+			assert.Equal(t, book.Name, roundTrip.Name)
+			assert.Equal(t, book.Extensions, roundTrip.Extensions)
+			require.Len(t, roundTrip.Entries, len(book.Entries))
+			for index, entry := range roundTrip.Entries {
+				assert.Equal(t, book.Entries[index].BookEntryCore, entry.BookEntryCore)
+				assert.Equal(t, book.Entries[index].Extensions, entry.Extensions)
+				assert.Equal(t, book.Entries[index].RawExtensions, entry.RawExtensions)
+			}
+		})
+	}
+
+	t.Run("Missing and duplicate IDs get free uids", func(t *testing.T) {
+		first, second, third := DefaultBookEntry(), DefaultBookEntry(), DefaultBookEntry()
+		first.ID.OnString("5")
+		second.ID.OnString("5")
+		third.ID.OnString("name")
+		book := &Book{Entries: []*BookEntry{first, second, third}}
+
+		var buf bytes.Buffer
+		require.NoError(t, book.ToWorldInfo(&buf))
+		roundTrip, err := BookFromWorldInfo(&buf)
+		require.NoError(t, err)
+
+		var uids []int
+		for _, entry := range roundTrip.Entries {
+			uids = append(uids, *entry.ID.IntValue)
+		}
+		assert.Equal(t, []int{5, 6, 7}, uids)
+	})
+}
+
+func TestBookFromWorldInfo_Invalid(t *testing.T) {
+	tests := []struct {
+		name     string
+		jsonData string
+	}{
+		{"Invalid JSON", `{"entries":`},
+		{"Invalid entry", `{"entries":{"0":"entry"}}`},
+		{"Invalid entry list", `{"entries":[1]}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := BookFromWorldInfo(strings.NewReader(tt.jsonData))
+			assert.Error(t, err)
+		})
+	}
+
+	t.Run("Empty world-info", func(t *testing.T) {
+		book, err := BookFromWorldInfo(strings.NewReader(`{"entries":{"0":null}}`))
+		require.NoError(t, err)
+		assert.Empty(t, book.Entries)
+	})
+}
@@ -0,0 +1,445 @@
+// Package fixtures generates deterministic, realistic synthetic chara cards for tests and benchmarks
+// Every generated value is derived from the seed, so a failing test can cite the seed to reproduce the card
+package fixtures
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	stdpng "image/png"
+	"io"
+	"math/rand/v2"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/r3dpixel/card-parser/character"
+	"github.com/r3dpixel/card-parser/internal/codec"
+	"github.com/r3dpixel/card-parser/png"
+	"github.com/r3dpixel/card-parser/property"
+	"github.com/r3dpixel/toolkit/timestamp"
+)
+
+// Default generation values (used for zero-valued options)
+const (
+	DefaultWords  = 40 // Default number of words of long text fields
+	DefaultWidth  = 64 // Default artwork width in pixels
+	DefaultHeight = 96 // Default artwork height in pixels
+	baseTimestamp = 1_600_000_000
+)
+
+// GenOptions options of the generated cards
+type GenOptions struct {
+	Revision  character.Revision // Card revision (defaults to V3)
+	Greetings int                // Number of alternate greetings
+	Entries   int                // Number of lorebook entries (no lorebook if zero)
+	Words     int                // Approximate number of words of long text fields (defaults to DefaultWords)
+	Messy     bool               // Generate messy values (broken macros, fancy quotes, padding, loosely typed JSON)
+	Width     int                // Artwork width in pixels (defaults to DefaultWidth)
+	Height    int                // Artwork height in pixels (defaults to DefaultHeight)
+}
+
+// Fixed pools of plausible values
+var (
+	namePool    = []string{"Aria", "Bram", "Celeste", "Dorian", "Elowen", "Fenris", "Gwen", "Hiro", "Isolde", "Jasper"}
+	tagPool     = []string{"Fantasy", "Sci-Fi", "Romance", "Adventure", "Mystery", "Horror", "Comedy", "Slice of Life", "Drama", "OC"}
+	creatorPool = []string{"quillsmith", "@nightowl", "Ember Works", "anon_writer", "StarForge"}
+	loremPool   = []string{
+		"lorem", "ipsum", "dolor", "sit", "amet", "consectetur", "adipiscing", "elit", "sed", "do", "eiusmod", "tempor",
+		"incididunt", "ut", "labore", "et", "dolore", "magna", "aliqua", "enim", "ad", "minim", "veniam", "quis",
+		"nostrud", "exercitation", "ullamco", "laboris", "nisi", "aliquip", "ex", "ea", "commodo", "consequat",
+	}
+	macroPool      = []string{"{{char}}", "{{user}}"}
+	messyMacroPool = []string{"{char}}", "{{user}", "{{{char}}}", "{user}"}
+	fancyQuotes    = [][2]string{{"“", "”"}, {"«", "»"}, {"「", "」"}, {"‘", "’"}}
+	languagePool   = []string{"en", "fr", "ja", "es"}
+	assetTypes     = []string{"icon", "background", "emotion"}
+)
+
+// generator generates the card values from a seeded random source
+type generator struct {
+	rnd  *rand.Rand
+	opts GenOptions
+}
+
+// newGenerator creates a generator for the seed, applying the defaults to the options
+func newGenerator(seed int64, opts GenOptions) *generator {
+	if opts.Revision == 0 {
+		opts.Revision = character.RevisionV3
+	}
+	if opts.Words <= 0 {
+		opts.Words = DefaultWords
+	}
+	if opts.Width <= 0 {
+		opts.Width = DefaultWidth
+	}
+	if opts.Height <= 0 {
+		opts.Height = DefaultHeight
+	}
+	return &generator{rnd: rand.New(rand.NewPCG(uint64(seed), 0x5eed)), opts: opts}
+}
+
+// Sheet generates a sheet covering the full schema, reproducible from the seed
+func Sheet(seed int64, opts GenOptions) *character.Sheet {
+	return newGenerator(seed, opts).sheet()
+}
+
+// JSON generates the JSON of Sheet(seed, opts)
+// When opts.Messy is set, the JSON is loosely typed (numbers and booleans as strings, enums as names,
+// straggler entry extensions), but it decodes to the same sheet with the tolerant parsers
+func JSON(seed int64, opts GenOptions) []byte {
+	sheet := Sheet(seed, opts)
+	data, err := sheet.ToBytes()
+	if err != nil {
+		panic(fmt.Sprintf("fixtures: seed %d: %v", seed, err))
+	}
+	if !opts.Messy {
+		return data
+	}
+	return messyJSON(seed, data)
+}
+
+// PNG generates a PNG card (artwork of the configured dimensions) embedding JSON(seed, opts)
+func PNG(seed int64, opts GenOptions) []byte {
+	g := newGenerator(seed, opts)
+
+	// Encode the artwork
+	artwork := new(bytes.Buffer)
+	if err := stdpng.Encode(artwork, g.artwork()); err != nil {
+		panic(fmt.Sprintf("fixtures: seed %d: %v", seed, err))
+	}
+
+	// Wrap the artwork into a card
+	rawCard, err := png.FromImage(io.NopCloser(artwork)).Get()
+	if err != nil {
+		panic(fmt.Sprintf("fixtures: seed %d: %v", seed, err))
+	}
+	rawJsonCard, err := rawCard.ToRawJson()
+	if err != nil {
+		panic(fmt.Sprintf("fixtures: seed %d: %v", seed, err))
+	}
+	rawJsonCard.RawJsonData = JSON(seed, opts)
+	rawJsonCard.Revision = g.opts.Revision
+
+	// Encode the card
+	data, err := rawJsonCard.ToRaw().ToBytes()
+	if err != nil {
+		panic(fmt.Sprintf("fixtures: seed %d: %v", seed, err))
+	}
+	return data
+}
+
+// sheet generates the sheet
+func (g *generator) sheet() *character.Sheet {
+	sheet := character.DefaultSheet(g.opts.Revision)
+	name := g.pick(namePool)
+
+	// Text fields
+	sheet.Title = property.String(name + " - " + g.sentence(4))
+	sheet.Name = property.String(name)
+	sheet.Description = property.String(g.paragraph())
+	sheet.Personality = property.String(g.paragraph())
+	sheet.Scenario = property.String(g.paragraph())
+	sheet.FirstMessage = property.String(g.paragraph())
+	sheet.MessageExamples = property.String(g.examples())
+	sheet.CreatorNotes = property.String(g.paragraph())
+	sheet.SystemPrompt = property.String(g.sentence(12))
+	sheet.PostHistoryInstructions = property.String(g.sentence(10))
+	sheet.Creator = property.String(g.pick(creatorPool))
+	sheet.CharacterVersion = property.String(fmt.Sprintf("%d.%d", g.rnd.IntN(3)+1, g.rnd.IntN(10)))
+	sheet.Nickname = property.String(strings.ToLower(name[:3]))
+
+	// Greetings and tags
+	for range g.opts.Greetings {
+		sheet.AlternateGreetings = append(sheet.AlternateGreetings, g.paragraph())
+	}
+	sheet.GroupGreetings = property.StringArray{g.sentence(8)}
+	sheet.Tags = g.tags()
+
+	// Depth prompt and extensions
+	sheet.DepthPrompt = character.DepthPrompt{Prompt: g.sentence(10), Depth: g.rnd.IntN(10) + 1}
+	sheet.Extensions = map[string]any{
+		"talkativeness": strconv.FormatFloat(float64(g.rnd.IntN(10))/10, 'f', 1, 64),
+		"fav":           g.rnd.IntN(2) == 0,
+		"world":         g.sentence(2),
+		"generator":     map[string]any{"seed": float64(g.rnd.IntN(1000)), "labels": []any{g.word(), g.word()}},
+	}
+
+	// V3 fields
+	sheet.Assets = []character.Asset{{
+		Type:      property.AssetType(g.pick(assetTypes)),
+		URI:       "ccdefault:",
+		Name:      "main",
+		Extension: "png",
+	}}
+	sheet.CreatorNotesMultilingual = map[string]property.String{g.pick(languagePool): property.String(g.sentence(6))}
+	sheet.Source = property.StringArray{"https://example.com/cards/" + strconv.Itoa(g.rnd.IntN(100000))}
+	sheet.CreationDate = timestamp.Seconds(baseTimestamp + g.rnd.IntN(1_000_000))
+	sheet.ModificationDate = sheet.CreationDate + timestamp.Seconds(g.rnd.IntN(1_000_000))
+
+	// Platform metadata
+	sheet.SourceID = property.String("src-" + strconv.Itoa(g.rnd.IntN(100000)))
+	sheet.CharacterID = property.String("char-" + strconv.Itoa(g.rnd.IntN(100000)))
+	sheet.PlatformID = property.String("platform-" + strconv.Itoa(g.rnd.IntN(10)))
+	sheet.DirectLink = property.String("https://example.com/c/" + string(sheet.CharacterID))
+
+	// Lorebook
+	if g.opts.Entries > 0 {
+		sheet.CharacterBook = g.book(name)
+	}
+
+	// Return the sheet
+	return sheet
+}
+
+// book generates the lorebook
+func (g *generator) book(name string) *character.Book {
+	book := &character.Book{
+		Name:              property.String(name + "'s lore"),
+		Description:       property.String(g.sentence(10)),
+		ScanDepth:         property.Integer(g.rnd.IntN(20) + 1),
+		TokenBudget:       property.Integer((g.rnd.IntN(8) + 1) * 256),
+		RecursiveScanning: property.Bool(g.rnd.IntN(2) == 0),
+		Extensions:        map[string]any{"source": g.word()},
+	}
+	for index := range g.opts.Entries {
+		book.Entries = append(book.Entries, g.entry(index))
+	}
+	return book
+}
+
+// entry generates a lorebook entry (typed extensions cycle through every value)
+func (g *generator) entry(index int) *character.BookEntry {
+	entry := character.DefaultBookEntry()
+	keyword := g.word()
+
+	// Alternate integer and string IDs
+	if index%3 == 2 {
+		entry.ID = property.UnionFromString("entry-" + strconv.Itoa(index))
+	} else {
+		entry.ID = property.UnionFromInt(index)
+	}
+
+	// Core fields
+	entry.Keys = property.StringArray{keyword, g.word()}
+	entry.SecondaryKeys = property.StringArray{g.word()}
+	entry.Name = property.String(capitalize(keyword))
+	entry.Comment = entry.Name
+	entry.Content = property.String(g.paragraph())
+	entry.Constant = property.Bool(g.rnd.IntN(4) == 0)
+	entry.Selective = property.Bool(g.rnd.IntN(2) == 0)
+	entry.InsertionOrder = property.Integer(g.rnd.IntN(200))
+	entry.Enabled = property.Bool(g.rnd.IntN(5) != 0)
+	entry.UseRegex = property.Bool(g.rnd.IntN(2) == 0)
+
+	// Messy entries only carry the comment (the name is mirrored by the tolerant helpers)
+	if g.opts.Messy && index%2 == 1 {
+		entry.Name = ""
+	}
+
+	// Typed extensions (enums cycle through every value)
+	entry.Extensions = character.BookEntryExtensions{
+		LorePosition:    property.LorePosition(index % (int(property.LorePositionEnd) + 1)),
+		Probability:     property.Float(float64(g.rnd.IntN(200)) / 2),
+		Depth:           property.Integer(g.rnd.IntN(10)),
+		SelectiveLogic:  property.SelectiveLogic(index % (int(property.SelectiveLogicEnd) + 1)),
+		MatchWholeWords: property.Bool(g.rnd.IntN(2) == 0),
+		CaseSensitive:   property.Bool(index%2 == 0),
+		Role:            property.Role(index % (int(property.RoleEnd) + 1)),
+		Sticky:          property.Integer(g.rnd.IntN(5)),
+		Cooldown:        property.Integer(g.rnd.IntN(5)),
+		Delay:           property.Integer(g.rnd.IntN(5)),
+		Group:           property.String(g.word()),
+		GroupWeight:     property.Float(g.rnd.IntN(100)),
+		UseProbability:  true,
+	}
+	entry.RawExtensions = map[string]any{"weight": float64(g.rnd.IntN(100))}
+
+	// Return the entry
+	return entry
+}
+
+// examples generates mes_example dialogues
+func (g *generator) examples() string {
+	builder := strings.Builder{}
+	for dialogue := range g.rnd.IntN(3) + 1 {
+		if dialogue > 0 {
+			builder.WriteString("\n")
+		}
+		builder.WriteString(character.ExampleSeparator)
+		for turn := range g.rnd.IntN(3) + 2 {
+			speaker := "{{user}}: "
+			if turn%2 == 1 {
+				speaker = "{{char}}: "
+			}
+			builder.WriteString("\n" + speaker + g.sentence(8))
+		}
+	}
+	return builder.String()
+}
+
+// tags generates tags from the fixed pool
+func (g *generator) tags() property.StringArray {
+	tags := property.StringArray{}
+	for _, index := range g.rnd.Perm(len(tagPool))[:g.rnd.IntN(3)+1] {
+		tag := tagPool[index]
+		if g.opts.Messy {
+			tag = "  " + strings.ToLower(tag) + " "
+		}
+		tags = append(tags, tag)
+	}
+	return tags
+}
+
+// paragraph generates a paragraph of about opts.Words words
+func (g *generator) paragraph() string {
+	sentences := []string{}
+	for words := 0; words < g.opts.Words; {
+		length := g.rnd.IntN(8) + 5
+		sentences = append(sentences, g.sentence(length))
+		words += length
+	}
+	return strings.Join(sentences, " ")
+}
+
+// sentence generates a sentence of the given number of words (with macros, and messy symbols if enabled)
+func (g *generator) sentence(words int) string {
+	parts := make([]string, 0, words)
+	for range words {
+		switch roll := g.rnd.IntN(12); {
+		case roll == 0 && g.opts.Messy:
+			parts = append(parts, g.pick(messyMacroPool))
+		case roll == 0:
+			parts = append(parts, g.pick(macroPool))
+		case roll == 1 && g.opts.Messy:
+			quotes := fancyQuotes[g.rnd.IntN(len(fancyQuotes))]
+			parts = append(parts, quotes[0]+g.word()+quotes[1])
+		default:
+			parts = append(parts, g.word())
+		}
+	}
+	return capitalize(strings.Join(parts, " ")) + "."
+}
+
+// capitalize returns the text with its first letter in upper case
+func capitalize(text string) string {
+	first, size := utf8.DecodeRuneInString(text)
+	return string(unicode.ToUpper(first)) + text[size:]
+}
+
+// word returns a random lorem word
+func (g *generator) word() string {
+	return g.pick(loremPool)
+}
+
+// pick returns a random element of the pool
+func (g *generator) pick(pool []string) string {
+	return pool[g.rnd.IntN(len(pool))]
+}
+
+// artwork generates a deterministic gradient artwork with a few colored blocks
+func (g *generator) artwork() image.Image {
+	width, height := g.opts.Width, g.opts.Height
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	base := color.RGBA{R: uint8(g.rnd.IntN(256)), G: uint8(g.rnd.IntN(256)), B: uint8(g.rnd.IntN(256)), A: 255}
+
+	// Paint the gradient
+	for y := range height {
+		for x := range width {
+			img.Set(x, y, color.RGBA{
+				R: base.R + uint8(x*255/max(width, 1)),
+				G: base.G + uint8(y*255/max(height, 1)),
+				B: base.B,
+				A: 255,
+			})
+		}
+	}
+
+	// Paint the blocks
+	for range 3 {
+		block := color.RGBA{R: uint8(g.rnd.IntN(256)), G: uint8(g.rnd.IntN(256)), B: uint8(g.rnd.IntN(256)), A: 255}
+		x0, y0 := g.rnd.IntN(width), g.rnd.IntN(height)
+		for y := y0; y < min(y0+height/4+1, height); y++ {
+			for x := x0; x < min(x0+width/4+1, width); x++ {
+				img.Set(x, y, block)
+			}
+		}
+	}
+
+	// Return the artwork
+	return img
+}
+
+// messy enum names (index = enum value)
+var (
+	positionNames = []string{"before_char", "after_char", "before_an", "after_an", "at_depth", "before_em", "after_em"}
+	logicNames    = []string{"and_any", "not_all", "not_any", "and_all"}
+	roleNames     = []string{"system", "user", "assistant"}
+)
+
+// messyJSON rewrites the sheet JSON with loosely typed values that the tolerant parsers map back to the same sheet
+func messyJSON(seed int64, data []byte) []byte {
+	var root map[string]any
+	if err := codec.Unmarshal(data, &root); err != nil {
+		panic(fmt.Sprintf("fixtures: seed %d: %v", seed, err))
+	}
+
+	// Loosely typed lorebook
+	content, _ := root["data"].(map[string]any)
+	if book, ok := content[character.CharacterBookField].(map[string]any); ok {
+		book["scan_depth"] = looseValue(book["scan_depth"])
+		book["recursive_scanning"] = looseValue(book["recursive_scanning"])
+
+		entries, _ := book["entries"].([]any)
+		for _, item := range entries {
+			entry, _ := item.(map[string]any)
+			extensions, _ := entry["extensions"].(map[string]any)
+
+			// Numbers and booleans as strings
+			for _, key := range []string{"id", "insertion_order", "enabled", "constant"} {
+				entry[key] = looseValue(entry[key])
+			}
+			for _, key := range []string{character.EntryProbability, character.EntryDepth, character.EntrySticky} {
+				extensions[key] = looseValue(extensions[key])
+			}
+
+			// Enums as names
+			extensions[character.EntryPosition] = enumName(positionNames, extensions[character.EntryPosition])
+			extensions[character.EntrySelectiveLogic] = enumName(logicNames, extensions[character.EntrySelectiveLogic])
+			extensions[character.EntryRole] = enumName(roleNames, extensions[character.EntryRole])
+
+			// Straggler extension (outside the extension map)
+			entry[character.EntryCaseSensitive] = extensions[character.EntryCaseSensitive]
+			delete(extensions, character.EntryCaseSensitive)
+		}
+	}
+
+	// Encode the messy JSON
+	messy, err := codec.Marshal(root)
+	if err != nil {
+		panic(fmt.Sprintf("fixtures: seed %d: %v", seed, err))
+	}
+	return messy
+}
+
+// looseValue returns the string representation of numbers and booleans
+func looseValue(value any) any {
+	switch typedValue := value.(type) {
+	case float64:
+		return strconv.FormatFloat(typedValue, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(typedValue)
+	default:
+		return value
+	}
+}
+
+// enumName returns the name of a numeric enum value (the value itself if out of range)
+func enumName(names []string, value any) any {
+	if number, ok := value.(float64); ok && number >= 0 && int(number) < len(names) {
+		return strings.ToUpper(names[int(number)])
+	}
+	return value
+}
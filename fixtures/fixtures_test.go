@@ -0,0 +1,222 @@
+package fixtures
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"testing"
+
+	"github.com/r3dpixel/card-parser/character"
+	"github.com/r3dpixel/card-parser/internal/codec"
+	"github.com/r3dpixel/card-parser/png"
+	"github.com/r3dpixel/card-parser/property"
+	"github.com/r3dpixel/toolkit/jsonx"
+	"github.com/r3dpixel/toolkit/sonicx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// roundTripSeeds are the seeds used by the round trip suites
+var roundTripSeeds = []int64{0, 1, 2, 42, 1337, 20240101}
+
+// roundTripOptions are the option sets used by the round trip suites
+var roundTripOptions = map[string]GenOptions{
+	"V2":    {Revision: character.RevisionV2, Greetings: 2, Entries: 3},
+	"V3":    {Revision: character.RevisionV3, Greetings: 3, Entries: 12},
+	"Messy": {Revision: character.RevisionV3, Greetings: 1, Entries: 8, Messy: true},
+	"Empty": {Revision: character.RevisionV3},
+}
+
+func TestSheet_Deterministic(t *testing.T) {
+	opts := GenOptions{Greetings: 2, Entries: 5, Messy: true}
+
+	assert.True(t, Sheet(7, opts).DeepEquals(Sheet(7, opts)), "Same seed must generate the same sheet")
+	assert.Equal(t, JSON(7, opts), JSON(7, opts), "Same seed must generate the same JSON")
+	assert.Equal(t, PNG(7, opts), PNG(7, opts), "Same seed must generate the same PNG")
+	assert.False(t, Sheet(7, opts).DeepEquals(Sheet(8, opts)), "Different seeds must generate different sheets")
+}
+
+func TestSheet_Options(t *testing.T) {
+	sheet := Sheet(1, GenOptions{Revision: character.RevisionV2, Greetings: 4, Entries: 7, Words: 100})
+
+	assert.Equal(t, character.RevisionV2, sheet.Revision)
+	assert.Equal(t, character.SpecV2, sheet.Spec)
+	assert.Len(t, sheet.AlternateGreetings, 4)
+	require.NotNil(t, sheet.CharacterBook)
+	assert.Len(t, sheet.CharacterBook.Entries, 7)
+	assert.GreaterOrEqual(t, len(bytes.Fields([]byte(sheet.Description))), 100)
+	assert.True(t, sheet.Integrity())
+	assert.Contains(t, string(sheet.MessageExamples), character.ExampleSeparator)
+
+	// Defaults
+	sheet = Sheet(1, GenOptions{})
+	assert.Equal(t, character.RevisionV3, sheet.Revision)
+	assert.Nil(t, sheet.CharacterBook)
+	assert.Empty(t, sheet.AlternateGreetings)
+}
+
+func TestSheet_Coverage(t *testing.T) {
+	sheet := Sheet(3, GenOptions{Greetings: 1, Entries: 12})
+
+	// Every typed enum value is covered by the entries
+	positions := map[property.LorePosition]bool{}
+	logics := map[property.SelectiveLogic]bool{}
+	roles := map[property.Role]bool{}
+	ids := map[string]bool{}
+	for _, entry := range sheet.CharacterBook.Entries {
+		positions[entry.Extensions.LorePosition] = true
+		logics[entry.Extensions.SelectiveLogic] = true
+		roles[entry.Extensions.Role] = true
+		ids[fmt.Sprintf("int=%t string=%t", entry.ID.IntValue != nil, entry.ID.StringValue != nil)] = true
+	}
+	assert.Len(t, positions, int(property.LorePositionEnd)+1)
+	assert.Len(t, logics, int(property.SelectiveLogicEnd)+1)
+	assert.Len(t, roles, int(property.RoleEnd)+1)
+	assert.Len(t, ids, 2)
+
+	// Every content field is populated
+	data, err := sheet.ToBytes()
+	require.NoError(t, err)
+	wrapper := struct {
+		Data map[string]any `json:"data"`
+	}{}
+	require.NoError(t, sonicx.Config.Unmarshal(data, &wrapper))
+	for _, field := range jsonx.ExtractJsonFieldNames(character.Content{}) {
+		assert.NotEmpty(t, wrapper.Data[field], "Field %s must be populated", field)
+	}
+}
+
+func TestSheet_Messy(t *testing.T) {
+	messy := Sheet(5, GenOptions{Greetings: 3, Entries: 6, Words: 200, Messy: true})
+	data, err := messy.ToBytes()
+	require.NoError(t, err)
+
+	// Messy sheets need the tolerant helpers
+	fixed := messy.Clone()
+	fixed.NormalizeSymbols()
+	fixed.FixUserCharTemplates()
+	assert.NotEqual(t, messy.Description, fixed.Description)
+	brokenMacro := `\{\{\{|(^|[^{])\{(char|user)\}|\{(char|user)\}([^}]|$)`
+	assert.Regexp(t, brokenMacro, string(messy.Description))
+	assert.NotRegexp(t, brokenMacro, string(fixed.Description))
+	assert.Contains(t, string(data), `"  `)
+
+	// Messy JSON is loosely typed
+	messyJSON := string(JSON(5, GenOptions{Entries: 6, Messy: true}))
+	assert.Contains(t, messyJSON, `"position":"AFTER_CHAR"`)
+	assert.Contains(t, messyJSON, `"role":"ASSISTANT"`)
+	assert.Contains(t, messyJSON, `"enabled":"`)
+}
+
+func TestRoundTrip(t *testing.T) {
+	for name, opts := range roundTripOptions {
+		for _, seed := range roundTripSeeds {
+			t.Run(fmt.Sprintf("%s/seed=%d", name, seed), func(t *testing.T) {
+				expected := Sheet(seed, opts)
+
+				// JSON round trip (messy JSON decodes to the same sheet)
+				decoded, err := character.FromBytes(JSON(seed, opts))
+				require.NoError(t, err)
+				assert.True(t, expected.DeepEquals(decoded), "seed %d: JSON round trip mismatch", seed)
+
+				// Re-encoding is stable
+				data, err := decoded.ToBytes()
+				require.NoError(t, err)
+				again, err := character.FromBytes(data)
+				require.NoError(t, err)
+				assert.True(t, decoded.DeepEquals(again), "seed %d: re-encoding mismatch", seed)
+			})
+		}
+	}
+}
+
+func TestRoundTrip_Codecs(t *testing.T) {
+	defer character.SetCodec(character.SetCodec(character.StdCodec))
+
+	for name, opts := range roundTripOptions {
+		for _, seed := range roundTripSeeds {
+			t.Run(fmt.Sprintf("%s/seed=%d", name, seed), func(t *testing.T) {
+				// Decode and canonically re-encode the JSON with each codec
+				var sheets []*character.Sheet
+				var outputs []string
+				for _, c := range codec.Available {
+					character.SetCodec(c)
+					decoded, err := character.FromBytes(JSON(seed, opts))
+					require.NoError(t, err)
+					data, err := decoded.CanonicalBytes()
+					require.NoError(t, err)
+					sheets = append(sheets, decoded)
+					outputs = append(outputs, string(data))
+				}
+
+				// Every codec produces the same sheet and the same JSON as the first one
+				for index := 1; index < len(sheets); index++ {
+					assert.True(t, sheets[0].DeepEquals(sheets[index]), "seed %d: codec decode mismatch", seed)
+					assert.Equal(t, outputs[0], outputs[index], "seed %d: codec encode mismatch", seed)
+				}
+			})
+		}
+	}
+}
+
+func TestPNG_RoundTrip(t *testing.T) {
+	for name, opts := range roundTripOptions {
+		for _, seed := range roundTripSeeds {
+			t.Run(fmt.Sprintf("%s/seed=%d", name, seed), func(t *testing.T) {
+				opts.Width, opts.Height = 40+int(seed%7), 30+int(seed%5)
+				data := PNG(seed, opts)
+
+				// The artwork is a real image of the configured dimensions
+				config, format, err := image.DecodeConfig(bytes.NewReader(data))
+				require.NoError(t, err)
+				assert.Equal(t, "png", format)
+				assert.Equal(t, opts.Width, config.Width)
+				assert.Equal(t, opts.Height, config.Height)
+
+				// The embedded card decodes to the generated sheet
+				rawCard, err := png.FromBytes(data).Get()
+				require.NoError(t, err)
+				card, err := rawCard.Decode()
+				require.NoError(t, err)
+				assert.True(t, Sheet(seed, opts).DeepEquals(card.Sheet), "seed %d: PNG round trip mismatch", seed)
+			})
+		}
+	}
+}
+
+func BenchmarkSheet_ToBytes(b *testing.B) {
+	sheet := Sheet(1, GenOptions{Greetings: 5, Entries: 50, Words: 300})
+	for b.Loop() {
+		if _, err := sheet.ToBytes(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkSheet_FromBytes(b *testing.B) {
+	for name, opts := range roundTripOptions {
+		data := JSON(1, opts)
+		b.Run(name, func(b *testing.B) {
+			b.SetBytes(int64(len(data)))
+			for b.Loop() {
+				if _, err := character.FromBytes(data); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkPNG_Decode(b *testing.B) {
+	data := PNG(1, GenOptions{Greetings: 5, Entries: 50, Width: 512, Height: 768})
+	b.SetBytes(int64(len(data)))
+	for b.Loop() {
+		rawCard, err := png.FromBytes(data).Get()
+		if err != nil {
+			b.Fatal(err)
+		}
+		if _, err := rawCard.Decode(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -3,12 +3,15 @@ module github.com/r3dpixel/card-parser
 go 1.25.4
 
 require (
+	github.com/bytedance/sonic v1.14.2
+	github.com/gen2brain/avif v0.4.4
 	github.com/gen2brain/jpegli v0.3.4
 	github.com/google/go-cmp v0.7.0
 	github.com/r3dpixel/toolkit v1.1.4
 	github.com/spf13/cast v1.10.0
 	github.com/stretchr/testify v1.11.1
 	github.com/sunshineplan/imgconv v1.1.14
+	golang.org/x/text v0.32.0
 )
 
 require (
@@ -16,12 +19,12 @@ require (
 	github.com/HugoSmits86/nativewebp v1.2.1 // indirect
 	github.com/andybalholm/brotli v1.2.0 // indirect
 	github.com/bytedance/gopkg v0.1.3 // indirect
-	github.com/bytedance/sonic v1.14.2 // indirect
 	github.com/bytedance/sonic/loader v0.4.0 // indirect
 	github.com/clipperhouse/stringish v0.1.1 // indirect
 	github.com/clipperhouse/uax29/v2 v2.3.0 // indirect
 	github.com/cloudwego/base64x v0.1.6 // indirect
 	github.com/danieljoos/wincred v1.2.3 // indirect
+	github.com/ebitengine/purego v0.8.3 // indirect
 	github.com/davecgh/go-spew v1.1.1 // indirect
 	github.com/elliotchance/orderedmap/v3 v3.1.0 // indirect
 	github.com/godbus/dbus/v5 v5.2.0 // indirect
@@ -53,7 +56,6 @@ require (
 	golang.org/x/image v0.34.0 // indirect
 	golang.org/x/net v0.48.0 // indirect
 	golang.org/x/sys v0.39.0 // indirect
-	golang.org/x/text v0.32.0 // indirect
 	gopkg.in/yaml.v2 v2.4.0 // indirect
 	gopkg.in/yaml.v3 v3.0.1 // indirect
 )
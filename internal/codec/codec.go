@@ -0,0 +1,112 @@
+// Package codec provides the JSON codec used by the custom (un)marshalers of the sheet and property types
+package codec
+
+import (
+	"io"
+
+	"github.com/r3dpixel/toolkit/jsonx"
+)
+
+// Codec JSON codec used by the custom (un)marshalers (Sonic by default, encoding/json with the stdjson build tag)
+type Codec interface {
+	Marshal(v any) ([]byte, error)
+	MarshalSorted(v any, indent string) ([]byte, error)
+	Encode(w io.Writer, v any, opts EncodeOptions) error
+	Unmarshal(data []byte, v any) error
+	UnmarshalFromString(data string, v any) error
+	NewDecoder(r io.Reader) Decoder
+	GetFromString(data string) (Node, error)
+}
+
+// EncodeOptions options of the streaming encoding (see Codec.Encode)
+// They apply to the whole output, including the JSON returned by the nested marshalers
+type EncodeOptions struct {
+	Indent     string // Indent of the nested structures (compact if empty)
+	EscapeHTML bool   // Escape the HTML characters (<, > and &) of the strings
+}
+
+// Decoder streaming JSON decoder
+type Decoder interface {
+	UseNumber()
+	Decode(v any) error
+}
+
+// Node lazily parsed JSON value (the members of objects are walked without decoding their values)
+type Node interface {
+	IsObject() bool
+	ForEachMember(fn func(key string, raw string, child Node) error) error
+}
+
+// active codec used by the package functions
+var active = defaultCodec
+
+// Set sets the codec used by the package functions (not safe to call while JSON is encoded or decoded)
+func Set(codec Codec) {
+	active = codec
+}
+
+// Get returns the codec used by the package functions
+func Get() Codec {
+	return active
+}
+
+// Marshal encodes the value with the active codec
+func Marshal(v any) ([]byte, error) {
+	return active.Marshal(v)
+}
+
+// MarshalSorted encodes the value with the active codec, with sorted map keys (indented if the indent is not empty)
+func MarshalSorted(v any, indent string) ([]byte, error) {
+	return active.MarshalSorted(v, indent)
+}
+
+// Encode writes the JSON encoding of the value, followed by a newline, to the writer with the active codec
+func Encode(w io.Writer, v any, opts EncodeOptions) error {
+	return active.Encode(w, v, opts)
+}
+
+// Unmarshal decodes the JSON data into the value with the active codec
+func Unmarshal(data []byte, v any) error {
+	return active.Unmarshal(data, v)
+}
+
+// UnmarshalFromString decodes the JSON string into the value with the active codec
+func UnmarshalFromString(data string, v any) error {
+	return active.UnmarshalFromString(data, v)
+}
+
+// NewDecoder returns a streaming decoder of the active codec
+func NewDecoder(r io.Reader) Decoder {
+	return active.NewDecoder(r)
+}
+
+// GetFromString lazily parses the JSON string with the active codec
+func GetFromString(data string) (Node, error) {
+	return active.GetFromString(data)
+}
+
+// String returns the JSON encoding of the value as a string (empty if it cannot be encoded)
+func String(v any) string {
+	data, _ := active.Marshal(v)
+	return string(data)
+}
+
+// HandlePrimitive decodes the JSON data with the active codec, and passes the value to the primitive handler
+func HandlePrimitive(data []byte, h jsonx.PrimitiveHandler) error {
+	var value any
+	if err := active.Unmarshal(data, &value); err != nil {
+		return err
+	}
+	jsonx.HandlePrimitiveValue(value, h)
+	return nil
+}
+
+// HandleEntity decodes the JSON data with the active codec, and passes the value to the entity handler
+func HandleEntity(data []byte, h jsonx.EntityHandler) error {
+	var value any
+	if err := active.Unmarshal(data, &value); err != nil {
+		return err
+	}
+	jsonx.HandleEntityValue(value, h)
+	return nil
+}
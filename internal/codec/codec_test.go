@@ -0,0 +1,187 @@
+package codec
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var codecs = map[string]Codec{"std": Std}
+
+func TestCodec_ForEachMember(t *testing.T) {
+	tests := []struct {
+		name     string
+		data     string
+		keys     []string
+		raws     []string
+		isObject bool
+		wantErr  bool
+	}{
+		{
+			name:     "members in document order",
+			data:     `{"b": 1, "a": "x", "c": {"d": [1, 2]}}`,
+			keys:     []string{"b", "a", "c"},
+			raws:     []string{`1`, `"x"`, `{"d": [1, 2]}`},
+			isObject: true,
+		},
+		{
+			name:     "empty object",
+			data:     `{}`,
+			isObject: true,
+		},
+		{
+			name:    "array",
+			data:    `[1, 2]`,
+			wantErr: true,
+		},
+		{
+			name:    "string",
+			data:    `"text"`,
+			wantErr: true,
+		},
+	}
+
+	for name, c := range codecs {
+		for _, tt := range tests {
+			t.Run(name+"/"+tt.name, func(t *testing.T) {
+				node, err := c.GetFromString(tt.data)
+				require.NoError(t, err)
+				assert.Equal(t, tt.isObject, node.IsObject())
+				if !tt.isObject {
+					return
+				}
+
+				var keys, raws []string
+				err = node.ForEachMember(func(key, raw string, child Node) error {
+					keys = append(keys, key)
+					raws = append(raws, strings.TrimSpace(raw))
+					return nil
+				})
+				assert.Equal(t, tt.wantErr, err != nil)
+				assert.Equal(t, tt.keys, keys)
+				assert.Equal(t, tt.raws, raws)
+			})
+		}
+	}
+}
+
+func TestCodec_NestedObject(t *testing.T) {
+	for name, c := range codecs {
+		t.Run(name, func(t *testing.T) {
+			node, err := c.GetFromString(`{"data": {"name": "Alice", "tags": []}}`)
+			require.NoError(t, err)
+
+			var keys []string
+			err = node.ForEachMember(func(_, _ string, child Node) error {
+				require.True(t, child.IsObject())
+				return child.ForEachMember(func(key, _ string, _ Node) error {
+					keys = append(keys, key)
+					return nil
+				})
+			})
+			require.NoError(t, err)
+			assert.Equal(t, []string{"name", "tags"}, keys)
+		})
+	}
+}
+
+func TestCodec_InvalidJSON(t *testing.T) {
+	for name, c := range codecs {
+		t.Run(name, func(t *testing.T) {
+			_, err := c.GetFromString(`{"name": `)
+			assert.Error(t, err)
+			var v map[string]any
+			assert.Error(t, c.UnmarshalFromString(`{"name": `, &v))
+		})
+	}
+}
+
+func TestCodec_Marshal(t *testing.T) {
+	value := map[string]any{"b": "<b>&</b>", "a": []int{1, 2}}
+
+	for name, c := range codecs {
+		t.Run(name, func(t *testing.T) {
+			data, err := c.MarshalSorted(value, "")
+			require.NoError(t, err)
+			assert.Equal(t, `{"a":[1,2],"b":"<b>&</b>"}`, string(data))
+
+			data, err = c.MarshalSorted(value, "  ")
+			require.NoError(t, err)
+			assert.Equal(t, "{\n  \"a\": [\n    1,\n    2\n  ],\n  \"b\": \"<b>&</b>\"\n}", string(data))
+
+			data, err = c.Marshal("<tag>")
+			require.NoError(t, err)
+			assert.Equal(t, `"<tag>"`, string(data))
+		})
+	}
+}
+
+// rawMarshaler value encoded by its own marshaler (compact JSON)
+type rawMarshaler struct{}
+
+// MarshalJSON returns a compact object with HTML characters
+func (rawMarshaler) MarshalJSON() ([]byte, error) {
+	return []byte(`{"html":"<b>","list":[1]}`), nil
+}
+
+func TestCodec_Encode(t *testing.T) {
+	for name, c := range codecs {
+		t.Run(name, func(t *testing.T) {
+			// The options apply to the output of the nested marshalers too
+			var buffer strings.Builder
+			require.NoError(t, c.Encode(&buffer, map[string]any{"nested": rawMarshaler{}}, EncodeOptions{Indent: "  ", EscapeHTML: true}))
+			assert.Equal(t, "{\n  \"nested\": {\n    \"html\": \"\\u003cb\\u003e\",\n    \"list\": [\n      1\n    ]\n  }\n}\n", buffer.String())
+
+			buffer.Reset()
+			require.NoError(t, c.Encode(&buffer, rawMarshaler{}, EncodeOptions{}))
+			assert.Equal(t, `{"html":"<b>","list":[1]}`+"\n", buffer.String())
+		})
+	}
+}
+
+func TestCodec_Decoder(t *testing.T) {
+	for name, c := range codecs {
+		t.Run(name, func(t *testing.T) {
+			decoder := c.NewDecoder(strings.NewReader(`{"n": 12345678901234567890} {"n": 1}`))
+			decoder.UseNumber()
+
+			var first, second map[string]any
+			require.NoError(t, decoder.Decode(&first))
+			require.NoError(t, decoder.Decode(&second))
+			assert.Equal(t, "12345678901234567890", first["n"].(interface{ String() string }).String())
+			assert.Equal(t, "1", second["n"].(interface{ String() string }).String())
+		})
+	}
+}
+
+func TestSet(t *testing.T) {
+	previous := Get()
+	defer Set(previous)
+
+	Set(Std)
+	assert.Equal(t, Std, Get())
+	assert.Equal(t, `{"a":1}`, String(map[string]int{"a": 1}))
+}
+
+func TestCodec_NilCollections(t *testing.T) {
+	var slice []string
+	var m map[string]int
+
+	for name, c := range codecs {
+		t.Run(name, func(t *testing.T) {
+			data, err := c.Marshal(slice)
+			require.NoError(t, err)
+			assert.Equal(t, `[]`, string(data))
+
+			data, err = c.Marshal(&slice)
+			require.NoError(t, err)
+			assert.Equal(t, `[]`, string(data))
+
+			data, err = c.Marshal(m)
+			require.NoError(t, err)
+			assert.Equal(t, `{}`, string(data))
+		})
+	}
+}
@@ -0,0 +1,9 @@
+//go:build !stdjson
+
+package codec
+
+// defaultCodec is the codec used until Set is called (Sonic, build with the stdjson tag to use encoding/json)
+var defaultCodec = Sonic
+
+// Available codecs compiled in the build (Sonic first)
+var Available = []Codec{Sonic, Std}
@@ -0,0 +1,9 @@
+//go:build stdjson
+
+package codec
+
+// defaultCodec is the codec used until Set is called (encoding/json with the stdjson build tag)
+var defaultCodec = Std
+
+// Available codecs compiled in the build (Sonic is not compiled with the stdjson build tag)
+var Available = []Codec{Std}
@@ -0,0 +1,88 @@
+//go:build !stdjson
+
+package codec
+
+import (
+	"io"
+
+	"github.com/bytedance/sonic"
+	"github.com/bytedance/sonic/ast"
+	"github.com/r3dpixel/toolkit/sonicx"
+)
+
+// Sonic codec backed by Sonic (sonicx configurations)
+var Sonic Codec = sonicCodec{}
+
+// sonicCodec Codec backed by Sonic
+type sonicCodec struct{}
+
+// Marshal encodes the value with the sonicx configuration
+func (sonicCodec) Marshal(v any) ([]byte, error) {
+	return sonicx.Config.Marshal(v)
+}
+
+// MarshalSorted encodes the value with the sorted sonicx configuration
+func (sonicCodec) MarshalSorted(v any, indent string) ([]byte, error) {
+	if indent != "" {
+		return sonicx.StableSort.MarshalIndent(v, "", indent)
+	}
+	return sonicx.StableSort.Marshal(v)
+}
+
+// Encode writes the value, followed by a newline, with an encoder of the sonicx configuration
+func (sonicCodec) Encode(w io.Writer, v any, opts EncodeOptions) error {
+	encoder := sonicx.Config.NewEncoder(w)
+	encoder.SetEscapeHTML(opts.EscapeHTML)
+	encoder.SetIndent("", opts.Indent)
+	return encoder.Encode(v)
+}
+
+// Unmarshal decodes the JSON data with the sonicx configuration
+func (sonicCodec) Unmarshal(data []byte, v any) error {
+	return sonicx.Config.Unmarshal(data, v)
+}
+
+// UnmarshalFromString decodes the JSON string with the sonicx configuration
+func (sonicCodec) UnmarshalFromString(data string, v any) error {
+	return sonicx.Config.UnmarshalFromString(data, v)
+}
+
+// NewDecoder returns a streaming decoder of the sonicx configuration
+func (sonicCodec) NewDecoder(r io.Reader) Decoder {
+	return sonicx.Config.NewDecoder(r)
+}
+
+// GetFromString lazily parses the JSON string into a Sonic AST node
+func (sonicCodec) GetFromString(data string) (Node, error) {
+	root, err := sonic.GetFromString(data)
+	if err != nil {
+		return nil, err
+	}
+	return &sonicNode{node: &root}, nil
+}
+
+// sonicNode Node backed by a Sonic AST node
+type sonicNode struct {
+	node *ast.Node
+}
+
+// IsObject checks if the node is a JSON object
+func (n *sonicNode) IsObject() bool {
+	return n.node.TypeSafe() == ast.V_OBJECT
+}
+
+// ForEachMember calls the function for each member of the object (in document order) with its raw value,
+// and stops at the first error
+func (n *sonicNode) ForEachMember(fn func(key string, raw string, child Node) error) error {
+	var err error
+	if scanErr := n.node.ForEach(func(path ast.Sequence, child *ast.Node) bool {
+		var raw string
+		if raw, err = child.Raw(); err == nil {
+			err = fn(*path.Key, raw, &sonicNode{node: child})
+		}
+		return err == nil
+	}); scanErr != nil {
+		return scanErr
+	}
+	return err
+}
@@ -0,0 +1,8 @@
+//go:build !stdjson
+
+package codec
+
+func init() {
+	// The Sonic codec is tested with the default build
+	codecs["sonic"] = Sonic
+}
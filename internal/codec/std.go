@@ -0,0 +1,133 @@
+package codec
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+)
+
+// Std codec backed by encoding/json (plain Go, for the platforms where Sonic is unsupported)
+// Like the Sonic codec, HTML characters are not escaped
+var Std Codec = stdCodec{}
+
+// marshalerType type of the json.Marshaler interface
+var marshalerType = reflect.TypeFor[json.Marshaler]()
+
+// errNotObject is returned when the members of a JSON value that is not an object are walked
+var errNotObject = errors.New("json value is not an object")
+
+// stdCodec Codec backed by encoding/json
+type stdCodec struct{}
+
+// Marshal encodes the value (map keys are sorted by encoding/json)
+func (c stdCodec) Marshal(v any) ([]byte, error) {
+	return c.MarshalSorted(v, "")
+}
+
+// MarshalSorted encodes the value with sorted map keys (indented if the indent is not empty)
+func (c stdCodec) MarshalSorted(v any, indent string) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := c.Encode(&buf, v, EncodeOptions{Indent: indent}); err != nil {
+		return nil, err
+	}
+	// Drop the newline written by the encoder
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n")), nil
+}
+
+// Encode writes the value, followed by a newline, with an encoder (map keys are sorted by encoding/json)
+func (stdCodec) Encode(w io.Writer, v any, opts EncodeOptions) error {
+	// Like Sonic, nil slices and maps are encoded as empty (nested ones are encoded by their own marshalers)
+	if empty, ok := emptyCollection(v); ok {
+		_, err := io.WriteString(w, empty+"\n")
+		return err
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetEscapeHTML(opts.EscapeHTML)
+	encoder.SetIndent("", opts.Indent)
+	return encoder.Encode(v)
+}
+
+// Unmarshal decodes the JSON data
+func (stdCodec) Unmarshal(data []byte, v any) error {
+	return json.Unmarshal(data, v)
+}
+
+// UnmarshalFromString decodes the JSON string
+func (stdCodec) UnmarshalFromString(data string, v any) error {
+	return json.Unmarshal([]byte(data), v)
+}
+
+// NewDecoder returns a streaming decoder
+func (stdCodec) NewDecoder(r io.Reader) Decoder {
+	return json.NewDecoder(r)
+}
+
+// GetFromString validates the JSON string, whose members are parsed when walked
+func (stdCodec) GetFromString(data string) (Node, error) {
+	var raw json.RawMessage
+	if err := json.Unmarshal([]byte(data), &raw); err != nil {
+		return nil, err
+	}
+	return &stdNode{raw: string(raw)}, nil
+}
+
+// emptyCollection returns the empty JSON value of a nil slice or map (or of a pointer to one),
+// unless the value has its own marshaler
+func emptyCollection(v any) (string, bool) {
+	value := reflect.ValueOf(v)
+	if value.Kind() == reflect.Pointer && !value.IsNil() {
+		value = value.Elem()
+	}
+	if value.Kind() == reflect.Invalid || reflect.PointerTo(value.Type()).Implements(marshalerType) {
+		return "", false
+	}
+	switch {
+	case value.Kind() == reflect.Slice && value.IsNil() && value.Type().Elem().Kind() != reflect.Uint8:
+		return "[]", true
+	case value.Kind() == reflect.Map && value.IsNil():
+		return "{}", true
+	}
+	return "", false
+}
+
+// stdNode Node holding the raw JSON value
+type stdNode struct {
+	raw string
+}
+
+// IsObject checks if the node is a JSON object
+func (n *stdNode) IsObject() bool {
+	return strings.HasPrefix(strings.TrimLeft(n.raw, " \t\r\n"), "{")
+}
+
+// ForEachMember calls the function for each member of the object (in document order) with its raw value,
+// and stops at the first error
+func (n *stdNode) ForEachMember(fn func(key string, raw string, child Node) error) error {
+	// Open the object
+	decoder := json.NewDecoder(strings.NewReader(n.raw))
+	if token, err := decoder.Token(); err != nil {
+		return err
+	} else if token != json.Delim('{') {
+		return errNotObject
+	}
+
+	// Walk the members
+	for decoder.More() {
+		token, err := decoder.Token()
+		if err != nil {
+			return err
+		}
+		var value json.RawMessage
+		if err := decoder.Decode(&value); err != nil {
+			return err
+		}
+		if err := fn(token.(string), string(value), &stdNode{raw: string(value)}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -2,30 +2,48 @@ package png
 
 import (
 	"bytes"
+	"context"
 	"encoding/binary"
+	"errors"
+	"fmt"
 	"io"
 	"os"
 	"slices"
+	"strings"
+	"time"
 
 	"github.com/r3dpixel/card-parser/character"
+	"github.com/r3dpixel/toolkit/bytex"
 	"github.com/r3dpixel/toolkit/reqx"
+	"github.com/r3dpixel/toolkit/stringsx"
+	"github.com/r3dpixel/toolkit/symbols"
 )
 
 // criteria defines the conditions for a chunk to be considered a valid PNG chara chunk
-type criteria func(rawCard *RawCard, chunk []byte, revision character.Revision) bool
+type criteria func(rawCard *RawCard, charaData []byte, revision character.Revision) bool
 
-// isLarger checks if the chunk is larger than the raw chara data
-func isLarger(rawCard *RawCard, chunk []byte, revision character.Revision) bool {
-	return len(chunk)-keywordsLength[revision] >= len(rawCard.RawCharaData)
+// isLarger checks if the chunk chara data is larger than the raw chara data
+func isLarger(rawCard *RawCard, charaData []byte, revision character.Revision) bool {
+	return len(charaData) >= len(rawCard.RawCharaData)
 }
 
 // isHigherVersion checks if the chunk revision is higher than the raw card revision
-func isHigherVersion(rawCard *RawCard, chunk []byte, revision character.Revision) bool {
+func isHigherVersion(rawCard *RawCard, charaData []byte, revision character.Revision) bool {
 	return revision >= rawCard.Revision
 }
 
+// isHigherVersionThenLarger checks if the chunk revision is higher than the raw card revision, or the same revision
+// with chara data at least as large (the first chunk is always selected)
+func isHigherVersionThenLarger(rawCard *RawCard, charaData []byte, revision character.Revision) bool {
+	if len(rawCard.RawCharaData) == 0 || revision > rawCard.Revision {
+		return true
+	}
+	return revision == rawCard.Revision && isLarger(rawCard, charaData, revision)
+}
+
 // ScanMode defines the scan mode for PNG card decoding
 type ScanMode struct {
+	name     string
 	deepScan bool
 	criteria criteria
 }
@@ -33,84 +51,282 @@ type ScanMode struct {
 // ScanMode values
 var (
 	First = ScanMode{
+		name:     "first",
 		deepScan: false,
 		criteria: isLarger,
 	}
 	LastVersion = ScanMode{
+		name:     "last_version",
 		deepScan: true,
 		criteria: isHigherVersion,
 	}
 	LastLongest = ScanMode{
+		name:     "last_longest",
 		deepScan: true,
 		criteria: isLarger,
 	}
+	LastVersionLongest = ScanMode{
+		name:     "last_version_longest",
+		deepScan: true,
+		criteria: isHigherVersionThenLarger,
+	}
 	DefaultScanMode = First
 )
 
+// ErrUnknownScanMode is returned when parsing a string that does not name a scan mode
+var ErrUnknownScanMode = errors.New("unknown scan mode")
+
+// scanModes maps the sanitized scan mode names to the scan modes
+var scanModes = map[string]ScanMode{
+	"first":              First,
+	"lastversion":        LastVersion,
+	"lastlongest":        LastLongest,
+	"lastversionlongest": LastVersionLongest,
+}
+
+// ParseScanMode parses a scan mode name (first, last_version, last_longest or last_version_longest),
+// ignoring case, whitespace and symbols
+// Returns the DefaultScanMode and ErrUnknownScanMode if the name is unknown
+func ParseScanMode(s string) (ScanMode, error) {
+	// Sanitize the name (remove non-ASCII, remove symbols, remove whitespace, lower all characters)
+	sanitized := strings.ToLower(stringsx.Remove(s, symbols.NonAlphaNumericWhiteSpaceRegExp))
+
+	// Check if the name corresponds to any scan mode
+	if mode, exists := scanModes[sanitized]; exists {
+		return mode, nil
+	}
+
+	// Return the DefaultScanMode value, otherwise
+	return DefaultScanMode, fmt.Errorf("%w: %q", ErrUnknownScanMode, s)
+}
+
+// String returns the name of the scan mode (e.g. last_longest)
+func (m ScanMode) String() string {
+	return m.name
+}
+
+// MarshalText marshals the scan mode as its name
+func (m ScanMode) MarshalText() ([]byte, error) {
+	return []byte(m.name), nil
+}
+
+// UnmarshalText parses the scan mode name (the DefaultScanMode is set if the name is unknown)
+func (m *ScanMode) UnmarshalText(text []byte) error {
+	mode, err := ParseScanMode(string(text))
+	*m = mode
+	return err
+}
+
+// deep returns the scan mode scanning every chunk (with the same selection criteria), so every chara chunk is
+// stripped from the image data
+func (m ScanMode) deep() ScanMode {
+	m.deepScan = true
+	return m
+}
+
+// SourceOptions options of the image sources (see FromImage, FromFile, FromBytes and FromURLWithOptions)
+type SourceOptions struct {
+	// MaxSignatureOffset is the maximum number of junk bytes skipped before the PNG signature (e.g. a UTF-8 BOM or an
+	// HTML fragment prepended by a proxy), see RawCard.SkippedPrefix; inputs without the signature in that range are
+	// converted as other images (0 means DefaultMaxSignatureOffset, a negative value disables the search)
+	MaxSignatureOffset int
+	// URLTimeout bounds the fetching (and streaming) of each URL (0 means no timeout); it is independent of the client
+	// timeout, and applies to all the attempts (retries) of a URL
+	URLTimeout time.Duration
+	// MaxDownloadBytes is the maximum size of a response fetched from a URL (0 means no limit); responses declaring a
+	// larger Content-Length are skipped early, others fail with ErrResponseTooLarge while streamed
+	MaxDownloadBytes int64
+}
+
+// DefaultMaxChunkSize is the default maximum size of a text chunk (and of the text data retained across chunks)
+// used by the processors, see Processor.MaxChunkSize
+const DefaultMaxChunkSize = 64 * bytex.MiB
+
+// AcceptHeader is the Accept header sent by FromURL (PNG is preferred, any other image is converted to PNG)
+const AcceptHeader = "image/png, image/webp, image/avif, image/jpeg;q=0.9, image/*;q=0.8"
+
 // Processor API for decoding chara PNG cards
 type Processor interface {
 	ScanMode(scanMode ScanMode) Processor
 	First() Processor
 	LastVersion() Processor
 	LastLongest() Processor
+	LastVersionLongest() Processor
+	VerifyCRC() Processor
+	Lenient() Processor
+	MaxChunkSize(size int) Processor
+	TrackOffsets() Processor
+	KeepOriginal() Processor
+	KeepTrailingData() Processor
+	OnChunk(callback func(typeCode uint32, length uint32)) Processor
+	RequireCharacterData() Processor
+	SourceFormat() string
+	Validate(constraints Constraints) error
+	Attempts() []AttemptInfo
 	Err() error
+	Stats() ScanStats
 	ImageSize() (int, int)
 	Get() (*RawCard, error)
+	GetAll() ([]*RawCard, error)
+	Pipe(w io.Writer, transform func(*RawCard) error) error
 	Close() error
 }
 
-// FromImage creates a Processor from an io.Reader containing PNG image data
-func FromImage(r io.ReadCloser) Processor {
+// FromImage creates a Processor from an io.Reader containing PNG image data (with the first of the given options)
+func FromImage(r io.ReadCloser, opts ...SourceOptions) Processor {
+	return fromImage(r, readerSize(r), sourceOptions(opts))
+}
+
+// sourceOptions returns the first of the given options (the zero options if there are none)
+func sourceOptions(opts []SourceOptions) SourceOptions {
+	if len(opts) == 0 {
+		return SourceOptions{}
+	}
+	return opts[0]
+}
+
+// fromImage creates a Processor from an io.Reader containing PNG image data of the given size (-1 if unknown)
+func fromImage(r io.ReadCloser, size int64, opts SourceOptions) Processor {
 	// Read the PNG header
 	header := make([]byte, fullIhdrSize)
 	// If the header cannot be read or is not long enough, return a converter processor
-	if _, err := io.ReadFull(r, header); err != nil {
-		return &converterProcessor{reader: io.MultiReader(bytes.NewReader(header), r), closer: r.Close}
+	if n, err := io.ReadFull(r, header); err != nil {
+		return newConverterProcessor(io.MultiReader(bytes.NewReader(header[:n]), r), r.Close, sniffFormat(header[:n]))
 	}
-	// If the header does not match the PNG header, return a converter processor
+	// If the header does not match the PNG header, look for the PNG signature after junk bytes (unknown formats only)
 	if !slices.Equal(header[0:headerSize], pngHeader) {
-		return &converterProcessor{reader: io.MultiReader(bytes.NewReader(header), r), closer: r.Close}
+		format := sniffFormat(header)
+		if maxOffset := opts.maxSignatureOffset(); format == "" && maxOffset > 0 {
+			more, _ := readUpTo(r, maxOffset)
+			data := append(header, more...)
+			if skipped := signatureOffset(data, maxOffset); skipped > 0 && len(data)-skipped >= fullIhdrSize {
+				rest := readCloser{Reader: io.MultiReader(bytes.NewReader(data[skipped+fullIhdrSize:]), r), Closer: r}
+				processor := newScanningProcessor(data[skipped:skipped+fullIhdrSize], rest)
+				processor.inputSize = size
+				processor.skippedPrefix = skipped
+				return processor
+			}
+			header = data
+		}
+		// Return a converter processor (JPEG metadata is scanned too)
+		return newConverterProcessor(io.MultiReader(bytes.NewReader(header), r), r.Close, format)
 	}
 	// Return a scanning processor
-	return newScanningProcessor(header, r)
+	processor := newScanningProcessor(header, r)
+	processor.inputSize = size
+	return processor
 }
 
-// FromFile creates a Processor from a PNG file at the given path
-func FromFile(path string) Processor {
+// FromFile creates a Processor from a PNG file at the given path (with the first of the given options)
+func FromFile(path string, opts ...SourceOptions) Processor {
 	// Open the PNG file
 	f, err := os.Open(path)
 	if err != nil {
-		return &converterProcessor{err: err}
+		return &converterProcessor{err: fmt.Errorf("%w: %w", ErrFileOpen, err)}
 	}
 	// Return a processor from the file
-	return FromImage(f)
+	return FromImage(f, opts...)
 }
 
-// FromBytes creates a Processor from a byte slice containing PNG image data
-func FromBytes(data []byte) Processor {
+// FromBytes creates a Processor from a byte slice containing PNG image data (with the first of the given options)
+func FromBytes(data []byte, opts ...SourceOptions) Processor {
 	// Return a processor from the byte slice
-	return FromImage(io.NopCloser(bytes.NewReader(data)))
+	return fromImage(io.NopCloser(bytes.NewReader(data)), int64(len(data)), sourceOptions(opts))
 }
 
 // FromURL creates a Processor by fetching a PNG image from the given URL
 func FromURL(c *reqx.Client, urls ...string) Processor {
+	return FromURLContext(context.Background(), c, urls...)
+}
+
+// FromURLContext creates a Processor by fetching a PNG image from the first URL that responds successfully
+// The context bounds every request and the streaming of the image (Get returns the context error if it is done)
+// Responses whose payload is text (e.g. HTML error pages) fail with a NotAnImageError, and responses over
+// SourceOptions.MaxDownloadBytes with ErrResponseTooLarge (both wrapped in the FetchError of the last URL); the outcome
+// of every URL is reported by Processor.Attempts
+func FromURLContext(ctx context.Context, c *reqx.Client, urls ...string) Processor {
+	return FromURLWithOptions(ctx, c, SourceOptions{}, urls...)
+}
+
+// FromURLWithOptions creates a Processor by fetching a PNG image from the first URL that responds successfully (see
+// FromURLContext), with the given options: each URL is bounded by the URLTimeout (if set), so the fallback moves on
+// from hung mirrors, responses are bounded by the MaxDownloadBytes (if set), and junk is skipped before the PNG
+// signature up to the MaxSignatureOffset
+func FromURLWithOptions(ctx context.Context, c *reqx.Client, opts SourceOptions, urls ...string) Processor {
 	// fetchErr will be the final error
 	var fetchErr error
+	var attempts []AttemptInfo
 
 	// Loop through the URLs and fetch the image
 	for _, url := range urls {
-		// Fetch the image from the URL
-		response, err := c.R().SetHeader("Accept", "image/png").Get(url)
+		// Bound the URL by the per-URL timeout
+		urlCtx, cancel := context.WithCancel(ctx)
+		if opts.URLTimeout > 0 {
+			urlCtx, cancel = context.WithTimeout(ctx, opts.URLTimeout)
+		}
+
+		// Fetch the image from the URL, and check the response
+		attempt := AttemptInfo{URL: url}
+		status := 0
+		response, err := c.R().SetContext(urlCtx).SetHeader("Accept", AcceptHeader).Get(url)
 		if err == nil {
-			// Return a processor from the image
-			return FromImage(response.Body)
+			status = response.StatusCode
+			attempt.ContentType = response.Header.Get(contentTypeHeader)
+			body := io.ReadCloser(&contextReader{ctx: urlCtx, body: response.Body, cancel: cancel})
+			if body, err = sniffResponse(url, response.Header, response.ContentLength, body, opts); err == nil {
+				// Return a processor from the image (released when the processor is closed)
+				attempts = append(attempts, attempt)
+				return withAttempts(FromImage(body, opts), attempts)
+			}
 		}
+		cancel()
+		attempt.Err = err
+		attempts = append(attempts, attempt)
+
+		// If the context is done, stop trying
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return &converterProcessor{err: ctxErr, attemptRecorder: attemptRecorder{attempts: attempts}}
+		}
+
 		// If there was an error, set it
-		fetchErr = err
+		fetchErr = &FetchError{URL: url, Status: status, Err: err}
 	}
 
 	// Return a converter processor with the final error
-	return &converterProcessor{err: fetchErr}
+	return &converterProcessor{err: fetchErr, attemptRecorder: attemptRecorder{attempts: attempts}}
+}
+
+// withAttempts records the fetch attempts on the processor
+func withAttempts(processor Processor, attempts []AttemptInfo) Processor {
+	switch typedProcessor := processor.(type) {
+	case *scanningProcessor:
+		typedProcessor.attempts = attempts
+	case *converterProcessor:
+		typedProcessor.attempts = attempts
+	}
+	return processor
+}
+
+// contextReader reads a response body, returning the context error once the context is done
+type contextReader struct {
+	ctx    context.Context
+	body   io.ReadCloser
+	cancel context.CancelFunc
+}
+
+// Read reads from the body (returning the context error if the context is done)
+func (r *contextReader) Read(p []byte) (int, error) {
+	n, err := r.body.Read(p)
+	if err != nil && r.ctx.Err() != nil {
+		return n, r.ctx.Err()
+	}
+	return n, err
+}
+
+// Close closes the body and releases the context
+func (r *contextReader) Close() error {
+	defer r.cancel()
+	return r.body.Close()
 }
 
 // widthPNG extracts the width from PNG header bytes
@@ -2,8 +2,11 @@ package png
 
 import (
 	"bytes"
+	"context"
 	"encoding/base64"
 	"encoding/binary"
+	"encoding/json"
+	"fmt"
 	"hash/crc32"
 	"image"
 	"image/color"
@@ -15,7 +18,9 @@ import (
 	"os"
 	"path/filepath"
 	"slices"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/r3dpixel/card-parser/character"
 	"github.com/r3dpixel/card-parser/property"
@@ -36,12 +41,16 @@ var (
 		tinyV2   *character.Sheet
 		firstV2  *character.Sheet
 		secondV2 *character.Sheet
+		smallV3  *character.Sheet
+		largeV2  *character.Sheet
 	}{
 		smallV2:  createSheet(character.RevisionV2, "Small V2"),
 		largeV3:  createSheet(character.RevisionV3, "Much larger V3 card with extra data to make it clearly bigger than the V2 card for testing longest chunk mode"),
 		tinyV2:   createSheet(character.RevisionV2, "Tiny"),
 		firstV2:  createSheet(character.RevisionV2, "First Card"),
 		secondV2: createSheet(character.RevisionV2, "Last  Card"), // Same length as firstV2
+		smallV3:  createSheet(character.RevisionV3, "Small V3"),
+		largeV2:  createSheet(character.RevisionV2, "Much larger V2 card with extra data to make it clearly bigger than the small V3 card"),
 	}
 )
 
@@ -60,7 +69,7 @@ func createSheet(revision character.Revision, name string) *character.Sheet {
 	return sheet
 }
 
-func encodeCardData(t *testing.T, sheet *character.Sheet) []byte {
+func encodeCardData(t testing.TB, sheet *character.Sheet) []byte {
 	t.Helper()
 	cardJSON, err := sheet.ToBytes()
 	require.NoError(t, err)
@@ -90,7 +99,7 @@ func createTestJPG(t *testing.T) []byte {
 }
 
 // injectSingleChunk creates a PNG with a single character chunk
-func injectSingleChunk(t *testing.T, basePNG []byte, sheet *character.Sheet, atEnd bool) []byte {
+func injectSingleChunk(t testing.TB, basePNG []byte, sheet *character.Sheet, atEnd bool) []byte {
 	t.Helper()
 	data := encodeCardData(t, sheet)
 	return injectChunk(t, basePNG, sheet.Revision, data, atEnd)
@@ -105,22 +114,22 @@ func injectDoubleChunk(t *testing.T, basePNG []byte, first, second *character.Sh
 	return injectChunk(t, withFirst, second.Revision, secondData, true)
 }
 
-func injectChunk(t *testing.T, pngBytes []byte, version character.Revision, data []byte, atEnd bool) []byte {
+func injectChunk(t testing.TB, pngBytes []byte, version character.Revision, data []byte, atEnd bool) []byte {
 	t.Helper()
-	keyword := keywords[version]
+	keyword := chunkKeywords()[version]
 	require.NotNil(t, keyword)
 
 	// Use streaming approach like production code
 	buf := new(bytes.Buffer)
 	chunkDataLen := uint32(len(keyword) + len(data))
 
-	// Write chunk header directly
+	// Write chunk length directly
 	require.NoError(t, binary.Write(buf, binary.BigEndian, chunkDataLen))
-	require.NoError(t, binary.Write(buf, binary.BigEndian, chunkTextTypeCode))
 
-	// Write data with CRC calculation
+	// Write type and data with CRC calculation (the CRC covers the chunk type)
 	crcHasher := crc32.NewIEEE()
 	multiWriter := io.MultiWriter(buf, crcHasher)
+	require.NoError(t, binary.Write(multiWriter, binary.BigEndian, chunkTextTypeCode))
 	_, err := multiWriter.Write(keyword)
 	require.NoError(t, err)
 	_, err = multiWriter.Write(data)
@@ -337,6 +346,54 @@ func TestProcessor_ScanModes(t *testing.T) {
 			scanMode: LastLongest,
 			want:     testCards.largeV3,
 		},
+		{
+			name:     "VersionLongest - longer V3 first beats shorter V3",
+			data:     injectDoubleChunk(t, basePNG, testCards.largeV3, testCards.smallV3),
+			scanMode: LastVersionLongest,
+			want:     testCards.largeV3,
+		},
+		{
+			name:     "VersionLongest - longer V3 last beats shorter V3",
+			data:     injectDoubleChunk(t, basePNG, testCards.smallV3, testCards.largeV3),
+			scanMode: LastVersionLongest,
+			want:     testCards.largeV3,
+		},
+		{
+			name:     "VersionLongest - longer V2 first beats shorter V2",
+			data:     injectDoubleChunk(t, basePNG, testCards.smallV2, testCards.tinyV2),
+			scanMode: LastVersionLongest,
+			want:     testCards.smallV2,
+		},
+		{
+			name:     "VersionLongest - longer V2 last beats shorter V2",
+			data:     injectDoubleChunk(t, basePNG, testCards.tinyV2, testCards.smallV2),
+			scanMode: LastVersionLongest,
+			want:     testCards.smallV2,
+		},
+		{
+			name:     "VersionLongest - same size prefers last",
+			data:     injectDoubleChunk(t, basePNG, testCards.firstV2, testCards.secondV2),
+			scanMode: LastVersionLongest,
+			want:     testCards.secondV2,
+		},
+		{
+			name:     "VersionLongest - smaller V3 beats larger V2",
+			data:     injectDoubleChunk(t, basePNG, testCards.largeV2, testCards.smallV3),
+			scanMode: LastVersionLongest,
+			want:     testCards.smallV3,
+		},
+		{
+			name:     "VersionLongest - smaller V3 first beats larger V2",
+			data:     injectDoubleChunk(t, basePNG, testCards.smallV3, testCards.largeV2),
+			scanMode: LastVersionLongest,
+			want:     testCards.smallV3,
+		},
+		{
+			name:     "HighestVersion - shorter V3 last wins",
+			data:     injectDoubleChunk(t, basePNG, testCards.largeV3, testCards.smallV3),
+			scanMode: LastVersion,
+			want:     testCards.smallV3,
+		},
 		{
 			name:     "No metadata - PNG",
 			data:     basePNG,
@@ -377,3 +434,444 @@ func TestProcessor_ScanModes(t *testing.T) {
 		assert.Error(t, err)
 	})
 }
+
+func TestParseScanMode(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected ScanMode
+		err      error
+	}{
+		{name: "First Lowercase", input: "first", expected: First},
+		{name: "First Uppercase", input: "FIRST", expected: First},
+		{name: "Last Version Snake Case", input: "last_version", expected: LastVersion},
+		{name: "Last Version Kebab Case", input: "last-version", expected: LastVersion},
+		{name: "Last Version Camel Case", input: "LastVersion", expected: LastVersion},
+		{name: "Last Longest With Whitespace", input: "  last longest  ", expected: LastLongest},
+		{name: "Last Longest Uppercase", input: "LAST_LONGEST", expected: LastLongest},
+		{name: "Last Version Longest Snake Case", input: "last_version_longest", expected: LastVersionLongest},
+		{name: "Last Version Longest Camel Case", input: "LastVersionLongest", expected: LastVersionLongest},
+		{name: "Invalid String", input: "deepest", expected: DefaultScanMode, err: ErrUnknownScanMode},
+		{name: "Empty String", input: "", expected: DefaultScanMode, err: ErrUnknownScanMode},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mode, err := ParseScanMode(tt.input)
+			assert.ErrorIs(t, err, tt.err)
+			assert.Equal(t, tt.expected.String(), mode.String())
+			assert.Equal(t, tt.expected.deepScan, mode.deepScan)
+		})
+	}
+}
+
+func TestScanMode_Text(t *testing.T) {
+	type config struct {
+		ScanMode ScanMode `json:"scan_mode"`
+	}
+
+	t.Run("Marshal", func(t *testing.T) {
+		for _, mode := range []ScanMode{First, LastVersion, LastLongest, LastVersionLongest} {
+			data, err := json.Marshal(config{ScanMode: mode})
+			require.NoError(t, err)
+			assert.JSONEq(t, fmt.Sprintf(`{"scan_mode": %q}`, mode), string(data))
+		}
+	})
+
+	t.Run("Unmarshal", func(t *testing.T) {
+		var decoded config
+		require.NoError(t, json.Unmarshal([]byte(`{"scan_mode": "Last-Longest"}`), &decoded))
+		assert.Equal(t, LastLongest.String(), decoded.ScanMode.String())
+
+		// The scanner uses the parsed mode
+		basePNG := createTestPNG(t, 4, 4)
+		rawCard, err := FromBytes(injectDoubleChunk(t, basePNG, testCards.largeV3, testCards.smallV2)).ScanMode(decoded.ScanMode).Get()
+		require.NoError(t, err)
+		assert.Equal(t, character.RevisionV3, rawCard.Revision)
+	})
+
+	t.Run("Unmarshal unknown", func(t *testing.T) {
+		decoded := config{ScanMode: LastVersion}
+		err := json.Unmarshal([]byte(`{"scan_mode": "deepest"}`), &decoded)
+		assert.ErrorIs(t, err, ErrUnknownScanMode)
+		assert.Equal(t, DefaultScanMode.String(), decoded.ScanMode.String())
+	})
+}
+
+func TestProcessor_GetAll(t *testing.T) {
+	basePNG := createTestPNG(t, 4, 4)
+	withV2 := injectSingleChunk(t, basePNG, testCards.tinyV2, false)
+	withV2V2 := injectSingleChunk(t, withV2, testCards.smallV2, true)
+	withAll := injectSingleChunk(t, withV2V2, testCards.largeV3, true)
+
+	t.Run("Two V2 chunks and one V3 chunk", func(t *testing.T) {
+		for _, scanMode := range []ScanMode{First, LastVersion, LastLongest} {
+			rawCards, err := FromBytes(withAll).ScanMode(scanMode).GetAll()
+			require.NoError(t, err)
+			require.Len(t, rawCards, 3)
+
+			expected := []*character.Sheet{testCards.tinyV2, testCards.smallV2, testCards.largeV3}
+			for index, rawCard := range rawCards {
+				assert.Equal(t, expected[index].Revision, rawCard.Revision)
+				assert.Equal(t, encodeCardData(t, expected[index]), rawCard.RawCharaData)
+
+				card, err := rawCard.Decode()
+				require.NoError(t, err)
+				assert.Equal(t, expected[index].Name, card.Name)
+
+				// The image data is shared and stripped of chara chunks
+				assert.Equal(t, rawCards[0].Header, rawCard.Header)
+				assert.Equal(t, rawCards[0].Body, rawCard.Body)
+			}
+		}
+	})
+
+	t.Run("Get is unchanged", func(t *testing.T) {
+		rawCard, err := FromBytes(withAll).First().Get()
+		require.NoError(t, err)
+		assert.Equal(t, testCards.tinyV2.Revision, rawCard.Revision)
+		assert.Equal(t, encodeCardData(t, testCards.tinyV2), rawCard.RawCharaData)
+	})
+
+	t.Run("No chara chunks", func(t *testing.T) {
+		rawCards, err := FromBytes(basePNG).GetAll()
+		require.NoError(t, err)
+		assert.Empty(t, rawCards)
+
+		rawCards, err = FromBytes(createTestJPG(t)).GetAll()
+		require.NoError(t, err)
+		assert.Empty(t, rawCards)
+	})
+
+	t.Run("Errors", func(t *testing.T) {
+		malformed := slices.Concat(pngHeader, minimalIHDR, []byte{0x00, 0x00, 0x01})
+		_, err := FromBytes(malformed).GetAll()
+		assert.Error(t, err)
+
+		_, err = FromFile("missing.png").GetAll()
+		assert.Error(t, err)
+	})
+}
+
+func TestProcessor_VerifyCRC(t *testing.T) {
+	basePNG := createTestPNG(t, 4, 4)
+	withChara := injectSingleChunk(t, basePNG, testCards.smallV2, false)
+	charaOffset := headerSize + ihdrSize
+	idatOffset := charaOffset + chunkHeaderSize + charaKeywordSize + len(encodeCardData(t, testCards.smallV2))
+	require.Equal(t, "IDAT", string(withChara[idatOffset+chunkLengthSize:idatOffset+chunkLengthSize+chunkTypeSize]))
+
+	// corrupt flips one byte at the given position
+	corrupt := func(data []byte, position int) []byte {
+		corrupted := slices.Clone(data)
+		corrupted[position] ^= 0xFF
+		return corrupted
+	}
+
+	t.Run("Valid PNG", func(t *testing.T) {
+		for _, scanMode := range []ScanMode{First, LastVersion, LastLongest} {
+			expected, err := FromBytes(withChara).ScanMode(scanMode).Get()
+			require.NoError(t, err)
+			rawCard, err := FromBytes(withChara).ScanMode(scanMode).VerifyCRC().Get()
+			require.NoError(t, err)
+			assert.Equal(t, expected, rawCard)
+		}
+	})
+
+	tests := []struct {
+		name      string
+		data      []byte
+		scanMode  ScanMode
+		chunkType string
+		offset    int
+	}{
+		{"Corrupted chara chunk", corrupt(withChara, charaOffset+chunkHeaderSize), LastVersion, "tEXt", charaOffset},
+		{"Corrupted streamed chunk", corrupt(withChara, idatOffset+chunkHeaderSize), LastVersion, "IDAT", idatOffset},
+		{"Corrupted chunk after short-circuit", corrupt(withChara, idatOffset+chunkHeaderSize), First, "IDAT", idatOffset},
+		{"Corrupted IHDR", corrupt(withChara, headerSize+chunkHeaderSize), First, "IHDR", headerSize},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Without verification the corruption goes unnoticed
+			_, err := FromBytes(tt.data).ScanMode(tt.scanMode).Get()
+			require.NoError(t, err)
+
+			// With verification the corrupted chunk is reported
+			_, err = FromBytes(tt.data).ScanMode(tt.scanMode).VerifyCRC().Get()
+			require.ErrorIs(t, err, ErrCRCMismatch)
+			assert.Contains(t, err.Error(), fmt.Sprintf("%q chunk at offset %d", tt.chunkType, tt.offset))
+		})
+	}
+
+	t.Run("Converter processor", func(t *testing.T) {
+		_, err := FromBytes(createTestJPG(t)).VerifyCRC().Get()
+		assert.NoError(t, err)
+	})
+}
+
+func TestProcessor_MaxChunkSize(t *testing.T) {
+	basePNG := createTestPNG(t, 4, 4)
+	injectionPoint := headerSize + ihdrSize
+
+	// A forged chara chunk declaring a huge length over a tiny body
+	forged := binary.BigEndian.AppendUint32(nil, 0x7FFFFFFF)
+	forged = binary.BigEndian.AppendUint32(forged, chunkTextTypeCode)
+	forged = append(forged, charaKeyword...)
+	forgedPNG := slices.Concat(basePNG[:injectionPoint], forged, basePNG[injectionPoint:])
+
+	// A card with two chara chunks (each under the limit), and a card with a large non-chara tEXt chunk
+	limit := len(encodeCardData(t, testCards.largeV3)) + ccv3KeywordSize
+	doublePNG := injectDoubleChunk(t, basePNG, testCards.smallV2, testCards.largeV3)
+	textPNG := slices.Concat(basePNG[:injectionPoint], textChunk(append([]byte("Comment\x00"), make([]byte, 4096)...)), basePNG[injectionPoint:])
+
+	tests := []struct {
+		name         string
+		data         []byte
+		scanMode     ScanMode
+		maxChunkSize int
+		err          error
+	}{
+		{"Forged length with default limit", forgedPNG, First, DefaultMaxChunkSize, ErrChunkTooLarge},
+		{"Forged length with custom limit", forgedPNG, First, 1024, ErrChunkTooLarge},
+		{"Chunks under the limit", doublePNG, First, limit, nil},
+		{"Chunks retained over the limit in deep scan", doublePNG, LastLongest, limit, ErrChunkTooLarge},
+		{"Chunks retained without limit in deep scan", doublePNG, LastLongest, 0, nil},
+		{"Text chunk over the limit", textPNG, First, 1024, ErrChunkTooLarge},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := FromBytes(tt.data).ScanMode(tt.scanMode).MaxChunkSize(tt.maxChunkSize).Get()
+			if tt.err != nil {
+				require.ErrorIs(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+
+	t.Run("Chunks retained over the limit in GetAll", func(t *testing.T) {
+		_, err := FromBytes(doublePNG).MaxChunkSize(limit).GetAll()
+		assert.ErrorIs(t, err, ErrChunkTooLarge)
+	})
+
+	t.Run("Converter processor", func(t *testing.T) {
+		_, err := FromBytes(createTestJPG(t)).MaxChunkSize(1).Get()
+		assert.NoError(t, err)
+	})
+}
+
+func TestFromURLContext(t *testing.T) {
+	pngBytes := createTestPNG(t, 4, 4)
+	client := reqx.NewClient(reqx.Options{RetryCount: 1})
+
+	// Track which URLs were accessed
+	var mu sync.Mutex
+	var accessLog []string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		accessLog = append(accessLog, r.URL.Path)
+		mu.Unlock()
+
+		switch r.URL.Path {
+		case "/fast":
+			w.Header().Set("Content-Type", "image/png")
+			w.Write(pngBytes)
+		case "/slow":
+			// Hang until the request is cancelled
+			<-r.Context().Done()
+		case "/stall":
+			// Send the PNG header, then hang mid-stream
+			w.Header().Set("Content-Type", "image/png")
+			w.Write(pngBytes[:fullIhdrSize])
+			w.(http.Flusher).Flush()
+			<-r.Context().Done()
+		}
+	}))
+	defer server.Close()
+
+	// resetLog clears the access log
+	resetLog := func() {
+		mu.Lock()
+		defer mu.Unlock()
+		accessLog = nil
+	}
+
+	timeoutOpts := SourceOptions{URLTimeout: 100 * time.Millisecond}
+
+	t.Run("Per-URL timeout falls back to the next URL", func(t *testing.T) {
+		resetLog()
+
+		start := time.Now()
+		rawCard, err := FromURLWithOptions(context.Background(), client, timeoutOpts, server.URL+"/slow", server.URL+"/fast").Get()
+		require.NoError(t, err)
+		assert.Equal(t, 4, rawCard.Width())
+		assert.Less(t, time.Since(start), 2*time.Second)
+
+		mu.Lock()
+		defer mu.Unlock()
+		assert.Equal(t, "/fast", accessLog[len(accessLog)-1])
+		assert.NotContains(t, accessLog[:len(accessLog)-1], "/fast")
+	})
+
+	t.Run("Cancelled context stops the fallback", func(t *testing.T) {
+		resetLog()
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+		defer cancel()
+
+		processor := FromURLContext(ctx, client, server.URL+"/slow", server.URL+"/fast")
+		assert.ErrorIs(t, processor.Err(), context.DeadlineExceeded)
+
+		mu.Lock()
+		defer mu.Unlock()
+		assert.NotContains(t, accessLog, "/fast")
+	})
+
+	t.Run("Already cancelled context", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		_, err := FromURLContext(ctx, client, server.URL+"/fast").Get()
+		assert.ErrorIs(t, err, context.Canceled)
+	})
+
+	t.Run("Cancellation mid-stream", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		processor := FromURLContext(ctx, client, server.URL+"/stall")
+		require.NoError(t, processor.Err())
+
+		time.AfterFunc(100*time.Millisecond, cancel)
+		_, err := processor.Get()
+		assert.ErrorIs(t, err, context.Canceled)
+	})
+
+	t.Run("Per-URL timeout mid-stream", func(t *testing.T) {
+		_, err := FromURLWithOptions(context.Background(), client, timeoutOpts, server.URL+"/stall").Get()
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+	})
+}
+
+func TestProcessor_Pipe(t *testing.T) {
+	basePNG := createTestPNG(t, 4, 4)
+	v2Data := encodeCardData(t, testCards.smallV2)
+	replacement := encodeCardData(t, testCards.firstV2)
+
+	// withTextChunk adds a non-chara tEXt chunk right after the IHDR chunk
+	withTextChunk := func(data []byte) []byte {
+		return slices.Concat(data[:fullIhdrSize], textChunk([]byte("Software\x00paint")), data[fullIhdrSize:])
+	}
+
+	tests := []struct {
+		name      string
+		data      []byte
+		transform func(*RawCard) error
+		expected  []byte
+	}{
+		{"Chara chunk first", injectSingleChunk(t, basePNG, testCards.smallV2, false), nil, v2Data},
+		{"Chara chunk last", injectSingleChunk(t, basePNG, testCards.smallV2, true), nil, v2Data},
+		{"Text chunk", withTextChunk(injectSingleChunk(t, basePNG, testCards.smallV2, false)), nil, v2Data},
+		{"No chara chunk", basePNG, nil, nil},
+		{
+			name: "Replaced chara data",
+			data: injectSingleChunk(t, basePNG, testCards.smallV2, false),
+			transform: func(rawCard *RawCard) error {
+				if rawCard.Body != nil {
+					return fmt.Errorf("unexpected body")
+				}
+				rawCard.RawCharaData = replacement
+				return nil
+			},
+			expected: replacement,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			require.NoError(t, FromBytes(tt.data).Pipe(&buf, tt.transform))
+
+			// The piped image matches the buffered image
+			original, err := FromBytes(tt.data).Get()
+			require.NoError(t, err)
+			piped, err := FromBytes(buf.Bytes()).Get()
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, []byte(piped.RawCharaData))
+			assert.Equal(t, original.Header, piped.Header)
+			assert.Equal(t, original.Body, piped.Body)
+			assert.Equal(t, original.TextChunks, piped.TextChunks)
+
+			// The piped image is a valid PNG
+			_, err = png.Decode(bytes.NewReader(buf.Bytes()))
+			assert.NoError(t, err)
+		})
+	}
+
+	t.Run("Transform error", func(t *testing.T) {
+		transformErr := fmt.Errorf("transform failed")
+		err := FromBytes(injectSingleChunk(t, basePNG, testCards.smallV2, false)).Pipe(io.Discard, func(*RawCard) error {
+			return transformErr
+		})
+		assert.ErrorIs(t, err, transformErr)
+	})
+
+	t.Run("Converted image", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.NoError(t, FromBytes(createTestJPG(t)).Pipe(&buf, func(rawCard *RawCard) error {
+			rawCard.RawCharaData = v2Data
+			rawCard.Revision = character.RevisionV2
+			return nil
+		}))
+		piped, err := FromBytes(buf.Bytes()).Get()
+		require.NoError(t, err)
+		assert.Equal(t, v2Data, []byte(piped.RawCharaData))
+	})
+
+	t.Run("Processor error", func(t *testing.T) {
+		err := FromFile(filepath.Join(t.TempDir(), "missing.png")).Pipe(io.Discard, nil)
+		assert.Error(t, err)
+	})
+}
+
+// ~10 MB chara card shared by the Pipe benchmarks
+var (
+	benchmarkPNGOnce sync.Once
+	benchmarkPNGData []byte
+)
+
+// benchmarkPNG returns the ~10 MB chara card shared by the Pipe benchmarks
+func benchmarkPNG(b *testing.B) []byte {
+	b.Helper()
+	benchmarkPNGOnce.Do(func() {
+		benchmarkPNGData = injectSingleChunk(b, createNoisyPNG(b, 1600, 1600), testCards.largeV3, false)
+	})
+	return benchmarkPNGData
+}
+
+func BenchmarkProcessor_GetToImage(b *testing.B) {
+	data := benchmarkPNG(b)
+	b.SetBytes(int64(len(data)))
+	b.ReportAllocs()
+	for b.Loop() {
+		rawCard, err := FromBytes(data).Get()
+		if err != nil {
+			b.Fatal(err)
+		}
+		if err := rawCard.ToImage(io.Discard); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkProcessor_Pipe(b *testing.B) {
+	data := benchmarkPNG(b)
+	b.SetBytes(int64(len(data)))
+	b.ReportAllocs()
+	for b.Loop() {
+		if err := FromBytes(data).Pipe(io.Discard, nil); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
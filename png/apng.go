@@ -0,0 +1,31 @@
+package png
+
+import "errors"
+
+// Discriminator 'acTL' (uint32) - 0x6163544C, the animation control chunk of APNG images
+const chunkACTLTypeCode uint32 = 0x6163544C
+
+// ErrAnimatedResizeUnsupported is returned when an animated (APNG) image is scaled down without ResizeOptions.Animated
+var ErrAnimatedResizeUnsupported = errors.New("resizing animated PNG images is not supported")
+
+// ResizeOptions configures ScaleDown and Resized
+type ResizeOptions struct {
+	// Animated scales animated (APNG) images down to their default image, dropping the animation; by default, they
+	// fail with ErrAnimatedResizeUnsupported instead of silently flattening the animation
+	Animated bool
+}
+
+// resizeOptions returns the first options, or the zero value if none are given
+func resizeOptions(opts []ResizeOptions) ResizeOptions {
+	if len(opts) == 0 {
+		return ResizeOptions{}
+	}
+	return opts[0]
+}
+
+// IsAnimated checks if the image is an animated PNG (an acTL chunk was found when scanning)
+// The animation chunks (acTL, fcTL and fdAT) are copied as is, and the chara chunks are written by ToImage right after
+// the IHDR chunk (or by Pipe right before IEND), so they never land inside a frame: the animation survives a rewrite
+func (p *pngData) IsAnimated() bool {
+	return p.animated
+}
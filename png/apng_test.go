@@ -0,0 +1,154 @@
+package png
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image/png"
+	"io"
+	"testing"
+
+	"github.com/r3dpixel/card-parser/character"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// APNG chunk discriminators used by the tests ('fcTL', 'fdAT' and 'IDAT')
+const (
+	chunkFCTLTypeCode uint32 = 0x6663544C
+	chunkFDATTypeCode uint32 = 0x66644154
+	chunkIDATTypeCode uint32 = 0x49444154
+)
+
+// pngChunk type and raw bytes (length, type, data and CRC) of a PNG chunk
+type pngChunk struct {
+	typeCode uint32
+	raw      []byte
+}
+
+// splitChunks splits the chunks following the IHDR chunk of a PNG
+func splitChunks(t *testing.T, data []byte) []pngChunk {
+	t.Helper()
+	var chunks []pngChunk
+	for rest := data[fullIhdrSize:]; len(rest) > 0; {
+		require.GreaterOrEqual(t, len(rest), chunkHeaderSize)
+		size := chunkHeaderSize + int(binary.BigEndian.Uint32(rest))
+		chunks = append(chunks, pngChunk{binary.BigEndian.Uint32(rest[chunkLengthSize:]), rest[:size]})
+		rest = rest[size:]
+	}
+	return chunks
+}
+
+// frameControl builds the data of a fcTL chunk covering the whole image
+func frameControl(sequence uint32, width int, height int) []byte {
+	data := binary.BigEndian.AppendUint32(nil, sequence)
+	data = binary.BigEndian.AppendUint32(data, uint32(width))
+	data = binary.BigEndian.AppendUint32(data, uint32(height))
+	data = binary.BigEndian.AppendUint64(data, 0)          // Offsets
+	data = binary.BigEndian.AppendUint32(data, 0x0001000A) // Delay (1/10s)
+	return append(data, 0, 0)                              // Dispose and blend operations
+}
+
+// createTestAPNG creates a two frame APNG (the second frame reuses the image data of the default image)
+func createTestAPNG(t *testing.T, width, height int) []byte {
+	t.Helper()
+	still := createTestPNG(t, width, height)
+	chunks := splitChunks(t, still)
+
+	// Animation control and the frame control of the default image, before the image data
+	apng := bytes.Clone(still[:fullIhdrSize])
+	apng = append(apng, typedChunk(chunkACTLTypeCode, binary.BigEndian.AppendUint64(nil, 2<<32))...)
+	apng = append(apng, typedChunk(chunkFCTLTypeCode, frameControl(0, width, height))...)
+
+	// Default image, then the second frame
+	var frameData []byte
+	for _, chunk := range chunks[:len(chunks)-1] {
+		apng = append(apng, chunk.raw...)
+		if chunk.typeCode == chunkIDATTypeCode {
+			frameData = append(frameData, chunk.raw[chunkLengthSize+chunkTypeSize:len(chunk.raw)-chunkCrcSize]...)
+		}
+	}
+	apng = append(apng, typedChunk(chunkFCTLTypeCode, frameControl(1, width, height))...)
+	apng = append(apng, typedChunk(chunkFDATTypeCode, binary.BigEndian.AppendUint32(nil, 2), frameData)...)
+	return append(apng, chunks[len(chunks)-1].raw...)
+}
+
+// animationChunks returns the animation chunks (acTL, fcTL and fdAT) of a PNG, and checks that no text chunk lands
+// between a fcTL chunk and the frame data following it
+func animationChunks(t *testing.T, data []byte) [][]byte {
+	t.Helper()
+	var animation [][]byte
+	inFrame := false
+	for _, chunk := range splitChunks(t, data) {
+		switch chunk.typeCode {
+		case chunkACTLTypeCode, chunkFDATTypeCode:
+			animation = append(animation, chunk.raw)
+			inFrame = false
+		case chunkFCTLTypeCode:
+			animation = append(animation, chunk.raw)
+			inFrame = true
+		case chunkIDATTypeCode:
+			inFrame = false
+		default:
+			_, isText := chunkFormats[chunk.typeCode]
+			assert.False(t, isText && inFrame, "text chunk inside a frame")
+		}
+	}
+	return animation
+}
+
+func TestRawCard_APNG(t *testing.T) {
+	apng := createTestAPNG(t, 8, 8)
+	input := injectSingleChunk(t, apng, createSheet(character.RevisionV2, "Before"), false)
+
+	// Read the animated card
+	rawCard, err := FromBytes(input).First().Get()
+	require.NoError(t, err)
+	assert.True(t, rawCard.IsAnimated())
+
+	// Rewrite the metadata
+	rawCard.RawCharaData = encodeCardData(t, createSheet(character.RevisionV3, "After"))
+	rawCard.Revision = character.RevisionV3
+	output, err := rawCard.ToBytes()
+	require.NoError(t, err)
+
+	// The frames survive the rewrite
+	assert.Len(t, animationChunks(t, apng), 4)
+	assert.Equal(t, animationChunks(t, apng), animationChunks(t, output))
+	_, err = png.Decode(bytes.NewReader(output))
+	require.NoError(t, err)
+
+	// The new metadata is read back
+	card, err := FromBytes(output).First().Get()
+	require.NoError(t, err)
+	assert.True(t, card.IsAnimated())
+	sheet, err := card.Decode()
+	require.NoError(t, err)
+	assert.Equal(t, "After", string(sheet.Name))
+}
+
+func TestRawCard_APNG_Pipe(t *testing.T) {
+	apng := createTestAPNG(t, 8, 8)
+	input := injectSingleChunk(t, apng, createSheet(character.RevisionV2, "Card"), false)
+
+	var output bytes.Buffer
+	require.NoError(t, FromImage(io.NopCloser(bytes.NewReader(input))).Pipe(&output, nil))
+	assert.Equal(t, animationChunks(t, apng), animationChunks(t, output.Bytes()))
+}
+
+func TestRawCard_APNG_Resize(t *testing.T) {
+	rawCard, err := FromBytes(createTestAPNG(t, 8, 8)).First().Get()
+	require.NoError(t, err)
+
+	// Animated images are not resized by default
+	_, err = rawCard.Resized(4)
+	assert.ErrorIs(t, err, ErrAnimatedResizeUnsupported)
+	assert.ErrorIs(t, rawCard.ScaleDown(4), ErrAnimatedResizeUnsupported)
+	assert.Equal(t, 8, rawCard.Width())
+
+	// The animation is dropped when forced
+	resized, err := rawCard.Resized(4, ResizeOptions{Animated: true})
+	require.NoError(t, err)
+	assert.False(t, resized.IsAnimated())
+	assert.Equal(t, 4, resized.Width())
+	assert.Empty(t, animationChunks(t, append(bytes.Clone(resized.Header), resized.Body...)))
+}
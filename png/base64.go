@@ -0,0 +1,80 @@
+package png
+
+import (
+	"bytes"
+	"encoding/base64"
+)
+
+// Base64Encoding base64 variant of the chara data
+type Base64Encoding int
+
+// Base64Encoding values (in the order they are tried when decoding)
+const (
+	StdBase64    Base64Encoding = iota // Standard alphabet, padded (the variant written by ToRaw and ToImage)
+	RawStdBase64                       // Standard alphabet, unpadded
+	URLBase64                          // URL-safe alphabet (- and _ instead of + and /), padded
+	RawURLBase64                       // URL-safe alphabet, unpadded
+)
+
+// base64Encodings decoders of the Base64Encoding values
+var base64Encodings = []*base64.Encoding{
+	StdBase64:    base64.StdEncoding,
+	RawStdBase64: base64.RawStdEncoding,
+	URLBase64:    base64.URLEncoding,
+	RawURLBase64: base64.RawURLEncoding,
+}
+
+// base64Whitespace whitespace embedded in the chara data by some writers
+const base64Whitespace = " \t\r\n"
+
+// String returns the name of the base64 variant
+func (e Base64Encoding) String() string {
+	switch e {
+	case RawStdBase64:
+		return "raw_std"
+	case URLBase64:
+		return "url"
+	case RawURLBase64:
+		return "raw_url"
+	default:
+		return "std"
+	}
+}
+
+// decodeBase64 decodes the chara data trying every base64 variant (standard first), ignoring embedded whitespace
+// Returns the decoded data and the variant used (the error of the standard variant if none matches)
+func decodeBase64(data []byte) ([]byte, Base64Encoding, error) {
+	// Remove the embedded whitespace
+	if bytes.ContainsAny(data, base64Whitespace) {
+		data = bytes.Join(bytes.Fields(data), nil)
+	}
+
+	// Try every variant (sharing the output buffer, the unpadded variants need the largest one)
+	decoded := make([]byte, base64.RawStdEncoding.DecodedLen(len(data)))
+	var stdErr error
+	for variant, encoding := range base64Encodings {
+		n, err := encoding.Decode(decoded, data)
+		if err == nil {
+			return decoded[:n], Base64Encoding(variant), nil
+		}
+		if stdErr == nil {
+			stdErr = err
+		}
+	}
+	return nil, StdBase64, stdErr
+}
+
+// detectBase64 returns the base64 variant of the chara data (StdBase64 if no variant matches), decoding it only when it
+// is not in the standard padded form
+func detectBase64(data []byte) Base64Encoding {
+	if isStdBase64(data) {
+		return StdBase64
+	}
+	_, encoding, _ := decodeBase64(data)
+	return encoding
+}
+
+// isStdBase64 checks if the chara data is in the standard padded form (without whitespace), without decoding it
+func isStdBase64(data []byte) bool {
+	return len(data)%4 == 0 && !bytes.ContainsAny(data, "-_"+base64Whitespace)
+}
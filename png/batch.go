@@ -0,0 +1,247 @@
+package png
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultSlowestFiles is the number of slowest files reported by ProcessDir (if BatchOptions.SlowestFiles is not set)
+const DefaultSlowestFiles = 10
+
+// BatchOptions options of ProcessDir
+type BatchOptions struct {
+	Extensions   []string // Extensions of the processed files, case-insensitive (default: .png)
+	MaxDepth     int      // Maximum depth of the processed files (1 for the root directory only, 0 for no limit)
+	Workers      int      // Number of files processed concurrently (default: GOMAXPROCS)
+	ScanMode     ScanMode // Scan mode of the processors (default: DefaultScanMode)
+	SlowestFiles int      // Number of slowest files reported (default: DefaultSlowestFiles)
+}
+
+// BatchReport outcome of ProcessDir
+type BatchReport struct {
+	Parsed     int          `json:"parsed"`      // Files with chara data processed successfully
+	Failed     int          `json:"failed"`      // Files that could not be read or processed (see Errors)
+	NoMetadata int          `json:"no_metadata"` // Files without chara data (not passed to the callback)
+	Errors     []*FileError `json:"errors"`      // Errors of the failed files
+	Slowest    []FileTiming `json:"slowest"`     // Slowest files (slowest first)
+}
+
+// FileError an error of a file processed by ProcessDir
+type FileError struct {
+	Path string
+	Err  error
+}
+
+// Error returns the error message prefixed by the file path
+func (e *FileError) Error() string {
+	return fmt.Sprintf("%s: %v", e.Path, e.Err)
+}
+
+// Unwrap returns the underlying error
+func (e *FileError) Unwrap() error {
+	return e.Err
+}
+
+// FileTiming processing time of a file
+type FileTiming struct {
+	Path     string        `json:"path"`
+	Duration time.Duration `json:"duration"`
+}
+
+// fileStatus outcome of a processed file
+type fileStatus int
+
+const (
+	fileParsed fileStatus = iota
+	fileFailed
+	fileNoMetadata
+)
+
+// fileResult outcome and processing time of a file
+type fileResult struct {
+	FileTiming
+	status fileStatus
+	err    error
+}
+
+// ProcessDir walks the directory tree and processes every file matching the options concurrently, calling fn
+// (from multiple goroutines) with the raw card of every file carrying chara data
+// Per-file errors (including the errors returned by fn) are collected in the report instead of aborting the walk;
+// the error is only set if the root cannot be walked or the context is done (the report holds the processed files)
+func ProcessDir(ctx context.Context, root string, opts BatchOptions, fn func(path string, card *RawCard) error) (BatchReport, error) {
+	opts = opts.withDefaults()
+	paths := make(chan string)
+	results := make(chan fileResult)
+
+	// Start the workers (each one reusing its file buffer)
+	var workers sync.WaitGroup
+	for range opts.Workers {
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			var buffer bytes.Buffer
+			for path := range paths {
+				results <- processFile(path, &buffer, opts.ScanMode, fn)
+			}
+		}()
+	}
+
+	// Walk the tree, then wait for the workers to finish
+	var walkErr error
+	go func() {
+		walkErr = walkDir(ctx, root, opts, paths, results)
+		close(paths)
+		workers.Wait()
+		close(results)
+	}()
+
+	// Collect the results
+	report := BatchReport{Errors: []*FileError{}, Slowest: []FileTiming{}}
+	for result := range results {
+		report.add(result, opts.SlowestFiles)
+	}
+
+	// Return the report (and the walk or context error)
+	if walkErr != nil {
+		return report, walkErr
+	}
+	return report, ctx.Err()
+}
+
+// withDefaults returns the options with the unset values replaced by the defaults
+func (o BatchOptions) withDefaults() BatchOptions {
+	if len(o.Extensions) == 0 {
+		o.Extensions = []string{Extension}
+	}
+	if o.Workers <= 0 {
+		o.Workers = runtime.GOMAXPROCS(0)
+	}
+	if o.ScanMode.criteria == nil {
+		o.ScanMode = DefaultScanMode
+	}
+	if o.SlowestFiles <= 0 {
+		o.SlowestFiles = DefaultSlowestFiles
+	}
+	return o
+}
+
+// matches checks if the file extension is one of the processed extensions
+func (o BatchOptions) matches(path string) bool {
+	extension := filepath.Ext(path)
+	return slices.ContainsFunc(o.Extensions, func(candidate string) bool {
+		return strings.EqualFold(candidate, extension)
+	})
+}
+
+// walkDir sends the paths of the matching files (the unreadable directories are sent as failed results)
+func walkDir(ctx context.Context, root string, opts BatchOptions, paths chan<- string, results chan<- fileResult) error {
+	return filepath.WalkDir(root, func(path string, entry fs.DirEntry, err error) error {
+		// Stop if the context is done
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ctxErr
+		}
+
+		// Report the unreadable entries (the root is fatal)
+		if err != nil {
+			if path == root {
+				return err
+			}
+			results <- fileResult{FileTiming: FileTiming{Path: path}, status: fileFailed, err: err}
+			return nil
+		}
+
+		// Skip the directories past the maximum depth
+		depth := 0
+		if rel, relErr := filepath.Rel(root, path); relErr == nil && rel != "." {
+			depth = strings.Count(rel, string(filepath.Separator)) + 1
+		}
+		if entry.IsDir() {
+			if opts.MaxDepth > 0 && depth >= opts.MaxDepth {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		// Send the matching files
+		if !entry.Type().IsRegular() || !opts.matches(path) {
+			return nil
+		}
+		select {
+		case paths <- path:
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	})
+}
+
+// processFile processes the file, and returns its outcome and processing time
+func processFile(path string, buffer *bytes.Buffer, scanMode ScanMode, fn func(path string, card *RawCard) error) fileResult {
+	start := time.Now()
+	status, err := processCard(path, buffer, scanMode, fn)
+	return fileResult{FileTiming: FileTiming{Path: path, Duration: time.Since(start)}, status: status, err: err}
+}
+
+// processCard reads the file into the buffer, extracts the raw card, and calls fn if the card carries chara data
+// The raw card does not reference the buffer, so the buffer is reused for the next file
+func processCard(path string, buffer *bytes.Buffer, scanMode ScanMode, fn func(path string, card *RawCard) error) (fileStatus, error) {
+	// Read the file
+	buffer.Reset()
+	file, err := os.Open(path)
+	if err != nil {
+		return fileFailed, err
+	}
+	_, err = buffer.ReadFrom(file)
+	_ = file.Close()
+	if err != nil {
+		return fileFailed, err
+	}
+
+	// Extract the raw card
+	card, err := FromBytes(buffer.Bytes()).ScanMode(scanMode).Get()
+	if err != nil {
+		return fileFailed, err
+	}
+	if len(card.RawCharaData) == 0 {
+		return fileNoMetadata, nil
+	}
+
+	// Process the raw card
+	if err := fn(path, card); err != nil {
+		return fileFailed, err
+	}
+	return fileParsed, nil
+}
+
+// add records the result of a file, keeping the given number of slowest files
+func (r *BatchReport) add(result fileResult, slowest int) {
+	// Count the file
+	switch result.status {
+	case fileParsed:
+		r.Parsed++
+	case fileNoMetadata:
+		r.NoMetadata++
+	default:
+		r.Failed++
+		r.Errors = append(r.Errors, &FileError{Path: result.Path, Err: result.err})
+	}
+
+	// Insert the timing in order (slowest first)
+	index, _ := slices.BinarySearchFunc(r.Slowest, result.Duration, func(timing FileTiming, duration time.Duration) int {
+		return cmp.Compare(duration, timing.Duration)
+	})
+	if index < slowest {
+		r.Slowest = slices.Insert(r.Slowest, index, result.FileTiming)
+		r.Slowest = r.Slowest[:min(len(r.Slowest), slowest)]
+	}
+}
@@ -0,0 +1,144 @@
+package png
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// createBatchDir creates a directory with valid cards, an image without metadata, a JPEG, and a corrupted file
+func createBatchDir(t *testing.T) string {
+	t.Helper()
+	root := t.TempDir()
+	basePNG := createTestPNG(t, 4, 4)
+	files := map[string][]byte{
+		"first.png":             injectSingleChunk(t, basePNG, testCards.smallV2, false),
+		"nested/second.PNG":     injectSingleChunk(t, basePNG, testCards.largeV3, true),
+		"nested/deep/third.png": injectSingleChunk(t, basePNG, testCards.smallV2, true),
+		"plain.png":             basePNG,
+		"photo.jpg":             createTestJPG(t),
+		"corrupted.png":         []byte("not an image"),
+		"notes.txt":             []byte("ignored"),
+	}
+	for name, data := range files {
+		path := filepath.Join(root, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		require.NoError(t, os.WriteFile(path, data, 0o644))
+	}
+	return root
+}
+
+func TestProcessDir(t *testing.T) {
+	root := createBatchDir(t)
+
+	tests := []struct {
+		name       string
+		opts       BatchOptions
+		parsed     []string
+		failed     []string
+		noMetadata int
+	}{
+		{
+			name:       "Default options",
+			opts:       BatchOptions{},
+			parsed:     []string{"first.png", "nested/deep/third.png", "nested/second.PNG"},
+			failed:     []string{"corrupted.png"},
+			noMetadata: 1,
+		},
+		{
+			name:       "Extension filter",
+			opts:       BatchOptions{Extensions: []string{".png", ".JPG"}, Workers: 2},
+			parsed:     []string{"first.png", "nested/deep/third.png", "nested/second.PNG"},
+			failed:     []string{"corrupted.png"},
+			noMetadata: 2,
+		},
+		{
+			name:       "Root directory only",
+			opts:       BatchOptions{MaxDepth: 1, Workers: 1},
+			parsed:     []string{"first.png"},
+			failed:     []string{"corrupted.png"},
+			noMetadata: 1,
+		},
+		{
+			name:       "Maximum depth",
+			opts:       BatchOptions{MaxDepth: 2, ScanMode: LastLongest},
+			parsed:     []string{"first.png", "nested/second.PNG"},
+			failed:     []string{"corrupted.png"},
+			noMetadata: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var mu sync.Mutex
+			var parsed []string
+			report, err := ProcessDir(context.Background(), root, tt.opts, func(path string, card *RawCard) error {
+				if _, err := card.Decode(); err != nil {
+					return err
+				}
+				rel, _ := filepath.Rel(root, path)
+				mu.Lock()
+				defer mu.Unlock()
+				parsed = append(parsed, filepath.ToSlash(rel))
+				return nil
+			})
+			require.NoError(t, err)
+
+			sort.Strings(parsed)
+			assert.Equal(t, tt.parsed, parsed)
+			assert.Equal(t, len(tt.parsed), report.Parsed)
+			assert.Equal(t, len(tt.failed), report.Failed)
+			assert.Equal(t, tt.noMetadata, report.NoMetadata)
+			require.Len(t, report.Errors, len(tt.failed))
+			for index, fileErr := range report.Errors {
+				assert.Equal(t, filepath.Join(root, tt.failed[index]), fileErr.Path)
+			}
+			assert.Len(t, report.Slowest, report.Parsed+report.Failed+report.NoMetadata)
+			for index := 1; index < len(report.Slowest); index++ {
+				assert.GreaterOrEqual(t, report.Slowest[index-1].Duration, report.Slowest[index].Duration)
+			}
+		})
+	}
+
+	t.Run("Callback errors", func(t *testing.T) {
+		errRejected := errors.New("rejected")
+		report, err := ProcessDir(context.Background(), root, BatchOptions{SlowestFiles: 2}, func(path string, card *RawCard) error {
+			return errRejected
+		})
+		require.NoError(t, err)
+		assert.Zero(t, report.Parsed)
+		assert.Equal(t, 4, report.Failed)
+		assert.Len(t, report.Slowest, 2)
+		var rejected int
+		for _, fileErr := range report.Errors {
+			if errors.Is(fileErr, errRejected) {
+				rejected++
+			}
+		}
+		assert.Equal(t, 3, rejected)
+	})
+
+	t.Run("Canceled context", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		report, err := ProcessDir(ctx, root, BatchOptions{}, func(path string, card *RawCard) error {
+			return nil
+		})
+		assert.ErrorIs(t, err, context.Canceled)
+		assert.Zero(t, report.Parsed)
+	})
+
+	t.Run("Missing root", func(t *testing.T) {
+		_, err := ProcessDir(context.Background(), filepath.Join(root, "missing"), BatchOptions{}, func(path string, card *RawCard) error {
+			return nil
+		})
+		assert.ErrorIs(t, err, os.ErrNotExist)
+	})
+}
@@ -0,0 +1,180 @@
+package png
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"slices"
+	"strings"
+
+	"github.com/r3dpixel/card-parser/character"
+	"github.com/sunshineplan/imgconv"
+)
+
+// DefaultCardSize is the width and height of the image generated by CardBuilder when no image is provided
+const DefaultCardSize = 512
+
+// Errors returned by CardBuilder.Build
+var (
+	ErrInvalidCardSize = errors.New("invalid card size")
+	ErrInvalidSheet    = errors.New("sheet fails the integrity check")
+)
+
+// CardBuilder builds chara PNG cards from scratch (see NewCardBuilder)
+type CardBuilder struct {
+	img        image.Image
+	imageData  []byte
+	sheet      *character.Sheet
+	background color.Color
+	width      int
+	height     int
+	strict     bool
+}
+
+// NewCardBuilder returns a card builder generating a black square image of DefaultCardSize, until an image is provided
+func NewCardBuilder() *CardBuilder {
+	return &CardBuilder{background: color.Black}
+}
+
+// WithImage sets the image of the card
+func (cb *CardBuilder) WithImage(img image.Image) *CardBuilder {
+	cb.img, cb.imageData = img, nil
+	return cb
+}
+
+// WithImageBytes sets the encoded image of the card: PNG images are kept as they are (their chara and text chunks
+// are dropped), any other format (e.g. JPEG, WebP) is converted to PNG as with FromBytes
+func (cb *CardBuilder) WithImageBytes(data []byte) *CardBuilder {
+	cb.img, cb.imageData = nil, data
+	return cb
+}
+
+// WithSheet sets the sheet stamped in the chara chunk (the chunk keyword follows the sheet revision)
+func (cb *CardBuilder) WithSheet(sheet *character.Sheet) *CardBuilder {
+	cb.sheet = sheet
+	return cb
+}
+
+// WithBackgroundColor sets the color of the generated image, and of the margins left when an image is fitted
+// into the size (black by default)
+func (cb *CardBuilder) WithBackgroundColor(c color.Color) *CardBuilder {
+	cb.background = c
+	return cb
+}
+
+// WithSize sets the size of the card image: the provided image is scaled to fit, centered on the background color
+// (without a size, the provided image is kept as is, and the generated image is a square of DefaultCardSize)
+func (cb *CardBuilder) WithSize(width int, height int) *CardBuilder {
+	cb.width, cb.height = width, height
+	return cb
+}
+
+// WithStrict fails the build with ErrInvalidSheet if the sheet does not pass the integrity check (see Content.Validate)
+func (cb *CardBuilder) WithStrict(enabled bool) *CardBuilder {
+	cb.strict = enabled
+	return cb
+}
+
+// Build encodes the image and returns the RawCard with the chara data of the sheet
+// Returns ErrNoSheet without a sheet, ErrInvalidCardSize for a non-positive size, and ErrInvalidSheet (listing the
+// errors reported by the validation) in strict mode
+func (cb *CardBuilder) Build() (*RawCard, error) {
+	// Check the sheet
+	if cb.sheet == nil {
+		return nil, ErrNoSheet
+	}
+	if cb.strict && !cb.sheet.Integrity() {
+		var messages []string
+		for _, issue := range slices.DeleteFunc(cb.sheet.Validate(), func(issue character.ValidationIssue) bool { return !issue.IsError() }) {
+			messages = append(messages, issue.String())
+		}
+		return nil, fmt.Errorf("%w: %s", ErrInvalidSheet, strings.Join(messages, "; "))
+	}
+
+	// Check the size
+	sized := cb.width != 0 || cb.height != 0
+	if sized && (cb.width <= 0 || cb.height <= 0) {
+		return nil, fmt.Errorf("%w: %dx%d", ErrInvalidCardSize, cb.width, cb.height)
+	}
+
+	// Encode the image
+	data, err := cb.pngData(sized)
+	if err != nil {
+		return nil, err
+	}
+
+	// Stamp the chara data of the sheet
+	return (&CharacterCard{pngData: data, Sheet: cb.sheet}).Encode()
+}
+
+// pngData returns the PNG data of the card image (fitted into the size if set)
+func (cb *CardBuilder) pngData(sized bool) (pngData, error) {
+	// Read the encoded image (kept as is without a size), and its text chunks
+	img := cb.img
+	var textChunks []TextChunk
+	if cb.imageData != nil {
+		rawCard, err := FromBytes(cb.imageData).Get()
+		if err != nil {
+			return pngData{}, err
+		}
+		if !sized {
+			return pngData{Header: rawCard.Header, Body: rawCard.Body, TextChunks: rawCard.TextChunks, scannedTextChunks: rawCard.scannedTextChunks, animated: rawCard.animated}, nil
+		}
+		if img, err = rawCard.Image(); err != nil {
+			return pngData{}, err
+		}
+		textChunks = rawCard.TextChunks
+	}
+
+	// Compose the image: the background, and the provided image fitted into the size
+	var canvas image.Image
+	switch {
+	case img == nil:
+		width, height := cb.width, cb.height
+		if !sized {
+			width, height = DefaultCardSize, DefaultCardSize
+		}
+		canvas = solidCanvas(width, height, cb.background)
+	case sized:
+		canvas = fitCanvas(img, cb.width, cb.height, cb.background)
+	default:
+		canvas = img
+	}
+
+	// Encode the image to PNG
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, canvas); err != nil {
+		return pngData{}, err
+	}
+	return pngData{Header: buf.Next(fullIhdrSize), Body: buf.Bytes(), TextChunks: textChunks}, nil
+}
+
+// solidCanvas returns an image of the given size filled with the background color
+func solidCanvas(width int, height int, background color.Color) *image.RGBA {
+	canvas := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.Draw(canvas, canvas.Bounds(), image.NewUniform(background), image.Point{}, draw.Src)
+	return canvas
+}
+
+// fitCanvas returns an image of the given size filled with the background color, with the image scaled to fit and
+// centered
+func fitCanvas(img image.Image, width int, height int, background color.Color) image.Image {
+	canvas := solidCanvas(width, height, background)
+
+	// Scale the image to fit (keeping the aspect ratio)
+	bounds := img.Bounds()
+	fittedWidth, fittedHeight := width, bounds.Dy()*width/bounds.Dx()
+	if fittedHeight > height {
+		fittedWidth, fittedHeight = bounds.Dx()*height/bounds.Dy(), height
+	}
+	scaled := imgconv.Resize(img, &imgconv.ResizeOption{Width: max(fittedWidth, 1), Height: max(fittedHeight, 1)})
+
+	// Center the scaled image
+	offset := image.Pt((width-scaled.Bounds().Dx())/2, (height-scaled.Bounds().Dy())/2)
+	draw.Draw(canvas, scaled.Bounds().Sub(scaled.Bounds().Min).Add(offset), scaled, scaled.Bounds().Min, draw.Over)
+	return canvas
+}
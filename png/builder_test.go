@@ -0,0 +1,140 @@
+package png
+
+import (
+	"image"
+	"image/color"
+	"os"
+	"slices"
+	"testing"
+
+	"github.com/r3dpixel/card-parser/character"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// builderSheet returns a valid V3 sheet
+func builderSheet() *character.Sheet {
+	sheet := createSheet(character.RevisionV3, "Alice")
+	sheet.Description = "A curious girl"
+	sheet.FirstMessage = "Hello!"
+	sheet.Title = "Alice"
+	sheet.Creator = "r3dpixel"
+	sheet.Nickname = "Ally"
+	sheet.SourceID = "alice"
+	sheet.CreationDate = 1700000000
+	sheet.ModificationDate = 1700000000
+	return sheet
+}
+
+// reparse reads the built card back, and returns its decoded card and image size
+func reparse(t *testing.T, rawCard *RawCard) (*CharacterCard, int, int) {
+	t.Helper()
+	data, err := rawCard.ToBytes()
+	require.NoError(t, err)
+	processor := FromBytes(data)
+	width, height := processor.ImageSize()
+	parsed, err := processor.Get()
+	require.NoError(t, err)
+	card, err := parsed.Decode()
+	require.NoError(t, err)
+	return card, width, height
+}
+
+func TestCardBuilder_Build(t *testing.T) {
+	webp, err := os.ReadFile("testdata/card.webp")
+	require.NoError(t, err)
+	webpWidth, webpHeight := FromBytes(webp).ImageSize()
+
+	source := image.NewRGBA(image.Rect(0, 0, 40, 20))
+	source.Set(0, 0, color.RGBA{R: 255, A: 255})
+
+	tests := []struct {
+		name           string
+		builder        *CardBuilder
+		expectedWidth  int
+		expectedHeight int
+	}{
+		{"Image", NewCardBuilder().WithImage(source), 40, 20},
+		{"Image fitted", NewCardBuilder().WithImage(source).WithSize(64, 64), 64, 64},
+		{"Generated", NewCardBuilder(), DefaultCardSize, DefaultCardSize},
+		{"Generated sized", NewCardBuilder().WithSize(30, 10).WithBackgroundColor(color.White), 30, 10},
+		{"PNG bytes", NewCardBuilder().WithImageBytes(createTestPNG(t, 12, 8)), 12, 8},
+		{"WebP bytes", NewCardBuilder().WithImageBytes(webp), webpWidth, webpHeight},
+		{"JPEG bytes fitted", NewCardBuilder().WithImageBytes(createTestJPG(t)).WithSize(16, 8), 16, 8},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rawCard, err := tt.builder.WithSheet(builderSheet()).WithStrict(true).Build()
+			require.NoError(t, err)
+			assert.Equal(t, character.RevisionV3, rawCard.Revision)
+
+			card, width, height := reparse(t, rawCard)
+			assert.Equal(t, tt.expectedWidth, width)
+			assert.Equal(t, tt.expectedHeight, height)
+			assert.True(t, builderSheet().DeepEquals(card.Sheet))
+		})
+	}
+}
+
+func TestCardBuilder_TextChunks(t *testing.T) {
+	pngBytes := slices.Concat(
+		createTestPNG(t, 12, 8)[:fullIhdrSize],
+		textChunk([]byte("Software\x00Stable Diffusion")),
+		createTestPNG(t, 12, 8)[fullIhdrSize:],
+	)
+
+	tests := []struct {
+		name    string
+		builder *CardBuilder
+	}{
+		{"Kept as is", NewCardBuilder().WithImageBytes(pngBytes)},
+		{"Fitted", NewCardBuilder().WithImageBytes(pngBytes).WithSize(24, 16)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rawCard, err := tt.builder.WithSheet(builderSheet()).Build()
+			require.NoError(t, err)
+
+			card, _, _ := reparse(t, rawCard)
+			assert.Equal(t, []TextChunk{{Keyword: "Software", Text: "Stable Diffusion"}}, card.TextChunks)
+		})
+	}
+}
+
+func TestCardBuilder_Pixels(t *testing.T) {
+	source := image.NewRGBA(image.Rect(0, 0, 10, 10))
+	for x := range 10 {
+		for y := range 10 {
+			source.Set(x, y, color.RGBA{R: 255, A: 255})
+		}
+	}
+
+	// The image is centered on the background color
+	rawCard, err := NewCardBuilder().WithImage(source).WithSize(30, 10).WithBackgroundColor(color.White).WithSheet(builderSheet()).Build()
+	require.NoError(t, err)
+	img, err := rawCard.Image()
+	require.NoError(t, err)
+	assert.Equal(t, color.RGBAModel.Convert(color.White), color.RGBAModel.Convert(img.At(0, 5)))
+	assert.Equal(t, color.RGBA{R: 255, A: 255}, color.RGBAModel.Convert(img.At(15, 5)))
+	assert.Equal(t, color.RGBAModel.Convert(color.White), color.RGBAModel.Convert(img.At(29, 5)))
+}
+
+func TestCardBuilder_Errors(t *testing.T) {
+	_, err := NewCardBuilder().Build()
+	assert.ErrorIs(t, err, ErrNoSheet)
+
+	_, err = NewCardBuilder().WithSheet(builderSheet()).WithSize(0, 10).Build()
+	assert.ErrorIs(t, err, ErrInvalidCardSize)
+
+	// The integrity check is only enforced in strict mode
+	invalid := createSheet(character.RevisionV2, "")
+	_, err = NewCardBuilder().WithSheet(invalid).Build()
+	require.NoError(t, err)
+	_, err = NewCardBuilder().WithSheet(invalid).WithStrict(true).Build()
+	assert.ErrorIs(t, err, ErrInvalidSheet)
+
+	_, err = NewCardBuilder().WithSheet(builderSheet()).WithImageBytes([]byte("not an image")).Build()
+	assert.Error(t, err)
+}
@@ -4,21 +4,49 @@ import (
 	"bytes"
 	"encoding/base64"
 	"encoding/binary"
+	"errors"
+	"fmt"
 	"hash/crc32"
-	"image"
-	"image/png"
 	"io"
 	"os"
+	"slices"
 
 	"github.com/r3dpixel/card-parser/character"
 	"github.com/r3dpixel/toolkit/filex"
 )
 
+// ErrNoSheet is returned when chara data is requested from a CharacterCard without a sheet
+var ErrNoSheet = errors.New("character card has no sheet")
+
 // RawCard encoded chara PNG card
 type RawCard struct {
 	pngData
 	RawCharaData []byte
 	Revision     character.Revision
+	// Encoding base64 variant of the chara data, detected when the card is read (non-standard chara data is normalized
+	// to the standard padded form when written by ToImage)
+	Encoding Base64Encoding
+	// ChunkSpans positions of the chara chunks in the input, in file order (only reported with TrackOffsets)
+	ChunkSpans []ChunkSpan
+	// SkippedPrefix number of junk bytes skipped before the PNG signature (see SourceOptions.MaxSignatureOffset); the ChunkSpans
+	// offsets are relative to the signature
+	SkippedPrefix int
+	// TrailingData bytes found after the IEND chunk (never written back, only kept with KeepTrailingData)
+	TrailingData []byte
+	// WasConverted is set when the input was not a PNG image, and was converted to PNG (see Processor.SourceFormat)
+	WasConverted bool
+	// OriginalFormat format of the input converted to PNG (e.g. jpeg, webp), only set with KeepOriginal
+	OriginalFormat string
+	// OriginalBytes input converted to PNG, written back by ToOriginal (only set with KeepOriginal, shared between cards)
+	OriginalBytes []byte
+	// originalCharaData chara data found in the metadata of the original input (compared by ToOriginal)
+	originalCharaData []byte
+}
+
+// charaPayload chara data written for a revision (with the keyword of the revision)
+type charaPayload struct {
+	revision  character.Revision
+	charaData []byte
 }
 
 // RawJsonCard encoded chara PNG card with JSON data
@@ -32,24 +60,62 @@ type RawJsonCard struct {
 type CharacterCard struct {
 	pngData
 	*character.Sheet
+	// RawJSON original JSON the sheet was decoded from (set by ToCharacter and Decode, nil for cards built from sheets)
+	// It shares its memory with RawJsonCard.RawJsonData and must not be modified; see Fingerprint and EncodePreservingRaw
+	RawJSON []byte
+}
+
+// ChunkFormat sets the format of the chara chunks written by ToImage (defaults to TEXT, or the format it was read from)
+func (rc *RawCard) ChunkFormat(format ChunkFormat) *RawCard {
+	rc.chunkFormat = format
+	return rc
 }
 
-// PlaceholderCharacterCard returns a placeholder character card of the given size (black PNG image)
-func PlaceholderCharacterCard(size int) (*RawCard, error) {
-	// Create a new black image
-	img := image.NewGray(image.Rect(0, 0, size, size))
+// ChunkFormat sets the format of the chara chunks written by ToImage (see RawCard.ChunkFormat)
+func (cc *CharacterCard) ChunkFormat(format ChunkFormat) *CharacterCard {
+	cc.chunkFormat = format
+	return cc
+}
 
-	// Encode to PNG bytes
-	var buf bytes.Buffer
-	if err := png.Encode(&buf, img); err != nil {
+// Resized returns a copy of the RawCard with the image scaled down to fit a square of the given size
+// The chara data and revision are carried over, so ToImage still produces a valid (smaller) card
+// Animated images fail with ErrAnimatedResizeUnsupported, unless ResizeOptions.Animated is set
+func (rc *RawCard) Resized(size int, opts ...ResizeOptions) (*RawCard, error) {
+	// Scale down the image
+	scaled, err := rc.scaled(size, resizeOptions(opts))
+	if err != nil {
 		return nil, err
 	}
 
-	// Return the RawCard
-	return FromImage(io.NopCloser(&buf)).First().Get()
+	// Return the resized RawCard
+	return &RawCard{
+		pngData:      scaled,
+		RawCharaData: slices.Clone(rc.RawCharaData),
+		Revision:     rc.Revision,
+		Encoding:     rc.Encoding,
+	}, nil
+}
+
+// Resized returns a copy of the CharacterCard with the image scaled down to fit a square of the given size
+// The sheet is carried over (shared with the original card); animated images are handled as in RawCard.Resized
+func (cc *CharacterCard) Resized(size int, opts ...ResizeOptions) (*CharacterCard, error) {
+	// Scale down the image
+	scaled, err := cc.scaled(size, resizeOptions(opts))
+	if err != nil {
+		return nil, err
+	}
+
+	// Return the resized CharacterCard
+	return &CharacterCard{
+		pngData: scaled,
+		Sheet:   cc.Sheet,
+		RawJSON: cc.RawJSON,
+	}, nil
 }
 
 // ToRawJson converts a RawCard to a RawJsonCard by decoding the base64 data
+// Standard, unpadded and URL-safe base64 are accepted (ignoring embedded whitespace), the card is not modified
+// Chara data that is not valid base64 fails with ErrInvalidBase64
 func (rc *RawCard) ToRawJson() (*RawJsonCard, error) {
 	// Create a new RawJsonCard
 	rawJsonCard := &RawJsonCard{
@@ -62,22 +128,23 @@ func (rc *RawCard) ToRawJson() (*RawJsonCard, error) {
 		return rawJsonCard, nil
 	}
 
-	// Decode chara data from base64
-	decodedJSON := make([]byte, base64.StdEncoding.DecodedLen(len(rc.RawCharaData)))
-	n, err := base64.StdEncoding.Decode(decodedJSON, rc.RawCharaData)
+	// Decode chara data from base64 (any variant)
+	decodedJSON, _, err := decodeBase64(rc.RawCharaData)
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("%w: %w", ErrInvalidBase64, err)
 	}
 
 	// Set the JSON data in the RawJsonCard
-	rawJsonCard.RawJsonData = decodedJSON[:n]
+	rawJsonCard.RawJsonData = decodedJSON
 
 	// Return the RawJsonCard
 	return rawJsonCard, nil
 }
 
-// ToCharacter converts a RawJsonCard to a CharacterCard by parsing the JSON data
-func (rjc *RawJsonCard) ToCharacter() (*CharacterCard, error) {
+// ToCharacter converts a RawJsonCard to a CharacterCard by parsing the JSON data (an InvalidCharacterJSONError is returned
+// if the JSON is not a valid sheet); the JSON data is kept as the RawJSON of the card
+// The decode options (e.g. RejectLegacyCards) are passed to character.FromBytesWithOptions
+func (rjc *RawJsonCard) ToCharacter(opts ...character.DecodeOptions) (*CharacterCard, error) {
 	// Create a new CharacterCard
 	characterCard := &CharacterCard{
 		pngData: rjc.pngData,
@@ -90,24 +157,53 @@ func (rjc *RawJsonCard) ToCharacter() (*CharacterCard, error) {
 	}
 
 	// Decode chara data from JSON into a Sheet
-	sheet, err := character.FromBytes(rjc.RawJsonData)
+	decodeOptions := character.DecodeOptions{}
+	if len(opts) > 0 {
+		decodeOptions = opts[0]
+	}
+	sheet, err := character.FromBytesWithOptions(rjc.RawJsonData, decodeOptions)
 	if err != nil {
-		return nil, err
+		return nil, &InvalidCharacterJSONError{Cause: err}
 	}
 
-	// Set the correct spec/version
-	stamp := character.Stamps[rjc.Revision]
-	sheet.Revision = rjc.Revision
-	sheet.Spec = stamp.Spec
-	sheet.Version = stamp.Version
+	// Set the sheet (with the correct spec/version) in the CharacterCard, and keep the original JSON
+	characterCard = newCharacterCard(rjc.pngData, sheet, rjc.Revision)
+	characterCard.RawJSON = rjc.RawJsonData
+	return characterCard, nil
+}
+
+// ToCharacterLazy converts a RawJsonCard to a CharacterCard like ToCharacter, except for the lorebook: it is only
+// decoded on the first call to Content.Book, and written back as is until then (see character.FromBytesLazy)
+func (rjc *RawJsonCard) ToCharacterLazy() (*CharacterCard, error) {
+	// If there is no JSON data, return a default sheet
+	if len(rjc.RawJsonData) == 0 {
+		return &CharacterCard{pngData: rjc.pngData, Sheet: character.DefaultSheet(character.RevisionV2)}, nil
+	}
 
-	// Set the sheet in the CharacterCard
-	characterCard.Sheet = sheet
+	// Decode chara data from JSON into a Sheet (without the lorebook)
+	sheet, err := character.FromBytesLazy(rjc.RawJsonData)
+	if err != nil {
+		return nil, &InvalidCharacterJSONError{Cause: err}
+	}
 
-	// Return the CharacterCard
+	// Set the sheet (with the correct spec/version) in the CharacterCard, and keep the original JSON
+	characterCard := newCharacterCard(rjc.pngData, sheet, rjc.Revision)
+	characterCard.RawJSON = rjc.RawJsonData
 	return characterCard, nil
 }
 
+// newCharacterCard returns the CharacterCard of the sheet, stamped with the spec/version of the revision
+// Sheets sharing the chunk keyword of the revision keep their own revision and version (e.g. 3.1 in a ccv3 chunk)
+func newCharacterCard(data pngData, sheet *character.Sheet, revision character.Revision) *CharacterCard {
+	// Set the correct spec/version
+	if keywordRevision(sheet.Revision) != keywordRevision(revision) {
+		stampSheet(sheet, revision)
+	}
+
+	// Return the CharacterCard
+	return &CharacterCard{pngData: data, Sheet: sheet}
+}
+
 // ToRawJson converts a CharacterCard to a RawJsonCard by serializing the Sheet to JSON
 func (cc *CharacterCard) ToRawJson() (*RawJsonCard, error) {
 	rawJsonCard := &RawJsonCard{
@@ -154,15 +250,15 @@ func (rjc *RawJsonCard) ToRaw() *RawCard {
 	return rawCard
 }
 
-// Decode converts a RawCard to a CharacterCard by decoding the base64 character data
-func (rc *RawCard) Decode() (*CharacterCard, error) {
+// Decode converts a RawCard to a CharacterCard by decoding the base64 character data (see ToCharacter for the options)
+func (rc *RawCard) Decode(opts ...character.DecodeOptions) (*CharacterCard, error) {
 	// Decode the character data from base64
 	rjc, err := rc.ToRawJson()
 	if err != nil {
 		return nil, err
 	}
 	// Decode the JSON data into a Sheet
-	return rjc.ToCharacter()
+	return rjc.ToCharacter(opts...)
 }
 
 // Encode converts a CharacterCard to a RawCard by encoding the character data as base64
@@ -177,26 +273,54 @@ func (cc *CharacterCard) Encode() (*RawCard, error) {
 }
 
 // ToImage writes the RawCard as a PNG image to the provided writer
-func (rc *RawCard) ToImage(w io.Writer) error {
-	// Write the header of the image first
+// A chara chunk is written for each of the given revisions (in order, e.g. RevisionV2, RevisionV3 for maximum
+// compatibility), defaulting to the card revision; the spec/spec_version of each chunk match its keyword
+// The chunks are written at the position of the injection policy (right after the header by default)
+func (rc *RawCard) ToImage(w io.Writer, revisions ...character.Revision) error {
+	// Write the header of the image, and the body up to the injection position
+	before, after := rc.bodyParts(rc.injectionOffset())
 	if _, err := w.Write(rc.Header); err != nil {
 		return err
 	}
+	if _, err := w.Write(before); err != nil {
+		return err
+	}
 
-	// Write the chara chunk
-	if err := rc.streamCharaChunk(w, rc.Revision); err != nil {
+	// Write the chara chunks, and the non-chara text chunks if they were changed
+	if err := rc.streamCharaChunks(w, revisions...); err != nil {
+		return err
+	}
+	if err := rc.writeTextChunks(w); err != nil {
 		return err
 	}
 
-	// Write the image body
-	_, err := w.Write(rc.Body)
+	// Write the rest of the image body
+	_, err := w.Write(after)
 
 	// Return
 	return err
 }
 
-// ToFile saves the RawCard as a PNG image file at the specified path
-func (rc *RawCard) ToFile(path string) error {
+// streamCharaChunks writes a chara chunk for each of the given revisions (defaulting to the card revision)
+func (rc *RawCard) streamCharaChunks(w io.Writer, revisions ...character.Revision) error {
+	// Stamp the chara data with each chunk revision
+	payloads, err := rc.charaPayloads(revisions...)
+	if err != nil {
+		return err
+	}
+
+	// Write a chara chunk for each revision
+	for _, payload := range payloads {
+		if err := streamCharaChunk(w, payload.revision, payload.charaData, rc.chunkFormat); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// ToFile saves the RawCard as a PNG image file at the specified path (see ToImage for the revisions)
+func (rc *RawCard) ToFile(path string, revisions ...character.Revision) error {
 	// Open a file io.Writer
 	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, filex.FilePermission)
 	if err != nil {
@@ -206,37 +330,185 @@ func (rc *RawCard) ToFile(path string) error {
 	defer file.Close()
 
 	// Write the image to the file
-	return rc.ToImage(file)
+	return rc.ToImage(file, revisions...)
+}
+
+// ToBytes returns the RawCard as a PNG image byte slice (see ToImage for the revisions)
+func (rc *RawCard) ToBytes(revisions ...character.Revision) ([]byte, error) {
+	// Create a byte buffer
+	buf := new(bytes.Buffer)
+	// Write the image to the byte buffer
+	if err := rc.ToImage(buf, revisions...); err != nil {
+		return nil, err
+	}
+	// Return the byte slice
+	return buf.Bytes(), nil
+}
+
+// ToImage encodes the sheet and writes the CharacterCard as a PNG image to the provided writer
+// The chunk keyword is picked from the sheet revision, unless revisions are given (see RawCard.ToImage)
+// Requesting revisions for a card without a sheet returns ErrNoSheet (without revisions only the image is written)
+func (cc *CharacterCard) ToImage(w io.Writer, revisions ...character.Revision) error {
+	// Chara data was explicitly requested, but there is no sheet
+	if cc.Sheet == nil && len(revisions) > 0 {
+		return ErrNoSheet
+	}
+
+	// Encode the sheet into a RawCard
+	rawCard, err := cc.Encode()
+	if err != nil {
+		return err
+	}
+
+	// Write the RawCard
+	return rawCard.ToImage(w, revisions...)
 }
 
-// ToBytes returns the RawCard as a PNG image byte slice
-func (rc *RawCard) ToBytes() ([]byte, error) {
+// ToFile encodes the sheet and saves the CharacterCard as a PNG image file at the specified path (see ToImage)
+// The sheet JSON can still be saved with Sheet.ToFile
+func (cc *CharacterCard) ToFile(path string, revisions ...character.Revision) error {
+	// Chara data was explicitly requested, but there is no sheet
+	if cc.Sheet == nil && len(revisions) > 0 {
+		return ErrNoSheet
+	}
+
+	// Encode the sheet into a RawCard
+	rawCard, err := cc.Encode()
+	if err != nil {
+		return err
+	}
+
+	// Save the RawCard
+	return rawCard.ToFile(path, revisions...)
+}
+
+// ToBytes encodes the sheet and returns the CharacterCard as a PNG image byte slice (see ToImage)
+// The sheet JSON can still be obtained with Sheet.ToBytes
+func (cc *CharacterCard) ToBytes(revisions ...character.Revision) ([]byte, error) {
 	// Create a byte buffer
 	buf := new(bytes.Buffer)
 	// Write the image to the byte buffer
-	if err := rc.ToImage(buf); err != nil {
+	if err := cc.ToImage(buf, revisions...); err != nil {
 		return nil, err
 	}
 	// Return the byte slice
 	return buf.Bytes(), nil
 }
 
-// streamCharaChunk writes the character data chunk to the PNG stream
-func (rc *RawCard) streamCharaChunk(w io.Writer, revision character.Revision) error {
+// charaPayloads returns the chara data stamped with each of the given revisions (defaulting to the card revision)
+// Revisions sharing a keyword are written once, and there is no payload if there is no chara data
+func (rc *RawCard) charaPayloads(revisions ...character.Revision) ([]charaPayload, error) {
+	// Default to the card revision
+	if len(revisions) == 0 {
+		revisions = []character.Revision{rc.Revision}
+	}
+
+	// Stamp the chara data for each revision (skipping duplicates)
+	var payloads []charaPayload
+	written := make(map[character.Revision]bool, len(revisions))
+	for _, revision := range revisions {
+		revision = keywordRevision(revision)
+		if written[revision] {
+			continue
+		}
+		written[revision] = true
+
+		// Stamp the chara data with the revision
+		charaData, err := rc.charaDataFor(revision)
+		if err != nil {
+			return nil, err
+		}
+		if len(charaData) > 0 {
+			payloads = append(payloads, charaPayload{revision: revision, charaData: charaData})
+		}
+	}
+	return payloads, nil
+}
+
+// charaDataFor returns the chara data stamped with the spec/spec_version of the given revision
+// Chara data of another revision is decoded and re-encoded at the revision (unknown keys of the content are dropped)
+func (rc *RawCard) charaDataFor(revision character.Revision) ([]byte, error) {
+	// The chara data already matches the revision in standard base64 (or there is no chara data)
+	matches := revision == keywordRevision(rc.Revision)
+	if len(rc.RawCharaData) == 0 || matches && isStdBase64(rc.RawCharaData) {
+		return rc.RawCharaData, nil
+	}
+
+	// Decode the JSON data (undecodable chara data matching the revision is written as is)
+	rjc, err := rc.ToRawJson()
+	if err != nil && matches {
+		return rc.RawCharaData, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// Normalize the chara data to standard base64
+	if matches {
+		return rjc.ToRaw().RawCharaData, nil
+	}
+
+	// Re-marshal the sheet at the revision (V2 chunks lose the V3 only fields, see character.Sheet.PruneForRevision)
+	sheet, err := character.FromBytes(rjc.RawJsonData)
+	if err != nil {
+		return nil, err
+	}
+	stampSheet(sheet, revision)
+	if err := sheet.PruneForRevision(character.PruneOptions{}); err != nil {
+		return nil, err
+	}
+	if rjc.RawJsonData, err = sheet.ToBytes(); err != nil {
+		return nil, err
+	}
+
+	// Encode the stamped JSON data
+	return rjc.ToRaw().RawCharaData, nil
+}
+
+// streamCharaChunk writes the chara data chunk (with the keyword of the revision, in the given format) to the PNG stream
+func streamCharaChunk(w io.Writer, revision character.Revision, charaData []byte, format ChunkFormat) error {
 	// If there is no chara data return empty byte slice
-	if len(rc.RawCharaData) == 0 {
+	if len(charaData) == 0 {
 		return nil
 	}
 
 	// Write the correct chara keyword (fallback to V2)
-	keyword := keywords[revision]
-	if keyword == nil {
-		keyword = keywords[character.RevisionV2]
+	return streamTextChunk(w, chunkKeywords()[keywordRevision(revision)], charaData, format)
+}
+
+// streamTextChunk writes a text chunk (keyword with its null separator, and the text in the given format) to the PNG stream
+func streamTextChunk(w io.Writer, keyword []byte, text []byte, format ChunkFormat) error {
+	// Encode the text in the chunk format
+	parts, err := format.encodeText(text)
+	if err != nil {
+		return err
+	}
+
+	// Compute the correct PNG chunk length
+	chunkDataLen := uint32(len(keyword))
+	for _, part := range parts {
+		chunkDataLen += uint32(len(part))
 	}
 
-	// Write the correct PNG chunk length
-	chunkDataLen := uint32(len(keyword) + len(rc.RawCharaData))
-	if err := binary.Write(w, binary.BigEndian, chunkDataLen); err != nil {
+	// Write the keyword and the encoded text
+	return streamChunk(w, format.typeCode(), chunkDataLen, func(cw io.Writer) error {
+		if _, err := cw.Write(keyword); err != nil {
+			return err
+		}
+		for _, part := range parts {
+			if _, err := cw.Write(part); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// streamChunk writes a PNG chunk (length, type, data written by the given function, crc) to the PNG stream
+// The function must write exactly the given number of bytes
+func streamChunk(w io.Writer, typeCode uint32, length uint32, writeData func(cw io.Writer) error) error {
+	// Write the PNG chunk length
+	if err := binary.Write(w, binary.BigEndian, length); err != nil {
 		return err
 	}
 
@@ -245,21 +517,28 @@ func (rc *RawCard) streamCharaChunk(w io.Writer, revision character.Revision) er
 	// Stream the writings to the output, as well as to the crc hasher
 	multiWriter := io.MultiWriter(w, crcHasher)
 
-	// Write the PNG chunk `tEXt` type
-	if err := binary.Write(multiWriter, binary.BigEndian, chunkTextTypeCode); err != nil {
+	// Write the PNG chunk type (`tEXt`, `zTXt` or `iTXt`)
+	if err := binary.Write(multiWriter, binary.BigEndian, typeCode); err != nil {
 		return err
 	}
 
-	// Write the chara keyword
-	if _, err := multiWriter.Write(keyword); err != nil {
-		return err
-	}
-
-	// Write the chara data
-	if _, err := multiWriter.Write(rc.RawCharaData); err != nil {
+	// Write the chunk data
+	if err := writeData(multiWriter); err != nil {
 		return err
 	}
 
 	// Write the crc hash
 	return binary.Write(w, binary.BigEndian, crcHasher.Sum32())
 }
+
+// keywordRevision returns the revision if it has a chara keyword, otherwise it falls back to V2
+// Every 3.x revision shares the ccv3 keyword (RevisionV3)
+func keywordRevision(revision character.Revision) character.Revision {
+	if revision.Major() == character.RevisionV3.Major() {
+		return character.RevisionV3
+	}
+	if chunkKeywords()[revision] == nil {
+		return character.RevisionV2
+	}
+	return revision
+}
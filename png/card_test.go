@@ -1,9 +1,13 @@
 package png
 
 import (
+	"bytes"
 	"encoding/base64"
+	"encoding/json"
+	"io"
 	"os"
 	"path/filepath"
+	"sync"
 	"testing"
 
 	"github.com/r3dpixel/card-parser/character"
@@ -229,6 +233,56 @@ func TestCard_EncodeDecode(t *testing.T) {
 		assert.Equal(t, character.V2, decodedCard.Sheet.Version)
 	})
 
+	t.Run("unknown top-level members survive the PNG round trip", func(t *testing.T) {
+		sheetJSON := `{"spec":"chara_card_v3","spec_version":"3.0","metadata":{"tool":"exporter"},"data":{"name":"Top Level"}}`
+		cardPNG := injectChunk(t, pngBytes, character.RevisionV3, []byte(base64.StdEncoding.EncodeToString([]byte(sheetJSON))), false)
+
+		// Decode, modify and re-encode the card
+		card, err := FromBytes(cardPNG).Get()
+		require.NoError(t, err)
+		decoded, err := card.Decode()
+		require.NoError(t, err)
+		decoded.Name = "Renamed"
+		encoded, err := decoded.ToBytes()
+		require.NoError(t, err)
+
+		// Decode the re-encoded card
+		card, err = FromBytes(encoded).Get()
+		require.NoError(t, err)
+		decoded, err = card.Decode()
+		require.NoError(t, err)
+		assert.Equal(t, "Renamed", string(decoded.Name))
+		assert.JSONEq(t, `{"tool":"exporter"}`, string(decoded.RawTopLevel["metadata"]))
+	})
+
+	t.Run("spec versions 3.x survive the PNG round trip", func(t *testing.T) {
+		for _, version := range []character.Version{character.V3_1, "3.2"} {
+			sheetJSON := `{"spec":"chara_card_v3","spec_version":"` + string(version) + `","data":{"name":"Minor"}}`
+			cardPNG := injectChunk(t, pngBytes, character.RevisionV3, []byte(base64.StdEncoding.EncodeToString([]byte(sheetJSON))), false)
+
+			// Decode, modify and re-encode the card
+			card, err := FromBytes(cardPNG).Get()
+			require.NoError(t, err)
+			decoded, err := card.Decode()
+			require.NoError(t, err)
+			assert.Equal(t, character.RevisionV3_1, decoded.Revision)
+			assert.Equal(t, version, decoded.Version)
+			decoded.Name = "Renamed"
+			encoded, err := decoded.ToBytes()
+			require.NoError(t, err)
+
+			// The chunk keyword stays ccv3, and the version is intact
+			card, err = FromBytes(encoded).Get()
+			require.NoError(t, err)
+			assert.Equal(t, character.RevisionV3, card.Revision)
+			decoded, err = card.Decode()
+			require.NoError(t, err)
+			assert.Equal(t, "Renamed", string(decoded.Name))
+			assert.Equal(t, character.RevisionV3_1, decoded.Revision)
+			assert.Equal(t, version, decoded.Version)
+		}
+	})
+
 	t.Run("decode with invalid base64 data", func(t *testing.T) {
 		rawCard, err := FromBytes(pngBytes).Get()
 		require.NoError(t, err)
@@ -308,3 +362,318 @@ func TestRawCard_ToPngBytes_And_ToFile(t *testing.T) {
 		assert.Equal(t, character.RevisionV2, reparsedCard.Revision)
 	})
 }
+
+func TestRawCard_ToImage_Revisions(t *testing.T) {
+	pngBytes := createTestPNG(t, 4, 4)
+	rawCard, err := FromBytes(pngBytes).Get()
+	require.NoError(t, err)
+	cardModel := createTestCard(t, character.RevisionV3, "V3 Sheet")
+	cardModel.Nickname = "Nick"
+	cardModel.GroupGreetings = property.StringArray{"Hello everyone"}
+	rawCard.RawCharaData = encodeCardData(t, cardModel)
+	rawCard.Revision = character.RevisionV3
+
+	dualBytes, err := rawCard.ToBytes(character.RevisionV2, character.RevisionV3)
+	require.NoError(t, err)
+
+	t.Run("Both chunks are written in order", func(t *testing.T) {
+		rawCards, err := FromBytes(dualBytes).GetAll()
+		require.NoError(t, err)
+		require.Len(t, rawCards, 2)
+		assert.Equal(t, character.RevisionV2, rawCards[0].Revision)
+		assert.Equal(t, character.RevisionV3, rawCards[1].Revision)
+		assert.Equal(t, rawCard.RawCharaData, rawCards[1].RawCharaData)
+	})
+
+	t.Run("V2 chunk is downgraded", func(t *testing.T) {
+		rawCards, err := FromBytes(dualBytes).GetAll()
+		require.NoError(t, err)
+		require.NotEmpty(t, rawCards)
+
+		rawJsonCard, err := rawCards[0].ToRawJson()
+		require.NoError(t, err)
+		var stamp struct {
+			Spec    character.Spec    `json:"spec"`
+			Version character.Version `json:"spec_version"`
+		}
+		require.NoError(t, json.Unmarshal(rawJsonCard.RawJsonData, &stamp))
+		assert.Equal(t, character.SpecV2, stamp.Spec)
+		assert.Equal(t, character.V2, stamp.Version)
+		assert.NotContains(t, string(rawJsonCard.RawJsonData), `"nickname"`)
+		assert.NotContains(t, string(rawJsonCard.RawJsonData), `"group_only_greetings"`)
+
+		card, err := rawCards[0].Decode()
+		require.NoError(t, err)
+		assert.Equal(t, cardModel.Name, card.Name)
+		assert.Equal(t, cardModel.Description, card.Description)
+	})
+
+	scanModes := []struct {
+		name     string
+		scanMode ScanMode
+		revision character.Revision
+	}{
+		{"First", First, character.RevisionV2},
+		{"LastVersion", LastVersion, character.RevisionV3},
+	}
+	for _, tc := range scanModes {
+		t.Run("Round trip with "+tc.name, func(t *testing.T) {
+			reparsedCard, err := FromBytes(dualBytes).ScanMode(tc.scanMode).Get()
+			require.NoError(t, err)
+			assert.Equal(t, tc.revision, reparsedCard.Revision)
+
+			card, err := reparsedCard.Decode()
+			require.NoError(t, err)
+			assert.Equal(t, tc.revision, card.Revision)
+			assert.Equal(t, character.Stamps[tc.revision].Spec, card.Spec)
+			assert.Equal(t, cardModel.Name, card.Name)
+		})
+	}
+
+	t.Run("Duplicate revisions are written once", func(t *testing.T) {
+		finalBytes, err := rawCard.ToBytes(character.RevisionV3, character.RevisionV3)
+		require.NoError(t, err)
+		rawCards, err := FromBytes(finalBytes).GetAll()
+		require.NoError(t, err)
+		assert.Len(t, rawCards, 1)
+	})
+
+	t.Run("Invalid chara data", func(t *testing.T) {
+		invalid := &RawCard{pngData: rawCard.pngData, RawCharaData: []byte("!!!"), Revision: character.RevisionV3}
+		_, err := invalid.ToBytes(character.RevisionV2)
+		assert.Error(t, err)
+
+		_, err = invalid.ToBytes()
+		assert.NoError(t, err)
+	})
+}
+
+func TestCharacterCard_ToFile_And_ToBytes(t *testing.T) {
+	tempDir := t.TempDir()
+	sourcePath := filepath.Join(tempDir, "source.png")
+	basePNG := createTestPNG(t, 4, 4)
+	require.NoError(t, os.WriteFile(sourcePath, injectSingleChunk(t, basePNG, createTestCard(t, character.RevisionV3, "Original"), false), 0o644))
+
+	loadCard := func(t *testing.T) *CharacterCard {
+		t.Helper()
+		rawCard, err := FromFile(sourcePath).Get()
+		require.NoError(t, err)
+		card, err := rawCard.Decode()
+		require.NoError(t, err)
+		return card
+	}
+
+	t.Run("ToFile round trip", func(t *testing.T) {
+		card := loadCard(t)
+		card.Content.Name = "Edited"
+		targetPath := filepath.Join(tempDir, "edited.png")
+		require.NoError(t, card.ToFile(targetPath))
+
+		rawCard, err := FromFile(targetPath).Get()
+		require.NoError(t, err)
+		assert.Equal(t, character.RevisionV3, rawCard.Revision)
+		reparsed, err := rawCard.Decode()
+		require.NoError(t, err)
+		assert.Equal(t, property.String("Edited"), reparsed.Content.Name)
+	})
+
+	t.Run("ToBytes uses the sheet revision", func(t *testing.T) {
+		card := loadCard(t)
+		card.Content.Name = "Downgraded"
+		card.SetRevision(character.RevisionV2)
+		data, err := card.ToBytes()
+		require.NoError(t, err)
+
+		rawCard, err := FromBytes(data).LastVersion().Get()
+		require.NoError(t, err)
+		assert.Equal(t, character.RevisionV2, rawCard.Revision)
+		reparsed, err := rawCard.Decode()
+		require.NoError(t, err)
+		assert.Equal(t, property.String("Downgraded"), reparsed.Content.Name)
+	})
+
+	t.Run("ToBytes with revisions", func(t *testing.T) {
+		data, err := loadCard(t).ToBytes(character.RevisionV2, character.RevisionV3)
+		require.NoError(t, err)
+		rawCards, err := FromBytes(data).GetAll()
+		require.NoError(t, err)
+		assert.Len(t, rawCards, 2)
+	})
+
+	t.Run("Nil sheet", func(t *testing.T) {
+		card := loadCard(t)
+		card.Sheet = nil
+
+		data, err := card.ToBytes()
+		require.NoError(t, err)
+		rawCard, err := FromBytes(data).Get()
+		require.NoError(t, err)
+		assert.Empty(t, rawCard.RawCharaData)
+
+		_, err = card.ToBytes(character.RevisionV3)
+		assert.ErrorIs(t, err, ErrNoSheet)
+		err = card.ToFile(filepath.Join(tempDir, "nil.png"), character.RevisionV2)
+		assert.ErrorIs(t, err, ErrNoSheet)
+	})
+}
+
+func TestCard_Resized(t *testing.T) {
+	pngBytes := injectSingleChunk(t, createTestPNG(t, 64, 32), testCards.largeV3, false)
+	rawCard, err := FromBytes(pngBytes).Get()
+	require.NoError(t, err)
+
+	t.Run("RawCard", func(t *testing.T) {
+		resized, err := rawCard.Resized(16)
+		require.NoError(t, err)
+		assert.Equal(t, rawCard.RawCharaData, resized.RawCharaData)
+		assert.Equal(t, rawCard.Revision, resized.Revision)
+
+		// The original card is untouched
+		assert.Equal(t, 64, rawCard.Width())
+
+		// The resized card parses, with the expected dimensions and the same sheet
+		resizedBytes, err := resized.ToBytes()
+		require.NoError(t, err)
+		processor := FromBytes(resizedBytes)
+		width, height := processor.ImageSize()
+		assert.Equal(t, 16, width)
+		assert.Equal(t, 8, height)
+
+		reparsed, err := processor.Get()
+		require.NoError(t, err)
+		assert.Equal(t, character.RevisionV3, reparsed.Revision)
+		characterCard, err := reparsed.Decode()
+		require.NoError(t, err)
+		assert.Equal(t, testCards.largeV3.Name, characterCard.Name)
+	})
+
+	t.Run("CharacterCard", func(t *testing.T) {
+		characterCard, err := rawCard.Decode()
+		require.NoError(t, err)
+		resized, err := characterCard.Resized(8)
+		require.NoError(t, err)
+		assert.Same(t, characterCard.Sheet, resized.Sheet)
+
+		resizedBytes, err := resized.ToBytes()
+		require.NoError(t, err)
+		processor := FromBytes(resizedBytes)
+		width, height := processor.ImageSize()
+		assert.Equal(t, 8, width)
+		assert.Equal(t, 4, height)
+
+		reparsed, err := processor.Get()
+		require.NoError(t, err)
+		decoded, err := reparsed.Decode()
+		require.NoError(t, err)
+		assert.Equal(t, testCards.largeV3.Name, decoded.Name)
+
+		// Thumbnails are available on the character card as well
+		thumbnail, err := characterCard.Thumbnail(8)
+		require.NoError(t, err)
+		assert.Equal(t, 8, thumbnail.Bounds().Dx())
+	})
+
+	t.Run("Invalid image", func(t *testing.T) {
+		_, err := (&RawCard{pngData: pngData{Header: []byte("invalid")}}).Resized(8)
+		assert.Error(t, err)
+	})
+}
+
+func TestRawCard_ToRawJson_Base64Variants(t *testing.T) {
+	// The JSON encodes to base64 with + and / characters, and padding
+	jsonData := []byte(`{"spec":"chara_card_v2","spec_version":"2.0","data":{"name":"Alice ~~~ ???>>>"}}`)
+	stdData := base64.StdEncoding.EncodeToString(jsonData)
+	require.Contains(t, stdData, "=")
+	require.Regexp(t, `[+/]`, stdData)
+
+	tests := []struct {
+		name     string
+		data     string
+		expected Base64Encoding
+	}{
+		{name: "Standard", data: stdData, expected: StdBase64},
+		{name: "Unpadded", data: base64.RawStdEncoding.EncodeToString(jsonData), expected: RawStdBase64},
+		{name: "URL-safe", data: base64.URLEncoding.EncodeToString(jsonData), expected: URLBase64},
+		{name: "URL-safe unpadded", data: base64.RawURLEncoding.EncodeToString(jsonData), expected: RawURLBase64},
+		{name: "Embedded newlines", data: stdData[:20] + "\n" + stdData[20:40] + "\r\n " + stdData[40:], expected: StdBase64},
+	}
+
+	basePNG := createTestPNG(t, 4, 4)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rawCard, err := FromBytes(injectChunk(t, basePNG, character.RevisionV2, []byte(tt.data), false)).Get()
+			require.NoError(t, err)
+
+			// The variant is detected when the card is read, and the chara data is decoded
+			assert.Equal(t, tt.expected, rawCard.Encoding)
+			rawJsonCard, err := rawCard.ToRawJson()
+			require.NoError(t, err)
+			assert.Equal(t, jsonData, rawJsonCard.RawJsonData)
+
+			// The chara data is written back in standard padded base64
+			var buf bytes.Buffer
+			require.NoError(t, rawCard.ToImage(&buf))
+			written, err := FromBytes(buf.Bytes()).Get()
+			require.NoError(t, err)
+			assert.Equal(t, stdData, string(written.RawCharaData))
+		})
+	}
+
+	t.Run("Invalid base64", func(t *testing.T) {
+		_, err := (&RawCard{RawCharaData: []byte("not base64!")}).ToRawJson()
+		assert.Error(t, err)
+	})
+
+	t.Run("Writing does not modify the card", func(t *testing.T) {
+		rawCard, err := FromBytes(basePNG).Get()
+		require.NoError(t, err)
+		rawCard.RawCharaData = []byte(base64.RawURLEncoding.EncodeToString(jsonData))
+
+		// Concurrent writes only read the card
+		var group sync.WaitGroup
+		for range 4 {
+			group.Go(func() {
+				assert.NoError(t, rawCard.ToImage(io.Discard))
+			})
+		}
+		group.Wait()
+		_, err = rawCard.ToRawJson()
+		require.NoError(t, err)
+		assert.Equal(t, StdBase64, rawCard.Encoding)
+	})
+}
+
+func TestRawCard_Decode_LegacyV1(t *testing.T) {
+	rawCard, err := FromFile("testdata/legacy_v1.png").Get()
+	require.NoError(t, err)
+	require.NotEmpty(t, rawCard.RawCharaData)
+
+	t.Run("Imported", func(t *testing.T) {
+		card, err := rawCard.Decode()
+		require.NoError(t, err)
+		assert.True(t, card.LegacyImport)
+		assert.Equal(t, character.RevisionV2, card.Revision)
+		assert.Equal(t, property.String("Elara"), card.Name)
+		assert.Equal(t, property.String("curious, patient, dry humor"), card.Personality)
+		assert.Contains(t, string(card.FirstMessage), "unrolls a worn map")
+		assert.Contains(t, string(card.MessageExamples), "<START>")
+
+		// The card is written back with the data wrapper
+		data, err := card.ToBytes()
+		require.NoError(t, err)
+		written, err := FromBytes(data).Get()
+		require.NoError(t, err)
+		rawJsonCard, err := written.ToRawJson()
+		require.NoError(t, err)
+		assert.Contains(t, string(rawJsonCard.RawJsonData), `"data":{`)
+		decoded, err := written.Decode()
+		require.NoError(t, err)
+		assert.False(t, decoded.LegacyImport)
+		assert.True(t, card.DeepEquals(decoded.Sheet))
+	})
+
+	t.Run("Rejected", func(t *testing.T) {
+		_, err := rawCard.Decode(character.DecodeOptions{RejectLegacyCards: true})
+		assert.ErrorIs(t, err, character.ErrLegacyCard)
+	})
+}
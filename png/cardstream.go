@@ -0,0 +1,125 @@
+package png
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/r3dpixel/card-parser/character"
+)
+
+// sizedReader reader reporting the (maximum) size of its remaining data, used to size the read buffer up front
+type sizedReader struct {
+	io.Reader
+	size int
+}
+
+// Len returns the maximum size of the remaining data
+func (r sizedReader) Len() int {
+	return r.size
+}
+
+// DecodeStream converts a RawCard to a CharacterCard like Decode, decoding the base64 chara data straight into the
+// JSON decoder (the decoded JSON is the only copy of the chara data, without an intermediate RawJsonCard)
+// Chara data that is not standard padded base64 (see Encoding) is decoded by Decode
+func (rc *RawCard) DecodeStream() (*CharacterCard, error) {
+	// Missing and non-standard chara data is decoded as usual
+	if len(rc.RawCharaData) == 0 || !isStdBase64(rc.RawCharaData) {
+		return rc.Decode()
+	}
+
+	// Decode the JSON data from the base64 decoder
+	decoder := base64.NewDecoder(base64.StdEncoding, bytes.NewReader(rc.RawCharaData))
+	sheet, err := character.FromJSON(sizedReader{Reader: decoder, size: base64.StdEncoding.DecodedLen(len(rc.RawCharaData))})
+	if err != nil {
+		return nil, decodeStreamError(err)
+	}
+
+	// Stamp the sheet with the card revision
+	return newCharacterCard(rc.pngData, sheet, rc.Revision), nil
+}
+
+// decodeStreamError classifies the error of the streamed decoding like Decode (ErrInvalidBase64 if the base64 decoder
+// failed, InvalidCharacterJSONError otherwise)
+func decodeStreamError(err error) error {
+	var corruptErr base64.CorruptInputError
+	if errors.As(err, &corruptErr) {
+		return fmt.Errorf("%w: %w", ErrInvalidBase64, err)
+	}
+	return &InvalidCharacterJSONError{Cause: err}
+}
+
+// EncodeStream writes the CharacterCard as a PNG image like ToImage (chunk keyword of the sheet revision), encoding
+// the sheet JSON to base64 straight into the chara chunk (the base64 chara data is never held in memory)
+// Compressed chunks (ZTXT) need the whole text to be compressed first, so their chara data is still buffered
+func (cc *CharacterCard) EncodeStream(w io.Writer) error {
+	// Write the header of the image, and the body up to the injection position (see InjectionPolicy)
+	before, after := cc.bodyParts(cc.injectionOffset())
+	if _, err := w.Write(cc.Header); err != nil {
+		return err
+	}
+	if _, err := w.Write(before); err != nil {
+		return err
+	}
+
+	// Write the chara chunk
+	if cc.Sheet != nil {
+		jsonData, err := cc.Sheet.ToBytes()
+		if err != nil {
+			return err
+		}
+		if err := streamBase64Chunk(w, chunkKeywords()[keywordRevision(cc.Sheet.Revision)], jsonData, cc.chunkFormat); err != nil {
+			return err
+		}
+	}
+
+	// Write the non-chara text chunks if they were changed
+	if err := cc.writeTextChunks(w); err != nil {
+		return err
+	}
+
+	// Write the rest of the image body
+	_, err := w.Write(after)
+	return err
+}
+
+// streamBase64Chunk writes a text chunk holding the standard base64 encoding of the data (nothing if the data is empty)
+// The base64 text is encoded while it is written, except for the ZTXT format
+func streamBase64Chunk(w io.Writer, keyword []byte, data []byte, format ChunkFormat) error {
+	// If there is no data, there is no chunk
+	if len(data) == 0 {
+		return nil
+	}
+
+	// Compressed text must be encoded first
+	if format == ZTXT {
+		encoded := make([]byte, base64.StdEncoding.EncodedLen(len(data)))
+		base64.StdEncoding.Encode(encoded, data)
+		return streamTextChunk(w, keyword, encoded, format)
+	}
+
+	// The parts preceding the uncompressed text (see ChunkFormat.encodeText)
+	parts, err := format.encodeText(nil)
+	if err != nil {
+		return err
+	}
+	prefix := bytes.Join(parts[:len(parts)-1], nil)
+
+	// Write the chunk, encoding the text on the fly
+	length := len(keyword) + len(prefix) + base64.StdEncoding.EncodedLen(len(data))
+	return streamChunk(w, format.typeCode(), uint32(length), func(cw io.Writer) error {
+		if _, err := cw.Write(keyword); err != nil {
+			return err
+		}
+		if _, err := cw.Write(prefix); err != nil {
+			return err
+		}
+		encoder := base64.NewEncoder(base64.StdEncoding, cw)
+		if _, err := encoder.Write(data); err != nil {
+			return err
+		}
+		return encoder.Close()
+	})
+}
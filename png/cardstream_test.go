@@ -0,0 +1,152 @@
+package png
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/r3dpixel/card-parser/character"
+	"github.com/r3dpixel/card-parser/property"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// createStreamCard returns a raw card whose chara data is a sheet of about the given size (lorebook entries)
+func createStreamCard(tb testing.TB, size int) *RawCard {
+	tb.Helper()
+	rawCard, err := PlaceholderCharacterCard(4)
+	require.NoError(tb, err)
+
+	sheet := character.DefaultSheet(character.RevisionV3)
+	sheet.Name = "Mega"
+	sheet.CharacterBook = character.DefaultBook()
+	content := strings.Repeat("lorem ipsum dolor sit amet ", 40)
+	for index := 0; index*len(content) < size; index++ {
+		sheet.CharacterBook.Entries = append(sheet.CharacterBook.Entries, character.FilledBookEntry(fmt.Sprintf("Entry %d", index), content))
+	}
+	jsonData, err := sheet.ToBytes()
+	require.NoError(tb, err)
+
+	rawCard.RawCharaData = []byte(base64.StdEncoding.EncodeToString(jsonData))
+	rawCard.Revision = character.RevisionV3
+	return rawCard
+}
+
+func TestRawCard_DecodeStream(t *testing.T) {
+	rawCard := createStreamCard(t, 64<<10)
+	rawCard.TextChunks = []TextChunk{{Keyword: "Comment", Text: "kept"}}
+
+	t.Run("Same result as Decode", func(t *testing.T) {
+		expected, err := rawCard.Decode()
+		require.NoError(t, err)
+		actual, err := rawCard.DecodeStream()
+		require.NoError(t, err)
+		assert.True(t, expected.DeepEquals(actual.Sheet))
+		assert.Equal(t, expected.pngData, actual.pngData)
+		assert.Equal(t, StdBase64, rawCard.Encoding)
+	})
+
+	t.Run("Revision of the card", func(t *testing.T) {
+		v2Card := *rawCard
+		v2Card.Revision = character.RevisionV2
+		card, err := v2Card.DecodeStream()
+		require.NoError(t, err)
+		assert.Equal(t, character.RevisionV2, card.Revision)
+		assert.Equal(t, character.SpecV2, card.Spec)
+	})
+
+	t.Run("Non-standard base64", func(t *testing.T) {
+		wrappedCard := *rawCard
+		wrappedCard.RawCharaData = append(append(bytes.Clone(rawCard.RawCharaData[:76]), '\n'), rawCard.RawCharaData[76:]...)
+		card, err := wrappedCard.DecodeStream()
+		require.NoError(t, err)
+		assert.Equal(t, property.String("Mega"), card.Name)
+		assert.Equal(t, StdBase64, wrappedCard.Encoding)
+	})
+
+	t.Run("No chara data", func(t *testing.T) {
+		emptyCard := *rawCard
+		emptyCard.RawCharaData = nil
+		card, err := emptyCard.DecodeStream()
+		require.NoError(t, err)
+		assert.Equal(t, character.RevisionV2, card.Revision)
+	})
+
+	t.Run("Invalid JSON", func(t *testing.T) {
+		invalidCard := *rawCard
+		invalidCard.RawCharaData = []byte(base64.StdEncoding.EncodeToString([]byte("{invalid")))
+		_, err := invalidCard.DecodeStream()
+		assert.Error(t, err)
+	})
+}
+
+func TestCharacterCard_EncodeStream(t *testing.T) {
+	card, err := createStreamCard(t, 64<<10).Decode()
+	require.NoError(t, err)
+	card.TextChunks = []TextChunk{{Keyword: "Comment", Text: "kept"}}
+
+	for _, format := range []ChunkFormat{TEXT, ZTXT, ITXT} {
+		t.Run(fmt.Sprintf("Same image as ToImage (format %d)", format), func(t *testing.T) {
+			card.ChunkFormat(format)
+			expected, err := card.ToBytes()
+			require.NoError(t, err)
+			var actual bytes.Buffer
+			require.NoError(t, card.EncodeStream(&actual))
+			assert.Equal(t, expected, actual.Bytes())
+
+			// The image is readable
+			decoded, err := FromBytes(actual.Bytes()).Get()
+			require.NoError(t, err)
+			decodedCard, err := decoded.DecodeStream()
+			require.NoError(t, err)
+			assert.True(t, card.DeepEquals(decodedCard.Sheet))
+		})
+	}
+
+	t.Run("No sheet", func(t *testing.T) {
+		noSheet := &CharacterCard{pngData: card.pngData}
+		expected, err := noSheet.ToBytes()
+		require.NoError(t, err)
+		var actual bytes.Buffer
+		require.NoError(t, noSheet.EncodeStream(&actual))
+		assert.Equal(t, expected, actual.Bytes())
+	})
+}
+
+func BenchmarkRawCard_DecodeStream(b *testing.B) {
+	rawCard := createStreamCard(b, 10<<20)
+
+	b.Run("Decode", func(b *testing.B) {
+		b.ReportAllocs()
+		for b.Loop() {
+			_, _ = rawCard.Decode()
+		}
+	})
+	b.Run("DecodeStream", func(b *testing.B) {
+		b.ReportAllocs()
+		for b.Loop() {
+			_, _ = rawCard.DecodeStream()
+		}
+	})
+}
+
+func BenchmarkCharacterCard_EncodeStream(b *testing.B) {
+	card, err := createStreamCard(b, 10<<20).Decode()
+	require.NoError(b, err)
+
+	b.Run("ToImage", func(b *testing.B) {
+		b.ReportAllocs()
+		for b.Loop() {
+			_ = card.ToImage(io.Discard)
+		}
+	})
+	b.Run("EncodeStream", func(b *testing.B) {
+		b.ReportAllocs()
+		for b.Loop() {
+			_ = card.EncodeStream(io.Discard)
+		}
+	})
+}
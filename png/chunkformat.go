@@ -0,0 +1,125 @@
+package png
+
+import (
+	"bytes"
+	"compress/zlib"
+	"io"
+)
+
+// ChunkFormat defines the PNG text chunk type used to store the chara data
+type ChunkFormat int
+
+// ChunkFormat values
+const (
+	TEXT ChunkFormat = iota // `tEXt` chunk (uncompressed Latin-1 text, the default)
+	ZTXT                    // `zTXt` chunk (zlib compressed Latin-1 text)
+	ITXT                    // `iTXt` chunk (uncompressed UTF-8 text)
+)
+
+// Text chunk discriminators and properties
+const (
+	chunkZTextTypeCode uint32 = 0x7A545874 // Discriminator 'zTXt' (uint32)
+	chunkITextTypeCode uint32 = 0x69545874 // Discriminator 'iTXt' (uint32)
+
+	compressionMethodDeflate byte = 0 // The only compression method defined by the PNG specification
+	compressionFlagOn        byte = 1 // iTXt compression flag for compressed text
+)
+
+// chunkFormats mappings from text chunk discriminators to chunk formats
+var chunkFormats = map[uint32]ChunkFormat{
+	chunkTextTypeCode:  TEXT,
+	chunkZTextTypeCode: ZTXT,
+	chunkITextTypeCode: ITXT,
+}
+
+// typeCode returns the chunk discriminator of the format (fallback to tEXt)
+func (f ChunkFormat) typeCode() uint32 {
+	switch f {
+	case ZTXT:
+		return chunkZTextTypeCode
+	case ITXT:
+		return chunkITextTypeCode
+	default:
+		return chunkTextTypeCode
+	}
+}
+
+// encodeText returns the chunk data parts following the keyword (with its null separator) for the given text
+func (f ChunkFormat) encodeText(text []byte) ([][]byte, error) {
+	switch f {
+	case ZTXT:
+		// Compression method, compressed text
+		var buf bytes.Buffer
+		zw := zlib.NewWriter(&buf)
+		if _, err := zw.Write(text); err != nil {
+			return nil, err
+		}
+		if err := zw.Close(); err != nil {
+			return nil, err
+		}
+		return [][]byte{{compressionMethodDeflate}, buf.Bytes()}, nil
+	case ITXT:
+		// Compression flag, compression method, empty language tag, empty translated keyword, text
+		return [][]byte{{0x00, compressionMethodDeflate, 0x00, 0x00}, text}, nil
+	default:
+		return [][]byte{text}, nil
+	}
+}
+
+// decodeText returns the zTXt/iTXt chunk data in the tEXt layout (keyword, null separator, uncompressed text)
+// It returns false if the chunk is malformed, uses an unknown compression method, or inflates past maxSize bytes
+// (0 means no limit, see Processor.MaxChunkSize)
+func (f ChunkFormat) decodeText(chunkData []byte, maxSize int) ([]byte, bool) {
+	// Split the keyword from the rest of the chunk
+	keyword, rest, ok := bytes.Cut(chunkData, []byte{0x00})
+	if !ok {
+		return nil, false
+	}
+
+	// Extract the (possibly compressed) text
+	compressed := false
+	switch f {
+	case ZTXT:
+		if len(rest) < 1 || rest[0] != compressionMethodDeflate {
+			return nil, false
+		}
+		compressed, rest = true, rest[1:]
+	case ITXT:
+		if len(rest) < 2 || (rest[0] == compressionFlagOn && rest[1] != compressionMethodDeflate) {
+			return nil, false
+		}
+		compressed = rest[0] == compressionFlagOn
+		// Skip the language tag and the translated keyword
+		var hasLanguage, hasTranslation bool
+		_, rest, hasLanguage = bytes.Cut(rest[2:], []byte{0x00})
+		_, rest, hasTranslation = bytes.Cut(rest, []byte{0x00})
+		if !hasLanguage || !hasTranslation {
+			return nil, false
+		}
+	default:
+		return chunkData, true
+	}
+
+	// Build the tEXt layout
+	text := append(append(make([]byte, 0, len(keyword)+1+len(rest)), keyword...), 0x00)
+	if !compressed {
+		return append(text, rest...), true
+	}
+
+	// Inflate the text
+	zr, err := zlib.NewReader(bytes.NewReader(rest))
+	if err != nil {
+		return nil, false
+	}
+	defer zr.Close()
+	buf := bytes.NewBuffer(text)
+	if maxSize <= 0 {
+		_, err = io.Copy(buf, zr)
+		return buf.Bytes(), err == nil
+	}
+	n, err := io.Copy(buf, io.LimitReader(zr, int64(maxSize)+1))
+	if err != nil || n > int64(maxSize) {
+		return nil, false
+	}
+	return buf.Bytes(), true
+}
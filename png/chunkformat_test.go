@@ -0,0 +1,203 @@
+package png
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"hash/crc32"
+	"slices"
+	"testing"
+
+	"github.com/r3dpixel/card-parser/character"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// typedChunk builds a PNG chunk of the given type (length, type, data, CRC)
+func typedChunk(typeCode uint32, data ...[]byte) []byte {
+	chunkData := slices.Concat(data...)
+	chunk := binary.BigEndian.AppendUint32(nil, uint32(len(chunkData)))
+	chunk = binary.BigEndian.AppendUint32(chunk, typeCode)
+	chunk = append(chunk, chunkData...)
+	return binary.BigEndian.AppendUint32(chunk, crc32.ChecksumIEEE(chunk[chunkLengthSize:]))
+}
+
+// deflate compresses the data with zlib
+func deflate(t *testing.T, data []byte) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := zlib.NewWriter(&buf)
+	_, err := zw.Write(data)
+	require.NoError(t, err)
+	require.NoError(t, zw.Close())
+	return buf.Bytes()
+}
+
+func TestProcessor_TextChunkFormats(t *testing.T) {
+	basePNG := createTestPNG(t, 4, 4)
+	v2Data := encodeCardData(t, testCards.smallV2)
+	v3Data := encodeCardData(t, testCards.largeV3)
+
+	// withChunk injects a chunk right after the IHDR chunk
+	withChunk := func(chunk []byte) []byte {
+		return slices.Concat(basePNG[:fullIhdrSize], chunk, basePNG[fullIhdrSize:])
+	}
+
+	// Fixtures for every text chunk variant
+	zTXt := withChunk(typedChunk(chunkZTextTypeCode, []byte("chara\x00\x00"), deflate(t, v2Data)))
+	iTXt := withChunk(typedChunk(chunkITextTypeCode, []byte("chara\x00\x00\x00\x00\x00"), v2Data))
+	iTXtCompressed := withChunk(typedChunk(chunkITextTypeCode, []byte("ccv3\x00\x01\x00en\x00ccv3\x00"), deflate(t, v3Data)))
+
+	t.Run("Read", func(t *testing.T) {
+		tests := []struct {
+			name     string
+			data     []byte
+			format   ChunkFormat
+			revision character.Revision
+			expected []byte
+		}{
+			{"zTXt", zTXt, ZTXT, character.RevisionV2, v2Data},
+			{"iTXt uncompressed", iTXt, ITXT, character.RevisionV2, v2Data},
+			{"iTXt compressed", iTXtCompressed, ITXT, character.RevisionV3, v3Data},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				rawCard, err := FromBytes(tt.data).VerifyCRC().Get()
+				require.NoError(t, err)
+				assert.Equal(t, tt.revision, rawCard.Revision)
+				assert.Equal(t, tt.expected, rawCard.RawCharaData)
+				assert.Equal(t, tt.format, rawCard.chunkFormat)
+				assert.Equal(t, basePNG[fullIhdrSize:], rawCard.Body)
+
+				rawCards, err := FromBytes(tt.data).GetAll()
+				require.NoError(t, err)
+				require.Len(t, rawCards, 1)
+				assert.Equal(t, tt.expected, rawCards[0].RawCharaData)
+			})
+		}
+	})
+
+	t.Run("Scan modes across formats", func(t *testing.T) {
+		data := injectSingleChunk(t, iTXtCompressed, testCards.smallV2, true)
+
+		rawCard, err := FromBytes(data).First().Get()
+		require.NoError(t, err)
+		assert.Equal(t, v3Data, rawCard.RawCharaData)
+
+		rawCard, err = FromBytes(data).LastVersion().Get()
+		require.NoError(t, err)
+		assert.Equal(t, character.RevisionV3, rawCard.Revision)
+		assert.Equal(t, ITXT, rawCard.chunkFormat)
+	})
+
+	t.Run("Non chara text chunks", func(t *testing.T) {
+		chunks := [][]byte{
+			typedChunk(chunkITextTypeCode, []byte("XML:com.adobe.xmp\x00\x00\x00\x00\x00"), []byte("<x:xmpmeta/>")),
+			typedChunk(chunkZTextTypeCode, []byte("Comment\x00\x00"), deflate(t, []byte("hello"))),
+			typedChunk(chunkZTextTypeCode, []byte("chara\x00\x00"), []byte("not zlib data")),
+			typedChunk(chunkITextTypeCode, []byte("chara\x00\x01\x00"), deflate(t, v2Data)),
+		}
+		for _, chunk := range chunks {
+			rawCard, err := FromBytes(withChunk(chunk)).Get()
+			require.NoError(t, err)
+			assert.Empty(t, rawCard.RawCharaData)
+			// The chunk is kept in the image body
+			assert.Equal(t, slices.Concat(chunk, basePNG[fullIhdrSize:]), rawCard.Body)
+		}
+	})
+
+	t.Run("Lenient keyword", func(t *testing.T) {
+		data := withChunk(typedChunk(chunkZTextTypeCode, []byte("CHARA\x00\x00"), deflate(t, v2Data)))
+
+		rawCard, err := FromBytes(data).Get()
+		require.NoError(t, err)
+		assert.Empty(t, rawCard.RawCharaData)
+
+		rawCard, err = FromBytes(data).Lenient().Get()
+		require.NoError(t, err)
+		assert.Equal(t, v2Data, rawCard.RawCharaData)
+	})
+
+	t.Run("Inflation limit", func(t *testing.T) {
+		// A small chunk inflating to 64 KiB of chara data
+		charaData := bytes.Repeat([]byte("A"), 64*1024)
+		data := withChunk(typedChunk(chunkZTextTypeCode, []byte("chara\x00\x00"), deflate(t, charaData)))
+
+		rawCard, err := FromBytes(data).MaxChunkSize(4096).Get()
+		require.NoError(t, err)
+		assert.Empty(t, rawCard.RawCharaData)
+
+		for _, size := range []int{DefaultMaxChunkSize, 0} {
+			rawCard, err = FromBytes(data).MaxChunkSize(size).Get()
+			require.NoError(t, err)
+			assert.Equal(t, charaData, rawCard.RawCharaData)
+		}
+	})
+
+	t.Run("Strip", func(t *testing.T) {
+		for _, data := range [][]byte{zTXt, iTXt, iTXtCompressed} {
+			var buf bytes.Buffer
+			require.NoError(t, Strip(bytes.NewReader(data), &buf))
+			assert.Equal(t, basePNG, buf.Bytes())
+		}
+	})
+}
+
+func TestRawCard_ChunkFormat(t *testing.T) {
+	rawCard, err := FromBytes(injectSingleChunk(t, createTestPNG(t, 4, 4), testCards.smallV2, false)).Get()
+	require.NoError(t, err)
+	require.Equal(t, TEXT, rawCard.chunkFormat)
+
+	tests := []struct {
+		name     string
+		format   ChunkFormat
+		typeCode uint32
+	}{
+		{"tEXt", TEXT, chunkTextTypeCode},
+		{"zTXt", ZTXT, chunkZTextTypeCode},
+		{"iTXt", ITXT, chunkITextTypeCode},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := rawCard.ChunkFormat(tt.format).ToBytes(character.RevisionV2, character.RevisionV3)
+			require.NoError(t, err)
+
+			// Both chara chunks are written in the requested format
+			chunkType := binary.BigEndian.AppendUint32(nil, tt.typeCode)
+			assert.Equal(t, 2, bytes.Count(data, chunkType))
+
+			// The written chunks read back (with valid CRCs) in the same format
+			rawCards, err := FromBytes(data).VerifyCRC().GetAll()
+			require.NoError(t, err)
+			require.Len(t, rawCards, 2)
+			assert.Equal(t, rawCard.RawCharaData, rawCards[0].RawCharaData)
+			assert.Equal(t, character.RevisionV3, rawCards[1].Revision)
+			for _, card := range rawCards {
+				assert.Equal(t, tt.format, card.chunkFormat)
+			}
+
+			// The format survives decoding and encoding
+			characterCard, err := rawCards[0].Decode()
+			require.NoError(t, err)
+			reencoded, err := characterCard.ToBytes()
+			require.NoError(t, err)
+			assert.Equal(t, 1, bytes.Count(reencoded, chunkType))
+		})
+	}
+
+	t.Run("Character card", func(t *testing.T) {
+		characterCard, err := rawCard.ChunkFormat(TEXT).Decode()
+		require.NoError(t, err)
+		data, err := characterCard.ChunkFormat(ZTXT).ToBytes()
+		require.NoError(t, err)
+
+		decoded, err := FromBytes(data).Get()
+		require.NoError(t, err)
+		assert.Equal(t, ZTXT, decoded.chunkFormat)
+		decodedCard, err := decoded.Decode()
+		require.NoError(t, err)
+		assert.Equal(t, testCards.smallV2.Name, decodedCard.Name)
+	})
+}
@@ -0,0 +1,231 @@
+package png
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"image"
+	"io"
+	"strings"
+
+	"github.com/sunshineplan/imgconv"
+)
+
+// dataURLScheme is the scheme prefix of every data URL
+const dataURLScheme = "data:"
+
+// dataURLBase64 is the parameter marking base64 encoded data URL payloads
+const dataURLBase64 = ";base64"
+
+// DefaultMaxDataURLLength is the default upper bound (in bytes) of data URLs produced by ThumbnailDataURL
+const DefaultMaxDataURLLength = 2 * 1024 * 1024
+
+// DataURLOptions configures ThumbnailDataURL
+type DataURLOptions struct {
+	// MaxLength is the upper bound (in bytes) of the data URL (0 uses DefaultMaxDataURLLength)
+	MaxLength int
+}
+
+// maxLength returns the effective upper bound of the data URL
+func (o DataURLOptions) maxLength() int {
+	if o.MaxLength <= 0 {
+		return DefaultMaxDataURLLength
+	}
+	return o.MaxLength
+}
+
+// Data URL errors
+var (
+	ErrDataURLTooLong       = errors.New("data URL exceeds the maximum length")
+	ErrInvalidDataURL       = errors.New("invalid data URL")
+	ErrUnsupportedFormat    = errors.New("unsupported image format")
+	ErrUnsupportedDataURL   = errors.New("data URL is not base64 encoded")
+	ErrUnsupportedMediaType = errors.New("data URL media type is not an image")
+	ErrInvalidBase64        = errors.New("invalid base64 payload")
+)
+
+// Format output image format
+type Format int
+
+// Format values
+const (
+	FormatPNG Format = iota
+	FormatJPEG
+	FormatWebP
+)
+
+// MediaType returns the media (MIME) type of the format
+func (f Format) MediaType() string {
+	switch f {
+	case FormatPNG:
+		return "image/png"
+	case FormatJPEG:
+		return "image/jpeg"
+	case FormatWebP:
+		return "image/webp"
+	default:
+		return ""
+	}
+}
+
+// String returns the name of the format
+func (f Format) String() string {
+	switch f {
+	case FormatPNG:
+		return "png"
+	case FormatJPEG:
+		return "jpeg"
+	case FormatWebP:
+		return "webp"
+	default:
+		return fmt.Sprintf("Format(%d)", int(f))
+	}
+}
+
+// encodeImage encodes the image in the given format (quality is ignored by lossless formats)
+func encodeImage(img image.Image, format Format, quality int) ([]byte, error) {
+	// Map the format to the encoder format
+	formatOption := imgconv.FormatOption{EncodeOption: []imgconv.EncodeOption{imgconv.Quality(quality)}}
+	switch format {
+	case FormatPNG:
+		formatOption.Format = imgconv.PNG
+	case FormatJPEG:
+		formatOption.Format = imgconv.JPEG
+	case FormatWebP:
+		formatOption.Format = imgconv.WEBP
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedFormat, format)
+	}
+
+	// Encode the image
+	writer := new(bytes.Buffer)
+	if err := formatOption.Encode(writer, img); err != nil {
+		return nil, err
+	}
+
+	// Return the encoded bytes
+	return writer.Bytes(), nil
+}
+
+// ThumbnailDataURL creates a thumbnail of the given size and returns it as a base64 data URL
+// (e.g. data:image/png;base64,...), ready to be embedded in web pages
+func (p *pngData) ThumbnailDataURL(size int, format Format, quality int, opts ...DataURLOptions) (string, error) {
+	// Create the thumbnail
+	thumbnail, err := p.Thumbnail(size)
+	if err != nil {
+		return "", err
+	}
+
+	// Encode the thumbnail in the requested format
+	encoded, err := encodeImage(thumbnail, format, quality)
+	if err != nil {
+		return "", err
+	}
+
+	// Check the final length before building the data URL
+	prefix := dataURLScheme + format.MediaType() + dataURLBase64 + ","
+	length := len(prefix) + base64.StdEncoding.EncodedLen(len(encoded))
+	maxLength := DataURLOptions{}.maxLength()
+	if len(opts) > 0 {
+		maxLength = opts[0].maxLength()
+	}
+	if length > maxLength {
+		return "", fmt.Errorf("%w: %d > %d", ErrDataURLTooLong, length, maxLength)
+	}
+
+	// Build the data URL
+	builder := strings.Builder{}
+	builder.Grow(length)
+	builder.WriteString(prefix)
+	builder.WriteString(base64.StdEncoding.EncodeToString(encoded))
+
+	// Return the data URL
+	return builder.String(), nil
+}
+
+// FromDataURI creates a Processor from a base64 image data URI (e.g. data:image/png;base64,...)
+// The payload is validated, then decoded while it is read (non-PNG images are converted, as with FromImage)
+func FromDataURI(dataURI string) Processor {
+	// Extract the data URI payload
+	payload, err := dataURLPayload(dataURI)
+	if err != nil {
+		return &converterProcessor{err: err}
+	}
+	if err := validateBase64(payload); err != nil {
+		return &converterProcessor{err: fmt.Errorf("%w: %w", ErrInvalidDataURL, err)}
+	}
+	// Return a processor streaming the decoded payload
+	return FromImage(io.NopCloser(base64.NewDecoder(base64.StdEncoding, strings.NewReader(payload))))
+}
+
+// FromBase64 creates a Processor from a bare base64 encoded image (standard encoding, line breaks are ignored)
+// The payload is validated, then decoded while it is read (non-PNG images are converted, as with FromImage)
+func FromBase64(encoded string) Processor {
+	// Validate the payload
+	encoded = strings.TrimSpace(encoded)
+	if err := validateBase64(encoded); err != nil {
+		return &converterProcessor{err: err}
+	}
+	// Return a processor streaming the decoded payload
+	return FromImage(io.NopCloser(base64.NewDecoder(base64.StdEncoding, strings.NewReader(encoded))))
+}
+
+// dataURLPayload validates an image data URL and returns its (still encoded) base64 payload
+func dataURLPayload(dataURL string) (string, error) {
+	// The data URL must start with the data scheme
+	rest, ok := strings.CutPrefix(strings.TrimSpace(dataURL), dataURLScheme)
+	if !ok {
+		return "", ErrInvalidDataURL
+	}
+
+	// Split the metadata from the payload
+	metadata, payload, ok := strings.Cut(rest, ",")
+	if !ok {
+		return "", ErrInvalidDataURL
+	}
+
+	// Only base64 encoded payloads are supported
+	mediaType, ok := strings.CutSuffix(metadata, dataURLBase64)
+	if !ok {
+		return "", ErrUnsupportedDataURL
+	}
+
+	// Only image media types are supported (parameters such as charset are ignored)
+	mediaType, _, _ = strings.Cut(mediaType, ";")
+	if !strings.HasPrefix(strings.ToLower(mediaType), "image/") {
+		return "", fmt.Errorf("%w: %q", ErrUnsupportedMediaType, mediaType)
+	}
+
+	// Return the payload
+	return payload, nil
+}
+
+// validateBase64 checks the alphabet and the padding of a standard base64 payload without decoding it
+// Line breaks are ignored, as by the base64 decoder
+func validateBase64(encoded string) error {
+	length, padding := 0, 0
+	for index := 0; index < len(encoded); index++ {
+		c := encoded[index]
+		switch {
+		case c == '\r' || c == '\n':
+			continue
+		case c == '=':
+			padding++
+		case padding > 0 || !isBase64Char(c):
+			return fmt.Errorf("%w: illegal character at offset %d", ErrInvalidBase64, index)
+		}
+		length++
+	}
+
+	// The payload must be made of complete (padded) quantums
+	if length == 0 || length%4 != 0 || padding > 2 {
+		return fmt.Errorf("%w: truncated or badly padded payload", ErrInvalidBase64)
+	}
+	return nil
+}
+
+// isBase64Char checks if the byte belongs to the standard base64 alphabet
+func isBase64Char(c byte) bool {
+	return c >= 'A' && c <= 'Z' || c >= 'a' && c <= 'z' || c >= '0' && c <= '9' || c == '+' || c == '/'
+}
@@ -0,0 +1,164 @@
+package png
+
+import (
+	"bytes"
+	"encoding/base64"
+	"image"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPngData_ThumbnailDataURL(t *testing.T) {
+	testCases := []struct {
+		name   string
+		format Format
+		prefix string
+		magic  []byte
+		codec  string
+	}{
+		{"PNG", FormatPNG, "data:image/png;base64,", pngHeader, "png"},
+		{"JPEG", FormatJPEG, "data:image/jpeg;base64,", []byte{0xff, 0xd8, 0xff}, "jpeg"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			pd := setupPngDataTest(t)
+
+			dataURL, err := pd.ThumbnailDataURL(50, tc.format, 90)
+			require.NoError(t, err)
+			require.True(t, strings.HasPrefix(dataURL, tc.prefix), "Data URL should carry the %s media type", tc.format)
+
+			data, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(dataURL, tc.prefix))
+			require.NoError(t, err)
+			assert.True(t, bytes.HasPrefix(data, tc.magic), "Payload should start with the %s magic bytes", tc.format)
+
+			config, codec, err := image.DecodeConfig(bytes.NewReader(data))
+			require.NoError(t, err)
+			assert.Equal(t, tc.codec, codec)
+			assert.Equal(t, 50, config.Width)
+			assert.Equal(t, 25, config.Height)
+		})
+	}
+}
+
+func TestPngData_ThumbnailDataURL_Errors(t *testing.T) {
+	t.Run("Too long", func(t *testing.T) {
+		pd := setupPngDataTest(t)
+		_, err := pd.ThumbnailDataURL(50, FormatPNG, 90, DataURLOptions{MaxLength: 32})
+		assert.ErrorIs(t, err, ErrDataURLTooLong)
+	})
+
+	t.Run("Unsupported format", func(t *testing.T) {
+		pd := setupPngDataTest(t)
+		_, err := pd.ThumbnailDataURL(50, Format(-1), 90)
+		assert.ErrorIs(t, err, ErrUnsupportedFormat)
+	})
+}
+
+func TestFromDataURI(t *testing.T) {
+	pngBytes := injectSingleChunk(t, createTestPNG(t, 8, 4), testCards.smallV2, false)
+	pngPayload := base64.StdEncoding.EncodeToString(pngBytes)
+	jpgPayload := base64.StdEncoding.EncodeToString(createTestJPG(t))
+
+	t.Run("PNG", func(t *testing.T) {
+		processor := FromDataURI("data:image/png;base64," + pngPayload)
+		require.NoError(t, processor.Err())
+		require.IsType(t, &scanningProcessor{}, processor)
+
+		rawCard, err := processor.Get()
+		require.NoError(t, err)
+		assert.Equal(t, encodeCardData(t, testCards.smallV2), []byte(rawCard.RawCharaData))
+		card, err := rawCard.Decode()
+		require.NoError(t, err)
+		assert.Equal(t, testCards.smallV2.Name, card.Name)
+		assert.Equal(t, 8, card.Width())
+		assert.Equal(t, 4, card.Height())
+	})
+
+	t.Run("Thumbnail round trip", func(t *testing.T) {
+		pd := setupPngDataTest(t)
+		dataURI, err := pd.ThumbnailDataURL(50, FormatJPEG, 90)
+		require.NoError(t, err)
+
+		width, height := FromDataURI(dataURI).ImageSize()
+		assert.Equal(t, 50, width)
+		assert.Equal(t, 25, height)
+	})
+
+	t.Run("JPEG", func(t *testing.T) {
+		processor := FromDataURI("data:image/jpeg;base64," + jpgPayload)
+		require.NoError(t, processor.Err())
+		require.IsType(t, &converterProcessor{}, processor)
+
+		rawCard, err := processor.Get()
+		require.NoError(t, err)
+		assert.Equal(t, pngHeader, rawCard.Header[:headerSize])
+	})
+
+	t.Run("Errors", func(t *testing.T) {
+		testCases := []struct {
+			name    string
+			dataURI string
+			err     error
+		}{
+			{"Missing scheme", "image/png;base64," + pngPayload, ErrInvalidDataURL},
+			{"Missing payload separator", "data:image/png;base64", ErrInvalidDataURL},
+			{"Not base64", "data:image/png," + pngPayload, ErrUnsupportedDataURL},
+			{"Not an image", "data:text/plain;base64," + pngPayload, ErrUnsupportedMediaType},
+			{"Unsupported media type", "data:application/pdf;base64," + pngPayload, ErrUnsupportedMediaType},
+			{"Corrupted payload", "data:image/png;base64,!!!", ErrInvalidDataURL},
+			{"Truncated payload", "data:image/png;base64," + pngPayload[:len(pngPayload)-3], ErrInvalidBase64},
+			{"Invalid padding", "data:image/png;base64,QUJD=REVG", ErrInvalidBase64},
+			{"Empty payload", "data:image/png;base64,", ErrInvalidDataURL},
+		}
+		for _, tc := range testCases {
+			t.Run(tc.name, func(t *testing.T) {
+				assert.ErrorIs(t, FromDataURI(tc.dataURI).Err(), tc.err)
+			})
+		}
+	})
+}
+
+func TestFromBase64(t *testing.T) {
+	pngBytes := injectSingleChunk(t, createTestPNG(t, 8, 4), testCards.smallV2, false)
+	payload := base64.StdEncoding.EncodeToString(pngBytes)
+
+	t.Run("PNG", func(t *testing.T) {
+		rawCard, err := FromBase64(payload).Get()
+		require.NoError(t, err)
+		assert.Equal(t, encodeCardData(t, testCards.smallV2), []byte(rawCard.RawCharaData))
+	})
+
+	t.Run("Line breaks", func(t *testing.T) {
+		wrapped := payload[:40] + "\r\n" + payload[40:80] + "\n" + payload[80:] + "\n"
+		rawCard, err := FromBase64(wrapped).Get()
+		require.NoError(t, err)
+		assert.Equal(t, encodeCardData(t, testCards.smallV2), []byte(rawCard.RawCharaData))
+	})
+
+	t.Run("JPEG", func(t *testing.T) {
+		width, height := FromBase64(base64.StdEncoding.EncodeToString(createTestJPG(t))).ImageSize()
+		assert.Positive(t, width)
+		assert.Positive(t, height)
+	})
+
+	t.Run("Errors", func(t *testing.T) {
+		testCases := []struct {
+			name    string
+			encoded string
+		}{
+			{"Truncated", payload[:len(payload)-1]},
+			{"Illegal character", "QUJD*EVG"},
+			{"Too much padding", "QQ==="},
+			{"Empty", ""},
+		}
+		for _, tc := range testCases {
+			t.Run(tc.name, func(t *testing.T) {
+				assert.ErrorIs(t, FromBase64(tc.encoded).Err(), ErrInvalidBase64)
+			})
+		}
+	})
+}
@@ -0,0 +1,124 @@
+package png
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/r3dpixel/card-parser/character"
+)
+
+// ErrNoChange is returned by the callback of EditFile to leave the file untouched
+var ErrNoChange = errors.New("no change")
+
+// EditOptions options of EditFile
+type EditOptions struct {
+	// Revisions of the chara chunks written (see RawCard.ToImage), defaulting to the revisions of the chara chunks
+	// found in the original file (the sheet revision if there are none)
+	Revisions []character.Revision
+	// PreserveModTime keeps the modification time of the original file
+	PreserveModTime bool
+	// AllowCreate edits a default sheet when the original file has no chara data (ErrNoCharacterData otherwise)
+	AllowCreate bool
+}
+
+// EditFile decodes the sheet of the PNG card at the given path, runs the callback on it, and saves the card back
+// The sheet of the highest revision chunk is edited (see LastVersion), and every chara chunk of the original file is
+// replaced (by default with a chunk for each revision found, e.g. both chara and ccv3 for dual cards)
+// The card is written to a temporary file in the same directory, synced, and renamed over the original (keeping its
+// file mode), so the original is never truncated: it is untouched if the callback, the encoding or the writing fails
+// The callback returns ErrNoChange to skip the writing (EditFile then returns nil); images that are not PNG (converted
+// on read) are rejected with ErrNotPNG, and symlinks are followed (the target file is replaced)
+func EditFile(path string, edit func(*character.Sheet) error, opts EditOptions) error {
+	// Resolve the file behind symlinks (the rename would replace the link)
+	path, err := filepath.EvalSymlinks(path)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrFileOpen, err)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrFileOpen, err)
+	}
+
+	// Read the whole card, selecting the highest revision chunk (every chara chunk is dropped from the image data, and
+	// replaced by the written ones)
+	rawCard, err := FromFile(path).LastVersion().TrackOffsets().Get()
+	if err != nil {
+		return err
+	}
+	if rawCard.WasConverted {
+		return fmt.Errorf("%w: %s", ErrNotPNG, path)
+	}
+	if len(rawCard.RawCharaData) == 0 && !opts.AllowCreate {
+		return fmt.Errorf("%w: %s", ErrNoCharacterData, path)
+	}
+	card, err := rawCard.Decode()
+	if err != nil {
+		return err
+	}
+
+	// Edit the sheet
+	if err := edit(card.Sheet); err != nil {
+		if errors.Is(err, ErrNoChange) {
+			return nil
+		}
+		return err
+	}
+
+	// Write the card to a temporary file (with the revisions found by default), and replace the original
+	revisions := opts.Revisions
+	if len(revisions) == 0 {
+		for _, span := range rawCard.ChunkSpans {
+			revisions = append(revisions, span.Revision)
+		}
+	}
+	if err := writeFileAtomic(path, info.Mode().Perm(), card, revisions); err != nil {
+		return err
+	}
+	if opts.PreserveModTime {
+		return os.Chtimes(path, info.ModTime(), info.ModTime())
+	}
+	return nil
+}
+
+// writeFileAtomic writes the card to a temporary file next to the path, syncs it, and renames it over the path
+// The temporary file is removed on failure
+func writeFileAtomic(path string, mode os.FileMode, card *CharacterCard, revisions []character.Revision) (err error) {
+	// Create the temporary file in the same directory (the rename must not cross file systems)
+	dir := filepath.Dir(path)
+	tmp, err := os.CreateTemp(dir, "."+filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			_ = tmp.Close()
+			_ = os.Remove(tmp.Name())
+		}
+	}()
+
+	// Write and sync the card
+	if err = tmp.Chmod(mode); err != nil {
+		return err
+	}
+	if err = card.ToImage(tmp, revisions...); err != nil {
+		return err
+	}
+	if err = tmp.Sync(); err != nil {
+		return err
+	}
+	if err = tmp.Close(); err != nil {
+		return err
+	}
+
+	// Replace the original, and sync the directory entry (best effort, not supported on every platform)
+	if err = os.Rename(tmp.Name(), path); err != nil {
+		return err
+	}
+	if d, dirErr := os.Open(dir); dirErr == nil {
+		_ = d.Sync()
+		_ = d.Close()
+	}
+	return nil
+}
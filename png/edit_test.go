@@ -0,0 +1,196 @@
+package png
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/r3dpixel/card-parser/character"
+	"github.com/r3dpixel/card-parser/property"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEditFile(t *testing.T) {
+	cardPNG := injectSingleChunk(t, createTestPNG(t, 4, 4), testCards.smallV2, false)
+	past := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	errEdit := errors.New("edit failed")
+
+	// writeCard writes the image to a temporary directory with an old modification time
+	writeCard := func(t *testing.T, data []byte) string {
+		t.Helper()
+		path := filepath.Join(t.TempDir(), "card.png")
+		require.NoError(t, os.WriteFile(path, data, 0o600))
+		require.NoError(t, os.Chtimes(path, past, past))
+		return path
+	}
+	rename := func(sheet *character.Sheet) error {
+		sheet.Name = "Edited"
+		return nil
+	}
+
+	tests := []struct {
+		name     string
+		data     []byte
+		edit     func(*character.Sheet) error
+		opts     EditOptions
+		err      error
+		expected string // Name of the saved sheet (the file is untouched if empty)
+	}{
+		{
+			name:     "Edit and save",
+			data:     cardPNG,
+			edit:     rename,
+			expected: "Edited",
+		},
+		{
+			name:     "Edit card with several chara chunks",
+			data:     injectDoubleChunk(t, createTestPNG(t, 4, 4), createSheet(character.RevisionV2, "Old V2"), createSheet(character.RevisionV2, "Old  V2")),
+			edit:     rename,
+			expected: "Edited",
+		},
+		{
+			name: "No change",
+			data: cardPNG,
+			edit: func(sheet *character.Sheet) error {
+				sheet.Name = "Discarded"
+				return ErrNoChange
+			},
+		},
+		{
+			name: "Callback error",
+			data: cardPNG,
+			edit: func(sheet *character.Sheet) error {
+				sheet.Name = "Discarded"
+				return errEdit
+			},
+			err: errEdit,
+		},
+		{
+			name: "No character data",
+			data: createTestPNG(t, 4, 4),
+			edit: rename,
+			err:  ErrNoCharacterData,
+		},
+		{
+			name:     "No character data (allow create)",
+			data:     createTestPNG(t, 4, 4),
+			edit:     rename,
+			opts:     EditOptions{AllowCreate: true},
+			expected: "Edited",
+		},
+		{
+			name: "Converted image",
+			data: createTestJPG(t),
+			edit: rename,
+			opts: EditOptions{AllowCreate: true},
+			err:  ErrNotPNG,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := writeCard(t, tt.data)
+
+			err := EditFile(path, tt.edit, tt.opts)
+			if tt.err != nil {
+				assert.ErrorIs(t, err, tt.err)
+			} else {
+				require.NoError(t, err)
+			}
+
+			// Only the card is left in the directory (no temporary file)
+			entries, err := os.ReadDir(filepath.Dir(path))
+			require.NoError(t, err)
+			assert.Len(t, entries, 1)
+
+			info, err := os.Stat(path)
+			require.NoError(t, err)
+			assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+			if tt.expected == "" {
+				// The original is untouched
+				data, err := os.ReadFile(path)
+				require.NoError(t, err)
+				assert.Equal(t, tt.data, data)
+				assert.True(t, past.Equal(info.ModTime()))
+				return
+			}
+
+			cards, err := FromFile(path).GetAll()
+			require.NoError(t, err)
+			require.Len(t, cards, 1)
+			decoded, err := cards[0].Decode()
+			require.NoError(t, err)
+			assert.Equal(t, property.String(tt.expected), decoded.Name)
+			assert.False(t, past.Equal(info.ModTime()))
+		})
+	}
+
+	t.Run("Preserve modification time and revisions", func(t *testing.T) {
+		path := writeCard(t, cardPNG)
+		opts := EditOptions{Revisions: []character.Revision{character.RevisionV2, character.RevisionV3}, PreserveModTime: true}
+		require.NoError(t, EditFile(path, rename, opts))
+
+		info, err := os.Stat(path)
+		require.NoError(t, err)
+		assert.True(t, past.Equal(info.ModTime()))
+		cards, err := FromFile(path).GetAll()
+		require.NoError(t, err)
+		require.Len(t, cards, 2)
+		assert.Equal(t, character.RevisionV2, cards[0].Revision)
+		assert.Equal(t, character.RevisionV3, cards[1].Revision)
+	})
+
+	t.Run("Dual chunk card", func(t *testing.T) {
+		v3Sheet := createSheet(character.RevisionV3, "Old V3")
+		v3Sheet.Nickname = "Only in V3"
+		path := writeCard(t, injectDoubleChunk(t, createTestPNG(t, 4, 4), createSheet(character.RevisionV2, "Much longer old V2"), v3Sheet))
+		require.NoError(t, EditFile(path, func(sheet *character.Sheet) error {
+			assert.Equal(t, property.String("Old V3"), sheet.Name)
+			return rename(sheet)
+		}, EditOptions{}))
+
+		// Both chunks are written back from the edited V3 sheet
+		cards, err := FromFile(path).GetAll()
+		require.NoError(t, err)
+		require.Len(t, cards, 2)
+		revisions := make([]character.Revision, 0, len(cards))
+		for _, card := range cards {
+			revisions = append(revisions, card.Revision)
+			decoded, err := card.Decode()
+			require.NoError(t, err)
+			assert.Equal(t, property.String("Edited"), decoded.Name)
+		}
+		assert.ElementsMatch(t, []character.Revision{character.RevisionV2, character.RevisionV3}, revisions)
+		v3Card, err := FromFile(path).LastVersion().Get()
+		require.NoError(t, err)
+		edited, err := v3Card.Decode()
+		require.NoError(t, err)
+		assert.Equal(t, property.String("Only in V3"), edited.Nickname)
+	})
+
+	t.Run("Symlink", func(t *testing.T) {
+		path := writeCard(t, cardPNG)
+		link := filepath.Join(t.TempDir(), "link.png")
+		if err := os.Symlink(path, link); err != nil {
+			t.Skip("symlinks are not supported:", err)
+		}
+		require.NoError(t, EditFile(link, rename, EditOptions{}))
+
+		info, err := os.Lstat(link)
+		require.NoError(t, err)
+		assert.NotZero(t, info.Mode()&os.ModeSymlink)
+		card, err := FromFile(path).Get()
+		require.NoError(t, err)
+		decoded, err := card.Decode()
+		require.NoError(t, err)
+		assert.Equal(t, property.String("Edited"), decoded.Name)
+	})
+
+	t.Run("Missing file", func(t *testing.T) {
+		err := EditFile(filepath.Join(t.TempDir(), "missing.png"), rename, EditOptions{})
+		assert.ErrorIs(t, err, ErrFileOpen)
+	})
+}
@@ -0,0 +1,105 @@
+package png
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// ErrNoCharacterData is returned by Get and GetAll when no chara data is found (with Processor.RequireCharacterData)
+var ErrNoCharacterData = errors.New("no character data found")
+
+// ErrMalformedChunk is matched (errors.Is) by MalformedChunkError
+var ErrMalformedChunk = errors.New("malformed chunk")
+
+// ErrInvalidCharacterJSON is matched (errors.Is) by InvalidCharacterJSONError
+var ErrInvalidCharacterJSON = errors.New("invalid character JSON")
+
+// ErrFileOpen is returned (wrapping the os error) when the file given to FromFile cannot be opened
+var ErrFileOpen = errors.New("cannot open file")
+
+// ErrFetch is matched (errors.Is) by FetchError
+var ErrFetch = errors.New("cannot fetch image")
+
+// MalformedChunkError a chunk that cannot be read (truncated, or failing the CRC verification)
+type MalformedChunkError struct {
+	Offset int64  // Offset of the chunk in the input
+	Type   string // Chunk type (empty if the input ends before it)
+	Err    error  // Underlying error (io.ErrUnexpectedEOF or ErrCRCMismatch)
+}
+
+// newMalformedChunkError returns a MalformedChunkError of the chunk type code at the given offset
+func newMalformedChunkError(typeCode uint32, offset int64, err error) *MalformedChunkError {
+	var chunkType string
+	if typeCode != 0 {
+		chunkType = string(binary.BigEndian.AppendUint32(nil, typeCode))
+	}
+	return &MalformedChunkError{Offset: offset, Type: chunkType, Err: err}
+}
+
+// Error returns the error message with the chunk type and offset
+func (e *MalformedChunkError) Error() string {
+	return fmt.Sprintf("%v: %q chunk at offset %d: %v", ErrMalformedChunk, e.Type, e.Offset, e.Err)
+}
+
+// Unwrap returns the underlying error
+func (e *MalformedChunkError) Unwrap() error {
+	return e.Err
+}
+
+// Is reports whether the target is ErrMalformedChunk
+func (e *MalformedChunkError) Is(target error) bool {
+	return target == ErrMalformedChunk
+}
+
+// InvalidCharacterJSONError chara data that is valid base64, but not a valid character sheet
+type InvalidCharacterJSONError struct {
+	Cause error // Error of the JSON decoding
+}
+
+// Error returns the error message with the cause
+func (e *InvalidCharacterJSONError) Error() string {
+	return fmt.Sprintf("%v: %v", ErrInvalidCharacterJSON, e.Cause)
+}
+
+// Unwrap returns the cause
+func (e *InvalidCharacterJSONError) Unwrap() error {
+	return e.Cause
+}
+
+// Is reports whether the target is ErrInvalidCharacterJSON
+func (e *InvalidCharacterJSONError) Is(target error) bool {
+	return target == ErrInvalidCharacterJSON
+}
+
+// FetchError the failure of the last URL given to FromURL and FromURLContext (every URL is reported by
+// Processor.Attempts)
+type FetchError struct {
+	URL    string // Fetched URL
+	Status int    // HTTP status of the response (0 if there was no response)
+	Err    error  // Underlying error (e.g. NotAnImageError, ErrResponseTooLarge, or the transport error)
+}
+
+// Error returns the error message with the URL and status
+func (e *FetchError) Error() string {
+	if e.Status == 0 {
+		return fmt.Sprintf("%v %s: %v", ErrFetch, e.URL, e.Err)
+	}
+	return fmt.Sprintf("%v %s (status %d): %v", ErrFetch, e.URL, e.Status, e.Err)
+}
+
+// Unwrap returns the underlying error
+func (e *FetchError) Unwrap() error {
+	return e.Err
+}
+
+// Is reports whether the target is ErrFetch
+func (e *FetchError) Is(target error) bool {
+	return target == ErrFetch
+}
+
+// isTruncated checks if the error reports input ending in the middle of a chunk
+func isTruncated(err error) bool {
+	return errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF)
+}
@@ -0,0 +1,184 @@
+package png
+
+import (
+	"encoding/base64"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/r3dpixel/toolkit/reqx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScanner_MalformedChunk(t *testing.T) {
+	basePNG := createTestPNG(t, 4, 4)
+	withChara := injectSingleChunk(t, basePNG, testCards.smallV2, false)
+	charaOffset := headerSize + ihdrSize
+	idatOffset := charaOffset + chunkHeaderSize + charaKeywordSize + len(encodeCardData(t, testCards.smallV2))
+
+	tests := []struct {
+		name      string
+		data      []byte
+		scanMode  ScanMode
+		chunkType string
+		offset    int
+	}{
+		{"Truncated chara chunk", withChara[:charaOffset+chunkHeaderSize], LastVersion, "tEXt", charaOffset},
+		{"Truncated streamed chunk", withChara[:idatOffset+chunkHeaderSize], LastVersion, "IDAT", idatOffset},
+		{"Truncated chunk after short-circuit", withChara[:idatOffset+chunkHeaderSize], First, "IDAT", idatOffset},
+		{"Truncated chunk type", withChara[:idatOffset+chunkLengthSize], LastVersion, "", idatOffset},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := FromBytes(tt.data).ScanMode(tt.scanMode).Get()
+			require.ErrorIs(t, err, ErrMalformedChunk)
+			require.ErrorIs(t, err, io.ErrUnexpectedEOF)
+
+			var malformed *MalformedChunkError
+			require.True(t, errors.As(err, &malformed))
+			assert.Equal(t, tt.chunkType, malformed.Type)
+			assert.Equal(t, int64(tt.offset), malformed.Offset)
+
+			// Streaming reports the same error
+			err = FromBytes(tt.data).ScanMode(tt.scanMode).Pipe(io.Discard, nil)
+			assert.ErrorIs(t, err, ErrMalformedChunk)
+		})
+	}
+
+	t.Run("CRC mismatch", func(t *testing.T) {
+		corrupted := append([]byte(nil), withChara...)
+		corrupted[idatOffset+chunkHeaderSize] ^= 0xFF
+		_, err := FromBytes(corrupted).VerifyCRC().Get()
+		require.ErrorIs(t, err, ErrMalformedChunk)
+		require.ErrorIs(t, err, ErrCRCMismatch)
+
+		var malformed *MalformedChunkError
+		require.True(t, errors.As(err, &malformed))
+		assert.Equal(t, "IDAT", malformed.Type)
+		assert.Equal(t, int64(idatOffset), malformed.Offset)
+	})
+
+	t.Run("Complete PNG", func(t *testing.T) {
+		_, err := FromBytes(withChara).Get()
+		assert.NoError(t, err)
+	})
+}
+
+func TestProcessor_RequireCharacterData(t *testing.T) {
+	basePNG := createTestPNG(t, 4, 4)
+	withChara := injectSingleChunk(t, basePNG, testCards.smallV2, false)
+
+	tests := []struct {
+		name    string
+		data    []byte
+		wantErr bool
+	}{
+		{"PNG with chara data", withChara, false},
+		{"PNG without chara data", basePNG, true},
+		{"Converted image without chara data", createTestJPG(t), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Without the flag an empty raw card is returned
+			rawCard, err := FromBytes(tt.data).Get()
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantErr, len(rawCard.RawCharaData) == 0)
+
+			// With the flag the missing chara data is reported
+			_, err = FromBytes(tt.data).RequireCharacterData().Get()
+			_, errAll := FromBytes(tt.data).RequireCharacterData().GetAll()
+			if tt.wantErr {
+				assert.ErrorIs(t, err, ErrNoCharacterData)
+				assert.ErrorIs(t, errAll, ErrNoCharacterData)
+			} else {
+				assert.NoError(t, err)
+				assert.NoError(t, errAll)
+			}
+		})
+	}
+}
+
+func TestConverter_NotPNG(t *testing.T) {
+	processor := FromBytes([]byte("definitely not an image, just some text"))
+	_, err := processor.Get()
+	assert.ErrorIs(t, err, ErrNotPNG)
+	assert.ErrorIs(t, processor.Err(), ErrNotPNG)
+}
+
+func TestRawCard_DecodeErrors(t *testing.T) {
+	invalidJSON := []byte(base64.StdEncoding.EncodeToString([]byte(`{"spec": "chara_card_v2", "data": [}`)))
+
+	tests := []struct {
+		name      string
+		charaData []byte
+		target    error
+	}{
+		{"Invalid base64", []byte("!!not*base64!!"), ErrInvalidBase64},
+		{"Invalid character JSON", invalidJSON, ErrInvalidCharacterJSON},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rawCard := &RawCard{RawCharaData: tt.charaData}
+
+			_, err := rawCard.Decode()
+			assert.ErrorIs(t, err, tt.target)
+			_, err = rawCard.DecodeStream()
+			assert.ErrorIs(t, err, tt.target)
+		})
+	}
+
+	t.Run("Cause of the invalid JSON", func(t *testing.T) {
+		_, err := (&RawCard{RawCharaData: invalidJSON}).Decode()
+		var invalid *InvalidCharacterJSONError
+		require.True(t, errors.As(err, &invalid))
+		assert.Error(t, invalid.Cause)
+	})
+}
+
+func TestConstructors_Errors(t *testing.T) {
+	t.Run("Missing file", func(t *testing.T) {
+		processor := FromFile(filepath.Join(t.TempDir(), "missing.png"))
+		assert.ErrorIs(t, processor.Err(), ErrFileOpen)
+		assert.ErrorIs(t, processor.Err(), os.ErrNotExist)
+	})
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/html":
+			w.Header().Set("Content-Type", "text/html")
+			w.Write([]byte("<html><body>Not found</body></html>"))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+	client := reqx.NewClient(reqx.Options{})
+
+	t.Run("Failed request", func(t *testing.T) {
+		processor := FromURL(client, server.URL+"/missing")
+		require.ErrorIs(t, processor.Err(), ErrFetch)
+
+		var fetchErr *FetchError
+		require.True(t, errors.As(processor.Err(), &fetchErr))
+		assert.Equal(t, server.URL+"/missing", fetchErr.URL)
+	})
+
+	t.Run("Response that is not an image", func(t *testing.T) {
+		processor := FromURL(client, server.URL+"/missing", server.URL+"/html")
+		require.ErrorIs(t, processor.Err(), ErrFetch)
+		require.ErrorIs(t, processor.Err(), ErrNotAnImage)
+
+		var fetchErr *FetchError
+		require.True(t, errors.As(processor.Err(), &fetchErr))
+		assert.Equal(t, server.URL+"/html", fetchErr.URL)
+		assert.Equal(t, http.StatusOK, fetchErr.Status)
+	})
+}
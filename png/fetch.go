@@ -0,0 +1,125 @@
+package png
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+)
+
+// Sniffing properties of the fetched responses
+const (
+	sniffSize         = 512            // Maximum number of bytes read for the preview of text payloads (same as http.DetectContentType)
+	snippetSize       = 120            // Maximum number of characters of the snippet preview
+	contentTypeHeader = "Content-Type" // Header declaring the media type of the response
+)
+
+// ErrNotAnImage is matched (errors.Is) by NotAnImageError
+var ErrNotAnImage = errors.New("response is not an image")
+
+// ErrResponseTooLarge is returned when a response exceeds SourceOptions.MaxDownloadBytes
+var ErrResponseTooLarge = errors.New("response too large")
+
+// NotAnImageError a response whose payload is text (e.g. an HTML error page served with status 200)
+type NotAnImageError struct {
+	URL            string // Fetched URL
+	ContentType    string // Declared Content-Type of the response
+	SnippetPreview string // Start of the payload (whitespace collapsed)
+}
+
+// Error returns the error message with the URL, content type and snippet preview
+func (e *NotAnImageError) Error() string {
+	return fmt.Sprintf("%s: %v (content type %q): %q", e.URL, ErrNotAnImage, e.ContentType, e.SnippetPreview)
+}
+
+// Is reports whether the target is ErrNotAnImage
+func (e *NotAnImageError) Is(target error) bool {
+	return target == ErrNotAnImage
+}
+
+// AttemptInfo outcome of fetching one of the URLs given to FromURL and FromURLContext
+type AttemptInfo struct {
+	URL         string // Fetched URL
+	ContentType string // Declared Content-Type of the response (empty if there was no response)
+	Err         error  // Failure reason (nil for the URL the processor reads from)
+}
+
+// attemptRecorder records the fetch attempts of a processor
+type attemptRecorder struct {
+	attempts []AttemptInfo
+}
+
+// Attempts returns a copy of the fetch attempts (nil if the processor was not created from URLs)
+func (r *attemptRecorder) Attempts() []AttemptInfo {
+	return slices.Clone(r.attempts)
+}
+
+// readCloser combines a reader with the closer of another reader
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
+
+// sniffResponse checks the response of the URL before it is decoded: the declared size must not exceed the
+// MaxDownloadBytes, and the start of the payload must not be text; returns the body to decode (sniffed bytes
+// included, bounded by the MaxDownloadBytes), or the failure reason (the body is closed)
+// Only the bytes needed anyway to detect the image are sniffed, so slow images do not stall the fallback
+func sniffResponse(url string, header http.Header, contentLength int64, body io.ReadCloser, opts SourceOptions) (io.ReadCloser, error) {
+	// Reject the responses declaring a size over the limit
+	if opts.MaxDownloadBytes > 0 && contentLength > opts.MaxDownloadBytes {
+		_ = body.Close()
+		return nil, fmt.Errorf("%w: %s declares %d bytes (maximum %d)", ErrResponseTooLarge, url, contentLength, opts.MaxDownloadBytes)
+	}
+
+	// Sniff the start of the payload (the size of the image header)
+	peek, err := readUpTo(body, fullIhdrSize)
+	if err != nil {
+		_ = body.Close()
+		return nil, err
+	}
+
+	// Reject text payloads (the preview is read from a larger start of the payload), unless the PNG signature follows
+	// the text (junk prepended to the image, see SourceOptions.MaxSignatureOffset)
+	if strings.HasPrefix(http.DetectContentType(peek), "text/") {
+		maxOffset := opts.maxSignatureOffset()
+		rest, _ := readUpTo(body, max(sniffSize, maxOffset+fullIhdrSize)-len(peek))
+		peek = append(peek, rest...)
+		if signatureOffset(peek, maxOffset) < 0 {
+			_ = body.Close()
+			return nil, &NotAnImageError{URL: url, ContentType: header.Get(contentTypeHeader), SnippetPreview: snippetPreview(peek[:min(len(peek), sniffSize)])}
+		}
+	}
+
+	// Put the sniffed bytes back in front of the body, and bound the body by the limit
+	body = readCloser{Reader: io.MultiReader(bytes.NewReader(peek), body), Closer: body}
+	if opts.MaxDownloadBytes > 0 {
+		body = &maxBytesReader{
+			ReadCloser: body,
+			remaining:  opts.MaxDownloadBytes,
+			err:        fmt.Errorf("%w: %s exceeds %d bytes", ErrResponseTooLarge, url, opts.MaxDownloadBytes),
+		}
+	}
+	return body, nil
+}
+
+// readUpTo reads up to size bytes (fewer if the reader ends first)
+func readUpTo(r io.Reader, size int) ([]byte, error) {
+	data := make([]byte, size)
+	n, err := io.ReadFull(r, data)
+	if errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, io.EOF) {
+		err = nil
+	}
+	return data[:n], err
+}
+
+// snippetPreview returns the start of a text payload with collapsed whitespace (at most snippetSize characters)
+func snippetPreview(payload []byte) string {
+	preview := strings.Join(strings.Fields(strings.ToValidUTF8(string(payload), "")), " ")
+	if runes := []rune(preview); len(runes) > snippetSize {
+		preview = string(runes[:snippetSize])
+	}
+	return preview
+}
@@ -0,0 +1,129 @@
+package png
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/r3dpixel/toolkit/reqx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFromURL_Sniffing(t *testing.T) {
+	pngBytes := createTestPNG(t, 16, 16)
+	client := reqx.NewClient(reqx.Options{})
+	errorPage := "<!DOCTYPE html>\n<html>\n  <head><title>Rate limited</title></head>\n  <body>Too many requests</body>\n</html>"
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/png":
+			w.Header().Set("Content-Type", "image/png")
+			w.Write(pngBytes)
+		case "/html":
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			w.Write([]byte(errorPage))
+		case "/mislabeled":
+			// HTML served as an image
+			w.Header().Set("Content-Type", "image/png")
+			w.Write([]byte(errorPage))
+		case "/stream":
+			// Chunked response without Content-Length
+			w.Header().Set("Content-Type", "image/png")
+			w.Write(pngBytes[:fullIhdrSize])
+			w.(http.Flusher).Flush()
+			w.Write(pngBytes[fullIhdrSize:])
+		}
+	}))
+	defer server.Close()
+
+	// fromURLWithLimit fetches the URL with the given download limit
+	fromURLWithLimit := func(limit int64, url string) Processor {
+		return FromURLWithOptions(context.Background(), client, SourceOptions{MaxDownloadBytes: limit}, url)
+	}
+
+	t.Run("HTML served with status 200", func(t *testing.T) {
+		processor := FromURL(client, server.URL+"/html")
+		require.ErrorIs(t, processor.Err(), ErrNotAnImage)
+
+		var notAnImage *NotAnImageError
+		require.True(t, errors.As(processor.Err(), &notAnImage))
+		assert.Equal(t, server.URL+"/html", notAnImage.URL)
+		assert.Equal(t, "text/html; charset=utf-8", notAnImage.ContentType)
+		assert.Equal(t, "<!DOCTYPE html> <html> <head><title>Rate limited</title></head> <body>Too many requests</body> </html>", notAnImage.SnippetPreview)
+	})
+
+	t.Run("HTML served as an image", func(t *testing.T) {
+		var notAnImage *NotAnImageError
+		require.True(t, errors.As(FromURL(client, server.URL+"/mislabeled").Err(), &notAnImage))
+		assert.Equal(t, "image/png", notAnImage.ContentType)
+	})
+
+	t.Run("Fallback records the attempts", func(t *testing.T) {
+		processor := FromURL(client, server.URL+"/html", server.URL+"/404", server.URL+"/png")
+		_, err := processor.Get()
+		require.NoError(t, err)
+
+		attempts := processor.Attempts()
+		require.Len(t, attempts, 3)
+		assert.ErrorIs(t, attempts[0].Err, ErrNotAnImage)
+		assert.Equal(t, "text/html; charset=utf-8", attempts[0].ContentType)
+		assert.Error(t, attempts[1].Err)
+		assert.Equal(t, server.URL+"/png", attempts[2].URL)
+		assert.Equal(t, "image/png", attempts[2].ContentType)
+		assert.NoError(t, attempts[2].Err)
+	})
+
+	t.Run("Every URL fails", func(t *testing.T) {
+		processor := FromURL(client, server.URL+"/html", server.URL+"/mislabeled")
+		assert.ErrorIs(t, processor.Err(), ErrNotAnImage)
+		assert.Len(t, processor.Attempts(), 2)
+	})
+
+	t.Run("Declared size over the limit", func(t *testing.T) {
+		processor := fromURLWithLimit(int64(len(pngBytes)-1), server.URL+"/png")
+		assert.ErrorIs(t, processor.Err(), ErrResponseTooLarge)
+		require.Len(t, processor.Attempts(), 1)
+		assert.ErrorIs(t, processor.Attempts()[0].Err, ErrResponseTooLarge)
+	})
+
+	t.Run("Streamed size over the limit", func(t *testing.T) {
+		processor := fromURLWithLimit(int64(len(pngBytes)-1), server.URL+"/stream")
+		require.NoError(t, processor.Err())
+		_, err := processor.Get()
+		assert.ErrorIs(t, err, ErrResponseTooLarge)
+	})
+
+	t.Run("Size within the limit", func(t *testing.T) {
+		for _, path := range []string{"/png", "/stream"} {
+			_, err := fromURLWithLimit(int64(len(pngBytes)), server.URL+path).Get()
+			assert.NoError(t, err, path)
+		}
+	})
+
+	t.Run("No attempts outside URLs", func(t *testing.T) {
+		assert.Nil(t, FromBytes(pngBytes).Attempts())
+	})
+}
+
+func TestSnippetPreview(t *testing.T) {
+	tests := []struct {
+		name     string
+		payload  string
+		expected string
+	}{
+		{"Empty", "", ""},
+		{"Collapsed whitespace", "  a \n\t b  ", "a b"},
+		{"Invalid UTF-8", "a\xffb", "ab"},
+		{"Truncated", strings.Repeat("é", snippetSize+10), strings.Repeat("é", snippetSize)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, snippetPreview([]byte(tt.payload)))
+		})
+	}
+}
@@ -0,0 +1,59 @@
+package png
+
+import (
+	"bytes"
+	"io"
+)
+
+// formatSniffSize number of bytes read to detect the image format
+const formatSniffSize = 12
+
+// Magic numbers of the detected image formats (PNG, JPEG, WebP and AVIF have their own checks)
+var (
+	gifMagics  = [][]byte{[]byte("GIF87a"), []byte("GIF89a")}
+	bmpMagic   = []byte("BM")
+	tiffMagics = [][]byte{{0x49, 0x49, 0x2A, 0x00}, {0x4D, 0x4D, 0x00, 0x2A}}
+)
+
+// DetectFormat detects the image format from the magic number at the start of the reader
+// Returns the format name (png, jpeg, webp, gif, avif, bmp or tiff; empty if unknown), and a reader replaying the
+// input from the start
+func DetectFormat(r io.Reader) (string, io.Reader, error) {
+	// Read the start of the input
+	peek, err := readUpTo(r, formatSniffSize)
+	if err != nil {
+		return "", nil, err
+	}
+	// Detect the format, and rewind the reader
+	return sniffFormat(peek), io.MultiReader(bytes.NewReader(peek), r), nil
+}
+
+// sniffFormat returns the format name of the image data from its magic number (empty if unknown)
+func sniffFormat(data []byte) string {
+	hasAnyPrefix := func(prefixes [][]byte) bool {
+		for _, prefix := range prefixes {
+			if bytes.HasPrefix(data, prefix) {
+				return true
+			}
+		}
+		return false
+	}
+
+	switch {
+	case bytes.HasPrefix(data, pngHeader):
+		return "png"
+	case isJPEG(data):
+		return "jpeg"
+	case isWebP(data):
+		return "webp"
+	case hasAnyPrefix(gifMagics):
+		return "gif"
+	case isAVIF(data):
+		return "avif"
+	case bytes.HasPrefix(data, bmpMagic):
+		return "bmp"
+	case hasAnyPrefix(tiffMagics):
+		return "tiff"
+	}
+	return ""
+}
@@ -0,0 +1,100 @@
+package png
+
+import (
+	"bytes"
+	"image"
+	"image/gif"
+	"io"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// createTestGIF creates a small GIF image
+func createTestGIF(t *testing.T) []byte {
+	t.Helper()
+	buf := new(bytes.Buffer)
+	require.NoError(t, gif.Encode(buf, image.NewGray(image.Rect(0, 0, 4, 4)), nil))
+	return buf.Bytes()
+}
+
+func TestDetectFormat(t *testing.T) {
+	webp, err := os.ReadFile("testdata/card.webp")
+	require.NoError(t, err)
+
+	tests := []struct {
+		name     string
+		data     []byte
+		expected string
+	}{
+		{"PNG", createTestPNG(t, 4, 4), "png"},
+		{"JPEG", createTestJPG(t), "jpeg"},
+		{"WebP", webp, "webp"},
+		{"GIF87a", []byte("GIF87a\x04\x00\x04\x00"), "gif"},
+		{"GIF89a", createTestGIF(t), "gif"},
+		{"AVIF", []byte("\x00\x00\x00\x1cftypavif\x00\x00\x00\x00"), "avif"},
+		{"AVIF sequence", []byte("\x00\x00\x00\x1cftypavis\x00\x00\x00\x00"), "avif"},
+		{"BMP", []byte("BM\x3a\x00\x00\x00\x00\x00\x00\x00\x36\x00"), "bmp"},
+		{"TIFF little-endian", []byte("II*\x00\x08\x00\x00\x00"), "tiff"},
+		{"TIFF big-endian", []byte("MM\x00*\x00\x00\x00\x08"), "tiff"},
+		{"Unknown", []byte("not an image at all"), ""},
+		{"Truncated", []byte("GIF8"), ""},
+		{"Empty", nil, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			format, r, err := DetectFormat(bytes.NewReader(tt.data))
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, format)
+
+			// The reader replays the whole input
+			replayed, err := io.ReadAll(r)
+			require.NoError(t, err)
+			assert.Equal(t, len(tt.data), len(replayed))
+			assert.True(t, bytes.Equal(tt.data, replayed))
+		})
+	}
+}
+
+func TestProcessor_SourceFormat(t *testing.T) {
+	t.Run("PNG", func(t *testing.T) {
+		processor := FromBytes(createTestPNG(t, 4, 4))
+		assert.Equal(t, "png", processor.SourceFormat())
+		rawCard, err := processor.Get()
+		require.NoError(t, err)
+		assert.False(t, rawCard.WasConverted)
+	})
+
+	t.Run("JPEG", func(t *testing.T) {
+		processor := FromBytes(createTestJPG(t))
+		assert.Equal(t, "jpeg", processor.SourceFormat())
+		rawCard, err := processor.Get()
+		require.NoError(t, err)
+		assert.True(t, rawCard.WasConverted)
+	})
+
+	t.Run("GIF", func(t *testing.T) {
+		processor := FromBytes(createTestGIF(t))
+		assert.Equal(t, "gif", processor.SourceFormat())
+		rawCard, err := processor.Get()
+		require.NoError(t, err)
+		assert.True(t, rawCard.WasConverted)
+	})
+
+	t.Run("Decoding failure", func(t *testing.T) {
+		processor := FromBytes([]byte("GIF89a truncated"))
+		_, err := processor.Get()
+		require.Error(t, err)
+		assert.Equal(t, "gif", processor.SourceFormat())
+	})
+
+	t.Run("Unknown", func(t *testing.T) {
+		processor := FromBytes([]byte("not an image at all"))
+		_, err := processor.Get()
+		require.Error(t, err)
+		assert.Empty(t, processor.SourceFormat())
+	})
+}
@@ -5,14 +5,21 @@ import (
 	"image"
 	"image/png"
 	"io"
+	"slices"
 
 	"github.com/sunshineplan/imgconv"
 )
 
 // pngData PNG image data
 type pngData struct {
-	Header []byte
-	Body   []byte
+	Header            []byte
+	Body              []byte
+	TextChunks        []TextChunk     // Non-chara `tEXt` chunks, in file order (rewritten by ToImage when changed)
+	scannedTextChunks []TextChunk     // Text chunks kept in place in the body (see textChunksChanged)
+	chunkFormat       ChunkFormat     // Format of the written chara chunks
+	injection         InjectionPolicy // Position of the written chara chunks
+	charaOffset       int             // Offset in the body where the first chara chunk of the scanned PNG was
+	animated          bool            // Set when the image has an acTL chunk (APNG)
 }
 
 // Width returns the width in pixels of the PNG
@@ -36,12 +43,37 @@ func (p *pngData) Thumbnail(size int) (image.Image, error) {
 	return resizeImage(imageSource, size), nil
 }
 
-// ScaleDown Scale down the png image
-func (p *pngData) ScaleDown(size int) error {
+// ScaleDown Scale down the png image (see ResizeOptions for animated images)
+func (p *pngData) ScaleDown(size int, opts ...ResizeOptions) error {
+	// Scale down the image
+	scaled, err := p.scaled(size, resizeOptions(opts))
+	if err != nil {
+		return err
+	}
+
+	// Replace the header and body
+	p.Header = scaled.Header
+	p.Body = scaled.Body
+	p.scannedTextChunks = nil
+
+	// Return nil (success)
+	return nil
+}
+
+// scaled returns a copy of the png data with the image scaled down to fit a square of the given size
+// The image is re-encoded, so only the critical chunks are kept (ancillary chunks are dropped)
+// Animated images fail with ErrAnimatedResizeUnsupported, unless ResizeOptions.Animated is set (the animation is
+// dropped)
+func (p *pngData) scaled(size int, opts ResizeOptions) (pngData, error) {
+	// Reject animated images
+	if p.animated && !opts.Animated {
+		return pngData{}, ErrAnimatedResizeUnsupported
+	}
+
 	// Decode the image
 	imageSource, err := p.Image()
 	if err != nil {
-		return err
+		return pngData{}, err
 	}
 
 	// Scale down the image
@@ -49,17 +81,19 @@ func (p *pngData) ScaleDown(size int) error {
 
 	// Encode the scaled-down image to PNG bytes
 	writer := new(bytes.Buffer)
-	err = png.Encode(writer, downScaledImageSource)
-	if err != nil {
-		return err
+	if err := png.Encode(writer, downScaledImageSource); err != nil {
+		return pngData{}, err
 	}
 
-	// Extract the header and body from the writer
-	p.Header = writer.Next(headerSize + ihdrSize)
-	p.Body = writer.Bytes()
-
-	// Return nil (success)
-	return nil
+	// Extract the header and body from the writer (the text chunks, the chunk format and the injection policy are
+	// kept; the original chara chunk position does not apply to the new body)
+	return pngData{
+		Header:      writer.Next(headerSize + ihdrSize),
+		Body:        writer.Bytes(),
+		TextChunks:  slices.Clone(p.TextChunks),
+		chunkFormat: p.chunkFormat,
+		injection:   p.injection,
+	}, nil
 }
 
 // Image FromBytes just the image from the raw context
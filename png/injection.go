@@ -0,0 +1,49 @@
+package png
+
+import "encoding/binary"
+
+// InjectionPolicy defines where ToImage writes the chara chunks (and the non-chara `tEXt` chunks) in the PNG
+type InjectionPolicy int
+
+// InjectionPolicy values
+const (
+	InjectAfterIHDR          InjectionPolicy = iota // Right after the IHDR chunk, before the image body (the default)
+	InjectBeforeIEND                                // After the image data, right before the IEND chunk
+	PreserveOriginalPosition                        // Where the first chara chunk of the scanned PNG was (after IHDR otherwise)
+)
+
+// InjectionPolicy sets where the chara chunks are written by ToImage (defaults to InjectAfterIHDR)
+func (rc *RawCard) InjectionPolicy(policy InjectionPolicy) *RawCard {
+	rc.injection = policy
+	return rc
+}
+
+// InjectionPolicy sets where the chara chunks are written by ToImage (see RawCard.InjectionPolicy)
+func (cc *CharacterCard) InjectionPolicy(policy InjectionPolicy) *CharacterCard {
+	cc.injection = policy
+	return cc
+}
+
+// injectionOffset returns the offset in the body where the chara chunks are written, following the injection policy
+func (p *pngData) injectionOffset() int {
+	switch p.injection {
+	case InjectBeforeIEND:
+		return iendOffset(p.Body)
+	case PreserveOriginalPosition:
+		return min(p.charaOffset, len(p.Body))
+	default:
+		return 0
+	}
+}
+
+// iendOffset returns the offset of the IEND chunk in the body (the end of the body if there is none)
+func iendOffset(body []byte) int {
+	offset := 0
+	for len(body)-offset >= chunkHeaderSize {
+		if binary.BigEndian.Uint32(body[offset+chunkLengthSize:]) == chunkIENDTypeCode {
+			return offset
+		}
+		offset += chunkHeaderSize + int(binary.BigEndian.Uint32(body[offset:]))
+	}
+	return len(body)
+}
@@ -0,0 +1,99 @@
+package png
+
+import (
+	"bytes"
+	"encoding/binary"
+	"slices"
+	"testing"
+
+	"github.com/r3dpixel/card-parser/character"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// chunkTIMETypeCode discriminator 'tIME' (uint32), used as an ancillary chunk by the tests
+const chunkTIMETypeCode uint32 = 0x74494D45
+
+// chunkTypes returns the types of the chunks following the IHDR chunk of a PNG
+func chunkTypes(t *testing.T, data []byte) []string {
+	t.Helper()
+	var types []string
+	for _, chunk := range splitChunks(t, data) {
+		types = append(types, string(binary.BigEndian.AppendUint32(nil, chunk.typeCode)))
+	}
+	return types
+}
+
+// createMidCharaPNG creates a PNG with the chara chunk between the image data and a tIME chunk
+func createMidCharaPNG(t *testing.T, sheet *character.Sheet) []byte {
+	t.Helper()
+	base := createTestPNG(t, 4, 4)
+	chunks := splitChunks(t, base)
+	require.Len(t, chunks, 2)
+	chara := typedChunk(chunkTextTypeCode, chunkKeywords()[sheet.Revision], encodeCardData(t, sheet))
+	return slices.Concat(base[:fullIhdrSize], chunks[0].raw, chara, typedChunk(chunkTIMETypeCode, make([]byte, 7)), chunks[1].raw)
+}
+
+func TestRawCard_InjectionPolicy(t *testing.T) {
+	sheet := createSheet(character.RevisionV2, "Injected")
+	input := createMidCharaPNG(t, sheet)
+	require.Equal(t, []string{"IDAT", "tEXt", "tIME", "IEND"}, chunkTypes(t, input))
+
+	tests := []struct {
+		name     string
+		policy   InjectionPolicy
+		expected []string
+	}{
+		{name: "After IHDR", policy: InjectAfterIHDR, expected: []string{"tEXt", "IDAT", "tIME", "IEND"}},
+		{name: "Before IEND", policy: InjectBeforeIEND, expected: []string{"IDAT", "tIME", "tEXt", "IEND"}},
+		{name: "Original position", policy: PreserveOriginalPosition, expected: []string{"IDAT", "tEXt", "tIME", "IEND"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rawCard, err := FromBytes(input).Get()
+			require.NoError(t, err)
+
+			// Raw card
+			output, err := rawCard.InjectionPolicy(tt.policy).ToBytes()
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, chunkTypes(t, output))
+
+			// Decoded card (encoded and streamed)
+			characterCard, err := rawCard.Decode()
+			require.NoError(t, err)
+			encoded, err := characterCard.ToBytes()
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, chunkTypes(t, encoded))
+			streamed := new(bytes.Buffer)
+			require.NoError(t, characterCard.EncodeStream(streamed))
+			assert.Equal(t, tt.expected, chunkTypes(t, streamed.Bytes()))
+
+			// Round trip
+			reparsed, err := FromBytes(output).Get()
+			require.NoError(t, err)
+			decoded, err := reparsed.Decode()
+			require.NoError(t, err)
+			assert.Equal(t, "Injected", string(decoded.Name))
+		})
+	}
+
+	t.Run("Original position without a chara chunk", func(t *testing.T) {
+		card, err := PlaceholderCharacterCard(4)
+		require.NoError(t, err)
+		card.RawCharaData = encodeCardData(t, sheet)
+		output, err := card.InjectionPolicy(PreserveOriginalPosition).ToBytes()
+		require.NoError(t, err)
+		assert.Equal(t, "tEXt", chunkTypes(t, output)[0])
+	})
+
+	t.Run("Original position of a scaled card", func(t *testing.T) {
+		rawCard, err := FromBytes(input).Get()
+		require.NoError(t, err)
+		resized, err := rawCard.InjectionPolicy(PreserveOriginalPosition).Resized(2)
+		require.NoError(t, err)
+		output, err := resized.ToBytes()
+		require.NoError(t, err)
+		assert.Equal(t, "tEXt", chunkTypes(t, output)[0])
+	})
+}
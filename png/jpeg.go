@@ -0,0 +1,351 @@
+package png
+
+import (
+	"bytes"
+	"crypto/md5"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/xml"
+	"image/jpeg"
+	"io"
+	"strings"
+
+	"github.com/r3dpixel/card-parser/character"
+)
+
+// JPEG markers and segment sizes in bytes
+const (
+	jpegMarkerPrefix byte = 0xFF // Prefix of every JPEG marker
+	jpegSOI          byte = 0xD8 // Start of image
+	jpegEOI          byte = 0xD9 // End of image
+	jpegSOS          byte = 0xDA // Start of scan (the entropy-coded data follows, no metadata segment after it)
+	jpegAPP1         byte = 0xE1 // Application segment 1 (Exif, XMP)
+	jpegRST0         byte = 0xD0 // First restart marker (standalone, up to RST7)
+	jpegRST7         byte = 0xD7 // Last restart marker (standalone)
+	jpegTEM          byte = 0x01 // Temporary marker (standalone)
+
+	jpegMarkerSize     int = 2                       // Size of a marker (prefix and code)
+	jpegLengthSize     int = 2                       // Size of the segment length (the length includes itself)
+	jpegMaxSegmentSize int = 0xFFFF - jpegLengthSize // Maximum size of the payload of a segment
+	xmpGUIDSize        int = 32                      // Size of the GUID of the extended XMP (hex MD5 digest)
+	xmpExtensionHeader     = xmpGUIDSize + 4 + 4     // GUID, full length, and offset of an extended XMP chunk
+
+	xmpIdentifier          = "http://ns.adobe.com/xap/1.0/\x00"       // Identifier of the main XMP segment
+	xmpExtensionIdentifier = "http://ns.adobe.com/xmp/extension/\x00" // Identifier of the extended XMP segments
+	xmpNoteNamespace       = "http://ns.adobe.com/xmp/note/"          // Namespace of the HasExtendedXMP property
+	xmpPacketID            = "W5M0MpCehiHzreSzNTczkc9d"               // Identifier of the xpacket wrapper
+
+	xmpMaxPacketSize = jpegMaxSegmentSize - len(xmpIdentifier)                               // Maximum size of the main XMP packet
+	xmpMaxChunkSize  = jpegMaxSegmentSize - len(xmpExtensionIdentifier) - xmpExtensionHeader // Maximum size of an extended XMP chunk
+)
+
+// XMPNamespace is the XMP namespace of the chara properties of JPEG cards: every property is named after the chara
+// keyword of the PNG text chunks (chara, ccv3) and holds the same base64 chara data
+const XMPNamespace = "https://github.com/r3dpixel/card-parser/xmp/1.0/"
+
+// The standard JPEG header (SOI marker followed by the prefix of the next marker)
+var jpegHeader = []byte{jpegMarkerPrefix, jpegSOI, jpegMarkerPrefix}
+
+// isJPEG checks if the data is a JPEG image (SOI marker)
+func isJPEG(data []byte) bool {
+	return bytes.HasPrefix(data, jpegHeader)
+}
+
+// ToJPEG writes the RawCard as a JPEG image (with the given quality, 1 to 100) to the provided writer
+// The pixels are re-encoded as JPEG (without alpha), and the chara data is stored in the XMP metadata (see
+// XMPNamespace), one property for each of the given revisions (see ToImage); the non-chara text chunks are not kept
+// Chara data larger than an XMP segment is stored in the extended XMP segments
+func (rc *RawCard) ToJPEG(w io.Writer, quality int, revisions ...character.Revision) error {
+	// Decode the image
+	img, err := rc.Image()
+	if err != nil {
+		return err
+	}
+
+	// Encode the image to JPEG
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: quality}); err != nil {
+		return err
+	}
+
+	// Collect the chara data of each revision
+	payloads, err := rc.charaPayloads(revisions...)
+	if err != nil {
+		return err
+	}
+
+	// Write the SOI marker first
+	encoded := buf.Bytes()
+	if _, err := w.Write(encoded[:jpegMarkerSize]); err != nil {
+		return err
+	}
+
+	// Write the XMP segments
+	if err := writeXMPSegments(w, payloads); err != nil {
+		return err
+	}
+
+	// Write the rest of the image
+	_, err = w.Write(encoded[jpegMarkerSize:])
+	return err
+}
+
+// ToJPEG encodes the sheet and writes the CharacterCard as a JPEG image to the provided writer (see RawCard.ToJPEG)
+// Requesting revisions for a card without a sheet returns ErrNoSheet (without revisions only the image is written)
+func (cc *CharacterCard) ToJPEG(w io.Writer, quality int, revisions ...character.Revision) error {
+	// Chara data was explicitly requested, but there is no sheet
+	if cc.Sheet == nil && len(revisions) > 0 {
+		return ErrNoSheet
+	}
+
+	// Encode the sheet into a RawCard
+	rawCard, err := cc.Encode()
+	if err != nil {
+		return err
+	}
+
+	// Write the RawCard
+	return rawCard.ToJPEG(w, quality, revisions...)
+}
+
+// writeXMPSegments writes the chara data as XMP properties (nothing if there is no chara data)
+// The properties are written in the main XMP packet if it fits in a segment, otherwise in the extended XMP packet
+func writeXMPSegments(w io.Writer, payloads []charaPayload) error {
+	// If there is no chara data, there are no segments
+	if len(payloads) == 0 {
+		return nil
+	}
+
+	// Write the chara properties in the main packet, if it fits
+	properties := xmpCharaProperties(payloads)
+	mainPacket := xmpPacket(properties, true)
+	if len(mainPacket) <= xmpMaxPacketSize {
+		return writeJPEGSegment(w, jpegAPP1, []byte(xmpIdentifier), mainPacket)
+	}
+
+	// Otherwise, point the main packet to the extended packet (identified by its MD5 digest)
+	extendedPacket := xmpPacket(properties, false)
+	digest := md5.Sum(extendedPacket)
+	guid := strings.ToUpper(hex.EncodeToString(digest[:]))
+	note := ` xmlns:xmpNote="` + xmpNoteNamespace + `" xmpNote:HasExtendedXMP="` + guid + `"`
+	if err := writeJPEGSegment(w, jpegAPP1, []byte(xmpIdentifier), xmpPacket(note, true)); err != nil {
+		return err
+	}
+
+	// Write the extended packet in chunks
+	header := make([]byte, xmpExtensionHeader)
+	copy(header, guid)
+	binary.BigEndian.PutUint32(header[xmpGUIDSize:], uint32(len(extendedPacket)))
+	for offset := 0; offset < len(extendedPacket); offset += xmpMaxChunkSize {
+		binary.BigEndian.PutUint32(header[xmpGUIDSize+4:], uint32(offset))
+		chunk := extendedPacket[offset:min(offset+xmpMaxChunkSize, len(extendedPacket))]
+		if err := writeJPEGSegment(w, jpegAPP1, []byte(xmpExtensionIdentifier), header, chunk); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// xmpCharaProperties returns the XML attributes of the chara properties (with the declaration of their namespace)
+func xmpCharaProperties(payloads []charaPayload) string {
+	var properties strings.Builder
+	properties.WriteString(` xmlns:card="` + XMPNamespace + `"`)
+	for _, payload := range payloads {
+		properties.WriteString(" card:" + xmpPropertyName(payload.revision) + `="`)
+		_ = xml.EscapeText(&properties, payload.charaData)
+		properties.WriteString(`"`)
+	}
+	return properties.String()
+}
+
+// xmpPacket returns an XMP packet with a single description holding the given XML attributes
+// Only the main packet is wrapped in the xpacket processing instructions
+func xmpPacket(attributes string, wrapped bool) []byte {
+	packet := `<x:xmpmeta xmlns:x="adobe:ns:meta/"><rdf:RDF xmlns:rdf="http://www.w3.org/1999/02/22-rdf-syntax-ns#">` +
+		`<rdf:Description rdf:about=""` + attributes + `/></rdf:RDF></x:xmpmeta>`
+	if wrapped {
+		packet = "<?xpacket begin=\"\uFEFF\" id=\"" + xmpPacketID + "\"?>" + packet + `<?xpacket end="w"?>`
+	}
+	return []byte(packet)
+}
+
+// xmpPropertyName returns the name of the chara property of the revision (its chara keyword)
+func xmpPropertyName(revision character.Revision) string {
+	return string(bytes.TrimSuffix(chunkKeywords()[keywordRevision(revision)], []byte{0x00}))
+}
+
+// writeJPEGSegment writes a JPEG segment (marker, length, and the payload parts) to the JPEG stream
+func writeJPEGSegment(w io.Writer, marker byte, parts ...[]byte) error {
+	// Compute the segment length (including the length itself)
+	length := jpegLengthSize
+	for _, part := range parts {
+		length += len(part)
+	}
+
+	// Write the marker and the length
+	if _, err := w.Write([]byte{jpegMarkerPrefix, marker, byte(length >> 8), byte(length)}); err != nil {
+		return err
+	}
+
+	// Write the payload
+	for _, part := range parts {
+		if _, err := w.Write(part); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// jpegScanner scans the metadata segments of a JPEG image for the XMP chara properties
+type jpegScanner struct {
+	mainPackets [][]byte          // Main XMP packets, in file order
+	extended    map[string][]byte // Extended XMP packets by GUID (assembled from their chunks)
+	received    map[string]int    // Number of bytes received for each extended XMP packet
+}
+
+// scanJPEG returns a RawCard for every chara property in the XMP metadata of the JPEG image (in file order)
+// Malformed segments and XMP packets are skipped, as well as incomplete extended XMP packets
+func scanJPEG(data []byte) []*RawCard {
+	scanner := &jpegScanner{extended: map[string][]byte{}, received: map[string]int{}}
+	scanner.scanSegments(data)
+	return scanner.rawCards()
+}
+
+// scanSegments collects the XMP packets of the metadata segments (up to the start of scan)
+func (s *jpegScanner) scanSegments(data []byte) {
+	for offset := len(jpegHeader) - 1; offset+1 < len(data); {
+		// Find the next marker (skipping the fill bytes)
+		if data[offset] != jpegMarkerPrefix {
+			return
+		}
+		marker := data[offset+1]
+		offset += 2
+		switch {
+		case marker == jpegMarkerPrefix:
+			offset--
+			continue
+		case marker == jpegSOS || marker == jpegEOI:
+			return
+		case marker == jpegTEM || marker >= jpegRST0 && marker <= jpegRST7:
+			continue
+		}
+
+		// Read the segment payload
+		if offset+jpegLengthSize > len(data) {
+			return
+		}
+		length := int(binary.BigEndian.Uint16(data[offset:]))
+		if length < jpegLengthSize || offset+length > len(data) {
+			return
+		}
+		payload := data[offset+jpegLengthSize : offset+length]
+		offset += length
+
+		// Collect the XMP packets
+		if marker == jpegAPP1 {
+			s.collectPacket(payload)
+		}
+	}
+}
+
+// collectPacket collects the main XMP packet, or the chunk of an extended XMP packet, of an APP1 payload
+func (s *jpegScanner) collectPacket(payload []byte) {
+	// Main XMP packet
+	if packet, ok := bytes.CutPrefix(payload, []byte(xmpIdentifier)); ok {
+		s.mainPackets = append(s.mainPackets, packet)
+		return
+	}
+
+	// Extended XMP chunk (GUID, full length, offset, chunk)
+	chunk, ok := bytes.CutPrefix(payload, []byte(xmpExtensionIdentifier))
+	if !ok || len(chunk) < xmpExtensionHeader {
+		return
+	}
+	guid := string(chunk[:xmpGUIDSize])
+	fullLength := int(binary.BigEndian.Uint32(chunk[xmpGUIDSize:]))
+	offset := int(binary.BigEndian.Uint32(chunk[xmpGUIDSize+4:]))
+	chunk = chunk[xmpExtensionHeader:]
+
+	// Copy the chunk at its offset in the packet
+	packet, exists := s.extended[guid]
+	if !exists {
+		packet = make([]byte, fullLength)
+		s.extended[guid] = packet
+	}
+	if len(packet) != fullLength || offset+len(chunk) > fullLength {
+		return
+	}
+	copy(packet[offset:], chunk)
+	s.received[guid] += len(chunk)
+}
+
+// rawCards returns a RawCard for every chara property of the main XMP packets (and their extended XMP packets)
+func (s *jpegScanner) rawCards() []*RawCard {
+	var rawCards []*RawCard
+	for _, packet := range s.mainPackets {
+		properties, guid := parseXMPProperties(packet)
+		if extended, ok := s.extended[guid]; ok && s.received[guid] == len(extended) {
+			extendedProperties, _ := parseXMPProperties(extended)
+			properties = append(properties, extendedProperties...)
+		}
+		for _, property := range properties {
+			rawCards = append(rawCards, &RawCard{
+				Revision:     property.revision,
+				RawCharaData: property.charaData,
+				Encoding:     detectBase64(property.charaData),
+			})
+		}
+	}
+	return rawCards
+}
+
+// parseXMPProperties returns the chara properties of the XMP packet (attributes or elements), and the GUID of its
+// extended XMP packet (empty if there is none); a malformed packet yields the properties parsed before the error
+func parseXMPProperties(packet []byte) ([]charaPayload, string) {
+	var payloads []charaPayload
+	var guid string
+
+	// Walk through the XML tokens
+	decoder := xml.NewDecoder(bytes.NewReader(packet))
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			return payloads, guid
+		}
+		element, ok := token.(xml.StartElement)
+		if !ok {
+			continue
+		}
+
+		// Chara properties written as attributes (and the GUID of the extended packet)
+		for _, attr := range element.Attr {
+			if revision, ok := xmpPropertyRevision(attr.Name); ok {
+				payloads = append(payloads, charaPayload{revision: revision, charaData: []byte(strings.TrimSpace(attr.Value))})
+			}
+			if attr.Name.Space == xmpNoteNamespace && attr.Name.Local == "HasExtendedXMP" {
+				guid = attr.Value
+			}
+		}
+
+		// Chara properties written as elements
+		if revision, ok := xmpPropertyRevision(element.Name); ok {
+			var value string
+			if decoder.DecodeElement(&value, &element) == nil {
+				payloads = append(payloads, charaPayload{revision: revision, charaData: []byte(strings.TrimSpace(value))})
+			}
+		}
+	}
+}
+
+// xmpPropertyRevision returns the revision of the chara property with the given name
+func xmpPropertyRevision(name xml.Name) (character.Revision, bool) {
+	if name.Space != XMPNamespace {
+		return character.RevisionV2, false
+	}
+	for revision := range chunkKeywords() {
+		if name.Local == xmpPropertyName(revision) {
+			return revision, true
+		}
+	}
+	return character.RevisionV2, false
+}
@@ -0,0 +1,178 @@
+package png
+
+import (
+	"bytes"
+	"encoding/base64"
+	"image"
+	"image/color"
+	"image/png"
+	"testing"
+
+	"github.com/r3dpixel/card-parser/character"
+	"github.com/r3dpixel/card-parser/property"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// createGradientCard returns a character card with a gradient image of the given size, and a sheet named after the revision
+func createGradientCard(t *testing.T, size int, revision character.Revision) *CharacterCard {
+	t.Helper()
+	img := image.NewRGBA(image.Rect(0, 0, size, size))
+	for y := range size {
+		for x := range size {
+			img.Set(x, y, color.RGBA{R: uint8(x * 255 / size), G: uint8(y * 255 / size), B: 128, A: 255})
+		}
+	}
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, img))
+
+	rawCard, err := FromBytes(buf.Bytes()).Get()
+	require.NoError(t, err)
+	return &CharacterCard{pngData: rawCard.pngData, Sheet: createSheet(revision, "JPEG "+revision.String())}
+}
+
+func TestCharacterCard_ToJPEG(t *testing.T) {
+	t.Run("PNG to JPEG to sheet", func(t *testing.T) {
+		card := createGradientCard(t, 64, character.RevisionV3)
+		var jpegData bytes.Buffer
+		require.NoError(t, card.ToJPEG(&jpegData, 90))
+		require.True(t, isJPEG(jpegData.Bytes()))
+
+		// The sheet survives
+		rawCard, err := FromBytes(jpegData.Bytes()).Get()
+		require.NoError(t, err)
+		assert.Equal(t, character.RevisionV3, rawCard.Revision)
+		decoded, err := rawCard.Decode()
+		require.NoError(t, err)
+		assert.Equal(t, property.String("JPEG v3"), decoded.Name)
+		assert.True(t, card.DeepEquals(decoded.Sheet))
+
+		// The pixels are within the JPEG tolerance
+		expected, err := card.Image()
+		require.NoError(t, err)
+		actual, err := rawCard.Image()
+		require.NoError(t, err)
+		require.Equal(t, expected.Bounds(), actual.Bounds())
+		var totalDiff, pixels int
+		for y := range 64 {
+			for x := range 64 {
+				er, eg, eb, _ := expected.At(x, y).RGBA()
+				ar, ag, ab, _ := actual.At(x, y).RGBA()
+				totalDiff += absDiff(er>>8, ar>>8) + absDiff(eg>>8, ag>>8) + absDiff(eb>>8, ab>>8)
+				pixels++
+			}
+		}
+		assert.Less(t, float64(totalDiff)/float64(pixels*3), 4.0)
+	})
+
+	t.Run("Every revision", func(t *testing.T) {
+		card := createGradientCard(t, 8, character.RevisionV2)
+		var jpegData bytes.Buffer
+		require.NoError(t, card.ToJPEG(&jpegData, 75, character.RevisionV2, character.RevisionV3))
+
+		rawCards, err := FromBytes(jpegData.Bytes()).GetAll()
+		require.NoError(t, err)
+		require.Len(t, rawCards, 2)
+		assert.Equal(t, character.RevisionV2, rawCards[0].Revision)
+		assert.Equal(t, character.RevisionV3, rawCards[1].Revision)
+
+		first, err := FromBytes(jpegData.Bytes()).First().Get()
+		require.NoError(t, err)
+		assert.Equal(t, character.RevisionV2, first.Revision)
+
+		latest, err := FromBytes(jpegData.Bytes()).LastVersion().Get()
+		require.NoError(t, err)
+		assert.Equal(t, character.RevisionV3, latest.Revision)
+		decoded, err := latest.Decode()
+		require.NoError(t, err)
+		assert.Equal(t, character.SpecV3, decoded.Spec)
+	})
+
+	t.Run("No sheet", func(t *testing.T) {
+		card := &CharacterCard{pngData: createGradientCard(t, 8, character.RevisionV2).pngData}
+		var jpegData bytes.Buffer
+		require.NoError(t, card.ToJPEG(&jpegData, 75))
+		assert.NotContains(t, jpegData.String(), XMPNamespace)
+
+		rawCard, err := FromBytes(jpegData.Bytes()).Get()
+		require.NoError(t, err)
+		assert.Empty(t, rawCard.RawCharaData)
+
+		assert.ErrorIs(t, card.ToJPEG(&jpegData, 75, character.RevisionV3), ErrNoSheet)
+	})
+}
+
+func TestRawCard_ToJPEG(t *testing.T) {
+	t.Run("Extended XMP", func(t *testing.T) {
+		rawCard := createStreamCard(t, 256<<10)
+		require.Greater(t, len(rawCard.RawCharaData), 3*xmpMaxChunkSize)
+		var jpegData bytes.Buffer
+		require.NoError(t, rawCard.ToJPEG(&jpegData, 80))
+
+		decoded, err := FromBytes(jpegData.Bytes()).Get()
+		require.NoError(t, err)
+		assert.Equal(t, rawCard.RawCharaData, decoded.RawCharaData)
+		assert.Equal(t, character.RevisionV3, decoded.Revision)
+	})
+
+	t.Run("Pipe writes a PNG card", func(t *testing.T) {
+		rawCard, err := createGradientCard(t, 8, character.RevisionV3).Encode()
+		require.NoError(t, err)
+		var jpegData, pngData bytes.Buffer
+		require.NoError(t, rawCard.ToJPEG(&jpegData, 90))
+		require.NoError(t, FromBytes(jpegData.Bytes()).Pipe(&pngData, nil))
+
+		decoded, err := FromBytes(pngData.Bytes()).Get()
+		require.NoError(t, err)
+		assert.Equal(t, rawCard.RawCharaData, decoded.RawCharaData)
+	})
+}
+
+func TestScanJPEG(t *testing.T) {
+	charaData := base64.StdEncoding.EncodeToString([]byte(`{"name":"Element"}`))
+	packet := `<x:xmpmeta xmlns:x="adobe:ns:meta/"><rdf:RDF xmlns:rdf="http://www.w3.org/1999/02/22-rdf-syntax-ns#">` +
+		`<rdf:Description rdf:about="" xmlns:card="` + XMPNamespace + `"><card:chara>` + charaData + `</card:chara>` +
+		`</rdf:Description></rdf:RDF></x:xmpmeta>`
+	jpegImage := createTestJPG(t)
+
+	// withSegment returns the image with the APP1 segment after the SOI marker
+	withSegment := func(parts ...[]byte) []byte {
+		var buf bytes.Buffer
+		buf.Write(jpegImage[:jpegMarkerSize])
+		require.NoError(t, writeJPEGSegment(&buf, jpegAPP1, parts...))
+		buf.Write(jpegImage[jpegMarkerSize:])
+		return buf.Bytes()
+	}
+
+	tests := []struct {
+		name     string
+		data     []byte
+		expected int
+	}{
+		{"No metadata", jpegImage, 0},
+		{"Property element", withSegment([]byte(xmpIdentifier), []byte(packet)), 1},
+		{"Foreign XMP", withSegment([]byte(xmpIdentifier), []byte(`<x:xmpmeta xmlns:x="adobe:ns:meta/"/>`)), 0},
+		{"Malformed XMP", withSegment([]byte(xmpIdentifier), []byte(`<x:xmpmeta`)), 0},
+		{"Incomplete extended XMP", withSegment([]byte(xmpExtensionIdentifier), make([]byte, xmpExtensionHeader)), 0},
+		{"Truncated segment", withSegment([]byte(xmpIdentifier), []byte(packet))[:40], 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rawCards := scanJPEG(tt.data)
+			require.Len(t, rawCards, tt.expected)
+			for _, rawCard := range rawCards {
+				assert.Equal(t, []byte(charaData), rawCard.RawCharaData)
+				assert.Equal(t, character.RevisionV2, rawCard.Revision)
+			}
+		})
+	}
+}
+
+// absDiff returns the absolute difference of two color components
+func absDiff(a, b uint32) int {
+	if a > b {
+		return int(a - b)
+	}
+	return int(b - a)
+}
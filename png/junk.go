@@ -0,0 +1,29 @@
+package png
+
+import (
+	"bytes"
+
+	"github.com/r3dpixel/toolkit/bytex"
+)
+
+// DefaultMaxSignatureOffset is the default maximum number of junk bytes skipped before the PNG signature (see
+// SourceOptions.MaxSignatureOffset)
+const DefaultMaxSignatureOffset = bytex.KiB
+
+// maxSignatureOffset returns the maximum number of junk bytes skipped before the PNG signature (0 if the search is
+// disabled)
+func (o SourceOptions) maxSignatureOffset() int {
+	if o.MaxSignatureOffset == 0 {
+		return DefaultMaxSignatureOffset
+	}
+	return max(o.MaxSignatureOffset, 0)
+}
+
+// signatureOffset returns the offset of the PNG signature in the data, searched within maxOffset bytes of the start
+// (-1 if not found)
+func signatureOffset(data []byte, maxOffset int) int {
+	if maxOffset <= 0 {
+		return -1
+	}
+	return bytes.Index(data[:min(len(data), maxOffset+headerSize)], pngHeader)
+}
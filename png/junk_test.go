@@ -0,0 +1,105 @@
+package png
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/r3dpixel/toolkit/reqx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProcessor_JunkBytes(t *testing.T) {
+	cleanPNG := injectSingleChunk(t, createTestPNG(t, 4, 4), testCards.smallV2, false)
+	clean, err := FromBytes(cleanPNG).Get()
+	require.NoError(t, err)
+	cleanOutput, err := clean.ToBytes()
+	require.NoError(t, err)
+
+	bom := []byte{0xEF, 0xBB, 0xBF}
+	html := []byte("<html><body>Proxy banner</body></html>\n")
+	trailing := bytes.Repeat([]byte("garbage!"), 13)[:100]
+
+	tests := []struct {
+		name     string
+		prefix   []byte
+		trailing []byte
+		deepScan bool
+	}{
+		{"BOM prefix", bom, nil, false},
+		{"HTML prefix", html, nil, false},
+		{"Trailing junk", nil, trailing, false},
+		{"Trailing junk with deep scan", nil, trailing, true},
+		{"Prefix and trailing junk", bom, trailing, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := slices.Concat(tt.prefix, cleanPNG, tt.trailing)
+			processor := FromBytes(data)
+			if tt.deepScan {
+				processor.LastLongest()
+			}
+			assert.Equal(t, "png", processor.SourceFormat())
+
+			// The junk is skipped, and the output is clean
+			rawCard, err := processor.Get()
+			require.NoError(t, err)
+			assert.Equal(t, len(tt.prefix), rawCard.SkippedPrefix)
+			assert.Nil(t, rawCard.TrailingData)
+			assert.Equal(t, clean.RawCharaData, rawCard.RawCharaData)
+			assert.Equal(t, clean.Body, rawCard.Body)
+			output, err := rawCard.ToBytes()
+			require.NoError(t, err)
+			assert.Equal(t, cleanOutput, output)
+
+			// The piped image is clean too
+			var piped bytes.Buffer
+			require.NoError(t, FromBytes(data).Pipe(&piped, nil))
+			var cleanPiped bytes.Buffer
+			require.NoError(t, FromBytes(cleanPNG).Pipe(&cleanPiped, nil))
+			assert.Equal(t, cleanPiped.Bytes(), piped.Bytes())
+
+			// The trailing data is kept on request
+			rawCard, err = FromBytes(data).KeepTrailingData().Get()
+			require.NoError(t, err)
+			assert.Equal(t, tt.trailing, rawCard.TrailingData)
+			assert.Equal(t, clean.Body, rawCard.Body)
+		})
+	}
+
+	t.Run("Signature too far", func(t *testing.T) {
+		data := slices.Concat(bytes.Repeat([]byte(" "), DefaultMaxSignatureOffset+1), cleanPNG)
+		_, err := FromBytes(data).Get()
+		assert.ErrorIs(t, err, ErrNotPNG)
+	})
+
+	t.Run("Larger search", func(t *testing.T) {
+		data := slices.Concat(bytes.Repeat([]byte(" "), DefaultMaxSignatureOffset+1), cleanPNG)
+		rawCard, err := FromBytes(data, SourceOptions{MaxSignatureOffset: 2 * DefaultMaxSignatureOffset}).Get()
+		require.NoError(t, err)
+		assert.Equal(t, DefaultMaxSignatureOffset+1, rawCard.SkippedPrefix)
+	})
+
+	t.Run("Search disabled", func(t *testing.T) {
+		_, err := FromBytes(slices.Concat(bom, cleanPNG), SourceOptions{MaxSignatureOffset: -1}).Get()
+		assert.ErrorIs(t, err, ErrNotPNG)
+	})
+
+	t.Run("Junk prepended to a download", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "image/png")
+			w.Write(slices.Concat(html, []byte(strings.Repeat("<p>padding</p>", 40)), cleanPNG))
+		}))
+		defer server.Close()
+
+		rawCard, err := FromURL(reqx.NewClient(reqx.Options{}), server.URL).Get()
+		require.NoError(t, err)
+		assert.Equal(t, len(html)+40*len("<p>padding</p>"), rawCard.SkippedPrefix)
+		assert.Equal(t, clean.RawCharaData, rawCard.RawCharaData)
+	})
+}
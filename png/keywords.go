@@ -0,0 +1,159 @@
+package png
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"sync"
+	"sync/atomic"
+
+	"github.com/r3dpixel/card-parser/character"
+)
+
+// ErrKeywordRegistered is returned when registering a keyword for a built-in (or already registered) revision,
+// or a keyword already used by another revision
+var ErrKeywordRegistered = errors.New("chara keyword already registered")
+
+// ErrInvalidStamp is returned when registering a keyword with a stamp missing its spec, or with an invalid spec version
+var ErrInvalidStamp = errors.New("invalid revision stamp")
+
+// keywordSet chara keywords known to the scanner and the writers (never modified once published)
+type keywordSet struct {
+	keywords map[character.Revision][]byte          // Keywords with their null separator
+	lenient  []lenientKeyword                       // Lowercase keywords (permissive detection), longest first
+	stamps   map[character.Revision]character.Stamp // Spec/version stamps of the registered revisions
+	maxSize  int                                    // Size of the longest keyword (with its null separator)
+}
+
+// lenientKeyword lowercase chara keyword of a revision, without the null separator
+type lenientKeyword struct {
+	revision character.Revision
+	keyword  []byte
+}
+
+// Registry of the chara keywords (copy-on-write, registrations are serialized by the mutex)
+var (
+	registeredKeywords atomic.Pointer[keywordSet]
+	registerMutex      sync.Mutex
+)
+
+func init() {
+	registeredKeywords.Store(&keywordSet{
+		keywords: map[character.Revision][]byte{
+			character.RevisionV2: charaKeyword,
+			character.RevisionV3: ccv3Keyword,
+		},
+		lenient: []lenientKeyword{
+			{revision: character.RevisionV2, keyword: charaKeyword[:len(charaKeyword)-1]},
+			{revision: character.RevisionV3, keyword: ccv3Keyword[:len(ccv3Keyword)-1]},
+		},
+		maxSize: max(charaKeywordSize, ccv3KeywordSize),
+	})
+}
+
+// RegisterKeyword registers the chara keyword of a custom revision (e.g. `ccv4` while prototyping a new spec), so the
+// scanner detects its chunks and the writers use it for the revision; it is safe to call while processors are running
+// The stamp gives the revision and the spec/spec_version of its cards (decoded cards and chunks written for the
+// revision are stamped with them), its spec must be set and its version valid (ErrInvalidStamp otherwise)
+// The keyword must be 1-79 printable ASCII characters (ErrInvalidKeyword otherwise), the built-in revisions (up to
+// character.LatestRevisionV3) and keywords cannot be overridden, and keywords cannot be unregistered
+// (ErrKeywordRegistered is returned for revisions and keywords already registered, case-insensitively)
+func RegisterKeyword(stamp character.Stamp, keyword string) error {
+	// Validate the keyword and the stamp
+	if !isPrintableKeyword(keyword) {
+		return fmt.Errorf("%w: %q", ErrInvalidKeyword, keyword)
+	}
+	version, err := character.ParseVersion(string(stamp.Version))
+	if stamp.Spec == "" || err != nil {
+		return fmt.Errorf("%w: spec %q, version %q", ErrInvalidStamp, stamp.Spec, stamp.Version)
+	}
+	revision := stamp.Revision
+	stamp.Version = version
+
+	registerMutex.Lock()
+	defer registerMutex.Unlock()
+
+	// Reject the built-in and registered revisions and keywords
+	current := registeredKeywords.Load()
+	if revision <= character.LatestRevisionV3 {
+		return fmt.Errorf("%w: revision %v is built in", ErrKeywordRegistered, revision)
+	}
+	if existing, ok := current.keywords[revision]; ok {
+		return fmt.Errorf("%w: revision %v uses %q", ErrKeywordRegistered, revision, existing[:len(existing)-1])
+	}
+	lowerKeyword := bytes.ToLower([]byte(keyword))
+	for _, existing := range current.lenient {
+		if bytes.Equal(existing.keyword, lowerKeyword) {
+			return fmt.Errorf("%w: %q", ErrKeywordRegistered, keyword)
+		}
+	}
+
+	// Publish a copy with the new keyword
+	next := &keywordSet{
+		keywords: maps.Clone(current.keywords),
+		lenient:  append(slices.Clone(current.lenient), lenientKeyword{revision: revision, keyword: lowerKeyword}),
+		stamps:   maps.Clone(current.stamps),
+		maxSize:  max(current.maxSize, len(keyword)+1),
+	}
+	if next.stamps == nil {
+		next.stamps = make(map[character.Revision]character.Stamp, 1)
+	}
+	next.keywords[revision] = append([]byte(keyword), 0x00)
+	next.stamps[revision] = stamp
+	// Longest keywords first, so a keyword prefixing another one (e.g. chara and chara2) never shadows it
+	slices.SortStableFunc(next.lenient, func(a, b lenientKeyword) int {
+		return len(b.keyword) - len(a.keyword)
+	})
+	registeredKeywords.Store(next)
+	return nil
+}
+
+// chunkKeywords returns the chara keywords (with their null separator) by revision, the map must not be modified
+func chunkKeywords() map[character.Revision][]byte {
+	return registeredKeywords.Load().keywords
+}
+
+// lenientKeywords returns the lowercase chara keywords (without the null separator), longest first; the slice must
+// not be modified
+func lenientKeywords() []lenientKeyword {
+	return registeredKeywords.Load().lenient
+}
+
+// revisionStamp returns the spec/version stamp of the revision (see character.Stamps), or the stamp registered with
+// RegisterKeyword for the custom revisions
+func revisionStamp(revision character.Revision) character.Stamp {
+	if stamp, ok := registeredKeywords.Load().stamps[revision]; ok {
+		return stamp
+	}
+	return character.Stamps[revision]
+}
+
+// stampSheet sets the revision, spec and version of the sheet like character.Sheet.SetRevision, with the stamps of
+// the custom revisions (see revisionStamp)
+func stampSheet(sheet *character.Sheet, revision character.Revision) {
+	if stamp := revisionStamp(revision); stamp.Spec != "" {
+		sheet.Revision, sheet.Spec, sheet.Version = revision, stamp.Spec, stamp.Version
+		return
+	}
+	sheet.SetRevision(revision)
+}
+
+// maxCharaKeywordSize returns the size of the longest chara keyword (with its null separator)
+func maxCharaKeywordSize() int {
+	return registeredKeywords.Load().maxSize
+}
+
+// isPrintableKeyword checks if the keyword is a valid chara keyword (1-79 printable ASCII characters)
+func isPrintableKeyword(keyword string) bool {
+	if len(keyword) == 0 || len(keyword) > maxKeywordSize {
+		return false
+	}
+	for index := 0; index < len(keyword); index++ {
+		if keyword[index] < 0x20 || keyword[index] > 0x7E {
+			return false
+		}
+	}
+	return true
+}
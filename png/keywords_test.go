@@ -0,0 +1,176 @@
+package png
+
+import (
+	"bytes"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/r3dpixel/card-parser/character"
+	"github.com/r3dpixel/card-parser/property"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// customRevision revision registered by the keyword tests
+const customRevision character.Revision = 40
+
+// customStamp returns the stamp of a custom revision (spec chara_card_v4, version 4.0)
+func customStamp(revision character.Revision) character.Stamp {
+	return character.Stamp{Spec: "chara_card_v4", Version: "4.0", Revision: revision}
+}
+
+// restoreKeywords restores the registered keywords at the end of the test
+func restoreKeywords(t *testing.T) {
+	t.Helper()
+	previous := registeredKeywords.Load()
+	t.Cleanup(func() { registeredKeywords.Store(previous) })
+}
+
+func TestRegisterKeyword(t *testing.T) {
+	restoreKeywords(t)
+	require.NoError(t, RegisterKeyword(customStamp(customRevision), "ccv4"))
+
+	tests := []struct {
+		name     string
+		revision character.Revision
+		keyword  string
+		err      error
+	}{
+		{"Empty keyword", customRevision + 1, "", ErrInvalidKeyword},
+		{"Keyword too long", customRevision + 1, strings.Repeat("k", maxKeywordSize+1), ErrInvalidKeyword},
+		{"Null byte", customRevision + 1, "cc\x00v5", ErrInvalidKeyword},
+		{"Non-ASCII keyword", customRevision + 1, "ccvé", ErrInvalidKeyword},
+		{"Built-in V2 revision", character.RevisionV2, "chara2", ErrKeywordRegistered},
+		{"Built-in V3 revision", character.RevisionV3, "ccv3x", ErrKeywordRegistered},
+		{"Built-in V3.1 revision", character.RevisionV3_1, "ccv31", ErrKeywordRegistered},
+		{"Built-in keyword", customRevision + 1, "CHARA", ErrKeywordRegistered},
+		{"Registered revision", customRevision, "ccv5", ErrKeywordRegistered},
+		{"Registered keyword", customRevision + 1, "CCV4", ErrKeywordRegistered},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.ErrorIs(t, RegisterKeyword(customStamp(tt.revision), tt.keyword), tt.err)
+		})
+	}
+
+	t.Run("Invalid stamp", func(t *testing.T) {
+		for _, stamp := range []character.Stamp{
+			{Version: "4.0", Revision: customRevision + 1},
+			{Spec: "chara_card_v4", Version: "four", Revision: customRevision + 1},
+		} {
+			assert.ErrorIs(t, RegisterKeyword(stamp, "ccv5"), ErrInvalidStamp)
+		}
+	})
+
+	t.Run("Longest keyword", func(t *testing.T) {
+		keyword := strings.Repeat("k", maxKeywordSize)
+		require.NoError(t, RegisterKeyword(customStamp(customRevision+2), keyword))
+		assert.Equal(t, maxKeywordSize+1, maxCharaKeywordSize())
+		assert.Equal(t, []byte(keyword+"\x00"), chunkKeywords()[customRevision+2])
+	})
+}
+
+func TestRegisterKeyword_RoundTrip(t *testing.T) {
+	restoreKeywords(t)
+	require.NoError(t, RegisterKeyword(customStamp(customRevision), "ccv4"))
+
+	basePNG := createTestPNG(t, 4, 4)
+	charaData := encodeCardData(t, testCards.smallV2)
+	data := injectChunk(t, basePNG, customRevision, charaData, false)
+	require.True(t, bytes.Contains(data, []byte("tEXtccv4\x00")))
+
+	// The scanner detects the custom keyword
+	rawCard, err := FromBytes(data).LastVersion().Get()
+	require.NoError(t, err)
+	assert.Equal(t, customRevision, rawCard.Revision)
+	assert.Equal(t, charaData, rawCard.RawCharaData)
+
+	// The writers use the custom keyword, and the written card scans the same
+	written, err := rawCard.ToBytes()
+	require.NoError(t, err)
+	assert.True(t, bytes.Contains(written, []byte("tEXtccv4\x00")))
+	rescanned, err := FromBytes(written).Get()
+	require.NoError(t, err)
+	assert.Equal(t, customRevision, rescanned.Revision)
+	assert.Equal(t, charaData, rescanned.RawCharaData)
+
+	// The custom chunks are stripped and reserved like the built-in ones
+	var stripped bytes.Buffer
+	require.NoError(t, Strip(bytes.NewReader(written), &stripped))
+	assert.False(t, bytes.Contains(stripped.Bytes(), []byte("ccv4")))
+	assert.ErrorIs(t, rawCard.SetTextChunk("ccv4", "text"), ErrInvalidKeyword)
+}
+
+func TestRegisterKeyword_Decode(t *testing.T) {
+	restoreKeywords(t)
+	require.NoError(t, RegisterKeyword(customStamp(customRevision), "ccv4"))
+	data := injectChunk(t, createTestPNG(t, 4, 4), customRevision, encodeCardData(t, createSheet(character.RevisionV2, "Custom")), false)
+
+	// The decoded sheet is stamped with the registered spec/version
+	card, err := FromBytes(data).LastVersion().Get()
+	require.NoError(t, err)
+	decoded, err := card.Decode()
+	require.NoError(t, err)
+	assert.Equal(t, customRevision, decoded.Revision)
+	assert.Equal(t, character.Spec("chara_card_v4"), decoded.Spec)
+	assert.Equal(t, character.Version("4.0"), decoded.Version)
+
+	// Every written chunk keeps a spec/spec_version, and the card decodes the same
+	written, err := decoded.ToBytes(customRevision, character.RevisionV2)
+	require.NoError(t, err)
+	cards, err := FromBytes(written).GetAll()
+	require.NoError(t, err)
+	require.Len(t, cards, 2)
+	for _, rawCard := range cards {
+		rawJson, err := rawCard.ToRawJson()
+		require.NoError(t, err)
+		assert.NotContains(t, string(rawJson.RawJsonData), `"spec":""`)
+		assert.NotContains(t, string(rawJson.RawJsonData), `"spec_version":""`)
+	}
+	redecoded, err := cards[0].Decode()
+	require.NoError(t, err)
+	assert.Equal(t, customRevision, redecoded.Revision)
+	assert.Equal(t, character.Spec("chara_card_v4"), redecoded.Spec)
+	assert.Equal(t, property.String("Custom"), redecoded.Name)
+}
+
+func TestRegisterKeyword_LenientLongestFirst(t *testing.T) {
+	restoreKeywords(t)
+	require.NoError(t, RegisterKeyword(customStamp(customRevision), "chara2"))
+	charaData := encodeCardData(t, createSheet(character.RevisionV2, "Prefixed"))
+
+	// The longest matching keyword wins (chara2 over chara), whatever the registration order
+	for range 20 {
+		revision, data, ok := lenientCharaChunk(append([]byte("CHARA2\x00"), charaData...))
+		require.True(t, ok)
+		assert.Equal(t, customRevision, revision)
+		assert.Equal(t, charaData, data)
+		revision, _, ok = lenientCharaChunk(append([]byte("Chara\x00"), charaData...))
+		require.True(t, ok)
+		assert.Equal(t, character.RevisionV2, revision)
+	}
+}
+
+func TestRegisterKeyword_Concurrent(t *testing.T) {
+	restoreKeywords(t)
+	data := injectSingleChunk(t, createTestPNG(t, 4, 4), testCards.largeV3, false)
+
+	var wg sync.WaitGroup
+	for index := range 8 {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			assert.NoError(t, RegisterKeyword(customStamp(customRevision+character.Revision(index)), "custom"+strings.Repeat("x", index)))
+		}()
+		go func() {
+			defer wg.Done()
+			rawCard, err := FromBytes(data).LastVersion().Get()
+			assert.NoError(t, err)
+			assert.Equal(t, character.RevisionV3, rawCard.Revision)
+		}()
+	}
+	wg.Wait()
+	assert.Len(t, chunkKeywords(), 2+8)
+}
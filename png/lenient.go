@@ -0,0 +1,102 @@
+package png
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+
+	"github.com/r3dpixel/card-parser/character"
+)
+
+// maxKeywordSize is the maximum size of a tEXt keyword in bytes (as per the PNG specification)
+const maxKeywordSize int = 79
+
+// Byte arrays used by the permissive chara chunk detection
+var (
+	// Base64 encoding of `{"` (the start of any base64 encoded JSON object)
+	base64JSONPrefix = []byte("eyJ")
+)
+
+// lenientCharaChunk detects mislabeled chara chunks and returns the revision and the (base64 encoded) chara data:
+//   - case-insensitive or suffixed keywords (e.g. `CHARA`, `Chara`, `chara-ext`)
+//   - keywords missing the null separator (e.g. `charaeyJ...`)
+//   - unknown keywords whose payload is base64 encoded JSON (starts with `eyJ`) or raw JSON
+func lenientCharaChunk(chunkData []byte) (character.Revision, []byte, bool) {
+	// Split the keyword from the payload (if there is a null separator)
+	keyword, payload, separated := bytes.Cut(chunkData[:min(len(chunkData), maxKeywordSize+1)], []byte{0x00})
+	if separated {
+		payload = chunkData[len(keyword)+1:]
+	} else {
+		keyword, payload = chunkData, chunkData
+	}
+
+	// Detect the chara keyword (case-insensitive)
+	lowerKeyword := bytes.ToLower(keyword)
+	for _, lenient := range lenientKeywords() {
+		revision, charaKeyword := lenient.revision, lenient.keyword
+		if !bytes.HasPrefix(lowerKeyword, charaKeyword) {
+			continue
+		}
+		// Without separator, the payload follows the bare keyword
+		if !separated {
+			payload = chunkData[len(charaKeyword):]
+		}
+		// Normalize the payload (the keyword decides the revision)
+		if charaData, _, ok := sniffCharaData(payload); ok {
+			return revision, charaData, true
+		}
+	}
+
+	// Sniff the payload of unknown keywords (the spec decides the revision)
+	if charaData, revision, ok := sniffCharaData(payload); ok {
+		return revision, charaData, true
+	}
+
+	// No chara data detected
+	return character.RevisionV2, nil, false
+}
+
+// sniffCharaData checks if the payload is base64 encoded JSON or raw JSON, and returns it base64 encoded,
+// together with the revision inferred from the JSON spec
+func sniffCharaData(payload []byte) ([]byte, character.Revision, bool) {
+	payload = bytes.TrimSpace(payload)
+
+	// Raw JSON payload, encode it to base64
+	if bytes.HasPrefix(payload, []byte("{")) {
+		if !json.Valid(payload) {
+			return nil, character.RevisionV2, false
+		}
+		charaData := make([]byte, base64.StdEncoding.EncodedLen(len(payload)))
+		base64.StdEncoding.Encode(charaData, payload)
+		return charaData, specRevision(payload), true
+	}
+
+	// Base64 encoded JSON payload, it must decode to valid JSON
+	if bytes.HasPrefix(payload, base64JSONPrefix) {
+		decoded := make([]byte, base64.StdEncoding.DecodedLen(len(payload)))
+		n, err := base64.StdEncoding.Decode(decoded, payload)
+		if err != nil || !json.Valid(decoded[:n]) {
+			return nil, character.RevisionV2, false
+		}
+		return payload, specRevision(decoded[:n]), true
+	}
+
+	// Not chara data
+	return nil, character.RevisionV2, false
+}
+
+// specRevision returns the revision matching the spec of the JSON card (fallback to V2)
+func specRevision(jsonData []byte) character.Revision {
+	var header struct {
+		Spec character.Spec `json:"spec"`
+	}
+	if err := json.Unmarshal(jsonData, &header); err != nil {
+		return character.RevisionV2
+	}
+	for revision, stamp := range character.Stamps {
+		if stamp.Spec == header.Spec && chunkKeywords()[revision] != nil {
+			return revision
+		}
+	}
+	return character.RevisionV2
+}
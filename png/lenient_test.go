@@ -0,0 +1,105 @@
+package png
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/r3dpixel/card-parser/character"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProcessor_Lenient(t *testing.T) {
+	basePNG := createTestPNG(t, 4, 4)
+	v2Data := encodeCardData(t, testCards.smallV2)
+	v3Data := encodeCardData(t, testCards.largeV3)
+	v2JSON, err := testCards.smallV2.ToBytes()
+	require.NoError(t, err)
+	v3JSON, err := testCards.largeV3.ToBytes()
+	require.NoError(t, err)
+
+	// withTextChunk injects a raw tEXt chunk right after the IHDR chunk
+	withTextChunk := func(chunkData ...[]byte) []byte {
+		return slices.Concat(basePNG[:fullIhdrSize], textChunk(slices.Concat(chunkData...)), basePNG[fullIhdrSize:])
+	}
+
+	tests := []struct {
+		name     string
+		data     []byte
+		revision character.Revision
+		expected *character.Sheet
+	}{
+		{"Uppercase keyword", withTextChunk([]byte("CHARA\x00"), v2Data), character.RevisionV2, testCards.smallV2},
+		{"Capitalized keyword", withTextChunk([]byte("Chara\x00"), v2Data), character.RevisionV2, testCards.smallV2},
+		{"Suffixed keyword", withTextChunk([]byte("chara-ext\x00"), v2Data), character.RevisionV2, testCards.smallV2},
+		{"Uppercase V3 keyword", withTextChunk([]byte("CCV3\x00"), v3Data), character.RevisionV3, testCards.largeV3},
+		{"Missing null separator", withTextChunk([]byte("chara"), v2Data), character.RevisionV2, testCards.smallV2},
+		{"Raw JSON payload with uppercase keyword", withTextChunk([]byte("CCV3\x00"), v3JSON), character.RevisionV3, testCards.largeV3},
+		{"Unknown keyword with base64 payload", withTextChunk([]byte("Description\x00"), v2Data), character.RevisionV2, testCards.smallV2},
+		{"Unknown keyword with V3 base64 payload", withTextChunk([]byte("metadata\x00"), v3Data), character.RevisionV3, testCards.largeV3},
+		{"Unknown keyword with raw JSON payload", withTextChunk([]byte("Comment\x00"), v3JSON), character.RevisionV3, testCards.largeV3},
+		{"Unlabeled base64 payload", withTextChunk(v2Data), character.RevisionV2, testCards.smallV2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// The strict detection ignores the mislabeled chunk
+			rawCard, err := FromBytes(tt.data).Get()
+			require.NoError(t, err)
+			assert.Empty(t, rawCard.RawCharaData)
+
+			// The lenient detection recognizes the mislabeled chunk
+			rawCard, err = FromBytes(tt.data).Lenient().Get()
+			require.NoError(t, err)
+			assert.Equal(t, tt.revision, rawCard.Revision)
+
+			characterCard, err := rawCard.Decode()
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected.Name, characterCard.Name)
+
+			// The chara chunk is stripped from the image body
+			assert.Equal(t, basePNG[fullIhdrSize:], rawCard.Body)
+		})
+	}
+
+	t.Run("Raw JSON payload with standard keyword", func(t *testing.T) {
+		data := withTextChunk([]byte("chara\x00"), v2JSON)
+
+		// The strict detection keeps the raw JSON payload as is
+		rawCard, err := FromBytes(data).Get()
+		require.NoError(t, err)
+		assert.Equal(t, v2JSON, rawCard.RawCharaData)
+
+		// The lenient detection base64 encodes the raw JSON payload
+		rawCard, err = FromBytes(data).Lenient().Get()
+		require.NoError(t, err)
+		assert.Equal(t, v2Data, rawCard.RawCharaData)
+	})
+
+	t.Run("Unrelated text chunks", func(t *testing.T) {
+		for _, data := range [][]byte{
+			withTextChunk([]byte("Software\x00"), []byte("Paint")),
+			withTextChunk([]byte("Comment\x00"), []byte("{not json")),
+			withTextChunk([]byte("Comment\x00"), []byte("eyJ!!invalid")),
+			withTextChunk([]byte("CHARA\x00"), []byte("not base64 data")),
+		} {
+			rawCard, err := FromBytes(data).Lenient().Get()
+			require.NoError(t, err)
+			assert.Empty(t, rawCard.RawCharaData)
+		}
+	})
+
+	t.Run("Strict keywords take precedence", func(t *testing.T) {
+		data := injectSingleChunk(t, withTextChunk([]byte("CHARA\x00"), v2Data), testCards.largeV3, true)
+		rawCard, err := FromBytes(data).LastVersion().Lenient().Get()
+		require.NoError(t, err)
+		assert.Equal(t, character.RevisionV3, rawCard.Revision)
+		assert.Equal(t, v3Data, rawCard.RawCharaData)
+
+		rawCards, err := FromBytes(data).Lenient().GetAll()
+		require.NoError(t, err)
+		require.Len(t, rawCards, 2)
+		assert.Equal(t, v2Data, rawCards[0].RawCharaData)
+		assert.Equal(t, v3Data, rawCards[1].RawCharaData)
+	})
+}
@@ -0,0 +1,53 @@
+package png
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"io"
+)
+
+// ErrNoOriginal is returned by ToOriginal when the raw card has no original input (see Processor.KeepOriginal)
+var ErrNoOriginal = errors.New("raw card has no original input")
+
+// ErrOriginalModified is returned by ToOriginal (with OriginalOptions.Strict) when the chara data or the text
+// chunks were changed, as the original input cannot carry them
+var ErrOriginalModified = errors.New("raw card was modified since it was read")
+
+// OriginalOptions configures ToOriginal
+type OriginalOptions struct {
+	// Strict returns ErrOriginalModified instead of writing the PNG image when the card was modified
+	Strict bool
+}
+
+// ToOriginal writes the original input (e.g. the JPEG image) back untouched to the writer
+// If the chara data or the text chunks were changed, the PNG image is written instead (see ToImage), or
+// ErrOriginalModified is returned with OriginalOptions.Strict
+func (rc *RawCard) ToOriginal(w io.Writer, opts ...OriginalOptions) error {
+	// There is no original input
+	if len(rc.OriginalBytes) == 0 {
+		return ErrNoOriginal
+	}
+
+	// Fall back to the PNG image if the card was modified
+	if !bytes.Equal(rc.RawCharaData, rc.originalCharaData) || rc.textChunksChanged() {
+		if len(opts) > 0 && opts[0].Strict {
+			return ErrOriginalModified
+		}
+		return rc.ToImage(w)
+	}
+
+	// Write the original input
+	_, err := w.Write(rc.OriginalBytes)
+	return err
+}
+
+// imageFormat returns the format name of the image data (e.g. jpeg, webp, avif), empty if unknown
+// Formats without a known magic number are named by the registered image decoders
+func imageFormat(data []byte) string {
+	if format := sniffFormat(data); format != "" {
+		return format
+	}
+	_, format, _ := image.DecodeConfig(bytes.NewReader(data))
+	return format
+}
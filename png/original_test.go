@@ -0,0 +1,87 @@
+package png
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/r3dpixel/card-parser/character"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProcessor_KeepOriginal(t *testing.T) {
+	jpgBytes := createTestJPG(t)
+
+	t.Run("Byte-identical passthrough", func(t *testing.T) {
+		processor := FromBytes(jpgBytes).KeepOriginal()
+		width, height := processor.ImageSize()
+		assert.Equal(t, 4, width)
+		assert.Equal(t, 4, height)
+
+		rawCard, err := processor.Get()
+		require.NoError(t, err)
+		assert.Equal(t, "jpeg", rawCard.OriginalFormat)
+
+		var out bytes.Buffer
+		require.NoError(t, rawCard.ToOriginal(&out))
+		assert.Equal(t, jpgBytes, out.Bytes())
+	})
+
+	t.Run("JPEG card with metadata", func(t *testing.T) {
+		card := createGradientCard(t, 16, character.RevisionV2)
+		var jpegData bytes.Buffer
+		require.NoError(t, card.ToJPEG(&jpegData, 90))
+
+		rawCards, err := FromBytes(jpegData.Bytes()).KeepOriginal().GetAll()
+		require.NoError(t, err)
+		require.Len(t, rawCards, 1)
+		require.NotEmpty(t, rawCards[0].RawCharaData)
+
+		// The chara data was not changed, so the original is written back
+		var out bytes.Buffer
+		require.NoError(t, rawCards[0].ToOriginal(&out))
+		assert.Equal(t, jpegData.Bytes(), out.Bytes())
+	})
+
+	t.Run("Fallback when chara data is set", func(t *testing.T) {
+		rawCard, err := FromBytes(jpgBytes).KeepOriginal().Get()
+		require.NoError(t, err)
+		rawCard.Revision = character.RevisionV2
+		rawCard.RawCharaData = encodeCardData(t, testCards.smallV2)
+
+		// The PNG image is written instead
+		var out bytes.Buffer
+		require.NoError(t, rawCard.ToOriginal(&out))
+		assert.True(t, bytes.HasPrefix(out.Bytes(), pngHeader))
+		written, err := FromBytes(out.Bytes()).Get()
+		require.NoError(t, err)
+		assert.Equal(t, rawCard.RawCharaData, written.RawCharaData)
+
+		// Or the modification is reported
+		err = rawCard.ToOriginal(&bytes.Buffer{}, OriginalOptions{Strict: true})
+		assert.ErrorIs(t, err, ErrOriginalModified)
+	})
+
+	t.Run("Fallback when a text chunk is set", func(t *testing.T) {
+		rawCard, err := FromBytes(jpgBytes).KeepOriginal().Get()
+		require.NoError(t, err)
+		require.NoError(t, rawCard.SetTextChunk("Software", "card-parser"))
+
+		var out bytes.Buffer
+		require.NoError(t, rawCard.ToOriginal(&out))
+		assert.True(t, bytes.HasPrefix(out.Bytes(), pngHeader))
+	})
+
+	t.Run("Not kept", func(t *testing.T) {
+		rawCard, err := FromBytes(jpgBytes).Get()
+		require.NoError(t, err)
+		assert.Empty(t, rawCard.OriginalFormat)
+		assert.Nil(t, rawCard.OriginalBytes)
+		assert.ErrorIs(t, rawCard.ToOriginal(&bytes.Buffer{}), ErrNoOriginal)
+
+		// PNG input is not converted
+		rawCard, err = FromBytes(createTestPNG(t, 4, 4)).KeepOriginal().Get()
+		require.NoError(t, err)
+		assert.ErrorIs(t, rawCard.ToOriginal(&bytes.Buffer{}), ErrNoOriginal)
+	})
+}
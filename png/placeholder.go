@@ -0,0 +1,145 @@
+package png
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"slices"
+	"sync"
+)
+
+// ErrInvalidPlaceholderSize is returned when a placeholder is requested with a size out of 1-MaxSize
+var ErrInvalidPlaceholderSize = errors.New("invalid placeholder size")
+
+// DefaultMaxPlaceholderSize is the default largest size of the placeholder images (in pixels)
+const DefaultMaxPlaceholderSize = 4096
+
+// placeholderCacheSize number of encoded placeholder images kept in the cache (least recently used are evicted)
+const placeholderCacheSize = 32
+
+// PlaceholderOptions options of the placeholder character cards
+type PlaceholderOptions struct {
+	Clone   bool // Return a copy of the image data, instead of the cached image data
+	MaxSize int  // Largest size of the image in pixels (0 uses DefaultMaxPlaceholderSize)
+}
+
+// placeholderKey cache key of an encoded placeholder image
+type placeholderKey struct {
+	size  int
+	color color.RGBA64
+}
+
+// placeholderCache encoded placeholder images by size and color (safe for concurrent use)
+var placeholderCache = struct {
+	sync.Mutex
+	images map[placeholderKey]pngData
+	order  []placeholderKey // Keys from the least to the most recently used
+}{images: make(map[placeholderKey]pngData)}
+
+// PlaceholderCharacterCard returns a placeholder character card of the given size (black PNG image)
+// See PlaceholderCharacterCardColor for the caching of the image data
+func PlaceholderCharacterCard(size int, opts ...PlaceholderOptions) (*RawCard, error) {
+	return PlaceholderCharacterCardColor(size, color.Black, opts...)
+}
+
+// PlaceholderCharacterCardColor returns a placeholder character card of the given size (PNG image of a solid color)
+// The images are encoded once per size and color, and cached: the returned card shares the cached Header and Body,
+// which must be treated as read-only (the cards replace them when scaled down or resized), unless Clone is set
+// The size must be between 1 and MaxSize (ErrInvalidPlaceholderSize otherwise)
+func PlaceholderCharacterCardColor(size int, c color.Color, opts ...PlaceholderOptions) (*RawCard, error) {
+	// Validate the size
+	maxSize := DefaultMaxPlaceholderSize
+	if len(opts) > 0 && opts[0].MaxSize > 0 {
+		maxSize = opts[0].MaxSize
+	}
+	if size <= 0 || size > maxSize {
+		return nil, fmt.Errorf("%w: %d (must be between 1 and %d)", ErrInvalidPlaceholderSize, size, maxSize)
+	}
+
+	// Get the cached image, or encode it
+	key := placeholderKey{size: size, color: color.RGBA64Model.Convert(c).(color.RGBA64)}
+	data, ok := cachedPlaceholder(key)
+	if !ok {
+		encoded, err := encodePlaceholder(key)
+		if err != nil {
+			return nil, err
+		}
+		data = cachePlaceholder(key, encoded)
+	}
+
+	// Return the RawCard (with a copy of the image data if requested)
+	if len(opts) > 0 && opts[0].Clone {
+		data.Header, data.Body = slices.Clone(data.Header), slices.Clone(data.Body)
+	}
+	return &RawCard{pngData: pngData{Header: data.Header, Body: data.Body}}, nil
+}
+
+// cachedPlaceholder returns the cached image of the key, and marks it as the most recently used
+func cachedPlaceholder(key placeholderKey) (pngData, bool) {
+	placeholderCache.Lock()
+	defer placeholderCache.Unlock()
+
+	data, ok := placeholderCache.images[key]
+	if ok {
+		index := slices.Index(placeholderCache.order, key)
+		placeholderCache.order = append(slices.Delete(placeholderCache.order, index, index+1), key)
+	}
+	return data, ok
+}
+
+// cachePlaceholder caches the image of the key (evicting the least recently used image if the cache is full), and
+// returns the cached image (the image cached first if another caller encoded the same key concurrently)
+func cachePlaceholder(key placeholderKey, data pngData) pngData {
+	placeholderCache.Lock()
+	defer placeholderCache.Unlock()
+
+	// Keep the image cached first
+	if cached, ok := placeholderCache.images[key]; ok {
+		return cached
+	}
+
+	// Evict the least recently used image
+	if len(placeholderCache.order) >= placeholderCacheSize {
+		delete(placeholderCache.images, placeholderCache.order[0])
+		placeholderCache.order = slices.Delete(placeholderCache.order, 0, 1)
+	}
+	placeholderCache.images[key] = data
+	placeholderCache.order = append(placeholderCache.order, key)
+	return data
+}
+
+// encodePlaceholder encodes the placeholder image of the key (opaque grays are encoded as grayscale images)
+func encodePlaceholder(key placeholderKey) (pngData, error) {
+	// Fill the image with the color
+	bounds := image.Rect(0, 0, key.size, key.size)
+	var img image.Image
+	if c := key.color; c.R == c.G && c.G == c.B && c.A == 0xFFFF {
+		gray := image.NewGray(bounds)
+		fill := color.GrayModel.Convert(c).(color.Gray).Y
+		for index := range gray.Pix {
+			gray.Pix[index] = fill
+		}
+		img = gray
+	} else {
+		rgba := image.NewNRGBA(bounds)
+		draw.Draw(rgba, bounds, image.NewUniform(c), image.Point{}, draw.Src)
+		img = rgba
+	}
+
+	// Encode to PNG bytes
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return pngData{}, err
+	}
+
+	// Split the image data
+	rawCard, err := FromBytes(buf.Bytes()).First().Get()
+	if err != nil {
+		return pngData{}, err
+	}
+	return rawCard.pngData, nil
+}
@@ -0,0 +1,109 @@
+package png
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPlaceholderCharacterCard(t *testing.T) {
+	t.Run("Black grayscale image", func(t *testing.T) {
+		card, err := PlaceholderCharacterCard(8)
+		require.NoError(t, err)
+		data, err := card.ToBytes()
+		require.NoError(t, err)
+
+		var expected bytes.Buffer
+		require.NoError(t, png.Encode(&expected, image.NewGray(image.Rect(0, 0, 8, 8))))
+		assert.Equal(t, expected.Bytes(), data)
+	})
+
+	t.Run("Solid color", func(t *testing.T) {
+		red := color.NRGBA{R: 200, A: 255}
+		card, err := PlaceholderCharacterCardColor(6, red)
+		require.NoError(t, err)
+		img, err := card.Image()
+		require.NoError(t, err)
+		assert.Equal(t, 6, img.Bounds().Dx())
+		assert.Equal(t, color.NRGBAModel.Convert(red), color.NRGBAModel.Convert(img.At(5, 5)))
+	})
+
+	t.Run("Shared and cloned image data", func(t *testing.T) {
+		first, err := PlaceholderCharacterCard(10)
+		require.NoError(t, err)
+		second, err := PlaceholderCharacterCard(10)
+		require.NoError(t, err)
+		assert.Same(t, &first.Body[0], &second.Body[0])
+
+		cloned, err := PlaceholderCharacterCard(10, PlaceholderOptions{Clone: true})
+		require.NoError(t, err)
+		assert.NotSame(t, &first.Body[0], &cloned.Body[0])
+		assert.Equal(t, first.Header, cloned.Header)
+		assert.Equal(t, first.Body, cloned.Body)
+
+		// Scaling a card down does not affect the cached image data
+		body := bytes.Clone(first.Body)
+		require.NoError(t, first.ScaleDown(4))
+		assert.Equal(t, body, second.Body)
+	})
+
+	t.Run("Invalid sizes", func(t *testing.T) {
+		for _, size := range []int{0, -1, DefaultMaxPlaceholderSize + 1} {
+			_, err := PlaceholderCharacterCard(size)
+			assert.ErrorIs(t, err, ErrInvalidPlaceholderSize)
+		}
+		_, err := PlaceholderCharacterCard(16, PlaceholderOptions{MaxSize: 8})
+		assert.ErrorIs(t, err, ErrInvalidPlaceholderSize)
+	})
+
+	t.Run("Bounded cache", func(t *testing.T) {
+		for size := 1; size <= placeholderCacheSize+8; size++ {
+			_, err := PlaceholderCharacterCardColor(size, color.White)
+			require.NoError(t, err)
+		}
+		placeholderCache.Lock()
+		defer placeholderCache.Unlock()
+		assert.Len(t, placeholderCache.images, placeholderCacheSize)
+		assert.Len(t, placeholderCache.order, placeholderCacheSize)
+	})
+}
+
+func TestPlaceholderCharacterCard_Concurrent(t *testing.T) {
+	sizes := []int{16, 32, 48, 64}
+	colors := []color.Color{color.Black, color.NRGBA{G: 128, A: 255}}
+
+	// Expected bytes of each placeholder (encoded without the cache)
+	expected := make(map[placeholderKey][]byte)
+	for _, size := range sizes {
+		for _, c := range colors {
+			key := placeholderKey{size: size, color: color.RGBA64Model.Convert(c).(color.RGBA64)}
+			data, err := encodePlaceholder(key)
+			require.NoError(t, err)
+			expected[key] = append(bytes.Clone(data.Header), data.Body...)
+		}
+	}
+
+	var wg sync.WaitGroup
+	for worker := range 16 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for iteration := range 50 {
+				size, c := sizes[(worker+iteration)%len(sizes)], colors[iteration%len(colors)]
+				card, err := PlaceholderCharacterCardColor(size, c, PlaceholderOptions{Clone: iteration%5 == 0})
+				if !assert.NoError(t, err) {
+					return
+				}
+				key := placeholderKey{size: size, color: color.RGBA64Model.Convert(c).(color.RGBA64)}
+				assert.Equal(t, expected[key], append(bytes.Clone(card.Header), card.Body...))
+			}
+		}()
+	}
+	wg.Wait()
+}
@@ -2,60 +2,210 @@ package png
 
 import (
 	"bytes"
+	"errors"
+	"fmt"
+	"image"
 	"io"
+	"slices"
+	"time"
 
+	"github.com/gen2brain/avif"
 	jpeg "github.com/gen2brain/jpegli"
 	"github.com/sunshineplan/imgconv"
 )
 
 // converterProcessor converts the card image from any format to PNG
+// The chara data of JPEG images is read from their XMP metadata (see RawCard.ToJPEG)
 type converterProcessor struct {
-	reader  io.Reader
-	closer  func() error
-	decoded bool
-	pngData pngData
-	err     error
+	attemptRecorder
+	reader       io.Reader
+	closer       func() error
+	scanMode     ScanMode
+	keepOriginal bool
+	requireChara bool
+	inputSize    int64 // Size of the input in bytes (known once decoded)
+	decoded      bool
+	configured   bool // Set once the dimensions were read from the image header (before decoding)
+	width        int
+	height       int
+	pngData      pngData
+	charaCards   []*RawCard // Chara data found in the metadata of the input (JPEG), in file order
+	original     []byte     // Input bytes (kept with KeepOriginal)
+	format       string     // Input format (detected from the magic number, or by the image decoders once decoded)
+	stats        ScanStats
+	err          error
 }
 
-// ScanMode returns the processor itself as it doesn't support scanning
+// newConverterProcessor creates a new converter processor of the input in the given format (empty if unknown)
+func newConverterProcessor(r io.Reader, closer func() error, format string) *converterProcessor {
+	return &converterProcessor{reader: r, closer: closer, scanMode: DefaultScanMode, format: format}
+}
+
+// ScanMode sets the scan mode selecting the chara data of the input metadata (JPEG)
 func (p *converterProcessor) ScanMode(scanMode ScanMode) Processor {
+	p.scanMode = scanMode
 	return p
 }
 
-// First returns the processor itself as it doesn't support scanning'
+// First sets the processor to select the first chara data of the input metadata (JPEG)
 func (p *converterProcessor) First() Processor {
 	return p.ScanMode(First)
 }
 
-// LastVersion returns the processor itself as it doesn't support scanning'
+// LastVersion sets the processor to select the latest chara data (highest revision) of the input metadata (JPEG)
 func (p *converterProcessor) LastVersion() Processor {
 	return p.ScanMode(LastVersion)
 }
 
-// LastLongest returns the processor itself as it doesn't support scanning'
+// LastLongest sets the processor to select the longest chara data of the input metadata (JPEG)
 func (p *converterProcessor) LastLongest() Processor {
 	return p.ScanMode(LastLongest)
 }
 
+// LastVersionLongest sets the processor to select the latest chara data (highest revision) of the input metadata (JPEG),
+// the longest one among the chara data of that revision
+func (p *converterProcessor) LastVersionLongest() Processor {
+	return p.ScanMode(LastVersionLongest)
+}
+
+// VerifyCRC returns the processor itself as the image is re-encoded (there are no source chunks to verify)
+func (p *converterProcessor) VerifyCRC() Processor {
+	return p
+}
+
+// Lenient returns the processor itself as the image is re-encoded (there are no source chunks to detect)
+func (p *converterProcessor) Lenient() Processor {
+	return p
+}
+
+// MaxChunkSize returns the processor itself as the image is re-encoded (there are no source chunks to read)
+func (p *converterProcessor) MaxChunkSize(size int) Processor {
+	return p
+}
+
+// TrackOffsets returns the processor itself as the image is re-encoded (there are no source chunks to locate)
+func (p *converterProcessor) TrackOffsets() Processor {
+	return p
+}
+
+// KeepOriginal keeps the input bytes and format on the raw cards (RawCard.OriginalBytes and OriginalFormat),
+// so the input can be written back untouched by RawCard.ToOriginal (the image is still converted to PNG)
+func (p *converterProcessor) KeepOriginal() Processor {
+	p.keepOriginal = true
+	return p
+}
+
+// KeepTrailingData returns the processor itself as the image is re-encoded (the data after the image is dropped)
+func (p *converterProcessor) KeepTrailingData() Processor {
+	return p
+}
+
+// OnChunk returns the processor itself as the image is re-encoded (there are no source chunks to read)
+func (p *converterProcessor) OnChunk(callback func(typeCode uint32, length uint32)) Processor {
+	return p
+}
+
+// RequireCharacterData makes Get and GetAll fail with ErrNoCharacterData if the input metadata (JPEG) has no chara data
+func (p *converterProcessor) RequireCharacterData() Processor {
+	p.requireChara = true
+	return p
+}
+
+// SourceFormat returns the format of the converted input (e.g. jpeg, webp), detected from its magic number before
+// decoding (so it is known even if the decoding fails); formats without a known magic number are named once decoded
+func (p *converterProcessor) SourceFormat() string {
+	return p.format
+}
+
+// Validate checks the image against the constraints, the input is always rejected if PNG input is required
+// The dimensions are read from the image header (see ImageSize); the image is only decoded if the input size is
+// constrained (once, and reused by Get and Pipe)
+func (p *converterProcessor) Validate(constraints Constraints) error {
+	// If there is an error return error
+	if p.err != nil {
+		return p.err
+	}
+
+	// Reject the converted input
+	var violations []error
+	if constraints.RequirePNGInput {
+		violations = append(violations, fmt.Errorf("%w: input is not a PNG image", ErrConstraintViolated))
+	}
+
+	// Check the dimensions
+	if constraints.needsImage() {
+		width, height := p.ImageSize()
+		if p.err != nil {
+			return p.err
+		}
+		violations = append(violations, constraints.checkImage(width, height)...)
+	}
+
+	// Check the input size
+	if constraints.MaxFileBytes > 0 {
+		p.decode()
+		if p.err != nil {
+			return p.err
+		}
+		violations = append(violations, constraints.checkFileBytes(p.inputSize))
+	}
+
+	// Return the violations
+	return errors.Join(violations...)
+}
+
 // Err returns any error that occurred during processing
 func (p *converterProcessor) Err() error {
 	return p.err
 }
 
+// Stats returns the statistics of the conversion (bytes read, chara data found and duration) once the image was
+// converted, the zero value before
+func (p *converterProcessor) Stats() ScanStats {
+	return p.stats
+}
+
 // ImageSize returns the width and height of the converted image
+// Before the conversion, the dimensions are read from the image header (the bytes read are kept for the conversion,
+// so Get still succeeds); the image is only decoded if the header cannot be read (e.g. AVIF without a decoder)
 func (p *converterProcessor) ImageSize() (int, int) {
-	// Decode the image
-	p.decode()
+	// Read the dimensions from the image header, or decode the image
+	if !p.decoded && !p.configured && !p.decodeConfig() {
+		p.decode()
+	}
 	// If there was an error return -1, -1
 	if p.err != nil {
 		return -1, -1
 	}
 
 	// Return the width and height
+	if !p.decoded {
+		return p.width, p.height
+	}
 	return widthPNG(p.pngData.Header), heightPNG(p.pngData.Header)
 }
 
-// Get returns a RawCard from the converted image data
+// decodeConfig reads the dimensions from the image header, and puts the bytes read back in front of the input
+func (p *converterProcessor) decodeConfig() bool {
+	// If there is an error return
+	if p.err != nil {
+		return false
+	}
+
+	// Read the image header, keeping the bytes read
+	var prefix bytes.Buffer
+	config, _, err := image.DecodeConfig(io.TeeReader(p.reader, &prefix))
+	p.reader = io.MultiReader(&prefix, p.reader)
+	if err != nil {
+		return false
+	}
+
+	// Set the dimensions
+	p.width, p.height, p.configured = config.Width, config.Height, true
+	return true
+}
+
+// Get returns a RawCard from the converted image data, with the chara data selected by the scan mode (if any)
 func (p *converterProcessor) Get() (*RawCard, error) {
 	// Decode the image
 	p.decode()
@@ -63,14 +213,90 @@ func (p *converterProcessor) Get() (*RawCard, error) {
 		return nil, p.err
 	}
 
+	// Select the chara data
+	rawCard := p.newRawCard()
+	for _, charaCard := range p.charaCards {
+		if p.scanMode.criteria(rawCard, charaCard.RawCharaData, charaCard.Revision) {
+			rawCard.Revision = charaCard.Revision
+			rawCard.RawCharaData = slices.Clone(charaCard.RawCharaData)
+			rawCard.Encoding = charaCard.Encoding
+			rawCard.originalCharaData = charaCard.RawCharaData
+		}
+		if !p.scanMode.deepScan && len(rawCard.RawCharaData) > 0 {
+			break
+		}
+	}
+
+	// Reject the input without chara data if required
+	if p.requireChara && len(rawCard.RawCharaData) == 0 {
+		return nil, ErrNoCharacterData
+	}
+
 	// Return the raw card
-	return &RawCard{
-		pngData: p.pngData,
-	}, nil
+	return rawCard, nil
 }
 
-// Close closes the underlying reader
+// GetAll returns a RawCard for every chara data found in the input metadata (JPEG), sharing the converted image data
+func (p *converterProcessor) GetAll() ([]*RawCard, error) {
+	// Decode the image
+	p.decode()
+	if p.err != nil {
+		return nil, p.err
+	}
+
+	// Reject the input without chara data if required
+	if p.requireChara && len(p.charaCards) == 0 {
+		return nil, ErrNoCharacterData
+	}
+
+	// Return all raw cards
+	rawCards := make([]*RawCard, 0, len(p.charaCards))
+	for _, charaCard := range p.charaCards {
+		rawCard := p.newRawCard()
+		rawCard.Revision = charaCard.Revision
+		rawCard.RawCharaData = slices.Clone(charaCard.RawCharaData)
+		rawCard.Encoding = charaCard.Encoding
+		rawCard.originalCharaData = charaCard.RawCharaData
+		rawCards = append(rawCards, rawCard)
+	}
+	return rawCards, nil
+}
+
+// newRawCard returns a RawCard of the converted image (flagged as converted), with the original input if kept
+func (p *converterProcessor) newRawCard() *RawCard {
+	rawCard := &RawCard{pngData: p.pngData, WasConverted: true}
+	if p.keepOriginal {
+		rawCard.OriginalFormat = p.format
+		rawCard.OriginalBytes = p.original
+	}
+	return rawCard
+}
+
+// Pipe writes the converted image to the writer, applying the transform (if any) on the raw card first
+// The converted image is held in memory, as it is re-encoded
+func (p *converterProcessor) Pipe(w io.Writer, transform func(*RawCard) error) error {
+	// Convert the image
+	rawCard, err := p.Get()
+	if err != nil {
+		return err
+	}
+
+	// Apply the transform
+	if transform != nil {
+		if err := transform(rawCard); err != nil {
+			return err
+		}
+	}
+
+	// Write the image
+	return rawCard.ToImage(w)
+}
+
+// Close closes the underlying reader (nothing to close for the processors of failed inputs)
 func (p *converterProcessor) Close() error {
+	if p.closer == nil {
+		return nil
+	}
 	return p.closer()
 }
 
@@ -82,21 +308,45 @@ func (p *converterProcessor) decode() {
 	}
 
 	// Read all from the input
+	start := time.Now()
 	data, err := io.ReadAll(p.reader)
 	if err != nil {
 		p.err = err
 		return
 	}
+	p.inputSize = int64(len(data))
+
+	// Scan the metadata of JPEG images for chara data
+	if isJPEG(data) {
+		p.charaCards = scanJPEG(data)
+	}
+
+	// Name the input format if it has no known magic number
+	if p.format == "" {
+		p.format = imageFormat(data)
+	}
+
+	// Keep the original input
+	if p.keepOriginal {
+		p.original = data
+	}
 
 	// Decode image
 	img, err := imgconv.Decode(bytes.NewReader(data))
-	if err != nil {
+	if err != nil && isWebP(data) {
+		// If decoding fails on a WebP image, try decoding the first frame (in case of an animated WebP)
+		img, err = decodeAnimatedWebP(data)
+	}
+	if err != nil && isAVIF(data) {
+		// If decoding fails on an AVIF image, decode it with the AVIF decoder
+		img, err = avif.Decode(bytes.NewReader(data))
+	} else if err != nil {
 		// If decoding fails try specialized decoding from jpeg (in case abnormal chrome subsampling)
 		img, err = jpeg.Decode(bytes.NewReader(data))
 	}
-	// If all decoders have failed, return the error
+	// If all decoders have failed, the input is neither a PNG nor a supported image
 	if err != nil {
-		p.err = err
+		p.err = fmt.Errorf("%w: %w", ErrNotPNG, err)
 		return
 	}
 
@@ -111,6 +361,7 @@ func (p *converterProcessor) decode() {
 
 	// Set a decoded flag to true
 	p.decoded = true
+	p.stats = ScanStats{CharaChunksFound: len(p.charaCards), BytesRead: p.inputSize, DurationNanos: time.Since(start).Nanoseconds()}
 
 	// Set the correct png data
 	p.pngData = pngData{
@@ -0,0 +1,141 @@
+package png
+
+import (
+	"bytes"
+	"context"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/jpeg"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/r3dpixel/toolkit/reqx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// countingReader counts the bytes read from the underlying reader
+type countingReader struct {
+	r io.Reader
+	n int
+}
+
+// Read reads from the underlying reader, counting the bytes read
+func (r *countingReader) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	r.n += n
+	return n, err
+}
+
+// createNoisyJPEG creates a JPEG of the given size that does not compress well
+func createNoisyJPEG(t *testing.T, width, height int) []byte {
+	t.Helper()
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	seed := uint32(1)
+	for index := range img.Pix {
+		seed = seed*1664525 + 1013904223
+		img.Pix[index] = uint8(seed >> 24)
+	}
+	buf := new(bytes.Buffer)
+	require.NoError(t, jpeg.Encode(buf, img, &jpeg.Options{Quality: 95}))
+	return buf.Bytes()
+}
+
+func TestConverterProcessor_ImageSize(t *testing.T) {
+	webpData, err := os.ReadFile("testdata/card.webp")
+	require.NoError(t, err)
+	webpConfig, _, err := image.DecodeConfig(bytes.NewReader(webpData))
+	require.NoError(t, err)
+	gifImage := image.NewPaletted(image.Rect(0, 0, 12, 7), []color.Color{color.Black, color.White})
+	gifBuffer := new(bytes.Buffer)
+	require.NoError(t, gif.Encode(gifBuffer, gifImage, nil))
+
+	tests := []struct {
+		name   string
+		data   []byte
+		width  int
+		height int
+	}{
+		{"JPEG", createNoisyJPEG(t, 640, 480), 640, 480},
+		{"WebP", webpData, webpConfig.Width, webpConfig.Height},
+		{"GIF", gifBuffer.Bytes(), 12, 7},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// The dimensions are read from the image header only
+			reader := &countingReader{r: bytes.NewReader(tt.data)}
+			processor := FromImage(io.NopCloser(reader))
+			require.IsType(t, &converterProcessor{}, processor)
+			width, height := processor.ImageSize()
+			assert.Equal(t, tt.width, width)
+			assert.Equal(t, tt.height, height)
+			assert.False(t, processor.(*converterProcessor).decoded)
+			assert.LessOrEqual(t, reader.n, 8*1024)
+
+			// The image is still converted afterwards
+			rawCard, err := processor.Get()
+			require.NoError(t, err)
+			assert.Equal(t, len(tt.data), reader.n)
+			assert.Equal(t, tt.width, rawCard.Width())
+			assert.Equal(t, tt.height, rawCard.Height())
+
+			// The size is the same once converted
+			width, height = processor.ImageSize()
+			assert.Equal(t, tt.width, width)
+			assert.Equal(t, tt.height, height)
+		})
+	}
+
+	t.Run("Large JPEG", func(t *testing.T) {
+		data := createNoisyJPEG(t, 2048, 1536)
+		require.Greater(t, len(data), 1024*1024)
+		reader := &countingReader{r: bytes.NewReader(data)}
+		width, height := FromImage(io.NopCloser(reader)).ImageSize()
+		assert.Equal(t, 2048, width)
+		assert.Equal(t, 1536, height)
+		assert.Less(t, reader.n, len(data)/100)
+	})
+
+	t.Run("Unreadable header", func(t *testing.T) {
+		processor := FromBytes([]byte("not an image at all, just some text"))
+		width, height := processor.ImageSize()
+		assert.Equal(t, -1, width)
+		assert.Equal(t, -1, height)
+		assert.ErrorIs(t, processor.Err(), ErrNotPNG)
+	})
+}
+
+func TestConverterProcessor_CloseErrorPaths(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	defer server.Close()
+	client := reqx.NewClient(reqx.Options{})
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	tests := []struct {
+		name      string
+		processor Processor
+	}{
+		{name: "Missing file", processor: FromFile(filepath.Join(t.TempDir(), "missing.png"))},
+		{name: "Invalid data URI", processor: FromDataURI("not a data URI")},
+		{name: "Invalid data URI payload", processor: FromDataURI("data:image/png;base64,!!!")},
+		{name: "Invalid base64", processor: FromBase64("!!!")},
+		{name: "Canceled context", processor: FromURLContext(canceled, client, server.URL)},
+		{name: "Failed fetch", processor: FromURL(client, server.URL)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Error(t, tt.processor.Err())
+			assert.NotPanics(t, func() {
+				assert.NoError(t, tt.processor.Close())
+			})
+		})
+	}
+}
@@ -3,8 +3,12 @@ package png
 import (
 	"bytes"
 	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
 	"io"
 	"slices"
+	"time"
 
 	"github.com/r3dpixel/card-parser/character"
 	"github.com/r3dpixel/toolkit/bytex"
@@ -43,37 +47,54 @@ var (
 	// Discriminator 'tEXt' (uint32) - 0x74455874
 	chunkTextTypeCode uint32 = 0x74455874
 	// Discriminator 'IEND' (uint32) - 0x49454E44
-	chunkIENDTypeCode = 0x49454E44
+	chunkIENDTypeCode uint32 = 0x49454E44
 	// 'chara' keyword (byte array)
 	charaKeyword = []byte{0x63, 0x68, 0x61, 0x72, 0x61, 0x00}
 	// 'ccv3' keyword (byte array)
 	ccv3Keyword = []byte{0x63, 0x63, 0x76, 0x33, 0x00}
 	// The standard PNG footer (byte array)
 	pngFooter = []byte{0x00, 0x00, 0x00, 0x00, 0x49, 0x45, 0x4E, 0x44, 0xAE, 0x42, 0x60, 0x82}
-
-	// List of keywords
-	keywords = map[character.Revision][]byte{
-		character.RevisionV2: charaKeyword,
-		character.RevisionV3: ccv3Keyword,
-	}
-	keywordsLength = map[character.Revision]int{
-		character.RevisionV2: len(charaKeyword),
-		character.RevisionV3: len(ccv3Keyword),
-	}
 )
 
+// ErrCRCMismatch is returned (with VerifyCRC, wrapped in a MalformedChunkError) when the CRC of a chunk does not match
+// its type and data
+var ErrCRCMismatch = errors.New("chunk CRC mismatch")
+
+// ErrChunkTooLarge is returned when a text chunk (or the text data retained across chunks) exceeds the maximum chunk size
+var ErrChunkTooLarge = errors.New("chunk exceeds the maximum size")
+
 // scanningProcessor implements the Processor interface and is used to scan PNG files for character data
 type scanningProcessor struct {
+	attemptRecorder
+
 	// Scanner properties
-	header   []byte
-	reader   io.ReadCloser
-	scanMode ScanMode
+	header        []byte
+	reader        io.ReadCloser
+	scanMode      ScanMode
+	verifyCRC     bool
+	lenient       bool
+	maxChunkSize  int
+	trackOffsets  bool
+	requireChara  bool
+	keepTrailing  bool
+	inputSize     int64 // Size of the input in bytes (-1 if unknown)
+	skippedPrefix int   // Number of junk bytes skipped before the PNG signature
+	onChunk       func(typeCode uint32, length uint32)
 
 	// Scanner state and caches
 	bodyBuffer   *bytes.Buffer
+	output       io.Writer    // Destination of the copied chunks (the body buffer, or the Pipe writer)
+	beforeIEND   func() error // Called before the IEND chunk is copied (used by Pipe)
 	chunkDetails chunkDetails
 	chunkBuffer  []byte
 	rawCard      *RawCard
+	collectAll   bool
+	rawCards     []*RawCard
+	retainedSize int  // Size of the text data retained across chunks (bounded by the maximum chunk size)
+	charaSeen    bool // Set once a chara chunk was found
+	stats        ScanStats
+	stoppedEarly bool // Set when the scan stopped at the first chara chunk (the rest is copied as is)
+	offset       int64
 	err          error
 }
 
@@ -86,9 +107,11 @@ type chunkDetails struct {
 // newScanningProcessor creates a new PNG scanner processor
 func newScanningProcessor(header []byte, r io.ReadCloser) *scanningProcessor {
 	s := &scanningProcessor{
-		header:   header,
-		reader:   r,
-		scanMode: DefaultScanMode,
+		header:       header,
+		reader:       r,
+		scanMode:     DefaultScanMode,
+		maxChunkSize: DefaultMaxChunkSize,
+		inputSize:    -1,
 	}
 	return s
 }
@@ -117,11 +140,109 @@ func (p *scanningProcessor) LastLongest() Processor {
 	return p
 }
 
+// LastVersionLongest sets the processor to scan for the latest chara chunk (highest revision), the longest one among
+// the chunks of that revision
+func (p *scanningProcessor) LastVersionLongest() Processor {
+	p.scanMode = LastVersionLongest
+	return p
+}
+
+// VerifyCRC enables the verification of the CRC of every chunk (the processing fails with ErrCRCMismatch on mismatch)
+func (p *scanningProcessor) VerifyCRC() Processor {
+	p.verifyCRC = true
+	return p
+}
+
+// Lenient enables the permissive detection of mislabeled chara chunks (case-insensitive or suffixed keywords,
+// missing null separators, and unlabeled base64/raw JSON payloads); the strict detection is used by default
+func (p *scanningProcessor) Lenient() Processor {
+	p.lenient = true
+	return p
+}
+
+// MaxChunkSize sets the maximum size of a text chunk, and of the text data retained across chunks (chara data and
+// collected `tEXt` chunks), 0 means no limit; larger chunks fail the processing with ErrChunkTooLarge before being
+// read, and compressed chunks inflating past the limit are ignored
+func (p *scanningProcessor) MaxChunkSize(size int) Processor {
+	p.maxChunkSize = size
+	return p
+}
+
+// TrackOffsets enables the reporting of the position of every detected chara chunk in the input (RawCard.ChunkSpans),
+// so the chunk can be replaced in place (see ReplaceChunkAt)
+func (p *scanningProcessor) TrackOffsets() Processor {
+	p.trackOffsets = true
+	return p
+}
+
+// KeepOriginal returns the processor itself as PNG input is not converted (the chunks are copied as they are read)
+func (p *scanningProcessor) KeepOriginal() Processor {
+	return p
+}
+
+// KeepTrailingData keeps the bytes found after the IEND chunk on the raw card (RawCard.TrailingData), for inspection
+// They are never copied to the body (or the Pipe writer), and are not read at all by default
+func (p *scanningProcessor) KeepTrailingData() Processor {
+	p.keepTrailing = true
+	return p
+}
+
+// OnChunk sets a callback called with the type and data length of every chunk read (IHDR included), in file order
+// The callback runs on the scanning goroutine, and must not retain or modify the processor
+func (p *scanningProcessor) OnChunk(callback func(typeCode uint32, length uint32)) Processor {
+	p.onChunk = callback
+	return p
+}
+
+// RequireCharacterData makes Get and GetAll fail with ErrNoCharacterData if the PNG has no chara chunk
+func (p *scanningProcessor) RequireCharacterData() Processor {
+	p.requireChara = true
+	return p
+}
+
+// SourceFormat returns png, as PNG input is scanned without conversion
+func (p *scanningProcessor) SourceFormat() string {
+	return "png"
+}
+
+// Validate checks the image against the constraints (from the IHDR header, without reading the image body)
+// Returns a joined error listing every violated rule; if the input size is unknown (e.g. streamed from a URL),
+// the maximum size is enforced while reading instead (the processing fails with ErrConstraintViolated)
+func (p *scanningProcessor) Validate(constraints Constraints) error {
+	// If there is an error return error
+	if p.err != nil {
+		return p.err
+	}
+
+	// Check the dimensions
+	violations := constraints.checkImage(p.ImageSize())
+
+	// Check the input size (bound the reading if the size is unknown)
+	switch {
+	case p.inputSize >= 0:
+		violations = append(violations, constraints.checkFileBytes(p.inputSize))
+	case constraints.MaxFileBytes > 0:
+		p.reader = &maxBytesReader{
+			ReadCloser: p.reader,
+			remaining:  constraints.MaxFileBytes - int64(p.skippedPrefix+len(p.header)),
+			err:        fmt.Errorf("%w: file size exceeds the maximum %d bytes", ErrConstraintViolated, constraints.MaxFileBytes),
+		}
+	}
+
+	// Return the violations
+	return errors.Join(violations...)
+}
+
 // Err returns any error that occurred during processing
 func (p *scanningProcessor) Err() error {
 	return p.err
 }
 
+// Stats returns the statistics of the last scan (Get, GetAll or Pipe), the zero value before
+func (p *scanningProcessor) Stats() ScanStats {
+	return p.stats
+}
+
 // ImageSize returns the width and height of the PNG image
 func (p *scanningProcessor) ImageSize() (int, int) {
 	if p.err != nil {
@@ -132,6 +253,31 @@ func (p *scanningProcessor) ImageSize() (int, int) {
 
 // Get processes the PNG and returns a RawCard with extracted character data
 func (p *scanningProcessor) Get() (*RawCard, error) {
+	return p.scan()
+}
+
+// GetAll processes the entire PNG (regardless of the scan mode) and returns a RawCard for every chara chunk found,
+// in the order the chunks appear in the file
+func (p *scanningProcessor) GetAll() ([]*RawCard, error) {
+	// Scan the PNG collecting every chara chunk
+	p.collectAll = true
+	rawCard, err := p.scan()
+	if err != nil {
+		return nil, err
+	}
+
+	// Every raw card shares the image data (the chara chunks are stripped from the body)
+	for _, card := range p.rawCards {
+		card.pngData = rawCard.pngData
+		card.SkippedPrefix, card.TrailingData = rawCard.SkippedPrefix, rawCard.TrailingData
+	}
+
+	// Return all raw cards
+	return p.rawCards, nil
+}
+
+// scan processes the PNG chunks and returns a RawCard with the chara data selected by the scan mode
+func (p *scanningProcessor) scan() (*RawCard, error) {
 	defer p.reader.Close()
 
 	// If there is an error return error
@@ -141,32 +287,111 @@ func (p *scanningProcessor) Get() (*RawCard, error) {
 
 	// Allocate new byte buffers
 	p.bodyBuffer = bytes.NewBuffer(make([]byte, 0, 32*bytex.KiB))
+	p.output = p.bodyBuffer
+
+	// Process PNG chunks
+	if err := p.processChunks(); err != nil {
+		return nil, err
+	}
+
+	// Reject the PNG without chara data if required
+	if p.requireChara && len(p.rawCard.RawCharaData) == 0 {
+		return nil, ErrNoCharacterData
+	}
+
+	// Set the body, and return the raw card
+	p.rawCard.Body = p.bodyBuffer.Bytes()
+	return p.rawCard, nil
+}
+
+// Pipe streams the PNG to the writer without buffering the image body: the chunks are copied as they are read,
+// while the chara chunks are collected, passed to the transform (if any), and written right before the IEND chunk
+// (the raw card passed to the transform has no body)
+// The non-chara `tEXt` chunks are copied in place, so only the text chunks added by the transform are written
+func (p *scanningProcessor) Pipe(w io.Writer, transform func(*RawCard) error) error {
+	defer p.reader.Close()
+
+	// If there is an error return error
+	if p.err != nil {
+		return p.err
+	}
+
+	// Write the image header
+	if _, err := w.Write(p.header); err != nil {
+		return err
+	}
+
+	// Write the chara chunks before IEND (applying the transform first)
+	p.output = w
+	p.beforeIEND = func() error {
+		if transform != nil {
+			if err := transform(p.rawCard); err != nil {
+				return err
+			}
+		}
+		if err := p.rawCard.streamCharaChunks(w); err != nil {
+			return err
+		}
+		return streamTextChunks(w, p.rawCard.addedTextChunks())
+	}
+
+	// Stream the PNG chunks
+	if err := p.processChunks(); err != nil {
+		return err
+	}
 
+	// Write the chara chunks if the image has no IEND chunk
+	if p.beforeIEND != nil {
+		return p.beforeIEND()
+	}
+	return nil
+}
+
+// processChunks processes every PNG chunk after the header, copying the non-chara chunks to the output
+func (p *scanningProcessor) processChunks() error {
 	// Set the correct image header
 	p.rawCard = &RawCard{
 		pngData: pngData{
 			Header: p.header,
 		},
+		SkippedPrefix: p.skippedPrefix,
 	}
 
+	// Start the statistics (the header was read when creating the processor)
+	start := time.Now()
+	p.stats = ScanStats{BytesRead: int64(p.skippedPrefix + len(p.header))}
+	p.reader = countingReadCloser{ReadCloser: p.reader, n: &p.stats.BytesRead}
+	defer func() { p.stats.DurationNanos = time.Since(start).Nanoseconds() }()
+	p.countChunk(binary.BigEndian.Uint32(p.header[headerSize+chunkLengthSize:]), binary.BigEndian.Uint32(p.header[headerSize:]))
+
+	// Verify the IHDR chunk
+	if p.verifyCRC {
+		ihdr := p.header[headerSize:]
+		typeAndData, crc := ihdr[chunkLengthSize:len(ihdr)-chunkCrcSize], ihdr[len(ihdr)-chunkCrcSize:]
+		typeCode, computed := binary.BigEndian.Uint32(typeAndData), crc32.ChecksumIEEE(typeAndData)
+		if err := checkCRC(typeCode, computed, binary.BigEndian.Uint32(crc), int64(headerSize)); err != nil {
+			return err
+		}
+	}
+	p.offset = int64(fullIhdrSize)
+
 	// Process PNG chunks
 	for {
 		// Process the PNG chunk
 		err := p.processChunk()
-		// If EOF, copy any remaining data, and set the body
+		// If EOF, copy any remaining data
 		if err == io.EOF {
-			// Copy remaining data
-			if _, copyErr := io.Copy(p.bodyBuffer, p.reader); copyErr != nil {
-				return nil, copyErr
-			}
-			// Set the body
-			p.rawCard.Body = p.bodyBuffer.Bytes()
-			// Return the raw card
-			return p.rawCard, nil
+			p.stats.ScannedToEnd = !p.stoppedEarly
+			return p.copyRemaining()
 		}
 		// If any other error occurred, return error
 		if err != nil {
-			return nil, err
+			return err
+		}
+		// Stop after the IEND chunk
+		if p.chunkDetails.typeCode == chunkIENDTypeCode {
+			p.stats.ScannedToEnd = true
+			return p.readTrailing()
 		}
 	}
 }
@@ -175,98 +400,353 @@ func (p *scanningProcessor) Close() error {
 	return p.reader.Close()
 }
 
-// processChunk processes a single PNG chunk and extracts character data if present
-func (p *scanningProcessor) processChunk() error {
-	// Read the PNG chunk length
+// readChunkDetails reads the length and discriminator of the next PNG chunk, and advances the offset past the chunk
+func (p *scanningProcessor) readChunkDetails() (int64, error) {
+	// Read the PNG chunk length (io.EOF is returned as is if the input ends cleanly before it)
+	p.chunkDetails.typeCode = 0
 	if err := binary.Read(p.reader, binary.BigEndian, &p.chunkDetails.length); err != nil {
-		return err
+		if err == io.EOF {
+			return 0, err
+		}
+		return 0, p.malformed(p.offset, err)
 	}
 
 	// Read the PNG chunk discriminator
 	if err := binary.Read(p.reader, binary.BigEndian, &p.chunkDetails.typeCode); err != nil {
+		p.chunkDetails.typeCode = 0
+		return 0, p.malformed(p.offset, err)
+	}
+
+	// Account for the chunk, advance the offset past the chunk, and return the chunk offset
+	p.countChunk(p.chunkDetails.typeCode, p.chunkDetails.length)
+	offset := p.offset
+	p.offset += int64(chunkHeaderSize) + int64(p.chunkDetails.length)
+	return offset, nil
+}
+
+// copyRemaining copies the remaining PNG chunks to the output stream, chunk by chunk (collecting the non-chara `tEXt` chunks)
+func (p *scanningProcessor) copyRemaining() error {
+	// Copy every chunk up to IEND
+	for {
+		offset, err := p.readChunkDetails()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if p.chunkDetails.typeCode == chunkTextTypeCode {
+			err = p.collectTextChunk(offset)
+		} else {
+			err = p.streamCopyChunk(offset)
+		}
+		if err != nil {
+			return err
+		}
+		// Stop after the IEND chunk
+		if p.chunkDetails.typeCode == chunkIENDTypeCode {
+			return p.readTrailing()
+		}
+	}
+}
+
+// readTrailing keeps the data after the IEND chunk on the raw card if enabled (it is never copied to the output)
+func (p *scanningProcessor) readTrailing() error {
+	if !p.keepTrailing {
+		return nil
+	}
+	trailing, err := io.ReadAll(p.reader)
+	if len(trailing) > 0 {
+		p.rawCard.TrailingData = trailing
+	}
+	return err
+}
+
+// collectTextChunk collects a `tEXt` chunk found after the scan stopped (chara chunks are copied to the output stream as is)
+func (p *scanningProcessor) collectTextChunk(offset int64) error {
+	crc, err := p.readTextChunk(offset)
+	if err != nil {
 		return err
 	}
+	if revision, _, isChara := p.isCharaTextChunk(TEXT, p.chunkBuffer); isChara {
+		p.stats.CharaChunksFound++
+		p.trackSpan(p.rawCard, offset, revision)
+		return p.writeChunk(crc)
+	}
+	return p.retainTextChunk(offset, crc)
+}
 
-	// If the PNG chunk IS NOT a `tEXt` chunk, stream copy it directly to the output
-	if p.chunkDetails.typeCode != chunkTextTypeCode {
-		return p.streamCopyChunk()
+// retainTextChunk collects the buffered `tEXt` chunk, and copies it to the output (the chunk keeps its position)
+func (p *scanningProcessor) retainTextChunk(offset int64, crc uint32) error {
+	if err := p.retain(len(p.chunkBuffer), offset); err != nil {
+		return err
 	}
+	chunk := newTextChunk(p.chunkBuffer)
+	p.rawCard.TextChunks = append(p.rawCard.TextChunks, chunk)
+	p.rawCard.scannedTextChunks = append(p.rawCard.scannedTextChunks, chunk)
+	return p.writeChunk(crc)
+}
 
-	// Reset the buffer
-	p.chunkBuffer = p.chunkBuffer[:0]
-	// If the buffer is not large enough, allocate a new one
-	if int(p.chunkDetails.length) > cap(p.chunkBuffer) {
-		p.chunkBuffer = make([]byte, p.chunkDetails.length)
+// retain accounts for text data retained from the chunk at the given offset, and checks the maximum chunk size
+func (p *scanningProcessor) retain(size int, offset int64) error {
+	p.retainedSize += size
+	if p.maxChunkSize > 0 && p.retainedSize > p.maxChunkSize {
+		return fmt.Errorf("%w: text data retained up to the chunk at offset %d is %d bytes (limit %d)", ErrChunkTooLarge, offset, p.retainedSize, p.maxChunkSize)
 	}
-	// Resize the buffer
-	p.chunkBuffer = p.chunkBuffer[:p.chunkDetails.length]
+	return nil
+}
 
-	// Read chunk data
-	if _, err := io.ReadFull(p.reader, p.chunkBuffer); err != nil {
+// processChunk processes a single PNG chunk and extracts character data if present
+func (p *scanningProcessor) processChunk() error {
+	// Read the PNG chunk length and discriminator
+	offset, err := p.readChunkDetails()
+	if err != nil {
 		return err
 	}
 
-	// Discard the CRC hash
-	if _, err := io.CopyN(io.Discard, p.reader, 4); err != nil {
+	// If the PNG chunk IS NOT a text chunk (`tEXt`, `zTXt` or `iTXt`), stream copy it directly to the output
+	format, isText := chunkFormats[p.chunkDetails.typeCode]
+	if !isText {
+		return p.streamCopyChunk(offset)
+	}
+
+	// Read the text chunk data and CRC hash
+	crc, err := p.readTextChunk(offset)
+	if err != nil {
 		return err
 	}
 
-	// Check if the PNG chunks contains chara data
-	revision, isChara := p.isCharaChunk(p.chunkBuffer)
-	// If not discard chunk
+	// Check if the PNG chunks contains chara data (inflating compressed text chunks first)
+	revision, charaData, isChara := p.isCharaTextChunk(format, p.chunkBuffer)
+	// If not, collect `tEXt` chunks, and keep every text chunk in place
 	if !isChara {
-		return nil
+		if format == TEXT {
+			return p.retainTextChunk(offset, crc)
+		}
+		return p.writeChunk(crc)
+	}
+
+	// Bound the chara data retained across chunks
+	p.stats.CharaChunksFound++
+	if err := p.retain(len(charaData), offset); err != nil {
+		return err
+	}
+
+	// Remember where the first chara chunk was in the body (see PreserveOriginalPosition)
+	if !p.charaSeen && p.output == p.bodyBuffer {
+		p.rawCard.charaOffset = p.bodyBuffer.Len()
+	}
+	p.charaSeen = true
+
+	// Collect every chara chunk if requested
+	p.trackSpan(p.rawCard, offset, revision)
+	if p.collectAll {
+		rawCard := &RawCard{
+			pngData:      pngData{chunkFormat: format},
+			Revision:     revision,
+			RawCharaData: slices.Clone(charaData),
+			Encoding:     detectBase64(charaData),
+		}
+		p.trackSpan(rawCard, offset, revision)
+		p.rawCards = append(p.rawCards, rawCard)
 	}
 
 	// Check if chara chunk revision is higher than the current revision
-	if p.scanMode.criteria(p.rawCard, p.chunkBuffer, revision) {
+	if p.scanMode.criteria(p.rawCard, charaData, revision) {
 		p.rawCard.Revision = revision
-		p.rawCard.RawCharaData = slices.Clone(p.chunkBuffer[keywordsLength[revision]:])
+		p.rawCard.RawCharaData = slices.Clone(charaData)
+		p.rawCard.Encoding = detectBase64(charaData)
+		p.rawCard.chunkFormat = format
 	}
 
 	// If deep scan is disabled, and we have found a chara chunk return io.EOF so the rest is stream copied
-	if !p.scanMode.deepScan && len(p.rawCard.RawCharaData) > 0 {
+	if !p.scanMode.deepScan && !p.collectAll && len(p.rawCard.RawCharaData) > 0 {
+		p.stoppedEarly = true
 		return io.EOF
 	}
 
 	return nil
 }
 
-// streamCopyChunk copies a non-character chunk to the output stream
-func (p *scanningProcessor) streamCopyChunk() error {
+// trackSpan records the position of the current chara chunk (at the given offset) on the raw card, if enabled
+func (p *scanningProcessor) trackSpan(rawCard *RawCard, offset int64, revision character.Revision) {
+	if p.trackOffsets {
+		rawCard.ChunkSpans = append(rawCard.ChunkSpans, ChunkSpan{
+			Offset:   offset,
+			Length:   int64(chunkHeaderSize) + int64(p.chunkDetails.length),
+			Revision: revision,
+		})
+	}
+}
+
+// readTextChunk reads the text chunk data into the chunk buffer, and returns the CRC hash (verified if enabled)
+func (p *scanningProcessor) readTextChunk(offset int64) (uint32, error) {
+	// Reject the chunk before allocating if it exceeds the maximum size
+	if p.maxChunkSize > 0 && int64(p.chunkDetails.length) > int64(p.maxChunkSize) {
+		chunkType := binary.BigEndian.AppendUint32(nil, p.chunkDetails.typeCode)
+		return 0, fmt.Errorf("%w: %q chunk at offset %d declares %d bytes (limit %d)", ErrChunkTooLarge, chunkType, offset, p.chunkDetails.length, p.maxChunkSize)
+	}
+
+	// Reset the buffer
+	p.chunkBuffer = p.chunkBuffer[:0]
+	// If the buffer is not large enough, allocate a new one
+	if int(p.chunkDetails.length) > cap(p.chunkBuffer) {
+		p.chunkBuffer = make([]byte, p.chunkDetails.length)
+	}
+	// Resize the buffer
+	p.chunkBuffer = p.chunkBuffer[:p.chunkDetails.length]
+
+	// Read chunk data
+	if _, err := io.ReadFull(p.reader, p.chunkBuffer); err != nil {
+		return 0, p.malformed(offset, err)
+	}
+
+	// Read the CRC hash
+	var crc uint32
+	if err := binary.Read(p.reader, binary.BigEndian, &crc); err != nil {
+		return 0, p.malformed(offset, err)
+	}
+
+	// Verify the CRC hash
+	if p.verifyCRC {
+		crcHasher := crc32.NewIEEE()
+		_ = binary.Write(crcHasher, binary.BigEndian, p.chunkDetails.typeCode)
+		_, _ = crcHasher.Write(p.chunkBuffer)
+		if err := checkCRC(p.chunkDetails.typeCode, crcHasher.Sum32(), crc, offset); err != nil {
+			return 0, err
+		}
+	}
+
+	return crc, nil
+}
+
+// streamCopyChunk copies a non-character chunk to the output stream (verifying the CRC if enabled)
+func (p *scanningProcessor) streamCopyChunk(offset int64) error {
+	// Detect animated images
+	if p.chunkDetails.typeCode == chunkACTLTypeCode {
+		p.rawCard.animated = true
+	}
+
+	// Run the IEND hook (once)
+	if p.chunkDetails.typeCode == chunkIENDTypeCode && p.beforeIEND != nil {
+		beforeIEND := p.beforeIEND
+		p.beforeIEND = nil
+		if err := beforeIEND(); err != nil {
+			return err
+		}
+	}
+
 	// Write the PNG chunk length
-	if err := binary.Write(p.bodyBuffer, binary.BigEndian, p.chunkDetails.length); err != nil {
+	if err := binary.Write(p.output, binary.BigEndian, p.chunkDetails.length); err != nil {
 		return err
 	}
 
 	// Write the PNG chunk discriminator
-	if err := binary.Write(p.bodyBuffer, binary.BigEndian, p.chunkDetails.typeCode); err != nil {
+	if err := binary.Write(p.output, binary.BigEndian, p.chunkDetails.typeCode); err != nil {
 		return err
 	}
 
 	// Write the PNG chunk content and the CRC hash
-	if _, err := io.CopyN(p.bodyBuffer, p.reader, int64(p.chunkDetails.length)+4); err != nil {
+	if !p.verifyCRC {
+		_, err := io.CopyN(p.output, p.reader, int64(p.chunkDetails.length)+4)
+		return p.malformed(offset, err)
+	}
+
+	// Write the PNG chunk content, while computing the CRC hash
+	crcHasher := crc32.NewIEEE()
+	_ = binary.Write(crcHasher, binary.BigEndian, p.chunkDetails.typeCode)
+	if _, err := io.CopyN(io.MultiWriter(p.output, crcHasher), p.reader, int64(p.chunkDetails.length)); err != nil {
+		return p.malformed(offset, err)
+	}
+
+	// Read, verify and write the CRC hash
+	var crc uint32
+	if err := binary.Read(p.reader, binary.BigEndian, &crc); err != nil {
+		return p.malformed(offset, err)
+	}
+	if err := checkCRC(p.chunkDetails.typeCode, crcHasher.Sum32(), crc, offset); err != nil {
 		return err
 	}
+	return binary.Write(p.output, binary.BigEndian, crc)
+}
 
-	// Return
-	return nil
+// writeChunk writes the buffered chunk to the output stream
+func (p *scanningProcessor) writeChunk(crc uint32) error {
+	// Write the PNG chunk length
+	if err := binary.Write(p.output, binary.BigEndian, p.chunkDetails.length); err != nil {
+		return err
+	}
+	// Write the PNG chunk discriminator
+	if err := binary.Write(p.output, binary.BigEndian, p.chunkDetails.typeCode); err != nil {
+		return err
+	}
+	// Write the PNG chunk content
+	if _, err := p.output.Write(p.chunkBuffer); err != nil {
+		return err
+	}
+	// Write the CRC hash
+	return binary.Write(p.output, binary.BigEndian, crc)
+}
+
+// malformed returns a MalformedChunkError of the current chunk (at the given offset) if the input ends in the middle
+// of it, other errors are returned as is
+func (p *scanningProcessor) malformed(offset int64, err error) error {
+	if isTruncated(err) {
+		return newMalformedChunkError(p.chunkDetails.typeCode, offset, io.ErrUnexpectedEOF)
+	}
+	return err
 }
 
-// isCharaChunk checks if chunk data contains character information and returns the revision
-func (p *scanningProcessor) isCharaChunk(chunkData []byte) (character.Revision, bool) {
+// checkCRC returns a MalformedChunkError wrapping ErrCRCMismatch if the computed CRC does not match the stored CRC
+func checkCRC(typeCode uint32, computed uint32, stored uint32, offset int64) error {
+	if computed == stored {
+		return nil
+	}
+	return newMalformedChunkError(typeCode, offset, fmt.Errorf("%w (stored %08x, computed %08x)", ErrCRCMismatch, stored, computed))
+}
+
+// isCharaTextChunk checks if the text chunk data (of the given format) contains character information
+// Compressed chunks are only inflated when they carry a chara keyword (or with the permissive detection), and are
+// ignored if they inflate past the maximum chunk size
+func (p *scanningProcessor) isCharaTextChunk(format ChunkFormat, chunkData []byte) (character.Revision, []byte, bool) {
+	if format != TEXT {
+		if !p.lenient && !isCharaKeyword(chunkData) {
+			return character.RevisionV2, nil, false
+		}
+		textData, ok := format.decodeText(chunkData, p.maxChunkSize)
+		if !ok {
+			return character.RevisionV2, nil, false
+		}
+		chunkData = textData
+	}
+	return p.isCharaChunk(chunkData)
+}
+
+// isCharaChunk checks if chunk data contains character information and returns the revision and the chara data
+func (p *scanningProcessor) isCharaChunk(chunkData []byte) (character.Revision, []byte, bool) {
 	// Return false (no chara data)
 	if len(chunkData) == 0 {
-		return character.RevisionV2, false
+		return character.RevisionV2, nil, false
 	}
 
 	// Detect the correct revision and keyword
-	for revision, keyword := range keywords {
-		if bytes.HasPrefix(chunkData, keyword) {
-			return revision, true
+	for revision, keyword := range chunkKeywords() {
+		if !bytes.HasPrefix(chunkData, keyword) {
+			continue
 		}
+		// Raw JSON payloads are base64 encoded in the permissive detection
+		charaData := chunkData[len(keyword):]
+		if p.lenient && bytes.HasPrefix(charaData, []byte("{")) {
+			charaData, _, _ = sniffCharaData(charaData)
+		}
+		return revision, charaData, charaData != nil
+	}
+
+	// Fallback to the permissive detection if enabled
+	if p.lenient {
+		return lenientCharaChunk(chunkData)
 	}
 
 	// No chara keyword detected
-	return character.RevisionV2, false
+	return character.RevisionV2, nil, false
 }
@@ -0,0 +1,51 @@
+package png
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+
+	"github.com/r3dpixel/card-parser/character"
+)
+
+// rawFingerprintPrefix prefix of the fingerprints of the original JSON (algorithm, without behavior version)
+const rawFingerprintPrefix = "sha256:"
+
+// Fingerprint returns the hash of the original JSON the card was decoded from (e.g. sha256:<hex>), or an empty string
+// if the card was not decoded (no RawJSON); unlike the canonical character.Sheet.Fingerprint (still available through
+// cc.Sheet.Fingerprint), it changes with the exact bytes of the payload (key order, whitespace), as needed for audits
+func (cc *CharacterCard) Fingerprint() string {
+	if cc.RawJSON == nil {
+		return ""
+	}
+	sum := sha256.Sum256(cc.RawJSON)
+	return rawFingerprintPrefix + hex.EncodeToString(sum[:])
+}
+
+// EncodePreservingRaw converts a CharacterCard to a RawCard like Encode, but embeds the original JSON untouched when
+// the sheet was not modified since it was decoded (lossless passthrough); modified sheets are re-serialized
+// The sheet is compared with a re-parse of RawJSON (character.Sheet.DeepEquals), so changes to the spec/version made
+// while decoding (e.g. a V3 sheet found in a chara chunk) also re-serialize the sheet; changes to the raw fields
+// ignored by DeepEquals (e.g. RawTopLevel) are not detected
+func (cc *CharacterCard) EncodePreservingRaw() (*RawCard, error) {
+	// Re-serialize cards that were not decoded, or whose sheet was modified
+	if !cc.rawUnmodified() {
+		return cc.Encode()
+	}
+
+	// Embed the original JSON
+	rjc := &RawJsonCard{
+		pngData:     cc.pngData,
+		RawJsonData: cc.RawJSON,
+		Revision:    cc.Sheet.Revision,
+	}
+	return rjc.ToRaw(), nil
+}
+
+// rawUnmodified checks if the sheet still matches the original JSON it was decoded from
+func (cc *CharacterCard) rawUnmodified() bool {
+	if cc.Sheet == nil || len(cc.RawJSON) == 0 {
+		return false
+	}
+	original, err := character.FromBytes(cc.RawJSON)
+	return err == nil && original.DeepEquals(cc.Sheet)
+}
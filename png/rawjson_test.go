@@ -0,0 +1,128 @@
+package png
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"testing"
+
+	"github.com/r3dpixel/card-parser/character"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// unusualKeyOrderJSON V3 sheet with the data before the spec, unusual key order and whitespace
+const unusualKeyOrderJSON = `{ "data": {"tags": ["b", "a"], "name": "Raw",  "first_mes": "Hi", "description": "Kept as is"},
+  "spec_version": "3.0", "spec": "chara_card_v3" }`
+
+// decodeRawFixture returns the card decoded from a PNG with the fixture JSON in a ccv3 chunk
+func decodeRawFixture(t *testing.T) *CharacterCard {
+	t.Helper()
+	charaData := []byte(base64.StdEncoding.EncodeToString([]byte(unusualKeyOrderJSON)))
+	data := injectChunk(t, createTestPNG(t, 4, 4), character.RevisionV3, charaData, false)
+	rawCard, err := FromBytes(data).Get()
+	require.NoError(t, err)
+	card, err := rawCard.Decode()
+	require.NoError(t, err)
+	return card
+}
+
+func TestCharacterCard_RawJSON(t *testing.T) {
+	card := decodeRawFixture(t)
+	assert.Equal(t, []byte(unusualKeyOrderJSON), card.RawJSON)
+
+	// The fingerprint hashes the original bytes, not a re-serialization
+	sum := sha256.Sum256([]byte(unusualKeyOrderJSON))
+	assert.Equal(t, "sha256:"+hex.EncodeToString(sum[:]), card.Fingerprint())
+	assert.NotEqual(t, card.Sheet.Fingerprint(), card.Fingerprint())
+
+	// Resized cards keep the original JSON
+	resized, err := card.Resized(2)
+	require.NoError(t, err)
+	assert.Equal(t, card.RawJSON, resized.RawJSON)
+
+	// Cards built from sheets have no original JSON
+	built := &CharacterCard{pngData: card.pngData, Sheet: character.DefaultSheet(character.RevisionV3)}
+	assert.Empty(t, built.Fingerprint())
+}
+
+func TestCharacterCard_EncodePreservingRaw(t *testing.T) {
+	decodedJSON := func(t *testing.T, rawCard *RawCard) []byte {
+		rjc, err := rawCard.ToRawJson()
+		require.NoError(t, err)
+		return rjc.RawJsonData
+	}
+
+	t.Run("Encode re-serializes", func(t *testing.T) {
+		rawCard, err := decodeRawFixture(t).Encode()
+		require.NoError(t, err)
+		assert.NotEqual(t, []byte(unusualKeyOrderJSON), decodedJSON(t, rawCard))
+	})
+
+	t.Run("Unmodified sheet keeps the bytes", func(t *testing.T) {
+		rawCard, err := decodeRawFixture(t).EncodePreservingRaw()
+		require.NoError(t, err)
+		assert.Equal(t, []byte(unusualKeyOrderJSON), decodedJSON(t, rawCard))
+		assert.Equal(t, character.RevisionV3, rawCard.Revision)
+
+		// The passthrough survives a full image round trip
+		data, err := rawCard.ToBytes()
+		require.NoError(t, err)
+		reread, err := FromBytes(data).Get()
+		require.NoError(t, err)
+		assert.Equal(t, []byte(unusualKeyOrderJSON), decodedJSON(t, reread))
+	})
+
+	t.Run("Modified sheet re-serializes", func(t *testing.T) {
+		card := decodeRawFixture(t)
+		card.Name = "Changed"
+		rawCard, err := card.EncodePreservingRaw()
+		require.NoError(t, err)
+		reencoded := decodedJSON(t, rawCard)
+		assert.NotEqual(t, []byte(unusualKeyOrderJSON), reencoded)
+		assert.Contains(t, string(reencoded), `"Changed"`)
+	})
+
+	t.Run("Restamped revision re-serializes", func(t *testing.T) {
+		card := decodeRawFixture(t)
+		card.SetRevision(character.RevisionV2)
+		rawCard, err := card.EncodePreservingRaw()
+		require.NoError(t, err)
+		assert.Equal(t, character.RevisionV2, rawCard.Revision)
+		assert.NotEqual(t, []byte(unusualKeyOrderJSON), decodedJSON(t, rawCard))
+	})
+}
+
+func TestRawJsonCard_ToCharacterLazy(t *testing.T) {
+	sheet := character.DefaultSheet(character.RevisionV3)
+	sheet.Name = "Lazy"
+	sheet.CharacterBook = &character.Book{Name: "Lore", Entries: []*character.BookEntry{character.FilledBookEntry("key", "content")}}
+	sheetJSON, err := sheet.ToBytes()
+	require.NoError(t, err)
+	full, err := character.FromBytes(sheetJSON)
+	require.NoError(t, err)
+	data := injectChunk(t, createTestPNG(t, 4, 4), character.RevisionV3, []byte(base64.StdEncoding.EncodeToString(sheetJSON)), false)
+	rawCard, err := FromBytes(data).Get()
+	require.NoError(t, err)
+	rjc, err := rawCard.ToRawJson()
+	require.NoError(t, err)
+
+	// The lorebook is not decoded until requested
+	card, err := rjc.ToCharacterLazy()
+	require.NoError(t, err)
+	assert.Equal(t, sheetJSON, card.RawJSON)
+	assert.Equal(t, character.RevisionV3, card.Revision)
+	assert.Nil(t, card.CharacterBook)
+
+	// The pending lorebook is kept when encoding
+	encoded, err := card.Encode()
+	require.NoError(t, err)
+	reread, err := encoded.Decode()
+	require.NoError(t, err)
+	assert.Equal(t, full.CharacterBook, reread.CharacterBook)
+
+	// The lorebook is decoded on request
+	book, err := card.Book()
+	require.NoError(t, err)
+	assert.Equal(t, full.CharacterBook, book)
+}
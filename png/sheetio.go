@@ -0,0 +1,70 @@
+package png
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/r3dpixel/card-parser/character"
+)
+
+// ErrUnsupportedSource is returned by ExtractSheet and EmbedSheet when the source is not a path, bytes or a reader
+var ErrUnsupportedSource = errors.New("unsupported image source")
+
+// ExtractSheet returns the sheet of the card image at the source: a file path (string), the image bytes ([]byte), or
+// an io.Reader (not closed, the caller owns it); the chara data is selected with the DefaultScanMode
+// Returns ErrNoCharacterData if the image has no chara data (see FromFile, FromBytes and FromImage for the other errors)
+func ExtractSheet(src any) (*character.Sheet, error) {
+	processor, err := sourceProcessor(src)
+	if err != nil {
+		return nil, err
+	}
+
+	// Read and decode the card
+	rawCard, err := processor.RequireCharacterData().Get()
+	if err != nil {
+		return nil, err
+	}
+	card, err := rawCard.Decode()
+	if err != nil {
+		return nil, err
+	}
+	return card.Sheet, nil
+}
+
+// EmbedSheet reads the image at the source (see ExtractSheet for the accepted sources), replaces its chara data with
+// the sheet, and writes the resulting PNG image to the writer (images that are not PNG are converted)
+// The chunk keyword is picked from the sheet revision; the non-chara chunks of the image are kept
+// Returns ErrNoSheet if the sheet is nil
+func EmbedSheet(src any, sheet *character.Sheet, dst io.Writer) error {
+	if sheet == nil {
+		return ErrNoSheet
+	}
+	processor, err := sourceProcessor(src)
+	if err != nil {
+		return err
+	}
+
+	// Read the whole image (every existing chara chunk is dropped from the image data)
+	rawCard, err := processor.ScanMode(DefaultScanMode.deep()).Get()
+	if err != nil {
+		return err
+	}
+
+	// Write the image with the sheet
+	card := &CharacterCard{pngData: rawCard.pngData, Sheet: sheet}
+	return card.ToImage(dst)
+}
+
+// sourceProcessor returns the Processor of the source (path, bytes or io.Reader), with the DefaultScanMode
+func sourceProcessor(src any) (Processor, error) {
+	switch source := src.(type) {
+	case string:
+		return FromFile(source), nil
+	case []byte:
+		return FromBytes(source), nil
+	case io.Reader:
+		return fromImage(io.NopCloser(source), readerSize(source), SourceOptions{}), nil
+	}
+	return nil, fmt.Errorf("%w: %T", ErrUnsupportedSource, src)
+}
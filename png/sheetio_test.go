@@ -0,0 +1,89 @@
+package png
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/r3dpixel/card-parser/character"
+	"github.com/r3dpixel/card-parser/property"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// imageSources returns the accepted sources (path, bytes and reader) of the image data, keyed by name
+func imageSources(t *testing.T, data []byte) map[string]any {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "card.png")
+	require.NoError(t, os.WriteFile(path, data, 0o600))
+	return map[string]any{"Path": path, "Bytes": data, "Reader": bytes.NewReader(data)}
+}
+
+func TestExtractSheet(t *testing.T) {
+	cardPNG := injectSingleChunk(t, createTestPNG(t, 4, 4), createSheet(character.RevisionV2, "Extracted"), false)
+	for name, src := range imageSources(t, cardPNG) {
+		t.Run(name, func(t *testing.T) {
+			sheet, err := ExtractSheet(src)
+			require.NoError(t, err)
+			assert.Equal(t, property.String("Extracted"), sheet.Name)
+			assert.Equal(t, character.RevisionV2, sheet.Revision)
+		})
+	}
+
+	tests := []struct {
+		name string
+		src  any
+		err  error
+	}{
+		{name: "No character data", src: createTestPNG(t, 4, 4), err: ErrNoCharacterData},
+		{name: "Missing file", src: filepath.Join(t.TempDir(), "missing.png"), err: ErrFileOpen},
+		{name: "Unsupported source", src: 42, err: ErrUnsupportedSource},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sheet, err := ExtractSheet(tt.src)
+			assert.ErrorIs(t, err, tt.err)
+			assert.Nil(t, sheet)
+		})
+	}
+}
+
+func TestEmbedSheet(t *testing.T) {
+	basePNG := createTestPNG(t, 4, 4)
+	withCharacters := injectDoubleChunk(t, basePNG, createSheet(character.RevisionV2, "Old V2"), createSheet(character.RevisionV3, "Old V3"))
+
+	tests := []struct {
+		name     string
+		data     []byte
+		revision character.Revision
+	}{
+		{name: "Replace existing character data", data: withCharacters, revision: character.RevisionV2},
+		{name: "Replace existing character data (V3 keyword)", data: withCharacters, revision: character.RevisionV3},
+		{name: "Image without character data", data: basePNG, revision: character.RevisionV3},
+	}
+	for _, tt := range tests {
+		for name, src := range imageSources(t, tt.data) {
+			t.Run(tt.name+" from "+name, func(t *testing.T) {
+				var output bytes.Buffer
+				require.NoError(t, EmbedSheet(src, createSheet(tt.revision, "Embedded"), &output))
+
+				// A single chara chunk of the sheet revision is written (the old chunks are replaced)
+				cards, err := FromBytes(output.Bytes()).GetAll()
+				require.NoError(t, err)
+				require.Len(t, cards, 1)
+				assert.Equal(t, tt.revision, cards[0].Revision)
+				sheet, err := ExtractSheet(output.Bytes())
+				require.NoError(t, err)
+				assert.Equal(t, property.String("Embedded"), sheet.Name)
+			})
+		}
+	}
+
+	t.Run("Errors", func(t *testing.T) {
+		var output bytes.Buffer
+		assert.ErrorIs(t, EmbedSheet(basePNG, nil, &output), ErrNoSheet)
+		assert.ErrorIs(t, EmbedSheet(42, createSheet(character.RevisionV2, "Embedded"), &output), ErrUnsupportedSource)
+		assert.Zero(t, output.Len())
+	})
+}
@@ -0,0 +1,89 @@
+package png
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+
+	"github.com/r3dpixel/card-parser/character"
+)
+
+// ErrSpanMismatch is returned when a chunk span does not point at a text chunk of the same length in the input
+var ErrSpanMismatch = errors.New("chunk span does not match the input")
+
+// ChunkSpan position of a chara chunk in the input (see Processor.TrackOffsets)
+type ChunkSpan struct {
+	Offset   int64              // Offset of the chunk from the start of the PNG (at its length field)
+	Length   int64              // Length of the whole chunk in bytes (length, type, data and CRC)
+	Revision character.Revision // Revision of the chara chunk keyword
+}
+
+// ReplaceChunkAt streams the PNG from the reader to the writer, replacing the chunk of the span with a chara chunk
+// holding the new payload (base64 chara data, with the keyword of the revision, and the format of the replaced chunk)
+// The prefix and suffix are copied untouched; the span must point at a text chunk, otherwise ErrSpanMismatch is returned
+func ReplaceChunkAt(rs io.ReadSeeker, w io.Writer, span ChunkSpan, newPayload []byte, revision character.Revision) error {
+	// Check the PNG header
+	header := make([]byte, headerSize)
+	if _, err := rs.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	if _, err := io.ReadFull(rs, header); err != nil || !slices.Equal(header, pngHeader) {
+		return ErrNotPNG
+	}
+
+	// Check the chunk of the span
+	format, err := spannedChunkFormat(rs, span)
+	if err != nil {
+		return err
+	}
+
+	// Copy the prefix
+	if _, err := rs.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	if _, err := io.CopyN(w, rs, span.Offset); err != nil {
+		return err
+	}
+
+	// Write the new chara chunk
+	if err := streamCharaChunk(w, keywordRevision(revision), newPayload, format); err != nil {
+		return err
+	}
+
+	// Copy the suffix
+	if _, err := rs.Seek(span.Offset+span.Length, io.SeekStart); err != nil {
+		return err
+	}
+	_, err = io.Copy(w, rs)
+	return err
+}
+
+// spannedChunkFormat returns the format of the text chunk at the span, checking that the chunk length matches the span
+func spannedChunkFormat(rs io.ReadSeeker, span ChunkSpan) (ChunkFormat, error) {
+	// The span cannot overlap the PNG header and IHDR
+	if span.Offset < int64(fullIhdrSize) || span.Length < int64(chunkHeaderSize) {
+		return TEXT, fmt.Errorf("%w: invalid span at offset %d (%d bytes)", ErrSpanMismatch, span.Offset, span.Length)
+	}
+
+	// Read the chunk header
+	chunkHeader := make([]byte, chunkLengthSize+chunkTypeSize)
+	if _, err := rs.Seek(span.Offset, io.SeekStart); err != nil {
+		return TEXT, err
+	}
+	if _, err := io.ReadFull(rs, chunkHeader); err != nil {
+		return TEXT, fmt.Errorf("%w: no chunk at offset %d: %w", ErrSpanMismatch, span.Offset, err)
+	}
+
+	// Check the chunk type and length
+	length := int64(binary.BigEndian.Uint32(chunkHeader[:chunkLengthSize]))
+	format, isText := chunkFormats[binary.BigEndian.Uint32(chunkHeader[chunkLengthSize:])]
+	if !isText {
+		return TEXT, fmt.Errorf("%w: %q chunk at offset %d is not a text chunk", ErrSpanMismatch, chunkHeader[chunkLengthSize:], span.Offset)
+	}
+	if int64(chunkHeaderSize)+length != span.Length {
+		return TEXT, fmt.Errorf("%w: chunk at offset %d is %d bytes (span %d)", ErrSpanMismatch, span.Offset, int64(chunkHeaderSize)+length, span.Length)
+	}
+	return format, nil
+}
@@ -0,0 +1,140 @@
+package png
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/r3dpixel/card-parser/character"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProcessor_TrackOffsets(t *testing.T) {
+	basePNG := createTestPNG(t, 4, 4)
+	smallData := encodeCardData(t, testCards.smallV2)
+	largeData := encodeCardData(t, testCards.largeV3)
+	smallLength := int64(chunkHeaderSize + len(charaKeyword) + len(smallData))
+	largeLength := int64(chunkHeaderSize + len(ccv3Keyword) + len(largeData))
+	iendOffset := int64(len(basePNG) - footerSize)
+
+	afterIHDR := injectSingleChunk(t, basePNG, testCards.smallV2, false)
+	beforeIEND := injectSingleChunk(t, basePNG, testCards.smallV2, true)
+	double := injectChunk(t, injectChunk(t, basePNG, character.RevisionV2, smallData, false), character.RevisionV3, largeData, true)
+
+	tests := []struct {
+		name     string
+		data     []byte
+		scanMode ScanMode
+		expected []ChunkSpan
+	}{
+		{
+			name:     "After IHDR",
+			data:     afterIHDR,
+			scanMode: First,
+			expected: []ChunkSpan{{Offset: int64(fullIhdrSize), Length: smallLength, Revision: character.RevisionV2}},
+		},
+		{
+			name:     "Before IEND",
+			data:     beforeIEND,
+			scanMode: First,
+			expected: []ChunkSpan{{Offset: iendOffset, Length: smallLength, Revision: character.RevisionV2}},
+		},
+		{
+			name:     "Both injection points",
+			data:     double,
+			scanMode: LastLongest,
+			expected: []ChunkSpan{
+				{Offset: int64(fullIhdrSize), Length: smallLength, Revision: character.RevisionV2},
+				{Offset: iendOffset + smallLength, Length: largeLength, Revision: character.RevisionV3},
+			},
+		},
+		{
+			name:     "Both injection points (scan stopped)",
+			data:     double,
+			scanMode: First,
+			expected: []ChunkSpan{
+				{Offset: int64(fullIhdrSize), Length: smallLength, Revision: character.RevisionV2},
+				{Offset: iendOffset + smallLength, Length: largeLength, Revision: character.RevisionV3},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rawCard, err := FromBytes(tt.data).ScanMode(tt.scanMode).TrackOffsets().Get()
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, rawCard.ChunkSpans)
+
+			// The spans point at the chunks of the input
+			for _, span := range rawCard.ChunkSpans {
+				chunk := tt.data[span.Offset : span.Offset+span.Length]
+				assert.Equal(t, []byte("tEXt"), chunk[chunkLengthSize:chunkLengthSize+chunkTypeSize])
+				assert.True(t, bytes.HasPrefix(chunk[chunkLengthSize+chunkTypeSize:], chunkKeywords()[span.Revision]))
+			}
+		})
+	}
+
+	t.Run("Disabled by default", func(t *testing.T) {
+		rawCard, err := FromBytes(afterIHDR).Get()
+		require.NoError(t, err)
+		assert.Nil(t, rawCard.ChunkSpans)
+	})
+
+	t.Run("GetAll", func(t *testing.T) {
+		rawCards, err := FromBytes(double).TrackOffsets().GetAll()
+		require.NoError(t, err)
+		require.Len(t, rawCards, 2)
+		assert.Equal(t, []ChunkSpan{{Offset: int64(fullIhdrSize), Length: smallLength, Revision: character.RevisionV2}}, rawCards[0].ChunkSpans)
+		assert.Equal(t, []ChunkSpan{{Offset: iendOffset + smallLength, Length: largeLength, Revision: character.RevisionV3}}, rawCards[1].ChunkSpans)
+	})
+}
+
+func TestReplaceChunkAt(t *testing.T) {
+	basePNG := createTestPNG(t, 4, 4)
+	newSheet := createSheet(character.RevisionV3, "Replaced card with a longer name than the original one")
+	newData := encodeCardData(t, newSheet)
+
+	for _, atEnd := range []bool{false, true} {
+		t.Run(map[bool]string{false: "After IHDR", true: "Before IEND"}[atEnd], func(t *testing.T) {
+			input := injectSingleChunk(t, basePNG, testCards.smallV2, atEnd)
+			rawCard, err := FromBytes(input).TrackOffsets().Get()
+			require.NoError(t, err)
+			require.Len(t, rawCard.ChunkSpans, 1)
+			span := rawCard.ChunkSpans[0]
+
+			// Splice the new chunk
+			var out bytes.Buffer
+			require.NoError(t, ReplaceChunkAt(bytes.NewReader(input), &out, span, newData, character.RevisionV3))
+
+			// The prefix and suffix are untouched, and the new chunk is valid
+			assert.True(t, bytes.HasPrefix(out.Bytes(), input[:span.Offset]))
+			assert.True(t, bytes.HasSuffix(out.Bytes(), input[span.Offset+span.Length:]))
+			assert.Equal(t, injectSingleChunk(t, basePNG, newSheet, atEnd), out.Bytes())
+
+			replaced, err := FromBytes(out.Bytes()).VerifyCRC().TrackOffsets().Get()
+			require.NoError(t, err)
+			assert.Equal(t, character.RevisionV3, replaced.Revision)
+			assert.Equal(t, newData, replaced.RawCharaData)
+			assert.Equal(t, []ChunkSpan{{Offset: span.Offset, Length: int64(chunkHeaderSize + len(ccv3Keyword) + len(newData)), Revision: character.RevisionV3}}, replaced.ChunkSpans)
+		})
+	}
+
+	t.Run("Span mismatch", func(t *testing.T) {
+		input := injectSingleChunk(t, basePNG, testCards.smallV2, false)
+		spans := []ChunkSpan{
+			{Offset: 0, Length: 20},
+			{Offset: int64(fullIhdrSize), Length: 20},
+			{Offset: int64(len(input) - footerSize), Length: int64(footerSize)},
+			{Offset: int64(len(input)), Length: 20},
+		}
+		for _, span := range spans {
+			err := ReplaceChunkAt(bytes.NewReader(input), &bytes.Buffer{}, span, newData, character.RevisionV3)
+			assert.ErrorIs(t, err, ErrSpanMismatch)
+		}
+	})
+
+	t.Run("Not a PNG", func(t *testing.T) {
+		err := ReplaceChunkAt(bytes.NewReader([]byte("not a png")), &bytes.Buffer{}, ChunkSpan{}, newData, character.RevisionV3)
+		assert.ErrorIs(t, err, ErrNotPNG)
+	})
+}
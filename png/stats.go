@@ -0,0 +1,41 @@
+package png
+
+import (
+	"io"
+)
+
+// ScanStats statistics of the processing of an input (see Processor.Stats)
+type ScanStats struct {
+	ChunksTotal      int    // Chunks read, IHDR and IEND included (0 for converted inputs)
+	TextChunks       int    // Text chunks read (tEXt, zTXt and iTXt)
+	CharaChunksFound int    // Chara chunks found (chara data found in the metadata of converted inputs, e.g. JPEG XMP)
+	BytesRead        int64  // Bytes read from the input (junk before the PNG signature included)
+	LargestChunk     uint32 // Data length of the largest chunk read
+	DurationNanos    int64  // Duration of the scan (or of the conversion) in nanoseconds
+	ScannedToEnd     bool   // Every chunk was inspected for chara data (false if the scan stopped at the first chara chunk)
+}
+
+// countingReadCloser counts the bytes read from the underlying reader
+type countingReadCloser struct {
+	io.ReadCloser
+	n *int64
+}
+
+// Read reads from the underlying reader, counting the bytes read
+func (r countingReadCloser) Read(p []byte) (int, error) {
+	n, err := r.ReadCloser.Read(p)
+	*r.n += int64(n)
+	return n, err
+}
+
+// countChunk accounts for a chunk of the given type and data length, and reports it to the chunk callback (if any)
+func (p *scanningProcessor) countChunk(typeCode uint32, length uint32) {
+	p.stats.ChunksTotal++
+	if _, isText := chunkFormats[typeCode]; isText {
+		p.stats.TextChunks++
+	}
+	p.stats.LargestChunk = max(p.stats.LargestChunk, length)
+	if p.onChunk != nil {
+		p.onChunk(typeCode, length)
+	}
+}
@@ -0,0 +1,98 @@
+package png
+
+import (
+	"encoding/binary"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProcessor_Stats(t *testing.T) {
+	data := injectDoubleChunk(t, createTestPNG(t, 4, 4), testCards.smallV2, testCards.largeV3)
+	largeChunkLength := uint32(len(ccv3Keyword) + len(encodeCardData(t, testCards.largeV3)))
+
+	tests := []struct {
+		name         string
+		processor    Processor
+		getAll       bool
+		scannedToEnd bool
+	}{
+		{"First", FromBytes(data).First(), false, false},
+		{"Deep scan", FromBytes(data).LastLongest(), false, true},
+		{"GetAll", FromBytes(data).First(), true, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			processor := tt.processor
+			assert.Zero(t, processor.Stats())
+
+			// Every chunk is read (the chunks after the first chara chunk are copied, and still counted)
+			var err error
+			if tt.getAll {
+				_, err = processor.GetAll()
+			} else {
+				_, err = processor.Get()
+			}
+			require.NoError(t, err)
+			stats := processor.Stats()
+			assert.Equal(t, 5, stats.ChunksTotal) // IHDR, tEXt, IDAT, tEXt, IEND
+			assert.Equal(t, 2, stats.TextChunks)
+			assert.Equal(t, 2, stats.CharaChunksFound)
+			assert.Equal(t, int64(len(data)), stats.BytesRead)
+			assert.Equal(t, largeChunkLength, stats.LargestChunk)
+			assert.Positive(t, stats.DurationNanos)
+			assert.Equal(t, tt.scannedToEnd, stats.ScannedToEnd)
+		})
+	}
+
+	t.Run("Pipe", func(t *testing.T) {
+		processor := FromBytes(data)
+		require.NoError(t, processor.Pipe(io.Discard, nil))
+		assert.Equal(t, 5, processor.Stats().ChunksTotal)
+		assert.Equal(t, int64(len(data)), processor.Stats().BytesRead)
+	})
+
+	t.Run("Junk and truncated input", func(t *testing.T) {
+		junk := append([]byte{0xEF, 0xBB, 0xBF}, data...)
+		processor := FromBytes(junk[:len(junk)-footerSize])
+		_, err := processor.Get()
+		require.NoError(t, err)
+		assert.Equal(t, 4, processor.Stats().ChunksTotal)
+		assert.Equal(t, int64(len(junk)-footerSize), processor.Stats().BytesRead)
+	})
+
+	t.Run("Converted input", func(t *testing.T) {
+		jpegData := createTestJPG(t)
+		processor := FromBytes(jpegData)
+		assert.Zero(t, processor.Stats())
+		_, err := processor.Get()
+		require.NoError(t, err)
+		stats := processor.Stats()
+		assert.Equal(t, ScanStats{BytesRead: int64(len(jpegData)), DurationNanos: stats.DurationNanos}, stats)
+		assert.Positive(t, stats.DurationNanos)
+	})
+}
+
+func TestProcessor_OnChunk(t *testing.T) {
+	data := injectDoubleChunk(t, createTestPNG(t, 4, 4), testCards.smallV2, testCards.largeV3)
+
+	var types []string
+	var lengths []uint32
+	_, err := FromBytes(data).OnChunk(func(typeCode uint32, length uint32) {
+		types = append(types, string(binary.BigEndian.AppendUint32(nil, typeCode)))
+		lengths = append(lengths, length)
+	}).Get()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"IHDR", "tEXt", "IDAT", "tEXt", "IEND"}, types)
+	assert.Equal(t, uint32(13), lengths[0])
+	assert.Equal(t, uint32(0), lengths[4])
+
+	// Converted inputs have no chunks
+	called := false
+	_, err = FromBytes(createTestJPG(t)).OnChunk(func(uint32, uint32) { called = true }).Get()
+	require.NoError(t, err)
+	assert.False(t, called)
+}
@@ -0,0 +1,81 @@
+package png
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"slices"
+)
+
+// ErrNotPNG is returned when the input does not start with the PNG header (wrapping the decoder error if the input
+// cannot be converted either)
+var ErrNotPNG = errors.New("input is not a PNG image")
+
+// Strip streams the PNG from the reader to the writer chunk by chunk, dropping every chara chunk (`chara` or `ccv3`,
+// stored as `tEXt`, `zTXt` or `iTXt`)
+// All other chunks (including their CRCs) are copied untouched, using constant memory regardless of the image size
+func Strip(r io.Reader, w io.Writer) error {
+	// Check and copy the PNG header
+	header := make([]byte, headerSize)
+	if _, err := io.ReadFull(r, header); err != nil || !slices.Equal(header, pngHeader) {
+		return ErrNotPNG
+	}
+	if _, err := w.Write(header); err != nil {
+		return err
+	}
+
+	// Chunk header (length + type) and the keyword prefix of text chunks
+	chunkHeader := make([]byte, chunkLengthSize+chunkTypeSize)
+	prefix := make([]byte, maxCharaKeywordSize())
+
+	for {
+		// Read the chunk header (the end of the input is the end of the image)
+		if _, err := io.ReadFull(r, chunkHeader); err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return err
+		}
+		length := int64(binary.BigEndian.Uint32(chunkHeader[:chunkLengthSize]))
+		typeCode := binary.BigEndian.Uint32(chunkHeader[chunkLengthSize:])
+
+		// Read the keyword prefix of text chunks
+		prefixLength := 0
+		if _, isText := chunkFormats[typeCode]; isText {
+			prefixLength = int(min(length, int64(len(prefix))))
+			if _, err := io.ReadFull(r, prefix[:prefixLength]); err != nil {
+				return err
+			}
+		}
+
+		// Drop the chara chunks (remaining data + CRC)
+		if prefixLength > 0 && isCharaKeyword(prefix[:prefixLength]) {
+			if _, err := io.CopyN(io.Discard, r, length-int64(prefixLength)+int64(chunkCrcSize)); err != nil {
+				return err
+			}
+			continue
+		}
+
+		// Copy any other chunk untouched (header, prefix, remaining data + CRC)
+		if _, err := w.Write(chunkHeader); err != nil {
+			return err
+		}
+		if _, err := w.Write(prefix[:prefixLength]); err != nil {
+			return err
+		}
+		if _, err := io.CopyN(w, r, length-int64(prefixLength)+int64(chunkCrcSize)); err != nil {
+			return err
+		}
+	}
+}
+
+// isCharaKeyword checks if the text chunk data starts with a chara keyword
+func isCharaKeyword(data []byte) bool {
+	for _, keyword := range chunkKeywords() {
+		if bytes.HasPrefix(data, keyword) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,102 @@
+package png
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/png"
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// createNoisyPNG creates a PNG of the given size that does not compress well
+func createNoisyPNG(t testing.TB, width, height int) []byte {
+	t.Helper()
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	seed := uint32(1)
+	for y := range height {
+		for x := range width {
+			seed = seed*1664525 + 1013904223
+			img.Set(x, y, color.RGBA{R: uint8(seed >> 24), G: uint8(seed >> 16), B: uint8(seed >> 8), A: 255})
+		}
+	}
+	buf := new(bytes.Buffer)
+	require.NoError(t, png.Encode(buf, img))
+	return buf.Bytes()
+}
+
+// textChunk creates a tEXt chunk with the given data
+func textChunk(data []byte) []byte {
+	chunk := binary.BigEndian.AppendUint32(nil, uint32(len(data)))
+	chunk = binary.BigEndian.AppendUint32(chunk, chunkTextTypeCode)
+	chunk = append(chunk, data...)
+	return binary.BigEndian.AppendUint32(chunk, crc32.ChecksumIEEE(chunk[chunkLengthSize:]))
+}
+
+func TestStrip(t *testing.T) {
+	basePNG := createNoisyPNG(t, 1024, 1024)
+	require.Greater(t, len(basePNG), 2<<20)
+
+	withV2 := injectSingleChunk(t, basePNG, testCards.tinyV2, false)
+	withV2V2 := injectSingleChunk(t, withV2, testCards.smallV2, true)
+	withAll := injectSingleChunk(t, withV2V2, testCards.largeV3, true)
+
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{"No chara chunks", basePNG},
+		{"Single chara chunk", withV2},
+		{"Multiple chara chunks", withAll},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			require.NoError(t, Strip(bytes.NewReader(tt.data), &out))
+
+			// The output is the original image (chara chunks are removed, everything else is untouched)
+			assert.Equal(t, basePNG, out.Bytes())
+
+			// The output parses with no character data
+			rawCards, err := FromBytes(out.Bytes()).GetAll()
+			require.NoError(t, err)
+			assert.Empty(t, rawCards)
+
+			// The pixels are unchanged
+			original, err := png.Decode(bytes.NewReader(basePNG))
+			require.NoError(t, err)
+			stripped, err := png.Decode(bytes.NewReader(out.Bytes()))
+			require.NoError(t, err)
+			assert.Equal(t, original, stripped)
+		})
+	}
+
+	t.Run("Other tEXt chunks are kept", func(t *testing.T) {
+		injectionPoint := headerSize + ihdrSize
+		comment, short := textChunk([]byte("Comment\x00kept")), textChunk([]byte("ch"))
+		expected := slices.Concat(basePNG[:injectionPoint], comment, short, basePNG[injectionPoint:])
+		withText := slices.Concat(withAll[:injectionPoint], comment, short, withAll[injectionPoint:])
+
+		var out bytes.Buffer
+		require.NoError(t, Strip(bytes.NewReader(withText), &out))
+		assert.Equal(t, expected, out.Bytes())
+	})
+
+	t.Run("Not a PNG", func(t *testing.T) {
+		err := Strip(bytes.NewReader(createTestJPG(t)), new(bytes.Buffer))
+		assert.ErrorIs(t, err, ErrNotPNG)
+	})
+
+	t.Run("Truncated PNG", func(t *testing.T) {
+		err := Strip(bytes.NewReader(withAll[:len(withAll)/2]), new(bytes.Buffer))
+		assert.Error(t, err)
+		assert.False(t, errors.Is(err, ErrNotPNG))
+	})
+}
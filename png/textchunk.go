@@ -0,0 +1,123 @@
+package png
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+)
+
+// ErrInvalidKeyword is returned when setting a text chunk with an invalid (or reserved chara) keyword
+var ErrInvalidKeyword = errors.New("invalid text chunk keyword")
+
+// TextChunk non-chara `tEXt` chunk of a PNG (e.g. Software, Title, Stable Diffusion parameters)
+// Keyword and text hold the raw chunk bytes (Latin-1 as per the PNG specification)
+type TextChunk struct {
+	Keyword string `json:"keyword"`
+	Text    string `json:"text"`
+}
+
+// newTextChunk splits the `tEXt` chunk data into keyword and text (a missing separator means an empty text)
+func newTextChunk(chunkData []byte) TextChunk {
+	keyword, text, _ := bytes.Cut(chunkData, []byte{0x00})
+	return TextChunk{Keyword: string(keyword), Text: string(text)}
+}
+
+// TextChunk returns the text of the first text chunk with the given keyword
+func (p *pngData) TextChunk(keyword string) (string, bool) {
+	index := slices.IndexFunc(p.TextChunks, func(chunk TextChunk) bool { return chunk.Keyword == keyword })
+	if index < 0 {
+		return "", false
+	}
+	return p.TextChunks[index].Text, true
+}
+
+// SetTextChunk sets the text of the keyword, replacing every text chunk with the same keyword (written by ToImage)
+// The keyword must be 1-79 bytes long without null bytes, and must not be a chara keyword (`chara`, `ccv3` or a registered one)
+func (p *pngData) SetTextChunk(keyword string, text string) error {
+	// Validate the keyword
+	if len(keyword) == 0 || len(keyword) > maxKeywordSize || strings.IndexByte(keyword, 0x00) >= 0 {
+		return fmt.Errorf("%w: %q", ErrInvalidKeyword, keyword)
+	}
+	if isCharaKeyword(append([]byte(keyword), 0x00)) {
+		return fmt.Errorf("%w: %q is reserved for chara data", ErrInvalidKeyword, keyword)
+	}
+
+	// Replace the first chunk with the keyword, and remove the others (the chunks are copied, as they may be shared)
+	index := slices.IndexFunc(p.TextChunks, func(chunk TextChunk) bool { return chunk.Keyword == keyword })
+	if index < 0 {
+		p.TextChunks = append(slices.Clip(p.TextChunks), TextChunk{Keyword: keyword, Text: text})
+		return nil
+	}
+	chunks := slices.Clone(p.TextChunks[:index+1])
+	chunks[index].Text = text
+	for _, chunk := range p.TextChunks[index+1:] {
+		if chunk.Keyword != keyword {
+			chunks = append(chunks, chunk)
+		}
+	}
+	p.TextChunks = chunks
+	return nil
+}
+
+// RemoveTextChunk removes every text chunk with the given keyword
+func (p *pngData) RemoveTextChunk(keyword string) {
+	p.TextChunks = slices.DeleteFunc(slices.Clone(p.TextChunks), func(chunk TextChunk) bool { return chunk.Keyword == keyword })
+}
+
+// textChunksChanged checks if the text chunks differ from the ones scanned (which are kept in place in the body)
+func (p *pngData) textChunksChanged() bool {
+	return !slices.Equal(p.TextChunks, p.scannedTextChunks)
+}
+
+// bodyParts returns the body before and after the injection offset
+// When the text chunks were changed, the scanned non-chara `tEXt` chunks are left out (see writeTextChunks)
+func (p *pngData) bodyParts(injectionOffset int) ([]byte, []byte) {
+	if !p.textChunksChanged() {
+		return p.Body[:injectionOffset], p.Body[injectionOffset:]
+	}
+	return stripTextChunks(p.Body[:injectionOffset]), stripTextChunks(p.Body[injectionOffset:])
+}
+
+// writeTextChunks writes the text chunks if they were changed (replacing the scanned ones, see bodyParts)
+func (p *pngData) writeTextChunks(w io.Writer) error {
+	if !p.textChunksChanged() {
+		return nil
+	}
+	return streamTextChunks(w, p.TextChunks)
+}
+
+// addedTextChunks returns the text chunks that were not scanned
+func (p *pngData) addedTextChunks() []TextChunk {
+	return slices.DeleteFunc(slices.Clone(p.TextChunks), func(chunk TextChunk) bool {
+		return slices.Contains(p.scannedTextChunks, chunk)
+	})
+}
+
+// streamTextChunks writes the text chunks as `tEXt` chunks
+func streamTextChunks(w io.Writer, chunks []TextChunk) error {
+	for _, chunk := range chunks {
+		if err := streamTextChunk(w, append([]byte(chunk.Keyword), 0x00), []byte(chunk.Text), TEXT); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// stripTextChunks returns a copy of the body chunks without the non-chara `tEXt` chunks
+func stripTextChunks(body []byte) []byte {
+	stripped := make([]byte, 0, len(body))
+	for len(body) >= chunkHeaderSize {
+		size := min(chunkHeaderSize+int(binary.BigEndian.Uint32(body)), len(body))
+		chunk := body[:size]
+		typeCode := binary.BigEndian.Uint32(chunk[chunkLengthSize:])
+		if typeCode != chunkTextTypeCode || isCharaKeyword(chunk[chunkLengthSize+chunkTypeSize:]) {
+			stripped = append(stripped, chunk...)
+		}
+		body = body[size:]
+	}
+	return append(stripped, body...)
+}
@@ -0,0 +1,146 @@
+package png
+
+import (
+	"bytes"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRawCard_TextChunks(t *testing.T) {
+	basePNG := injectSingleChunk(t, createTestPNG(t, 4, 4), testCards.smallV2, false)
+	parameters := "masterpiece, 1girl\nSteps: 20, Sampler: Euler a"
+	data := slices.Concat(
+		basePNG[:fullIhdrSize],
+		textChunk([]byte("Software\x00Stable Diffusion")),
+		textChunk([]byte("parameters\x00"+parameters)),
+		basePNG[fullIhdrSize:],
+	)
+
+	t.Run("Read", func(t *testing.T) {
+		rawCard, err := FromBytes(data).Get()
+		require.NoError(t, err)
+
+		assert.Equal(t, []TextChunk{
+			{Keyword: "Software", Text: "Stable Diffusion"},
+			{Keyword: "parameters", Text: parameters},
+		}, rawCard.TextChunks)
+		text, ok := rawCard.TextChunk("parameters")
+		assert.True(t, ok)
+		assert.Equal(t, parameters, text)
+		_, ok = rawCard.TextChunk("Title")
+		assert.False(t, ok)
+
+		// The chara data is still parsed
+		assert.Equal(t, encodeCardData(t, testCards.smallV2), []byte(rawCard.RawCharaData))
+	})
+
+	t.Run("Round trip", func(t *testing.T) {
+		rawCard, err := FromBytes(data).Get()
+		require.NoError(t, err)
+		pngBytes, err := rawCard.ToBytes()
+		require.NoError(t, err)
+
+		reparsed, err := FromBytes(pngBytes).Get()
+		require.NoError(t, err)
+		assert.Equal(t, rawCard.TextChunks, reparsed.TextChunks)
+		assert.Equal(t, rawCard.RawCharaData, reparsed.RawCharaData)
+
+		// The chunks are written once
+		assert.Equal(t, len(pngBytes), len(data))
+	})
+
+	t.Run("Chunks keep their position", func(t *testing.T) {
+		comment := textChunk([]byte("Comment\x00after the image data"))
+		// Insert the chunk right before IEND
+		trailingData := slices.Concat(data[:len(data)-chunkHeaderSize], comment, data[len(data)-chunkHeaderSize:])
+
+		rawCard, err := FromBytes(trailingData).Get()
+		require.NoError(t, err)
+		pngBytes, err := rawCard.ToBytes()
+		require.NoError(t, err)
+
+		// The untouched chunks are written where they were (after IDAT)
+		assert.Greater(t, bytes.Index(pngBytes, comment), bytes.LastIndex(pngBytes, []byte("IDAT")))
+		assert.Equal(t, len(trailingData), len(pngBytes))
+		var streamed bytes.Buffer
+		require.NoError(t, FromBytes(trailingData).Pipe(&streamed, nil))
+		assert.Greater(t, bytes.Index(streamed.Bytes(), comment), bytes.LastIndex(streamed.Bytes(), []byte("IDAT")))
+	})
+
+	t.Run("Round trip through the character card", func(t *testing.T) {
+		rawCard, err := FromBytes(data).Get()
+		require.NoError(t, err)
+		characterCard, err := rawCard.Decode()
+		require.NoError(t, err)
+		pngBytes, err := characterCard.ToBytes()
+		require.NoError(t, err)
+
+		reparsed, err := FromBytes(pngBytes).Get()
+		require.NoError(t, err)
+		assert.Equal(t, rawCard.TextChunks, reparsed.TextChunks)
+	})
+
+	t.Run("Set and remove", func(t *testing.T) {
+		rawCard, err := FromBytes(data).Get()
+		require.NoError(t, err)
+		rawCard.TextChunks = append(rawCard.TextChunks, TextChunk{Keyword: "Software", Text: "duplicate"})
+		original := slices.Clone(rawCard.TextChunks)
+
+		// Replacing removes the duplicates, keeping the position of the first chunk
+		require.NoError(t, rawCard.SetTextChunk("Software", "card-parser"))
+		require.NoError(t, rawCard.SetTextChunk("Title", "Alice"))
+		assert.Equal(t, []TextChunk{
+			{Keyword: "Software", Text: "card-parser"},
+			{Keyword: "parameters", Text: parameters},
+			{Keyword: "Title", Text: "Alice"},
+		}, rawCard.TextChunks)
+
+		rawCard.RemoveTextChunk("parameters")
+		assert.Equal(t, []TextChunk{
+			{Keyword: "Software", Text: "card-parser"},
+			{Keyword: "Title", Text: "Alice"},
+		}, rawCard.TextChunks)
+
+		// The updated chunks replace the scanned ones
+		pngBytes, err := rawCard.ToBytes()
+		require.NoError(t, err)
+		reparsed, err := FromBytes(pngBytes).Get()
+		require.NoError(t, err)
+		assert.Equal(t, rawCard.TextChunks, reparsed.TextChunks)
+		assert.NotContains(t, string(pngBytes), "Stable Diffusion")
+
+		// The original chunks are not modified
+		assert.Equal(t, []TextChunk{
+			{Keyword: "Software", Text: "Stable Diffusion"},
+			{Keyword: "parameters", Text: parameters},
+			{Keyword: "Software", Text: "duplicate"},
+		}, original)
+	})
+
+	t.Run("Invalid keywords", func(t *testing.T) {
+		rawCard, err := FromBytes(data).Get()
+		require.NoError(t, err)
+
+		tests := []struct {
+			name    string
+			keyword string
+		}{
+			{"Empty", ""},
+			{"Too long", strings.Repeat("k", maxKeywordSize+1)},
+			{"Null byte", "Soft\x00ware"},
+			{"Chara keyword", "chara"},
+			{"CCv3 keyword", "ccv3"},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				assert.ErrorIs(t, rawCard.SetTextChunk(tt.keyword, "text"), ErrInvalidKeyword)
+			})
+		}
+		assert.Len(t, rawCard.TextChunks, 2)
+	})
+}
@@ -0,0 +1,97 @@
+package png
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+)
+
+// ErrConstraintViolated is returned (joined for every violated rule) when an image does not satisfy the constraints
+var ErrConstraintViolated = errors.New("image constraint violated")
+
+// Constraints image constraints checked by Processor.Validate (zero values disable the checks)
+type Constraints struct {
+	MinWidth        int     // Minimum width in pixels
+	MaxWidth        int     // Maximum width in pixels
+	MinHeight       int     // Minimum height in pixels
+	MaxHeight       int     // Maximum height in pixels
+	MaxAspectRatio  float64 // Maximum ratio of the longer side to the shorter side (1 for square images only)
+	MaxFileBytes    int64   // Maximum size of the input in bytes
+	RequirePNGInput bool    // Rejects the inputs that are not PNG (converted by the processor)
+}
+
+// checkImage returns a violation for every dimension constraint the image does not satisfy
+func (c Constraints) checkImage(width int, height int) []error {
+	var violations []error
+	if c.MinWidth > 0 && width < c.MinWidth {
+		violations = append(violations, fmt.Errorf("%w: width %d is below the minimum %d", ErrConstraintViolated, width, c.MinWidth))
+	}
+	if c.MaxWidth > 0 && width > c.MaxWidth {
+		violations = append(violations, fmt.Errorf("%w: width %d exceeds the maximum %d", ErrConstraintViolated, width, c.MaxWidth))
+	}
+	if c.MinHeight > 0 && height < c.MinHeight {
+		violations = append(violations, fmt.Errorf("%w: height %d is below the minimum %d", ErrConstraintViolated, height, c.MinHeight))
+	}
+	if c.MaxHeight > 0 && height > c.MaxHeight {
+		violations = append(violations, fmt.Errorf("%w: height %d exceeds the maximum %d", ErrConstraintViolated, height, c.MaxHeight))
+	}
+	if c.MaxAspectRatio > 0 && width > 0 && height > 0 {
+		if ratio := float64(max(width, height)) / float64(min(width, height)); ratio > c.MaxAspectRatio {
+			violations = append(violations, fmt.Errorf("%w: aspect ratio %.2f exceeds the maximum %.2f", ErrConstraintViolated, ratio, c.MaxAspectRatio))
+		}
+	}
+	return violations
+}
+
+// checkFileBytes returns a violation if the input size exceeds the maximum size
+func (c Constraints) checkFileBytes(size int64) error {
+	if c.MaxFileBytes > 0 && size > c.MaxFileBytes {
+		return fmt.Errorf("%w: file size %d exceeds the maximum %d bytes", ErrConstraintViolated, size, c.MaxFileBytes)
+	}
+	return nil
+}
+
+// needsImage checks if any constraint needs the image dimensions or the input size
+func (c Constraints) needsImage() bool {
+	return c.MinWidth > 0 || c.MaxWidth > 0 || c.MinHeight > 0 || c.MaxHeight > 0 || c.MaxAspectRatio > 0 || c.MaxFileBytes > 0
+}
+
+// readerSize returns the size of a regular file reader (-1 if the size is unknown)
+func readerSize(r io.Reader) int64 {
+	if file, ok := r.(interface{ Stat() (os.FileInfo, error) }); ok {
+		if info, err := file.Stat(); err == nil && info.Mode().IsRegular() {
+			return info.Size()
+		}
+	}
+	return -1
+}
+
+// maxBytesReader fails the reading with its error (e.g. a constraint violation) once more than the remaining bytes are read
+type maxBytesReader struct {
+	io.ReadCloser
+	remaining int64
+	err       error
+}
+
+// Read reads from the underlying reader, failing past the remaining bytes
+func (r *maxBytesReader) Read(b []byte) (int, error) {
+	// Fail if the limit was already exceeded
+	if r.remaining < 0 {
+		return 0, r.err
+	}
+
+	// Read one byte past the limit, to detect the excess
+	if int64(len(b)) > r.remaining+1 {
+		b = b[:r.remaining+1]
+	}
+	n, err := r.ReadCloser.Read(b)
+	if int64(n) <= r.remaining {
+		r.remaining -= int64(n)
+		return n, err
+	}
+
+	// Fail with the error
+	n, r.remaining = int(r.remaining), -1
+	return n, r.err
+}
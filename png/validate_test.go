@@ -0,0 +1,91 @@
+package png
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProcessor_Validate(t *testing.T) {
+	uploads := Constraints{
+		MinWidth:        256,
+		MaxWidth:        2048,
+		MinHeight:       256,
+		MaxHeight:       2048,
+		MaxAspectRatio:  1.5,
+		MaxFileBytes:    5 << 20,
+		RequirePNGInput: true,
+	}
+	squarePNG := createTestPNG(t, 300, 300)
+	oversizedPNG := createTestPNG(t, 2100, 300)
+
+	tests := []struct {
+		name        string
+		data        []byte
+		constraints Constraints
+		violations  int
+	}{
+		{"Pass-through", squarePNG, uploads, 0},
+		{"No constraints", oversizedPNG, Constraints{}, 0},
+		{"Oversized image", oversizedPNG, uploads, 2},
+		{"Small image", createTestPNG(t, 4, 8), uploads, 3},
+		{"File too large", squarePNG, Constraints{MaxFileBytes: int64(len(squarePNG) - 1)}, 1},
+		{"JPEG with PNG input required", createTestJPG(t), uploads, 3},
+		{"JPEG without PNG input required", createTestJPG(t), Constraints{MaxWidth: 8, MaxHeight: 8}, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := FromBytes(tt.data).Validate(tt.constraints)
+			if tt.violations == 0 {
+				require.NoError(t, err)
+				return
+			}
+			require.ErrorIs(t, err, ErrConstraintViolated)
+			joined, ok := err.(interface{ Unwrap() []error })
+			require.True(t, ok)
+			assert.Len(t, joined.Unwrap(), tt.violations)
+		})
+	}
+
+	t.Run("Converted image is decoded once", func(t *testing.T) {
+		processor := FromBytes(createTestJPG(t))
+		require.NoError(t, processor.Validate(Constraints{MaxWidth: 8}))
+		card, err := processor.Get()
+		require.NoError(t, err)
+		assert.Equal(t, 4, card.Width())
+	})
+
+	t.Run("File size from the file", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "card.png")
+		require.NoError(t, os.WriteFile(path, squarePNG, 0o644))
+		processor := FromFile(path)
+		defer processor.Close()
+		assert.ErrorIs(t, processor.Validate(Constraints{MaxFileBytes: 64}), ErrConstraintViolated)
+	})
+
+	t.Run("Unknown file size is enforced while reading", func(t *testing.T) {
+		// The reader hides the input size
+		reader := io.NopCloser(io.MultiReader(bytes.NewReader(squarePNG)))
+		processor := FromImage(reader)
+		require.NoError(t, processor.Validate(Constraints{MaxFileBytes: int64(len(squarePNG) - 1)}))
+		_, err := processor.Get()
+		assert.ErrorIs(t, err, ErrConstraintViolated)
+
+		// The input under the limit is read as usual
+		processor = FromImage(io.NopCloser(io.MultiReader(bytes.NewReader(squarePNG))))
+		require.NoError(t, processor.Validate(Constraints{MaxFileBytes: int64(len(squarePNG))}))
+		_, err = processor.Get()
+		assert.NoError(t, err)
+	})
+
+	t.Run("Processor error", func(t *testing.T) {
+		err := FromFile(filepath.Join(t.TempDir(), "missing.png")).Validate(Constraints{})
+		assert.ErrorIs(t, err, os.ErrNotExist)
+	})
+}
@@ -0,0 +1,138 @@
+package png
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"image"
+	"slices"
+
+	"github.com/sunshineplan/imgconv"
+)
+
+// WebP container sizes in bytes
+const (
+	webpHeaderSize      int  = 12   // Size of the RIFF header ('RIFF', size, 'WEBP')
+	webpChunkHeaderSize int  = 8    // Size of a RIFF chunk header (FourCC, size)
+	webpFrameHeaderSize int  = 16   // Size of the ANMF frame header (offsets, dimensions, duration, flags)
+	webpVP8XSize        int  = 10   // Size of the VP8X chunk payload
+	webpAlphaFlag       byte = 0x10 // VP8X flag of images with alpha
+)
+
+// FourCC codes of the WebP container
+var (
+	riffFourCC = []byte("RIFF")
+	webpFourCC = []byte("WEBP")
+	anmfFourCC = []byte("ANMF")
+	alphFourCC = []byte("ALPH")
+	vp8xFourCC = []byte("VP8X")
+	vp8FourCC  = []byte("VP8 ")
+	vp8lFourCC = []byte("VP8L")
+
+	// AVIF brands of the ISO BMFF 'ftyp' box
+	avifBrands = [][]byte{[]byte("ftypavif"), []byte("ftypavis")}
+)
+
+// isWebP checks if the data is a WebP image (RIFF container with the WEBP form type)
+func isWebP(data []byte) bool {
+	return len(data) >= webpHeaderSize && bytes.Equal(data[:4], riffFourCC) && bytes.Equal(data[8:12], webpFourCC)
+}
+
+// isAVIF checks if the data is an AVIF image (ISO BMFF 'ftyp' box with an AVIF brand)
+func isAVIF(data []byte) bool {
+	if len(data) < 12 {
+		return false
+	}
+	for _, brand := range avifBrands {
+		if bytes.Equal(data[4:12], brand) {
+			return true
+		}
+	}
+	return false
+}
+
+// decodeAnimatedWebP decodes the first frame of an animated WebP image
+// The first ANMF frame is repackaged as a still WebP image, which is decoded by the registered WebP decoder
+func decodeAnimatedWebP(data []byte) (image.Image, error) {
+	// Find the first frame
+	frame, ok := webpChunk(data[webpHeaderSize:], anmfFourCC)
+	if !ok || len(frame) < webpFrameHeaderSize {
+		return nil, errors.New("webp: no animation frame found")
+	}
+
+	// Extract the frame dimensions and bitstream (with the alpha chunk, if any)
+	width := uint24(frame[6:9]) + 1
+	height := uint24(frame[9:12]) + 1
+	frameChunks := frame[webpFrameHeaderSize:]
+
+	// Repackage the frame as a still image
+	var still []byte
+	if bitstream, ok := webpRawChunk(frameChunks, vp8lFourCC); ok {
+		// Lossless frames carry their own alpha
+		still = bitstream
+	} else if bitstream, ok := webpRawChunk(frameChunks, vp8FourCC); ok {
+		still = bitstream
+		// Lossy frames with alpha need the extended format (VP8X + ALPH + VP8)
+		if alpha, ok := webpRawChunk(frameChunks, alphFourCC); ok {
+			vp8x := make([]byte, webpChunkHeaderSize+webpVP8XSize)
+			copy(vp8x, vp8xFourCC)
+			binary.LittleEndian.PutUint32(vp8x[4:], uint32(webpVP8XSize))
+			vp8x[webpChunkHeaderSize] = webpAlphaFlag
+			putUint24(vp8x[webpChunkHeaderSize+4:], width-1)
+			putUint24(vp8x[webpChunkHeaderSize+7:], height-1)
+			still = bytes.Join([][]byte{vp8x, alpha, bitstream}, nil)
+		}
+	} else {
+		return nil, errors.New("webp: animation frame has no bitstream")
+	}
+
+	// Build the RIFF container
+	container := make([]byte, webpHeaderSize, webpHeaderSize+len(still))
+	copy(container, riffFourCC)
+	binary.LittleEndian.PutUint32(container[4:], uint32(4+len(still)))
+	copy(container[8:], webpFourCC)
+	container = append(container, still...)
+
+	// Decode the still image
+	return imgconv.Decode(bytes.NewReader(container))
+}
+
+// webpChunk returns the payload of the first chunk with the given FourCC
+func webpChunk(data []byte, fourCC []byte) ([]byte, bool) {
+	chunk, ok := webpRawChunk(data, fourCC)
+	if !ok {
+		return nil, false
+	}
+	return chunk[webpChunkHeaderSize:], true
+}
+
+// webpRawChunk returns the first chunk with the given FourCC (header, payload, and padding)
+func webpRawChunk(data []byte, fourCC []byte) ([]byte, bool) {
+	for len(data) >= webpChunkHeaderSize {
+		size := int(binary.LittleEndian.Uint32(data[4:8]))
+		end := webpChunkHeaderSize + size
+		if end > len(data) {
+			return nil, false
+		}
+		// Chunks are padded to an even size (the padding of the last chunk may be missing)
+		padded := min(end+size&1, len(data))
+		if bytes.Equal(data[:4], fourCC) {
+			if padded < end+size&1 {
+				return append(slices.Clone(data[:padded]), 0x00), true
+			}
+			return data[:padded], true
+		}
+		data = data[padded:]
+	}
+	return nil, false
+}
+
+// uint24 decodes a 24-bit little-endian integer
+func uint24(b []byte) int {
+	return int(b[0]) | int(b[1])<<8 | int(b[2])<<16
+}
+
+// putUint24 encodes a 24-bit little-endian integer
+func putUint24(b []byte, v int) {
+	b[0], b[1], b[2] = byte(v), byte(v>>8), byte(v>>16)
+}
@@ -0,0 +1,151 @@
+package png
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/r3dpixel/toolkit/reqx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/sunshineplan/imgconv"
+)
+
+// riffChunk builds a RIFF chunk (FourCC, little-endian size, payload, padding)
+func riffChunk(fourCC string, payload ...[]byte) []byte {
+	data := bytes.Join(payload, nil)
+	chunk := binary.LittleEndian.AppendUint32([]byte(fourCC), uint32(len(data)))
+	chunk = append(chunk, data...)
+	if len(data)%2 == 1 {
+		chunk = append(chunk, 0x00)
+	}
+	return chunk
+}
+
+// animatedWebP wraps the frame chunks of a still WebP image into a two frame animated WebP image
+func animatedWebP(t *testing.T, still []byte) []byte {
+	t.Helper()
+	config, _, err := image.DecodeConfig(bytes.NewReader(still))
+	require.NoError(t, err)
+
+	// Frame chunks of the still image (skipping the VP8X chunk)
+	frameChunks := still[webpHeaderSize:]
+	if vp8x, ok := webpRawChunk(frameChunks, vp8xFourCC); ok {
+		frameChunks = frameChunks[len(vp8x):]
+	}
+
+	// Frame header: offsets, dimensions, duration and flags
+	frameHeader := make([]byte, webpFrameHeaderSize)
+	putUint24(frameHeader[6:], config.Width-1)
+	putUint24(frameHeader[9:], config.Height-1)
+	putUint24(frameHeader[12:], 100)
+
+	// Extended header: animation and alpha flags, canvas dimensions
+	vp8x := make([]byte, webpVP8XSize)
+	vp8x[0] = 0x02 | webpAlphaFlag
+	putUint24(vp8x[4:], config.Width-1)
+	putUint24(vp8x[7:], config.Height-1)
+
+	body := bytes.Join([][]byte{
+		webpFourCC,
+		riffChunk("VP8X", vp8x),
+		riffChunk("ANIM", make([]byte, 6)),
+		riffChunk("ANMF", frameHeader, frameChunks),
+		riffChunk("ANMF", frameHeader, frameChunks),
+	}, nil)
+	return append(binary.LittleEndian.AppendUint32([]byte("RIFF"), uint32(len(body))), body...)
+}
+
+func TestConverterProcessor_WebP(t *testing.T) {
+	lossy, err := os.ReadFile("testdata/card.webp")
+	require.NoError(t, err)
+	lossyWithAlpha, err := os.ReadFile("testdata/card_alpha.webp")
+	require.NoError(t, err)
+
+	tests := []struct {
+		name     string
+		data     []byte
+		source   []byte
+		animated bool
+	}{
+		{"Still lossy", lossy, lossy, false},
+		{"Still lossy with alpha", lossyWithAlpha, lossyWithAlpha, false},
+		{"Animated lossy", animatedWebP(t, lossy), lossy, true},
+		{"Animated lossy with alpha", animatedWebP(t, lossyWithAlpha), lossyWithAlpha, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// The registered WebP decoder does not support animations
+			_, err := imgconv.Decode(bytes.NewReader(tt.data))
+			assert.Equal(t, tt.animated, err != nil)
+
+			// Expected dimensions (of the still image, or of the first frame)
+			config, _, err := image.DecodeConfig(bytes.NewReader(tt.source))
+			require.NoError(t, err)
+
+			processor := FromBytes(tt.data)
+			require.IsType(t, &converterProcessor{}, processor)
+			width, height := processor.ImageSize()
+			assert.Equal(t, config.Width, width)
+			assert.Equal(t, config.Height, height)
+
+			rawCard, err := processor.Get()
+			require.NoError(t, err)
+			assert.Equal(t, pngHeader, rawCard.Header[:headerSize])
+			assert.Empty(t, rawCard.RawCharaData)
+
+			// The converted image is a valid PNG
+			pngBytes, err := rawCard.ToBytes()
+			require.NoError(t, err)
+			reparsed := FromBytes(pngBytes)
+			require.IsType(t, &scanningProcessor{}, reparsed)
+			width, height = reparsed.ImageSize()
+			assert.Equal(t, config.Width, width)
+			assert.Equal(t, config.Height, height)
+		})
+	}
+
+	t.Run("From file", func(t *testing.T) {
+		rawCard, err := FromFile("testdata/card.webp").Get()
+		require.NoError(t, err)
+		assert.Equal(t, pngHeader, rawCard.Header[:headerSize])
+	})
+
+	t.Run("From URL", func(t *testing.T) {
+		var accept string
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			accept = r.Header.Get("Accept")
+			w.Header().Set("Content-Type", "image/webp")
+			w.Write(lossy)
+		}))
+		defer server.Close()
+
+		rawCard, err := FromURL(reqx.NewClient(reqx.Options{RetryCount: 1}), server.URL).Get()
+		require.NoError(t, err)
+		assert.Equal(t, pngHeader, rawCard.Header[:headerSize])
+		assert.Contains(t, accept, "image/webp")
+		assert.Contains(t, accept, "image/avif")
+	})
+
+	t.Run("Malformed animation", func(t *testing.T) {
+		animated := animatedWebP(t, lossy)
+		_, err := FromBytes(animated[:len(animated)/3]).Get()
+		assert.Error(t, err)
+	})
+}
+
+func TestConverterProcessor_AVIF(t *testing.T) {
+	// AVIF header (ftyp box) without any image data
+	avif := append([]byte{0x00, 0x00, 0x00, 0x1c}, []byte("ftypavif\x00\x00\x00\x00avifmif1miaf")...)
+	assert.True(t, isAVIF(avif))
+	assert.False(t, isAVIF(createTestPNG(t, 1, 1)))
+
+	// The AVIF decoder rejects the image
+	_, err := FromBytes(avif).Get()
+	assert.ErrorIs(t, err, ErrNotPNG)
+}
@@ -0,0 +1,111 @@
+package property
+
+import (
+	"strings"
+
+	"github.com/r3dpixel/card-parser/internal/codec"
+	"github.com/r3dpixel/toolkit/stringsx"
+	"github.com/r3dpixel/toolkit/symbols"
+)
+
+// AssetType constants (V3 spec asset types)
+const (
+	IconAsset       AssetType = "icon"
+	BackgroundAsset AssetType = "background"
+	UserIconAsset   AssetType = "user_icon"
+	EmotionAsset    AssetType = "emotion"
+	OtherAsset      AssetType = "other"
+
+	DefaultAssetType = OtherAsset // DefaultAssetType is OtherAsset
+)
+
+// AssetType represents the type of chara card asset
+type AssetType string
+
+// OnFloat sets the AssetType to the default value (numbers are not valid asset types)
+func (a *AssetType) OnFloat(floatValue float64) {
+	*a = DefaultAssetType
+}
+
+// OnString sets the AssetType to the parsed string value
+func (a *AssetType) OnString(stringValue string) {
+	*a = atParser.FromString(stringValue)
+}
+
+// OnBool sets the AssetType to the default value (booleans are not valid asset types)
+func (a *AssetType) OnBool(boolValue bool) {
+	*a = DefaultAssetType
+}
+
+// OnNull sets the AssetType to the default value
+func (a *AssetType) OnNull() {
+	*a = DefaultAssetType
+}
+
+// OnArray is a no-op for AssetType, as it is not a complex type (sets default value)
+func (a *AssetType) OnArray(arrayValue []any) {
+	*a = DefaultAssetType
+}
+
+// OnObject is a no-op for AssetType, as it is not a complex type (sets default value)
+func (a *AssetType) OnObject(objectValue map[string]any) {
+	*a = DefaultAssetType
+}
+
+// MarshalJSON marshals the AssetType to JSON using the JSON codec
+func (a *AssetType) MarshalJSON() ([]byte, error) {
+	return codec.Marshal((*string)(a))
+}
+
+// UnmarshalJSON unmarshals JSON data into the AssetType using the JSON codec
+func (a *AssetType) UnmarshalJSON(data []byte) error {
+	return codec.HandleEntity(data, a)
+}
+
+// AssetTypeParser API to parse string into a valid AssetType
+type AssetTypeParser interface {
+	FromString(value string) AssetType
+}
+
+// assetTypeParser API to parse string into a valid AssetType
+type assetTypeParser struct {
+	values map[string]AssetType
+}
+
+// atParser instance of assetTypeParser holding the correct mappings from string to AssetType
+var atParser = &assetTypeParser{
+	values: map[string]AssetType{
+		"icon":        IconAsset,
+		"avatar":      IconAsset,
+		"background":  BackgroundAsset,
+		"backgrounds": BackgroundAsset,
+		"bg":          BackgroundAsset,
+		"usericon":    UserIconAsset,
+		"useravatar":  UserIconAsset,
+		"emotion":     EmotionAsset,
+		"emotions":    EmotionAsset,
+		"expression":  EmotionAsset,
+		"expressions": EmotionAsset,
+		"sprite":      EmotionAsset,
+		"other":       OtherAsset,
+	},
+}
+
+// AssetTypeProp returns the global AssetTypeParser instance
+func AssetTypeProp() AssetTypeParser {
+	return atParser
+}
+
+// FromString converts a string value to an AssetType after sanitization
+func (at *assetTypeParser) FromString(value string) AssetType {
+	// Input value is a string (remove non-ASCII, remove symbols, remove whitespace, lower all characters)
+	sanitizedValue := strings.ToLower(stringsx.Remove(value, symbols.NonAlphaNumericWhiteSpaceRegExp))
+
+	// Check if the string input corresponds to any AssetType value
+	if assetType, exists := at.values[sanitizedValue]; exists {
+		return assetType
+	}
+
+	// Return the DefaultAssetType value
+	return DefaultAssetType
+}
@@ -0,0 +1,77 @@
+package property
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/r3dpixel/toolkit/sonicx"
+	"github.com/stretchr/testify/assert"
+)
+
+var assetTypeFromStringTests = []propertyTestCase[string, AssetType]{
+	{name: "Icon", input: "icon", expected: IconAsset},
+	{name: "Icon Uppercase", input: "ICON", expected: IconAsset},
+	{name: "Avatar", input: "avatar", expected: IconAsset},
+	{name: "Background", input: "background", expected: BackgroundAsset},
+	{name: "Background Short", input: "BG", expected: BackgroundAsset},
+	{name: "User Icon", input: "user_icon", expected: UserIconAsset},
+	{name: "User Icon Camel Case", input: "userIcon", expected: UserIconAsset},
+	{name: "Emotion", input: "emotion", expected: EmotionAsset},
+	{name: "Emotions", input: " Emotions ", expected: EmotionAsset},
+	{name: "Expression", input: "expression", expected: EmotionAsset},
+	{name: "Other", input: "other", expected: OtherAsset},
+	{name: "Unknown", input: "x-custom", expected: DefaultAssetType},
+	{name: "Empty", input: "", expected: DefaultAssetType},
+}
+
+func TestAssetType_FromString(t *testing.T) {
+	for _, tc := range assetTypeFromStringTests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, AssetTypeProp().FromString(tc.input))
+		})
+	}
+}
+
+func TestAssetType_UnmarshalJSON(t *testing.T) {
+	var allTestCases []propertyTestCase[string, AssetType]
+	for _, tc := range assetTypeFromStringTests {
+		allTestCases = append(allTestCases, propertyTestCase[string, AssetType]{
+			name:     fmt.Sprintf("From JSON String '%s'", tc.name),
+			input:    fmt.Sprintf(`"%s"`, tc.input),
+			expected: tc.expected,
+		})
+	}
+
+	extraTestCases := []propertyTestCase[string, AssetType]{
+		{name: "JSON Number", input: "1", expected: DefaultAssetType},
+		{name: "JSON Boolean", input: "true", expected: DefaultAssetType},
+		{name: "JSON Null", input: "null", expected: DefaultAssetType},
+		{name: "JSON Object", input: "{}", expected: DefaultAssetType},
+		{name: "JSON Array", input: "[]", expected: DefaultAssetType},
+		{name: "Malformed JSON", input: "{", shouldErr: true, expected: AssetType("")},
+	}
+	allTestCases = append(allTestCases, extraTestCases...)
+
+	for _, tc := range allTestCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var result AssetType
+			err := sonicx.Config.UnmarshalFromString(tc.input, &result)
+			if tc.shouldErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tc.expected, result)
+		})
+	}
+}
+
+func TestAssetType_MarshalJSON(t *testing.T) {
+	for _, assetType := range []AssetType{IconAsset, BackgroundAsset, UserIconAsset, EmotionAsset, OtherAsset} {
+		t.Run(string(assetType), func(t *testing.T) {
+			bytes, err := sonicx.Config.Marshal(&assetType)
+			assert.NoError(t, err)
+			assert.JSONEq(t, fmt.Sprintf(`"%s"`, assetType), string(bytes))
+		})
+	}
+}
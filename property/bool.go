@@ -1,8 +1,7 @@
 package property
 
 import (
-	"github.com/r3dpixel/toolkit/jsonx"
-	"github.com/r3dpixel/toolkit/sonicx"
+	"github.com/r3dpixel/card-parser/internal/codec"
 	"github.com/spf13/cast"
 )
 
@@ -25,14 +24,14 @@ func (b *Bool) OnNull() {}
 // NOTE: The original value is preserved
 func (b *Bool) OnComplex(complex any) {}
 
-// MarshalJSON marshals the Bool to JSON using Sonic
+// MarshalJSON marshals the Bool to JSON using the JSON codec
 func (b *Bool) MarshalJSON() ([]byte, error) {
-	return sonicx.Config.Marshal((*bool)(b))
+	return codec.Marshal((*bool)(b))
 }
 
-// UnmarshalJSON unmarshals JSON data into the Bool using Sonic
+// UnmarshalJSON unmarshals JSON data into the Bool using the JSON codec
 func (b *Bool) UnmarshalJSON(data []byte) error {
-	return jsonx.HandlePrimitive(data, b)
+	return codec.HandlePrimitive(data, b)
 }
 
 // SetIfPtr updates the Bool if the value is not nil
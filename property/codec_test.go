@@ -0,0 +1,18 @@
+package property
+
+import (
+	"os"
+	"testing"
+
+	"github.com/r3dpixel/card-parser/internal/codec"
+)
+
+// TestMain runs every test of the package with each available codec (Sonic first, then encoding/json)
+func TestMain(m *testing.M) {
+	for _, c := range codec.Available {
+		codec.Set(c)
+		if code := m.Run(); code != 0 {
+			os.Exit(code)
+		}
+	}
+}
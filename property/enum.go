@@ -0,0 +1,18 @@
+package property
+
+import "sync/atomic"
+
+// enumsAsStrings marshals LorePosition, SelectiveLogic and Role as their canonical names (instead of numbers)
+var enumsAsStrings atomic.Bool
+
+// MarshalEnumsAsStrings sets whether LorePosition, SelectiveLogic and Role marshal to their canonical
+// SillyTavern names (e.g. "before_char", "and_any", "system") instead of numbers (default false)
+// Unmarshalling always accepts both forms
+func MarshalEnumsAsStrings(enabled bool) {
+	enumsAsStrings.Store(enabled)
+}
+
+// EnumsAsStrings returns true if LorePosition, SelectiveLogic and Role marshal to their canonical names
+func EnumsAsStrings() bool {
+	return enumsAsStrings.Load()
+}
@@ -0,0 +1,71 @@
+package property
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/r3dpixel/toolkit/sonicx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMarshalEnumsAsStrings(t *testing.T) {
+	MarshalEnumsAsStrings(true)
+	defer MarshalEnumsAsStrings(false)
+	assert.True(t, EnumsAsStrings())
+
+	t.Run("LorePosition", func(t *testing.T) {
+		for position, name := range LorePositionNames {
+			data, err := sonicx.Config.Marshal(&position)
+			require.NoError(t, err)
+			assert.JSONEq(t, fmt.Sprintf(`"%s"`, name), string(data))
+
+			var result LorePosition
+			require.NoError(t, sonicx.Config.Unmarshal(data, &result))
+			assert.Equal(t, position, result)
+		}
+	})
+
+	t.Run("SelectiveLogic", func(t *testing.T) {
+		for logic, name := range SelectiveLogicNames {
+			data, err := sonicx.Config.Marshal(&logic)
+			require.NoError(t, err)
+			assert.JSONEq(t, fmt.Sprintf(`"%s"`, name), string(data))
+
+			var result SelectiveLogic
+			require.NoError(t, sonicx.Config.Unmarshal(data, &result))
+			assert.Equal(t, logic, result)
+		}
+	})
+
+	t.Run("Role", func(t *testing.T) {
+		for role, name := range RoleNames {
+			data, err := sonicx.Config.Marshal(&role)
+			require.NoError(t, err)
+			assert.JSONEq(t, fmt.Sprintf(`"%s"`, name), string(data))
+
+			var result Role
+			require.NoError(t, sonicx.Config.Unmarshal(data, &result))
+			assert.Equal(t, role, result)
+		}
+	})
+
+	t.Run("Out of range values stay numeric", func(t *testing.T) {
+		position := LorePosition(42)
+		data, err := sonicx.Config.Marshal(&position)
+		require.NoError(t, err)
+		assert.Equal(t, "42", string(data))
+	})
+}
+
+func TestEnumNames_Complete(t *testing.T) {
+	for position := LorePositionStart; position <= LorePositionEnd; position++ {
+		assert.Contains(t, LorePositionNames, position)
+	}
+	for logic := SelectiveLogicStart; logic <= SelectiveLogicEnd; logic++ {
+		assert.Contains(t, SelectiveLogicNames, logic)
+	}
+	for role := RoleStart; role <= RoleEnd; role++ {
+		assert.Contains(t, RoleNames, role)
+	}
+}
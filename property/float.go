@@ -1,8 +1,9 @@
 package property
 
 import (
-	"github.com/r3dpixel/toolkit/jsonx"
-	"github.com/r3dpixel/toolkit/sonicx"
+	"math"
+
+	"github.com/r3dpixel/card-parser/internal/codec"
 	"github.com/spf13/cast"
 )
 
@@ -25,14 +26,23 @@ func (f *Float) OnNull() {}
 // NOTE: The original value is preserved
 func (f *Float) OnComplex(complex any) {}
 
-// MarshalJSON marshals the Float to JSON using Sonic
+// MarshalJSON marshals the Float to JSON using the JSON codec
 func (f *Float) MarshalJSON() ([]byte, error) {
-	return sonicx.Config.Marshal((*float64)(f))
+	return codec.Marshal((*float64)(f))
 }
 
-// UnmarshalJSON unmarshals JSON data into the Float using Sonic
+// UnmarshalJSON unmarshals JSON data into the Float using the JSON codec
 func (f *Float) UnmarshalJSON(data []byte) error {
-	return jsonx.HandlePrimitive(data, f)
+	return codec.HandlePrimitive(data, f)
+}
+
+// Clamp limits the Float to the [lower, upper] range (NaN is set to the lower bound)
+func (f *Float) Clamp(lower, upper float64) {
+	if math.IsNaN(float64(*f)) {
+		*f = Float(lower)
+		return
+	}
+	*f = Float(max(lower, min(upper, float64(*f))))
 }
 
 // SetIfPtr updates the Float if the value is not nil
@@ -1,6 +1,7 @@
 package property
 
 import (
+	"math"
 	"testing"
 
 	"github.com/r3dpixel/toolkit/ptr"
@@ -200,3 +201,27 @@ func TestFloat_SetIfPropertyPtr(t *testing.T) {
 		})
 	}
 }
+
+func TestFloat_Clamp(t *testing.T) {
+	tests := []struct {
+		name     string
+		initial  Float
+		lower    float64
+		upper    float64
+		expected Float
+	}{
+		{name: "Within range", initial: Float(87.5), lower: 0, upper: 100, expected: Float(87.5)},
+		{name: "Below lower bound", initial: Float(-0.5), lower: 0, upper: 100, expected: Float(0)},
+		{name: "Above upper bound", initial: Float(250), lower: 0, upper: 100, expected: Float(100)},
+		{name: "Infinity", initial: Float(math.Inf(1)), lower: 0, upper: 100, expected: Float(100)},
+		{name: "NaN", initial: Float(math.NaN()), lower: 0, upper: 100, expected: Float(0)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := tt.initial
+			result.Clamp(tt.lower, tt.upper)
+			assert.Equal(t, tt.expected, result)
+		})
+	}
+}
@@ -1,8 +1,7 @@
 package property
 
 import (
-	"github.com/r3dpixel/toolkit/jsonx"
-	"github.com/r3dpixel/toolkit/sonicx"
+	"github.com/r3dpixel/card-parser/internal/codec"
 	"github.com/spf13/cast"
 )
 
@@ -25,14 +24,19 @@ func (i *Integer) OnNull() {}
 // NOTE: The original value is preserved
 func (i *Integer) OnComplex(complex any) {}
 
-// MarshalJSON marshals the Integer to JSON using Sonic
+// MarshalJSON marshals the Integer to JSON using the JSON codec
 func (i *Integer) MarshalJSON() ([]byte, error) {
-	return sonicx.Config.Marshal((*int)(i))
+	return codec.Marshal((*int)(i))
 }
 
-// UnmarshalJSON unmarshals JSON data into the Integer using Sonic
+// UnmarshalJSON unmarshals JSON data into the Integer using the JSON codec
 func (i *Integer) UnmarshalJSON(data []byte) error {
-	return jsonx.HandlePrimitive(data, i)
+	return codec.HandlePrimitive(data, i)
+}
+
+// Clamp limits the Integer to the [lower, upper] range
+func (i *Integer) Clamp(lower, upper int) {
+	*i = Integer(max(lower, min(upper, int(*i))))
 }
 
 // SetIfPtr updates the Integer if the value is not nil
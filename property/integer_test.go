@@ -119,3 +119,26 @@ func TestInteger_SetIfPropertyPtr(t *testing.T) {
 		})
 	}
 }
+
+func TestInteger_Clamp(t *testing.T) {
+	tests := []struct {
+		name     string
+		initial  Integer
+		lower    int
+		upper    int
+		expected Integer
+	}{
+		{name: "Within range", initial: Integer(50), lower: 0, upper: 100, expected: Integer(50)},
+		{name: "Below lower bound", initial: Integer(-3), lower: 0, upper: 100, expected: Integer(0)},
+		{name: "Above upper bound", initial: Integer(250), lower: 0, upper: 100, expected: Integer(100)},
+		{name: "At bounds", initial: Integer(100), lower: 0, upper: 100, expected: Integer(100)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := tt.initial
+			result.Clamp(tt.lower, tt.upper)
+			assert.Equal(t, tt.expected, result)
+		})
+	}
+}
@@ -3,8 +3,7 @@ package property
 import (
 	"strings"
 
-	"github.com/r3dpixel/toolkit/jsonx"
-	"github.com/r3dpixel/toolkit/sonicx"
+	"github.com/r3dpixel/card-parser/internal/codec"
 	"github.com/r3dpixel/toolkit/stringsx"
 	"github.com/r3dpixel/toolkit/symbols"
 	"github.com/spf13/cast"
@@ -28,6 +27,17 @@ const (
 // LorePosition represents the position of a book entry in the Lorebook
 type LorePosition int
 
+// LorePositionNames canonical (SillyTavern) names of the LorePosition values
+var LorePositionNames = map[LorePosition]string{
+	BeforeCharPosition:    "before_char",
+	AfterCharPosition:     "after_char",
+	BeforeAuthorNotes:     "before_an",
+	AfterAuthorNotes:      "after_an",
+	AtDepth:               "at_depth",
+	BeforeExampleMessages: "before_em",
+	AfterExampleMessages:  "after_em",
+}
+
 // OnFloat converts the float value to an integer and sets the LorePosition to the corresponding value
 func (l *LorePosition) OnFloat(floatValue float64) {
 	*l = lpParser.FromInt(cast.ToInt(floatValue))
@@ -63,14 +73,18 @@ func (l *LorePosition) OnObject(objectValue map[string]any) {
 	*l = DefaultLorePosition
 }
 
-// MarshalJSON marshals the LorePosition to JSON using Sonic
+// MarshalJSON marshals the LorePosition to JSON using the JSON codec
+// The canonical name is marshaled instead if MarshalEnumsAsStrings is enabled
 func (l *LorePosition) MarshalJSON() ([]byte, error) {
-	return sonicx.Config.Marshal((*int)(l))
+	if name, ok := LorePositionNames[*l]; ok && EnumsAsStrings() {
+		return codec.Marshal(name)
+	}
+	return codec.Marshal((*int)(l))
 }
 
-// UnmarshalJSON unmarshals JSON data into the LorePosition using Sonic
+// UnmarshalJSON unmarshals JSON data into the LorePosition using the JSON codec
 func (l *LorePosition) UnmarshalJSON(data []byte) error {
-	return jsonx.HandleEntity(data, l)
+	return codec.HandleEntity(data, l)
 }
 
 // SetIfPtr updates the LorePosition if the value is not nil
@@ -91,6 +105,7 @@ func (l *LorePosition) SetIfPropertyPtr(value *LorePosition) {
 type LorePositionParser interface {
 	FromString(value string) LorePosition
 	FromInt(value int) LorePosition
+	Parse(value string) (LorePosition, bool)
 }
 
 // lorePositionParser API to parse string into a valid LorePosition
@@ -124,18 +139,24 @@ func LorePositionProp() LorePositionParser {
 	return lpParser
 }
 
-// FromString converts a string value to a LorePosition after sanitization
+// FromString converts a string value to a LorePosition after sanitization (unknown values are converted to DefaultLorePosition)
 func (lp *lorePositionParser) FromString(value string) LorePosition {
-	sanitizedValue := strings.ToLower(stringsx.Remove(value, symbols.NonAlphaNumericWhiteSpaceRegExp))
-
-	// Check string sets for before position
-	if position, ok := lp.strs[sanitizedValue]; ok {
+	if position, ok := lp.Parse(value); ok {
 		return position
 	}
-
 	return DefaultLorePosition
 }
 
+// Parse converts a string value to a LorePosition after sanitization, and reports whether the value is known
+func (lp *lorePositionParser) Parse(value string) (LorePosition, bool) {
+	// Input value is a string (remove non-ASCII, remove symbols, remove whitespace, lower all characters)
+	sanitizedValue := strings.ToLower(stringsx.Remove(value, symbols.NonAlphaNumericWhiteSpaceRegExp))
+
+	// Check if the string input corresponds to any LorePosition value
+	position, ok := lp.strs[sanitizedValue]
+	return position, ok
+}
+
 // FromInt converts an integer value to a LorePosition
 func (lp *lorePositionParser) FromInt(value int) LorePosition {
 	// Check if the integer value is within the valid range
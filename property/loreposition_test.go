@@ -137,6 +137,18 @@ func TestLorePosition_FromString(t *testing.T) {
 	}
 }
 
+func TestLorePosition_Parse(t *testing.T) {
+	value, ok := LorePositionProp().Parse("after_an")
+	assert.True(t, ok)
+	assert.Equal(t, AfterAuthorNotes, value)
+
+	// The default value is known, unknown values are reported
+	_, ok = LorePositionProp().Parse("before_char")
+	assert.True(t, ok)
+	_, ok = LorePositionProp().Parse("sideways")
+	assert.False(t, ok)
+}
+
 func TestLorePosition_FromInt(t *testing.T) {
 	for _, tc := range lorePositionTests.fromInt {
 		t.Run(tc.name, func(t *testing.T) {
@@ -0,0 +1,296 @@
+package property
+
+import (
+	"html"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// htmlBlockTags HTML elements rendered on their own lines (replaced with line breaks by StripHTML)
+var htmlBlockTags = map[string]bool{
+	"address": true, "article": true, "aside": true, "blockquote": true, "br": true, "dd": true, "details": true,
+	"div": true, "dl": true, "dt": true, "figcaption": true, "figure": true, "footer": true, "h1": true, "h2": true,
+	"h3": true, "h4": true, "h5": true, "h6": true, "header": true, "hr": true, "li": true, "main": true, "nav": true,
+	"ol": true, "p": true, "pre": true, "section": true, "summary": true, "table": true, "tr": true, "ul": true,
+}
+
+// htmlRawTextTags HTML elements whose content is dropped with the tags (not text)
+var htmlRawTextTags = map[string]bool{"script": true, "style": true}
+
+// legacyMacroTags angle bracket macros of the SillyTavern cards (<START>, <USER>, <BOT>, <CHAR>), kept by StripHTML
+var legacyMacroTags = map[string]bool{"start": true, "user": true, "bot": true, "char": true}
+
+// Delimiters of the placeholders of the text protected from the markdown stripping (private use characters)
+const (
+	placeholderStart = "\uE000"
+	placeholderEnd   = "\uE001"
+)
+
+// Markdown syntax removed (or unwrapped) by StripMarkdown
+var (
+	markdownProtected   = regexp.MustCompile("`[^`\n]+`|\\{\\{.*?\\}\\}|\\\\[!-/:-@\\[-`{-~]")
+	markdownPlaceholder = regexp.MustCompile(placeholderStart + `(\d+)` + placeholderEnd)
+	markdownHeading     = regexp.MustCompile(`^ {0,3}#{1,6}(?:\s+|$)`)
+	markdownBreak       = regexp.MustCompile(`^ {0,3}(?:(?:\*\s*){3,}|(?:-\s*){3,}|(?:_\s*){3,})$`)
+	markdownLink        = regexp.MustCompile(`!?\[([^\]\n]*)\]\([^)\n]*\)`)
+	markdownStrong      = regexp.MustCompile(`\*\*([^*\s](?:[^*]*[^*\s])?)\*\*`)
+	markdownEmphasis    = regexp.MustCompile(`\*([^*\s](?:[^*]*[^*\s])?)\*`)
+	markdownStrike      = regexp.MustCompile(`~~([^~\s](?:[^~]*[^~\s])?)~~`)
+	markdownUnderlines  = []*regexp.Regexp{
+		regexp.MustCompile(`(^|[^\p{L}\p{N}_])__([^_\s](?:[^_]*[^_\s])?)__($|[^\p{L}\p{N}_])`),
+		regexp.MustCompile(`(^|[^\p{L}\p{N}_])_([^_\s](?:[^_]*[^_\s])?)_($|[^\p{L}\p{N}_])`),
+	}
+)
+
+// HasHTML checks if the String contains HTML tags or comments (the legacy <START>, <USER>, <BOT> and <CHAR> macros
+// are not tags)
+func (s String) HasHTML() bool {
+	text := string(s)
+	for index := strings.IndexByte(text, '<'); index >= 0; {
+		if _, _, _, ok := scanHTMLTag(text, index); ok {
+			return true
+		}
+		next := strings.IndexByte(text[index+1:], '<')
+		if next < 0 {
+			break
+		}
+		index += next + 1
+	}
+	return false
+}
+
+// StripHTML returns the String without the HTML tags and comments, and with the entities decoded (&nbsp; becomes a
+// space); block elements (e.g. p, div, li, br) are replaced with line breaks, script and style elements are dropped
+// with their content, and runs of blank lines are collapsed into one (the result is trimmed)
+// Text that only looks like markup is kept: a < not starting a tag (e.g. a < b, <3), {{...}} macros and the
+// legacy <START>, <USER>, <BOT> and <CHAR> macros
+func (s String) StripHTML() String {
+	text := string(s)
+	if !strings.ContainsAny(text, "<&") {
+		return s
+	}
+
+	var builder strings.Builder
+	builder.Grow(len(text))
+	textStart := 0
+	for index := 0; index < len(text); {
+		// Find the next tag (text is written when a tag is found, with its entities decoded)
+		if text[index] != '<' {
+			index++
+			continue
+		}
+		end, name, closing, ok := scanHTMLTag(text, index)
+		if !ok {
+			index++
+			continue
+		}
+		builder.WriteString(html.UnescapeString(text[textStart:index]))
+
+		// Drop the content of the raw text elements, and break the lines of the block elements
+		switch {
+		case htmlRawTextTags[name] && !closing:
+			end = skipRawText(text, end, name)
+		case htmlBlockTags[name]:
+			builder.WriteByte('\n')
+		}
+		index, textStart = end, end
+	}
+	builder.WriteString(html.UnescapeString(text[textStart:]))
+
+	// Clean up the whitespace left by the tags
+	return String(collapseBlankLines(strings.ReplaceAll(builder.String(), "\u00a0", " ")))
+}
+
+// StripMarkdown returns the String without the markdown syntax: emphasis, strong and strikethrough text is unwrapped,
+// links and images are replaced with their text, code spans with their content, headings lose their markers, and
+// horizontal rules are removed; the fences of code blocks are removed, and their content is kept untouched
+// {{...}} macros and escaped characters (e.g. \*) are never treated as markdown (the escapes are removed)
+func (s String) StripMarkdown() String {
+	lines := strings.Split(string(s), "\n")
+	stripped := make([]string, 0, len(lines))
+	fence := "" // Fence of the open code block
+	for _, line := range lines {
+		trimmed := strings.TrimSpace(line)
+
+		// Keep the content of code blocks untouched (without the fences)
+		if fence != "" {
+			if strings.HasPrefix(trimmed, fence) && strings.Trim(trimmed, fence[:1]) == "" {
+				fence = ""
+				continue
+			}
+			stripped = append(stripped, line)
+			continue
+		}
+		if marker := codeFence(trimmed); marker != "" {
+			fence = marker
+			continue
+		}
+
+		// Drop the horizontal rules, and strip the headings and inline syntax
+		if markdownBreak.MatchString(line) {
+			continue
+		}
+		stripped = append(stripped, stripInlineMarkdown(markdownHeading.ReplaceAllString(line, "")))
+	}
+	return String(strings.Join(stripped, "\n"))
+}
+
+// HasMarkdown checks if the String contains markdown syntax (removed by StripMarkdown)
+func (s String) HasMarkdown() bool {
+	return s.StripMarkdown() != s
+}
+
+// scanHTMLTag scans the tag (or comment, or declaration) starting at the given < and returns the index after its >,
+// its lowercase name, and whether it is a closing tag; ok is false if the < does not start a tag
+func scanHTMLTag(text string, start int) (end int, name string, closing bool, ok bool) {
+	index := start + 1
+
+	// Comments end at -->, or at the end of the text
+	if strings.HasPrefix(text[index:], "!--") {
+		if commentEnd := strings.Index(text[index+3:], "-->"); commentEnd >= 0 {
+			return index + 3 + commentEnd + 3, "", false, true
+		}
+		return len(text), "", false, true
+	}
+
+	// Declarations and processing instructions end at the next >
+	if index < len(text) && (text[index] == '!' || text[index] == '?') {
+		if declarationEnd := strings.IndexByte(text[index:], '>'); declarationEnd >= 0 {
+			return index + declarationEnd + 1, "", false, true
+		}
+		return 0, "", false, false
+	}
+
+	// Tag name (starting with a letter)
+	if index < len(text) && text[index] == '/' {
+		closing = true
+		index++
+	}
+	nameStart := index
+	for index < len(text) && (isASCIILetter(text[index]) || index > nameStart && isTagNameByte(text[index])) {
+		index++
+	}
+	if index == nameStart {
+		return 0, "", false, false
+	}
+	name = strings.ToLower(text[nameStart:index])
+
+	// Attributes up to the closing > (quoted values can contain >)
+	var quote byte
+	for ; index < len(text); index++ {
+		switch char := text[index]; {
+		case quote != 0:
+			if char == quote {
+				quote = 0
+			}
+		case char == '"' || char == '\'':
+			quote = char
+		case char == '<':
+			return 0, "", false, false
+		case char == '>':
+			// The legacy macros are text
+			if !closing && legacyMacroTags[name] && index == nameStart+len(name) {
+				return 0, "", false, false
+			}
+			return index + 1, name, closing, true
+		}
+	}
+	return 0, "", false, false
+}
+
+// skipRawText returns the index after the closing tag of the raw text element (the end of the text if unclosed)
+func skipRawText(text string, start int, name string) int {
+	lower := strings.ToLower(text[start:])
+	closeStart := strings.Index(lower, "</"+name)
+	if closeStart < 0 {
+		return len(text)
+	}
+	closeEnd := strings.IndexByte(lower[closeStart:], '>')
+	if closeEnd < 0 {
+		return len(text)
+	}
+	return start + closeStart + closeEnd + 1
+}
+
+// collapseBlankLines trims the trailing spaces of the lines, collapses the runs of blank lines, and trims the text
+func collapseBlankLines(text string) string {
+	lines := strings.Split(text, "\n")
+	kept := lines[:0]
+	blank := false
+	for _, line := range lines {
+		line = strings.TrimRight(line, " \t\r")
+		if line == "" {
+			if blank {
+				continue
+			}
+			blank = true
+		} else {
+			blank = false
+		}
+		kept = append(kept, line)
+	}
+	return strings.TrimSpace(strings.Join(kept, "\n"))
+}
+
+// codeFence returns the fence (``` or ~~~, at least 3 characters) opening a code block on the trimmed line (empty if
+// the line does not open a code block)
+func codeFence(trimmed string) string {
+	for _, char := range []string{"`", "~"} {
+		marker := strings.Repeat(char, 3)
+		if !strings.HasPrefix(trimmed, marker) {
+			continue
+		}
+		length := len(trimmed) - len(strings.TrimLeft(trimmed, char))
+		return trimmed[:length]
+	}
+	return ""
+}
+
+// stripInlineMarkdown unwraps the inline markdown syntax of the line (the code spans, macros and escaped characters
+// are replaced with placeholders, so their content is never treated as markdown)
+func stripInlineMarkdown(line string) string {
+	// Protect the code spans, macros and escaped characters
+	var protected []string
+	line = markdownProtected.ReplaceAllStringFunc(line, func(match string) string {
+		switch {
+		case strings.HasPrefix(match, "`"):
+			match = match[1 : len(match)-1]
+		case strings.HasPrefix(match, `\`):
+			match = match[1:]
+		}
+		protected = append(protected, match)
+		return placeholderStart + strconv.Itoa(len(protected)-1) + placeholderEnd
+	})
+
+	// Unwrap the links, emphasis, strong and strikethrough text
+	line = markdownLink.ReplaceAllString(line, "$1")
+	line = markdownStrong.ReplaceAllString(line, "$1")
+	for _, underline := range markdownUnderlines {
+		// Adjacent matches share their boundary, so replace until stable
+		for replaced := underline.ReplaceAllString(line, "$1$2$3"); replaced != line; replaced = underline.ReplaceAllString(line, "$1$2$3") {
+			line = replaced
+		}
+	}
+	line = markdownEmphasis.ReplaceAllString(line, "$1")
+	line = markdownStrike.ReplaceAllString(line, "$1")
+
+	// Restore the protected text
+	if len(protected) == 0 {
+		return line
+	}
+	return markdownPlaceholder.ReplaceAllStringFunc(line, func(match string) string {
+		index, _ := strconv.Atoi(match[len(placeholderStart) : len(match)-len(placeholderEnd)])
+		return protected[index]
+	})
+}
+
+// isASCIILetter checks if the byte is an ASCII letter
+func isASCIILetter(b byte) bool {
+	return b >= 'a' && b <= 'z' || b >= 'A' && b <= 'Z'
+}
+
+// isTagNameByte checks if the byte can continue an HTML tag name
+func isTagNameByte(b byte) bool {
+	return isASCIILetter(b) || b >= '0' && b <= '9' || b == '-' || b == ':'
+}
@@ -0,0 +1,113 @@
+package property
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestString_StripHTML(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    String
+		expected String
+		hasHTML  bool
+	}{
+		{name: "Plain text", input: `{{char}} says "hi" to {{user}}`, expected: `{{char}} says "hi" to {{user}}`},
+		{
+			name:     "Nested tags",
+			input:    `<div class="card"><p>Hello <b>{{user}}, <i>welcome</i></b>!</p><p>It's <span style="color: red">late</span></p></div>`,
+			expected: "Hello {{user}}, welcome!\n\nIt's late",
+			hasHTML:  true,
+		},
+		{
+			name:     "Line breaks and lists",
+			input:    "Line 1<br>Line 2<br/>Line 3<ul>\n  <li>One</li>\n  <li>Two</li>\n</ul>",
+			expected: "Line 1\nLine 2\nLine 3\n\nOne\n\nTwo",
+			hasHTML:  true,
+		},
+		{
+			name:     "Entities",
+			input:    `<p>Fish &amp; chips&nbsp;&quot;&#39;&lt;b&gt;&quot;</p>`,
+			expected: `Fish & chips "'<b>"`,
+			hasHTML:  true,
+		},
+		{
+			name:     "Comments, scripts and styles",
+			input:    `<!-- hidden <b> -->Shown<style>p > b { color: red }</style><SCRIPT>if (a < b) {}</SCRIPT> text`,
+			expected: "Shown text",
+			hasHTML:  true,
+		},
+		{
+			name:     "Quoted attribute with >",
+			input:    `<a title="a > b" href='x'>Link</a>`,
+			expected: "Link",
+			hasHTML:  true,
+		},
+		{
+			name:     "Text looking like markup",
+			input:    "a < b, <3 and <{{char}}>\n<START>\n<USER>: Hi\n<bot>: Hello",
+			expected: "a < b, <3 and <{{char}}>\n<START>\n<USER>: Hi\n<bot>: Hello",
+		},
+		{name: "Unclosed tag", input: `Text <b class="x`, expected: `Text <b class="x`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.input.StripHTML())
+			assert.Equal(t, tt.hasHTML, tt.input.HasHTML())
+		})
+	}
+}
+
+func TestString_StripMarkdown(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    String
+		expected String
+	}{
+		{
+			name:     "Emphasis",
+			input:    `**Bold**, *italic*, ***both***, __under__, _single_ and ~~struck~~ "quotes"`,
+			expected: `Bold, italic, both, under, single and struck "quotes"`,
+		},
+		{
+			name:     "Roleplay actions and macros",
+			input:    `*{{char}} waves at {{user}}* "Hello, {{random::a_b::c_d}}!" 2 * 3 * 4 snake_case_name`,
+			expected: `{{char}} waves at {{user}} "Hello, {{random::a_b::c_d}}!" 2 * 3 * 4 snake_case_name`,
+		},
+		{
+			name:     "Links and images",
+			input:    `See [the **wiki**](https://example.com/wiki "Wiki") and ![portrait](img.png)`,
+			expected: `See the wiki and portrait`,
+		},
+		{
+			name:     "Headings and horizontal rules",
+			input:    "# Title\n## Section ##\n---\nText\n* * *\n#hashtag",
+			expected: "Title\nSection ##\nText\n#hashtag",
+		},
+		{
+			name:     "Code block with braces",
+			input:    "Example:\n```json\n{\"name\": \"{{char}}\", \"*keep*\": {}}\n```\nAfter `{{user}} *code*` text",
+			expected: "Example:\n{\"name\": \"{{char}}\", \"*keep*\": {}}\nAfter {{user}} *code* text",
+		},
+		{
+			name:     "Unclosed code block",
+			input:    "~~~~\n**kept**\n~~~\nstill code",
+			expected: "**kept**\n~~~\nstill code",
+		},
+		{
+			name:     "Escapes",
+			input:    `\*not italic\* and 5 \_ 6`,
+			expected: `*not italic* and 5 _ 6`,
+		},
+		{name: "Plain text", input: "Nothing to strip, it's fine.", expected: "Nothing to strip, it's fine."},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.input.StripMarkdown())
+			assert.Equal(t, tt.input != tt.expected, tt.input.HasMarkdown())
+		})
+	}
+}
@@ -3,8 +3,7 @@ package property
 import (
 	"strings"
 
-	"github.com/r3dpixel/toolkit/jsonx"
-	"github.com/r3dpixel/toolkit/sonicx"
+	"github.com/r3dpixel/card-parser/internal/codec"
 	"github.com/r3dpixel/toolkit/stringsx"
 	"github.com/r3dpixel/toolkit/symbols"
 	"github.com/spf13/cast"
@@ -23,6 +22,13 @@ const (
 // Role represents the role of a character in a book
 type Role int
 
+// RoleNames canonical (SillyTavern) names of the Role values
+var RoleNames = map[Role]string{
+	SystemRole:    "system",
+	UserRole:      "user",
+	AssistantRole: "assistant",
+}
+
 // OnFloat converts the float value to an integer and sets the Role to the corresponding value
 func (r *Role) OnFloat(floatValue float64) {
 	*r = rlParser.FromInt(cast.ToInt(floatValue))
@@ -58,14 +64,18 @@ func (r *Role) OnObject(objectValue map[string]any) {
 	*r = DefaultRole
 }
 
-// MarshalJSON marshals the Role to JSON using Sonic
+// MarshalJSON marshals the Role to JSON using the JSON codec
+// The canonical name is marshaled instead if MarshalEnumsAsStrings is enabled
 func (r *Role) MarshalJSON() ([]byte, error) {
-	return sonicx.Config.Marshal((*int)(r))
+	if name, ok := RoleNames[*r]; ok && EnumsAsStrings() {
+		return codec.Marshal(name)
+	}
+	return codec.Marshal((*int)(r))
 }
 
-// UnmarshalJSON unmarshals JSON data into the Role using Sonic
+// UnmarshalJSON unmarshals JSON data into the Role using the JSON codec
 func (r *Role) UnmarshalJSON(data []byte) error {
-	return jsonx.HandleEntity(data, r)
+	return codec.HandleEntity(data, r)
 }
 
 // SetIfPtr updates the role if the value is not blank or nil
@@ -86,6 +96,7 @@ func (r *Role) SetIfPropertyPtr(value *Role) {
 type RoleParser interface {
 	FromString(value string) Role
 	FromInt(value int) Role
+	Parse(value string) (Role, bool)
 }
 
 // roleParser API to parse string into a valid Role
@@ -111,18 +122,22 @@ func RoleProp() RoleParser {
 	return rlParser
 }
 
-// FromString converts a string value to a Role after sanitization
+// FromString converts a string value to a Role after sanitization (unknown values are converted to DefaultRole)
 func (rl *roleParser) FromString(value string) Role {
+	if role, ok := rl.Parse(value); ok {
+		return role
+	}
+	return DefaultRole
+}
+
+// Parse converts a string value to a Role after sanitization, and reports whether the value is known
+func (rl *roleParser) Parse(value string) (Role, bool) {
 	// Input value is a string (remove non-ASCII, remove symbols, remove whitespace, lower all characters)
 	sanitizedValue := strings.ToLower(stringsx.Remove(value, symbols.NonAlphaNumericWhiteSpaceRegExp))
 
 	// Check if the string input corresponds to any Role value
-	if role, exists := rl.values[sanitizedValue]; exists {
-		return role
-	}
-
-	// Return the DefaultRole value
-	return DefaultRole
+	role, ok := rl.values[sanitizedValue]
+	return role, ok
 }
 
 // FromInt converts an integer value to a SelectiveLogic
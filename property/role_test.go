@@ -121,6 +121,18 @@ func TestRole_FromString(t *testing.T) {
 	}
 }
 
+func TestRole_Parse(t *testing.T) {
+	value, ok := RoleProp().Parse("lorebook_depth_user")
+	assert.True(t, ok)
+	assert.Equal(t, UserRole, value)
+
+	// The default value is known, unknown values are reported
+	_, ok = RoleProp().Parse("system")
+	assert.True(t, ok)
+	_, ok = RoleProp().Parse("sideways")
+	assert.False(t, ok)
+}
+
 func TestRole_FromInt(t *testing.T) {
 	for _, tc := range roleTests.fromInt {
 		t.Run(tc.name, func(t *testing.T) {
@@ -3,8 +3,7 @@ package property
 import (
 	"strings"
 
-	"github.com/r3dpixel/toolkit/jsonx"
-	"github.com/r3dpixel/toolkit/sonicx"
+	"github.com/r3dpixel/card-parser/internal/codec"
 	"github.com/r3dpixel/toolkit/stringsx"
 	"github.com/r3dpixel/toolkit/symbols"
 	"github.com/spf13/cast"
@@ -25,6 +24,14 @@ const (
 // SelectiveLogic represents the selective logic of a book entry
 type SelectiveLogic int
 
+// SelectiveLogicNames canonical (SillyTavern) names of the SelectiveLogic values
+var SelectiveLogicNames = map[SelectiveLogic]string{
+	SelectiveAndAny: "and_any",
+	SelectiveNotAll: "not_all",
+	SelectiveNotAny: "not_any",
+	SelectiveAndAll: "and_all",
+}
+
 // OnFloat converts the float value to an integer and sets the SelectiveLogic to the corresponding value
 func (s *SelectiveLogic) OnFloat(floatValue float64) {
 	*s = slParser.FromInt(cast.ToInt(floatValue))
@@ -60,14 +67,18 @@ func (s *SelectiveLogic) OnObject(objectValue map[string]any) {
 	*s = DefaultSelectiveLogic
 }
 
-// MarshalJSON marshals the SelectiveLogic to JSON using Sonic
+// MarshalJSON marshals the SelectiveLogic to JSON using the JSON codec
+// The canonical name is marshaled instead if MarshalEnumsAsStrings is enabled
 func (s *SelectiveLogic) MarshalJSON() ([]byte, error) {
-	return sonicx.Config.Marshal((*int)(s))
+	if name, ok := SelectiveLogicNames[*s]; ok && EnumsAsStrings() {
+		return codec.Marshal(name)
+	}
+	return codec.Marshal((*int)(s))
 }
 
-// UnmarshalJSON unmarshals JSON data into the SelectiveLogic using Sonic
+// UnmarshalJSON unmarshals JSON data into the SelectiveLogic using the JSON codec
 func (s *SelectiveLogic) UnmarshalJSON(data []byte) error {
-	return jsonx.HandleEntity(data, s)
+	return codec.HandleEntity(data, s)
 }
 
 // SetIfPtr updates the selectivr logic if the value is not blank or nil
@@ -88,6 +99,7 @@ func (s *SelectiveLogic) SetIfPropertyPtr(value *SelectiveLogic) {
 type SelectiveLogicParser interface {
 	FromString(value string) SelectiveLogic
 	FromInt(value int) SelectiveLogic
+	Parse(value string) (SelectiveLogic, bool)
 }
 
 // selectiveLogicParser API to parse string into a valid SelectiveLogic
@@ -110,18 +122,22 @@ func SelectiveLogicProp() SelectiveLogicParser {
 	return slParser
 }
 
-// FromString converts a string value to a SelectiveLogic after sanitization
+// FromString converts a string value to a SelectiveLogic after sanitization (unknown values are converted to DefaultSelectiveLogic)
 func (sl *selectiveLogicParser) FromString(value string) SelectiveLogic {
+	if selectiveValue, ok := sl.Parse(value); ok {
+		return selectiveValue
+	}
+	return DefaultSelectiveLogic
+}
+
+// Parse converts a string value to a SelectiveLogic after sanitization, and reports whether the value is known
+func (sl *selectiveLogicParser) Parse(value string) (SelectiveLogic, bool) {
 	// Input value is a string (remove non-ASCII, remove symbols, remove whitespace, lower all characters)
 	sanitizedValue := strings.ToLower(stringsx.Remove(value, symbols.NonAlphaNumericWhiteSpaceRegExp))
 
 	// Check if the string input corresponds to any SelectiveLogic value
-	if selectiveValue, exists := sl.values[sanitizedValue]; exists {
-		return selectiveValue
-	}
-
-	// Return the DefaultSelectiveLogic value, otherwise
-	return DefaultSelectiveLogic
+	selectiveValue, ok := sl.values[sanitizedValue]
+	return selectiveValue, ok
 }
 
 // FromInt converts an integer value to a SelectiveLogic
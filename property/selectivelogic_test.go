@@ -118,6 +118,18 @@ func TestSelectiveLogic_FromString(t *testing.T) {
 	}
 }
 
+func TestSelectiveLogic_Parse(t *testing.T) {
+	value, ok := SelectiveLogicProp().Parse("NOT_ANY")
+	assert.True(t, ok)
+	assert.Equal(t, SelectiveNotAny, value)
+
+	// The default value is known, unknown values are reported
+	_, ok = SelectiveLogicProp().Parse("and_any")
+	assert.True(t, ok)
+	_, ok = SelectiveLogicProp().Parse("sideways")
+	assert.False(t, ok)
+}
+
 func TestSelectiveLogic_FromInt(t *testing.T) {
 	for _, tc := range selectiveLogicTests.fromInt {
 		t.Run(tc.name, func(t *testing.T) {
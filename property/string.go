@@ -1,8 +1,11 @@
 package property
 
 import (
-	"github.com/r3dpixel/toolkit/jsonx"
-	"github.com/r3dpixel/toolkit/sonicx"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/r3dpixel/card-parser/internal/codec"
 	"github.com/r3dpixel/toolkit/stringsx"
 	"github.com/spf13/cast"
 )
@@ -15,6 +18,78 @@ func (s *String) NormalizeSymbols() {
 	*s = String(stringsx.NormalizeSymbols(string(*s)))
 }
 
+// LenRunes returns the length of the String in runes (characters)
+func (s String) LenRunes() int {
+	return utf8.RuneCountInString(string(s))
+}
+
+// LenBytes returns the length of the String in bytes (UTF-8)
+func (s String) LenBytes() int {
+	return len(s)
+}
+
+// Truncate returns the String cut to at most maxRunes runes, including the ellipsis appended to the cut text
+// (dropped if it does not fit), or the String itself if it fits
+// The cut never splits a character (combining marks, emoji modifiers and ZWJ sequences stay with their base),
+// and a {{...}} macro crossing the limit is cut before its opening braces
+func (s String) Truncate(maxRunes int, ellipsis string) String {
+	// Return the String if it fits
+	runes := []rune(string(s))
+	if len(runes) <= maxRunes {
+		return s
+	}
+
+	// Leave room for the ellipsis
+	cut := maxRunes - utf8.RuneCountInString(ellipsis)
+	if cut < 0 {
+		cut, ellipsis = max(maxRunes, 0), ""
+	}
+
+	// Move the cut before the extensions of the last character, and before an unpaired flag half
+	for cut > 0 && joinsRunes(runes[cut-1], runes[cut]) {
+		cut--
+	}
+	if isRegionalIndicator(runes[cut]) {
+		indicators := 0
+		for index := cut - 1; index >= 0 && isRegionalIndicator(runes[index]); index-- {
+			indicators++
+		}
+		cut -= indicators % 2
+	}
+
+	// Move the cut before an unclosed macro (or a split opening brace pair)
+	text := string(runes[:cut])
+	if open := strings.LastIndex(text, "{{"); open >= 0 && !strings.Contains(text[open:], "}}") {
+		text = text[:open]
+	} else if strings.HasSuffix(text, "{") && runes[cut] == '{' {
+		text = text[:len(text)-1]
+	}
+
+	// Append the ellipsis to the cut text
+	return String(strings.TrimRightFunc(text, unicode.IsSpace) + ellipsis)
+}
+
+// joinsRunes checks if the next rune extends the character of the previous rune (the two runes cannot be split)
+func joinsRunes(previous, next rune) bool {
+	const zeroWidthJoiner = '\u200D'
+	switch {
+	case previous == zeroWidthJoiner || next == zeroWidthJoiner:
+		return true
+	case unicode.In(next, unicode.Mn, unicode.Me, unicode.Mc, unicode.Variation_Selector):
+		return true
+	case next >= 0x1F3FB && next <= 0x1F3FF: // Emoji skin tone modifiers
+		return true
+	case next >= 0xE0020 && next <= 0xE007F: // Emoji tag sequences (subdivision flags)
+		return true
+	}
+	return false
+}
+
+// isRegionalIndicator checks if the rune is a regional indicator symbol (pairs of them form flags)
+func isRegionalIndicator(r rune) bool {
+	return r >= 0x1F1E6 && r <= 0x1F1FF
+}
+
 // OnValue populates the String with the value converted to a string
 func (s *String) OnValue(value any) {
 	if stringValue, err := cast.ToStringE(value); err == nil {
@@ -29,17 +104,17 @@ func (s *String) OnNull() {
 
 // OnComplex populates the String with the JSON representation of the complex value
 func (s *String) OnComplex(complex any) {
-	*s = String(jsonx.String(complex))
+	*s = String(codec.String(complex))
 }
 
-// MarshalJSON marshals the String to JSON using Sonic
+// MarshalJSON marshals the String to JSON using the JSON codec
 func (s *String) MarshalJSON() ([]byte, error) {
-	return sonicx.Config.Marshal((*string)(s))
+	return codec.Marshal((*string)(s))
 }
 
-// UnmarshalJSON unmarshals JSON data into the String using Sonic
+// UnmarshalJSON unmarshals JSON data into the String using the JSON codec
 func (s *String) UnmarshalJSON(data []byte) error {
-	return jsonx.HandlePrimitive(data, s)
+	return codec.HandlePrimitive(data, s)
 }
 
 // SetIf updates the String if the value is not blank
@@ -286,3 +286,61 @@ func TestString_SetIfPropertyPtr(t *testing.T) {
 		})
 	}
 }
+
+func TestString_Lengths(t *testing.T) {
+	tests := []struct {
+		name  string
+		input String
+		runes int
+		bytes int
+	}{
+		{name: "Empty", input: "", runes: 0, bytes: 0},
+		{name: "ASCII", input: "Hello", runes: 5, bytes: 5},
+		{name: "CJK", input: "你好世界", runes: 4, bytes: 12},
+		{name: "Emoji", input: "Hi 👋", runes: 4, bytes: 7},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.runes, tt.input.LenRunes())
+			assert.Equal(t, tt.bytes, tt.input.LenBytes())
+		})
+	}
+}
+
+func TestString_Truncate(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    String
+		maxRunes int
+		ellipsis string
+		expected String
+	}{
+		{name: "Fits", input: "Hello", maxRunes: 5, ellipsis: "...", expected: "Hello"},
+		{name: "ASCII", input: "Hello world", maxRunes: 8, ellipsis: "...", expected: "Hello..."},
+		{name: "No ellipsis", input: "Hello world", maxRunes: 5, expected: "Hello"},
+		{name: "Trailing space trimmed", input: "Hello world", maxRunes: 7, ellipsis: "…", expected: "Hello…"},
+		{name: "Ellipsis does not fit", input: "Hello", maxRunes: 2, ellipsis: "...", expected: "He"},
+		{name: "Zero", input: "Hello", maxRunes: 0, ellipsis: "...", expected: ""},
+		{name: "CJK", input: "你好世界和平", maxRunes: 4, ellipsis: "…", expected: "你好世…"},
+		{name: "Emoji", input: "Hi 👋👋👋", maxRunes: 5, expected: "Hi 👋👋"},
+		{name: "Emoji with skin tone", input: "ok 👋🏽 bye", maxRunes: 4, expected: "ok"},
+		{name: "Emoji ZWJ sequence", input: "a👩‍💻 b", maxRunes: 3, expected: "a"},
+		{name: "Emoji with variation selector", input: "I ❤️ it", maxRunes: 3, expected: "I"},
+		{name: "Combining mark", input: "café au lait", maxRunes: 4, expected: "caf"},
+		{name: "Flags", input: "🇫🇷🇩🇪🇮🇹", maxRunes: 3, expected: "🇫🇷"},
+		{name: "Macro straddling the limit", input: "Hello {{char}}, welcome", maxRunes: 10, ellipsis: "...", expected: "Hello..."},
+		{name: "Macro cut between closing braces", input: "Hi {{user}}!", maxRunes: 10, expected: "Hi"},
+		{name: "Macro cut between opening braces", input: "Hi {{user}}!", maxRunes: 4, expected: "Hi"},
+		{name: "Closed macro kept", input: "Hi {{user}}, welcome", maxRunes: 14, ellipsis: "...", expected: "Hi {{user}}..."},
+		{name: "CJK macro", input: "你好{{char}}世界", maxRunes: 6, expected: "你好"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := tt.input.Truncate(tt.maxRunes, tt.ellipsis)
+			assert.Equal(t, tt.expected, result)
+			assert.LessOrEqual(t, result.LenRunes(), max(tt.maxRunes, 0))
+		})
+	}
+}
@@ -1,8 +1,7 @@
 package property
 
 import (
-	"github.com/r3dpixel/toolkit/jsonx"
-	"github.com/r3dpixel/toolkit/sonicx"
+	"github.com/r3dpixel/card-parser/internal/codec"
 	"github.com/spf13/cast"
 )
 
@@ -31,7 +30,7 @@ func (s *StringArray) OnNull() {
 
 // OnObject populates the StringArray with a single string containing the JSON representation of the object
 func (s *StringArray) OnObject(objectValue map[string]any) {
-	*s = StringArray{jsonx.String(objectValue)}
+	*s = StringArray{codec.String(objectValue)}
 }
 
 // OnArray populates the StringArray with the array values converted to strings
@@ -45,7 +44,7 @@ func (s *StringArray) OnArray(arrayValue []any) {
 		switch v := item.(type) {
 		case []any, map[string]any:
 			// If the item is an array or object, convert it to JSON string
-			stringItem = jsonx.String(v)
+			stringItem = codec.String(v)
 		default:
 			// Otherwise, convert the primitive value to a string
 			stringItem = cast.ToString(item)
@@ -57,12 +56,12 @@ func (s *StringArray) OnArray(arrayValue []any) {
 	*s = stringItems
 }
 
-// MarshalJSON marshals the StringArray to JSON using Sonic
+// MarshalJSON marshals the StringArray to JSON using the JSON codec
 func (s *StringArray) MarshalJSON() ([]byte, error) {
-	return sonicx.Config.Marshal((*[]string)(s))
+	return codec.Marshal((*[]string)(s))
 }
 
-// UnmarshalJSON unmarshals JSON data into the StringArray using Sonic
+// UnmarshalJSON unmarshals JSON data into the StringArray using the JSON codec
 func (s *StringArray) UnmarshalJSON(data []byte) error {
-	return jsonx.HandleEntity(data, s)
+	return codec.HandleEntity(data, s)
 }
@@ -0,0 +1,88 @@
+package property
+
+import (
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/r3dpixel/card-parser/internal/codec"
+	"github.com/spf13/cast"
+)
+
+// MillisecondsThreshold numeric timestamps above it are read as milliseconds (1e12 seconds is past the year 30000,
+// 1e12 milliseconds is in 2001)
+const MillisecondsThreshold = 1e12
+
+// timestampLayouts date layouts accepted by ParseTimestamp (RFC 3339 first; dates without zone are UTC)
+var timestampLayouts = []string{time.RFC3339Nano, "2006-01-02T15:04:05", "2006-01-02 15:04:05", "2006-01-02"}
+
+// Timestamp represents a Unix timestamp in seconds, parsed from the many forms found in cards (see ParseTimestamp)
+type Timestamp int64
+
+// ParseTimestamp converts the value to a Unix timestamp in seconds: integer and float seconds, integer milliseconds
+// (values above MillisecondsThreshold), numeric strings, and RFC 3339 strings (e.g. 2023-05-01T10:00:00Z) are accepted
+// Invalid values (e.g. booleans, other strings, negative or out of range numbers) are converted to 0
+func ParseTimestamp(value any) Timestamp {
+	switch typedValue := value.(type) {
+	case nil, bool:
+		return 0
+	case string:
+		text := strings.TrimSpace(typedValue)
+		if number, err := strconv.ParseFloat(text, 64); err == nil {
+			return epochTimestamp(number)
+		}
+		for _, layout := range timestampLayouts {
+			if parsed, err := time.Parse(layout, text); err == nil {
+				return epochTimestamp(float64(parsed.Unix()))
+			}
+		}
+		return 0
+	}
+	number, err := cast.ToFloat64E(value)
+	if err != nil {
+		return 0
+	}
+	return epochTimestamp(number)
+}
+
+// epochTimestamp converts the numeric timestamp (seconds or milliseconds) to seconds (0 if invalid)
+func epochTimestamp(number float64) Timestamp {
+	if number > MillisecondsThreshold {
+		number /= 1000
+	}
+	if math.IsNaN(number) || number < 0 || number > MillisecondsThreshold {
+		return 0
+	}
+	return Timestamp(number)
+}
+
+// OnValue populates the Timestamp with the given value (see ParseTimestamp)
+func (t *Timestamp) OnValue(value any) {
+	*t = ParseTimestamp(value)
+}
+
+// OnNull sets the Timestamp to 0
+func (t *Timestamp) OnNull() {
+	*t = 0
+}
+
+// OnComplex sets the Timestamp to 0, as it is not a complex type
+func (t *Timestamp) OnComplex(complex any) {
+	*t = 0
+}
+
+// MarshalJSON marshals the Timestamp to JSON (plain seconds) using the JSON codec
+func (t *Timestamp) MarshalJSON() ([]byte, error) {
+	return codec.Marshal((*int64)(t))
+}
+
+// UnmarshalJSON unmarshals JSON data into the Timestamp using the JSON codec
+func (t *Timestamp) UnmarshalJSON(data []byte) error {
+	return codec.HandlePrimitive(data, t)
+}
+
+// Time returns the Timestamp as a time
+func (t Timestamp) Time() time.Time {
+	return time.Unix(int64(t), 0)
+}
@@ -0,0 +1,54 @@
+package property
+
+import (
+	"testing"
+
+	"github.com/r3dpixel/toolkit/sonicx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTimestamp_UnmarshalJSON(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected Timestamp
+	}{
+		{name: "Integer seconds", input: `1700000000`, expected: 1700000000},
+		{name: "Integer milliseconds", input: `1700000000123`, expected: 1700000000},
+		{name: "Float seconds", input: `1700000000.75`, expected: 1700000000},
+		{name: "Float milliseconds", input: `1700000000123.5`, expected: 1700000000},
+		{name: "Numeric string", input: `"1700000000"`, expected: 1700000000},
+		{name: "Numeric string milliseconds", input: `" 1700000000123 "`, expected: 1700000000},
+		{name: "RFC 3339 string", input: `"2023-11-14T22:13:20Z"`, expected: 1700000000},
+		{name: "RFC 3339 string with offset", input: `"2023-11-15T00:13:20.5+02:00"`, expected: 1700000000},
+		{name: "Date without zone", input: `"2023-11-14 22:13:20"`, expected: 1700000000},
+		{name: "Date only", input: `"2023-11-14"`, expected: 1699920000},
+		{name: "Boundary (seconds)", input: `1000000000000`, expected: 1000000000000},
+		{name: "Boundary (milliseconds)", input: `1000000000001`, expected: 1000000000},
+		{name: "Zero", input: `0`, expected: 0},
+		{name: "Negative", input: `-5`, expected: 0},
+		{name: "Microseconds", input: `1700000000123456`, expected: 0},
+		{name: "Invalid string", input: `"yesterday"`, expected: 0},
+		{name: "Empty string", input: `""`, expected: 0},
+		{name: "Boolean", input: `true`, expected: 0},
+		{name: "Null", input: `null`, expected: 0},
+		{name: "Object", input: `{"date": 1700000000}`, expected: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := Timestamp(42)
+			require.NoError(t, sonicx.Config.UnmarshalFromString(tt.input, &result))
+			assert.Equal(t, tt.expected, result)
+		})
+	}
+}
+
+func TestTimestamp_MarshalJSON(t *testing.T) {
+	timestamp := Timestamp(1700000000)
+	bytes, err := sonicx.Config.Marshal(&timestamp)
+	require.NoError(t, err)
+	assert.Equal(t, "1700000000", string(bytes))
+	assert.Equal(t, int64(1700000000), timestamp.Time().Unix())
+}
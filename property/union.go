@@ -1,9 +1,10 @@
 package property
 
 import (
-	"github.com/r3dpixel/toolkit/jsonx"
+	"strconv"
+
+	"github.com/r3dpixel/card-parser/internal/codec"
 	"github.com/r3dpixel/toolkit/ptr"
-	"github.com/r3dpixel/toolkit/sonicx"
 	"github.com/spf13/cast"
 )
 
@@ -13,6 +14,66 @@ type Union struct {
 	StringValue *string
 }
 
+// UnionFromInt returns a Union holding the integer value
+func UnionFromInt(intValue int) Union {
+	return Union{IntValue: &intValue}
+}
+
+// UnionFromString returns a Union holding the string value (kept as a string, even if numeric)
+func UnionFromString(stringValue string) Union {
+	return Union{StringValue: &stringValue}
+}
+
+// IsZero checks if the Union holds no value
+func (u Union) IsZero() bool {
+	return u.IntValue == nil && u.StringValue == nil
+}
+
+// String returns the formatted integer value, otherwise the string value, otherwise an empty string
+func (u Union) String() string {
+	switch {
+	case u.IntValue != nil:
+		return strconv.Itoa(*u.IntValue)
+	case u.StringValue != nil:
+		return *u.StringValue
+	default:
+		return ""
+	}
+}
+
+// Int returns the integer value (or the string value parsed as an integer), and whether there is one
+func (u Union) Int() (int, bool) {
+	switch {
+	case u.IntValue != nil:
+		return *u.IntValue, true
+	case u.StringValue != nil:
+		intValue, err := strconv.Atoi(*u.StringValue)
+		return intValue, err == nil
+	default:
+		return 0, false
+	}
+}
+
+// Clone returns a copy of the Union that does not share the values
+func (u Union) Clone() Union {
+	clone := Union{}
+	if u.IntValue != nil {
+		clone.IntValue = ptr.Of(*u.IntValue)
+	}
+	if u.StringValue != nil {
+		clone.StringValue = ptr.Of(*u.StringValue)
+	}
+	return clone
+}
+
+// Equals checks if both Unions hold the same value, an integer being equal to its formatted string (5 equals "5")
+func (u Union) Equals(other Union) bool {
+	if u.IsZero() || other.IsZero() {
+		return u.IsZero() == other.IsZero()
+	}
+	return u.String() == other.String()
+}
+
 // OnFloat populates the Union with an integer value from a float64
 func (u *Union) OnFloat(floatValue float64) {
 	// If float value is detected, convert to integer and save it in the integer field
@@ -50,14 +111,14 @@ func (u *Union) OnNull() {
 // OnArray populates the Union with a string value from an array
 func (u *Union) OnArray(arrayValue []any) {
 	// If array is detected convert to json string and save it in the string field
-	u.StringValue = ptr.Of(jsonx.String(arrayValue))
+	u.StringValue = ptr.Of(codec.String(arrayValue))
 	u.IntValue = nil
 }
 
 // OnObject populates the Union with a string value from an object
 func (u *Union) OnObject(objectValue map[string]any) {
 	// If map is detected convert to json string and save it in the string field
-	u.StringValue = ptr.Of(jsonx.String(objectValue))
+	u.StringValue = ptr.Of(codec.String(objectValue))
 	u.IntValue = nil
 }
 
@@ -66,17 +127,17 @@ func (u *Union) MarshalJSON() ([]byte, error) {
 	switch {
 	case u.IntValue != nil:
 		// Integer values have priority (marshal integer value if it exists)
-		return sonicx.Config.Marshal(*u.IntValue)
+		return codec.Marshal(*u.IntValue)
 	case u.StringValue != nil:
 		// Fallback to marshalling the string value
-		return sonicx.Config.Marshal(*u.StringValue)
+		return codec.Marshal(*u.StringValue)
 	default:
 		// If nothing exists marshall nil
-		return sonicx.Config.Marshal(nil)
+		return codec.Marshal(nil)
 	}
 }
 
 // UnmarshalJSON unmarshals JSON data into the Union using the provided decoder
 func (u *Union) UnmarshalJSON(data []byte) error {
-	return jsonx.HandleEntity(data, u)
+	return codec.HandleEntity(data, u)
 }
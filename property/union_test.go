@@ -73,3 +73,66 @@ func TestBookEntryID_MarshalJSON(t *testing.T) {
 		})
 	}
 }
+
+func TestUnion_Helpers(t *testing.T) {
+	tests := []struct {
+		name     string
+		union    Union
+		isZero   bool
+		text     string
+		intValue int
+		isInt    bool
+	}{
+		{name: "Integer", union: UnionFromInt(5), text: "5", intValue: 5, isInt: true},
+		{name: "Negative integer", union: UnionFromInt(-3), text: "-3", intValue: -3, isInt: true},
+		{name: "Numeric string", union: UnionFromString("5"), text: "5", intValue: 5, isInt: true},
+		{name: "String", union: UnionFromString("entry-1"), text: "entry-1"},
+		{name: "Empty string", union: UnionFromString(""), text: ""},
+		{name: "Both values (integer takes precedence)", union: Union{IntValue: ptr.Of(1), StringValue: ptr.Of("a")}, text: "1", intValue: 1, isInt: true},
+		{name: "Zero", union: Union{}, isZero: true, text: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.isZero, tt.union.IsZero())
+			assert.Equal(t, tt.text, tt.union.String())
+			intValue, isInt := tt.union.Int()
+			assert.Equal(t, tt.isInt, isInt)
+			assert.Equal(t, tt.intValue, intValue)
+
+			// The clone holds the same values, without sharing them
+			clone := tt.union.Clone()
+			assert.Equal(t, tt.union, clone)
+			if tt.union.IntValue != nil {
+				assert.NotSame(t, tt.union.IntValue, clone.IntValue)
+			}
+			if tt.union.StringValue != nil {
+				assert.NotSame(t, tt.union.StringValue, clone.StringValue)
+			}
+		})
+	}
+}
+
+func TestUnion_Equals(t *testing.T) {
+	values := map[string]Union{
+		"int 5":        UnionFromInt(5),
+		"int 6":        UnionFromInt(6),
+		"string 5":     UnionFromString("5"),
+		"string 05":    UnionFromString("05"),
+		"string abc":   UnionFromString("abc"),
+		"string empty": UnionFromString(""),
+		"zero":         {},
+	}
+	equal := map[[2]string]bool{
+		{"int 5", "string 5"}: true,
+	}
+
+	for nameX, x := range values {
+		for nameY, y := range values {
+			t.Run(nameX+" vs "+nameY, func(t *testing.T) {
+				expected := nameX == nameY || equal[[2]string{nameX, nameY}] || equal[[2]string{nameY, nameX}]
+				assert.Equal(t, expected, x.Equals(y))
+			})
+		}
+	}
+}